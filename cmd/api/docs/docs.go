@@ -765,6 +765,31 @@ const docTemplate = `{
                 }
             }
         },
+        "main.BillingInfo": {
+            "type": "object",
+            "required": [
+                "company_name",
+                "tax_id"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "Jl. Jend. Sudirman Kav. 52-53, Jakarta"
+                },
+                "company_name": {
+                    "type": "string",
+                    "example": "PT Maju Bersama"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "finance@majubersama.co.id"
+                },
+                "tax_id": {
+                    "type": "string",
+                    "example": "01.234.567.8-901.000"
+                }
+            }
+        },
         "main.BookingResponse": {
             "type": "object",
             "properties": {
@@ -857,6 +882,9 @@ const docTemplate = `{
                 "room_id"
             ],
             "properties": {
+                "billing": {
+                    "$ref": "#/definitions/main.BillingInfo"
+                },
                 "check_in": {
                     "type": "string",
                     "example": "2025-01-15T00:00:00Z"
@@ -879,10 +907,15 @@ const docTemplate = `{
                     "type": "string",
                     "enum": [
                         "PAY_NOW",
-                        "PAY_AT_HOTEL"
+                        "PAY_AT_HOTEL",
+                        "PAY_LATER"
                     ],
                     "example": "PAY_NOW"
                 },
+                "payment_token": {
+                    "type": "string",
+                    "example": "521111-1117-a1b2c3d4"
+                },
                 "room_id": {
                     "type": "string",
                     "example": "room-001"
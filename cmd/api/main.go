@@ -16,13 +16,22 @@ import (
 	"github.com/ekonugroho98/be-bookingkuy/internal/destinations"
 	"github.com/ekonugroho98/be-bookingkuy/internal/hotel"
 	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelmapping"
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelplanner"
+	"github.com/ekonugroho98/be-bookingkuy/internal/invoice"
+	"github.com/ekonugroho98/be-bookingkuy/internal/ledger"
 	"github.com/ekonugroho98/be-bookingkuy/internal/midtrans"
 	"github.com/ekonugroho98/be-bookingkuy/internal/notification"
 	"github.com/ekonugroho98/be-bookingkuy/internal/payment"
 	"github.com/ekonugroho98/be-bookingkuy/internal/pricing"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
+	"github.com/ekonugroho98/be-bookingkuy/internal/providertraffic"
+	"github.com/ekonugroho98/be-bookingkuy/internal/reconciliation"
 	"github.com/ekonugroho98/be-bookingkuy/internal/review"
+	"github.com/ekonugroho98/be-bookingkuy/internal/risk"
 	"github.com/ekonugroho98/be-bookingkuy/internal/search"
 	"github.com/ekonugroho98/be-bookingkuy/internal/sendgrid"
+	"github.com/ekonugroho98/be-bookingkuy/internal/settlement"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/config"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
@@ -31,7 +40,9 @@ import (
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/middleware"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/server"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/worker"
 	"github.com/ekonugroho98/be-bookingkuy/internal/user"
+	"github.com/redis/go-redis/v9"
 
 	httpSwagger "github.com/swaggo/http-swagger" // swagger middleware
 )
@@ -74,6 +85,7 @@ func main() {
 	eb.Subscribe(context.Background(), eventbus.EventBookingPaid, booking.HandleBookingPaid)
 	eb.Subscribe(context.Background(), eventbus.EventBookingConfirmed, booking.HandleBookingConfirmed)
 	eb.Subscribe(context.Background(), eventbus.EventBookingCancelled, booking.HandleBookingCancelled)
+	eb.Subscribe(context.Background(), eventbus.EventBookingPaymentDue, booking.HandleBookingPaymentDue)
 
 	// Subscribe to payment events
 	eb.Subscribe(context.Background(), eventbus.EventPaymentSuccess, payment.HandlePaymentSuccess)
@@ -101,8 +113,55 @@ func main() {
 		cfg.Hotelbeds.Secret,
 		cfg.Hotelbeds.BaseURL,
 	)
+	hotelbedsQuotas := hotelbeds.DefaultQuotas()
+	hotelbedsQuotas[hotelbeds.OpAvailability] = cfg.Hotelbeds.AvailabilityQuota
+	hotelbedsQuotas[hotelbeds.OpBooking] = cfg.Hotelbeds.BookingQuota
+	if cfg.Hotelbeds.SharedRateLimit {
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port),
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		hotelbedsClient.SetLimiter(hotelbeds.NewRedisLimiter(redisClient, hotelbedsQuotas))
+		logger.Info("✅ HotelBeds rate limits shared through Redis")
+	} else {
+		hotelbedsClient.SetLimiter(hotelbeds.NewRateLimiter(hotelbedsQuotas))
+	}
 	logger.Info("✅ HotelBeds client initialized")
 
+	// Opt-in capture of redacted provider traffic, fetched by admins per booking
+	trafficConfig := providertraffic.Config{
+		Enabled:       cfg.ProviderTraffic.Enabled,
+		RetentionDays: cfg.ProviderTraffic.RetentionDays,
+		MaxBodyBytes:  cfg.ProviderTraffic.MaxBodyBytes,
+		QueueSize:     cfg.ProviderTraffic.QueueSize,
+	}
+	trafficService := providertraffic.NewService(providertraffic.NewRepository(database), trafficConfig)
+	trafficHandler := providertraffic.NewHandler(trafficService)
+	if trafficConfig.Enabled {
+		trafficService.Start(context.Background())
+		hotelbedsClient.WrapTransport(providertraffic.Wrap("hotelbeds", trafficService, trafficConfig))
+		logger.Info("✅ Provider traffic capture enabled")
+	}
+
+	// Hotel providers; booking and hotel services reach suppliers through the registry
+	providerRegistry := provider.NewRegistry()
+	providerRegistry.RegisterWithConfig(
+		provider.NewHotelbedsProviderWithClient(hotelbedsClient),
+		provider.Config{BaseURL: cfg.Hotelbeds.BaseURL, Timeout: provider.DefaultSearchTimeout, Enabled: true},
+	)
+	if cfg.HotelPlanner.APIKey != "" {
+		hotelplannerClient := hotelplanner.NewClient(cfg.HotelPlanner.APIKey, cfg.HotelPlanner.Secret, cfg.HotelPlanner.BaseURL)
+		if trafficConfig.Enabled {
+			hotelplannerClient.WrapTransport(providertraffic.Wrap("hotelplanner", trafficService, trafficConfig))
+		}
+		providerRegistry.RegisterWithConfig(
+			provider.NewHotelPlannerProviderWithClient(hotelplannerClient),
+			provider.Config{BaseURL: cfg.HotelPlanner.BaseURL, Timeout: provider.DefaultSearchTimeout, Enabled: true, Priority: 1},
+		)
+		logger.Info("✅ HotelPlanner provider registered")
+	}
+
 	// Initialize SendGrid client
 	sendgridClient := sendgrid.NewClient(sendgrid.Config{
 		APIKey:    cfg.SendGrid.APIKey,
@@ -132,14 +191,134 @@ func main() {
 	// Initialize services
 	userService := user.NewService(userRepo, eb)
 	authService := auth.NewService(userRepo, authRepo, eb, jwtManager)
-	searchService := search.NewService(search.NewRepository(database))
 	pricingService := pricing.NewService()
-	bookingService := booking.NewService(booking.NewRepository(database), eb, pricingService, hotelbedsClient)
-	paymentService := payment.NewServiceWithMidtrans(payment.NewRepository(database), eb, midtransClient)
+	searchService := search.NewService(search.NewRepository(database), providerRegistry, pricingService, search.DefaultConfig())
+	bookingRepo := booking.NewRepository(database)
+
+	// Fraud and risk scoring of bookings and payments
+	riskService := risk.NewService(risk.NewRepository(database), userRepo, bookingRepo, eb, risk.Config{
+		ReviewScore:        cfg.Risk.ReviewScore,
+		BlockScore:         cfg.Risk.BlockScore,
+		HighValueAmount:    cfg.Risk.HighValueAmount,
+		LastMinuteWindow:   cfg.Risk.LastMinuteWindow,
+		NewAccountAge:      cfg.Risk.NewAccountAge,
+		VelocityWindow:     cfg.Risk.VelocityWindow,
+		MaxAttemptsPerUser: cfg.Risk.MaxAttemptsPerUser,
+		MaxAttemptsPerIP:   cfg.Risk.MaxAttemptsPerIP,
+		MaxAccountsPerCard: cfg.Risk.MaxAccountsPerCard,
+	})
+	riskHandler := risk.NewHandler(riskService)
+	var riskScreener booking.RiskScreener
+	var riskChecker payment.RiskChecker
+	if cfg.Risk.Enabled {
+		riskScreener = risk.NewBookingScreener(riskService)
+		riskChecker = riskService
+	} else {
+		logger.Warn("⚠️ Fraud and risk scoring is disabled")
+	}
+
+	paymentService := payment.NewServiceWithRisk(payment.NewRepository(database), eb, midtransClient, riskChecker)
+	// Pay-at-hotel bookings are guaranteed by a saved card the gateway verifies
+	guaranteeChecker := booking.NewGuaranteeChecker(paymentService, cfg.Booking.MaxUnguaranteedAmount)
+	bookingService := booking.NewServiceWithRisk(bookingRepo, eb, pricingService, providerRegistry, guaranteeChecker, riskScreener)
+	eb.Subscribe(context.Background(), eventbus.EventBookingPaymentDue, payment.NewBookingPaymentDueHandler(paymentService, bookingRepo))
+	eb.Subscribe(context.Background(), eventbus.EventRiskReviewRequired, booking.NewRiskReviewRequiredHandler(bookingService))
+	eb.Subscribe(context.Background(), eventbus.EventRiskReviewApproved, booking.NewRiskReviewApprovedHandler(bookingService))
+	eb.Subscribe(context.Background(), eventbus.EventRiskReviewRejected, booking.NewRiskReviewRejectedHandler(bookingService))
+	eb.Subscribe(context.Background(), eventbus.EventRiskReviewApproved, payment.NewRiskReviewApprovedHandler(paymentService))
+	eb.Subscribe(context.Background(), eventbus.EventRiskReviewRejected, payment.NewRiskReviewRejectedHandler(paymentService))
+
+	// Ledger postings for money movements
+	ledgerService := ledger.NewService(ledger.NewRepository(database))
+	eb.Subscribe(context.Background(), eventbus.EventPaymentSuccess, ledger.NewPaymentSuccessHandler(ledgerService))
+	eb.Subscribe(context.Background(), eventbus.EventPaymentRefunded, ledger.NewPaymentRefundedHandler(ledgerService))
+	eb.Subscribe(context.Background(), eventbus.EventBookingConfirmed, ledger.NewBookingConfirmedHandler(ledgerService))
+	eb.Subscribe(context.Background(), eventbus.EventBookingCancelled, ledger.NewBookingCancelledHandler(ledgerService))
+
+	// Supplier payables and settlement
+	settlementService := settlement.NewService(settlement.NewRepository(database), settlement.SupplierTerms{
+		ProviderCode:    booking.DefaultProviderCode,
+		PaymentTermDays: cfg.Hotelbeds.PaymentTermDays,
+	})
+	settlementHandler := settlement.NewHandler(settlementService)
+	eb.Subscribe(context.Background(), eventbus.EventBookingConfirmed, settlement.NewBookingConfirmedHandler(settlementService))
+	eb.Subscribe(context.Background(), eventbus.EventBookingCancelled, settlement.NewBookingCancelledHandler(settlementService))
+
+	// Invoices and credit notes
+	invoiceService := invoice.NewService(invoice.NewRepository(database), bookingService, invoice.Issuer{
+		Party: invoice.Party{
+			Name:    cfg.Invoice.CompanyName,
+			TaxID:   cfg.Invoice.TaxID,
+			Address: cfg.Invoice.Address,
+		},
+		TaxName: cfg.Invoice.TaxName,
+		TaxRate: cfg.Invoice.TaxRate,
+	})
+	invoiceHandler := invoice.NewHandler(invoiceService)
+	eb.Subscribe(context.Background(), eventbus.EventPaymentSuccess, invoice.NewPaymentSuccessHandler(invoiceService))
+	eb.Subscribe(context.Background(), eventbus.EventPaymentRefunded, invoice.NewPaymentRefundedHandler(invoiceService))
+
+	// Nightly reconciliation of supplier bookings against ours
+	reconciliationService := reconciliation.NewService(reconciliation.NewRepository(database), reconciliation.DefaultConfig(),
+		reconciliation.NewHotelbedsSupplier(hotelbedsClient))
+	reconciliationHandler := reconciliation.NewHandler(reconciliationService)
+
+	// Cross-provider hotel mapping
+	hotelMappingService := hotelmapping.NewService(hotelmapping.NewRepository(database))
+	hotelMappingHandler := hotelmapping.NewHandler(hotelMappingService)
+
+	// Provider routing rules and cross-provider deduplication
+	providerRegistry.SetDeduplicator(hotelMappingService)
+	providerRouting := provider.NewRoutingService(providerRegistry, provider.NewRulesRepository(database))
+	if err := providerRouting.LoadRules(context.Background()); err != nil {
+		logger.ErrorWithErr(err, "Failed to load provider routing rules, using provider defaults")
+	}
+
+	// Background jobs
+	jobWorker := worker.New()
+	jobWorker.Register(&worker.Job{
+		ID:       "booking-pay-later",
+		Name:     "Pay-later charges and reminders",
+		Handler:  bookingService.ProcessDuePayLaterBookings,
+		Interval: 15 * time.Minute,
+	})
+	jobWorker.Register(&worker.Job{
+		ID:   "hotel-mapping-rebuild",
+		Name: "Rebuild cross-provider hotel mappings",
+		Handler: func(ctx context.Context) error {
+			_, err := hotelMappingService.Rebuild(ctx)
+			return err
+		},
+		Interval: 24 * time.Hour,
+	})
+	jobWorker.Register(&worker.Job{
+		ID:       "booking-reconciliation",
+		Name:     "Reconcile supplier bookings",
+		Handler:  reconciliationService.ReconcileAll,
+		Interval: 24 * time.Hour,
+	})
+	jobWorker.Register(&worker.Job{
+		ID:       "provider-traffic-purge",
+		Name:     "Purge captured provider traffic past retention",
+		Handler:  trafficService.Purge,
+		Interval: 24 * time.Hour,
+	})
+	jobWorker.Register(&worker.Job{
+		ID:       "provider-health-probe",
+		Name:     "Probe provider health for circuit breakers",
+		Handler:  providerRegistry.ProbeHealth,
+		Interval: time.Minute,
+	})
+	jobWorker.Register(&worker.Job{
+		ID:       "provider-routing-reload",
+		Name:     "Reload provider routing rules saved by other instances",
+		Handler:  providerRouting.LoadRules,
+		Interval: time.Minute,
+	})
 
 	// Initialize admin service
 	adminRepo := admin.NewRepository(database.Pool)
-	adminService := admin.NewService(adminRepo, eb, cfg.JWT.Secret, 24*time.Hour)
+	adminService := admin.NewService(adminRepo, eb, ledgerService, providerRouting, cfg.JWT.Secret, 24*time.Hour)
 	adminHandler := admin.NewHandler(adminService, cfg.JWT.Secret)
 
 	// Initialize review service
@@ -149,11 +328,11 @@ func main() {
 
 	// Initialize hotel service
 	hotelRepo := hotel.NewRepository(database.Pool)
-	hotelService := hotel.NewService(hotelRepo, hotelbedsClient)
+	hotelService := hotel.NewService(hotelRepo, providerRegistry)
 	hotelHandler := hotel.NewHandler(hotelService)
 
 	// Initialize destinations handler
-	destinationsHandler := destinations.NewHandler(database.Pool)
+	destinationsHandler := destinations.NewHandler(searchService)
 
 	// Initialize handlers
 	userHandler := user.NewHandler(userService)
@@ -197,6 +376,7 @@ func main() {
 	mux.HandleFunc("GET /api/v1/bookings/my", middleware.AuthMiddleware(jwtManager)(http.HandlerFunc(bookingHandler.GetMyBookings)).ServeHTTP)
 	mux.HandleFunc("PUT /api/v1/bookings/{id}", middleware.AuthMiddleware(jwtManager)(http.HandlerFunc(bookingHandler.UpdateBooking)).ServeHTTP)
 	mux.HandleFunc("POST /api/v1/bookings/{id}/cancel", middleware.AuthMiddleware(jwtManager)(http.HandlerFunc(bookingHandler.CancelBooking)).ServeHTTP)
+	mux.HandleFunc("GET /api/v1/bookings/{id}/invoice", middleware.AuthMiddleware(jwtManager)(http.HandlerFunc(invoiceHandler.GetBookingInvoice)).ServeHTTP)
 
 	// Payment endpoints (protected + webhook)
 	mux.HandleFunc("POST /api/v1/payments", middleware.AuthMiddleware(jwtManager)(http.HandlerFunc(paymentHandler.CreatePayment)).ServeHTTP)
@@ -256,12 +436,53 @@ func main() {
 	mux.HandleFunc("GET /api/v1/admin/providers", adminAuth(adminHandler.HandleListProviders))
 	mux.HandleFunc("GET /api/v1/admin/providers/", adminAuth(adminHandler.HandleGetProvider))
 	mux.HandleFunc("PUT /api/v1/admin/providers/", adminAuth(adminHandler.HandleUpdateProvider))
+	mux.HandleFunc("PUT /api/v1/admin/providers/{code}/breaker", adminAuth(adminHandler.HandleSetProviderBreaker))
 
 	// Analytics (requires analytics:read permission)
 	mux.HandleFunc("GET /api/v1/admin/analytics/revenue", adminAuth(adminHandler.HandleRevenueStats))
 	mux.HandleFunc("GET /api/v1/admin/analytics/users", adminAuth(adminHandler.HandleUserStats))
 	mux.HandleFunc("GET /api/v1/admin/analytics/providers", adminAuth(adminHandler.HandleProviderStats))
 
+	// Admin ledger
+	mux.HandleFunc("GET /api/v1/admin/ledger/trial-balance", adminAuth(adminHandler.HandleTrialBalance))
+	mux.HandleFunc("GET /api/v1/admin/ledger/accounts/{account}/balance", adminAuth(adminHandler.HandleAccountBalance))
+
+	// Admin supplier settlement
+	mux.HandleFunc("GET /api/v1/admin/settlements/payables", adminAuth(settlementHandler.ListPayables))
+	mux.HandleFunc("POST /api/v1/admin/settlements/payables/{id}/settle", adminAuth(settlementHandler.SettlePayable))
+	mux.HandleFunc("GET /api/v1/admin/settlements/reports/{year}/{month}", adminAuth(settlementHandler.GetReport))
+	mux.HandleFunc("POST /api/v1/admin/settlements/reconcile", adminAuth(settlementHandler.Reconcile))
+
+	// Admin invoices
+	mux.HandleFunc("GET /api/v1/admin/invoices", adminAuth(invoiceHandler.SearchInvoices))
+	mux.HandleFunc("GET /api/v1/admin/invoices/{id}", adminAuth(invoiceHandler.GetInvoice))
+
+	// Admin risk review queue
+	mux.HandleFunc("GET /api/v1/admin/risk/assessments", adminAuth(riskHandler.ListAssessments))
+	mux.HandleFunc("GET /api/v1/admin/risk/assessments/{id}", adminAuth(riskHandler.GetAssessment))
+	mux.HandleFunc("POST /api/v1/admin/risk/assessments/{id}/approve", adminAuth(riskHandler.Approve))
+	mux.HandleFunc("POST /api/v1/admin/risk/assessments/{id}/reject", adminAuth(riskHandler.Reject))
+
+	// Admin booking reconciliation
+	mux.HandleFunc("GET /api/v1/admin/reconciliation/discrepancies", adminAuth(reconciliationHandler.ListDiscrepancies))
+	mux.HandleFunc("GET /api/v1/admin/reconciliation/discrepancies/{id}", adminAuth(reconciliationHandler.GetDiscrepancy))
+	mux.HandleFunc("POST /api/v1/admin/reconciliation/discrepancies/{id}/resolve", adminAuth(reconciliationHandler.Resolve))
+	mux.HandleFunc("POST /api/v1/admin/reconciliation/run", adminAuth(reconciliationHandler.Run))
+
+	// Admin captured provider traffic
+	mux.HandleFunc("GET /api/v1/admin/bookings/{id}/provider-traffic", adminAuth(trafficHandler.ListForBooking))
+
+	// Admin hotel mapping
+	mux.HandleFunc("GET /api/v1/admin/hotel-mappings/masters", adminAuth(hotelMappingHandler.ListMasters))
+	mux.HandleFunc("GET /api/v1/admin/hotel-mappings/masters/{id}", adminAuth(hotelMappingHandler.GetMaster))
+	mux.HandleFunc("PUT /api/v1/admin/hotel-mappings/hotels/{id}", adminAuth(hotelMappingHandler.AssignHotel))
+	mux.HandleFunc("DELETE /api/v1/admin/hotel-mappings/hotels/{id}", adminAuth(hotelMappingHandler.DetachHotel))
+	mux.HandleFunc("POST /api/v1/admin/hotel-mappings/rebuild", adminAuth(hotelMappingHandler.Rebuild))
+
+	// Admin inactive hotels
+	mux.HandleFunc("GET /api/v1/admin/hotels/inactive", adminAuth(hotelHandler.ListInactiveHotels))
+	mux.HandleFunc("POST /api/v1/admin/hotels/{id}/reactivate", adminAuth(hotelHandler.ReactivateHotel))
+
 	// Audit logs
 	mux.HandleFunc("GET /api/v1/admin/audit-logs", adminAuth(adminHandler.HandleAuditLogs))
 
@@ -284,6 +505,8 @@ func main() {
 		}
 	}()
 
+	jobWorker.Start(context.Background())
+
 	logger.Info("✅ Bookingkuy API is ready!")
 	logger.Info(fmt.Sprintf("🚀 Server listening on %s:%s", cfg.Server.Host, cfg.Server.Port))
 
@@ -293,12 +516,14 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down server...")
+	jobWorker.Stop()
 
 	// Graceful shutdown
 	ctx := context.Background()
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.ErrorWithErr(err, "Server shutdown error")
 	}
+	trafficService.Stop()
 
 	logger.Info("Server stopped")
 }
@@ -44,6 +44,7 @@ type SearchHotelsRequest struct {
 	Guests   int    `json:"guests" example:"2" validate:"required,min=1,max=10"`
 	MinPrice *int   `json:"min_price,omitempty" example:"50"`
 	MaxPrice *int   `json:"max_price,omitempty" example:"500"`
+	Currency string `json:"currency,omitempty" example:"IDR"`
 }
 
 // SearchHotelResponse represents a hotel in search results
@@ -150,7 +151,18 @@ type CreateBookingRequest struct {
 	CheckIn    string `json:"check_in" example:"2025-01-15T00:00:00Z" validate:"required"`
 	CheckOut   string `json:"check_out" example:"2025-01-17T00:00:00Z" validate:"required"`
 	Guests     int    `json:"guests" example:"2" validate:"required,min=1,max=10"`
-	PaymentType string `json:"payment_type" example:"PAY_NOW" validate:"required,oneof=PAY_NOW PAY_AT_HOTEL"`
+	GuestName  string `json:"guest_name,omitempty" example:"Budi Santoso"`
+	PaymentType string `json:"payment_type" example:"PAY_NOW" validate:"required,oneof=PAY_NOW PAY_AT_HOTEL PAY_LATER"`
+	PaymentToken string `json:"payment_token,omitempty" example:"521111-1117-a1b2c3d4"`
+	Billing      *BillingInfo `json:"billing,omitempty"`
+}
+
+// BillingInfo represents company billing details for corporate invoices
+type BillingInfo struct {
+	CompanyName string `json:"company_name" example:"PT Maju Bersama" validate:"required"`
+	TaxID       string `json:"tax_id" example:"01.234.567.8-901.000" validate:"required"`
+	Address     string `json:"address,omitempty" example:"Jl. Jend. Sudirman Kav. 52-53, Jakarta"`
+	Email       string `json:"email,omitempty" example:"finance@majubersama.co.id"`
 }
 
 // BookingResponse represents booking response
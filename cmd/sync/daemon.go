@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/admin"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/config"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/ekonugroho98/be-bookingkuy/internal/sync"
+)
+
+// runDaemon runs scheduled syncs until ctx is done. Every instance serves
+// its status; only the leader syncs.
+func runDaemon(ctx context.Context, cfg *config.Config, database *db.DB, syncService *sync.Service) {
+	daemonConfig := sync.DefaultDaemonConfig()
+	daemonConfig.DestinationsSchedule = cfg.Sync.DestinationsSchedule
+	daemonConfig.HotelsSchedule = cfg.Sync.HotelsSchedule
+	daemonConfig.RoomsSchedule = cfg.Sync.RoomsSchedule
+	daemonConfig.CountryCode = cfg.Sync.CountryCode
+	daemonConfig.DeactivationThreshold = cfg.Sync.DeactivationThreshold
+	daemonConfig.Destinations = cfg.Sync.Destinations
+	if len(daemonConfig.Destinations) == 0 {
+		logger.Warn("No BOOKINGKUY_SYNC_DESTINATIONS configured, only destinations will be synced")
+	}
+
+	daemon, err := sync.NewDaemon(syncService, database, daemonConfig)
+	if err != nil {
+		log.Fatalf("Invalid sync schedule: %v", err)
+	}
+	handler := sync.NewHandler(daemon)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", handler.Status)
+	mux.HandleFunc("POST /runs/{job}", admin.RequireAdmin(cfg.JWT.Secret)(handler.TriggerRun))
+
+	srv := &http.Server{
+		Addr:              cfg.Sync.StatusAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Infof("Sync status listening on %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Sync status server failed: %v", err)
+		}
+	}()
+
+	daemon.Run(ctx)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.ErrorWithErr(err, "Sync status server shutdown error")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -27,8 +29,23 @@ func main() {
 	countryCode := flag.String("country", "ID", "Country code to sync (default: ID for Indonesia)")
 	limit := flag.Int("limit", 0, "Max records to sync (0 = unlimited)")
 	dryRun := flag.Bool("dry-run", false, "Print what would be synced without saving")
+	destination := flag.String("destination", "", "HotelBeds destination code to sync hotels of (empty = sync destinations)")
+	resume := flag.Bool("resume", false, "Resume the last unfinished run from its checkpoint")
+	since := flag.String("since", "", "Only sync hotels updated since YYYY-MM-DD, or \"last\" for since the last completed run")
+	daemon := flag.Bool("daemon", false, "Run syncs on the BOOKINGKUY_SYNC_* schedules and serve their status")
+	forceDeactivation := flag.Bool("force-deactivation", false, "Deactivate hotels missing from a complete run even above the alert threshold")
 	flag.Parse()
 
+	var sinceTime time.Time
+	incremental := *since == "last"
+	if *since != "" && !incremental {
+		t, err := time.Parse("2006-01-02", *since)
+		if err != nil {
+			log.Fatalf("Invalid --since date %q, expected YYYY-MM-DD or \"last\": %v", *since, err)
+		}
+		sinceTime = t
+	}
+
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
@@ -55,19 +72,46 @@ func main() {
 	// Initialize sync service
 	syncService := sync.NewService(database, hotelbedsClient)
 
+	// Interrupted runs are checkpointed and can be continued with --resume
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *daemon {
+		runDaemon(ctx, cfg, database, syncService)
+		return
+	}
+
 	// Run sync
-	ctx := context.Background()
+
 	opts := sync.SyncOptions{
 		CountryCode: *countryCode,
 		Limit:       *limit,
 		DryRun:      *dryRun,
+		Resume:      *resume,
+		Since:       sinceTime,
+		Incremental: incremental,
+
+		ForceDeactivation: *forceDeactivation,
+	}
+
+	title := "HotelBeds Destination Sync"
+	if *destination != "" {
+		title = "HotelBeds Hotel Sync"
 	}
 
 	fmt.Println()
 	fmt.Println("==============================================")
-	fmt.Println("   HotelBeds Destination Sync")
+	fmt.Printf("   %s\n", title)
 	fmt.Println("==============================================")
-	fmt.Printf("Country Code: %s\n", *countryCode)
+	if *destination != "" {
+		fmt.Printf("Destination: %s\n", *destination)
+	} else {
+		fmt.Printf("Country Code: %s\n", *countryCode)
+	}
+	if *since != "" {
+		fmt.Printf("Since: %s\n", *since)
+	}
+	fmt.Printf("Resume: %v\n", *resume)
 	fmt.Printf("Limit: %d (0 = unlimited)\n", *limit)
 	fmt.Printf("Dry Run: %v\n", *dryRun)
 	fmt.Println("==============================================")
@@ -75,7 +119,12 @@ func main() {
 
 	startTime := time.Now()
 
-	result, err := syncService.SyncDestinations(ctx, opts)
+	var result *sync.SyncResult
+	if *destination != "" {
+		result, err = syncService.SyncHotels(ctx, *destination, opts)
+	} else {
+		result, err = syncService.SyncDestinations(ctx, opts)
+	}
 	if err != nil {
 		log.Fatalf("Sync failed: %v", err)
 	}
@@ -92,6 +141,7 @@ func main() {
 	fmt.Printf("Updated:     %d\n", result.Updated)
 	fmt.Printf("Failed:      %d\n", result.Failed)
 	fmt.Printf("Skipped:     %d\n", result.Skipped)
+	fmt.Printf("Deactivated: %d\n", result.Deactivated)
 	fmt.Printf("Duration:    %v\n", duration)
 	fmt.Printf("Service:     %v\n", result.Duration)
 	fmt.Println("==============================================")
@@ -107,9 +157,16 @@ func main() {
 		}
 	}
 
+	if len(result.Alerts) > 0 {
+		fmt.Printf("\n🚨 Alerts (%d):\n", len(result.Alerts))
+		for _, alert := range result.Alerts {
+			fmt.Printf("  - %s\n", alert)
+		}
+	}
+
 	fmt.Println()
 
-	if result.Failed > 0 {
+	if result.Failed > 0 || len(result.Alerts) > 0 {
 		os.Exit(1)
 	}
 }
@@ -3,12 +3,15 @@ package admin
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ekonugroho98/be-bookingkuy/internal/ledger"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
 )
 
@@ -26,37 +29,45 @@ func NewHandler(service Service, jwtSecret string) *Handler {
 
 // AuthMiddleware validates JWT token and extracts admin info
 func (h *Handler) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// Get token from Authorization header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
-			return
-		}
+	return RequireAdmin(h.jwtSecret)(next)
+}
 
-		// Check Bearer format
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
-			return
-		}
+// RequireAdmin returns middleware admitting requests with a valid admin
+// token, for servers without an admin Handler
+func RequireAdmin(jwtSecret string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			// Get token from Authorization header
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+				return
+			}
 
-		token := parts[1]
+			// Check Bearer format
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
+				return
+			}
 
-		// Validate token
-		adminID, role, err := ValidateToken(token, h.jwtSecret)
-		if err != nil {
-			logger.ErrorWithErr(err, "Token validation failed")
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-			return
-		}
+			token := parts[1]
 
-		// Add admin info to context
-		ctx := r.Context()
-		ctx = contextWithAdminInfo(ctx, adminID, role)
-		r = r.WithContext(ctx)
+			// Validate token
+			adminID, role, err := ValidateToken(token, jwtSecret)
+			if err != nil {
+				logger.ErrorWithErr(err, "Token validation failed")
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
 
-		next(w, r)
+			// Add admin info to context
+			ctx := r.Context()
+			ctx = contextWithAdminInfo(ctx, adminID, role)
+			r = r.WithContext(ctx)
+
+			next(w, r)
+		}
 	}
 }
 
@@ -695,6 +706,10 @@ func (h *Handler) HandleUpdateProvider(w http.ResponseWriter, r *http.Request) {
 
 	err = h.service.UpdateProvider(r.Context(), adminID, code, &req, ipAddress, userAgent)
 	if err != nil {
+		if errors.Is(err, provider.ErrProviderNotFound) {
+			writeError(w, http.StatusNotFound, "Provider not found")
+			return
+		}
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -702,6 +717,47 @@ func (h *Handler) HandleUpdateProvider(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"message": "Provider updated successfully"})
 }
 
+// Handler: PUT /api/v1/admin/providers/{code}/breaker
+func (h *Handler) HandleSetProviderBreaker(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	code := r.PathValue("code")
+	if code == "" {
+		writeError(w, http.StatusBadRequest, "Provider code required")
+		return
+	}
+
+	var req SetProviderBreakerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminID, _, err := extractAdminInfo(r.Context())
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ipAddress := getIPAddress(r)
+	userAgent := r.Header.Get("User-Agent")
+
+	info, err := h.service.SetProviderBreaker(r.Context(), adminID, code, &req, ipAddress, userAgent)
+	if err != nil {
+		if errors.Is(err, provider.ErrProviderNotFound) {
+			writeError(w, http.StatusNotFound, "Provider not found")
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
+
 // Handler: GET /api/v1/admin/analytics/revenue
 func (h *Handler) HandleRevenueStats(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -791,6 +847,73 @@ func (h *Handler) HandleProviderStats(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, stats)
 }
 
+// Handler: GET /api/v1/admin/ledger/trial-balance
+func (h *Handler) HandleTrialBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Default to now; as_of includes the whole day
+	asOf := time.Now()
+	if asOfStr := r.URL.Query().Get("as_of"); asOfStr != "" {
+		t, err := time.Parse("2006-01-02", asOfStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid as_of date")
+			return
+		}
+		asOf = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	tb, err := h.service.GetTrialBalance(r.Context(), asOf)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to get trial balance")
+		writeError(w, http.StatusInternalServerError, "Failed to get trial balance")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tb)
+}
+
+// Handler: GET /api/v1/admin/ledger/accounts/{account}/balance
+func (h *Handler) HandleAccountBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Default to all time up to now
+	startDate := time.Time{}
+	endDate := time.Now()
+
+	if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
+		t, err := time.Parse("2006-01-02", startDateStr)
+		if err == nil {
+			startDate = t
+		}
+	}
+
+	if endDateStr := r.URL.Query().Get("end_date"); endDateStr != "" {
+		t, err := time.Parse("2006-01-02", endDateStr)
+		if err == nil {
+			endDate = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+
+	balances, err := h.service.GetAccountBalance(r.Context(), r.PathValue("account"), startDate, endDate)
+	if err != nil {
+		if errors.Is(err, ledger.ErrUnknownAccount) {
+			writeError(w, http.StatusNotFound, "Account not found")
+			return
+		}
+		logger.ErrorWithErr(err, "Failed to get account balance")
+		writeError(w, http.StatusInternalServerError, "Failed to get account balance")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, balances)
+}
+
 // Handler: GET /api/v1/admin/audit-logs
 func (h *Handler) HandleAuditLogs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -852,3 +975,9 @@ func extractAdminInfo(ctx context.Context) (string, AdminRole, error) {
 
 	return adminID, AdminRole(roleStr), nil
 }
+
+// AdminIDFromContext returns the ID of the admin authenticated by AuthMiddleware
+func AdminIDFromContext(ctx context.Context) (string, bool) {
+	adminID, ok := ctx.Value(adminIDKey).(string)
+	return adminID, ok
+}
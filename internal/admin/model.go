@@ -2,6 +2,8 @@ package admin
 
 import (
 	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
 )
 
 // Admin represents an admin user in the system
@@ -185,23 +187,32 @@ type ProviderConfig struct {
 
 // UpdateProviderRequest represents a request to update provider config
 type UpdateProviderRequest struct {
-	APIKey         *string `json:"api_key,omitempty"`
-	APISecret      *string `json:"api_secret,omitempty"`
-	BaseURL        *string `json:"base_url,omitempty"`
-	IsActive       *bool   `json:"is_active,omitempty"`
-	RateLimitRPS   *int    `json:"rate_limit_rps,omitempty" validate:"omitempty,min=1,max=100"`
-	TimeoutSeconds *int    `json:"timeout_seconds,omitempty" validate:"omitempty,min=1,max=60"`
+	APIKey         *string               `json:"api_key,omitempty"`
+	APISecret      *string               `json:"api_secret,omitempty"`
+	BaseURL        *string               `json:"base_url,omitempty"`
+	IsActive       *bool                 `json:"is_active,omitempty"`
+	RateLimitRPS   *int                  `json:"rate_limit_rps,omitempty" validate:"omitempty,min=1,max=100"`
+	TimeoutSeconds *int                  `json:"timeout_seconds,omitempty" validate:"omitempty,min=1,max=60"`
+	Priority       *int                  `json:"priority,omitempty" validate:"omitempty,min=0"`
+	Weight         *int                  `json:"weight,omitempty" validate:"omitempty,min=0"`
+	Rules          *[]provider.RuleInput `json:"rules,omitempty"` // Replaces the rules by country, destination or hotel
+}
+
+// SetProviderBreakerRequest represents a request to force a provider's circuit breaker
+type SetProviderBreakerRequest struct {
+	Override        string `json:"override" validate:"required,oneof=OPEN CLOSED AUTO"` // AUTO hands the breaker back to call outcomes
+	DurationMinutes int    `json:"duration_minutes,omitempty" validate:"omitempty,min=1,max=1440"` // How long OPEN or CLOSED holds, 60 when unset
 }
 
 // DashboardStats represents dashboard statistics
 type DashboardStats struct {
-	TotalUsers      int64 `json:"total_users"`
-	TotalBookings   int64 `json:"total_bookings"`
-	TotalRevenue    int64 `json:"total_revenue"` // In cents/rupiah
-	TodayUsers      int64 `json:"today_users"`
-	TodayBookings   int64 `json:"today_bookings"`
-	TodayRevenue    int64 `json:"today_revenue"`
-	ActiveProviders int   `json:"active_providers"`
+	TotalUsers      int64            `json:"total_users"`
+	TotalBookings   int64            `json:"total_bookings"`
+	TotalRevenue    map[string]int64 `json:"total_revenue"` // Key: currency, in minor units
+	TodayUsers      int64            `json:"today_users"`
+	TodayBookings   int64            `json:"today_bookings"`
+	TodayRevenue    map[string]int64 `json:"today_revenue"` // Key: currency
+	ActiveProviders int              `json:"active_providers"`
 }
 
 // BookingStats represents booking statistics
@@ -216,9 +227,13 @@ type BookingStats struct {
 	ByDate           map[string]int64 `json:"by_date"` // Key: YYYY-MM-DD
 }
 
-// RevenueStats represents revenue statistics
+// RevenueStats represents revenue statistics in one currency
 type RevenueStats struct {
-	TotalRevenue      int64            `json:"total_revenue"`
+	Currency          string           `json:"currency"`
+	TotalRevenue      int64            `json:"total_revenue"` // Margin posted to the revenue account
+	Refunds           int64            `json:"refunds"`
+	Fees              int64            `json:"fees"`
+	NetRevenue        int64            `json:"net_revenue"` // Revenue minus refunds and fees
 	ThisMonthRevenue  int64            `json:"this_month_revenue"`
 	LastMonthRevenue  int64            `json:"last_month_revenue"`
 	ByPaymentMethod   map[string]int64 `json:"by_payment_method"`
@@ -29,7 +29,6 @@ type Repository interface {
 	// Statistics
 	GetDashboardStats(ctx context.Context) (*DashboardStats, error)
 	GetBookingStats(ctx context.Context, startDate, endDate time.Time) (*BookingStats, error)
-	GetRevenueStats(ctx context.Context, startDate, endDate time.Time) (*RevenueStats, error)
 	GetUserStats(ctx context.Context, startDate, endDate time.Time) (*UserStats, error)
 	GetProviderStats(ctx context.Context) ([]*ProviderStats, error)
 }
@@ -394,7 +393,8 @@ func (r *repository) ListAuditLogs(ctx context.Context, adminID, entityType, ent
 	return logs, total, nil
 }
 
-// GetDashboardStats retrieves dashboard statistics
+// GetDashboardStats retrieves dashboard statistics.
+// Revenue figures are filled in by the service from the ledger.
 func (r *repository) GetDashboardStats(ctx context.Context) (*DashboardStats, error) {
 	var stats DashboardStats
 
@@ -410,12 +410,6 @@ func (r *repository) GetDashboardStats(ctx context.Context) (*DashboardStats, er
 		return nil, fmt.Errorf("failed to get total bookings: %w", err)
 	}
 
-	// Get total revenue (successful payments)
-	err = r.db.QueryRow(ctx, `SELECT COALESCE(SUM(amount), 0) FROM payments WHERE status = 'success'`).Scan(&stats.TotalRevenue)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get total revenue: %w", err)
-	}
-
 	// Get today's stats
 	today := time.Now().Format("2006-01-02")
 
@@ -435,14 +429,6 @@ func (r *repository) GetDashboardStats(ctx context.Context) (*DashboardStats, er
 		return nil, fmt.Errorf("failed to get today bookings: %w", err)
 	}
 
-	err = r.db.QueryRow(ctx, `
-		SELECT COALESCE(SUM(amount), 0) FROM payments
-		WHERE DATE(created_at) = $1 AND status = 'success'
-	`, today).Scan(&stats.TodayRevenue)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get today revenue: %w", err)
-	}
-
 	// Get active providers count
 	err = r.db.QueryRow(ctx, `SELECT COUNT(DISTINCT provider_code) FROM hotels WHERE is_active = true`).Scan(&stats.ActiveProviders)
 	if err != nil {
@@ -522,105 +508,6 @@ func (r *repository) GetBookingStats(ctx context.Context, startDate, endDate tim
 	return &stats, nil
 }
 
-// GetRevenueStats retrieves revenue statistics
-func (r *repository) GetRevenueStats(ctx context.Context, startDate, endDate time.Time) (*RevenueStats, error) {
-	var stats RevenueStats
-
-	// Get total revenue
-	err := r.db.QueryRow(ctx, `
-		SELECT COALESCE(SUM(amount), 0)
-		FROM payments
-		WHERE status = 'success' AND created_at >= $1 AND created_at <= $2
-	`, startDate, endDate).Scan(&stats.TotalRevenue)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get total revenue: %w", err)
-	}
-
-	// Get this month revenue
-	now := time.Now()
-	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
-
-	err = r.db.QueryRow(ctx, `
-		SELECT COALESCE(SUM(amount), 0)
-		FROM payments
-		WHERE status = 'success' AND created_at >= $1
-	`, firstOfMonth).Scan(&stats.ThisMonthRevenue)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get this month revenue: %w", err)
-	}
-
-	// Get last month revenue
-	lastMonth := firstOfMonth.AddDate(0, -1, 0)
-	firstOfLastMonth := time.Date(lastMonth.Year(), lastMonth.Month(), 1, 0, 0, 0, 0, time.UTC)
-
-	err = r.db.QueryRow(ctx, `
-		SELECT COALESCE(SUM(amount), 0)
-		FROM payments
-		WHERE status = 'success' AND created_at >= $1 AND created_at < $2
-	`, firstOfLastMonth, firstOfMonth).Scan(&stats.LastMonthRevenue)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get last month revenue: %w", err)
-	}
-
-	// Get by payment method
-	stats.ByPaymentMethod = make(map[string]int64)
-	pmRows, err := r.db.Query(ctx, `
-		SELECT method, COALESCE(SUM(amount), 0)
-		FROM payments
-		WHERE status = 'success' AND created_at >= $1 AND created_at <= $2
-		GROUP BY method
-	`, startDate, endDate)
-	if err == nil {
-		defer pmRows.Close()
-		for pmRows.Next() {
-			var method string
-			var amount int64
-			pmRows.Scan(&method, &amount)
-			stats.ByPaymentMethod[method] = amount
-		}
-	}
-
-	// Get by provider
-	stats.ByProvider = make(map[string]int64)
-	provRows, err := r.db.Query(ctx, `
-		SELECT b.provider_code, COALESCE(SUM(p.amount), 0)
-		FROM bookings b
-		JOIN payments p ON p.booking_id = b.id
-		WHERE p.status = 'success' AND p.created_at >= $1 AND p.created_at <= $2
-		GROUP BY b.provider_code
-	`, startDate, endDate)
-	if err == nil {
-		defer provRows.Close()
-		for provRows.Next() {
-			var provider string
-			var amount int64
-			provRows.Scan(&provider, &amount)
-			stats.ByProvider[provider] = amount
-		}
-	}
-
-	// Get by date
-	stats.ByDate = make(map[string]int64)
-	dateRows, err := r.db.Query(ctx, `
-		SELECT DATE(created_at) as date, COALESCE(SUM(amount), 0)
-		FROM payments
-		WHERE status = 'success' AND created_at >= $1 AND created_at <= $2
-		GROUP BY DATE(created_at)
-		ORDER BY date
-	`, startDate, endDate)
-	if err == nil {
-		defer dateRows.Close()
-		for dateRows.Next() {
-			var dateStr string
-			var amount int64
-			dateRows.Scan(&dateStr, &amount)
-			stats.ByDate[dateStr] = amount
-		}
-	}
-
-	return &stats, nil
-}
-
 // GetUserStats retrieves user statistics
 func (r *repository) GetUserStats(ctx context.Context, startDate, endDate time.Time) (*UserStats, error) {
 	var stats UserStats
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ekonugroho98/be-bookingkuy/internal/ledger"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
 	"github.com/golang-jwt/jwt/v5"
@@ -43,14 +45,19 @@ type Service interface {
 	ListProviders(ctx context.Context) ([]*ProviderInfo, error)
 	GetProvider(ctx context.Context, code string) (*ProviderInfo, error)
 	UpdateProvider(ctx context.Context, adminID, providerCode string, req *UpdateProviderRequest, ipAddress, userAgent string) error
+	SetProviderBreaker(ctx context.Context, adminID, providerCode string, req *SetProviderBreakerRequest, ipAddress, userAgent string) (*ProviderInfo, error)
 
 	// Analytics
 	GetDashboardStats(ctx context.Context) (*DashboardStats, error)
 	GetBookingStats(ctx context.Context, startDate, endDate time.Time) (*BookingStats, error)
-	GetRevenueStats(ctx context.Context, startDate, endDate time.Time) (*RevenueStats, error)
+	GetRevenueStats(ctx context.Context, startDate, endDate time.Time) (map[string]*RevenueStats, error)
 	GetUserStats(ctx context.Context, startDate, endDate time.Time) (*UserStats, error)
 	GetProviderStats(ctx context.Context) ([]*ProviderStats, error)
 
+	// Ledger
+	GetTrialBalance(ctx context.Context, asOf time.Time) (*ledger.TrialBalance, error)
+	GetAccountBalance(ctx context.Context, account string, startDate, endDate time.Time) ([]*ledger.Balance, error)
+
 	// Audit logs
 	ListAuditLogs(ctx context.Context, adminID, entityType, entityID string, limit, offset int) ([]*AuditLog, int, error)
 }
@@ -86,26 +93,34 @@ type BookingData struct {
 
 // ProviderInfo represents provider information
 type ProviderInfo struct {
-	ProviderCode   string `json:"provider_code"`
-	Name           string `json:"name"`
-	IsActive       bool   `json:"is_active"`
-	BaseURL        string `json:"base_url"`
-	RateLimitRPS   int    `json:"rate_limit_rps"`
-	TimeoutSeconds int    `json:"timeout_seconds"`
+	ProviderCode   string                  `json:"provider_code"`
+	Name           string                  `json:"name"`
+	IsActive       bool                    `json:"is_active"`
+	BaseURL        string                  `json:"base_url"`
+	RateLimitRPS   int                     `json:"rate_limit_rps"`
+	TimeoutSeconds int                     `json:"timeout_seconds"`
+	Priority       int                     `json:"priority"`
+	Weight         int                     `json:"weight"`
+	Rules          []*provider.RoutingRule `json:"rules"`
+	Health         provider.HealthStatus   `json:"health"`
 }
 
 type service struct {
 	repo       Repository
 	eventBus   eventbus.EventBus
+	ledger     ledger.Service
+	routing    provider.RoutingService
 	jwtSecret  string
 	jwtExpiry  time.Duration
 }
 
 // NewService creates a new admin service
-func NewService(repo Repository, eb eventbus.EventBus, jwtSecret string, jwtExpiry time.Duration) Service {
+func NewService(repo Repository, eb eventbus.EventBus, ledgerService ledger.Service, routing provider.RoutingService, jwtSecret string, jwtExpiry time.Duration) Service {
 	return &service{
 		repo:      repo,
 		eventBus:  eb,
+		ledger:    ledgerService,
+		routing:   routing,
 		jwtSecret: jwtSecret,
 		jwtExpiry: jwtExpiry,
 	}
@@ -483,17 +498,29 @@ func (s *service) UpdateBooking(ctx context.Context, adminID, bookingID string,
 
 // ListProviders returns a list of providers
 func (s *service) ListProviders(ctx context.Context) ([]*ProviderInfo, error) {
-	// Placeholder - would return from providers table
-	return []*ProviderInfo{}, nil
+	routings, err := s.routing.ListProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := make([]*ProviderInfo, 0, len(routings))
+	for _, routing := range routings {
+		providers = append(providers, toProviderInfo(routing))
+	}
+	return providers, nil
 }
 
 // GetProvider returns provider info
 func (s *service) GetProvider(ctx context.Context, code string) (*ProviderInfo, error) {
-	// Placeholder
-	return nil, errors.New("not implemented yet")
+	routing, err := s.routing.GetProvider(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return toProviderInfo(routing), nil
 }
 
-// UpdateProvider updates provider configuration
+// UpdateProvider updates provider routing: whether it is used, its priority
+// and weight, and its rules by country, destination or hotel
 func (s *service) UpdateProvider(ctx context.Context, adminID, providerCode string, req *UpdateProviderRequest, ipAddress, userAgent string) error {
 	// Get requesting admin
 	requestingAdmin, err := s.repo.GetAdminByID(ctx, adminID)
@@ -506,15 +533,157 @@ func (s *service) UpdateProvider(ctx context.Context, adminID, providerCode stri
 		return errors.New("insufficient permissions")
 	}
 
-	// Placeholder - would update provider config
-	logger.Infof("Provider update requested: %s by %s", providerCode, requestingAdmin.Email)
+	// Credentials and connection settings come from the service configuration
+	if req.APIKey != nil || req.APISecret != nil || req.BaseURL != nil || req.RateLimitRPS != nil || req.TimeoutSeconds != nil {
+		return errors.New("credentials, base_url, rate_limit_rps and timeout_seconds are set in the service configuration")
+	}
+
+	if req.IsActive == nil && req.Priority == nil && req.Weight == nil && req.Rules == nil {
+		return errors.New("no fields to update")
+	}
+
+	current, err := s.routing.GetProvider(ctx, providerCode)
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.routing.UpdateProvider(ctx, providerCode, &provider.RoutingUpdate{
+		Enabled:  req.IsActive,
+		Priority: req.Priority,
+		Weight:   req.Weight,
+		Rules:    req.Rules,
+	}, requestingAdmin.Email)
+	if err != nil {
+		return err
+	}
+
+	// Create audit log
+	auditLog := &AuditLog{
+		AdminID:    adminID,
+		AdminEmail: requestingAdmin.Email,
+		Action:     "provider.updated",
+		EntityType: "provider",
+		EntityID:   providerCode,
+		OldValues:  routingValues(current),
+		NewValues:  routingValues(updated),
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+	}
+	_ = s.repo.CreateAuditLog(ctx, auditLog)
+
+	logger.Infof("Provider routing updated: %s by %s", providerCode, requestingAdmin.Email)
 
 	return nil
 }
 
+// SetProviderBreaker forces a provider's circuit breaker open or closed, or
+// hands it back to automatic control
+func (s *service) SetProviderBreaker(ctx context.Context, adminID, providerCode string, req *SetProviderBreakerRequest, ipAddress, userAgent string) (*ProviderInfo, error) {
+	// Get requesting admin
+	requestingAdmin, err := s.repo.GetAdminByID(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check permission
+	if !requestingAdmin.Role.HasPermission(PermissionProviderWrite) {
+		return nil, errors.New("insufficient permissions")
+	}
+
+	if req.Override == "" {
+		return nil, errors.New("override is required")
+	}
+
+	current, err := s.routing.GetProvider(ctx, providerCode)
+	if err != nil {
+		return nil, err
+	}
+
+	override := provider.BreakerOverride(req.Override)
+	if req.Override == "AUTO" {
+		override = provider.OverrideNone
+	}
+
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	updated, err := s.routing.OverrideBreaker(ctx, providerCode, override, duration, requestingAdmin.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create audit log
+	auditLog := &AuditLog{
+		AdminID:    adminID,
+		AdminEmail: requestingAdmin.Email,
+		Action:     "provider.breaker_overridden",
+		EntityType: "provider",
+		EntityID:   providerCode,
+		OldValues:  map[string]interface{}{"override": current.Health.Override, "state": current.Health.State},
+		NewValues:  map[string]interface{}{"override": updated.Health.Override, "state": updated.Health.State, "expires_at": updated.Health.OverrideExpiresAt},
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+	}
+	_ = s.repo.CreateAuditLog(ctx, auditLog)
+
+	logger.Infof("Provider circuit breaker set to %s: %s by %s", req.Override, providerCode, requestingAdmin.Email)
+
+	return toProviderInfo(updated), nil
+}
+
+func toProviderInfo(routing *provider.ProviderRouting) *ProviderInfo {
+	return &ProviderInfo{
+		ProviderCode:   routing.Code,
+		Name:           routing.Code,
+		IsActive:       routing.Configured && routing.Enabled,
+		BaseURL:        routing.BaseURL,
+		TimeoutSeconds: routing.TimeoutSeconds,
+		Priority:       routing.Priority,
+		Weight:         routing.Weight,
+		Rules:          routing.Rules,
+		Health:         routing.Health,
+	}
+}
+
+func routingValues(routing *provider.ProviderRouting) map[string]interface{} {
+	return map[string]interface{}{
+		"enabled":  routing.Enabled,
+		"priority": routing.Priority,
+		"weight":   routing.Weight,
+		"rules":    routing.Rules,
+	}
+}
+
 // GetDashboardStats returns dashboard statistics
 func (s *service) GetDashboardStats(ctx context.Context) (*DashboardStats, error) {
-	return s.repo.GetDashboardStats(ctx)
+	stats, err := s.repo.GetDashboardStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Revenue comes from the ledger
+	now := time.Now()
+	total, err := s.ledger.GetRevenueSummary(ctx, time.Time{}, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total revenue: %w", err)
+	}
+	stats.TotalRevenue = netRevenue(total)
+
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	today, err := s.ledger.GetRevenueSummary(ctx, startOfDay, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get today revenue: %w", err)
+	}
+	stats.TodayRevenue = netRevenue(today)
+
+	return stats, nil
+}
+
+// netRevenue picks the net revenue out of per-currency summaries
+func netRevenue(summaries map[string]*ledger.RevenueSummary) map[string]int64 {
+	revenue := make(map[string]int64, len(summaries))
+	for currency, summary := range summaries {
+		revenue[currency] = summary.NetRevenue
+	}
+	return revenue
 }
 
 // GetBookingStats returns booking statistics
@@ -522,9 +691,69 @@ func (s *service) GetBookingStats(ctx context.Context, startDate, endDate time.T
 	return s.repo.GetBookingStats(ctx, startDate, endDate)
 }
 
-// GetRevenueStats returns revenue statistics
-func (s *service) GetRevenueStats(ctx context.Context, startDate, endDate time.Time) (*RevenueStats, error) {
-	return s.repo.GetRevenueStats(ctx, startDate, endDate)
+// GetRevenueStats returns revenue statistics from the ledger, keyed by currency
+func (s *service) GetRevenueStats(ctx context.Context, startDate, endDate time.Time) (map[string]*RevenueStats, error) {
+	period, err := s.ledger.GetRevenueSummary(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revenue: %w", err)
+	}
+
+	now := time.Now()
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	thisMonth, err := s.ledger.GetRevenueSummary(ctx, firstOfMonth, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get this month revenue: %w", err)
+	}
+
+	firstOfLastMonth := firstOfMonth.AddDate(0, -1, 0)
+	lastMonth, err := s.ledger.GetRevenueSummary(ctx, firstOfLastMonth, firstOfMonth.Add(-time.Nanosecond))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last month revenue: %w", err)
+	}
+
+	stats := make(map[string]*RevenueStats, len(period))
+	statsFor := func(currency string) *RevenueStats {
+		st, ok := stats[currency]
+		if !ok {
+			st = &RevenueStats{
+				Currency:        currency,
+				ByPaymentMethod: map[string]int64{},
+				ByProvider:      map[string]int64{},
+				ByDate:          map[string]int64{},
+			}
+			stats[currency] = st
+		}
+		return st
+	}
+
+	for currency, summary := range period {
+		st := statsFor(currency)
+		st.TotalRevenue = summary.Revenue
+		st.Refunds = summary.Refunds
+		st.Fees = summary.Fees
+		st.NetRevenue = summary.NetRevenue
+		st.ByPaymentMethod = summary.ByPaymentMethod
+		st.ByProvider = summary.ByProvider
+		st.ByDate = summary.ByDate
+	}
+	for currency, summary := range thisMonth {
+		statsFor(currency).ThisMonthRevenue = summary.NetRevenue
+	}
+	for currency, summary := range lastMonth {
+		statsFor(currency).LastMonthRevenue = summary.NetRevenue
+	}
+
+	return stats, nil
+}
+
+// GetTrialBalance returns the ledger trial balance
+func (s *service) GetTrialBalance(ctx context.Context, asOf time.Time) (*ledger.TrialBalance, error) {
+	return s.ledger.GetTrialBalance(ctx, asOf)
+}
+
+// GetAccountBalance returns a ledger account balance per currency for a period
+func (s *service) GetAccountBalance(ctx context.Context, account string, startDate, endDate time.Time) ([]*ledger.Balance, error) {
+	return s.ledger.GetBalance(ctx, ledger.AccountCode(account), startDate, endDate)
 }
 
 // GetUserStats returns user statistics
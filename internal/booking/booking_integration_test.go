@@ -7,6 +7,7 @@ import (
 
 	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
 	"github.com/ekonugroho98/be-bookingkuy/internal/pricing"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
 	"github.com/ekonugroho98/be-bookingkuy/internal/testutil"
 	"github.com/stretchr/testify/mock"
@@ -32,12 +33,14 @@ func TestBookingFlow_EndToEnd(t *testing.T) {
 		"test-secret",
 		"https://mock-hotelbeds.com",
 	)
+	providers := provider.NewRegistry()
+	providers.Register(provider.NewHotelbedsProviderWithClient(hotelbedsClient))
 
 	// Setup: Create mock repository
 	mockRepo := new(MockRepository)
 
 	// Setup: Create booking service
-	service := NewService(mockRepo, eventBus, pricingService, hotelbedsClient)
+	service := NewService(mockRepo, eventBus, pricingService, providers)
 
 	// Setup: Create test user
 	userID := testutil.GetTestUserID()
@@ -89,14 +92,14 @@ func TestBookingService_Integration(t *testing.T) {
 	// Setup: Create pricing service
 	pricingService := pricing.NewService()
 
-	// Setup: Create mock HotelBeds client
-	mockHotelbedsClient := new(MockHotelbedsClient)
+	// Setup: Create mock provider gateway
+	mockProviders := new(MockProviderGateway)
 
 	// Setup: Create mock repository
 	mockRepo := new(MockRepository)
 
 	// Setup: Create booking service
-	service := NewService(mockRepo, eventBus, pricingService, mockHotelbedsClient)
+	service := NewService(mockRepo, eventBus, pricingService, mockProviders)
 
 	// Setup: Create test user
 	userID := testutil.GetTestUserID()
@@ -120,39 +123,10 @@ func TestBookingService_Integration(t *testing.T) {
 		}
 
 		// Setup: Mock HotelBeds availability
-		mockHotelbedsClient.On("GetHotelAvailability", ctx, mock.AnythingOfType("*hotelbeds.AvailabilityRequest")).Return(&hotelbeds.AvailabilityResponse{
-			HotelCode:   testutil.GetTestHotelID(),
-			HotelName:   "Test Hotel",
-			IsAvailable: true,
-			Rooms: []hotelbeds.Room{
-				{
-					RoomCode:  testutil.GetTestRoomID(),
-					RoomName:  "Deluxe Room",
-					Available: true,
-					Price:     1500000,
-					Currency:  "IDR",
-				},
-			},
-			TotalPrice: 1500000,
-			Currency:   "IDR",
-		}, nil)
-
-		mockHotelbedsClient.On("GetRoomRates", ctx, mock.AnythingOfType("*hotelbeds.RoomRateRequest")).Return(&hotelbeds.RoomRateResponse{
-			HotelCode:  testutil.GetTestHotelID(),
-			RoomCode:   testutil.GetTestRoomID(),
-			RoomName:   "Deluxe Room",
-			TotalPrice: 1500000,
-			Currency:   "IDR",
-			Rates: []hotelbeds.Rate{
-				{
-					RateCode: "RATE-1",
-					Price:    1500000,
-					Currency: "IDR",
-				},
-			},
-		}, nil)
+		setupAvailableRoom(supplierCtx, mockProviders, createReq, 1500000)
 
 		// Setup: Mock repository
+		mockRepo.On("GetHotelCategory", ctx, DefaultProviderCode, createReq.HotelID).Return(pricing.CategoryFourStar, nil)
 		mockRepo.On("Create", ctx, mock.AnythingOfType("*booking.Booking")).Return(nil)
 		mockRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), StatusAwaitingPayment).Return(nil)
 
@@ -165,14 +139,15 @@ func TestBookingService_Integration(t *testing.T) {
 		require.Equal(t, testutil.GetTestRoomID(), newBooking.RoomID)
 		require.Equal(t, StatusAwaitingPayment, newBooking.Status)
 		require.NotEqual(t, 1000000, newBooking.TotalAmount, "Price should be real from HotelBeds API!")
-		require.Equal(t, 1500000, newBooking.TotalAmount, "Price should match HotelBeds mock response")
+		require.Equal(t, 1500000, newBooking.NetAmount, "Price should match HotelBeds mock response")
+		require.Equal(t, 1770000, newBooking.TotalAmount, "Sell price should carry the four-star markup")
 		require.Equal(t, "IDR", newBooking.Currency)
 
 		t.Logf("✅ Booking created: ID=%s, Reference=%s, Amount=%d %s",
 			newBooking.ID, newBooking.BookingReference, newBooking.TotalAmount, newBooking.Currency)
 
 		// Verify mocks were called
-		mockHotelbedsClient.AssertExpectations(t)
+		mockProviders.AssertExpectations(t)
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -229,6 +204,7 @@ func TestBookingService_Integration(t *testing.T) {
 			ID:                bookingID,
 			UserID:            userID,
 			Status:            StatusConfirmed,
+			ProviderCode:      DefaultProviderCode,
 			SupplierReference: "HB-SUPPLIER-123",
 		}
 
@@ -236,7 +212,7 @@ func TestBookingService_Integration(t *testing.T) {
 		mockRepo.On("UpdateStatus", ctx, bookingID, StatusCancelled).Return(nil)
 
 		// Setup: Mock HotelBeds cancellation
-		mockHotelbedsClient.On("CancelBooking", ctx, "HB-SUPPLIER-123").Return(nil)
+		mockProviders.On("CancelBooking", supplierCtx, DefaultProviderCode, "HB-SUPPLIER-123").Return(nil)
 
 		// Execute: Cancel booking
 		cancelledBooking, err := service.CancelBooking(ctx, bookingID)
@@ -247,7 +223,7 @@ func TestBookingService_Integration(t *testing.T) {
 		t.Logf("✅ Booking cancelled: ID=%s, Status=%s", cancelledBooking.ID, cancelledBooking.Status)
 
 		// Verify mocks were called
-		mockHotelbedsClient.AssertExpectations(t)
+		mockProviders.AssertExpectations(t)
 		mockRepo.AssertExpectations(t)
 	})
 }
@@ -3,6 +3,8 @@ package booking
 import (
 	"fmt"
 	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
 )
 
 // BookingResponse represents the booking response format for frontend
@@ -15,6 +17,7 @@ type BookingResponse struct {
 	City          string `json:"city"`           // ✅ FE expects this
 	RoomID        string `json:"room_id"`
 	RoomName      string `json:"roomName"`       // ✅ FE: camelCase
+	RoomImage     string `json:"roomImage,omitempty"`
 	CheckIn       string `json:"checkIn"`        // ✅ FE: camelCase, formatted string
 	CheckOut      string `json:"checkOut"`       // ✅ FE: camelCase, formatted string
 	Guests        int    `json:"guests"`         // ✅ Keep original for API
@@ -37,7 +40,7 @@ type BookingResponse struct {
 	UpdatedAt        string `json:"updated_at"`   // ✅ Formatted string
 }
 
-// HotelDetails represents hotel information from HotelBeds
+// HotelDetails represents hotel information from the provider
 type HotelDetails struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
@@ -48,13 +51,38 @@ type HotelDetails struct {
 	Description string `json:"description,omitempty"`
 }
 
-// RoomDetails represents room information from HotelBeds
+// toHotelDetails maps provider hotel content to the booking response shape
+func toHotelDetails(h *types.Hotel) *HotelDetails {
+	details := &HotelDetails{
+		ID:          h.ID,
+		Name:        h.Name,
+		City:        h.City,
+		Country:     h.CountryCode,
+		Rating:      h.Rating,
+		Description: h.Description,
+	}
+	// Get first image if available
+	if len(h.Images) > 0 {
+		details.Image = h.Images[0]
+	}
+	return details
+}
+
+// RoomDetails represents room information from the synced room catalog
 type RoomDetails struct {
 	ID         string `json:"id"`
 	Name       string `json:"name"`
 	Image      string `json:"image,omitempty"`
 }
 
+// AccountHolder represents the contact details of the user who made a booking
+type AccountHolder struct {
+	FirstName string
+	LastName  string
+	Email     string
+	Phone     string
+}
+
 // ToBookingResponse converts Booking model to BookingResponse for frontend
 func ToBookingResponse(b *Booking, hotel *HotelDetails, room *RoomDetails) *BookingResponse {
 	if b == nil {
@@ -105,6 +133,7 @@ func ToBookingResponse(b *Booking, hotel *HotelDetails, room *RoomDetails) *Book
 	// Add room details if available
 	if room != nil {
 		response.RoomName = room.Name
+		response.RoomImage = room.Image
 	}
 
 	return response
@@ -113,10 +142,10 @@ func ToBookingResponse(b *Booking, hotel *HotelDetails, room *RoomDetails) *Book
 // formatStatusForFE converts backend status to frontend-friendly format
 func formatStatusForFE(status BookingStatus) string {
 	// Frontend expects: 'Confirmed' | 'Pending' | 'Cancelled'
-	// Backend has: INIT, AWAITING_PAYMENT, PAID, CONFIRMED, COMPLETED, CANCELLED
+	// Backend has: INIT, ON_HOLD, GUARANTEED, AWAITING_PAYMENT, PAID, CONFIRMED, COMPLETED, CANCELLED
 
 	switch status {
-	case StatusInit, StatusAwaitingPayment, StatusPaid:
+	case StatusInit, StatusOnHold, StatusGuaranteed, StatusAwaitingPayment, StatusPaid:
 		return "Pending"
 	case StatusConfirmed, StatusCompleted:
 		return "Confirmed"
@@ -127,13 +156,18 @@ func formatStatusForFE(status BookingStatus) string {
 	}
 }
 
+// roomKey keys rooms by hotel, as room codes repeat across hotels
+func roomKey(hotelID, roomID string) string {
+	return hotelID + "/" + roomID
+}
+
 // ToBookingResponseList converts multiple bookings to response format
 func ToBookingResponseList(bookings []*Booking, hotels map[string]*HotelDetails, rooms map[string]*RoomDetails) []*BookingResponse {
 	responses := make([]*BookingResponse, 0, len(bookings))
 
 	for _, b := range bookings {
 		hotel := hotels[b.HotelID]
-		room := rooms[b.RoomID]
+		room := rooms[roomKey(b.HotelID, b.RoomID)]
 		response := ToBookingResponse(b, hotel, room)
 		responses = append(responses, response)
 	}
@@ -4,17 +4,30 @@ import "errors"
 
 // Package-level errors for booking operations
 var (
-	ErrBookingNotFound      = errors.New("booking not found")
-	ErrInvalidBooking       = errors.New("invalid booking")
-	ErrBookingCancelled     = errors.New("booking cancelled")
-	ErrInvalidStatus        = errors.New("invalid status transition")
-	ErrInvalidDateRange     = errors.New("invalid date range")
-	ErrInvalidGuests        = errors.New("invalid number of guests")
-	ErrInvalidCheckOut      = errors.New("check-out date must be after check-in date")
-	ErrInvalidCheckIn       = errors.New("check-in date cannot be in the past")
-	ErrRoomNotAvailable     = errors.New("room is not available for the selected dates")
-	ErrFailedToCreate       = errors.New("failed to create booking")
-	ErrFailedToUpdateStatus = errors.New("failed to update booking status")
-	ErrInvalidPaymentType   = errors.New("invalid payment type")
-	ErrFailedToUpdate       = errors.New("failed to update booking")
+	ErrBookingNotFound       = errors.New("booking not found")
+	ErrInvalidBooking        = errors.New("invalid booking")
+	ErrBookingCancelled      = errors.New("booking cancelled")
+	ErrInvalidStatus         = errors.New("invalid status transition")
+	ErrInvalidDateRange      = errors.New("invalid date range")
+	ErrInvalidGuests         = errors.New("invalid number of guests")
+	ErrInvalidCheckOut       = errors.New("check-out date must be after check-in date")
+	ErrInvalidCheckIn        = errors.New("check-in date cannot be in the past")
+	ErrRoomNotAvailable      = errors.New("room is not available for the selected dates")
+	ErrFailedToCreate        = errors.New("failed to create booking")
+	ErrFailedToUpdateStatus  = errors.New("failed to update booking status")
+	ErrStatusChanged         = errors.New("booking status changed concurrently")
+	ErrInvalidPaymentType    = errors.New("invalid payment type")
+	ErrPaymentTypeChange     = errors.New("payment type cannot be changed after booking")
+	ErrPayLaterUnavailable   = errors.New("pay later is not available this close to the free-cancellation deadline")
+	ErrFailedToUpdate        = errors.New("failed to update booking")
+	ErrGuaranteeRequired     = errors.New("card guarantee is required for this pay-at-hotel booking")
+	ErrInvalidGuarantee      = errors.New("card guarantee could not be verified")
+	ErrInvalidBilling        = errors.New("company name and tax ID are required for billing")
+	ErrBookingDeclined       = errors.New("booking declined by fraud checks")
+	ErrNotUnderReview        = errors.New("booking is not held for risk review")
+	ErrUnknownProvider       = errors.New("unknown provider")
+	ErrRoomNotInCatalog      = errors.New("room not found in catalog")
+	ErrSupplierRejected      = errors.New("failed to confirm with supplier")
+	ErrSupplierUnconfirmed   = errors.New("supplier confirmation outcome unknown")
+	ErrAccountHolderNotFound = errors.New("account holder not found")
 )
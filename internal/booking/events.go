@@ -20,6 +20,7 @@ type NotificationService interface {
 	SendBookingConfirmation(ctx context.Context, email, name string, bookingDetails map[string]interface{}) error
 	SendPaymentConfirmation(ctx context.Context, email, name string, paymentDetails map[string]interface{}) error
 	SendBookingCancelled(ctx context.Context, email, name string, cancellationDetails map[string]interface{}) error
+	SendPaymentReminder(ctx context.Context, email, name string, reminderDetails map[string]interface{}) error
 }
 
 // SetNotificationService sets the global notification service
@@ -401,3 +402,115 @@ func notifyAdminCancellation(ctx context.Context, data map[string]interface{}) {
 	userID := data["user_id"].(string)
 	logger.Infof("📢 Admin notified about cancellation: %s from user: %s", bookingID, userID)
 }
+
+// HandleBookingPaymentDue handles booking payment due event (PAY_LATER bookings)
+func HandleBookingPaymentDue(ctx context.Context, event eventbus.Event) error {
+	bookingID, _ := event.Payload["booking_id"].(string)
+	bookingRef, _ := event.Payload["booking_reference"].(string)
+	autoCharge, _ := event.Payload["auto_charge"].(bool)
+
+	logger.Infof("Booking payment due event received: %s (%s) - Auto charge: %t", bookingID, bookingRef, autoCharge)
+
+	// Saved cards are charged by the payment package, everyone else gets a reminder
+	if autoCharge {
+		return nil
+	}
+
+	if err := sendPaymentReminderEmail(ctx, event.Payload); err != nil {
+		logger.ErrorWithErr(err, "Failed to send payment reminder email")
+	}
+
+	logger.Infof("Booking payment due event processed: %s", bookingID)
+	return nil
+}
+
+// sendPaymentReminderEmail reminds the guest to pay before free cancellation ends
+func sendPaymentReminderEmail(ctx context.Context, data map[string]interface{}) error {
+	bookingRef, _ := data["booking_reference"].(string)
+	userID, _ := data["user_id"].(string)
+	totalAmount, _ := data["total_amount"].(int)
+	currency, _ := data["currency"].(string)
+	deadline, _ := data["free_cancellation_until"].(string)
+
+	logger.Infof("📧 Sending payment reminder to user %s", userID)
+	logger.Infof("   - Booking Reference: %s", bookingRef)
+	logger.Infof("   - Pay before: %s", deadline)
+
+	ns := getNotificationService()
+	if ns == nil {
+		logger.Warn("Notification service not available, skipping email")
+		return nil
+	}
+
+	// TODO: Get user email and name from user service
+	userEmail := "user@example.com"
+	userName := "User"
+
+	reminderDetails := map[string]interface{}{
+		"booking_reference": bookingRef,
+		"total_amount":      float64(totalAmount),
+		"currency":          currency,
+		"pay_before":        deadline,
+	}
+
+	if err := ns.SendPaymentReminder(ctx, userEmail, userName, reminderDetails); err != nil {
+		return fmt.Errorf("failed to send payment reminder email: %w", err)
+	}
+
+	logger.Infof("✅ Payment reminder sent successfully to %s", userEmail)
+	return nil
+}
+
+// riskStageBooking is the risk stage of bookings screened at creation. Those
+// are held by CreateBooking itself, before the booking is saved.
+const riskStageBooking = "BOOKING"
+
+// NewRiskReviewRequiredHandler creates a handler that holds a booking whose
+// payment was flagged for review
+func NewRiskReviewRequiredHandler(svc Service) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		bookingID, _ := event.Payload["booking_id"].(string)
+		stage, _ := event.Payload["stage"].(string)
+		if bookingID == "" || stage == riskStageBooking {
+			return nil
+		}
+
+		if _, err := svc.HoldForReview(ctx, bookingID); err != nil {
+			logger.ErrorWithErr(err, fmt.Sprintf("Failed to hold booking %s for risk review", bookingID))
+		}
+		return nil
+	}
+}
+
+// NewRiskReviewApprovedHandler creates a handler that releases a booking a
+// reviewer approved back into its payment flow
+func NewRiskReviewApprovedHandler(svc Service) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		bookingID, _ := event.Payload["booking_id"].(string)
+		stage, _ := event.Payload["stage"].(string)
+		if bookingID == "" {
+			return nil
+		}
+
+		if _, err := svc.ReleaseFromReview(ctx, bookingID, stage != riskStageBooking); err != nil {
+			logger.ErrorWithErr(err, fmt.Sprintf("Failed to release booking %s from risk review", bookingID))
+		}
+		return nil
+	}
+}
+
+// NewRiskReviewRejectedHandler creates a handler that cancels a booking a
+// reviewer rejected
+func NewRiskReviewRejectedHandler(svc Service) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		bookingID, _ := event.Payload["booking_id"].(string)
+		if bookingID == "" {
+			return nil
+		}
+
+		if _, err := svc.CancelBooking(ctx, bookingID); err != nil {
+			logger.ErrorWithErr(err, fmt.Sprintf("Failed to cancel rejected booking %s", bookingID))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,55 @@
+package booking
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// GuaranteeChecker decides whether a PAY_AT_HOTEL booking can be confirmed
+// with the supplier without taking a payment up front
+type GuaranteeChecker interface {
+	CheckGuarantee(ctx context.Context, booking *Booking) error
+}
+
+// CardVerifier checks with the payment gateway that a saved card can be
+// charged, so it can stand as a guarantee
+type CardVerifier interface {
+	VerifySavedCard(ctx context.Context, bookingID, savedTokenID string) error
+}
+
+type defaultGuaranteeChecker struct {
+	verifier              CardVerifier
+	maxUnguaranteedAmount int
+}
+
+// NewGuaranteeChecker creates the default guarantee checker: a saved card
+// the gateway verifies guarantees any amount. IDR bookings up to
+// maxUnguaranteedAmount are accepted without a card; 0 requires a card for
+// every booking. A nil verifier accepts no card.
+func NewGuaranteeChecker(verifier CardVerifier, maxUnguaranteedAmount int) GuaranteeChecker {
+	return &defaultGuaranteeChecker{
+		verifier:              verifier,
+		maxUnguaranteedAmount: maxUnguaranteedAmount,
+	}
+}
+
+func (c *defaultGuaranteeChecker) CheckGuarantee(ctx context.Context, booking *Booking) error {
+	if booking.PaymentToken != "" {
+		if c.verifier == nil {
+			return ErrInvalidGuarantee
+		}
+		if err := c.verifier.VerifySavedCard(ctx, booking.ID, booking.PaymentToken); err != nil {
+			logger.ErrorWithErr(err, fmt.Sprintf("Failed to verify guarantee card for booking %s", booking.ID))
+			return ErrInvalidGuarantee
+		}
+		return nil
+	}
+
+	if c.maxUnguaranteedAmount > 0 && booking.Currency == "IDR" && booking.TotalAmount <= c.maxUnguaranteedAmount {
+		return nil
+	}
+
+	return ErrGuaranteeRequired
+}
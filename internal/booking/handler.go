@@ -35,15 +35,24 @@ func (h *Handler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	req.Client = ClientInfo{
+		IPAddress: middleware.ClientIP(r),
+		Country:   middleware.ClientCountry(r),
+	}
+
 	booking, err := h.service.CreateBooking(r.Context(), userID, &req)
 	if err != nil {
 		logger.ErrorWithErr(err, "Failed to create booking")
 		// Return proper HTTP status based on error type
 		switch err {
-		case ErrInvalidCheckOut, ErrInvalidCheckIn, ErrInvalidGuests:
+		case ErrInvalidCheckOut, ErrInvalidCheckIn, ErrInvalidGuests, ErrInvalidBilling, ErrUnknownProvider:
 			respondWithError(w, http.StatusBadRequest, err.Error())
 		case ErrRoomNotAvailable:
 			respondWithError(w, http.StatusConflict, err.Error())
+		case ErrGuaranteeRequired, ErrInvalidGuarantee, ErrPayLaterUnavailable:
+			respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		case ErrBookingDeclined:
+			respondWithError(w, http.StatusForbidden, err.Error())
 		default:
 			respondWithError(w, http.StatusInternalServerError, "Failed to create booking")
 		}
@@ -166,7 +175,7 @@ func (h *Handler) UpdateBooking(w http.ResponseWriter, r *http.Request) {
 		switch err {
 		case ErrBookingNotFound:
 			respondWithError(w, http.StatusNotFound, err.Error())
-		case ErrInvalidStatus, ErrInvalidPaymentType:
+		case ErrInvalidStatus, ErrInvalidPaymentType, ErrPaymentTypeChange:
 			respondWithError(w, http.StatusBadRequest, err.Error())
 		default:
 			respondWithError(w, http.StatusInternalServerError, "Failed to update booking")
@@ -10,7 +10,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/jwt"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/middleware"
 	"github.com/stretchr/testify/assert"
@@ -24,9 +23,9 @@ func TestBookingHandler_CreateBooking_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 	handler := NewHandler(service)
 
 	// Create authenticated request
@@ -50,37 +49,8 @@ func TestBookingHandler_CreateBooking_Success(t *testing.T) {
 	jsonBody, _ := json.Marshal(reqBody)
 
 	// Mock HotelBeds API responses
-	mockHB.On("GetHotelAvailability", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*hotelbeds.AvailabilityRequest")).Return(&hotelbeds.AvailabilityResponse{
-		HotelCode:   "hotel-1",
-		HotelName:   "Test Hotel",
-		IsAvailable: true,
-		Rooms: []hotelbeds.Room{
-			{
-				RoomCode:  "room-1",
-				RoomName:  "Test Room",
-				Available: true,
-				Price:     1500000,
-				Currency:  "IDR",
-			},
-		},
-		TotalPrice: 1500000,
-		Currency:   "IDR",
-	}, nil)
-
-	mockHB.On("GetRoomRates", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*hotelbeds.RoomRateRequest")).Return(&hotelbeds.RoomRateResponse{
-		HotelCode:  "hotel-1",
-		RoomCode:   "room-1",
-		RoomName:   "Test Room",
-		TotalPrice: 1500000,
-		Currency:   "IDR",
-		Rates: []hotelbeds.Rate{
-			{
-				RateCode: "RATE-1",
-				Price:    1500000,
-				Currency: "IDR",
-			},
-		},
-	}, nil)
+	setupAvailableRoom(mock.AnythingOfType("*context.valueCtx"), mockProviders, &reqBody, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, &reqBody, 1500000)
 
 	// Mock: Successful booking creation
 	mockRepo.On("Create", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*booking.Booking")).Return(nil)
@@ -116,7 +86,7 @@ func TestBookingHandler_CreateBooking_Success(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 	mockEB.AssertExpectations(t)
-	mockHB.AssertExpectations(t)
+	mockProviders.AssertExpectations(t)
 }
 
 // TestBookingHandler_GetBooking_Success tests successful booking retrieval via HTTP
@@ -128,9 +98,9 @@ func TestBookingHandler_GetBooking_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 	handler := NewHandler(service)
 
 	bookingID := "booking-123"
@@ -177,9 +147,9 @@ func TestBookingHandler_GetBooking_NotFound(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 	handler := NewHandler(service)
 
 	bookingID := "non-existent"
@@ -216,9 +186,9 @@ func TestBookingHandler_CancelBooking_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 	handler := NewHandler(service)
 
 	bookingID := "booking-123"
@@ -273,9 +243,9 @@ func TestBookingHandler_GetMyBookings_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 	handler := NewHandler(service)
 
 	// Create authenticated request
@@ -339,9 +309,9 @@ func TestBookingHandler_CreateBooking_InvalidJSON(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 	handler := NewHandler(service)
 
 	// Create authenticated request
@@ -380,9 +350,9 @@ func TestBookingHandler_GetMyBookings_DefaultPagination(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 	handler := NewHandler(service)
 
 	// Create authenticated request
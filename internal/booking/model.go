@@ -1,6 +1,7 @@
 package booking
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +17,13 @@ const (
 	StatusConfirmed     BookingStatus = "CONFIRMED"
 	StatusCompleted     BookingStatus = "COMPLETED"
 	StatusCancelled     BookingStatus = "CANCELLED"
+
+	// StatusOnHold is a PAY_LATER booking held until its charge is due
+	StatusOnHold        BookingStatus = "ON_HOLD"
+	// StatusGuaranteed is a PAY_AT_HOTEL booking whose card guarantee passed
+	StatusGuaranteed    BookingStatus = "GUARANTEED"
+	// StatusRiskReview is a booking held by fraud checks until a reviewer decides
+	StatusRiskReview    BookingStatus = "RISK_REVIEW"
 )
 
 // PaymentType represents payment type
@@ -27,6 +35,22 @@ const (
 	PaymentTypePayLater  PaymentType = "PAY_LATER"
 )
 
+// DefaultProviderCode is the supplier bookings are made with when the
+// request does not name one
+const DefaultProviderCode = "hotelbeds"
+
+const (
+	// DefaultFreeCancellationWindow is how long before check-in a booking can
+	// still be cancelled free of charge when the supplier gives no deadline
+	DefaultFreeCancellationWindow = 48 * time.Hour
+	// PayLaterChargeLeadTime is how long before the free-cancellation deadline
+	// a PAY_LATER booking is charged (or the guest is reminded to pay)
+	PayLaterChargeLeadTime = 24 * time.Hour
+	// PayLaterMinPaymentWindow is the least time a PAY_LATER guest is given to
+	// pay before free cancellation ends
+	PayLaterMinPaymentWindow = 24 * time.Hour
+)
+
 // Booking represents a booking
 type Booking struct {
 	ID                string        `json:"id" db:"id"`
@@ -34,6 +58,9 @@ type Booking struct {
 	HotelID           string        `json:"hotel_id" db:"hotel_id"`
 	RoomID            string        `json:"room_id" db:"room_id"`
 	BookingReference  string        `json:"booking_reference" db:"booking_reference"`
+	ProviderCode      string        `json:"provider_code" db:"provider_code"`
+	RateKey           string        `json:"-" db:"provider_rate_key"`  // The priced rate, booked at confirmation
+	RateType          string        `json:"-" db:"provider_rate_type"` // e.g. RECHECK rates are rechecked before booking
 	SupplierReference string        `json:"supplier_reference,omitempty" db:"supplier_reference"`
 	CheckIn           time.Time     `json:"check_in" db:"check_in"`
 	CheckOut          time.Time     `json:"check_out" db:"check_out"`
@@ -43,9 +70,16 @@ type Booking struct {
 	GuestPhone        string        `json:"guest_phone,omitempty" db:"guest_phone"`
 	SpecialRequests   string        `json:"special_requests,omitempty" db:"special_requests"`
 	Status            BookingStatus `json:"status" db:"status"`
-	TotalAmount       int           `json:"total_amount" db:"total_amount"`
+	TotalAmount       int           `json:"total_amount" db:"total_amount"` // Sell price charged to the guest
+	NetAmount         int           `json:"-" db:"net_amount"`              // Supplier's net rate, owed to the supplier
+	// CancellationPenalty is what the supplier charges for cancelling after FreeCancelUntil
+	CancellationPenalty int `json:"-" db:"cancellation_penalty"`
 	Currency          string        `json:"currency" db:"currency"`
 	PaymentType       PaymentType   `json:"payment_type" db:"payment_type"`
+	PaymentToken      string        `json:"-" db:"payment_token"`
+	FreeCancelUntil   *time.Time    `json:"free_cancellation_until,omitempty" db:"free_cancellation_until"`
+	PaymentDueAt      *time.Time    `json:"payment_due_at,omitempty" db:"payment_due_at"`
+	Billing           *BillingInfo  `json:"billing,omitempty" db:"billing_details"`
 	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time     `json:"updated_at" db:"updated_at"`
 }
@@ -57,7 +91,34 @@ type CreateBookingRequest struct {
 	CheckIn     time.Time    `json:"check_in" validate:"required"`
 	CheckOut    time.Time    `json:"check_out" validate:"required,gtfield=CheckIn"`
 	Guests      int          `json:"guests" validate:"required,min=1,max=10"`
-	PaymentType PaymentType  `json:"payment_type" validate:"required,oneof=PAY_NOW PAY_AT_HOTEL"`
+	// GuestName is the lead guest, when someone else than the account holder stays
+	GuestName   string       `json:"guest_name,omitempty"`
+	PaymentType PaymentType  `json:"payment_type" validate:"required,oneof=PAY_NOW PAY_AT_HOTEL PAY_LATER"`
+	// ProviderCode is the supplier the room was found with (defaults to hotelbeds)
+	ProviderCode string      `json:"provider_code,omitempty"`
+	// PaymentToken is a saved-card token from the payment gateway. It guarantees
+	// PAY_AT_HOTEL bookings and is charged automatically for PAY_LATER bookings.
+	PaymentToken string      `json:"payment_token,omitempty"`
+	// Billing holds company details for corporate invoices
+	Billing     *BillingInfo `json:"billing,omitempty"`
+	// Client is filled in by the handler for fraud screening
+	Client      ClientInfo   `json:"-"`
+}
+
+// BillingInfo holds the company billing details printed on invoices
+type BillingInfo struct {
+	CompanyName string `json:"company_name"`
+	TaxID       string `json:"tax_id"` // NPWP
+	Address     string `json:"address,omitempty"`
+	Email       string `json:"email,omitempty"`
+}
+
+// Validate checks that the details needed on a tax invoice are present
+func (b *BillingInfo) Validate() error {
+	if strings.TrimSpace(b.CompanyName) == "" || strings.TrimSpace(b.TaxID) == "" {
+		return ErrInvalidBilling
+	}
+	return nil
 }
 
 // UpdateBookingRequest represents request to update booking
@@ -66,24 +127,32 @@ type UpdateBookingRequest struct {
 	GuestEmail       string      `json:"guest_email,omitempty"`
 	GuestPhone       string      `json:"guest_phone,omitempty"`
 	SpecialRequests  string      `json:"special_requests,omitempty"`
-	PaymentType      PaymentType `json:"payment_type,omitempty"`
+	PaymentType      PaymentType `json:"payment_type,omitempty"` // Only the booking's own type is accepted
 }
 
 // NewBooking creates a new booking
 func NewBooking(userID string, req *CreateBookingRequest) *Booking {
 	now := time.Now()
+	providerCode := req.ProviderCode
+	if providerCode == "" {
+		providerCode = DefaultProviderCode
+	}
 	return &Booking{
 		ID:               uuid.New().String(),
 		UserID:           userID,
 		HotelID:          req.HotelID,
 		RoomID:           req.RoomID,
 		BookingReference: generateBookingReference(),
+		ProviderCode:     providerCode,
 		CheckIn:          req.CheckIn,
 		CheckOut:         req.CheckOut,
 		Guests:           req.Guests,
+		GuestName:        req.GuestName,
 		Status:           StatusInit,
 		Currency:         "IDR",
 		PaymentType:      req.PaymentType,
+		PaymentToken:     req.PaymentToken,
+		Billing:          req.Billing,
 		CreatedAt:        now,
 		UpdatedAt:        now,
 	}
@@ -94,18 +163,55 @@ func generateBookingReference() string {
 	return "BKG-" + uuid.New().String()[:8]
 }
 
-// CanTransitionTo checks if state transition is valid
-func (b *Booking) CanTransitionTo(newStatus BookingStatus) bool {
-	validTransitions := map[BookingStatus][]BookingStatus{
-		StatusInit:            {StatusAwaitingPayment, StatusCancelled},
-		StatusAwaitingPayment: {StatusPaid, StatusCancelled},
+// validTransitions holds the state paths for each payment type.
+// PAY_NOW:      INIT -> AWAITING_PAYMENT -> PAID -> CONFIRMED -> COMPLETED
+// PAY_AT_HOTEL: INIT -> GUARANTEED -> CONFIRMED -> COMPLETED
+// PAY_LATER:    INIT -> ON_HOLD -> AWAITING_PAYMENT -> PAID -> CONFIRMED -> COMPLETED
+// Fraud checks can hold a booking in RISK_REVIEW at creation or while it awaits
+// payment; a reviewer releases it back into the flow or it is cancelled.
+var validTransitions = map[PaymentType]map[BookingStatus][]BookingStatus{
+	PaymentTypePayNow: {
+		StatusInit:            {StatusAwaitingPayment, StatusRiskReview, StatusCancelled},
+		StatusRiskReview:      {StatusAwaitingPayment, StatusCancelled},
+		StatusAwaitingPayment: {StatusPaid, StatusRiskReview, StatusCancelled},
 		StatusPaid:            {StatusConfirmed, StatusCancelled},
 		StatusConfirmed:       {StatusCompleted, StatusCancelled},
 		StatusCompleted:       {},
 		StatusCancelled:       {},
+	},
+	PaymentTypePayAtHotel: {
+		StatusInit:       {StatusGuaranteed, StatusRiskReview, StatusCancelled},
+		StatusRiskReview: {StatusGuaranteed, StatusCancelled},
+		StatusGuaranteed: {StatusConfirmed, StatusCancelled},
+		StatusConfirmed:  {StatusCompleted, StatusCancelled},
+		StatusCompleted:  {},
+		StatusCancelled:  {},
+	},
+	PaymentTypePayLater: {
+		StatusInit:            {StatusOnHold, StatusRiskReview, StatusCancelled},
+		StatusOnHold:          {StatusAwaitingPayment, StatusCancelled},
+		StatusRiskReview:      {StatusOnHold, StatusAwaitingPayment, StatusCancelled},
+		StatusAwaitingPayment: {StatusPaid, StatusRiskReview, StatusCancelled},
+		StatusPaid:            {StatusConfirmed, StatusCancelled},
+		StatusConfirmed:       {StatusCompleted, StatusCancelled},
+		StatusCompleted:       {},
+		StatusCancelled:       {},
+	},
+}
+
+// CanTransitionTo checks if state transition is valid
+func (b *Booking) CanTransitionTo(newStatus BookingStatus) bool {
+	paymentType := b.PaymentType
+	if paymentType == "" {
+		paymentType = PaymentTypePayNow
 	}
 
-	allowedStates, ok := validTransitions[b.Status]
+	transitions, ok := validTransitions[paymentType]
+	if !ok {
+		return false
+	}
+
+	allowedStates, ok := transitions[b.Status]
 	if !ok {
 		return false
 	}
@@ -118,3 +224,42 @@ func (b *Booking) CanTransitionTo(newStatus BookingStatus) bool {
 
 	return false
 }
+
+// PenaltyAt returns what the supplier charges if the booking is cancelled at
+// the given moment: nothing before the free-cancellation deadline, the
+// supplier's penalty after it. A booking never confirmed with the supplier
+// costs nothing to cancel.
+func (b *Booking) PenaltyAt(at time.Time) int {
+	if b.SupplierReference == "" {
+		return 0
+	}
+	if b.FreeCancelUntil != nil && at.Before(*b.FreeCancelUntil) {
+		return 0
+	}
+	return b.CancellationPenalty
+}
+
+// SchedulePayLater sets the free-cancellation deadline and the moment the
+// PAY_LATER charge (or payment reminder) is due. The supplier's deadline is
+// kept when the rate came with one. A deadline less than
+// PayLaterMinPaymentWindow away leaves no time to pay, so the booking cannot
+// be paid later. If the deadline is closer than the lead time, payment is due
+// immediately.
+func (b *Booking) SchedulePayLater(now time.Time) error {
+	deadline := b.CheckIn.Add(-DefaultFreeCancellationWindow)
+	if b.FreeCancelUntil != nil {
+		deadline = *b.FreeCancelUntil
+	}
+	if deadline.Before(now.Add(PayLaterMinPaymentWindow)) {
+		return ErrPayLaterUnavailable
+	}
+
+	dueAt := deadline.Add(-PayLaterChargeLeadTime)
+	if dueAt.Before(now) {
+		dueAt = now
+	}
+
+	b.FreeCancelUntil = &deadline
+	b.PaymentDueAt = &dueAt
+	return nil
+}
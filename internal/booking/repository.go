@@ -2,9 +2,12 @@ package booking
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
+	"github.com/ekonugroho98/be-bookingkuy/internal/pricing"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
 	"github.com/jackc/pgx/v5"
 )
@@ -16,6 +19,11 @@ type Repository interface {
 	GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*Booking, error)
 	Update(ctx context.Context, booking *Booking) error
 	UpdateStatus(ctx context.Context, id string, status BookingStatus) error
+	TransitionStatus(ctx context.Context, id string, from, to BookingStatus) error
+	GetDuePayLater(ctx context.Context, now time.Time, limit int) ([]*Booking, error)
+	GetCatalogRoom(ctx context.Context, providerCode, hotelID, roomID string) (*RoomDetails, error)
+	GetHotelCategory(ctx context.Context, providerCode, hotelID string) (pricing.HotelCategory, error)
+	GetAccountHolder(ctx context.Context, userID string) (*AccountHolder, error)
 }
 
 type repository struct {
@@ -31,15 +39,20 @@ func NewRepository(database *db.DB) Repository {
 
 func (r *repository) Create(ctx context.Context, booking *Booking) error {
 	query := `
-		INSERT INTO bookings (id, user_id, hotel_id, room_id, booking_reference, check_in, check_out, guests, status, total_amount, currency, payment_type, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		INSERT INTO bookings (id, user_id, hotel_id, room_id, booking_reference, check_in, check_out, guests, status, total_amount, net_amount, cancellation_penalty, currency,
+		                      payment_type, payment_token, free_cancellation_until, payment_due_at, billing_details, created_at, updated_at, provider_code,
+		                      provider_rate_key, provider_rate_type, guest_name, guest_email, guest_phone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)
 	`
 
 	_, err := r.db.Pool.Exec(ctx, query,
 		booking.ID, booking.UserID, booking.HotelID, booking.RoomID,
 		booking.BookingReference, booking.CheckIn, booking.CheckOut,
-		booking.Guests, booking.Status, booking.TotalAmount,
-		booking.Currency, booking.PaymentType, booking.CreatedAt, booking.UpdatedAt,
+		booking.Guests, booking.Status, booking.TotalAmount, booking.NetAmount, booking.CancellationPenalty,
+		booking.Currency, booking.PaymentType, nullIfEmpty(booking.PaymentToken),
+		booking.FreeCancelUntil, booking.PaymentDueAt, booking.Billing, booking.CreatedAt, booking.UpdatedAt,
+		booking.ProviderCode, nullIfEmpty(booking.RateKey), nullIfEmpty(booking.RateType), nullIfEmpty(booking.GuestName),
+		nullIfEmpty(booking.GuestEmail), nullIfEmpty(booking.GuestPhone),
 	)
 
 	if err != nil {
@@ -52,8 +65,11 @@ func (r *repository) Create(ctx context.Context, booking *Booking) error {
 func (r *repository) GetByID(ctx context.Context, id string) (*Booking, error) {
 	query := `
 		SELECT id, user_id, hotel_id, room_id, booking_reference, supplier_reference,
-		       check_in, check_out, guests, status, total_amount, currency, payment_type,
-		       created_at, updated_at
+		       check_in, check_out, guests, status, total_amount, COALESCE(net_amount, total_amount), COALESCE(cancellation_penalty, net_amount, total_amount), currency, payment_type,
+		       COALESCE(payment_token, ''), free_cancellation_until, payment_due_at,
+		       billing_details, created_at, updated_at, provider_code,
+		       COALESCE(provider_rate_key, ''), COALESCE(provider_rate_type, ''), COALESCE(guest_name, ''),
+		       COALESCE(guest_email, ''), COALESCE(guest_phone, '')
 		FROM bookings
 		WHERE id = $1
 	`
@@ -65,8 +81,11 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Booking, error) {
 		&booking.ID, &booking.UserID, &booking.HotelID, &booking.RoomID,
 		&booking.BookingReference, &booking.SupplierReference,
 		&booking.CheckIn, &booking.CheckOut, &booking.Guests, &booking.Status,
-		&booking.TotalAmount, &booking.Currency, &booking.PaymentType,
-		&booking.CreatedAt, &booking.UpdatedAt,
+		&booking.TotalAmount, &booking.NetAmount, &booking.CancellationPenalty, &booking.Currency, &booking.PaymentType,
+		&booking.PaymentToken, &booking.FreeCancelUntil, &booking.PaymentDueAt,
+		&booking.Billing, &booking.CreatedAt, &booking.UpdatedAt, &booking.ProviderCode,
+		&booking.RateKey, &booking.RateType, &booking.GuestName,
+		&booking.GuestEmail, &booking.GuestPhone,
 	)
 
 	if err != nil {
@@ -82,8 +101,11 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Booking, error) {
 func (r *repository) GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*Booking, error) {
 	query := `
 		SELECT id, user_id, hotel_id, room_id, booking_reference, supplier_reference,
-		       check_in, check_out, guests, status, total_amount, currency, payment_type,
-		       created_at, updated_at
+		       check_in, check_out, guests, status, total_amount, COALESCE(net_amount, total_amount), COALESCE(cancellation_penalty, net_amount, total_amount), currency, payment_type,
+		       COALESCE(payment_token, ''), free_cancellation_until, payment_due_at,
+		       billing_details, created_at, updated_at, provider_code,
+		       COALESCE(provider_rate_key, ''), COALESCE(provider_rate_type, ''), COALESCE(guest_name, ''),
+		       COALESCE(guest_email, ''), COALESCE(guest_phone, '')
 		FROM bookings
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -103,8 +125,11 @@ func (r *repository) GetByUserID(ctx context.Context, userID string, limit, offs
 			&booking.ID, &booking.UserID, &booking.HotelID, &booking.RoomID,
 			&booking.BookingReference, &booking.SupplierReference,
 			&booking.CheckIn, &booking.CheckOut, &booking.Guests, &booking.Status,
-			&booking.TotalAmount, &booking.Currency, &booking.PaymentType,
-			&booking.CreatedAt, &booking.UpdatedAt,
+			&booking.TotalAmount, &booking.NetAmount, &booking.CancellationPenalty, &booking.Currency, &booking.PaymentType,
+			&booking.PaymentToken, &booking.FreeCancelUntil, &booking.PaymentDueAt,
+			&booking.Billing, &booking.CreatedAt, &booking.UpdatedAt, &booking.ProviderCode,
+			&booking.RateKey, &booking.RateType, &booking.GuestName,
+			&booking.GuestEmail, &booking.GuestPhone,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan booking: %w", err)
@@ -118,7 +143,8 @@ func (r *repository) GetByUserID(ctx context.Context, userID string, limit, offs
 func (r *repository) Update(ctx context.Context, booking *Booking) error {
 	query := `
 		UPDATE bookings
-		SET status = $2, supplier_reference = $3, total_amount = $4, updated_at = $5
+		SET status = $2, supplier_reference = $3, total_amount = $4, net_amount = $5, guest_name = $6,
+		    guest_email = $7, guest_phone = $8, updated_at = $9
 		WHERE id = $1
 	`
 
@@ -126,7 +152,8 @@ func (r *repository) Update(ctx context.Context, booking *Booking) error {
 
 	result, err := r.db.Pool.Exec(ctx, query,
 		booking.ID, booking.Status, booking.SupplierReference,
-		booking.TotalAmount, booking.UpdatedAt,
+		booking.TotalAmount, booking.NetAmount, nullIfEmpty(booking.GuestName),
+		nullIfEmpty(booking.GuestEmail), nullIfEmpty(booking.GuestPhone), booking.UpdatedAt,
 	)
 
 	if err != nil {
@@ -154,3 +181,149 @@ func (r *repository) UpdateStatus(ctx context.Context, id string, status Booking
 
 	return nil
 }
+
+// TransitionStatus moves a booking from one status to another. It returns
+// ErrStatusChanged when the booking is no longer in from, so only one of
+// several concurrent callers makes the move.
+func (r *repository) TransitionStatus(ctx context.Context, id string, from, to BookingStatus) error {
+	query := `
+		UPDATE bookings
+		SET status = $3, updated_at = $4
+		WHERE id = $1 AND status = $2
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, from, to, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update booking status: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrStatusChanged
+	}
+
+	return nil
+}
+
+// GetDuePayLater returns PAY_LATER bookings that need action: ON_HOLD bookings whose
+// charge is due, and AWAITING_PAYMENT bookings past their free-cancellation deadline
+func (r *repository) GetDuePayLater(ctx context.Context, now time.Time, limit int) ([]*Booking, error) {
+	query := `
+		SELECT id, user_id, hotel_id, room_id, booking_reference, supplier_reference,
+		       check_in, check_out, guests, status, total_amount, COALESCE(net_amount, total_amount), COALESCE(cancellation_penalty, net_amount, total_amount), currency, payment_type,
+		       COALESCE(payment_token, ''), free_cancellation_until, payment_due_at,
+		       billing_details, created_at, updated_at, provider_code,
+		       COALESCE(provider_rate_key, ''), COALESCE(provider_rate_type, ''), COALESCE(guest_name, ''),
+		       COALESCE(guest_email, ''), COALESCE(guest_phone, '')
+		FROM bookings
+		WHERE payment_type = $1
+		  AND ((status = $2 AND payment_due_at <= $4)
+		       OR (status = $3 AND free_cancellation_until <= $4))
+		ORDER BY payment_due_at ASC
+		LIMIT $5
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, PaymentTypePayLater, StatusOnHold, StatusAwaitingPayment, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due pay-later bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []*Booking
+	for rows.Next() {
+		var booking Booking
+		err := rows.Scan(
+			&booking.ID, &booking.UserID, &booking.HotelID, &booking.RoomID,
+			&booking.BookingReference, &booking.SupplierReference,
+			&booking.CheckIn, &booking.CheckOut, &booking.Guests, &booking.Status,
+			&booking.TotalAmount, &booking.NetAmount, &booking.CancellationPenalty, &booking.Currency, &booking.PaymentType,
+			&booking.PaymentToken, &booking.FreeCancelUntil, &booking.PaymentDueAt,
+			&booking.Billing, &booking.CreatedAt, &booking.UpdatedAt, &booking.ProviderCode,
+			&booking.RateKey, &booking.RateType, &booking.GuestName,
+			&booking.GuestEmail, &booking.GuestPhone,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan booking: %w", err)
+		}
+		bookings = append(bookings, &booking)
+	}
+
+	return bookings, nil
+}
+
+// nullIfEmpty stores empty strings as NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// GetCatalogRoom returns a room from the synced room catalog by its provider
+// hotel and room codes, as stored on bookings
+func (r *repository) GetCatalogRoom(ctx context.Context, providerCode, hotelID, roomID string) (*RoomDetails, error) {
+	query := `
+		SELECT r.name, COALESCE(r.images->0->>'url', '')
+		FROM rooms r
+		JOIN hotels h ON h.id = r.hotel_id
+		WHERE h.provider_code = $1 AND h.provider_hotel_id = $2
+			AND r.provider_room_code = $3 AND r.deleted_at IS NULL
+	`
+
+	room := &RoomDetails{ID: roomID}
+	err := r.db.Pool.QueryRow(ctx, query, providerCode, hotelID, roomID).Scan(&room.Name, &room.Image)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrRoomNotInCatalog
+		}
+		return nil, fmt.Errorf("failed to get catalog room: %w", err)
+	}
+
+	return room, nil
+}
+
+// GetHotelCategory returns the star category a provider hotel is priced by,
+// from the synced hotels. Hotels without a star rating are category 0.
+func (r *repository) GetHotelCategory(ctx context.Context, providerCode, hotelID string) (pricing.HotelCategory, error) {
+	query := `
+		SELECT star_rating
+		FROM hotels
+		WHERE provider_code = $1
+		  AND (provider_hotel_id = $2 OR id::text = $2)
+		  AND deleted_at IS NULL
+		LIMIT 1
+	`
+
+	var stars *float64
+	err := r.db.Pool.QueryRow(ctx, query, providerCode, hotelID).Scan(&stars)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get hotel category: %w", err)
+	}
+
+	if stars == nil {
+		return 0, nil
+	}
+	return pricing.HotelCategory(math.Round(*stars)), nil
+}
+
+// GetAccountHolder returns the contact details of the user a booking was made by
+func (r *repository) GetAccountHolder(ctx context.Context, userID string) (*AccountHolder, error) {
+	query := `
+		SELECT COALESCE(first_name, ''), COALESCE(last_name, ''), email, COALESCE(phone, '')
+		FROM users
+		WHERE id = $1
+	`
+
+	var holder AccountHolder
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(&holder.FirstName, &holder.LastName, &holder.Email, &holder.Phone)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAccountHolderNotFound
+		}
+		return nil, fmt.Errorf("failed to get account holder: %w", err)
+	}
+
+	return &holder, nil
+}
@@ -0,0 +1,41 @@
+package booking
+
+import (
+	"context"
+)
+
+// RiskDecision is a risk screener's verdict on a new booking
+type RiskDecision string
+
+const (
+	RiskAllow  RiskDecision = "ALLOW"
+	RiskReview RiskDecision = "REVIEW"
+	RiskBlock  RiskDecision = "BLOCK"
+)
+
+// ClientInfo describes where a booking request came from
+type ClientInfo struct {
+	IPAddress string
+	Country   string // ISO 3166-1 alpha-2, empty when unknown
+}
+
+// RiskScreener scores a new booking for fraud before it is saved. Bookings
+// held for review wait in RISK_REVIEW until a reviewer approves or rejects them.
+type RiskScreener interface {
+	ScreenBooking(ctx context.Context, booking *Booking, client ClientInfo) (RiskDecision, error)
+}
+
+// reviewReleaseStatus is where a booking goes once a reviewer approves it.
+// Bookings held while paying go back to AWAITING_PAYMENT so they are not
+// charged twice; bookings held at creation start their normal payment flow.
+func reviewReleaseStatus(booking *Booking, awaitingPayment bool) BookingStatus {
+	switch booking.PaymentType {
+	case PaymentTypePayAtHotel:
+		return StatusGuaranteed
+	case PaymentTypePayLater:
+		if !awaitingPayment {
+			return StatusOnHold
+		}
+	}
+	return StatusAwaitingPayment
+}
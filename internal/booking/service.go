@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
 	"github.com/ekonugroho98/be-bookingkuy/internal/pricing"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
+	"github.com/ekonugroho98/be-bookingkuy/internal/providertraffic"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
 )
@@ -23,22 +26,41 @@ type Service interface {
 	UpdateStatus(ctx context.Context, bookingID string, status BookingStatus) (*Booking, error)
 	CancelBooking(ctx context.Context, bookingID string) (*Booking, error)
 	ConfirmBookingWithSupplier(ctx context.Context, bookingID string) (*Booking, error)
+	ProcessDuePayLaterBookings(ctx context.Context) error
+	HoldForReview(ctx context.Context, bookingID string) (*Booking, error)
+	ReleaseFromReview(ctx context.Context, bookingID string, awaitingPayment bool) (*Booking, error)
 }
 
 type service struct {
-	repo            Repository
-	eventBus        eventbus.EventBus
-	pricingService  pricing.Service
-	hotelbedsClient hotelbeds.ClientInterface
+	repo             Repository
+	eventBus         eventbus.EventBus
+	pricingService   pricing.Service
+	providers        provider.Gateway
+	guaranteeChecker GuaranteeChecker
+	riskScreener     RiskScreener
 }
 
-// NewService creates a new booking service
-func NewService(repo Repository, eb eventbus.EventBus, ps pricing.Service, hbClient hotelbeds.ClientInterface) Service {
+// NewService creates a new booking service. Without a card verifier,
+// PAY_AT_HOTEL bookings cannot be guaranteed.
+func NewService(repo Repository, eb eventbus.EventBus, ps pricing.Service, providers provider.Gateway) Service {
+	return NewServiceWithGuarantee(repo, eb, ps, providers, NewGuaranteeChecker(nil, 0))
+}
+
+// NewServiceWithGuarantee creates a new booking service with a custom PAY_AT_HOTEL guarantee checker
+func NewServiceWithGuarantee(repo Repository, eb eventbus.EventBus, ps pricing.Service, providers provider.Gateway, gc GuaranteeChecker) Service {
+	return NewServiceWithRisk(repo, eb, ps, providers, gc, nil)
+}
+
+// NewServiceWithRisk creates a new booking service that screens new bookings
+// for fraud. A nil screener disables screening.
+func NewServiceWithRisk(repo Repository, eb eventbus.EventBus, ps pricing.Service, providers provider.Gateway, gc GuaranteeChecker, rs RiskScreener) Service {
 	return &service{
-		repo:            repo,
-		eventBus:        eb,
-		pricingService:  ps,
-		hotelbedsClient: hbClient,
+		repo:             repo,
+		eventBus:         eb,
+		pricingService:   ps,
+		providers:        providers,
+		guaranteeChecker: gc,
+		riskScreener:     rs,
 	}
 }
 
@@ -52,69 +74,136 @@ func (s *service) CreateBooking(ctx context.Context, userID string, req *CreateB
 		return nil, ErrInvalidCheckIn
 	}
 
-	// 2. Check availability with HotelBeds
-	availability, err := s.hotelbedsClient.GetHotelAvailability(ctx, &hotelbeds.AvailabilityRequest{
-		HotelCode: req.HotelID,
-		RoomCode:  req.RoomID,
-		CheckIn:   req.CheckIn,
-		CheckOut:  req.CheckOut,
-		Guests:    req.Guests,
-	})
+	if req.Billing != nil {
+		if err := req.Billing.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. Check availability with the provider the room was found with
+	booking := NewBooking(userID, req)
+	// Tag supplier calls so captured provider traffic can be found by booking
+	supplierCtx := providertraffic.WithBookingID(ctx, booking.ID)
+	roomReq := &types.RoomRequest{
+		HotelID:  req.HotelID,
+		RoomID:   req.RoomID,
+		CheckIn:  req.CheckIn,
+		CheckOut: req.CheckOut,
+		Guests:   req.Guests,
+	}
+
+	availability, err := s.providers.CheckAvailability(supplierCtx, booking.ProviderCode, roomReq)
 	if err != nil {
-		logger.ErrorWithErr(err, "Failed to check availability with HotelBeds")
+		if errors.Is(err, provider.ErrProviderNotFound) {
+			return nil, ErrUnknownProvider
+		}
+		logger.ErrorWithErr(err, fmt.Sprintf("Failed to check availability with %s", booking.ProviderCode))
 		return nil, fmt.Errorf("failed to check availability: %w", err)
 	}
 
-	if !availability.IsAvailable || len(availability.Rooms) == 0 {
+	if len(availability.Rooms) == 0 {
 		return nil, ErrRoomNotAvailable
 	}
 
-	// 3. Get room pricing from HotelBeds
-	roomRate, err := s.hotelbedsClient.GetRoomRates(ctx, &hotelbeds.RoomRateRequest{
-		HotelCode: req.HotelID,
-		RoomCode:  req.RoomID,
-		CheckIn:   req.CheckIn,
-		CheckOut:  req.CheckOut,
-		Guests:    req.Guests,
-	})
+	// 3. Get room pricing from the provider
+	roomRate, err := s.providers.GetRoomRate(supplierCtx, booking.ProviderCode, roomReq)
 	if err != nil {
-		logger.ErrorWithErr(err, "Failed to get room rates from HotelBeds")
+		logger.ErrorWithErr(err, fmt.Sprintf("Failed to get room rates from %s", booking.ProviderCode))
 		return nil, fmt.Errorf("failed to get room rates: %w", err)
 	}
 
-	// 4. Create booking with REAL price from HotelBeds
-	booking := NewBooking(userID, req)
-	booking.TotalAmount = roomRate.TotalPrice
+	// 4. Price the booking from the REAL net rate of the provider, marked up
+	// like search results are, and keep the rate so confirmation books exactly
+	// what was priced
+	category, err := s.repo.GetHotelCategory(ctx, booking.ProviderCode, booking.HotelID)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to get hotel category")
+		return nil, fmt.Errorf("failed to price booking: %w", err)
+	}
+	price, err := s.pricingService.CalculateSellPrice(roomRate.NetPrice, category)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to calculate sell price")
+		return nil, fmt.Errorf("failed to price booking: %w", err)
+	}
+	booking.NetAmount = price.NetPrice
+	booking.TotalAmount = price.SellPrice
 	booking.Currency = roomRate.Currency
+	booking.RateKey = roomRate.RateKey
+	booking.RateType = roomRate.RateType
+	if deadline := roomRate.Cancellation.FreeCancellationBefore; !deadline.IsZero() {
+		booking.FreeCancelUntil = &deadline
+	}
+	// Past the deadline the supplier keeps its penalty, or the whole net rate
+	// when the rate names no lower one
+	booking.CancellationPenalty = booking.NetAmount
+	if penalty := roomRate.Cancellation.PenaltyAmount; penalty > 0 && penalty < booking.NetAmount && !roomRate.Cancellation.NonRefundable {
+		booking.CancellationPenalty = penalty
+	}
+
+	// 5. Pick the first state for the payment type
+	nextStatus := StatusAwaitingPayment
+	switch booking.PaymentType {
+	case PaymentTypePayAtHotel:
+		// No payment up front - the guest pays at the property, so we need a guarantee
+		if err := s.guaranteeChecker.CheckGuarantee(ctx, booking); err != nil {
+			logger.Warnf("Pay-at-hotel guarantee rejected for user %s: %v", userID, err)
+			return nil, err
+		}
+		nextStatus = StatusGuaranteed
+	case PaymentTypePayLater:
+		// Hold the room and charge (or remind) before free cancellation ends
+		if err := booking.SchedulePayLater(time.Now()); err != nil {
+			return nil, err
+		}
+		nextStatus = StatusOnHold
+	}
+
+	// 6. Screen for fraud - held bookings wait in RISK_REVIEW for a reviewer
+	if s.riskScreener != nil {
+		decision, err := s.riskScreener.ScreenBooking(ctx, booking, req.Client)
+		switch {
+		case err != nil:
+			// Don't turn guests away because scoring is unavailable
+			logger.ErrorWithErr(err, "Failed to screen booking for fraud")
+		case decision == RiskBlock:
+			logger.Warnf("Booking %s for user %s blocked by fraud checks", booking.ID, userID)
+			return nil, ErrBookingDeclined
+		case decision == RiskReview:
+			logger.Warnf("Booking %s for user %s held for risk review", booking.ID, userID)
+			nextStatus = StatusRiskReview
+		}
+	}
 
-	// 5. Save booking
+	// 7. Save booking
 	if err := s.repo.Create(ctx, booking); err != nil {
 		logger.ErrorWithErr(err, "Failed to create booking")
 		return nil, ErrFailedToCreate
 	}
 
-	// 6. Transition to AWAITING_PAYMENT
+	// 8. Transition to the first state of the payment flow
 	sm := NewStateMachine(booking)
-	if err := sm.Transition(StatusAwaitingPayment); err != nil {
+	if err := sm.Transition(nextStatus); err != nil {
 		logger.ErrorWithErr(err, "Failed to transition booking state")
 		return nil, err
 	}
 
-	// 7. Update status in database
+	// 9. Update status in database
 	if err := s.repo.UpdateStatus(ctx, booking.ID, booking.Status); err != nil {
 		logger.ErrorWithErr(err, "Failed to update booking status")
 		return nil, ErrFailedToUpdateStatus
 	}
 
-	// 8. Publish booking.created event
+	// 10. Publish booking.created event
 	if err := s.eventBus.Publish(ctx, eventbus.EventBookingCreated, map[string]interface{}{
 		"booking_id":        booking.ID,
 		"user_id":           booking.UserID,
 		"hotel_id":          booking.HotelID,
 		"room_id":           booking.RoomID,
+		"provider_code":     booking.ProviderCode,
 		"booking_reference": booking.BookingReference,
 		"total_amount":      booking.TotalAmount,
 		"currency":          booking.Currency,
+		"payment_type":      string(booking.PaymentType),
 		"status":            string(booking.Status),
 	}); err != nil {
 		logger.ErrorWithErr(err, "Failed to publish booking.created event")
@@ -122,6 +211,14 @@ func (s *service) CreateBooking(ctx context.Context, userID string, req *CreateB
 
 	logger.Infof("Booking created: %s (%s) - Amount: %d %s",
 		booking.ID, booking.BookingReference, booking.TotalAmount, booking.Currency)
+
+	// 11. Guaranteed pay-at-hotel bookings skip payment and go straight to the supplier
+	if booking.Status == StatusGuaranteed {
+		if err := s.confirmGuaranteed(ctx, booking); err != nil {
+			return nil, err
+		}
+	}
+
 	return booking, nil
 }
 
@@ -142,32 +239,16 @@ func (s *service) GetBookingWithDetails(ctx context.Context, bookingID string) (
 		return nil, err
 	}
 
-	// Fetch hotel details from HotelBeds
+	// Fetch hotel details from the provider
 	hotel := &HotelDetails{}
-	if s.hotelbedsClient != nil {
-		hotelData, err := s.hotelbedsClient.GetHotelDetails(ctx, booking.HotelID)
+	if s.providers != nil {
+		hotelData, err := s.providers.GetHotelDetails(ctx, booking.ProviderCode, booking.HotelID)
 		if err == nil && hotelData != nil {
-			hotel.ID = hotelData.HotelCode
-			hotel.Name = hotelData.HotelName
-			hotel.City = hotelData.CityCode
-			hotel.Country = hotelData.CountryCode
-			// Get first image if available
-			if len(hotelData.Images) > 0 {
-				hotel.Image = hotelData.Images[0].URL
-			}
-			hotel.Rating = hotelData.Rating
-			hotel.Description = hotelData.Description
+			hotel = toHotelDetails(hotelData)
 		}
 	}
 
-	// For now, use basic room info from RoomID
-	// In real implementation, we would fetch room details from HotelBeds
-	room := &RoomDetails{
-		ID:   booking.RoomID,
-		Name: "Standard Room", // Default fallback
-	}
-
-	return ToBookingResponse(booking, hotel, room), nil
+	return ToBookingResponse(booking, hotel, s.catalogRoom(ctx, booking)), nil
 }
 
 func (s *service) GetUserBookings(ctx context.Context, userID string, page, perPage int) ([]*Booking, error) {
@@ -189,50 +270,51 @@ func (s *service) GetUserBookingsWithDetails(ctx context.Context, userID string,
 		return nil, err
 	}
 
-	// Fetch unique hotel IDs
-	hotelIDs := make(map[string]bool)
+	// Fetch unique hotel IDs with the provider each was booked with
+	hotelIDs := make(map[string]string)
 	for _, b := range bookings {
-		hotelIDs[b.HotelID] = true
+		hotelIDs[b.HotelID] = b.ProviderCode
 	}
 
 	// Fetch all hotels
 	hotels := make(map[string]*HotelDetails)
-	if s.hotelbedsClient != nil {
-		for hotelID := range hotelIDs {
-			hotelData, err := s.hotelbedsClient.GetHotelDetails(ctx, hotelID)
+	if s.providers != nil {
+		for hotelID, providerCode := range hotelIDs {
+			hotelData, err := s.providers.GetHotelDetails(ctx, providerCode, hotelID)
 			if err == nil && hotelData != nil {
-				// Get first image if available
-				imageURL := ""
-				if len(hotelData.Images) > 0 {
-					imageURL = hotelData.Images[0].URL
-				}
-				hotels[hotelID] = &HotelDetails{
-					ID:          hotelData.HotelCode,
-					Name:        hotelData.HotelName,
-					City:        hotelData.CityCode,
-					Country:     hotelData.CountryCode,
-					Image:       imageURL,
-					Rating:      hotelData.Rating,
-					Description: hotelData.Description,
-				}
+				hotels[hotelID] = toHotelDetails(hotelData)
 			}
 		}
 	}
 
-	// For rooms, use basic info for now
+	// Fetch rooms from the synced room catalog
 	rooms := make(map[string]*RoomDetails)
 	for _, b := range bookings {
-		if _, exists := rooms[b.RoomID]; !exists {
-			rooms[b.RoomID] = &RoomDetails{
-				ID:   b.RoomID,
-				Name: "Standard Room", // Default fallback
-			}
+		key := roomKey(b.HotelID, b.RoomID)
+		if _, exists := rooms[key]; !exists {
+			rooms[key] = s.catalogRoom(ctx, b)
 		}
 	}
 
 	return ToBookingResponseList(bookings, hotels, rooms), nil
 }
 
+// catalogRoom returns a booking's room from the synced room catalog, or a
+// generic room when the catalog does not have it
+func (s *service) catalogRoom(ctx context.Context, booking *Booking) *RoomDetails {
+	room, err := s.repo.GetCatalogRoom(ctx, booking.ProviderCode, booking.HotelID, booking.RoomID)
+	if err != nil {
+		if !errors.Is(err, ErrRoomNotInCatalog) {
+			logger.ErrorWithErr(err, "Failed to get room from catalog")
+		}
+		return &RoomDetails{
+			ID:   booking.RoomID,
+			Name: "Standard Room", // Default fallback
+		}
+	}
+	return room
+}
+
 func (s *service) UpdateStatus(ctx context.Context, bookingID string, status BookingStatus) (*Booking, error) {
 	// Get booking
 	booking, err := s.repo.GetByID(ctx, bookingID)
@@ -271,15 +353,16 @@ func (s *service) CancelBooking(ctx context.Context, bookingID string) (*Booking
 		return nil, err
 	}
 
-	// 2. Cancel with HotelBeds supplier if already confirmed
+	// 2. Cancel with the supplier if already confirmed
 	if booking.SupplierReference != "" {
-		err := s.hotelbedsClient.CancelBooking(ctx, booking.SupplierReference)
+		supplierCtx := providertraffic.WithBookingID(ctx, booking.ID)
+		err := s.providers.CancelBooking(supplierCtx, booking.ProviderCode, booking.SupplierReference)
 		if err != nil {
-			logger.ErrorWithErr(err, "Failed to cancel booking with HotelBeds supplier")
+			logger.ErrorWithErr(err, fmt.Sprintf("Failed to cancel booking with %s supplier", booking.ProviderCode))
 			// Log error but continue - we still want to mark as cancelled locally
 			// This allows for manual reconciliation later
 		} else {
-			logger.Infof("Booking cancelled with HotelBeds supplier: %s", booking.SupplierReference)
+			logger.Infof("Booking cancelled with %s supplier: %s", booking.ProviderCode, booking.SupplierReference)
 		}
 	}
 
@@ -300,15 +383,27 @@ func (s *service) publishStatusEvent(ctx context.Context, booking *Booking, stat
 		return nil
 	}
 
-	return s.eventBus.Publish(ctx, eventType, map[string]interface{}{
-		"booking_id":        booking.ID,
-		"user_id":           booking.UserID,
-		"booking_reference": booking.BookingReference,
-		"status":            string(status),
-	})
+	payload := map[string]interface{}{
+		"booking_id":         booking.ID,
+		"user_id":            booking.UserID,
+		"booking_reference":  booking.BookingReference,
+		"supplier_reference": booking.SupplierReference,
+		"provider_code":      booking.ProviderCode,
+		"payment_type":       string(booking.PaymentType),
+		"total_amount":       booking.TotalAmount,
+		"net_amount":         booking.NetAmount,
+		"currency":           booking.Currency,
+		"check_in":           booking.CheckIn,
+		"check_out":          booking.CheckOut,
+		"status":             string(status),
+	}
+	if status == StatusCancelled {
+		payload["cancellation_penalty"] = booking.PenaltyAt(time.Now())
+	}
+	return s.eventBus.Publish(ctx, eventType, payload)
 }
 
-// ConfirmBookingWithSupplier confirms booking with HotelBeds supplier
+// ConfirmBookingWithSupplier confirms booking with the supplier it was made with
 func (s *service) ConfirmBookingWithSupplier(ctx context.Context, bookingID string) (*Booking, error) {
 	// 1. Get booking
 	booking, err := s.repo.GetByID(ctx, bookingID)
@@ -317,69 +412,286 @@ func (s *service) ConfirmBookingWithSupplier(ctx context.Context, bookingID stri
 		return nil, fmt.Errorf("booking not found: %w", err)
 	}
 
-	// 2. Validate booking status
-	if booking.Status != StatusPaid {
-		return nil, fmt.Errorf("booking must be PAID before confirming with supplier, current status: %s", booking.Status)
+	if err := s.confirmWithSupplier(ctx, booking); err != nil {
+		return nil, err
 	}
+	return booking, nil
+}
 
-	// 3. Get user information for holder details
-	// TODO: Call user service to get user name and email
-	// For now, use placeholder values
-	holderName := "Guest" // Should come from user service
-	holderEmail := "guest@example.com" // Should come from user service
+// confirmWithSupplier books the room with the booking's provider and moves the booking to CONFIRMED.
+// The booking must be PAID, or GUARANTEED for pay-at-hotel bookings.
+func (s *service) confirmWithSupplier(ctx context.Context, booking *Booking) error {
+	// 1. Validate booking status
+	if !booking.CanTransitionTo(StatusConfirmed) {
+		return fmt.Errorf("booking must be PAID or GUARANTEED before confirming with supplier, current status: %s", booking.Status)
+	}
 
-	// 4. Create booking with HotelBeds
-	hbBooking, err := s.hotelbedsClient.CreateBooking(ctx, &hotelbeds.BookingRequest{
-		HotelCode: booking.HotelID,
-		RoomCode:  booking.RoomID,
+	// 2. Book in the lead guest's name, or the account holder's
+	guest, err := s.supplierGuest(ctx, booking)
+	if err != nil {
+		logger.ErrorWithErr(err, fmt.Sprintf("Failed to get holder details of booking %s", booking.ID))
+		return err
+	}
+
+	// 3. Create booking with the provider, at the rate and cancellation terms
+	// it was priced at so the provider can refuse a rate that has since changed
+	rate := types.Rate{
+		RoomID:   booking.RoomID,
+		NetPrice: booking.NetAmount,
+		Currency: booking.Currency,
+		RateKey:  booking.RateKey,
+		RateType: booking.RateType,
+	}
+	if booking.FreeCancelUntil != nil {
+		rate.Cancellation = types.CancellationPolicy{
+			FreeCancellationBefore: *booking.FreeCancelUntil,
+			PenaltyType:            "FIXED",
+			PenaltyAmount:          booking.CancellationPenalty,
+		}
+	}
+	supplierCtx := providertraffic.WithBookingID(ctx, booking.ID)
+	confirmation, err := s.providers.CreateBooking(supplierCtx, booking.ProviderCode, &types.BookingRequest{
+		HotelID:   booking.HotelID,
+		RoomID:    booking.RoomID,
 		CheckIn:   booking.CheckIn,
 		CheckOut:  booking.CheckOut,
 		Guests:    booking.Guests,
-		Holder: hotelbeds.HolderInfo{
-			Name:  holderName,
-			Email: holderEmail,
-		},
-		Payment: hotelbeds.PaymentInfo{
-			PaymentMethodType: "CREDITCARD", // Default payment method
-		},
+		GuestInfo: guest,
+		Rate:      rate,
+		// Pay-at-hotel bookings are settled by the guest at the property
+		PayAtHotel: booking.PaymentType == PaymentTypePayAtHotel,
+		Reference:  booking.BookingReference,
 	})
 	if err != nil {
-		logger.ErrorWithErr(err, "Failed to create booking with HotelBeds")
-		return nil, fmt.Errorf("failed to confirm with supplier: %w", err)
+		logger.ErrorWithErr(err, fmt.Sprintf("Failed to create booking with %s", booking.ProviderCode))
+		if provider.IsRejection(err) {
+			return fmt.Errorf("%w: %w", ErrSupplierRejected, err)
+		}
+		// A timeout or server error may come after the supplier booked
+		return fmt.Errorf("%w: %w", ErrSupplierUnconfirmed, err)
 	}
 
-	// 5. Update booking with supplier reference
-	booking.SupplierReference = hbBooking.BookingReference
+	// 4. Update booking with supplier reference, and the net the supplier
+	// confirmed, which is what we owe them
+	booking.SupplierReference = confirmation.ProviderReference
+	if confirmation.TotalPrice > 0 {
+		if confirmation.TotalPrice != booking.NetAmount {
+			logger.Warnf("Booking %s confirmed by %s at net %d, priced at %d",
+				booking.ID, booking.ProviderCode, confirmation.TotalPrice, booking.NetAmount)
+		}
+		booking.NetAmount = confirmation.TotalPrice
+	}
 
-	// 6. Transition to CONFIRMED
+	// 5. Transition to CONFIRMED
 	sm := NewStateMachine(booking)
 	if err := sm.Transition(StatusConfirmed); err != nil {
 		logger.ErrorWithErr(err, "Failed to transition booking to CONFIRMED")
-		return nil, err
+		return err
 	}
 
-	// 7. Update status in database
-	if err := s.repo.UpdateStatus(ctx, booking.ID, booking.Status); err != nil {
+	// 6. Save the confirmation
+	if err := s.repo.Update(ctx, booking); err != nil {
 		logger.ErrorWithErr(err, "Failed to update booking status")
-		return nil, ErrFailedToUpdateStatus
+		return ErrFailedToUpdateStatus
 	}
 
-	// 8. Publish booking.confirmed event
+	// 7. Publish booking.confirmed event
 	if err := s.eventBus.Publish(ctx, eventbus.EventBookingConfirmed, map[string]interface{}{
 		"booking_id":         booking.ID,
 		"user_id":            booking.UserID,
 		"booking_reference":  booking.BookingReference,
-		"supplier_reference": hbBooking.BookingReference,
+		"supplier_reference": confirmation.ProviderReference,
+		"provider_code":      booking.ProviderCode,
+		"payment_type":       string(booking.PaymentType),
+		"total_amount":       booking.TotalAmount,
+		"net_amount":         booking.NetAmount,
+		"currency":           booking.Currency,
+		"check_in":           booking.CheckIn,
+		"check_out":          booking.CheckOut,
 		"status":             string(booking.Status),
 	}); err != nil {
 		logger.ErrorWithErr(err, "Failed to publish booking.confirmed event")
 	}
 
-	logger.Infof("Booking confirmed with supplier: %s - Supplier Ref: %s",
-		booking.BookingReference, hbBooking.BookingReference)
+	logger.Infof("Booking confirmed with %s: %s - Supplier Ref: %s",
+		booking.ProviderCode, booking.BookingReference, confirmation.ProviderReference)
+	return nil
+}
+
+// supplierGuest returns the lead guest a supplier booking is made for: the
+// guest named on the booking, with the account holder's details filling in
+// what the booking doesn't give
+func (s *service) supplierGuest(ctx context.Context, booking *Booking) (types.GuestInfo, error) {
+	guest := types.GuestInfo{
+		Email: strings.TrimSpace(booking.GuestEmail),
+		Phone: strings.TrimSpace(booking.GuestPhone),
+	}
+	guest.FirstName, guest.LastName = splitName(booking.GuestName)
+	if guest.FirstName != "" && guest.Email != "" {
+		return guest, nil
+	}
+
+	holder, err := s.repo.GetAccountHolder(ctx, booking.UserID)
+	if err != nil {
+		return types.GuestInfo{}, err
+	}
+	if guest.FirstName == "" {
+		guest.FirstName, guest.LastName = strings.TrimSpace(holder.FirstName), strings.TrimSpace(holder.LastName)
+	}
+	if guest.Email == "" {
+		guest.Email = holder.Email
+	}
+	if guest.Phone == "" {
+		guest.Phone = holder.Phone
+	}
+	return guest, nil
+}
+
+// splitName splits a full name into a first name and the rest as last name
+func splitName(name string) (string, string) {
+	first, last, _ := strings.Cut(strings.TrimSpace(name), " ")
+	return first, strings.TrimSpace(last)
+}
+
+// confirmGuaranteed confirms a GUARANTEED pay-at-hotel booking with the
+// supplier. Nothing was charged, so a booking the supplier turned down is
+// cancelled rather than left GUARANTEED without a supplier reference. When
+// the outcome is unknown the supplier may hold the room, so the booking
+// stays GUARANTEED for reconciliation to match it with the supplier's.
+func (s *service) confirmGuaranteed(ctx context.Context, booking *Booking) error {
+	err := s.confirmWithSupplier(ctx, booking)
+	if errors.Is(err, ErrSupplierUnconfirmed) {
+		logger.Warnf("Booking %s left GUARANTEED: confirmation by %s unknown", booking.ID, booking.ProviderCode)
+		return nil
+	}
+	if err == nil || !errors.Is(err, ErrSupplierRejected) {
+		return err
+	}
+
+	sm := NewStateMachine(booking)
+	if cancelErr := sm.Transition(StatusCancelled); cancelErr != nil {
+		logger.ErrorWithErr(cancelErr, "Failed to transition booking to CANCELLED")
+		return err
+	}
+	if cancelErr := s.repo.UpdateStatus(ctx, booking.ID, booking.Status); cancelErr != nil {
+		logger.ErrorWithErr(cancelErr, fmt.Sprintf("Failed to cancel booking %s the supplier did not confirm", booking.ID))
+		return err
+	}
+	if cancelErr := s.publishStatusEvent(ctx, booking, StatusCancelled); cancelErr != nil {
+		logger.ErrorWithErr(cancelErr, "Failed to publish booking.cancelled event")
+	}
+
+	logger.Warnf("Booking %s cancelled: not confirmed by %s", booking.ID, booking.ProviderCode)
+	return err
+}
+
+// HoldForReview holds a booking whose payment was flagged by fraud checks
+func (s *service) HoldForReview(ctx context.Context, bookingID string) (*Booking, error) {
+	booking, err := s.repo.GetByID(ctx, bookingID)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to get booking")
+		return nil, err
+	}
+
+	if booking.Status == StatusRiskReview {
+		return booking, nil
+	}
+
+	return s.UpdateStatus(ctx, bookingID, StatusRiskReview)
+}
+
+// ReleaseFromReview moves an approved booking back into its payment flow.
+// awaitingPayment is set when the booking was held while paying rather than
+// at creation. Approved pay-at-hotel bookings are confirmed with the supplier.
+func (s *service) ReleaseFromReview(ctx context.Context, bookingID string, awaitingPayment bool) (*Booking, error) {
+	booking, err := s.repo.GetByID(ctx, bookingID)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to get booking")
+		return nil, err
+	}
+
+	if booking.Status != StatusRiskReview {
+		return nil, ErrNotUnderReview
+	}
+
+	booking, err = s.UpdateStatus(ctx, bookingID, reviewReleaseStatus(booking, awaitingPayment))
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status == StatusGuaranteed {
+		if err := s.confirmGuaranteed(ctx, booking); err != nil {
+			return nil, err
+		}
+	}
+
 	return booking, nil
 }
 
+// ProcessDuePayLaterBookings moves PAY_LATER bookings along once their charge is due.
+// ON_HOLD bookings become AWAITING_PAYMENT and a booking.payment_due event triggers the
+// automatic charge (saved card) or a payment reminder. Bookings still unpaid when free
+// cancellation ends are cancelled so the room is released.
+func (s *service) ProcessDuePayLaterBookings(ctx context.Context) error {
+	now := time.Now()
+	bookings, err := s.repo.GetDuePayLater(ctx, now, 100)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to get due pay-later bookings")
+		return err
+	}
+
+	for _, booking := range bookings {
+		switch booking.Status {
+		case StatusOnHold:
+			if err := s.requestPayLaterPayment(ctx, booking); err != nil {
+				logger.ErrorWithErr(err, fmt.Sprintf("Failed to request payment for booking %s", booking.ID))
+			}
+		case StatusAwaitingPayment:
+			logger.Infof("Pay-later booking %s unpaid at free-cancellation deadline, cancelling", booking.ID)
+			if _, err := s.CancelBooking(ctx, booking.ID); err != nil {
+				logger.ErrorWithErr(err, fmt.Sprintf("Failed to cancel unpaid booking %s", booking.ID))
+			}
+		}
+	}
+
+	if len(bookings) > 0 {
+		logger.Infof("Processed %d due pay-later bookings", len(bookings))
+	}
+	return nil
+}
+
+// requestPayLaterPayment moves an ON_HOLD booking to AWAITING_PAYMENT and announces that payment is due.
+// The move only happens if the booking is still ON_HOLD, so when several job runs pick up the same
+// booking, only one of them announces it.
+func (s *service) requestPayLaterPayment(ctx context.Context, booking *Booking) error {
+	sm := NewStateMachine(booking)
+	if err := sm.Transition(StatusAwaitingPayment); err != nil {
+		return err
+	}
+
+	if err := s.repo.TransitionStatus(ctx, booking.ID, StatusOnHold, booking.Status); err != nil {
+		if errors.Is(err, ErrStatusChanged) {
+			logger.Infof("Pay-later booking %s already moved on, skipping", booking.ID)
+			return nil
+		}
+		return ErrFailedToUpdateStatus
+	}
+
+	payload := map[string]interface{}{
+		"booking_id":        booking.ID,
+		"user_id":           booking.UserID,
+		"booking_reference": booking.BookingReference,
+		"total_amount":      booking.TotalAmount,
+		"currency":          booking.Currency,
+		"auto_charge":       booking.PaymentToken != "",
+	}
+	if booking.FreeCancelUntil != nil {
+		payload["free_cancellation_until"] = booking.FreeCancelUntil.Format(time.RFC3339)
+	}
+
+	return s.eventBus.Publish(ctx, eventbus.EventBookingPaymentDue, payload)
+}
+
 // UpdateBooking updates booking details
 func (s *service) UpdateBooking(ctx context.Context, bookingID string, req *UpdateBookingRequest) (*Booking, error) {
 	logger.Infof("Updating booking: %s", bookingID)
@@ -392,7 +704,7 @@ func (s *service) UpdateBooking(ctx context.Context, bookingID string, req *Upda
 	}
 
 	// 2. Validate booking status - can only update certain statuses
-	if booking.Status != StatusInit && booking.Status != StatusAwaitingPayment {
+	if booking.Status != StatusInit && booking.Status != StatusAwaitingPayment && booking.Status != StatusOnHold {
 		return nil, fmt.Errorf("cannot update booking with status: %s", booking.Status)
 	}
 
@@ -409,12 +721,10 @@ func (s *service) UpdateBooking(ctx context.Context, bookingID string, req *Upda
 	if req.SpecialRequests != "" {
 		booking.SpecialRequests = req.SpecialRequests
 	}
-	if req.PaymentType != "" {
-		// Validate payment type
-		if req.PaymentType != PaymentTypePayNow && req.PaymentType != PaymentTypePayAtHotel && req.PaymentType != PaymentTypePayLater {
-			return nil, ErrInvalidPaymentType
-		}
-		booking.PaymentType = req.PaymentType
+	// The payment type is settled at creation: its guarantee, schedule and
+	// state path were set up for it
+	if req.PaymentType != "" && req.PaymentType != booking.PaymentType {
+		return nil, ErrPaymentTypeChange
 	}
 
 	// 4. Update in database
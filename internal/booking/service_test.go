@@ -3,11 +3,14 @@ package booking
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
-	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
 	"github.com/ekonugroho98/be-bookingkuy/internal/pricing"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
+	"github.com/ekonugroho98/be-bookingkuy/internal/providertraffic"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -50,6 +53,40 @@ func (m *MockRepository) UpdateStatus(ctx context.Context, id string, status Boo
 	return args.Error(0)
 }
 
+func (m *MockRepository) TransitionStatus(ctx context.Context, id string, from, to BookingStatus) error {
+	args := m.Called(ctx, id, from, to)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetDuePayLater(ctx context.Context, now time.Time, limit int) ([]*Booking, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Booking), args.Error(1)
+}
+
+func (m *MockRepository) GetHotelCategory(ctx context.Context, providerCode, hotelID string) (pricing.HotelCategory, error) {
+	args := m.Called(ctx, providerCode, hotelID)
+	return args.Get(0).(pricing.HotelCategory), args.Error(1)
+}
+
+func (m *MockRepository) GetAccountHolder(ctx context.Context, userID string) (*AccountHolder, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*AccountHolder), args.Error(1)
+}
+
+func (m *MockRepository) GetCatalogRoom(ctx context.Context, providerCode, hotelID, roomID string) (*RoomDetails, error) {
+	args := m.Called(ctx, providerCode, hotelID, roomID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RoomDetails), args.Error(1)
+}
+
 // MockEventBus is a mock implementation of eventbus.EventBus
 type MockEventBus struct {
 	mock.Mock
@@ -88,56 +125,69 @@ func (m *MockPricingService) CalculateMargin(netPrice, sellPrice int) (int, floa
 	return args.Int(0), args.Get(1).(float64)
 }
 
-// MockHotelbedsClient is a mock implementation of hotelbeds.ClientInterface
-type MockHotelbedsClient struct {
+// MockProviderGateway is a mock implementation of provider.Gateway
+type MockProviderGateway struct {
 	mock.Mock
 }
 
-func (m *MockHotelbedsClient) GetHotelAvailability(ctx context.Context, req *hotelbeds.AvailabilityRequest) (*hotelbeds.AvailabilityResponse, error) {
-	args := m.Called(ctx, req)
+func (m *MockProviderGateway) CheckAvailability(ctx context.Context, code string, req *types.RoomRequest) (*types.HotelAvailability, error) {
+	args := m.Called(ctx, code, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.HotelAvailability), args.Error(1)
+}
+
+func (m *MockProviderGateway) CheckHotelsAvailability(ctx context.Context, code string, req *types.HotelsRequest) ([]types.HotelAvailability, error) {
+	args := m.Called(ctx, code, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*hotelbeds.AvailabilityResponse), args.Error(1)
+	return args.Get(0).([]types.HotelAvailability), args.Error(1)
 }
 
-func (m *MockHotelbedsClient) GetRoomRates(ctx context.Context, req *hotelbeds.RoomRateRequest) (*hotelbeds.RoomRateResponse, error) {
-	args := m.Called(ctx, req)
+func (m *MockProviderGateway) GetRoomRate(ctx context.Context, code string, req *types.RoomRequest) (*types.Rate, error) {
+	args := m.Called(ctx, code, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*hotelbeds.RoomRateResponse), args.Error(1)
+	return args.Get(0).(*types.Rate), args.Error(1)
 }
 
-func (m *MockHotelbedsClient) GetHotelDetails(ctx context.Context, hotelCode string) (*hotelbeds.HotelDetailsResponse, error) {
-	args := m.Called(ctx, hotelCode)
+func (m *MockProviderGateway) GetHotelDetails(ctx context.Context, code, hotelID string) (*types.Hotel, error) {
+	args := m.Called(ctx, code, hotelID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*hotelbeds.HotelDetailsResponse), args.Error(1)
+	return args.Get(0).(*types.Hotel), args.Error(1)
 }
 
-func (m *MockHotelbedsClient) CreateBooking(ctx context.Context, req *hotelbeds.BookingRequest) (*hotelbeds.BookingResponse, error) {
-	args := m.Called(ctx, req)
+func (m *MockProviderGateway) CreateBooking(ctx context.Context, code string, req *types.BookingRequest) (*types.BookingConfirmation, error) {
+	args := m.Called(ctx, code, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*hotelbeds.BookingResponse), args.Error(1)
+	return args.Get(0).(*types.BookingConfirmation), args.Error(1)
 }
 
-func (m *MockHotelbedsClient) CancelBooking(ctx context.Context, bookingReference string) error {
-	args := m.Called(ctx, bookingReference)
+func (m *MockProviderGateway) CancelBooking(ctx context.Context, code, bookingReference string) error {
+	args := m.Called(ctx, code, bookingReference)
 	return args.Error(0)
 }
 
+func (m *MockProviderGateway) GetBookingStatus(ctx context.Context, code, bookingReference string) (string, error) {
+	args := m.Called(ctx, code, bookingReference)
+	return args.String(0), args.Error(1)
+}
+
 // TestNewService tests creating a new booking service
 func TestNewService(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	require.NotNil(t, service)
 }
@@ -147,9 +197,9 @@ func TestService_CreateBooking_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	userID := "user-123"
@@ -163,37 +213,8 @@ func TestService_CreateBooking_Success(t *testing.T) {
 	}
 
 	// Setup HotelBeds mock expectations
-	mockHB.On("GetHotelAvailability", ctx, mock.AnythingOfType("*hotelbeds.AvailabilityRequest")).Return(&hotelbeds.AvailabilityResponse{
-		HotelCode:   req.HotelID,
-		HotelName:   "Test Hotel",
-		IsAvailable: true,
-		Rooms: []hotelbeds.Room{
-			{
-				RoomCode:  req.RoomID,
-				RoomName:  "Test Room",
-				Available: true,
-				Price:     1500000,
-				Currency:  "IDR",
-			},
-		},
-		TotalPrice: 1500000,
-		Currency:   "IDR",
-	}, nil)
-
-	mockHB.On("GetRoomRates", ctx, mock.AnythingOfType("*hotelbeds.RoomRateRequest")).Return(&hotelbeds.RoomRateResponse{
-		HotelCode:  req.HotelID,
-		RoomCode:   req.RoomID,
-		RoomName:   "Test Room",
-		TotalPrice: 1500000,
-		Currency:   "IDR",
-		Rates: []hotelbeds.Rate{
-			{
-				RateCode: "RATE-1",
-				Price:    1500000,
-				Currency: "IDR",
-			},
-		},
-	}, nil)
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1500000)
 
 	// Setup repository and event bus expectations
 	mockRepo.On("Create", ctx, mock.AnythingOfType("*booking.Booking")).Return(nil)
@@ -218,12 +239,13 @@ func TestService_CreateBooking_Success(t *testing.T) {
 	assert.NotEmpty(t, booking.BookingReference)
 	assert.Contains(t, booking.BookingReference, "BKG-")
 	assert.Equal(t, "IDR", booking.Currency)
-	assert.Equal(t, 1500000, booking.TotalAmount) // Real price from HotelBeds!
+	assert.Equal(t, 1500000, booking.NetAmount)   // Real price from HotelBeds!
+	assert.Equal(t, 1770000, booking.TotalAmount) // Sold with the four-star markup
 
 	// Verify all mocks were called
 	mockRepo.AssertExpectations(t)
 	mockEB.AssertExpectations(t)
-	mockHB.AssertExpectations(t)
+	mockProviders.AssertExpectations(t)
 }
 
 // TestService_CreateBooking_RepositoryError tests booking creation with repository error
@@ -231,9 +253,9 @@ func TestService_CreateBooking_RepositoryError(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	userID := "user-123"
@@ -247,37 +269,8 @@ func TestService_CreateBooking_RepositoryError(t *testing.T) {
 	}
 
 	// Setup HotelBeds mock expectations (success, but repo fails)
-	mockHB.On("GetHotelAvailability", ctx, mock.AnythingOfType("*hotelbeds.AvailabilityRequest")).Return(&hotelbeds.AvailabilityResponse{
-		HotelCode:   req.HotelID,
-		HotelName:   "Test Hotel",
-		IsAvailable: true,
-		Rooms: []hotelbeds.Room{
-			{
-				RoomCode:  req.RoomID,
-				RoomName:  "Test Room",
-				Available: true,
-				Price:     1500000,
-				Currency:  "IDR",
-			},
-		},
-		TotalPrice: 1500000,
-		Currency:   "IDR",
-	}, nil)
-
-	mockHB.On("GetRoomRates", ctx, mock.AnythingOfType("*hotelbeds.RoomRateRequest")).Return(&hotelbeds.RoomRateResponse{
-		HotelCode:  req.HotelID,
-		RoomCode:   req.RoomID,
-		RoomName:   "Test Room",
-		TotalPrice: 1500000,
-		Currency:   "IDR",
-		Rates: []hotelbeds.Rate{
-			{
-				RateCode: "RATE-1",
-				Price:    1500000,
-				Currency: "IDR",
-			},
-		},
-	}, nil)
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1500000)
 
 	// Setup expectations - Create fails
 	mockRepo.On("Create", ctx, mock.AnythingOfType("*booking.Booking")).Return(errors.New("database error"))
@@ -291,7 +284,7 @@ func TestService_CreateBooking_RepositoryError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to create booking")
 
 	mockRepo.AssertExpectations(t)
-	mockHB.AssertExpectations(t)
+	mockProviders.AssertExpectations(t)
 }
 
 // TestService_CreateBooking_UpdateStatusError tests booking creation with update status error
@@ -299,9 +292,9 @@ func TestService_CreateBooking_UpdateStatusError(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	userID := "user-123"
@@ -315,37 +308,8 @@ func TestService_CreateBooking_UpdateStatusError(t *testing.T) {
 	}
 
 	// Setup HotelBeds mock expectations
-	mockHB.On("GetHotelAvailability", ctx, mock.AnythingOfType("*hotelbeds.AvailabilityRequest")).Return(&hotelbeds.AvailabilityResponse{
-		HotelCode:   req.HotelID,
-		HotelName:   "Test Hotel",
-		IsAvailable: true,
-		Rooms: []hotelbeds.Room{
-			{
-				RoomCode:  req.RoomID,
-				RoomName:  "Test Room",
-				Available: true,
-				Price:     1500000,
-				Currency:  "IDR",
-			},
-		},
-		TotalPrice: 1500000,
-		Currency:   "IDR",
-	}, nil)
-
-	mockHB.On("GetRoomRates", ctx, mock.AnythingOfType("*hotelbeds.RoomRateRequest")).Return(&hotelbeds.RoomRateResponse{
-		HotelCode:  req.HotelID,
-		RoomCode:   req.RoomID,
-		RoomName:   "Test Room",
-		TotalPrice: 1500000,
-		Currency:   "IDR",
-		Rates: []hotelbeds.Rate{
-			{
-				RateCode: "RATE-1",
-				Price:    1500000,
-				Currency: "IDR",
-			},
-		},
-	}, nil)
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1500000)
 
 	// Setup expectations
 	mockRepo.On("Create", ctx, mock.AnythingOfType("*booking.Booking")).Return(nil)
@@ -360,7 +324,7 @@ func TestService_CreateBooking_UpdateStatusError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to update booking status")
 
 	mockRepo.AssertExpectations(t)
-	mockHB.AssertExpectations(t)
+	mockProviders.AssertExpectations(t)
 }
 
 // TestService_GetBooking_Success tests successful booking retrieval
@@ -368,9 +332,9 @@ func TestService_GetBooking_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	bookingID := "booking-123"
@@ -407,9 +371,9 @@ func TestService_GetBooking_NotFound(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	bookingID := "non-existent-booking"
@@ -432,9 +396,9 @@ func TestService_GetUserBookings_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	userID := "user-123"
@@ -481,9 +445,9 @@ func TestService_GetUserBookings_Empty(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	userID := "user-123"
@@ -525,8 +489,8 @@ func TestService_GetUserBookings_Pagination(t *testing.T) {
 			mockRepo := new(MockRepository)
 			mockEB := new(MockEventBus)
 			mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
-			service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	mockProviders := new(MockProviderGateway)
+			service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 			// Setup expectations
 			mockRepo.On("GetByUserID", ctx, userID, tt.perPage, tt.expected).Return([]*Booking{}, nil)
@@ -546,9 +510,9 @@ func TestService_UpdateStatus_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	bookingID := "booking-123"
@@ -583,9 +547,9 @@ func TestService_UpdateStatus_InvalidTransition(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	bookingID := "booking-123"
@@ -617,9 +581,9 @@ func TestService_UpdateStatus_BookingNotFound(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	bookingID := "non-existent-booking"
@@ -642,9 +606,9 @@ func TestService_UpdateStatus_UpdateError(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	bookingID := "booking-123"
@@ -677,9 +641,9 @@ func TestService_CancelBooking_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockEB := new(MockEventBus)
 	mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-	service := NewService(mockRepo, mockEB, mockPS, mockHB)
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 	ctx := context.Background()
 	bookingID := "booking-123"
@@ -709,6 +673,151 @@ func TestService_CancelBooking_Success(t *testing.T) {
 	mockEB.AssertExpectations(t)
 }
 
+// TestService_CancelBooking_PublishesPenalty tests that cancelling a
+// supplier-confirmed booking past its free-cancellation deadline publishes
+// the supplier's net rate and penalty
+func TestService_CancelBooking_PublishesPenalty(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
+
+	ctx := context.Background()
+	bookingID := "booking-123"
+	deadline := time.Now().Add(-time.Hour)
+	existingBooking := &Booking{
+		ID:                  bookingID,
+		UserID:              "user-123",
+		BookingReference:    "BKG-ABC123",
+		SupplierReference:   "HB-123",
+		ProviderCode:        DefaultProviderCode,
+		Status:              StatusConfirmed,
+		TotalAmount:         1770000,
+		NetAmount:           1500000,
+		CancellationPenalty: 750000,
+		FreeCancelUntil:     &deadline,
+	}
+
+	var payload map[string]interface{}
+	mockRepo.On("GetByID", ctx, bookingID).Return(existingBooking, nil)
+	mockRepo.On("UpdateStatus", ctx, bookingID, StatusCancelled).Return(nil)
+	mockProviders.On("CancelBooking", mock.Anything, DefaultProviderCode, "HB-123").Return(nil)
+	mockEB.On("Publish", ctx, "booking.cancelled", mock.AnythingOfType("map[string]interface {}")).
+		Run(func(args mock.Arguments) { payload = args.Get(2).(map[string]interface{}) }).
+		Return(nil)
+
+	_, err := service.CancelBooking(ctx, bookingID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1770000, payload["total_amount"])
+	assert.Equal(t, 1500000, payload["net_amount"])
+	assert.Equal(t, 750000, payload["cancellation_penalty"])
+}
+
+// TestService_ConfirmBookingWithSupplier_KeepsConfirmedNet tests that the
+// net the supplier confirms is saved and published as what we owe them
+func TestService_ConfirmBookingWithSupplier_KeepsConfirmedNet(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
+
+	ctx := context.Background()
+	bookingID := "booking-123"
+	existingBooking := &Booking{
+		ID:               bookingID,
+		UserID:           "user-123",
+		BookingReference: "BKG-ABC123",
+		ProviderCode:     DefaultProviderCode,
+		Status:           StatusPaid,
+		TotalAmount:      1770000,
+		NetAmount:        1500000,
+		Currency:         "IDR",
+	}
+
+	var payload map[string]interface{}
+	mockRepo.On("GetByID", ctx, bookingID).Return(existingBooking, nil)
+	setupAccountHolder(mockRepo)
+	mockProviders.On("CreateBooking", mock.Anything, DefaultProviderCode, mock.AnythingOfType("*types.BookingRequest")).
+		Return(&types.BookingConfirmation{ProviderReference: "HB-123", TotalPrice: 1525000, Currency: "IDR"}, nil)
+	mockRepo.On("Update", ctx, mock.MatchedBy(func(b *Booking) bool {
+		return b.Status == StatusConfirmed && b.SupplierReference == "HB-123" && b.NetAmount == 1525000
+	})).Return(nil)
+	mockEB.On("Publish", ctx, "booking.confirmed", mock.AnythingOfType("map[string]interface {}")).
+		Run(func(args mock.Arguments) { payload = args.Get(2).(map[string]interface{}) }).
+		Return(nil)
+
+	booking, err := service.ConfirmBookingWithSupplier(ctx, bookingID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1525000, booking.NetAmount)
+	assert.Equal(t, 1770000, payload["total_amount"])
+	assert.Equal(t, 1525000, payload["net_amount"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestService_SupplierGuest tests that the supplier books in the guest's name
+// and falls back to the account holder only for missing details
+func TestService_SupplierGuest(t *testing.T) {
+	tests := []struct {
+		name     string
+		booking  *Booking
+		lookup   bool
+		expected types.GuestInfo
+	}{
+		{
+			name:     "guest named on booking",
+			booking:  &Booking{UserID: "user-123", GuestName: "John van Doe", GuestEmail: "john@example.com"},
+			expected: types.GuestInfo{FirstName: "John", LastName: "van Doe", Email: "john@example.com"},
+		},
+		{
+			name:     "guest without email",
+			booking:  &Booking{UserID: "user-123", GuestName: "John Doe"},
+			lookup:   true,
+			expected: types.GuestInfo{FirstName: "John", LastName: "Doe", Email: "jane@example.com", Phone: "+6281200000000"},
+		},
+		{
+			name:     "no guest details",
+			booking:  &Booking{UserID: "user-123"},
+			lookup:   true,
+			expected: types.GuestInfo{FirstName: "Jane", LastName: "Account", Email: "jane@example.com", Phone: "+6281200000000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			svc := &service{repo: mockRepo}
+			if tt.lookup {
+				setupAccountHolder(mockRepo)
+			}
+
+			guest, err := svc.supplierGuest(context.Background(), tt.booking)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, guest)
+			if !tt.lookup {
+				mockRepo.AssertNotCalled(t, "GetAccountHolder", mock.Anything, mock.Anything)
+			}
+		})
+	}
+
+	t.Run("account holder lookup fails", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := &service{repo: mockRepo}
+		mockRepo.On("GetAccountHolder", mock.Anything, "user-404").Return(nil, ErrAccountHolderNotFound)
+
+		_, err := svc.supplierGuest(context.Background(), &Booking{UserID: "user-404"})
+
+		assert.ErrorIs(t, err, ErrAccountHolderNotFound)
+	})
+}
+
 // TestService_UpdateStatus_AllStatuses tests all status transitions
 func TestService_UpdateStatus_AllStatuses(t *testing.T) {
 	statusEvents := map[BookingStatus]string{
@@ -722,9 +831,9 @@ func TestService_UpdateStatus_AllStatuses(t *testing.T) {
 			mockRepo := new(MockRepository)
 			mockEB := new(MockEventBus)
 			mockPS := new(MockPricingService)
-	mockHB := new(MockHotelbedsClient)
+	mockProviders := new(MockProviderGateway)
 
-			service := NewService(mockRepo, mockEB, mockPS, mockHB)
+			service := NewService(mockRepo, mockEB, mockPS, mockProviders)
 
 			ctx := context.Background()
 			bookingID := "booking-123"
@@ -806,3 +915,713 @@ func TestGenerateBookingReference(t *testing.T) {
 	assert.NotEqual(t, ref1, ref2, "Each reference should be unique")
 	assert.Len(t, ref1, 12) // "BKG-" + 8 characters
 }
+
+// supplierCtx matches the booking-tagged contexts supplier calls are made with
+var supplierCtx = mock.MatchedBy(func(ctx context.Context) bool {
+	return providertraffic.BookingIDFromContext(ctx) != ""
+})
+
+// setupAvailableRoom sets provider expectations for an available room at the given price
+func setupAvailableRoom(ctx interface{}, mockProviders *MockProviderGateway, req *CreateBookingRequest, price int) {
+	mockProviders.On("CheckAvailability", ctx, DefaultProviderCode, mock.AnythingOfType("*types.RoomRequest")).Return(&types.HotelAvailability{
+		Hotel: types.Hotel{ID: req.HotelID, Name: "Test Hotel"},
+		Rooms: []types.RoomRate{
+			{
+				Room:  types.Room{ID: req.RoomID, HotelID: req.HotelID, Name: "Test Room"},
+				Rates: []types.Rate{{RoomID: req.RoomID, NetPrice: price, Currency: "IDR"}},
+			},
+		},
+		Provider: DefaultProviderCode,
+	}, nil)
+
+	mockProviders.On("GetRoomRate", ctx, DefaultProviderCode, mock.AnythingOfType("*types.RoomRequest")).Return(&types.Rate{
+		RoomID:   req.RoomID,
+		NetPrice: price,
+		Currency: "IDR",
+	}, nil)
+}
+
+// setupFourStarPricing prices the room of setupAvailableRoom with the 18%
+// four-star markup
+func setupFourStarPricing(mockRepo *MockRepository, mockPS *MockPricingService, req *CreateBookingRequest, price int) {
+	mockRepo.On("GetHotelCategory", mock.Anything, DefaultProviderCode, req.HotelID).Return(pricing.CategoryFourStar, nil)
+	mockPS.On("CalculateSellPrice", price, pricing.CategoryFourStar).Return(&pricing.PriceCalculation{
+		NetPrice:  price,
+		SellPrice: price * 118 / 100,
+		Margin:    price * 18 / 100,
+	}, nil)
+}
+
+// stubCardVerifier verifies saved cards unless err is set
+type stubCardVerifier struct {
+	err    error
+	tokens []string
+}
+
+func (v *stubCardVerifier) VerifySavedCard(ctx context.Context, bookingID, savedTokenID string) error {
+	v.tokens = append(v.tokens, savedTokenID)
+	return v.err
+}
+
+// setupAccountHolder sets up the account holder supplier bookings fall back to
+func setupAccountHolder(mockRepo *MockRepository) {
+	mockRepo.On("GetAccountHolder", mock.Anything, "user-123").Return(&AccountHolder{
+		FirstName: "Jane",
+		LastName:  "Account",
+		Email:     "jane@example.com",
+		Phone:     "+6281200000000",
+	}, nil)
+}
+
+// newGuaranteedService creates a service that accepts saved cards as a
+// pay-at-hotel guarantee
+func newGuaranteedService(repo Repository, eb *MockEventBus, ps *MockPricingService, providers *MockProviderGateway) Service {
+	return NewServiceWithGuarantee(repo, eb, ps, providers, NewGuaranteeChecker(&stubCardVerifier{}, 0))
+}
+
+// TestService_CreateBooking_PayAtHotel tests that pay-at-hotel bookings are confirmed without payment
+func TestService_CreateBooking_PayAtHotel(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := newGuaranteedService(mockRepo, mockEB, mockPS, mockProviders)
+
+	ctx := context.Background()
+	req := &CreateBookingRequest{
+		HotelID:     "hotel-123",
+		RoomID:      "room-123",
+		CheckIn:     time.Now().Add(24 * time.Hour),
+		CheckOut:    time.Now().Add(48 * time.Hour),
+		Guests:      2,
+		PaymentType:  PaymentTypePayAtHotel,
+		PaymentToken: "saved-token-123",
+	}
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1500000)
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*booking.Booking")).Return(nil)
+	mockRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), StatusGuaranteed).Return(nil)
+	mockRepo.On("Update", ctx, mock.MatchedBy(func(b *Booking) bool { return b.Status == StatusConfirmed })).Return(nil)
+	setupAccountHolder(mockRepo)
+	mockProviders.On("CreateBooking", supplierCtx, DefaultProviderCode, mock.MatchedBy(func(r *types.BookingRequest) bool {
+		return r.PayAtHotel
+	})).Return(&types.BookingConfirmation{ProviderReference: "HB-123"}, nil)
+	mockEB.On("Publish", ctx, "booking.created", mock.AnythingOfType("map[string]interface {}")).Return(nil)
+	mockEB.On("Publish", ctx, "booking.confirmed", mock.AnythingOfType("map[string]interface {}")).Return(nil)
+
+	booking, err := service.CreateBooking(ctx, "user-123", req)
+
+	require.NoError(t, err)
+	require.NotNil(t, booking)
+	assert.Equal(t, StatusConfirmed, booking.Status)
+	assert.Equal(t, "HB-123", booking.SupplierReference)
+
+	mockRepo.AssertExpectations(t)
+	mockEB.AssertExpectations(t)
+	mockProviders.AssertExpectations(t)
+}
+
+// TestService_CreateBooking_PayAtHotel_SupplierFails tests that a pay-at-hotel
+// booking the supplier turns down is cancelled, not left GUARANTEED
+func TestService_CreateBooking_PayAtHotel_SupplierFails(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := newGuaranteedService(mockRepo, mockEB, mockPS, mockProviders)
+
+	ctx := context.Background()
+	req := &CreateBookingRequest{
+		HotelID:     "hotel-123",
+		RoomID:      "room-123",
+		CheckIn:     time.Now().Add(24 * time.Hour),
+		CheckOut:    time.Now().Add(48 * time.Hour),
+		Guests:      2,
+		PaymentType:  PaymentTypePayAtHotel,
+		PaymentToken: "saved-token-123",
+	}
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1500000)
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*booking.Booking")).Return(nil)
+	mockRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), StatusGuaranteed).Return(nil)
+	mockRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), StatusCancelled).Return(nil)
+	setupAccountHolder(mockRepo)
+	mockProviders.On("CreateBooking", supplierCtx, DefaultProviderCode, mock.AnythingOfType("*types.BookingRequest")).
+		Return(nil, fmt.Errorf("%w: allotment exhausted", provider.ErrRejected))
+	mockEB.On("Publish", ctx, "booking.created", mock.AnythingOfType("map[string]interface {}")).Return(nil)
+	mockEB.On("Publish", ctx, "booking.cancelled", mock.MatchedBy(func(data map[string]interface{}) bool {
+		return data["status"] == string(StatusCancelled)
+	})).Return(nil)
+
+	booking, err := service.CreateBooking(ctx, "user-123", req)
+
+	require.ErrorIs(t, err, ErrSupplierRejected)
+	assert.Nil(t, booking)
+	mockRepo.AssertNotCalled(t, "Update", ctx, mock.Anything)
+	mockRepo.AssertExpectations(t)
+	mockEB.AssertExpectations(t)
+}
+
+// TestService_CreateBooking_PayAtHotel_SupplierTimeout tests that a pay-at-hotel
+// booking whose supplier call timed out stays GUARANTEED, as the supplier
+// may have booked it
+func TestService_CreateBooking_PayAtHotel_SupplierTimeout(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := newGuaranteedService(mockRepo, mockEB, mockPS, mockProviders)
+
+	ctx := context.Background()
+	req := &CreateBookingRequest{
+		HotelID:      "hotel-123",
+		RoomID:       "room-123",
+		CheckIn:      time.Now().Add(24 * time.Hour),
+		CheckOut:     time.Now().Add(48 * time.Hour),
+		Guests:       2,
+		PaymentType:  PaymentTypePayAtHotel,
+		PaymentToken: "saved-token-123",
+	}
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1500000)
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*booking.Booking")).Return(nil)
+	mockRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), StatusGuaranteed).Return(nil)
+	setupAccountHolder(mockRepo)
+	mockProviders.On("CreateBooking", supplierCtx, DefaultProviderCode, mock.AnythingOfType("*types.BookingRequest")).
+		Return(nil, fmt.Errorf("failed to create booking: %w", context.DeadlineExceeded))
+	mockEB.On("Publish", ctx, "booking.created", mock.AnythingOfType("map[string]interface {}")).Return(nil)
+
+	booking, err := service.CreateBooking(ctx, "user-123", req)
+
+	require.NoError(t, err)
+	assert.Equal(t, StatusGuaranteed, booking.Status)
+	assert.Empty(t, booking.SupplierReference)
+	mockRepo.AssertNotCalled(t, "UpdateStatus", ctx, mock.Anything, StatusCancelled)
+	mockEB.AssertNotCalled(t, "Publish", ctx, "booking.cancelled", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestService_CreateBooking_BooksPricedRate tests that the priced rate key and
+// the supplier's cancellation deadline are kept and used at confirmation
+func TestService_CreateBooking_BooksPricedRate(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := newGuaranteedService(mockRepo, mockEB, mockPS, mockProviders)
+
+	ctx := context.Background()
+	req := &CreateBookingRequest{
+		HotelID:     "hotel-123",
+		RoomID:      "room-123",
+		CheckIn:     time.Now().Add(72 * time.Hour),
+		CheckOut:    time.Now().Add(96 * time.Hour),
+		Guests:      2,
+		PaymentType:  PaymentTypePayAtHotel,
+		PaymentToken: "saved-token-123",
+	}
+	deadline := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1650000)
+	mockProviders.ExpectedCalls[1].ReturnArguments = mock.Arguments{&types.Rate{
+		RoomID:       req.RoomID,
+		NetPrice:     1650000,
+		Currency:     "IDR",
+		Cancellation: types.CancellationPolicy{FreeCancellationBefore: deadline, PenaltyType: "FIXED", PenaltyAmount: 825000},
+		RateKey:      "rechecked-key",
+		RateType:     "BOOKABLE",
+	}, nil}
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*booking.Booking")).Return(nil)
+	mockRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), StatusGuaranteed).Return(nil)
+	mockRepo.On("Update", ctx, mock.MatchedBy(func(b *Booking) bool { return b.Status == StatusConfirmed })).Return(nil)
+	setupAccountHolder(mockRepo)
+	mockProviders.On("CreateBooking", supplierCtx, DefaultProviderCode, mock.MatchedBy(func(r *types.BookingRequest) bool {
+		return r.Rate.RateKey == "rechecked-key" && r.Rate.RateType == "BOOKABLE" && r.Reference != "" &&
+			r.Rate.NetPrice == 1650000
+	})).Return(&types.BookingConfirmation{ProviderReference: "HB-123"}, nil)
+	mockEB.On("Publish", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("map[string]interface {}")).Return(nil)
+
+	booking, err := service.CreateBooking(ctx, "user-123", req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1947000, booking.TotalAmount)
+	assert.Equal(t, 1650000, booking.NetAmount)
+	assert.Equal(t, "rechecked-key", booking.RateKey)
+	require.NotNil(t, booking.FreeCancelUntil)
+	assert.True(t, booking.FreeCancelUntil.Equal(deadline))
+	assert.Equal(t, 825000, booking.CancellationPenalty)
+
+	mockProviders.AssertExpectations(t)
+}
+
+// TestService_CreateBooking_PayAtHotel_GuaranteeRequired tests that unguaranteed bookings are rejected
+func TestService_CreateBooking_PayAtHotel_GuaranteeRequired(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := newGuaranteedService(mockRepo, mockEB, mockPS, mockProviders)
+
+	ctx := context.Background()
+	req := &CreateBookingRequest{
+		HotelID:     "hotel-123",
+		RoomID:      "room-123",
+		CheckIn:     time.Now().Add(24 * time.Hour),
+		CheckOut:    time.Now().Add(48 * time.Hour),
+		Guests:      2,
+		PaymentType: PaymentTypePayAtHotel,
+	}
+	setupAvailableRoom(supplierCtx, mockProviders, req, 500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 500000)
+
+	booking, err := service.CreateBooking(ctx, "user-123", req)
+
+	require.ErrorIs(t, err, ErrGuaranteeRequired)
+	assert.Nil(t, booking)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestService_CreateBooking_PayAtHotel_CardNotVerified tests that a card the
+// gateway does not verify is no guarantee
+func TestService_CreateBooking_PayAtHotel_CardNotVerified(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+	verifier := &stubCardVerifier{err: errors.New("card declined")}
+
+	service := NewServiceWithGuarantee(mockRepo, mockEB, mockPS, mockProviders, NewGuaranteeChecker(verifier, 0))
+
+	ctx := context.Background()
+	req := &CreateBookingRequest{
+		HotelID:      "hotel-123",
+		RoomID:       "room-123",
+		CheckIn:      time.Now().Add(24 * time.Hour),
+		CheckOut:     time.Now().Add(48 * time.Hour),
+		Guests:       2,
+		PaymentType:  PaymentTypePayAtHotel,
+		PaymentToken: "x",
+	}
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1500000)
+
+	booking, err := service.CreateBooking(ctx, "user-123", req)
+
+	require.ErrorIs(t, err, ErrInvalidGuarantee)
+	assert.Nil(t, booking)
+	assert.Equal(t, []string{"x"}, verifier.tokens)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestGuaranteeChecker tests which pay-at-hotel bookings count as guaranteed
+func TestGuaranteeChecker(t *testing.T) {
+	tests := []struct {
+		name     string
+		checker  GuaranteeChecker
+		booking  *Booking
+		expected error
+	}{
+		{"verified card", NewGuaranteeChecker(&stubCardVerifier{}, 0), &Booking{PaymentToken: "tok", Currency: "IDR", TotalAmount: 50000000}, nil},
+		{"card without verifier", NewGuaranteeChecker(nil, 0), &Booking{PaymentToken: "tok", Currency: "IDR", TotalAmount: 500000}, ErrInvalidGuarantee},
+		{"no card, threshold off", NewGuaranteeChecker(&stubCardVerifier{}, 0), &Booking{Currency: "IDR", TotalAmount: 500000}, ErrGuaranteeRequired},
+		{"no card, under threshold", NewGuaranteeChecker(&stubCardVerifier{}, 2000000), &Booking{Currency: "IDR", TotalAmount: 2000000}, nil},
+		{"no card, over threshold", NewGuaranteeChecker(&stubCardVerifier{}, 2000000), &Booking{Currency: "IDR", TotalAmount: 2000001}, ErrGuaranteeRequired},
+		{"no card, other currency", NewGuaranteeChecker(&stubCardVerifier{}, 2000000), &Booking{Currency: "USD", TotalAmount: 100}, ErrGuaranteeRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.checker.CheckGuarantee(context.Background(), tt.booking)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+// TestService_CreateBooking_InvalidBilling tests that billing details need a company name and tax ID
+func TestService_CreateBooking_InvalidBilling(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
+
+	req := &CreateBookingRequest{
+		HotelID:     "hotel-123",
+		RoomID:      "room-123",
+		CheckIn:     time.Now().Add(24 * time.Hour),
+		CheckOut:    time.Now().Add(48 * time.Hour),
+		Guests:      2,
+		PaymentType: PaymentTypePayNow,
+		Billing:     &BillingInfo{CompanyName: "PT Maju Bersama"},
+	}
+
+	booking, err := service.CreateBooking(context.Background(), "user-123", req)
+
+	require.ErrorIs(t, err, ErrInvalidBilling)
+	assert.Nil(t, booking)
+	mockProviders.AssertNotCalled(t, "GetHotelAvailability", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestService_CreateBooking_PayLater tests that pay-later bookings are held with a charge schedule
+func TestService_CreateBooking_PayLater(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
+
+	ctx := context.Background()
+	req := &CreateBookingRequest{
+		HotelID:      "hotel-123",
+		RoomID:       "room-123",
+		CheckIn:      time.Now().Add(10 * 24 * time.Hour),
+		CheckOut:     time.Now().Add(12 * 24 * time.Hour),
+		Guests:       2,
+		PaymentType:  PaymentTypePayLater,
+		PaymentToken: "saved-token-123",
+	}
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1500000)
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*booking.Booking")).Return(nil)
+	mockRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), StatusOnHold).Return(nil)
+	mockEB.On("Publish", ctx, "booking.created", mock.AnythingOfType("map[string]interface {}")).Return(nil)
+
+	booking, err := service.CreateBooking(ctx, "user-123", req)
+
+	require.NoError(t, err)
+	require.NotNil(t, booking)
+	assert.Equal(t, StatusOnHold, booking.Status)
+	assert.Equal(t, "saved-token-123", booking.PaymentToken)
+	require.NotNil(t, booking.PaymentDueAt)
+	require.NotNil(t, booking.FreeCancelUntil)
+	assert.True(t, booking.PaymentDueAt.Before(*booking.FreeCancelUntil))
+
+	mockRepo.AssertExpectations(t)
+	mockEB.AssertExpectations(t)
+	mockProviders.AssertNotCalled(t, "CreateBooking", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestService_CreateBooking_PayLaterTooLate tests that pay later is refused
+// when free cancellation ends before the guest would have time to pay
+func TestService_CreateBooking_PayLaterTooLate(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
+
+	ctx := context.Background()
+	req := &CreateBookingRequest{
+		HotelID:     "hotel-123",
+		RoomID:      "room-123",
+		CheckIn:     time.Now().Add(2 * 24 * time.Hour),
+		CheckOut:    time.Now().Add(4 * 24 * time.Hour),
+		Guests:      2,
+		PaymentType: PaymentTypePayLater,
+	}
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1500000)
+
+	booking, err := service.CreateBooking(ctx, "user-123", req)
+
+	require.ErrorIs(t, err, ErrPayLaterUnavailable)
+	assert.Nil(t, booking)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestService_ProcessDuePayLaterBookings tests charge requests and release of unpaid bookings
+func TestService_ProcessDuePayLaterBookings(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := NewService(mockRepo, mockEB, mockPS, mockProviders)
+
+	ctx := context.Background()
+	onHold := &Booking{
+		ID:           "booking-hold",
+		Status:       StatusOnHold,
+		PaymentType:  PaymentTypePayLater,
+		TotalAmount:  1500000,
+		PaymentToken: "saved-token-123",
+	}
+	unpaid := &Booking{
+		ID:          "booking-unpaid",
+		Status:      StatusAwaitingPayment,
+		PaymentType: PaymentTypePayLater,
+	}
+
+	mockRepo.On("GetDuePayLater", ctx, mock.AnythingOfType("time.Time"), 100).Return([]*Booking{onHold, unpaid}, nil)
+	mockRepo.On("TransitionStatus", ctx, "booking-hold", StatusOnHold, StatusAwaitingPayment).Return(nil)
+	mockEB.On("Publish", ctx, "booking.payment_due", mock.MatchedBy(func(p map[string]interface{}) bool {
+		_, hasToken := p["payment_token"]
+		return p["booking_id"] == "booking-hold" && p["auto_charge"] == true && !hasToken
+	})).Return(nil)
+
+	mockRepo.On("GetByID", ctx, "booking-unpaid").Return(unpaid, nil)
+	mockRepo.On("UpdateStatus", ctx, "booking-unpaid", StatusCancelled).Return(nil)
+	mockEB.On("Publish", ctx, "booking.cancelled", mock.AnythingOfType("map[string]interface {}")).Return(nil)
+
+	err := service.ProcessDuePayLaterBookings(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, StatusAwaitingPayment, onHold.Status)
+	assert.Equal(t, StatusCancelled, unpaid.Status)
+	mockRepo.AssertExpectations(t)
+	mockEB.AssertExpectations(t)
+}
+
+// TestService_ProcessDuePayLaterBookings_AlreadyMoved tests that a booking
+// another run already moved on is not announced again
+func TestService_ProcessDuePayLaterBookings_AlreadyMoved(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	service := NewService(mockRepo, mockEB, new(MockPricingService), new(MockProviderGateway))
+
+	ctx := context.Background()
+	onHold := &Booking{
+		ID:           "booking-hold",
+		Status:       StatusOnHold,
+		PaymentType:  PaymentTypePayLater,
+		PaymentToken: "saved-token-123",
+	}
+
+	mockRepo.On("GetDuePayLater", ctx, mock.AnythingOfType("time.Time"), 100).Return([]*Booking{onHold}, nil)
+	mockRepo.On("TransitionStatus", ctx, "booking-hold", StatusOnHold, StatusAwaitingPayment).Return(ErrStatusChanged)
+
+	err := service.ProcessDuePayLaterBookings(ctx)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockEB.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// stubRiskScreener returns a fixed decision
+type stubRiskScreener struct {
+	decision  RiskDecision
+	client    ClientInfo
+	guestName string
+}
+
+func (s *stubRiskScreener) ScreenBooking(ctx context.Context, booking *Booking, client ClientInfo) (RiskDecision, error) {
+	s.client = client
+	s.guestName = booking.GuestName
+	return s.decision, nil
+}
+
+// TestService_CreateBooking_RiskReview tests that flagged bookings are held instead of confirmed
+func TestService_CreateBooking_RiskReview(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+	screener := &stubRiskScreener{decision: RiskReview}
+
+	service := NewServiceWithRisk(mockRepo, mockEB, mockPS, mockProviders, NewGuaranteeChecker(&stubCardVerifier{}, 0), screener)
+
+	ctx := context.Background()
+	req := &CreateBookingRequest{
+		HotelID:     "hotel-123",
+		RoomID:      "room-123",
+		CheckIn:     time.Now().Add(24 * time.Hour),
+		CheckOut:    time.Now().Add(48 * time.Hour),
+		Guests:      2,
+		GuestName:    "John Doe",
+		PaymentType:  PaymentTypePayAtHotel,
+		PaymentToken: "saved-token-123",
+		Client:       ClientInfo{IPAddress: "203.0.113.7", Country: "ID"},
+	}
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1500000)
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*booking.Booking")).Return(nil)
+	mockRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), StatusRiskReview).Return(nil)
+	mockEB.On("Publish", ctx, "booking.created", mock.AnythingOfType("map[string]interface {}")).Return(nil)
+
+	booking, err := service.CreateBooking(ctx, "user-123", req)
+
+	require.NoError(t, err)
+	assert.Equal(t, StatusRiskReview, booking.Status)
+	assert.Equal(t, "203.0.113.7", screener.client.IPAddress)
+	assert.Equal(t, "John Doe", screener.guestName, "the guest name is screened against the account holder")
+	assert.Equal(t, "John Doe", booking.GuestName)
+	mockRepo.AssertExpectations(t)
+	mockProviders.AssertNotCalled(t, "CreateBooking", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestService_CreateBooking_RiskBlocked tests that blocked bookings are never saved
+func TestService_CreateBooking_RiskBlocked(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockPS := new(MockPricingService)
+	mockProviders := new(MockProviderGateway)
+
+	service := NewServiceWithRisk(mockRepo, mockEB, mockPS, mockProviders, NewGuaranteeChecker(nil, 0), &stubRiskScreener{decision: RiskBlock})
+
+	ctx := context.Background()
+	req := &CreateBookingRequest{
+		HotelID:     "hotel-123",
+		RoomID:      "room-123",
+		CheckIn:     time.Now().Add(24 * time.Hour),
+		CheckOut:    time.Now().Add(48 * time.Hour),
+		Guests:      2,
+		PaymentType: PaymentTypePayNow,
+	}
+	setupAvailableRoom(supplierCtx, mockProviders, req, 1500000)
+	setupFourStarPricing(mockRepo, mockPS, req, 1500000)
+
+	booking, err := service.CreateBooking(ctx, "user-123", req)
+
+	require.ErrorIs(t, err, ErrBookingDeclined)
+	assert.Nil(t, booking)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestService_ReleaseFromReview tests where approved bookings resume their flow
+func TestService_ReleaseFromReview(t *testing.T) {
+	tests := []struct {
+		name            string
+		paymentType     PaymentType
+		awaitingPayment bool
+		expected        BookingStatus
+	}{
+		{"pay now", PaymentTypePayNow, false, StatusAwaitingPayment},
+		{"pay later at creation", PaymentTypePayLater, false, StatusOnHold},
+		{"pay later while paying", PaymentTypePayLater, true, StatusAwaitingPayment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockEB := new(MockEventBus)
+			service := NewService(mockRepo, mockEB, new(MockPricingService), new(MockProviderGateway))
+
+			ctx := context.Background()
+			held := &Booking{ID: "booking-123", Status: StatusRiskReview, PaymentType: tt.paymentType}
+			mockRepo.On("GetByID", ctx, "booking-123").Return(held, nil)
+			mockRepo.On("UpdateStatus", ctx, "booking-123", tt.expected).Return(nil)
+
+			booking, err := service.ReleaseFromReview(ctx, "booking-123", tt.awaitingPayment)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, booking.Status)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestService_ReleaseFromReview_NotHeld tests that only held bookings can be released
+func TestService_ReleaseFromReview_NotHeld(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, new(MockEventBus), new(MockPricingService), new(MockProviderGateway))
+
+	ctx := context.Background()
+	mockRepo.On("GetByID", ctx, "booking-123").Return(&Booking{ID: "booking-123", Status: StatusCancelled}, nil)
+
+	_, err := service.ReleaseFromReview(ctx, "booking-123", false)
+
+	require.ErrorIs(t, err, ErrNotUnderReview)
+}
+
+// TestService_ReleaseFromReview_PayAtHotelSupplierFails tests that an approved
+// pay-at-hotel booking the supplier turns down is cancelled
+func TestService_ReleaseFromReview_PayAtHotelSupplierFails(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+	mockProviders := new(MockProviderGateway)
+	service := NewService(mockRepo, mockEB, new(MockPricingService), mockProviders)
+
+	ctx := context.Background()
+	held := &Booking{ID: "booking-123", Status: StatusRiskReview, PaymentType: PaymentTypePayAtHotel, ProviderCode: DefaultProviderCode,
+		GuestName: "John Doe", GuestEmail: "john@example.com"}
+	mockRepo.On("GetByID", ctx, "booking-123").Return(held, nil)
+	mockRepo.On("UpdateStatus", ctx, "booking-123", StatusGuaranteed).Return(nil)
+	mockRepo.On("UpdateStatus", ctx, "booking-123", StatusCancelled).Return(nil)
+	mockProviders.On("CreateBooking", mock.Anything, DefaultProviderCode, mock.AnythingOfType("*types.BookingRequest")).
+		Return(nil, fmt.Errorf("%w: allotment exhausted", provider.ErrRejected))
+	mockEB.On("Publish", ctx, "booking.cancelled", mock.AnythingOfType("map[string]interface {}")).Return(nil)
+
+	booking, err := service.ReleaseFromReview(ctx, "booking-123", false)
+
+	require.ErrorIs(t, err, ErrSupplierRejected)
+	assert.Nil(t, booking)
+	assert.Equal(t, StatusCancelled, held.Status)
+	mockRepo.AssertExpectations(t)
+	mockEB.AssertExpectations(t)
+}
+
+// TestService_GetBookingWithDetails_RoomFromCatalog tests that booking responses use the synced room catalog
+func TestService_GetBookingWithDetails_RoomFromCatalog(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("room in catalog", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, nil, nil, nil)
+
+		mockRepo.On("GetByID", ctx, "booking-1").Return(&Booking{
+			ID: "booking-1", HotelID: "12345", RoomID: "DBL.ST", ProviderCode: DefaultProviderCode,
+		}, nil)
+		mockRepo.On("GetCatalogRoom", ctx, DefaultProviderCode, "12345", "DBL.ST").Return(&RoomDetails{
+			ID: "DBL.ST", Name: "DOUBLE STANDARD", Image: "https://photos.example/dbl.jpg",
+		}, nil)
+
+		response, err := service.GetBookingWithDetails(ctx, "booking-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "DOUBLE STANDARD", response.RoomName)
+		assert.Equal(t, "https://photos.example/dbl.jpg", response.RoomImage)
+	})
+
+	t.Run("room not in catalog", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, nil, nil, nil)
+
+		mockRepo.On("GetByID", ctx, "booking-2").Return(&Booking{
+			ID: "booking-2", HotelID: "12345", RoomID: "SUI.ST", ProviderCode: DefaultProviderCode,
+		}, nil)
+		mockRepo.On("GetCatalogRoom", ctx, DefaultProviderCode, "12345", "SUI.ST").Return(nil, ErrRoomNotInCatalog)
+
+		response, err := service.GetBookingWithDetails(ctx, "booking-2")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Standard Room", response.RoomName)
+		assert.Empty(t, response.RoomImage)
+	})
+}
+
+// TestService_UpdateBooking_RejectsPaymentTypeChange tests that a booking
+// keeps the payment type it was guaranteed and scheduled for
+func TestService_UpdateBooking_RejectsPaymentTypeChange(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, nil, nil, nil)
+
+	mockRepo.On("GetByID", ctx, "booking-1").Return(&Booking{
+		ID: "booking-1", Status: StatusOnHold, PaymentType: PaymentTypePayLater,
+	}, nil)
+
+	_, err := service.UpdateBooking(ctx, "booking-1", &UpdateBookingRequest{PaymentType: PaymentTypePayAtHotel})
+
+	require.ErrorIs(t, err, ErrPaymentTypeChange)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
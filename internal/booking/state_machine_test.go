@@ -2,6 +2,7 @@ package booking
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -332,6 +333,167 @@ func TestStateMachine_FullBookingFlow(t *testing.T) {
 	assert.True(t, sm.IsFinal())
 }
 
+// TestStateMachine_PaymentTypePaths tests the state path of each payment type
+func TestStateMachine_PaymentTypePaths(t *testing.T) {
+	tests := []struct {
+		name        string
+		paymentType PaymentType
+		path        []BookingStatus
+	}{
+		{
+			name:        "PAY_NOW",
+			paymentType: PaymentTypePayNow,
+			path:        []BookingStatus{StatusAwaitingPayment, StatusPaid, StatusConfirmed, StatusCompleted},
+		},
+		{
+			name:        "PAY_AT_HOTEL",
+			paymentType: PaymentTypePayAtHotel,
+			path:        []BookingStatus{StatusGuaranteed, StatusConfirmed, StatusCompleted},
+		},
+		{
+			name:        "PAY_LATER",
+			paymentType: PaymentTypePayLater,
+			path:        []BookingStatus{StatusOnHold, StatusAwaitingPayment, StatusPaid, StatusConfirmed, StatusCompleted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := &Booking{
+				ID:          "test-booking",
+				Status:      StatusInit,
+				PaymentType: tt.paymentType,
+			}
+
+			sm := NewStateMachine(booking)
+			for _, status := range tt.path {
+				require.NoError(t, sm.Transition(status))
+				assert.Equal(t, status, sm.GetStatus())
+			}
+			assert.True(t, sm.IsFinal())
+		})
+	}
+}
+
+// TestBooking_CanTransitionTo_PaymentTypes tests transitions that depend on payment type
+func TestBooking_CanTransitionTo_PaymentTypes(t *testing.T) {
+	tests := []struct {
+		name         string
+		paymentType  PaymentType
+		currentState BookingStatus
+		targetState  BookingStatus
+		expected     bool
+	}{
+		{name: "pay now cannot be guaranteed", paymentType: PaymentTypePayNow, currentState: StatusInit, targetState: StatusGuaranteed, expected: false},
+		{name: "pay now cannot be held", paymentType: PaymentTypePayNow, currentState: StatusInit, targetState: StatusOnHold, expected: false},
+		{name: "pay at hotel skips payment", paymentType: PaymentTypePayAtHotel, currentState: StatusInit, targetState: StatusAwaitingPayment, expected: false},
+		{name: "pay at hotel cannot be paid", paymentType: PaymentTypePayAtHotel, currentState: StatusGuaranteed, targetState: StatusPaid, expected: false},
+		{name: "pay at hotel guaranteed can cancel", paymentType: PaymentTypePayAtHotel, currentState: StatusGuaranteed, targetState: StatusCancelled, expected: true},
+		{name: "pay later must hold first", paymentType: PaymentTypePayLater, currentState: StatusInit, targetState: StatusAwaitingPayment, expected: false},
+		{name: "pay later hold cannot confirm", paymentType: PaymentTypePayLater, currentState: StatusOnHold, targetState: StatusConfirmed, expected: false},
+		{name: "pay later hold can cancel", paymentType: PaymentTypePayLater, currentState: StatusOnHold, targetState: StatusCancelled, expected: true},
+		{name: "unknown payment type", paymentType: PaymentType("CRYPTO"), currentState: StatusInit, targetState: StatusAwaitingPayment, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := &Booking{
+				ID:          "test-booking",
+				Status:      tt.currentState,
+				PaymentType: tt.paymentType,
+			}
+
+			assert.Equal(t, tt.expected, booking.CanTransitionTo(tt.targetState))
+		})
+	}
+}
+
+// TestBooking_SchedulePayLater tests the pay-later charge schedule
+func TestBooking_SchedulePayLater(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("check-in far away", func(t *testing.T) {
+		booking := &Booking{CheckIn: now.Add(10 * 24 * time.Hour)}
+		require.NoError(t, booking.SchedulePayLater(now))
+
+		require.NotNil(t, booking.FreeCancelUntil)
+		require.NotNil(t, booking.PaymentDueAt)
+		assert.Equal(t, booking.CheckIn.Add(-DefaultFreeCancellationWindow), *booking.FreeCancelUntil)
+		assert.Equal(t, booking.FreeCancelUntil.Add(-PayLaterChargeLeadTime), *booking.PaymentDueAt)
+	})
+
+	t.Run("deadline within the lead time", func(t *testing.T) {
+		booking := &Booking{CheckIn: now.Add(DefaultFreeCancellationWindow + PayLaterMinPaymentWindow + time.Hour)}
+		require.NoError(t, booking.SchedulePayLater(now))
+
+		require.NotNil(t, booking.PaymentDueAt)
+		assert.Equal(t, now.Add(time.Hour), *booking.PaymentDueAt)
+	})
+
+	t.Run("check-in close by", func(t *testing.T) {
+		booking := &Booking{CheckIn: now.Add(24 * time.Hour)}
+
+		assert.ErrorIs(t, booking.SchedulePayLater(now), ErrPayLaterUnavailable)
+		assert.Nil(t, booking.FreeCancelUntil)
+		assert.Nil(t, booking.PaymentDueAt)
+	})
+
+	t.Run("supplier deadline passed", func(t *testing.T) {
+		supplierDeadline := now.Add(-time.Hour)
+		booking := &Booking{CheckIn: now.Add(10 * 24 * time.Hour), FreeCancelUntil: &supplierDeadline}
+
+		assert.ErrorIs(t, booking.SchedulePayLater(now), ErrPayLaterUnavailable)
+	})
+
+	t.Run("supplier deadline", func(t *testing.T) {
+		supplierDeadline := now.Add(5 * 24 * time.Hour)
+		booking := &Booking{CheckIn: now.Add(10 * 24 * time.Hour), FreeCancelUntil: &supplierDeadline}
+		require.NoError(t, booking.SchedulePayLater(now))
+
+		assert.Equal(t, supplierDeadline, *booking.FreeCancelUntil)
+		assert.Equal(t, supplierDeadline.Add(-PayLaterChargeLeadTime), *booking.PaymentDueAt)
+	})
+}
+
+// TestBooking_PenaltyAt tests what the supplier charges for a cancellation
+func TestBooking_PenaltyAt(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	deadline := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		booking  Booking
+		expected int
+	}{
+		{
+			name:     "before the deadline",
+			booking:  Booking{SupplierReference: "HB-1", CancellationPenalty: 500000, FreeCancelUntil: &deadline},
+			expected: 0,
+		},
+		{
+			name:     "non-refundable",
+			booking:  Booking{SupplierReference: "HB-1", CancellationPenalty: 500000},
+			expected: 500000,
+		},
+		{
+			name:     "not confirmed with the supplier",
+			booking:  Booking{CancellationPenalty: 500000},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.booking.PenaltyAt(now))
+		})
+	}
+
+	t.Run("after the deadline", func(t *testing.T) {
+		booking := Booking{SupplierReference: "HB-1", CancellationPenalty: 500000, FreeCancelUntil: &deadline}
+		assert.Equal(t, 500000, booking.PenaltyAt(deadline.Add(time.Minute)))
+	})
+}
+
 // TestStateMachine_CancellationFlow tests booking cancellation at various stages
 func TestStateMachine_CancellationFlow(t *testing.T) {
 	cancellationStages := []struct {
@@ -5,24 +5,24 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-
+	"github.com/ekonugroho98/be-bookingkuy/internal/search"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
 )
 
 // Handler handles destination-related HTTP requests
 type Handler struct {
-	db *pgxpool.Pool
+	search search.Service
 }
 
 // NewHandler creates a new destinations handler
-func NewHandler(db *pgxpool.Pool) *Handler {
+func NewHandler(searchService search.Service) *Handler {
 	return &Handler{
-		db: db,
+		search: searchService,
 	}
 }
 
-// AutocompleteResponse represents the autocomplete response
+// AutocompleteResponse represents the autocomplete response of this route,
+// kept for clients written against it
 type AutocompleteResponse struct {
 	Code        string   `json:"code"`
 	Name        string   `json:"name"`
@@ -39,7 +39,9 @@ type AutocompleteRequest struct {
 	Limit int
 }
 
-// Autocomplete handles destination autocomplete requests
+// Autocomplete handles destination autocomplete requests. It adapts the
+// search package's autocomplete to this route's original response shape;
+// new clients use GET /api/v1/search/autocomplete?types=region,city,landmark.
 // GET /api/v1/destinations/autocomplete?q={query}&limit={limit}
 func (h *Handler) Autocomplete(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
@@ -55,55 +57,33 @@ func (h *Handler) Autocomplete(w http.ResponseWriter, r *http.Request) {
 		limit = 10
 	}
 
-	// Use PostgreSQL full-text search for autocomplete with priority to exact prefix matches
-	searchQuery := `
-		SELECT
-			code,
-			name,
-			country_code,
-			country_name,
-			type,
-			latitude,
-			longitude
-		FROM destinations
-		WHERE name ILIKE $1
-		ORDER BY
-			CASE WHEN name ILIKE $2 THEN 0 ELSE 1 END,
-			name
-		LIMIT $3
-	`
-
-	rows, err := h.db.Query(r.Context(), searchQuery, "%"+query+"%", query+"%", limit)
+	// Destinations only, from the search package's autocomplete
+	result, err := h.search.Autocomplete(r.Context(), &search.AutocompleteRequest{
+		Query: query,
+		Limit: limit,
+		Types: []search.AutocompleteResultType{
+			search.AutocompleteTypeRegion,
+			search.AutocompleteTypeCity,
+			search.AutocompleteTypeLandmark,
+		},
+	})
 	if err != nil {
 		logger.Errorf("Failed to search destinations: %v", err)
 		http.Error(w, `{"error":"failed to search destinations"}`, http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
 
-	var results []AutocompleteResponse
-	for rows.Next() {
-		var dest AutocompleteResponse
-		err := rows.Scan(
-			&dest.Code,
-			&dest.Name,
-			&dest.CountryCode,
-			&dest.CountryName,
-			&dest.Type,
-			&dest.Latitude,
-			&dest.Longitude,
-		)
-		if err != nil {
-			logger.Errorf("Failed to scan destination row: %v", err)
-			continue
-		}
-		results = append(results, dest)
-	}
-
-	if err = rows.Err(); err != nil {
-		logger.Errorf("Error iterating destination rows: %v", err)
-		http.Error(w, `{"error":"failed to process results"}`, http.StatusInternalServerError)
-		return
+	results := []AutocompleteResponse{}
+	for _, dest := range result.Results {
+		results = append(results, AutocompleteResponse{
+			Code:        dest.Code,
+			Name:        dest.Name,
+			CountryCode: dest.CountryCode,
+			CountryName: dest.CountryName,
+			Type:        dest.DestinationType,
+			Latitude:    dest.Latitude,
+			Longitude:   dest.Longitude,
+		})
 	}
 
 	// Build response
@@ -0,0 +1,105 @@
+package destinations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/search"
+)
+
+// stubSearch answers autocompletes with a fixed response and records the
+// request. Other search methods are unused.
+type stubSearch struct {
+	search.Service
+	response *search.AutocompleteResponse
+	err      error
+	request  *search.AutocompleteRequest
+}
+
+func (s *stubSearch) Autocomplete(ctx context.Context, req *search.AutocompleteRequest) (*search.AutocompleteResponse, error) {
+	s.request = req
+	return s.response, s.err
+}
+
+func pointerToFloat64(f float64) *float64 {
+	return &f
+}
+
+// TestHandler_Autocomplete tests that the route keeps its response shape and
+// asks for destinations only
+func TestHandler_Autocomplete(t *testing.T) {
+	stub := &stubSearch{response: &search.AutocompleteResponse{
+		Query: "jogja",
+		Results: []search.AutocompleteResult{{
+			Type:            search.AutocompleteTypeCity,
+			ID:              "Yogyakarta",
+			Code:            "JOG",
+			DestinationType: "CITY",
+			Name:            "Yogyakarta",
+			CountryCode:     "ID",
+			CountryName:     "Indonesia",
+			Latitude:        pointerToFloat64(-7.7956),
+			Longitude:       pointerToFloat64(110.3695),
+			MatchedAlias:    "Jogja",
+		}},
+	}}
+	handler := NewHandler(stub)
+
+	rr := httptest.NewRecorder()
+	handler.Autocomplete(rr, httptest.NewRequest("GET", "/api/v1/destinations/autocomplete?q=jogja&limit=30", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var body struct {
+		Data []AutocompleteResponse `json:"data"`
+		Meta map[string]interface{} `json:"meta"`
+	}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, AutocompleteResponse{
+		Code:        "JOG",
+		Name:        "Yogyakarta",
+		CountryCode: "ID",
+		CountryName: "Indonesia",
+		Type:        "CITY",
+		Latitude:    pointerToFloat64(-7.7956),
+		Longitude:   pointerToFloat64(110.3695),
+	}, body.Data[0])
+	assert.Equal(t, "jogja", body.Meta["query"])
+	assert.Equal(t, float64(1), body.Meta["count"])
+	assert.Equal(t, float64(30), body.Meta["limit"])
+
+	require.NotNil(t, stub.request)
+	assert.Equal(t, 30, stub.request.Limit)
+	assert.NotContains(t, stub.request.Types, search.AutocompleteTypeHotel)
+	assert.Contains(t, stub.request.Types, search.AutocompleteTypeLandmark)
+}
+
+// TestHandler_Autocomplete_MissingQuery tests that q is required
+func TestHandler_Autocomplete_MissingQuery(t *testing.T) {
+	handler := NewHandler(&stubSearch{})
+
+	rr := httptest.NewRecorder()
+	handler.Autocomplete(rr, httptest.NewRequest("GET", "/api/v1/destinations/autocomplete", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+// TestHandler_Autocomplete_SearchError tests that search failures are a 500
+func TestHandler_Autocomplete_SearchError(t *testing.T) {
+	handler := NewHandler(&stubSearch{err: errors.New("connection refused")})
+
+	rr := httptest.NewRecorder()
+	handler.Autocomplete(rr, httptest.NewRequest("GET", "/api/v1/destinations/autocomplete?q=bali", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Contains(t, rr.Body.String(), "failed to search destinations")
+}
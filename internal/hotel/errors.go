@@ -0,0 +1,12 @@
+package hotel
+
+import "errors"
+
+var (
+	// ErrContentNotFound is returned when a hotel has no content in a language
+	ErrContentNotFound = errors.New("hotel content not found")
+
+	// ErrInactiveHotelNotFound is returned when reactivating a hotel that is
+	// unknown or already active
+	ErrInactiveHotelNotFound = errors.New("inactive hotel not found")
+)
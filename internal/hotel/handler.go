@@ -2,10 +2,13 @@ package hotel
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/ekonugroho98/be-bookingkuy/internal/admin"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
 )
 
@@ -27,15 +30,19 @@ func (h *Handler) GetHotel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger.Infof("GetHotel request for hotel: %s", hotelID)
+	language := ParseAcceptLanguage(r.Header.Get("Accept-Language"))
 
-	hotel, err := h.service.GetHotel(r.Context(), hotelID)
+	logger.Infof("GetHotel request for hotel: %s (language=%s)", hotelID, language)
+
+	hotel, err := h.service.GetHotel(r.Context(), hotelID, language)
 	if err != nil {
 		logger.ErrorWithErr(err, "Failed to get hotel")
 		respondWithError(w, http.StatusNotFound, "Hotel not found")
 		return
 	}
 
+	w.Header().Set("Content-Language", hotel.Language)
+	w.Header().Add("Vary", "Accept-Language")
 	respondWithJSON(w, http.StatusOK, hotel)
 }
 
@@ -114,6 +121,71 @@ func (h *Handler) GetImages(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, response)
 }
 
+// ListInactiveHotels handles GET /api/v1/admin/hotels/inactive
+// Filters: ?reason= (e.g. SUPPLIER_REMOVED), ?limit=, ?offset=.
+func (h *Handler) ListInactiveHotels(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 || l > 100 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+		limit = l
+	}
+
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		o, err := strconv.Atoi(offsetStr)
+		if err != nil || o < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset parameter")
+			return
+		}
+		offset = o
+	}
+
+	hotels, total, err := h.service.ListInactiveHotels(r.Context(), query.Get("reason"), limit, offset)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to list inactive hotels")
+		respondWithError(w, http.StatusInternalServerError, "Failed to list inactive hotels")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"hotels": hotels,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// ReactivateHotel handles POST /api/v1/admin/hotels/{id}/reactivate
+func (h *Handler) ReactivateHotel(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := admin.AdminIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Admin ID not found in request")
+		return
+	}
+
+	hotelID := r.PathValue("id")
+	if err := h.service.ReactivateHotel(r.Context(), hotelID, adminID); err != nil {
+		if errors.Is(err, ErrInactiveHotelNotFound) {
+			respondWithError(w, http.StatusNotFound, "Inactive hotel not found")
+			return
+		}
+		logger.ErrorWithErr(err, "Failed to reactivate hotel")
+		respondWithError(w, http.StatusInternalServerError, "Failed to reactivate hotel")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"hotel_id": hotelID,
+		"status":   "active",
+	})
+}
+
 // respondWithJSON writes JSON response
 func respondWithJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -1,11 +1,19 @@
 package hotel
 
-import "time"
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Hotel represents a hotel with all details
 type Hotel struct {
 	ID          string     `json:"id" db:"id"`
 	Name        string     `json:"name" db:"name"`
+	// ProviderCode and ProviderHotelID identify the hotel at its supplier
+	ProviderCode    string `json:"provider_code" db:"provider_code"`
+	ProviderHotelID string `json:"-" db:"provider_hotel_id"`
 	Description string     `json:"description,omitempty" db:"description"`
 	CountryCode string     `json:"country_code" db:"country_code"`
 	City        string     `json:"city" db:"city"`
@@ -30,15 +38,87 @@ type Image struct {
 	SortOrder int    `json:"sort_order" db:"sort_order"`
 }
 
-// RoomInfo represents room information
+// Content languages, as ISO 639-1 codes
+const (
+	LanguageIndonesian = "id"
+	LanguageEnglish    = "en"
+
+	// DefaultLanguage is served when no requested language has content
+	DefaultLanguage = LanguageEnglish
+	// BaseLanguage is the language of the hotels row, served when a hotel
+	// has no content at all
+	BaseLanguage = LanguageEnglish
+)
+
+var supportedLanguages = map[string]bool{
+	LanguageIndonesian: true,
+	LanguageEnglish:    true,
+}
+
+// Content is a hotel's synced description, amenities, images and policies in
+// one language
+type Content struct {
+	HotelID     string    `json:"hotel_id" db:"hotel_id"`
+	Language    string    `json:"language" db:"language"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Amenities   []string  `json:"amenities" db:"amenities"`
+	Images      []Image   `json:"images" db:"images"`
+	Policies    Policies  `json:"policies" db:"policies"`
+	SyncedAt    time.Time `json:"synced_at" db:"synced_at"`
+}
+
+// ParseAcceptLanguage returns the supported language the client prefers
+// most in an Accept-Language header, or DefaultLanguage
+func ParseAcceptLanguage(header string) string {
+	type preference struct {
+		language string
+		quality  float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+					quality = parsed
+				}
+			}
+		}
+		// id-ID and en-US match their base language
+		language, _, _ := strings.Cut(tag, "-")
+		preferences = append(preferences, preference{language: language, quality: quality})
+	}
+
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+	for _, p := range preferences {
+		if p.quality > 0 && supportedLanguages[p.language] {
+			return p.language
+		}
+	}
+	return DefaultLanguage
+}
+
+// RoomInfo represents a room type from the hotel's synced room catalog
 type RoomInfo struct {
-	ID        string   `json:"id" db:"id"`
-	HotelID   string   `json:"hotel_id" db:"hotel_id"`
-	Name      string   `json:"name" db:"name"`
-	MaxGuests int      `json:"max_guests" db:"max_guests"`
-	Beds      string   `json:"beds,omitempty" db:"beds"`
-	Size      string   `json:"size,omitempty" db:"size"`
-	Amenities []string `json:"amenities,omitempty" db:"-"`
+	ID               string   `json:"id" db:"id"`
+	HotelID          string   `json:"hotel_id" db:"hotel_id"`
+	ProviderRoomCode string   `json:"room_code" db:"provider_room_code"`
+	Name             string   `json:"name" db:"name"`
+	MaxGuests        int      `json:"max_guests" db:"max_occupancy"`
+	MaxAdults        int      `json:"max_adults,omitempty" db:"max_adults"`
+	MaxChildren      int      `json:"max_children,omitempty" db:"max_children"`
+	Beds             string   `json:"beds,omitempty" db:"beds"`
+	Amenities        []string `json:"amenities,omitempty" db:"amenities"`
+	Images           []Image  `json:"images,omitempty" db:"images"`
 }
 
 // Location represents hotel location
@@ -69,6 +149,7 @@ type HotelDetailsResponse struct {
 	Location    Location     `json:"location"`
 	Policies    Policies     `json:"policies"`
 	Rooms       []RoomInfo   `json:"rooms,omitempty"`
+	Language    string       `json:"language"` // Language of the content served
 }
 
 // RoomAvailabilityRequest represents request to check room availability
@@ -95,8 +176,10 @@ type AvailableRoom struct {
 	Available bool   `json:"available"`
 	Price     int    `json:"price"`
 	Currency  string `json:"currency"`
-	MaxGuests int    `json:"max_guests"`
-	Beds      string `json:"beds"`
+	MaxGuests int      `json:"max_guests"`
+	Beds      string   `json:"beds"`
+	Amenities []string `json:"amenities,omitempty"`
+	Images    []Image  `json:"images,omitempty"`
 }
 
 // HotelImagesResponse represents hotel images
@@ -104,3 +187,20 @@ type HotelImagesResponse struct {
 	HotelID string  `json:"hotel_id"`
 	Images  []Image `json:"images"`
 }
+
+// DeactivationReasonSupplierRemoved marks hotels deactivated because the
+// supplier no longer returns them
+const DeactivationReasonSupplierRemoved = "SUPPLIER_REMOVED"
+
+// InactiveHotel is a deactivated hotel, listed for admins to review
+type InactiveHotel struct {
+	ID                 string     `json:"id" db:"id"`
+	ProviderCode       string     `json:"provider_code" db:"provider_code"`
+	ProviderHotelID    string     `json:"provider_hotel_id" db:"provider_hotel_id"`
+	Name               string     `json:"name" db:"name"`
+	City               string     `json:"city" db:"city"`
+	DestinationCode    string     `json:"destination_code,omitempty" db:"destination_code"`
+	DeactivationReason string     `json:"deactivation_reason,omitempty" db:"deactivation_reason"`
+	DeactivatedAt      time.Time  `json:"deactivated_at" db:"deleted_at"`
+	LastSeenAt         *time.Time `json:"last_seen_at,omitempty" db:"last_seen_at"`
+}
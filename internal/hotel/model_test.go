@@ -0,0 +1,30 @@
+package hotel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"empty header", "", LanguageEnglish},
+		{"indonesian with region", "id-ID", LanguageIndonesian},
+		{"browser list", "id-ID,id;q=0.9,en-US;q=0.8,en;q=0.7", LanguageIndonesian},
+		{"quality order", "en;q=0.5, id;q=0.9", LanguageIndonesian},
+		{"unsupported first", "fr-FR,fr;q=0.9,id;q=0.8", LanguageIndonesian},
+		{"only unsupported", "ja", LanguageEnglish},
+		{"excluded language", "id;q=0", LanguageEnglish},
+		{"wildcard", "*", LanguageEnglish},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseAcceptLanguage(tt.header))
+		})
+	}
+}
@@ -2,8 +2,11 @@ package hotel
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,6 +15,9 @@ type Repository interface {
 	GetByID(ctx context.Context, id string) (*Hotel, error)
 	GetImages(ctx context.Context, hotelID string) ([]Image, error)
 	GetRooms(ctx context.Context, hotelID string) ([]RoomInfo, error)
+	GetContent(ctx context.Context, hotelID, language string) (*Content, error)
+	ListInactive(ctx context.Context, reason string, limit, offset int) ([]*InactiveHotel, int, error)
+	Reactivate(ctx context.Context, hotelID, adminID string) error
 }
 
 type repository struct {
@@ -27,7 +33,8 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Hotel, error) {
 	query := `
 		SELECT id, name, description, country_code, city, address,
 		       overall_rating, star_rating, latitude, longitude,
-		       created_at, updated_at
+		       created_at, updated_at,
+		       COALESCE(provider_code, 'hotelbeds'), COALESCE(provider_hotel_id, '')
 		FROM hotels
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -40,6 +47,7 @@ func (r *repository) GetByID(ctx context.Context, id string) (*Hotel, error) {
 		&hotel.Rating, &starRating,
 		&hotel.Location.Latitude, &hotel.Location.Longitude,
 		&hotel.CreatedAt, &hotel.UpdatedAt,
+		&hotel.ProviderCode, &hotel.ProviderHotelID,
 	)
 
 	if err != nil {
@@ -93,9 +101,11 @@ func (r *repository) GetImages(ctx context.Context, hotelID string) ([]Image, er
 
 func (r *repository) GetRooms(ctx context.Context, hotelID string) ([]RoomInfo, error) {
 	query := `
-		SELECT id, hotel_id, name, max_guests, beds, size
+		SELECT id, hotel_id, provider_room_code, name, max_occupancy,
+		       COALESCE(max_adults, 0), COALESCE(max_children, 0), COALESCE(beds, ''),
+		       COALESCE(amenities, '[]'), COALESCE(images, '[]')
 		FROM rooms
-		WHERE hotel_id = $1
+		WHERE hotel_id = $1 AND deleted_at IS NULL
 		ORDER BY name ASC
 	`
 
@@ -108,11 +118,118 @@ func (r *repository) GetRooms(ctx context.Context, hotelID string) ([]RoomInfo,
 	var rooms []RoomInfo
 	for rows.Next() {
 		var room RoomInfo
-		if err := rows.Scan(&room.ID, &room.HotelID, &room.Name, &room.MaxGuests, &room.Beds, &room.Size); err != nil {
+		var amenities, images []byte
+		if err := rows.Scan(
+			&room.ID, &room.HotelID, &room.ProviderRoomCode, &room.Name, &room.MaxGuests,
+			&room.MaxAdults, &room.MaxChildren, &room.Beds, &amenities, &images,
+		); err != nil {
 			return nil, fmt.Errorf("failed to scan room: %w", err)
 		}
+		if err := json.Unmarshal(amenities, &room.Amenities); err != nil {
+			return nil, fmt.Errorf("failed to decode room amenities: %w", err)
+		}
+		if err := json.Unmarshal(images, &room.Images); err != nil {
+			return nil, fmt.Errorf("failed to decode room images: %w", err)
+		}
+		for i := range room.Images {
+			room.Images[i].HotelID = hotelID
+		}
 		rooms = append(rooms, room)
 	}
 
-	return rooms, nil
+	return rooms, rows.Err()
+}
+
+func (r *repository) GetContent(ctx context.Context, hotelID, language string) (*Content, error) {
+	query := `
+		SELECT hotel_id, language, name, COALESCE(description, ''), amenities, images, policies, synced_at
+		FROM hotel_contents
+		WHERE hotel_id = $1 AND language = $2
+	`
+
+	var content Content
+	var amenities, images, policies []byte
+	err := r.pool.QueryRow(ctx, query, hotelID, language).Scan(
+		&content.HotelID, &content.Language, &content.Name, &content.Description,
+		&amenities, &images, &policies, &content.SyncedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrContentNotFound
+		}
+		return nil, fmt.Errorf("failed to get hotel content: %w", err)
+	}
+
+	if err := json.Unmarshal(amenities, &content.Amenities); err != nil {
+		return nil, fmt.Errorf("failed to decode hotel amenities: %w", err)
+	}
+	if err := json.Unmarshal(images, &content.Images); err != nil {
+		return nil, fmt.Errorf("failed to decode hotel images: %w", err)
+	}
+	if err := json.Unmarshal(policies, &content.Policies); err != nil {
+		return nil, fmt.Errorf("failed to decode hotel policies: %w", err)
+	}
+	for i := range content.Images {
+		content.Images[i].HotelID = hotelID
+	}
+
+	return &content, nil
+}
+
+// ListInactive retrieves deactivated hotels, most recent first, optionally
+// only those deactivated for reason
+func (r *repository) ListInactive(ctx context.Context, reason string, limit, offset int) ([]*InactiveHotel, int, error) {
+	where := `WHERE deleted_at IS NOT NULL AND ($1 = '' OR deactivation_reason = $1)`
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM hotels `+where, reason).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count inactive hotels: %w", err)
+	}
+
+	query := `
+		SELECT id, COALESCE(provider_code, ''), COALESCE(provider_hotel_id, ''), name, COALESCE(city, ''),
+		       COALESCE(destination_code, ''), COALESCE(deactivation_reason, ''), deleted_at, last_seen_at
+		FROM hotels
+		` + where + `
+		ORDER BY deleted_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, reason, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list inactive hotels: %w", err)
+	}
+	defer rows.Close()
+
+	hotels := make([]*InactiveHotel, 0)
+	for rows.Next() {
+		var h InactiveHotel
+		if err := rows.Scan(
+			&h.ID, &h.ProviderCode, &h.ProviderHotelID, &h.Name, &h.City,
+			&h.DestinationCode, &h.DeactivationReason, &h.DeactivatedAt, &h.LastSeenAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan inactive hotel: %w", err)
+		}
+		hotels = append(hotels, &h)
+	}
+
+	return hotels, total, rows.Err()
+}
+
+// Reactivate restores a deactivated hotel. It counts as seen now, so the
+// next complete sync deactivates it again only if the supplier still lacks it.
+func (r *repository) Reactivate(ctx context.Context, hotelID, adminID string) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE hotels
+		SET deleted_at = NULL, deactivation_reason = NULL, last_seen_at = NOW(),
+		    reactivated_by = $2, reactivated_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`, hotelID, adminID)
+	if err != nil {
+		return fmt.Errorf("failed to reactivate hotel: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrInactiveHotelNotFound
+	}
+	return nil
 }
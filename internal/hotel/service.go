@@ -2,35 +2,41 @@ package hotel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
-	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
 )
 
 // Service defines hotel service interface
 type Service interface {
-	GetHotel(ctx context.Context, hotelID string) (*HotelDetailsResponse, error)
+	GetHotel(ctx context.Context, hotelID, language string) (*HotelDetailsResponse, error)
 	GetAvailableRooms(ctx context.Context, hotelID string, checkIn, checkOut time.Time, guests int) (*RoomAvailabilityResponse, error)
 	GetImages(ctx context.Context, hotelID string) ([]Image, error)
+	ListInactiveHotels(ctx context.Context, reason string, limit, offset int) ([]*InactiveHotel, int, error)
+	ReactivateHotel(ctx context.Context, hotelID, adminID string) error
 }
 
 type service struct {
-	repo            Repository
-	hotelbedsClient *hotelbeds.Client
+	repo      Repository
+	providers provider.Gateway
 }
 
 // NewService creates a new hotel service
-func NewService(repo Repository, hbClient *hotelbeds.Client) Service {
+func NewService(repo Repository, providers provider.Gateway) Service {
 	return &service{
-		repo:            repo,
-		hotelbedsClient: hbClient,
+		repo:      repo,
+		providers: providers,
 	}
 }
 
-func (s *service) GetHotel(ctx context.Context, hotelID string) (*HotelDetailsResponse, error) {
-	logger.Infof("Fetching hotel details: %s", hotelID)
+// GetHotel returns a hotel from the local store with its content in
+// language, falling back to English
+func (s *service) GetHotel(ctx context.Context, hotelID, language string) (*HotelDetailsResponse, error) {
+	logger.Infof("Fetching hotel details: %s (language=%s)", hotelID, language)
 
 	// 1. Get hotel from database
 	hotel, err := s.repo.GetByID(ctx, hotelID)
@@ -39,7 +45,14 @@ func (s *service) GetHotel(ctx context.Context, hotelID string) (*HotelDetailsRe
 		return nil, err
 	}
 
-	// 2. Get images from database
+	// 2. Get synced content in the requested language
+	content, err := s.getContent(ctx, hotelID, language)
+	if err != nil && !errors.Is(err, ErrContentNotFound) {
+		logger.ErrorWithErr(err, "Failed to get hotel content")
+		// Don't fail, just continue with the hotel's own description
+	}
+
+	// 3. Get images from database
 	images, err := s.repo.GetImages(ctx, hotelID)
 	if err != nil {
 		logger.ErrorWithErr(err, "Failed to get hotel images")
@@ -47,7 +60,7 @@ func (s *service) GetHotel(ctx context.Context, hotelID string) (*HotelDetailsRe
 		images = []Image{}
 	}
 
-	// 3. Get rooms from database
+	// 4. Get rooms from database
 	rooms, err := s.repo.GetRooms(ctx, hotelID)
 	if err != nil {
 		logger.ErrorWithErr(err, "Failed to get rooms")
@@ -55,7 +68,7 @@ func (s *service) GetHotel(ctx context.Context, hotelID string) (*HotelDetailsRe
 		rooms = []RoomInfo{}
 	}
 
-	// 4. Build response
+	// 5. Build response
 	response := &HotelDetailsResponse{
 		ID:          hotel.ID,
 		Name:        hotel.Name,
@@ -70,48 +83,111 @@ func (s *service) GetHotel(ctx context.Context, hotelID string) (*HotelDetailsRe
 		Location:    hotel.Location,
 		Policies:    hotel.Policies,
 		Rooms:       rooms,
+		Language:    BaseLanguage,
+	}
+
+	if content != nil {
+		response.Description = content.Description
+		response.Amenities = content.Amenities
+		response.Policies = content.Policies
+		if len(content.Images) > 0 {
+			response.Images = content.Images
+		}
+		response.Language = content.Language
 	}
 
 	logger.Infof("Hotel details fetched successfully: %s", hotelID)
 	return response, nil
 }
 
+// getContent returns a hotel's content in language, or in DefaultLanguage
+// when it has none in language
+func (s *service) getContent(ctx context.Context, hotelID, language string) (*Content, error) {
+	content, err := s.repo.GetContent(ctx, hotelID, language)
+	if errors.Is(err, ErrContentNotFound) && language != DefaultLanguage {
+		return s.repo.GetContent(ctx, hotelID, DefaultLanguage)
+	}
+	return content, err
+}
+
 func (s *service) GetAvailableRooms(ctx context.Context, hotelID string, checkIn, checkOut time.Time, guests int) (*RoomAvailabilityResponse, error) {
 	logger.Infof("Checking room availability: hotel=%s, checkIn=%s, checkOut=%s, guests=%d",
 		hotelID, checkIn.Format("2006-01-02"), checkOut.Format("2006-01-02"), guests)
 
-	// 1. Check availability with HotelBeds
-	availability, err := s.hotelbedsClient.GetHotelAvailability(ctx, &hotelbeds.AvailabilityRequest{
-		HotelCode: hotelID,
-		CheckIn:   checkIn,
-		CheckOut:  checkOut,
-		Guests:    guests,
+	// 1. Resolve the supplier the hotel belongs to
+	hotel, err := s.repo.GetByID(ctx, hotelID)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to get hotel from database")
+		return nil, err
+	}
+
+	providerHotelID := hotel.ProviderHotelID
+	if providerHotelID == "" {
+		providerHotelID = hotel.ID
+	}
+
+	// 2. Check availability with the provider
+	availability, err := s.providers.CheckAvailability(ctx, hotel.ProviderCode, &types.RoomRequest{
+		HotelID:  providerHotelID,
+		CheckIn:  checkIn,
+		CheckOut: checkOut,
+		Guests:   guests,
 	})
 
 	if err != nil {
-		logger.ErrorWithErr(err, "Failed to check availability with HotelBeds")
+		logger.ErrorWithErr(err, fmt.Sprintf("Failed to check availability with %s", hotel.ProviderCode))
 		return nil, fmt.Errorf("failed to check availability: %w", err)
 	}
 
-	// 2. Transform to response format
+	// 3. Enrich with the synced room catalog
+	catalog := make(map[string]RoomInfo)
+	catalogRooms, err := s.repo.GetRooms(ctx, hotelID)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to get room catalog")
+		// Don't fail, just continue with what the provider returned
+	}
+	for _, room := range catalogRooms {
+		catalog[room.ProviderRoomCode] = room
+	}
+
+	// 4. Transform to response format
 	var rooms []AvailableRoom
 	for _, room := range availability.Rooms {
-		if room.Available {
-			rooms = append(rooms, AvailableRoom{
-				RoomID:    room.RoomCode,
-				RoomName:  room.RoomName,
-				Available: room.Available,
-				Price:     room.Price,
-				Currency:  room.Currency,
-				MaxGuests: guests, // Default to requested guests
-				Beds:      "1 King Bed", // Could be parsed from room details
-			})
+		if len(room.Rates) == 0 {
+			continue
+		}
+		rate := room.Rates[0]
+		available := AvailableRoom{
+			RoomID:    room.Room.ID,
+			RoomName:  room.Room.Name,
+			Available: true,
+			Price:     rate.NetPrice,
+			Currency:  rate.Currency,
+			MaxGuests: room.Room.Capacity,
+			Beds:      room.Room.BedType,
+		}
+		if info, ok := catalog[room.Room.ID]; ok {
+			if available.RoomName == "" {
+				available.RoomName = info.Name
+			}
+			if info.MaxGuests > 0 {
+				available.MaxGuests = info.MaxGuests
+			}
+			if available.Beds == "" {
+				available.Beds = info.Beds
+			}
+			available.Amenities = info.Amenities
+			available.Images = info.Images
 		}
+		if available.MaxGuests == 0 {
+			available.MaxGuests = guests // Default to requested guests
+		}
+		rooms = append(rooms, available)
 	}
 
 	response := &RoomAvailabilityResponse{
 		HotelID:   hotelID,
-		HotelName: availability.HotelName,
+		HotelName: hotel.Name,
 		CheckIn:   checkIn.Format("2006-01-02"),
 		CheckOut:  checkOut.Format("2006-01-02"),
 		Guests:    guests,
@@ -134,3 +210,18 @@ func (s *service) GetImages(ctx context.Context, hotelID string) ([]Image, error
 	logger.Infof("Fetched %d images for hotel: %s", len(images), hotelID)
 	return images, nil
 }
+
+// ListInactiveHotels returns deactivated hotels for admins to review
+func (s *service) ListInactiveHotels(ctx context.Context, reason string, limit, offset int) ([]*InactiveHotel, int, error) {
+	return s.repo.ListInactive(ctx, reason, limit, offset)
+}
+
+// ReactivateHotel makes a deactivated hotel searchable and bookable again
+func (s *service) ReactivateHotel(ctx context.Context, hotelID, adminID string) error {
+	if err := s.repo.Reactivate(ctx, hotelID, adminID); err != nil {
+		return err
+	}
+
+	logger.Infof("🏨 Hotel %s reactivated by %s", hotelID, adminID)
+	return nil
+}
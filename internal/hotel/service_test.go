@@ -0,0 +1,192 @@
+package hotel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubGateway returns fixed availability; other gateway methods are unused
+type stubGateway struct {
+	provider.Gateway
+	availability *types.HotelAvailability
+}
+
+func (g *stubGateway) CheckAvailability(ctx context.Context, providerCode string, req *types.RoomRequest) (*types.HotelAvailability, error) {
+	return g.availability, nil
+}
+
+// MockRepository is a mock implementation of Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetByID(ctx context.Context, id string) (*Hotel, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Hotel), args.Error(1)
+}
+
+func (m *MockRepository) GetImages(ctx context.Context, hotelID string) ([]Image, error) {
+	args := m.Called(ctx, hotelID)
+	return args.Get(0).([]Image), args.Error(1)
+}
+
+func (m *MockRepository) GetRooms(ctx context.Context, hotelID string) ([]RoomInfo, error) {
+	args := m.Called(ctx, hotelID)
+	return args.Get(0).([]RoomInfo), args.Error(1)
+}
+
+func (m *MockRepository) GetContent(ctx context.Context, hotelID, language string) (*Content, error) {
+	args := m.Called(ctx, hotelID, language)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Content), args.Error(1)
+}
+
+func (m *MockRepository) ListInactive(ctx context.Context, reason string, limit, offset int) ([]*InactiveHotel, int, error) {
+	args := m.Called(ctx, reason, limit, offset)
+	return args.Get(0).([]*InactiveHotel), args.Int(1), args.Error(2)
+}
+
+func (m *MockRepository) Reactivate(ctx context.Context, hotelID, adminID string) error {
+	args := m.Called(ctx, hotelID, adminID)
+	return args.Error(0)
+}
+
+func setupHotel(ctx context.Context, repo *MockRepository) {
+	repo.On("GetByID", ctx, "hotel-1").Return(&Hotel{
+		ID:          "hotel-1",
+		Name:        "Hotel Bali",
+		Description: "Stored description",
+	}, nil)
+	repo.On("GetImages", ctx, "hotel-1").Return([]Image{{URL: "https://img/stored.jpg"}}, nil)
+	repo.On("GetRooms", ctx, "hotel-1").Return([]RoomInfo{}, nil)
+}
+
+func TestService_GetHotel_RequestedLanguage(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+	setupHotel(ctx, repo)
+	repo.On("GetContent", ctx, "hotel-1", LanguageIndonesian).Return(&Content{
+		Language:    LanguageIndonesian,
+		Description: "Hotel di tepi pantai",
+		Amenities:   []string{"Kolam renang"},
+		Images:      []Image{{URL: "https://img/1.jpg", Type: "GEN"}},
+		Policies:    Policies{CheckInTime: "14:00"},
+	}, nil)
+
+	svc := NewService(repo, nil)
+	hotel, err := svc.GetHotel(ctx, "hotel-1", LanguageIndonesian)
+
+	require.NoError(t, err)
+	assert.Equal(t, LanguageIndonesian, hotel.Language)
+	assert.Equal(t, "Hotel di tepi pantai", hotel.Description)
+	assert.Equal(t, []string{"Kolam renang"}, hotel.Amenities)
+	assert.Equal(t, "https://img/1.jpg", hotel.Images[0].URL)
+	assert.Equal(t, "14:00", hotel.Policies.CheckInTime)
+}
+
+func TestService_GetHotel_FallsBackToEnglish(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+	setupHotel(ctx, repo)
+	repo.On("GetContent", ctx, "hotel-1", LanguageIndonesian).Return(nil, ErrContentNotFound)
+	repo.On("GetContent", ctx, "hotel-1", LanguageEnglish).Return(&Content{
+		Language:    LanguageEnglish,
+		Description: "Beachfront hotel",
+	}, nil)
+
+	svc := NewService(repo, nil)
+	hotel, err := svc.GetHotel(ctx, "hotel-1", LanguageIndonesian)
+
+	require.NoError(t, err)
+	assert.Equal(t, LanguageEnglish, hotel.Language)
+	assert.Equal(t, "Beachfront hotel", hotel.Description)
+	assert.Equal(t, "https://img/stored.jpg", hotel.Images[0].URL, "stored images are kept when content has none")
+	repo.AssertExpectations(t)
+}
+
+func TestService_GetHotel_WithoutContent(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+	setupHotel(ctx, repo)
+	repo.On("GetContent", ctx, "hotel-1", LanguageEnglish).Return(nil, ErrContentNotFound)
+
+	svc := NewService(repo, nil)
+	hotel, err := svc.GetHotel(ctx, "hotel-1", LanguageEnglish)
+
+	require.NoError(t, err)
+	assert.Equal(t, LanguageEnglish, hotel.Language)
+	assert.Equal(t, "Stored description", hotel.Description)
+	repo.AssertNumberOfCalls(t, "GetContent", 1)
+}
+
+func TestService_GetAvailableRooms_EnrichedFromCatalog(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+	repo.On("GetByID", ctx, "hotel-1").Return(&Hotel{
+		ID: "hotel-1", Name: "Hotel Bali", ProviderCode: "hotelbeds", ProviderHotelID: "12345",
+	}, nil)
+	repo.On("GetRooms", ctx, "hotel-1").Return([]RoomInfo{{
+		ProviderRoomCode: "DBL.ST",
+		Name:             "DOUBLE STANDARD",
+		MaxGuests:        3,
+		Beds:             "1 Double bed",
+		Amenities:        []string{"Air conditioning"},
+		Images:           []Image{{URL: "https://img/dbl.jpg"}},
+	}}, nil)
+
+	gateway := &stubGateway{availability: &types.HotelAvailability{
+		Rooms: []types.RoomRate{
+			{
+				Room:  types.Room{ID: "DBL.ST", Name: "Double Standard", Capacity: 2},
+				Rates: []types.Rate{{NetPrice: 1500000, Currency: "IDR"}},
+			},
+			{
+				Room:  types.Room{ID: "SUI.ST", Name: "Suite"},
+				Rates: []types.Rate{{NetPrice: 3000000, Currency: "IDR"}},
+			},
+		},
+	}}
+
+	svc := NewService(repo, gateway)
+	checkIn := time.Now().AddDate(0, 0, 7)
+	resp, err := svc.GetAvailableRooms(ctx, "hotel-1", checkIn, checkIn.AddDate(0, 0, 2), 2)
+
+	require.NoError(t, err)
+	require.Len(t, resp.Rooms, 2)
+
+	catalogued := resp.Rooms[0]
+	assert.Equal(t, "Double Standard", catalogued.RoomName, "provider room names are kept")
+	assert.Equal(t, 3, catalogued.MaxGuests)
+	assert.Equal(t, "1 Double bed", catalogued.Beds)
+	assert.Equal(t, []string{"Air conditioning"}, catalogued.Amenities)
+	assert.Equal(t, "https://img/dbl.jpg", catalogued.Images[0].URL)
+
+	uncatalogued := resp.Rooms[1]
+	assert.Equal(t, 2, uncatalogued.MaxGuests, "defaults to the requested guests")
+	assert.Empty(t, uncatalogued.Beds)
+}
+
+func TestService_ReactivateHotel(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+	repo.On("Reactivate", ctx, "hotel-1", "admin-1").Return(nil)
+	repo.On("Reactivate", ctx, "hotel-2", "admin-1").Return(ErrInactiveHotelNotFound)
+
+	svc := NewService(repo, &stubGateway{})
+
+	require.NoError(t, svc.ReactivateHotel(ctx, "hotel-1", "admin-1"))
+	assert.ErrorIs(t, svc.ReactivateHotel(ctx, "hotel-2", "admin-1"), ErrInactiveHotelNotFound)
+	repo.AssertExpectations(t)
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
@@ -20,6 +23,15 @@ type AvailabilityRequest struct {
 	Guests    int       `json:"guests"`
 }
 
+// HotelsAvailabilityRequest checks several hotels' rooms for a stay in one
+// request, which counts once against the availability quota
+type HotelsAvailabilityRequest struct {
+	HotelCodes []string  `json:"hotelCodes"`
+	CheckIn    time.Time `json:"checkIn"`
+	CheckOut   time.Time `json:"checkOut"`
+	Guests     int       `json:"guests"`
+}
+
 // AvailabilityResponse represents availability check response from HotelBeds
 type AvailabilityResponse struct {
 	HotelCode    string  `json:"hotelCode"`
@@ -39,6 +51,9 @@ type Room struct {
 	Currency    string  `json:"currency"`
 	MaxGuests   int     `json:"maxGuests"`
 	Beds        string  `json:"beds"`
+	RateKey     string  `json:"rateKey,omitempty"`  // Key of the rate priced above
+	RateType    string  `json:"rateType,omitempty"` // BOOKABLE or RECHECK
+	Rates       []Rate  `json:"rates,omitempty"`    // Every rate of the room, with its board and cancellation policies
 }
 
 // HotelDetailsRequest represents a request to get hotel information
@@ -102,14 +117,19 @@ type RoomRateRequest struct {
 	Guests    int       `json:"guests"`
 }
 
-// RoomRateResponse represents room pricing information
+// RoomRateResponse represents room pricing information. RECHECK rates have
+// already been rechecked, so RateKey, TotalPrice and CancellationPolicies are
+// the confirmed ones.
 type RoomRateResponse struct {
-	HotelCode  string `json:"hotelCode"`
-	RoomCode   string `json:"roomCode"`
-	RoomName   string `json:"roomName"`
-	Rates      []Rate `json:"rates"`
-	TotalPrice int    `json:"totalPrice"`
-	Currency   string `json:"currency"`
+	HotelCode            string               `json:"hotelCode"`
+	RoomCode             string               `json:"roomCode"`
+	RoomName             string               `json:"roomName"`
+	Rates                []Rate               `json:"rates"`
+	TotalPrice           int                  `json:"totalPrice"`
+	Currency             string               `json:"currency"`
+	RateKey              string               `json:"rateKey,omitempty"`
+	RateType             string               `json:"rateType,omitempty"`
+	CancellationPolicies []CancellationPolicy `json:"cancellationPolicies,omitempty"`
 }
 
 // Rate represents a rate for a specific date or package
@@ -119,6 +139,10 @@ type Rate struct {
 	Price        int     `json:"price"`
 	Currency     string  `json:"currency"`
 	Description  string  `json:"description,omitempty"`
+	RateKey      string  `json:"rateKey,omitempty"`
+	RateType     string  `json:"rateType,omitempty"`
+	BoardCode    string  `json:"boardCode,omitempty"` // e.g. RO, BB, HB
+	CancellationPolicies []CancellationPolicy `json:"cancellationPolicies,omitempty"`
 }
 
 // BookingRequest represents a request to book a room with HotelBeds
@@ -130,6 +154,13 @@ type BookingRequest struct {
 	Guests      int              `json:"guests"`
 	Holder      HolderInfo       `json:"holder"`
 	Payment     PaymentInfo      `json:"payment"`
+	// RateKey is the rate to book; RECHECK rates are rechecked again first
+	RateKey     string           `json:"rateKey,omitempty"`
+	RateType    string           `json:"rateType,omitempty"`
+	// NetPrice and CancellationPolicies are what the booking was priced at;
+	// a recheck that comes back worse fails with ErrRateChanged
+	NetPrice             int                  `json:"netPrice,omitempty"`
+	CancellationPolicies []CancellationPolicy `json:"cancellationPolicies,omitempty"`
 }
 
 // HolderInfo represents guest/booking holder information
@@ -158,6 +189,8 @@ type BookingResponse struct {
 	CheckOut          time.Time `json:"checkOut"`
 	Status            string    `json:"status"`
 	Cancellation      CancellationInfo `json:"cancellation"`
+	TotalNet          int       `json:"totalNet"` // What HotelBeds charges us for the booking
+	Currency          string    `json:"currency"`
 }
 
 // CancellationInfo represents cancellation information
@@ -169,6 +202,45 @@ type CancellationInfo struct {
 	Currency          string `json:"currency,omitempty"`
 }
 
+// apiRate is a rate in availability responses
+type apiRate struct {
+	RateKey              string  `json:"rateKey"`
+	RateType             string  `json:"rateType"`
+	RateCode             string  `json:"rateCode"`
+	RateName             string  `json:"rateName"`
+	Net                  float64 `json:"net"`
+	Currency             string  `json:"currency"`
+	BoardCode            string  `json:"boardCode"`
+	CancellationPolicies []struct {
+		Amount float64   `json:"amount"`
+		From   time.Time `json:"from"`
+	} `json:"cancellationPolicies"`
+}
+
+// toRate converts an availability rate, in the given currency when the
+// rate names none
+func (r *apiRate) toRate(currency string) Rate {
+	rate := Rate{
+		RateCode:  r.RateCode,
+		RateName:  r.RateName,
+		Price:     int(r.Net),
+		Currency:  r.Currency,
+		RateKey:   r.RateKey,
+		RateType:  r.RateType,
+		BoardCode: r.BoardCode,
+	}
+	if rate.Currency == "" {
+		rate.Currency = currency
+	}
+	for _, policy := range r.CancellationPolicies {
+		rate.CancellationPolicies = append(rate.CancellationPolicies, CancellationPolicy{
+			Amount: int(policy.Amount),
+			From:   policy.From,
+		})
+	}
+	return rate
+}
+
 // GetHotelAvailability checks room availability for specific hotel and dates
 func (c *Client) GetHotelAvailability(ctx context.Context, req *AvailabilityRequest) (*AvailabilityResponse, error) {
 	logger.Infof("Checking HotelBeds availability: hotel=%s, checkIn=%s, checkOut=%s, guests=%d",
@@ -217,13 +289,7 @@ func (c *Client) GetHotelAvailability(ctx context.Context, req *AvailabilityRequ
 			RoomCode   string  `json:"roomCode"`
 			RoomName   string  `json:"roomName"`
 			Available  bool    `json:"available"`
-			Rates      []struct {
-				RateCode   string `json:"rateCode"`
-				RateName   string `json:"rateName"`
-				NetPrice   int    `json:"net"`
-				GrossPrice int    `json:"gross"`
-				Currency   string `json:"currency"`
-			} `json:"rates"`
+			Rates      []apiRate `json:"rates"`
 		} `json:"rooms"`
 	}
 
@@ -241,19 +307,24 @@ func (c *Client) GetHotelAvailability(ctx context.Context, req *AvailabilityRequ
 	// Convert rooms
 	for _, apiRoom := range apiResp.Rooms {
 		if apiRoom.Available && len(apiRoom.Rates) > 0 {
-			// Use the first available rate
+			// Price the room from its first rate
 			rate := apiRoom.Rates[0]
-			totalPrice := rate.GrossPrice // Use gross price (what customer pays)
+			totalPrice := int(rate.Net) // What we pay HotelBeds; our markup is added when selling
 
-			response.Rooms = append(response.Rooms, Room{
+			room := Room{
 				RoomCode:  apiRoom.RoomCode,
 				RoomName:  apiRoom.RoomName,
 				Available: apiRoom.Available,
 				Price:     totalPrice,
 				Currency:   rate.Currency,
 				MaxGuests: req.Guests, // Default to requested guests
-				Beds:       "1 King Bed", // Could be parsed from room details
-			})
+				RateKey:   rate.RateKey,
+				RateType:  rate.RateType,
+			}
+			for _, r := range apiRoom.Rates {
+				room.Rates = append(room.Rates, r.toRate(r.Currency))
+			}
+			response.Rooms = append(response.Rooms, room)
 
 			// Set total price from first room
 			response.TotalPrice = totalPrice
@@ -267,6 +338,112 @@ func (c *Client) GetHotelAvailability(ctx context.Context, req *AvailabilityRequ
 	return response, nil
 }
 
+// GetHotelsAvailability checks the availability of several hotels for the
+// same stay with one request. Only hotels with rooms available are returned.
+func (c *Client) GetHotelsAvailability(ctx context.Context, req *HotelsAvailabilityRequest) ([]AvailabilityResponse, error) {
+	logger.Infof("Checking HotelBeds availability: hotels=%d, checkIn=%s, checkOut=%s, guests=%d",
+		len(req.HotelCodes), req.CheckIn.Format("2006-01-02"), req.CheckOut.Format("2006-01-02"), req.Guests)
+
+	// HotelBeds hotel codes are numeric
+	codes := make([]interface{}, len(req.HotelCodes))
+	for i, code := range req.HotelCodes {
+		if n, err := strconv.Atoi(code); err == nil {
+			codes[i] = n
+		} else {
+			codes[i] = code
+		}
+	}
+
+	apiReq := map[string]interface{}{
+		"stay": map[string]interface{}{
+			"checkIn":  req.CheckIn.Format("2006-01-02"),
+			"checkOut": req.CheckOut.Format("2006-01-02"),
+		},
+		"occupancies": []map[string]interface{}{
+			{
+				"rooms":    1,
+				"adults":   req.Guests,
+				"children": 0,
+			},
+		},
+		"hotels": map[string]interface{}{
+			"hotel": codes,
+		},
+	}
+
+	resp, err := c.Post(ctx, "/hotel-api/1.0/hotels", apiReq)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to check HotelBeds availability")
+		return nil, fmt.Errorf("failed to check availability: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	logger.Debugf("HotelBeds availability response: %s", string(body))
+
+	var apiResp struct {
+		Hotels struct {
+			Hotels []struct {
+				Code     json.RawMessage `json:"code"` // Numeric, sometimes quoted
+				Name     string          `json:"name"`
+				Currency string          `json:"currency"`
+				Rooms    []struct {
+					Code  string `json:"code"`
+					Name  string `json:"name"`
+					Rates []apiRate `json:"rates"`
+				} `json:"rooms"`
+			} `json:"hotels"`
+		} `json:"hotels"`
+	}
+
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse availability response: %w", err)
+	}
+
+	var hotels []AvailabilityResponse
+	for _, apiHotel := range apiResp.Hotels.Hotels {
+		hotel := AvailabilityResponse{
+			HotelCode: strings.Trim(string(apiHotel.Code), `"`),
+			HotelName: apiHotel.Name,
+			Currency:  apiHotel.Currency,
+		}
+		for _, apiRoom := range apiHotel.Rooms {
+			if len(apiRoom.Rates) == 0 {
+				continue
+			}
+			// Price the room from its first rate, as single-hotel availability does
+			rate := apiRoom.Rates[0]
+			room := Room{
+				RoomCode:  apiRoom.Code,
+				RoomName:  apiRoom.Name,
+				Available: true,
+				Price:     int(rate.Net),
+				Currency:  apiHotel.Currency,
+				MaxGuests: req.Guests,
+				RateKey:   rate.RateKey,
+				RateType:  rate.RateType,
+			}
+			for _, r := range apiRoom.Rates {
+				room.Rates = append(room.Rates, r.toRate(apiHotel.Currency))
+			}
+			hotel.Rooms = append(hotel.Rooms, room)
+		}
+		hotel.IsAvailable = len(hotel.Rooms) > 0
+		if hotel.IsAvailable {
+			hotel.TotalPrice = hotel.Rooms[0].Price
+			hotels = append(hotels, hotel)
+		}
+	}
+
+	logger.Infof("HotelBeds availability check complete: %d of %d hotels available", len(hotels), len(req.HotelCodes))
+
+	return hotels, nil
+}
+
 // GetHotelDetails fetches complete hotel information from HotelBeds
 func (c *Client) GetHotelDetails(ctx context.Context, hotelCode string) (*HotelDetailsResponse, error) {
 	logger.Infof("Fetching HotelBeds hotel details: %s", hotelCode)
@@ -375,11 +552,14 @@ func (c *Client) GetRoomRates(ctx context.Context, req *RoomRateRequest) (*RoomR
 			RoomCode  string  `json:"roomCode"`
 			RoomName  string  `json:"roomName"`
 			Rates     []struct {
+				RateKey     string  `json:"rateKey"`
+				RateType    string  `json:"rateType"`
 				RateCode    string  `json:"rateCode"`
 				RateName    string  `json:"rateName"`
 				NetPrice    int     `json:"net"`
 				GrossPrice  int     `json:"gross"`
 				Currency    string  `json:"currency"`
+				CancellationPolicies []CancellationPolicy `json:"cancellationPolicies"`
 			} `json:"rates"`
 		} `json:"rooms"`
 	}
@@ -393,11 +573,14 @@ func (c *Client) GetRoomRates(ctx context.Context, req *RoomRateRequest) (*RoomR
 		RoomCode  string  `json:"roomCode"`
 		RoomName  string  `json:"roomName"`
 		Rates     []struct {
+			RateKey     string  `json:"rateKey"`
+			RateType    string  `json:"rateType"`
 			RateCode    string  `json:"rateCode"`
 			RateName    string  `json:"rateName"`
 			NetPrice    int     `json:"net"`
 			GrossPrice  int     `json:"gross"`
 			Currency    string  `json:"currency"`
+			CancellationPolicies []CancellationPolicy `json:"cancellationPolicies"`
 		} `json:"rates"`
 	}
 
@@ -422,9 +605,10 @@ func (c *Client) GetRoomRates(ctx context.Context, req *RoomRateRequest) (*RoomR
 
 	// Calculate total price (sum of all rates for the stay)
 	// HotelBeds returns rates per room per night, so we need to calculate total
+	// Net is what we pay HotelBeds; our markup is added when selling
 	rate := targetRoom.Rates[0]
 	nights := int(req.CheckOut.Sub(req.CheckIn).Hours() / 24)
-	totalPrice := rate.GrossPrice * nights
+	totalPrice := rate.NetPrice * nights
 
 	response := &RoomRateResponse{
 		HotelCode:  apiResp.HotelCode,
@@ -434,17 +618,38 @@ func (c *Client) GetRoomRates(ctx context.Context, req *RoomRateRequest) (*RoomR
 			{
 				RateCode:    rate.RateCode,
 				RateName:    rate.RateName,
-				Price:       rate.GrossPrice,
+				Price:       rate.NetPrice,
 				Currency:    rate.Currency,
 				Description: fmt.Sprintf("Rate per night (%d nights)", nights),
+				RateKey:     rate.RateKey,
+				RateType:    rate.RateType,
 			},
 		},
-		TotalPrice: totalPrice,
-		Currency:   rate.Currency,
+		TotalPrice:           totalPrice,
+		Currency:             rate.Currency,
+		RateKey:              rate.RateKey,
+		RateType:             rate.RateType,
+		CancellationPolicies: rate.CancellationPolicies,
+	}
+
+	// RECHECK rates are only confirmed by CheckRate, which may change the
+	// price, the cancellation policies and the key to book with
+	if NeedsRecheck(rate.RateType) {
+		checked, err := c.CheckRate(ctx, rate.RateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get room rates: %w", err)
+		}
+		response.TotalPrice = checked.TotalPrice
+		response.RateKey = checked.RateKey
+		response.RateType = checked.RateType
+		response.CancellationPolicies = checked.CancellationPolicies
+		if checked.Currency != "" {
+			response.Currency = checked.Currency
+		}
 	}
 
 	logger.Infof("HotelBeds room rates fetched: price=%d %s for %d nights",
-		totalPrice, response.Currency, nights)
+		response.TotalPrice, response.Currency, nights)
 
 	return response, nil
 }
@@ -454,6 +659,32 @@ func (c *Client) CreateBooking(ctx context.Context, req *BookingRequest) (*Booki
 	logger.Infof("Creating HotelBeds booking: hotel=%s, room=%s, checkIn=%s, checkOut=%s",
 		req.HotelCode, req.RoomCode, req.CheckIn.Format("2006-01-02"), req.CheckOut.Format("2006-01-02"))
 
+	// RECHECK rates are rechecked right before booking, and booked with the
+	// key CheckRate returns
+	rateKey := req.RateKey
+	if rateKey != "" && NeedsRecheck(req.RateType) {
+		checked, err := c.CheckRate(ctx, rateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create booking: %w", err)
+		}
+		if err := checkRateUnchanged(req, checked); err != nil {
+			logger.Warnf("HotelBeds rate %s changed before booking: %v", rateKey, err)
+			return nil, fmt.Errorf("failed to create booking: %w", err)
+		}
+		rateKey = checked.RateKey
+	}
+
+	room := map[string]interface{}{
+		"roomCode": req.RoomCode,
+		"rateCode": "STANDARD", // Used when no rate key was priced
+	}
+	if rateKey != "" {
+		room = map[string]interface{}{
+			"roomCode": req.RoomCode,
+			"rateKey":  rateKey,
+		}
+	}
+
 	// Build HotelBeds booking request
 	apiReq := map[string]interface{}{
 		"stay": map[string]interface{}{
@@ -474,12 +705,7 @@ func (c *Client) CreateBooking(ctx context.Context, req *BookingRequest) (*Booki
 			"surname":   req.Holder.Surname,
 			"email":     req.Holder.Email,
 		},
-		"rooms": []map[string]interface{}{
-			{
-				"roomCode": req.RoomCode,
-				"rateCode": "STANDARD", // Could be made configurable
-			},
-		},
+		"rooms": []map[string]interface{}{room},
 		"payment": map[string]interface{}{
 			"paymentMethodType": req.Payment.PaymentMethodType,
 			// Add card details if credit card
@@ -510,6 +736,8 @@ func (c *Client) CreateBooking(ctx context.Context, req *BookingRequest) (*Booki
 		CheckOut         string    `json:"checkOut"`
 		Status           string    `json:"status"`
 		Cancellation     CancellationInfo `json:"cancellation"`
+		TotalNet         float64   `json:"totalNet"`
+		Currency         string    `json:"currency"`
 	}
 
 	if err := json.Unmarshal(body, &apiResp); err != nil {
@@ -522,6 +750,8 @@ func (c *Client) CreateBooking(ctx context.Context, req *BookingRequest) (*Booki
 		RoomCode:         apiResp.RoomCode,
 		Status:           apiResp.Status,
 		Cancellation:     apiResp.Cancellation,
+		TotalNet:         int(apiResp.TotalNet),
+		Currency:         apiResp.Currency,
 	}
 
 	// Parse dates
@@ -587,7 +817,9 @@ type ContentHotelContent struct {
 	Code         string                    `json:"code"`
 	Name         string                    `json:"name"`
 	Destination  ContentDestinationCode    `json:"destination"`
+	Coordinates  *ContentCoordinates       `json:"coordinates,omitempty"`
 	Category     []ContentCategoryCode     `json:"category"`
+	AccommodationTypeCode string           `json:"accommodationTypeCode,omitempty"` // e.g. HOTEL, APARTMENT, RESORT
 	Address      string                    `json:"address,omitempty"`
 	PostalCode   string                    `json:"postalCode,omitempty"`
 	City         string                    `json:"city,omitempty"`
@@ -608,10 +840,16 @@ type ContentHotelContent struct {
 
 // ContentDestinationCode represents destination reference
 type ContentDestinationCode struct {
-	Code int    `json:"code"`
+	Code string `json:"code"`
 	Name string `json:"name,omitempty"`
 }
 
+// ContentCoordinates represents a hotel's location
+type ContentCoordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
 // ContentCategoryCode represents hotel category (stars)
 type ContentCategoryCode struct {
 	Code        string `json:"code"`
@@ -624,6 +862,7 @@ type ContentHotelImage struct {
 	ImageType   string `json:"imageType"`
 	Path        string `json:"path"`
 	VisualOrder int    `json:"visualOrder,omitempty"`
+	RoomCode    string `json:"roomCode,omitempty"` // Set on images of a room type
 }
 
 // ContentDescription represents hotel description
@@ -642,6 +881,25 @@ type ContentRoomDetail struct {
 	MaxAdults       int      `json:"maxAdults,omitempty"`
 	MaxChildren     int      `json:"maxChildren,omitempty"`
 	MinAdults       int      `json:"minAdults,omitempty"`
+	Description     string   `json:"description,omitempty"` // Room name, e.g. "DOUBLE STANDARD"
+	RoomFacilities  []ContentRoomFacility `json:"roomFacilities,omitempty"`
+	RoomStays       []ContentRoomStay     `json:"roomStays,omitempty"`
+}
+
+// ContentRoomFacility represents a facility of a room type
+type ContentRoomFacility struct {
+	FacilityCode      int            `json:"facilityCode"`
+	FacilityGroupCode int            `json:"facilityGroupCode"`
+	Number            int            `json:"number,omitempty"`
+	Description       ContentContent `json:"description,omitempty"`
+}
+
+// ContentRoomStay represents a space of a room type; BED stays list the beds
+type ContentRoomStay struct {
+	StayType           string                `json:"stayType"`
+	Order              string                `json:"order,omitempty"`
+	Description        string                `json:"description,omitempty"`
+	RoomStayFacilities []ContentRoomFacility `json:"roomStayFacilities,omitempty"`
 }
 
 // ContentFacilityGroup represents hotel facilities grouped by type
@@ -709,39 +967,44 @@ type ContentHotelsResponse struct {
 	Hotels  []ContentHotelContent     `json:"hotels,omitempty"`
 }
 
-// GetHotels fetches hotels from HotelBeds Content API
-func (c *Client) GetHotels(ctx context.Context, destinationCode string, offset, limit int) ([]ContentHotelContent, error) {
-	// Build endpoint with query parameters
-	endpoint := "/hotel-content-api/1.0/hotels"
-	queryParams := make(map[string]string)
+// ContentHotelsRequest selects a page of hotels from the Content API
+type ContentHotelsRequest struct {
+	DestinationCode string
+	Language        string    // e.g. "IND"; English when empty
+	LastUpdateTime  time.Time // Only hotels changed since; all hotels when zero
+	Offset          int       // Zero-based position of the first hotel
+	Limit           int       // Page size; MaxContentPage when zero
+}
+
+// MaxContentPage is the largest page the Content API returns
+const MaxContentPage = 1000
 
-	if destinationCode != "" {
-		queryParams["fields"] = "all" // Get all hotel details
-		queryParams["destinationCode"] = destinationCode
+// GetHotels fetches a page of hotels from HotelBeds Content API
+func (c *Client) GetHotels(ctx context.Context, req *ContentHotelsRequest) ([]ContentHotelContent, error) {
+	limit := req.Limit
+	if limit <= 0 || limit > MaxContentPage {
+		limit = MaxContentPage
 	}
 
-	if offset > 0 {
-		queryParams["from"] = fmt.Sprintf("%d", offset)
+	// from and to are one-based and inclusive
+	query := url.Values{}
+	query.Set("fields", "all") // Get all hotel details
+	if req.DestinationCode != "" {
+		query.Set("destinationCode", req.DestinationCode)
 	}
-	if limit > 0 {
-		queryParams["to"] = fmt.Sprintf("%d", offset + limit - 1)
+	if req.Language != "" {
+		query.Set("language", req.Language)
 	}
-
-	// Add query string to endpoint
-	if len(queryParams) > 0 {
-		endpoint += "?"
-		first := true
-		for k, v := range queryParams {
-			if !first {
-				endpoint += "&"
-			}
-			endpoint += k + "=" + v
-			first = false
-		}
+	if !req.LastUpdateTime.IsZero() {
+		query.Set("lastUpdateTime", req.LastUpdateTime.Format("2006-01-02"))
 	}
+	query.Set("from", strconv.Itoa(req.Offset+1))
+	query.Set("to", strconv.Itoa(req.Offset+limit))
 
-	logger.Infof("Fetching hotels from HotelBeds: destination=%s, offset=%d, limit=%d",
-		destinationCode, offset, limit)
+	endpoint := "/hotel-content-api/1.0/hotels?" + query.Encode()
+
+	logger.Infof("Fetching hotels from HotelBeds: destination=%s, language=%s, since=%s, offset=%d, limit=%d",
+		req.DestinationCode, req.Language, req.LastUpdateTime.Format("2006-01-02"), req.Offset, limit)
 
 	// Make API request
 	resp, err := c.Get(ctx, endpoint)
@@ -780,11 +1043,11 @@ func (c *Client) GetHotels(ctx context.Context, destinationCode string, offset,
 	return response.Hotels, nil
 }
 
-// GetAllHotels fetches all hotels with pagination
-func (c *Client) GetAllHotels(ctx context.Context, destinationCode string) ([]ContentHotelContent, error) {
+// GetAllHotels fetches all hotels matching req with pagination, starting at req.Offset
+func (c *Client) GetAllHotels(ctx context.Context, req *ContentHotelsRequest) ([]ContentHotelContent, error) {
 	var allHotels []ContentHotelContent
-	offset := 0
-	limit := 1000 // HotelBeds max pagination
+	page := *req
+	page.Limit = MaxContentPage
 
 	for {
 		select {
@@ -793,19 +1056,19 @@ func (c *Client) GetAllHotels(ctx context.Context, destinationCode string) ([]Co
 		default:
 		}
 
-		hotels, err := c.GetHotels(ctx, destinationCode, offset, limit)
+		hotels, err := c.GetHotels(ctx, &page)
 		if err != nil {
 			return nil, err
 		}
 
-		if len(hotels) == 0 {
-			break // No more data
-		}
-
 		allHotels = append(allHotels, hotels...)
-		offset += limit
+		page.Offset += len(hotels)
 
 		logger.Infof("Fetched %d hotels (total: %d)", len(hotels), len(allHotels))
+
+		if len(hotels) < page.Limit {
+			break // Last page
+		}
 	}
 
 	return allHotels, nil
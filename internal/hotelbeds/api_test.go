@@ -0,0 +1,65 @@
+package hotelbeds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetHotelsAvailability_ChecksHotelsInOneRequest(t *testing.T) {
+	var requests int
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/hotel-api/1.0/hotels", r.URL.Path)
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"hotels": map[string]interface{}{
+				"hotels": []map[string]interface{}{{
+					"code":     1001,
+					"name":     "Grand Hotel Bali",
+					"currency": "IDR",
+					"rooms": []map[string]interface{}{{
+						"code": "DBL.ST",
+						"name": "Double Standard",
+						"rates": []map[string]interface{}{
+							{"rateKey": "key-1", "rateType": RateTypeBookable, "net": 1500000},
+						},
+					}},
+				}},
+			},
+		})
+	}))
+	defer server.Close()
+	client := NewClient("key", "secret", server.URL)
+
+	checkIn := time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC)
+	hotels, err := client.GetHotelsAvailability(context.Background(), &HotelsAvailabilityRequest{
+		HotelCodes: []string{"1001", "1002"},
+		CheckIn:    checkIn,
+		CheckOut:   checkIn.AddDate(0, 0, 2),
+		Guests:     2,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, []interface{}{float64(1001), float64(1002)}, body["hotels"].(map[string]interface{})["hotel"])
+
+	require.Len(t, hotels, 1, "hotels without availability are left out")
+	assert.Equal(t, "1001", hotels[0].HotelCode)
+	assert.True(t, hotels[0].IsAvailable)
+	require.Len(t, hotels[0].Rooms, 1)
+	assert.Equal(t, "DBL.ST", hotels[0].Rooms[0].RoomCode)
+	assert.Equal(t, 1500000, hotels[0].Rooms[0].Price)
+	assert.Equal(t, "IDR", hotels[0].Rooms[0].Currency)
+	assert.Equal(t, "key-1", hotels[0].Rooms[0].RateKey)
+}
@@ -0,0 +1,211 @@
+package hotelbeds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Booking statuses reported by the Booking List and Booking Detail endpoints
+const (
+	BookingStatusConfirmed = "CONFIRMED"
+	BookingStatusCancelled = "CANCELLED"
+)
+
+// Booking list filter types
+const (
+	// FilterByCheckIn lists bookings checking in between Start and End
+	FilterByCheckIn = "CHECKIN"
+	// FilterByCreation lists bookings created between Start and End
+	FilterByCreation = "CREATION"
+)
+
+// MaxBookingListPage is the most bookings HotelBeds returns per page
+const MaxBookingListPage = 100
+
+// ErrBookingNotFound is returned when HotelBeds has no booking with a reference
+var ErrBookingNotFound = errors.New("hotelbeds booking not found")
+
+// BookingListRequest selects a page of bookings. From and To are 1-based
+// positions in the result, both inclusive.
+type BookingListRequest struct {
+	Start      time.Time
+	End        time.Time
+	FilterType string
+	From       int
+	To         int
+}
+
+// BookingListResponse is one page of bookings
+type BookingListResponse struct {
+	Bookings []BookingDetail `json:"bookings"`
+	From     int             `json:"from"`
+	To       int             `json:"to"`
+	Total    int             `json:"total"`
+}
+
+// HasMore reports whether bookings remain after this page
+func (r *BookingListResponse) HasMore() bool {
+	return r.To < r.Total && len(r.Bookings) > 0
+}
+
+// BookingDetail is a booking as HotelBeds holds it
+type BookingDetail struct {
+	Reference       string    `json:"reference"`
+	ClientReference string    `json:"clientReference"`
+	Status          string    `json:"status"`
+	CreationDate    time.Time `json:"creationDate"`
+	HotelCode       string    `json:"hotelCode"`
+	HotelName       string    `json:"hotelName"`
+	CheckIn         time.Time `json:"checkIn"`
+	CheckOut        time.Time `json:"checkOut"`
+	TotalNet        int       `json:"totalNet"`
+	Currency        string    `json:"currency"`
+	HolderName      string    `json:"holderName"`
+}
+
+// apiBooking is a booking in Booking List and Booking Detail responses
+type apiBooking struct {
+	Reference       string `json:"reference"`
+	ClientReference string `json:"clientReference"`
+	CreationDate    string `json:"creationDate"`
+	Status          string `json:"status"`
+	Holder          struct {
+		Name    string `json:"name"`
+		Surname string `json:"surname"`
+	} `json:"holder"`
+	Hotel struct {
+		Code     json.RawMessage `json:"code"` // Numeric, sometimes quoted
+		Name     string          `json:"name"`
+		CheckIn  string          `json:"checkIn"`
+		CheckOut string          `json:"checkOut"`
+		TotalNet float64         `json:"totalNet"`
+		Currency string          `json:"currency"`
+	} `json:"hotel"`
+	TotalNet float64 `json:"totalNet"`
+	Currency string  `json:"currency"`
+}
+
+func (b *apiBooking) toBookingDetail() BookingDetail {
+	detail := BookingDetail{
+		Reference:       b.Reference,
+		ClientReference: b.ClientReference,
+		Status:          b.Status,
+		HotelCode:       strings.Trim(string(b.Hotel.Code), `"`),
+		HotelName:       b.Hotel.Name,
+		TotalNet:        int(b.TotalNet),
+		Currency:        b.Currency,
+		HolderName:      strings.TrimSpace(b.Holder.Name + " " + b.Holder.Surname),
+	}
+	if detail.TotalNet == 0 {
+		detail.TotalNet = int(b.Hotel.TotalNet)
+	}
+	if detail.Currency == "" {
+		detail.Currency = b.Hotel.Currency
+	}
+	detail.CreationDate, _ = time.Parse("2006-01-02", b.CreationDate)
+	detail.CheckIn, _ = time.Parse("2006-01-02", b.Hotel.CheckIn)
+	detail.CheckOut, _ = time.Parse("2006-01-02", b.Hotel.CheckOut)
+	return detail
+}
+
+// ListBookings fetches one page of bookings from the Booking List endpoint
+func (c *Client) ListBookings(ctx context.Context, req *BookingListRequest) (*BookingListResponse, error) {
+	filterType := req.FilterType
+	if filterType == "" {
+		filterType = FilterByCheckIn
+	}
+	from := req.From
+	if from < 1 {
+		from = 1
+	}
+	to := req.To
+	if to < from || to-from >= MaxBookingListPage {
+		to = from + MaxBookingListPage - 1
+	}
+
+	query := url.Values{}
+	query.Set("start", req.Start.Format("2006-01-02"))
+	query.Set("end", req.End.Format("2006-01-02"))
+	query.Set("filterType", filterType)
+	query.Set("from", strconv.Itoa(from))
+	query.Set("to", strconv.Itoa(to))
+
+	logger.Infof("Listing HotelBeds bookings: %s %s to %s, %d-%d",
+		filterType, req.Start.Format("2006-01-02"), req.End.Format("2006-01-02"), from, to)
+
+	resp, err := c.Get(ctx, "/hotel-api/1.0/bookings?"+query.Encode())
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to list HotelBeds bookings")
+		return nil, fmt.Errorf("failed to list bookings: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var apiResp struct {
+		Bookings struct {
+			From     int          `json:"from"`
+			To       int          `json:"to"`
+			Total    int          `json:"total"`
+			Bookings []apiBooking `json:"bookings"`
+		} `json:"bookings"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse booking list response: %w", err)
+	}
+
+	response := &BookingListResponse{
+		From:  apiResp.Bookings.From,
+		To:    apiResp.Bookings.To,
+		Total: apiResp.Bookings.Total,
+	}
+	for i := range apiResp.Bookings.Bookings {
+		response.Bookings = append(response.Bookings, apiResp.Bookings.Bookings[i].toBookingDetail())
+	}
+
+	logger.Infof("HotelBeds bookings listed: %d of %d", len(response.Bookings), response.Total)
+
+	return response, nil
+}
+
+// GetBookingDetail fetches a booking by its HotelBeds reference
+func (c *Client) GetBookingDetail(ctx context.Context, bookingReference string) (*BookingDetail, error) {
+	resp, err := c.Get(ctx, "/hotel-api/1.0/bookings/"+url.PathEscape(bookingReference))
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, ErrBookingNotFound
+		}
+		logger.ErrorWithErr(err, "Failed to get HotelBeds booking")
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var apiResp struct {
+		Booking apiBooking `json:"booking"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse booking detail response: %w", err)
+	}
+
+	detail := apiResp.Booking.toBookingDetail()
+	return &detail, nil
+}
@@ -0,0 +1,74 @@
+package hotelbeds
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bookingJSON(ref, status string) string {
+	return fmt.Sprintf(`{
+		"reference": %q, "clientReference": "BKG-%s", "creationDate": "2026-10-01", "status": %q,
+		"holder": {"name": "BUDI", "surname": "SANTOSO"},
+		"hotel": {"code": 12345, "name": "Grand Hyatt Bali", "checkIn": "2026-11-20", "checkOut": "2026-11-22",
+			"totalNet": 2100000, "currency": "IDR"},
+		"totalNet": 2100000, "currency": "IDR"
+	}`, ref, ref, status)
+}
+
+func TestClient_ListBookings(t *testing.T) {
+	var query []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/hotel-api/1.0/bookings", r.URL.Path)
+		query = append(query, r.URL.RawQuery)
+		fmt.Fprintf(w, `{"bookings": {"from": 1, "to": 2, "total": 3, "bookings": [%s, %s]}}`,
+			bookingJSON("102-1", BookingStatusConfirmed), bookingJSON("102-2", BookingStatusCancelled))
+	}))
+	defer server.Close()
+	client := NewClient("key", "secret", server.URL)
+
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	resp, err := client.ListBookings(context.Background(), &BookingListRequest{Start: start, End: start.AddDate(0, 1, 0)})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"end=2026-12-01&filterType=CHECKIN&from=1&start=2026-11-01&to=100"}, query)
+	assert.True(t, resp.HasMore())
+	require.Len(t, resp.Bookings, 2)
+
+	booking := resp.Bookings[0]
+	assert.Equal(t, "102-1", booking.Reference)
+	assert.Equal(t, "BKG-102-1", booking.ClientReference)
+	assert.Equal(t, "12345", booking.HotelCode)
+	assert.Equal(t, "BUDI SANTOSO", booking.HolderName)
+	assert.Equal(t, time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC), booking.CheckIn)
+	assert.Equal(t, time.Date(2026, 11, 22, 0, 0, 0, 0, time.UTC), booking.CheckOut)
+	assert.Equal(t, 2100000, booking.TotalNet)
+	assert.Equal(t, BookingStatusCancelled, resp.Bookings[1].Status)
+}
+
+func TestClient_GetBookingDetail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/hotel-api/1.0/bookings/102-1" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"code": "INVALID_DATA", "message": "Booking not found"}}`))
+			return
+		}
+		fmt.Fprintf(w, `{"booking": %s}`, bookingJSON("102-1", BookingStatusConfirmed))
+	}))
+	defer server.Close()
+	client := NewClient("key", "secret", server.URL)
+
+	detail, err := client.GetBookingDetail(context.Background(), "102-1")
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusConfirmed, detail.Status)
+	assert.Equal(t, "IDR", detail.Currency)
+
+	_, err = client.GetBookingDetail(context.Background(), "102-404")
+	assert.ErrorIs(t, err, ErrBookingNotFound)
+}
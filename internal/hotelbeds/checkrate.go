@@ -0,0 +1,173 @@
+package hotelbeds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Rate types returned with every HotelBeds rate
+const (
+	// RateTypeBookable rates can be booked with their rate key as is
+	RateTypeBookable = "BOOKABLE"
+	// RateTypeRecheck rates must go through CheckRate first, which confirms
+	// the price and returns the rate key to book with
+	RateTypeRecheck = "RECHECK"
+)
+
+// ErrRateChanged is returned when a rechecked rate costs more, or cancels on
+// stricter terms, than the booking was priced at
+var ErrRateChanged = errors.New("hotelbeds rate changed since it was priced")
+
+// CancellationPolicy is a cancellation charge that applies from a moment on
+type CancellationPolicy struct {
+	Amount int       `json:"amount"`
+	From   time.Time `json:"from"`
+}
+
+// CheckRateResponse is a rechecked rate with its current price and
+// cancellation policies
+type CheckRateResponse struct {
+	HotelCode            string               `json:"hotelCode"`
+	RoomCode             string               `json:"roomCode"`
+	RateKey              string               `json:"rateKey"`
+	RateType             string               `json:"rateType"`
+	TotalPrice           int                  `json:"totalPrice"`
+	Currency             string               `json:"currency"`
+	CancellationPolicies []CancellationPolicy `json:"cancellationPolicies,omitempty"`
+	RateComments         string               `json:"rateComments,omitempty"`
+}
+
+// NeedsRecheck reports whether a rate of this type must be rechecked before
+// booking
+func NeedsRecheck(rateType string) bool {
+	return rateType == RateTypeRecheck
+}
+
+// CheckRate confirms a rate's price and cancellation policies. The rate key
+// in the response replaces the one from availability.
+func (c *Client) CheckRate(ctx context.Context, rateKey string) (*CheckRateResponse, error) {
+	logger.Infof("Rechecking HotelBeds rate: %s", rateKey)
+
+	apiReq := map[string]interface{}{
+		"rooms": []map[string]interface{}{
+			{"rateKey": rateKey},
+		},
+	}
+
+	resp, err := c.Post(ctx, "/hotel-api/1.0/checkrates", apiReq)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to recheck HotelBeds rate")
+		return nil, fmt.Errorf("failed to check rate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	logger.Debugf("HotelBeds checkrate response: %s", string(body))
+
+	var apiResp struct {
+		Hotel struct {
+			Code     string  `json:"code"`
+			TotalNet float64 `json:"totalNet"`
+			Currency string  `json:"currency"`
+			Rooms    []struct {
+				Code  string `json:"code"`
+				Rates []struct {
+					RateKey              string  `json:"rateKey"`
+					RateType             string  `json:"rateType"`
+					Net                  float64 `json:"net"`
+					RateComments         string  `json:"rateComments"`
+					CancellationPolicies []struct {
+						Amount float64   `json:"amount"`
+						From   time.Time `json:"from"`
+					} `json:"cancellationPolicies"`
+				} `json:"rates"`
+			} `json:"rooms"`
+		} `json:"hotel"`
+	}
+
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkrate response: %w", err)
+	}
+
+	hotel := apiResp.Hotel
+	if len(hotel.Rooms) == 0 || len(hotel.Rooms[0].Rates) == 0 {
+		return nil, fmt.Errorf("failed to check rate: rate %s is no longer available", rateKey)
+	}
+
+	room := hotel.Rooms[0]
+	rate := room.Rates[0]
+	response := &CheckRateResponse{
+		HotelCode:    hotel.Code,
+		RoomCode:     room.Code,
+		RateKey:      rate.RateKey,
+		RateType:     rate.RateType,
+		TotalPrice:   int(rate.Net),
+		Currency:     hotel.Currency,
+		RateComments: rate.RateComments,
+	}
+	if hotel.TotalNet > 0 {
+		response.TotalPrice = int(hotel.TotalNet)
+	}
+	for _, policy := range rate.CancellationPolicies {
+		response.CancellationPolicies = append(response.CancellationPolicies, CancellationPolicy{
+			Amount: int(policy.Amount),
+			From:   policy.From,
+		})
+	}
+
+	logger.Infof("HotelBeds rate rechecked: price=%d %s, policies=%d",
+		response.TotalPrice, response.Currency, len(response.CancellationPolicies))
+
+	return response, nil
+}
+
+// checkRateUnchanged compares a rechecked rate with what the booking was
+// priced at. A lower price or looser terms are fine; a higher net, an earlier
+// first charge or a bigger first charge is not. Unknown expectations (a zero
+// price, no policies) are not checked.
+func checkRateUnchanged(req *BookingRequest, checked *CheckRateResponse) error {
+	if req.NetPrice > 0 && checked.TotalPrice > req.NetPrice {
+		return fmt.Errorf("%w: net %d is now %d", ErrRateChanged, req.NetPrice, checked.TotalPrice)
+	}
+
+	expected, ok := firstCharge(req.CancellationPolicies)
+	if !ok {
+		return nil
+	}
+	actual, ok := firstCharge(checked.CancellationPolicies)
+	if !ok {
+		return nil
+	}
+	if actual.From.Before(expected.From) {
+		return fmt.Errorf("%w: cancellation charges from %s instead of %s", ErrRateChanged,
+			actual.From.Format(time.RFC3339), expected.From.Format(time.RFC3339))
+	}
+	if actual.Amount > expected.Amount {
+		return fmt.Errorf("%w: cancellation charge %d is now %d", ErrRateChanged, expected.Amount, actual.Amount)
+	}
+	return nil
+}
+
+// firstCharge returns the cancellation charge that applies earliest
+func firstCharge(policies []CancellationPolicy) (CancellationPolicy, bool) {
+	if len(policies) == 0 {
+		return CancellationPolicy{}, false
+	}
+	first := policies[0]
+	for _, policy := range policies[1:] {
+		if policy.From.Before(first.From) {
+			first = policy
+		}
+	}
+	return first, true
+}
@@ -0,0 +1,187 @@
+package hotelbeds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rateServer serves availability with one rate of the given type, and a
+// CheckRate that returns a new key, a higher price and a cancellation policy
+type rateServer struct {
+	mu       sync.Mutex
+	rateType string
+	checked  []string
+	booked   map[string]interface{}
+}
+
+func (s *rateServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/hotel-api/1.0/hotels/H1/availability":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"hotelCode": "H1",
+			"rooms": []map[string]interface{}{{
+				"roomCode":  "DBL",
+				"available": true,
+				"rates": []map[string]interface{}{{
+					"rateKey":  "key-from-availability",
+					"rateType": s.rateType,
+					"net":      900000,
+					"gross":    1000000,
+					"currency": "IDR",
+				}},
+			}},
+		})
+	case "/hotel-api/1.0/checkrates":
+		rooms := body["rooms"].([]interface{})
+		s.checked = append(s.checked, rooms[0].(map[string]interface{})["rateKey"].(string))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"hotel": map[string]interface{}{
+				"code":     "H1",
+				"totalNet": 2100000,
+				"currency": "IDR",
+				"rooms": []map[string]interface{}{{
+					"code": "DBL",
+					"rates": []map[string]interface{}{{
+						"rateKey":  "key-from-checkrate",
+						"rateType": RateTypeBookable,
+						"net":      2100000,
+						"cancellationPolicies": []map[string]interface{}{
+							{"amount": 1050000, "from": "2026-11-18T23:59:00+07:00"},
+						},
+					}},
+				}},
+			},
+		})
+	case "/hotel-api/1.0/bookings":
+		s.booked = body
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"reference": "HB-1", "status": "CONFIRMED"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func rateRequest() *RoomRateRequest {
+	checkIn := time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC)
+	return &RoomRateRequest{HotelCode: "H1", RoomCode: "DBL", CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 2), Guests: 2}
+}
+
+func TestClient_GetRoomRates_RechecksRecheckRates(t *testing.T) {
+	srv := &rateServer{rateType: RateTypeRecheck}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client := NewClient("key", "secret", server.URL)
+
+	resp, err := client.GetRoomRates(context.Background(), rateRequest())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"key-from-availability"}, srv.checked)
+	assert.Equal(t, "key-from-checkrate", resp.RateKey)
+	assert.Equal(t, RateTypeBookable, resp.RateType)
+	assert.Equal(t, 2100000, resp.TotalPrice)
+	require.Len(t, resp.CancellationPolicies, 1)
+	assert.Equal(t, 1050000, resp.CancellationPolicies[0].Amount)
+	assert.True(t, resp.CancellationPolicies[0].From.Equal(time.Date(2026, 11, 18, 16, 59, 0, 0, time.UTC)))
+}
+
+func TestClient_GetRoomRates_BookableRatesSkipRecheck(t *testing.T) {
+	srv := &rateServer{rateType: RateTypeBookable}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client := NewClient("key", "secret", server.URL)
+
+	resp, err := client.GetRoomRates(context.Background(), rateRequest())
+	require.NoError(t, err)
+
+	assert.Empty(t, srv.checked)
+	assert.Equal(t, "key-from-availability", resp.RateKey)
+	assert.Equal(t, 1800000, resp.TotalPrice) // Net per night for two nights
+}
+
+func TestClient_CreateBooking_UsesRecheckedRateKey(t *testing.T) {
+	srv := &rateServer{rateType: RateTypeRecheck}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client := NewClient("key", "secret", server.URL)
+
+	req := rateRequest()
+	_, err := client.CreateBooking(context.Background(), &BookingRequest{
+		HotelCode: req.HotelCode,
+		RoomCode:  req.RoomCode,
+		CheckIn:   req.CheckIn,
+		CheckOut:  req.CheckOut,
+		Guests:    req.Guests,
+		Holder:    HolderInfo{Name: "Budi", Email: "budi@example.com"},
+		Payment:   PaymentInfo{PaymentMethodType: "CREDITCARD"},
+		RateKey:   "key-from-availability",
+		RateType:  RateTypeRecheck,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"key-from-availability"}, srv.checked)
+	rooms := srv.booked["rooms"].([]interface{})
+	assert.Equal(t, "key-from-checkrate", rooms[0].(map[string]interface{})["rateKey"])
+}
+
+func TestClient_CreateBooking_RefusesChangedRates(t *testing.T) {
+	deadline := time.Date(2026, 11, 18, 16, 59, 0, 0, time.UTC) // The policy CheckRate returns
+	tests := []struct {
+		name     string
+		netPrice int
+		policies []CancellationPolicy
+		changed  bool
+	}{
+		{"unchanged", 2100000, []CancellationPolicy{{Amount: 1050000, From: deadline}}, false},
+		{"price went down", 2200000, []CancellationPolicy{{Amount: 1050000, From: deadline}}, false},
+		{"price went up", 1800000, []CancellationPolicy{{Amount: 1050000, From: deadline}}, true},
+		{"charges start earlier", 2100000, []CancellationPolicy{{Amount: 1050000, From: deadline.Add(24 * time.Hour)}}, true},
+		{"charge is bigger", 2100000, []CancellationPolicy{{Amount: 900000, From: deadline}}, true},
+		{"terms unknown", 2100000, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &rateServer{rateType: RateTypeRecheck}
+			server := httptest.NewServer(srv)
+			defer server.Close()
+			client := NewClient("key", "secret", server.URL)
+
+			req := rateRequest()
+			_, err := client.CreateBooking(context.Background(), &BookingRequest{
+				HotelCode:            req.HotelCode,
+				RoomCode:             req.RoomCode,
+				CheckIn:              req.CheckIn,
+				CheckOut:             req.CheckOut,
+				Guests:               req.Guests,
+				Holder:               HolderInfo{Name: "Budi", Email: "budi@example.com"},
+				Payment:              PaymentInfo{PaymentMethodType: "CREDITCARD"},
+				RateKey:              "key-from-availability",
+				RateType:             RateTypeRecheck,
+				NetPrice:             tt.netPrice,
+				CancellationPolicies: tt.policies,
+			})
+
+			if tt.changed {
+				assert.ErrorIs(t, err, ErrRateChanged)
+				assert.Nil(t, srv.booked, "a changed rate is not booked")
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, srv.booked)
+		})
+	}
+}
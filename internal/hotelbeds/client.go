@@ -20,7 +20,20 @@ type Client struct {
 	sharedSecret string
 	baseURL       string
 	httpClient    *http.Client
-	rateLimiter   *RateLimiter
+	limiter       Limiter
+	retry         RetryPolicy
+	sleep         func(ctx context.Context, d time.Duration) error
+}
+
+// APIError is a non-2xx response from the Hotelbeds API
+type APIError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // From the Retry-After header of 429 responses
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Hotelbeds API error: status %d, body: %s", e.StatusCode, e.Body)
 }
 
 // NewClient creates a new Hotelbeds client
@@ -32,28 +45,76 @@ func NewClient(apiKey, sharedSecret, baseURL string) *Client {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		rateLimiter: NewRateLimiter(100), // 100 requests per minute limit
+		limiter: NewRateLimiter(DefaultQuotas()),
+		retry:   DefaultRetryPolicy(),
+		sleep:   sleepContext,
 	}
 }
 
-// Do performs an HTTP request to Hotelbeds API
+// SetLimiter replaces the in-process rate limiter, e.g. with a RedisLimiter
+// shared between instances
+func (c *Client) SetLimiter(limiter Limiter) {
+	c.limiter = limiter
+}
+
+// SetRetryPolicy replaces the default retry policy
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// WrapTransport wraps the HTTP transport, e.g. to capture traffic. Each retry
+// attempt goes through the wrapped transport.
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
+// Do performs an HTTP request to Hotelbeds API. Failed attempts are retried
+// according to the retry policy.
 func (c *Client) Do(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
+	op := operationFor(method, endpoint)
+
+	// Marshal body if provided
+	var payload []byte
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		payload = jsonData
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, op, method, endpoint, payload)
+		if err == nil {
+			return resp, nil
+		}
+
+		delay, retry := c.retry.retryDelay(ctx, op, method, err, attempt)
+		if !retry {
+			return nil, err
+		}
+
+		logger.Warnf("Hotelbeds API %s %s failed (attempt %d of %d), retrying in %s: %v",
+			method, endpoint, attempt, c.retry.MaxAttempts, delay, err)
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, fmt.Errorf("failed to execute request: %w", err)
+		}
+	}
+}
+
+// send performs a single attempt of a request
+func (c *Client) send(ctx context.Context, op Operation, method, endpoint string, payload []byte) (*http.Response, error) {
 	// Wait for rate limiter
-	if err := c.rateLimiter.Wait(ctx); err != nil {
+	if err := c.limiter.Wait(ctx, op); err != nil {
 		return nil, fmt.Errorf("rate limiter error: %w", err)
 	}
 
 	// Build request URL
 	url := c.baseURL + endpoint
 
-	// Marshal body if provided
 	var reqBody io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewReader(jsonData)
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
 	}
 
 	// Create request
@@ -78,7 +139,12 @@ func (c *Client) Do(ctx context.Context, method, endpoint string, body interface
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		defer resp.Body.Close()
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Hotelbeds API error: status %d, body: %s", resp.StatusCode, string(bodyBytes))
+		apiErr := &APIError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			c.limiter.Throttled(ctx, op, apiErr.RetryAfter)
+		}
+		return nil, apiErr
 	}
 
 	return resp, nil
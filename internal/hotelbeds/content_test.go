@@ -0,0 +1,64 @@
+package hotelbeds
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetHotels(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/hotel-content-api/1.0/hotels", r.URL.Path)
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"from": 101, "to": 200, "total": 1, "hotels": [{"code": "12345", "name": "Grand Hyatt Bali"}]}`))
+	}))
+	defer server.Close()
+	client := NewClient("key", "secret", server.URL)
+
+	hotels, err := client.GetHotels(context.Background(), &ContentHotelsRequest{
+		DestinationCode: "DPS",
+		Language:        "IND",
+		LastUpdateTime:  time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Offset:          100,
+		Limit:           100,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "destinationCode=DPS&fields=all&from=101&language=IND&lastUpdateTime=2026-10-01&to=200", query)
+	require.Len(t, hotels, 1)
+	assert.Equal(t, "Grand Hyatt Bali", hotels[0].Name)
+}
+
+func TestClient_GetAllHotels_Pages(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.Query().Get("from")+"-"+r.URL.Query().Get("to"))
+
+		// A full first page, then a short one
+		count := MaxContentPage
+		if len(pages) > 1 {
+			count = 2
+		}
+		hotels := make([]string, count)
+		for i := range hotels {
+			hotels[i] = fmt.Sprintf(`{"code": "%d"}`, i+1)
+		}
+		fmt.Fprintf(w, `{"hotels": [%s]}`, strings.Join(hotels, ","))
+	}))
+	defer server.Close()
+	client := NewClient("key", "secret", server.URL)
+
+	hotels, err := client.GetAllHotels(context.Background(), &ContentHotelsRequest{DestinationCode: "DPS"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"1-1000", "1001-2000"}, pages)
+	assert.Len(t, hotels, MaxContentPage+2)
+}
@@ -47,11 +47,12 @@ func (c *Client) GetDestinations(ctx context.Context, countryCode string, offset
 	if countryCode != "" {
 		queryParams["countryCode"] = countryCode
 	}
+	// from and to are one-based and inclusive
 	if offset > 0 {
-		queryParams["from"] = strconv.Itoa(offset)
+		queryParams["from"] = strconv.Itoa(offset + 1)
 	}
 	if limit > 0 {
-		queryParams["to"] = strconv.Itoa(offset + limit - 1)
+		queryParams["to"] = strconv.Itoa(offset + limit)
 	}
 
 	// Add query string to endpoint
@@ -6,10 +6,14 @@ import "context"
 // This allows mocking for tests
 type ClientInterface interface {
 	GetHotelAvailability(ctx context.Context, req *AvailabilityRequest) (*AvailabilityResponse, error)
+	GetHotelsAvailability(ctx context.Context, req *HotelsAvailabilityRequest) ([]AvailabilityResponse, error)
 	GetRoomRates(ctx context.Context, req *RoomRateRequest) (*RoomRateResponse, error)
+	CheckRate(ctx context.Context, rateKey string) (*CheckRateResponse, error)
 	GetHotelDetails(ctx context.Context, hotelCode string) (*HotelDetailsResponse, error)
 	CreateBooking(ctx context.Context, req *BookingRequest) (*BookingResponse, error)
 	CancelBooking(ctx context.Context, bookingReference string) error
+	ListBookings(ctx context.Context, req *BookingListRequest) (*BookingListResponse, error)
+	GetBookingDetail(ctx context.Context, bookingReference string) (*BookingDetail, error)
 }
 
 // Ensure Client implements the interface
@@ -2,52 +2,204 @@ package hotelbeds
 
 import (
 	"context"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
-// RateLimiter implements token bucket rate limiting
+// Operation groups HotelBeds endpoints that share a rate limit
+type Operation string
+
+const (
+	OpAvailability Operation = "availability" // Availability and hotel details
+	OpCheckRate    Operation = "checkrate"
+	OpBooking      Operation = "booking"      // Booking confirmation and cancellation
+	OpBookingList  Operation = "booking_list" // Booking List and Booking Detail
+	OpContent      Operation = "content"      // Content API
+)
+
+// operationFor classifies a request by its method and endpoint
+func operationFor(method, endpoint string) Operation {
+	switch {
+	case strings.HasPrefix(endpoint, "/hotel-content-api/"):
+		return OpContent
+	case strings.HasPrefix(endpoint, "/hotel-api/1.0/checkrates"):
+		return OpCheckRate
+	case strings.HasPrefix(endpoint, "/hotel-api/1.0/bookings"):
+		if method == http.MethodGet {
+			return OpBookingList
+		}
+		return OpBooking
+	default:
+		return OpAvailability
+	}
+}
+
+// readOnly reports whether requests of this operation change nothing at
+// HotelBeds, so repeating one is harmless whatever the HTTP method
+func (op Operation) readOnly() bool {
+	return op != OpBooking
+}
+
+// Quotas are the requests per minute allowed for each operation
+type Quotas map[Operation]int
+
+// DefaultQuota applies to operations without a configured quota
+const DefaultQuota = 100
+
+// DefaultQuotas returns the default per-operation quotas
+func DefaultQuotas() Quotas {
+	return Quotas{
+		OpAvailability: 300,
+		OpCheckRate:    120,
+		OpBooking:      30,
+		OpBookingList:  60,
+		OpContent:      100,
+	}
+}
+
+func (q Quotas) perMinute(op Operation) int {
+	if n, ok := q[op]; ok && n > 0 {
+		return n
+	}
+	return DefaultQuota
+}
+
+// Limiter paces requests per operation
+type Limiter interface {
+	// Wait blocks until a request of op may be sent
+	Wait(ctx context.Context, op Operation) error
+	// Throttled records that HotelBeds rejected op with a 429 and asked us to
+	// wait retryAfter before trying again
+	Throttled(ctx context.Context, op Operation, retryAfter time.Duration)
+}
+
+// ThrottleRecoveryPeriod is how long an operation takes to climb back to its
+// full quota after a 429
+const ThrottleRecoveryPeriod = 5 * time.Minute
+
+// RateLimiter is an in-process token bucket per operation. A 429 pauses the
+// operation for the Retry-After period and halves its rate, which then
+// recovers to the quota over ThrottleRecoveryPeriod.
 type RateLimiter struct {
-	tokens chan struct{}
+	mu      sync.Mutex
+	quotas  Quotas
+	buckets map[Operation]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	quota         float64 // Requests per minute at full speed
+	tokens        float64
+	last          time.Time
+	pausedUntil   time.Time
+	throttledAt   time.Time
+	throttledRate float64
+}
+
+// rate returns the requests per minute allowed at now
+func (b *bucket) rate(now time.Time) float64 {
+	if b.throttledAt.IsZero() {
+		return b.quota
+	}
+	elapsed := now.Sub(b.throttledAt)
+	if elapsed >= ThrottleRecoveryPeriod {
+		b.throttledAt = time.Time{}
+		return b.quota
+	}
+	return b.throttledRate + (b.quota-b.throttledRate)*float64(elapsed)/float64(ThrottleRecoveryPeriod)
 }
 
-// NewRateLimiter creates a new rate limiter
-// requestsPerMinute is the maximum number of requests allowed per minute
-func NewRateLimiter(requestsPerMinute int) *RateLimiter {
-	rl := &RateLimiter{
-		tokens: make(chan struct{}, requestsPerMinute),
+// NewRateLimiter creates an in-process limiter with the given quotas
+func NewRateLimiter(quotas Quotas) *RateLimiter {
+	return &RateLimiter{
+		quotas:  quotas,
+		buckets: make(map[Operation]*bucket),
+		now:     time.Now,
 	}
+}
 
-	// Fill tokens initially
-	for i := 0; i < requestsPerMinute; i++ {
-		rl.tokens <- struct{}{}
+func (rl *RateLimiter) bucket(op Operation) *bucket {
+	b, ok := rl.buckets[op]
+	if !ok {
+		quota := float64(rl.quotas.perMinute(op))
+		b = &bucket{quota: quota, tokens: quota, last: rl.now()}
+		rl.buckets[op] = b
 	}
+	return b
+}
+
+// reserve takes a token for op, or returns how long to wait for one
+func (rl *RateLimiter) reserve(op Operation) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
 
-	// Start refill goroutine
-	go rl.refill(requestsPerMinute)
+	now := rl.now()
+	b := rl.bucket(op)
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now)
+	}
+
+	rate := b.rate(now)
+	b.tokens += now.Sub(b.last).Minutes() * rate
+	if b.tokens > rate {
+		b.tokens = rate
+	}
+	b.last = now
 
-	return rl
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / rate * float64(time.Minute))
 }
 
 // Wait waits for a token to be available
-func (rl *RateLimiter) Wait(ctx context.Context) error {
-	select {
-	case <-rl.tokens:
-		return nil
-	case <-ctx.Done():
-		return ctx.Err()
+func (rl *RateLimiter) Wait(ctx context.Context, op Operation) error {
+	for {
+		wait := rl.reserve(op)
+		if wait <= 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
 	}
 }
 
-// refill periodically adds tokens to the bucket
-func (rl *RateLimiter) refill(requestsPerMinute int) {
-	ticker := time.NewTicker(time.Minute / time.Duration(requestsPerMinute))
-	defer ticker.Stop()
+// Throttled pauses op and halves its rate
+func (rl *RateLimiter) Throttled(ctx context.Context, op Operation, retryAfter time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
 
-	for range ticker.C {
-		select {
-		case rl.tokens <- struct{}{}:
-		default:
-			// Bucket is full, drop token
-		}
+	now := rl.now()
+	b := rl.bucket(op)
+	if until := now.Add(retryAfter); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+	// No tokens accrue while paused
+	b.last = now
+	if b.pausedUntil.After(now) {
+		b.last = b.pausedUntil
+	}
+	b.throttledRate = b.rate(now) / 2
+	if b.throttledRate < 1 {
+		b.throttledRate = 1
+	}
+	b.throttledAt = now
+	b.tokens = 0
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
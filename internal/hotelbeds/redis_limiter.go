@@ -0,0 +1,93 @@
+package hotelbeds
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisLimiter shares per-operation quotas between instances with a
+// per-minute counter in Redis. A 429 seen by one instance pauses the
+// operation for all of them. When Redis is unreachable it falls back to an
+// in-process limiter, so supplier calls are never blocked by Redis.
+type RedisLimiter struct {
+	client   *redis.Client
+	prefix   string
+	quotas   Quotas
+	fallback *RateLimiter
+	now      func() time.Time
+}
+
+// NewRedisLimiter creates a limiter shared through Redis
+func NewRedisLimiter(client *redis.Client, quotas Quotas) *RedisLimiter {
+	return &RedisLimiter{
+		client:   client,
+		prefix:   "hotelbeds:ratelimit",
+		quotas:   quotas,
+		fallback: NewRateLimiter(quotas),
+		now:      time.Now,
+	}
+}
+
+func (l *RedisLimiter) pauseKey(op Operation) string {
+	return fmt.Sprintf("%s:%s:pause", l.prefix, op)
+}
+
+// Wait blocks until the shared quota for op has room
+func (l *RedisLimiter) Wait(ctx context.Context, op Operation) error {
+	for {
+		wait, err := l.reserve(ctx, op)
+		if err != nil {
+			logger.Warnf("Shared HotelBeds rate limit unavailable, limiting locally: %v", err)
+			return l.fallback.Wait(ctx, op)
+		}
+		if wait <= 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve counts a request against the current minute, or returns how long
+// to wait
+func (l *RedisLimiter) reserve(ctx context.Context, op Operation) (time.Duration, error) {
+	pause, err := l.client.PTTL(ctx, l.pauseKey(op)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if pause > 0 {
+		return pause, nil
+	}
+
+	now := l.now()
+	window := now.Truncate(time.Minute)
+	key := fmt.Sprintf("%s:%s:%d", l.prefix, op, window.Unix())
+
+	pipe := l.client.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, 2*time.Minute)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	if count.Val() <= int64(l.quotas.perMinute(op)) {
+		return 0, nil
+	}
+	return window.Add(time.Minute).Sub(now), nil
+}
+
+// Throttled pauses op on every instance for retryAfter
+func (l *RedisLimiter) Throttled(ctx context.Context, op Operation, retryAfter time.Duration) {
+	l.fallback.Throttled(ctx, op, retryAfter)
+	if retryAfter <= 0 {
+		return
+	}
+	if err := l.client.Set(ctx, l.pauseKey(op), "1", retryAfter).Err(); err != nil {
+		logger.Warnf("Failed to share HotelBeds throttle for %s: %v", op, err)
+	}
+}
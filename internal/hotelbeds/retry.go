@@ -0,0 +1,106 @@
+package hotelbeds
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	MaxAttempts   int           // Including the first attempt
+	BaseDelay     time.Duration // Backoff before the first retry, doubled for each further one
+	MaxDelay      time.Duration // Longest backoff between attempts
+	MaxRetryAfter time.Duration // 429s asking us to wait longer than this are not retried
+}
+
+// DefaultRetryPolicy returns the default retry policy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:   3,
+		BaseDelay:     500 * time.Millisecond,
+		MaxDelay:      5 * time.Second,
+		MaxRetryAfter: 30 * time.Second,
+	}
+}
+
+// backoff returns a jittered delay before retry number attempt (1-based)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Full jitter keeps instances that failed together from retrying together
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// retryDelay decides whether a failed attempt is retried and after how long.
+// Read-only requests are retried on throttling, server errors and network
+// failures. Bookings are only retried when HotelBeds cannot have processed
+// them: a 429, or a connection that was never established.
+func (p RetryPolicy) retryDelay(ctx context.Context, op Operation, method string, err error, attempt int) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+	idempotent := method == http.MethodGet || op.readOnly()
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			if apiErr.RetryAfter > p.MaxRetryAfter {
+				return 0, false
+			}
+			if apiErr.RetryAfter > 0 {
+				return apiErr.RetryAfter, true
+			}
+			return p.backoff(attempt), true
+		case apiErr.StatusCode == http.StatusRequestTimeout || apiErr.StatusCode >= 500:
+			return p.backoff(attempt), idempotent
+		default:
+			return 0, false
+		}
+	}
+
+	if notSent(err) {
+		return p.backoff(attempt), true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return p.backoff(attempt), idempotent
+	}
+	return 0, false
+}
+
+// notSent reports whether err means the request never reached HotelBeds
+func notSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
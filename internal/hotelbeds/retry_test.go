@@ -0,0 +1,183 @@
+package hotelbeds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRetryTestClient returns a client that records backoff delays instead of sleeping
+func newRetryTestClient(baseURL string) (*Client, *[]time.Duration) {
+	client := NewClient("key", "secret", baseURL)
+	var delays []time.Duration
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return client, &delays
+}
+
+// recordingLimiter never waits and records throttling
+type recordingLimiter struct {
+	throttled map[Operation]time.Duration
+}
+
+func (l *recordingLimiter) Wait(ctx context.Context, op Operation) error { return nil }
+
+func (l *recordingLimiter) Throttled(ctx context.Context, op Operation, retryAfter time.Duration) {
+	if l.throttled == nil {
+		l.throttled = make(map[Operation]time.Duration)
+	}
+	l.throttled[op] = retryAfter
+}
+
+// failingServer answers the first failures requests with status, then succeeds
+func failingServer(failures int32, status int, retryAfter string) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{"reference": "HB-1", "status": "CONFIRMED"}`))
+	}))
+	return server, &calls
+}
+
+func TestClient_Do_RetriesReadsOnServerErrors(t *testing.T) {
+	server, calls := failingServer(2, http.StatusServiceUnavailable, "")
+	defer server.Close()
+	client, delays := newRetryTestClient(server.URL)
+
+	resp, err := client.Get(context.Background(), "/hotel-api/1.0/bookings/HB-1")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	require.Len(t, *delays, 2)
+	assert.LessOrEqual(t, (*delays)[0], 500*time.Millisecond)
+	assert.LessOrEqual(t, (*delays)[1], time.Second)
+}
+
+func TestClient_Do_DoesNotRetryBookingsOnServerErrors(t *testing.T) {
+	server, calls := failingServer(1, http.StatusBadGateway, "")
+	defer server.Close()
+	client, delays := newRetryTestClient(server.URL)
+
+	_, err := client.Post(context.Background(), "/hotel-api/1.0/bookings", map[string]string{"clientReference": "BKG-1"})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	assert.Empty(t, *delays)
+}
+
+func TestClient_Do_RetriesThrottledBookingsAfterRetryAfter(t *testing.T) {
+	server, calls := failingServer(1, http.StatusTooManyRequests, "2")
+	defer server.Close()
+	client, delays := newRetryTestClient(server.URL)
+	limiter := &recordingLimiter{}
+	client.SetLimiter(limiter)
+
+	resp, err := client.Post(context.Background(), "/hotel-api/1.0/bookings", map[string]string{"clientReference": "BKG-1"})
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+	assert.Equal(t, []time.Duration{2 * time.Second}, *delays)
+	assert.Equal(t, map[Operation]time.Duration{OpBooking: 2 * time.Second}, limiter.throttled)
+}
+
+func TestClient_Do_GivesUpOnLongRetryAfter(t *testing.T) {
+	server, calls := failingServer(1, http.StatusTooManyRequests, "120")
+	defer server.Close()
+	client, delays := newRetryTestClient(server.URL)
+
+	_, err := client.Get(context.Background(), "/hotel-api/1.0/hotels/H1?language=ENG")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 2*time.Minute, apiErr.RetryAfter)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	assert.Empty(t, *delays)
+}
+
+func TestClient_Do_RetriesBookingsThatWereNeverSent(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close() // Connections are refused
+	client, delays := newRetryTestClient(server.URL)
+
+	_, err := client.Post(context.Background(), "/hotel-api/1.0/bookings", map[string]string{"clientReference": "BKG-1"})
+	require.Error(t, err)
+	assert.Len(t, *delays, 2)
+}
+
+func TestRateLimiter_QuotaPerOperation(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(Quotas{OpBooking: 2, OpAvailability: 60})
+	limiter.now = func() time.Time { return now }
+
+	assert.Zero(t, limiter.reserve(OpBooking))
+	assert.Zero(t, limiter.reserve(OpBooking))
+	assert.Equal(t, 30*time.Second, limiter.reserve(OpBooking))
+
+	// Other operations have their own quota
+	assert.Zero(t, limiter.reserve(OpAvailability))
+
+	now = now.Add(30 * time.Second)
+	assert.Zero(t, limiter.reserve(OpBooking))
+}
+
+func TestRateLimiter_ThrottledPausesAndSlowsDown(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(Quotas{OpAvailability: 60})
+	limiter.now = func() time.Time { return now }
+
+	limiter.Throttled(context.Background(), OpAvailability, 5*time.Second)
+	assert.Equal(t, 5*time.Second, limiter.reserve(OpAvailability))
+
+	// After the pause the operation runs at half its quota
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, 2*time.Second, limiter.reserve(OpAvailability).Round(100*time.Millisecond))
+
+	// and is back to full speed once recovered
+	now = now.Add(ThrottleRecoveryPeriod)
+	assert.Zero(t, limiter.reserve(OpAvailability))
+}
+
+func TestOperationFor(t *testing.T) {
+	tests := []struct {
+		method   string
+		endpoint string
+		want     Operation
+	}{
+		{http.MethodPost, "/hotel-api/1.0/hotels/H1/availability", OpAvailability},
+		{http.MethodGet, "/hotel-api/1.0/hotels/H1?language=ENG", OpAvailability},
+		{http.MethodPost, "/hotel-api/1.0/checkrates", OpCheckRate},
+		{http.MethodPost, "/hotel-api/1.0/bookings", OpBooking},
+		{http.MethodPut, "/hotel-api/1.0/bookings/HB-1/cancellation", OpBooking},
+		{http.MethodGet, "/hotel-api/1.0/bookings?from=1&to=100", OpBookingList},
+		{http.MethodGet, "/hotel-content-api/1.0/hotels", OpContent},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, operationFor(tt.method, tt.endpoint), tt.endpoint)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 7*time.Second, parseRetryAfter("7", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter("Sun, 18 Oct 2026 09:01:30 GMT", now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+}
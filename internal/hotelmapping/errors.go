@@ -0,0 +1,9 @@
+package hotelmapping
+
+import "errors"
+
+// Package-level errors for hotel mapping operations
+var (
+	ErrMasterNotFound = errors.New("master hotel not found")
+	ErrHotelNotFound  = errors.New("hotel not found")
+)
@@ -0,0 +1,163 @@
+package hotelmapping
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/admin"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Handler handles admin HTTP requests for hotel mappings
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new hotel mapping handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// AssignRequest is the body of a request moving a hotel to a master hotel
+type AssignRequest struct {
+	MasterHotelID string `json:"master_hotel_id"`
+}
+
+// ListMasters handles GET /admin/hotel-mappings/masters
+// Filters: ?city=, ?country=, ?merged=true for masters with several provider hotels.
+func (h *Handler) ListMasters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 || l > 100 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+		limit = l
+	}
+
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		o, err := strconv.Atoi(offsetStr)
+		if err != nil || o < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset parameter")
+			return
+		}
+		offset = o
+	}
+
+	filter := ListFilter{
+		City:        query.Get("city"),
+		CountryCode: query.Get("country"),
+		MergedOnly:  query.Get("merged") == "true",
+	}
+
+	masters, total, err := h.service.ListMasters(r.Context(), filter, limit, offset)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to list master hotels")
+		respondWithError(w, http.StatusInternalServerError, "Failed to list master hotels")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"masters": masters,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// GetMaster handles GET /admin/hotel-mappings/masters/{id}
+func (h *Handler) GetMaster(w http.ResponseWriter, r *http.Request) {
+	master, err := h.service.GetMaster(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.respondWithServiceError(w, err, "Failed to get master hotel")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, master)
+}
+
+// AssignHotel handles PUT /admin/hotel-mappings/hotels/{id}
+func (h *Handler) AssignHotel(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := admin.AdminIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Admin ID not found in request")
+		return
+	}
+
+	var req AssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.MasterHotelID == "" {
+		respondWithError(w, http.StatusBadRequest, "master_hotel_id is required")
+		return
+	}
+
+	master, err := h.service.AssignHotel(r.Context(), r.PathValue("id"), req.MasterHotelID, adminID)
+	if err != nil {
+		h.respondWithServiceError(w, err, "Failed to assign hotel mapping")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, master)
+}
+
+// DetachHotel handles DELETE /admin/hotel-mappings/hotels/{id}
+func (h *Handler) DetachHotel(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := admin.AdminIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Admin ID not found in request")
+		return
+	}
+
+	master, err := h.service.DetachHotel(r.Context(), r.PathValue("id"), adminID)
+	if err != nil {
+		h.respondWithServiceError(w, err, "Failed to detach hotel mapping")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, master)
+}
+
+// Rebuild handles POST /admin/hotel-mappings/rebuild
+func (h *Handler) Rebuild(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.Rebuild(r.Context())
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to rebuild hotel mappings")
+		respondWithError(w, http.StatusInternalServerError, "Failed to rebuild hotel mappings")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) respondWithServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, ErrMasterNotFound):
+		respondWithError(w, http.StatusNotFound, "Master hotel not found")
+	case errors.Is(err, ErrHotelNotFound):
+		respondWithError(w, http.StatusNotFound, "Hotel not found")
+	default:
+		logger.ErrorWithErr(err, message)
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
+
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	respondWithJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,292 @@
+package hotelmapping
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MatchThreshold is the score at which two provider hotels are taken to be the same hotel
+	MatchThreshold = 0.7
+	// MaxDistanceMeters is how far apart two listings of the same hotel can be
+	MaxDistanceMeters = 1000.0
+)
+
+// Weights of each kind of evidence in a match score
+const (
+	nameWeight    = 0.5
+	geoWeight     = 0.3
+	phoneWeight   = 0.3
+	addressWeight = 0.2
+)
+
+// nameStopWords are words that don't tell hotels apart
+var nameStopWords = map[string]bool{
+	"hotel": true, "hotels": true, "resort": true, "resorts": true,
+	"the": true, "and": true, "dan": true, "by": true, "at": true, "a": true,
+}
+
+// addressStopWords are street abbreviations providers spell differently
+var addressStopWords = map[string]bool{
+	"jl": true, "jln": true, "jalan": true, "no": true, "street": true, "st": true,
+	"road": true, "rd": true, "kec": true, "kel": true, "kab": true,
+}
+
+// Match scores how likely two provider hotels are the same physical hotel,
+// from 0 to 1. Hotels in different countries or further apart than
+// MaxDistanceMeters never match.
+func Match(a, b *Candidate) float64 {
+	if a.CountryCode != "" && b.CountryCode != "" && !strings.EqualFold(a.CountryCode, b.CountryCode) {
+		return 0
+	}
+
+	score := nameWeight * nameSimilarity(a.Name, b.Name)
+
+	if distance, ok := candidateDistance(a, b); ok {
+		if distance > MaxDistanceMeters {
+			return 0
+		}
+		score += geoWeight * (1 - distance/MaxDistanceMeters)
+	}
+
+	if pa, pb := NormalizePhone(a.Phone), NormalizePhone(b.Phone); pa != "" && pa == pb {
+		score += phoneWeight
+	}
+
+	if a.Address != "" && b.Address != "" {
+		score += addressWeight * diceSimilarity(tokens(a.Address, addressStopWords), tokens(b.Address, addressStopWords))
+	}
+
+	return math.Min(score, 1)
+}
+
+// NormalizeName lowercases a hotel name and drops punctuation and words like "hotel"
+func NormalizeName(name string) string {
+	return strings.Join(tokens(name, nameStopWords), " ")
+}
+
+// NormalizePhone reduces a phone number to its national digits, e.g.
+// "+62 361 123-456" and "(0361) 123456" both become "361123456". Numbers too
+// short to identify a hotel normalize to "".
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+
+	n := strings.TrimPrefix(digits.String(), "00")
+	n = strings.TrimPrefix(n, "62")
+	n = strings.TrimLeft(n, "0")
+	if len(n) < 6 {
+		return ""
+	}
+	return n
+}
+
+// nameSimilarity compares names word by word. A name whose words all appear
+// in the other, like "Grand Hyatt Bali" in "Grand Hyatt Bali Nusa Dua", is a
+// near match.
+func nameSimilarity(a, b string) float64 {
+	ta, tb := tokens(a, nameStopWords), tokens(b, nameStopWords)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	similarity := diceSimilarity(ta, tb)
+	if similarity < 0.9 && (containsAll(ta, tb) || containsAll(tb, ta)) {
+		similarity = 0.9
+	}
+	return similarity
+}
+
+// tokens splits text into lowercase words, leaving out stop words
+func tokens(s string, stopWords map[string]bool) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	out := fields[:0]
+	for _, f := range fields {
+		if !stopWords[f] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// diceSimilarity is twice the number of shared words over the total number of words
+func diceSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int, len(a))
+	for _, w := range a {
+		counts[w]++
+	}
+	shared := 0
+	for _, w := range b {
+		if counts[w] > 0 {
+			counts[w]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
+
+// containsAll reports whether every word of sub is in words
+func containsAll(sub, words []string) bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	for _, w := range sub {
+		if !set[w] {
+			return false
+		}
+	}
+	return true
+}
+
+// candidateDistance returns the distance between two hotels in meters, if both have coordinates
+func candidateDistance(a, b *Candidate) (float64, bool) {
+	if a.Latitude == nil || a.Longitude == nil || b.Latitude == nil || b.Longitude == nil {
+		return 0, false
+	}
+	return DistanceMeters(*a.Latitude, *a.Longitude, *b.Latitude, *b.Longitude), true
+}
+
+// DistanceMeters returns the great-circle distance between two points
+func DistanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadius = 6371000.0
+
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// cluster is a group of provider hotels that are one physical hotel
+type cluster struct {
+	masterID string
+	members  []*Candidate
+	scores   map[string]float64 // Match score of each member against the rest
+	manual   bool
+}
+
+// closed reports whether the matcher may not add hotels to the cluster. An
+// admin detaching a hotel leaves it alone in a manual cluster, and it has to
+// stay alone.
+func (c *cluster) closed() bool {
+	return c.manual && len(c.members) == 1
+}
+
+func (c *cluster) add(candidate *Candidate, score float64) {
+	c.members = append(c.members, candidate)
+	c.scores[candidate.HotelID] = score
+}
+
+// clusterCandidates groups the hotels of one area into clusters. Manually
+// mapped hotels stay where an admin put them; every other hotel joins the
+// cluster it matches best, or starts its own. A cluster never takes two
+// hotels from the same provider, since a provider lists a hotel once.
+// Clusters keep the master ID most of their members had, so master hotels
+// survive rebuilds; a new cluster gets an empty master ID.
+func clusterCandidates(candidates []*Candidate) []*cluster {
+	var clusters []*cluster
+	manual := make(map[string]*cluster)
+	var auto []*Candidate
+
+	for _, c := range candidates {
+		if c.Source != SourceManual || c.MasterHotelID == "" {
+			auto = append(auto, c)
+			continue
+		}
+		cl, exists := manual[c.MasterHotelID]
+		if !exists {
+			cl = &cluster{masterID: c.MasterHotelID, scores: make(map[string]float64), manual: true}
+			manual[c.MasterHotelID] = cl
+			clusters = append(clusters, cl)
+		}
+		cl.add(c, 1)
+	}
+
+	sort.Slice(auto, func(i, j int) bool { return auto[i].HotelID < auto[j].HotelID })
+
+	for _, c := range auto {
+		var best *cluster
+		bestScore := 0.0
+		for _, cl := range clusters {
+			if cl.closed() {
+				continue
+			}
+			if score, ok := clusterScore(cl, c); ok && score >= MatchThreshold && score > bestScore {
+				best, bestScore = cl, score
+			}
+		}
+
+		if best == nil {
+			cl := &cluster{scores: make(map[string]float64)}
+			cl.add(c, 1)
+			clusters = append(clusters, cl)
+			continue
+		}
+		best.add(c, bestScore)
+	}
+
+	assignMasterIDs(clusters)
+	return clusters
+}
+
+// clusterScore is a hotel's best match against the cluster's members, or
+// false when the cluster already has a hotel from the same provider
+func clusterScore(cl *cluster, c *Candidate) (float64, bool) {
+	best := 0.0
+	for _, m := range cl.members {
+		if m.ProviderCode == c.ProviderCode {
+			return 0, false
+		}
+		best = math.Max(best, Match(m, c))
+	}
+	return best, true
+}
+
+// assignMasterIDs gives each automatic cluster the master ID most of its
+// members already had, unless another cluster took it first
+func assignMasterIDs(clusters []*cluster) {
+	used := make(map[string]bool)
+	for _, cl := range clusters {
+		if cl.manual {
+			used[cl.masterID] = true
+		}
+	}
+
+	for _, cl := range clusters {
+		if cl.manual {
+			continue
+		}
+
+		votes := make(map[string]int)
+		for _, m := range cl.members {
+			if m.MasterHotelID != "" && !used[m.MasterHotelID] {
+				votes[m.MasterHotelID]++
+			}
+		}
+
+		for id, n := range votes {
+			if n > votes[cl.masterID] || (n == votes[cl.masterID] && id < cl.masterID) {
+				cl.masterID = id
+			}
+		}
+		if cl.masterID != "" {
+			used[cl.masterID] = true
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package hotelmapping
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func coords(lat, lng float64) (*float64, *float64) {
+	return &lat, &lng
+}
+
+func candidate(id, provider, name string, lat, lng float64) *Candidate {
+	c := &Candidate{HotelID: id, ProviderCode: provider, ProviderHotelID: id, Name: name, City: "Badung", CountryCode: "ID"}
+	if lat != 0 || lng != 0 {
+		c.Latitude, c.Longitude = coords(lat, lng)
+	}
+	return c
+}
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "grand hyatt bali", NormalizeName("The Grand Hyatt, Bali"))
+	assert.Equal(t, "ayana villas", NormalizeName("AYANA Resort & Villas"))
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"+62 361 771234", "361771234"},
+		{"(0361) 771-234", "361771234"},
+		{"0062361771234", "361771234"},
+		{"123", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, NormalizePhone(tt.in), tt.in)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	base := candidate("hb-1", "hotelbeds", "Grand Hyatt Bali", -8.8003, 115.2323)
+	base.Phone = "+62 361 771234"
+	base.Address = "Jl. Nusa Dua Selatan, Kawasan Wisata"
+
+	tests := []struct {
+		name  string
+		other *Candidate
+		match bool
+	}{
+		{
+			name:  "same hotel a few meters apart",
+			other: candidate("hp-1", "hotelplanner", "The Grand Hyatt Bali Hotel", -8.8004, 115.2324),
+			match: true,
+		},
+		{
+			name: "same phone and address without coordinates",
+			other: &Candidate{HotelID: "hp-2", ProviderCode: "hotelplanner", Name: "Grand Hyatt Bali Nusa Dua",
+				CountryCode: "ID", Phone: "(0361) 771234", Address: "Nusa Dua Selatan, Kawasan Wisata"},
+			match: true,
+		},
+		{
+			name:  "neighbouring hotel",
+			other: candidate("hp-3", "hotelplanner", "Sofitel Bali Nusa Dua", -8.8010, 115.2330),
+			match: false,
+		},
+		{
+			name:  "same name in another town",
+			other: candidate("hp-4", "hotelplanner", "Grand Hyatt Bali", -8.6500, 115.2167),
+			match: false,
+		},
+		{
+			name: "different country",
+			other: &Candidate{HotelID: "hp-5", ProviderCode: "hotelplanner", Name: "Grand Hyatt Bali",
+				CountryCode: "SG", Phone: "+62 361 771234"},
+			match: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := Match(base, tt.other)
+			assert.Equal(t, tt.match, score >= MatchThreshold, "score %.2f", score)
+			assert.InDelta(t, score, Match(tt.other, base), 1e-9, "match should be symmetric")
+		})
+	}
+}
+
+func TestDistanceMeters(t *testing.T) {
+	// Ngurah Rai airport to Kuta beach is about 3 km
+	assert.InDelta(t, 3000, DistanceMeters(-8.7482, 115.1672, -8.7203, 115.1686), 200)
+}
+
+func TestClusterCandidates(t *testing.T) {
+	hb := candidate("a-hb", "hotelbeds", "Grand Hyatt Bali", -8.8003, 115.2323)
+	hb.MasterHotelID = "master-1"
+	hp := candidate("b-hp", "hotelplanner", "Grand Hyatt Bali", -8.8004, 115.2324)
+	// A provider lists a hotel once, so its own near-duplicate is a different hotel
+	hbTwin := candidate("c-hb", "hotelbeds", "Grand Hyatt Bali", -8.8003, 115.2323)
+	other := candidate("d-hp", "hotelplanner", "Sofitel Bali Nusa Dua", -8.8010, 115.2330)
+
+	clusters := clusterCandidates([]*Candidate{other, hbTwin, hp, hb})
+
+	require.Len(t, clusters, 3)
+	assert.Equal(t, "master-1", clusters[0].masterID, "cluster keeps its master ID")
+	assert.Equal(t, []*Candidate{hb, hp}, clusters[0].members)
+	assert.GreaterOrEqual(t, clusters[0].scores["b-hp"], MatchThreshold)
+	assert.Equal(t, []*Candidate{hbTwin}, clusters[1].members)
+	assert.Empty(t, clusters[1].masterID)
+	assert.Equal(t, []*Candidate{other}, clusters[2].members)
+}
+
+func TestClusterCandidates_ManualMappings(t *testing.T) {
+	// An admin merged two hotels the matcher can't tell apart...
+	merged1 := candidate("a-hb", "hotelbeds", "Kuta Paradiso", 0, 0)
+	merged1.MasterHotelID, merged1.Source = "master-merged", SourceManual
+	merged2 := candidate("b-hp", "hotelplanner", "Paradiso Kuta Beach Hotel Bali", 0, 0)
+	merged2.MasterHotelID, merged2.Source = "master-merged", SourceManual
+
+	// ...and split off one the matcher wrongly merged
+	detached := candidate("c-hb", "hotelbeds", "Grand Hyatt Bali", -8.8003, 115.2323)
+	detached.MasterHotelID, detached.Source = "master-detached", SourceManual
+	lookalike := candidate("d-hp", "hotelplanner", "Grand Hyatt Bali", -8.8004, 115.2324)
+	lookalike.MasterHotelID, lookalike.Source = "master-detached", SourceAuto
+
+	clusters := clusterCandidates([]*Candidate{lookalike, detached, merged2, merged1})
+
+	require.Len(t, clusters, 3)
+	byMaster := make(map[string][]*Candidate)
+	for _, cl := range clusters {
+		byMaster[cl.masterID] = cl.members
+	}
+	assert.ElementsMatch(t, []*Candidate{merged1, merged2}, byMaster["master-merged"])
+	assert.Equal(t, []*Candidate{detached}, byMaster["master-detached"])
+	assert.Equal(t, []*Candidate{lookalike}, byMaster[""], "detached hotel stays alone")
+}
+
+func TestNewMaster(t *testing.T) {
+	sparse := candidate("a-hb", "hotelbeds", "Grand Hyatt", 0, 0)
+	sparse.Phone = "+62 361 771234"
+	rich := candidate("b-hp", "hotelplanner", "Grand Hyatt Bali", -8.8004, 115.2324)
+	rich.ImageCount = 12
+	rich.DescriptionLen = 800
+
+	master := newMaster("master-1", []*Candidate{sparse, rich})
+
+	assert.Equal(t, "master-1", master.ID)
+	assert.Equal(t, "b-hp", master.ContentHotelID)
+	assert.Equal(t, "Grand Hyatt Bali", master.Name)
+	assert.Equal(t, "+62 361 771234", master.Phone, "gaps are filled from other hotels")
+	assert.Equal(t, 2, master.HotelCount)
+}
@@ -0,0 +1,146 @@
+package hotelmapping
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Source records how a provider hotel got mapped to its master hotel
+type Source string
+
+const (
+	// SourceAuto mappings are made by the matcher and redone on every rebuild
+	SourceAuto Source = "AUTO"
+	// SourceManual mappings are made by an admin and kept by rebuilds
+	SourceManual Source = "MANUAL"
+)
+
+// Candidate is a provider hotel as the matcher sees it
+type Candidate struct {
+	HotelID         string
+	ProviderCode    string
+	ProviderHotelID string
+	Name            string
+	Address         string
+	City            string
+	CountryCode     string
+	Phone           string
+	Latitude        *float64
+	Longitude       *float64
+	StarRating      float64
+	DescriptionLen  int
+	ImageCount      int
+
+	// Current mapping, empty when the hotel was never mapped
+	MasterHotelID string
+	Source        Source
+}
+
+// MasterHotel is one physical hotel that one or more provider hotels sell
+type MasterHotel struct {
+	ID             string     `json:"id" db:"id"`
+	Name           string     `json:"name" db:"name"`
+	City           string     `json:"city" db:"city"`
+	CountryCode    string     `json:"country_code" db:"country_code"`
+	Address        string     `json:"address,omitempty" db:"address"`
+	Phone          string     `json:"phone,omitempty" db:"phone"`
+	Latitude       *float64   `json:"latitude,omitempty" db:"latitude"`
+	Longitude      *float64   `json:"longitude,omitempty" db:"longitude"`
+	StarRating     float64    `json:"star_rating,omitempty" db:"star_rating"`
+	ContentHotelID string     `json:"content_hotel_id" db:"content_hotel_id"` // Provider hotel whose description and images are shown
+	HotelCount     int        `json:"hotel_count" db:"-"`
+	Hotels         []*Mapping `json:"hotels,omitempty" db:"-"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Mapping links a provider hotel to its master hotel
+type Mapping struct {
+	HotelID         string    `json:"hotel_id" db:"hotel_id"`
+	MasterHotelID   string    `json:"master_hotel_id" db:"master_hotel_id"`
+	ProviderCode    string    `json:"provider_code" db:"provider_code"`
+	ProviderHotelID string    `json:"provider_hotel_id" db:"provider_hotel_id"`
+	HotelName       string    `json:"hotel_name" db:"hotel_name"`
+	Score           float64   `json:"score" db:"match_score"` // Match score against the rest of the cluster, 1 for a lone hotel
+	Source          Source    `json:"source" db:"source"`
+	UpdatedBy       string    `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// newMaster creates a master hotel from its cluster, taking content from the
+// richest provider hotel and filling gaps from the others
+func newMaster(id string, members []*Candidate) *MasterHotel {
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	content := members[0]
+	for _, c := range members[1:] {
+		if richerContent(c, content) {
+			content = c
+		}
+	}
+
+	now := time.Now()
+	m := &MasterHotel{
+		ID:             id,
+		Name:           content.Name,
+		City:           content.City,
+		CountryCode:    content.CountryCode,
+		Address:        content.Address,
+		Phone:          content.Phone,
+		Latitude:       content.Latitude,
+		Longitude:      content.Longitude,
+		StarRating:     content.StarRating,
+		ContentHotelID: content.HotelID,
+		HotelCount:     len(members),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	for _, c := range members {
+		if m.Address == "" {
+			m.Address = c.Address
+		}
+		if m.Phone == "" {
+			m.Phone = c.Phone
+		}
+		if m.Latitude == nil && c.Latitude != nil && c.Longitude != nil {
+			m.Latitude, m.Longitude = c.Latitude, c.Longitude
+		}
+		if m.StarRating == 0 {
+			m.StarRating = c.StarRating
+		}
+	}
+
+	return m
+}
+
+// richerContent reports whether a has more to show than b: more images, then
+// a longer description, then the lower ID so the choice is stable
+func richerContent(a, b *Candidate) bool {
+	if a.ImageCount != b.ImageCount {
+		return a.ImageCount > b.ImageCount
+	}
+	if a.DescriptionLen != b.DescriptionLen {
+		return a.DescriptionLen > b.DescriptionLen
+	}
+	return a.HotelID < b.HotelID
+}
+
+// ListFilter filters master hotels in the admin mapping screens
+type ListFilter struct {
+	City        string
+	CountryCode string
+	MergedOnly  bool // Only masters that more than one provider hotel maps to
+}
+
+// RebuildResult summarises a mapping rebuild
+type RebuildResult struct {
+	Hotels   int           `json:"hotels"`
+	Masters  int           `json:"masters"`
+	Merged   int           `json:"merged"` // Hotels mapped together with at least one other
+	Manual   int           `json:"manual"` // Hotels kept where an admin put them
+	Duration time.Duration `json:"duration"`
+}
@@ -0,0 +1,339 @@
+package hotelmapping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// ProviderRef identifies a hotel the way a provider does
+type ProviderRef struct {
+	ProviderCode    string
+	ProviderHotelID string
+}
+
+// Repository defines interface for hotel mapping data operations
+type Repository interface {
+	// ListCandidates returns every active provider hotel with its current mapping
+	ListCandidates(ctx context.Context) ([]*Candidate, error)
+	GetCandidate(ctx context.Context, hotelID string) (*Candidate, error)
+	ListCandidatesByMaster(ctx context.Context, masterID string) ([]*Candidate, error)
+	// SaveClusters upserts master hotels and mappings in one transaction and
+	// deletes master hotels no hotel maps to any more
+	SaveClusters(ctx context.Context, masters []*MasterHotel, mappings []*Mapping) error
+	// DeleteStaleMappings removes mappings of hotels that were deleted
+	DeleteStaleMappings(ctx context.Context) (int, error)
+	GetMaster(ctx context.Context, id string) (*MasterHotel, error)
+	ListMasters(ctx context.Context, filter ListFilter, limit, offset int) ([]*MasterHotel, int, error)
+	// FindMasterIDs maps provider hotel references to master hotel IDs; unmapped hotels are left out
+	FindMasterIDs(ctx context.Context, refs []ProviderRef) (map[ProviderRef]string, error)
+}
+
+type repository struct {
+	db *db.DB
+}
+
+// NewRepository creates a new hotel mapping repository
+func NewRepository(database *db.DB) Repository {
+	return &repository{
+		db: database,
+	}
+}
+
+const candidateQuery = `
+	SELECT h.id, h.provider_code, h.provider_hotel_id, h.name, COALESCE(h.address, ''),
+		COALESCE(h.city, ''), COALESCE(h.country_code, ''), COALESCE(h.phone, ''),
+		h.latitude, h.longitude, COALESCE(h.star_rating, 0), COALESCE(LENGTH(h.description), 0),
+		CASE WHEN jsonb_typeof(h.images) = 'array' THEN jsonb_array_length(h.images) ELSE 0 END,
+		COALESCE(hm.master_hotel_id::text, ''), COALESCE(hm.source, '')
+	FROM hotels h
+	LEFT JOIN hotel_mappings hm ON hm.hotel_id = h.id
+	WHERE h.deleted_at IS NULL
+`
+
+func scanCandidate(row pgx.Row) (*Candidate, error) {
+	var c Candidate
+	err := row.Scan(
+		&c.HotelID, &c.ProviderCode, &c.ProviderHotelID, &c.Name, &c.Address,
+		&c.City, &c.CountryCode, &c.Phone,
+		&c.Latitude, &c.Longitude, &c.StarRating, &c.DescriptionLen,
+		&c.ImageCount,
+		&c.MasterHotelID, &c.Source,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *repository) queryCandidates(ctx context.Context, query string, args ...interface{}) ([]*Candidate, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mapping candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []*Candidate
+	for rows.Next() {
+		c, err := scanCandidate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan mapping candidate: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
+
+// ListCandidates retrieves all active provider hotels
+func (r *repository) ListCandidates(ctx context.Context) ([]*Candidate, error) {
+	return r.queryCandidates(ctx, candidateQuery+` ORDER BY h.country_code, LOWER(h.city), h.id`)
+}
+
+// GetCandidate retrieves one active provider hotel
+func (r *repository) GetCandidate(ctx context.Context, hotelID string) (*Candidate, error) {
+	c, err := scanCandidate(r.db.Pool.QueryRow(ctx, candidateQuery+` AND h.id = $1`, hotelID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrHotelNotFound
+		}
+		return nil, fmt.Errorf("failed to get mapping candidate: %w", err)
+	}
+	return c, nil
+}
+
+// ListCandidatesByMaster retrieves the provider hotels mapped to a master hotel
+func (r *repository) ListCandidatesByMaster(ctx context.Context, masterID string) ([]*Candidate, error) {
+	return r.queryCandidates(ctx, candidateQuery+` AND hm.master_hotel_id = $1 ORDER BY h.id`, masterID)
+}
+
+// SaveClusters stores master hotels and their mappings
+func (r *repository) SaveClusters(ctx context.Context, masters []*MasterHotel, mappings []*Mapping) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, m := range masters {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO master_hotels (
+				id, name, city, country_code, address, phone, latitude, longitude, star_rating,
+				content_hotel_id, created_at, updated_at
+			) VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, NULLIF($10, '')::uuid, $11, $12)
+			ON CONFLICT (id) DO UPDATE SET
+				name = EXCLUDED.name,
+				city = EXCLUDED.city,
+				country_code = EXCLUDED.country_code,
+				address = EXCLUDED.address,
+				phone = EXCLUDED.phone,
+				latitude = EXCLUDED.latitude,
+				longitude = EXCLUDED.longitude,
+				star_rating = EXCLUDED.star_rating,
+				content_hotel_id = EXCLUDED.content_hotel_id,
+				updated_at = EXCLUDED.updated_at
+		`,
+			m.ID, m.Name, m.City, m.CountryCode, m.Address, m.Phone, m.Latitude, m.Longitude, m.StarRating,
+			m.ContentHotelID, m.CreatedAt, m.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save master hotel: %w", err)
+		}
+	}
+
+	for _, m := range mappings {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO hotel_mappings (hotel_id, master_hotel_id, match_score, source, updated_by, updated_at)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+			ON CONFLICT (hotel_id) DO UPDATE SET
+				master_hotel_id = EXCLUDED.master_hotel_id,
+				match_score = EXCLUDED.match_score,
+				source = EXCLUDED.source,
+				updated_by = EXCLUDED.updated_by,
+				updated_at = EXCLUDED.updated_at
+		`, m.HotelID, m.MasterHotelID, m.Score, m.Source, m.UpdatedBy, m.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save hotel mapping: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM master_hotels mh
+		WHERE NOT EXISTS (SELECT 1 FROM hotel_mappings hm WHERE hm.master_hotel_id = mh.id)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to delete empty master hotels: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit hotel mappings: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteStaleMappings removes mappings of deleted hotels
+func (r *repository) DeleteStaleMappings(ctx context.Context) (int, error) {
+	result, err := r.db.Pool.Exec(ctx, `
+		DELETE FROM hotel_mappings hm
+		USING hotels h
+		WHERE h.id = hm.hotel_id AND h.deleted_at IS NOT NULL
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale hotel mappings: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+const masterColumns = `
+	mh.id, mh.name, COALESCE(mh.city, ''), COALESCE(mh.country_code, ''), COALESCE(mh.address, ''),
+	COALESCE(mh.phone, ''), mh.latitude, mh.longitude, COALESCE(mh.star_rating, 0),
+	COALESCE(mh.content_hotel_id::text, ''), mh.created_at, mh.updated_at,
+	(SELECT COUNT(*) FROM hotel_mappings hm WHERE hm.master_hotel_id = mh.id)
+`
+
+func scanMaster(row pgx.Row) (*MasterHotel, error) {
+	var m MasterHotel
+	err := row.Scan(
+		&m.ID, &m.Name, &m.City, &m.CountryCode, &m.Address,
+		&m.Phone, &m.Latitude, &m.Longitude, &m.StarRating,
+		&m.ContentHotelID, &m.CreatedAt, &m.UpdatedAt,
+		&m.HotelCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetMaster retrieves a master hotel with the provider hotels mapped to it
+func (r *repository) GetMaster(ctx context.Context, id string) (*MasterHotel, error) {
+	m, err := scanMaster(r.db.Pool.QueryRow(ctx, `SELECT `+masterColumns+` FROM master_hotels mh WHERE mh.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMasterNotFound
+		}
+		return nil, fmt.Errorf("failed to get master hotel: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT hm.hotel_id, hm.master_hotel_id, h.provider_code, h.provider_hotel_id, h.name,
+			hm.match_score, hm.source, COALESCE(hm.updated_by, ''), hm.updated_at
+		FROM hotel_mappings hm
+		JOIN hotels h ON h.id = hm.hotel_id
+		WHERE hm.master_hotel_id = $1
+		ORDER BY h.provider_code, h.provider_hotel_id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hotel mappings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mapping Mapping
+		err := rows.Scan(
+			&mapping.HotelID, &mapping.MasterHotelID, &mapping.ProviderCode, &mapping.ProviderHotelID, &mapping.HotelName,
+			&mapping.Score, &mapping.Source, &mapping.UpdatedBy, &mapping.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan hotel mapping: %w", err)
+		}
+		m.Hotels = append(m.Hotels, &mapping)
+	}
+
+	return m, rows.Err()
+}
+
+// ListMasters retrieves master hotels matching the filter
+func (r *repository) ListMasters(ctx context.Context, filter ListFilter, limit, offset int) ([]*MasterHotel, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.City != "" {
+		add("mh.city ILIKE $%d", filter.City)
+	}
+	if filter.CountryCode != "" {
+		add("mh.country_code = $%d", strings.ToUpper(filter.CountryCode))
+	}
+	if filter.MergedOnly {
+		conditions = append(conditions, "(SELECT COUNT(*) FROM hotel_mappings hm WHERE hm.master_hotel_id = mh.id) > 1")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM master_hotels mh`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count master hotels: %w", err)
+	}
+
+	args = append(args, limit, offset)
+	query := `SELECT ` + masterColumns + ` FROM master_hotels mh` + where +
+		fmt.Sprintf(" ORDER BY mh.country_code, mh.city, mh.name LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list master hotels: %w", err)
+	}
+	defer rows.Close()
+
+	var masters []*MasterHotel
+	for rows.Next() {
+		m, err := scanMaster(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan master hotel: %w", err)
+		}
+		masters = append(masters, m)
+	}
+
+	return masters, total, rows.Err()
+}
+
+// FindMasterIDs looks up the master hotels of provider hotels
+func (r *repository) FindMasterIDs(ctx context.Context, refs []ProviderRef) (map[ProviderRef]string, error) {
+	found := make(map[ProviderRef]string, len(refs))
+	if len(refs) == 0 {
+		return found, nil
+	}
+
+	codes := make([]string, len(refs))
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		codes[i] = ref.ProviderCode
+		ids[i] = ref.ProviderHotelID
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT h.provider_code, h.provider_hotel_id, hm.master_hotel_id::text
+		FROM hotels h
+		JOIN hotel_mappings hm ON hm.hotel_id = h.id
+		JOIN UNNEST($1::text[], $2::text[]) AS ref(provider_code, provider_hotel_id)
+			ON ref.provider_code = h.provider_code AND ref.provider_hotel_id = h.provider_hotel_id
+	`, codes, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find master hotels: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ref ProviderRef
+		var masterID string
+		if err := rows.Scan(&ref.ProviderCode, &ref.ProviderHotelID, &masterID); err != nil {
+			return nil, fmt.Errorf("failed to scan master hotel: %w", err)
+		}
+		found[ref] = masterID
+	}
+
+	return found, rows.Err()
+}
@@ -0,0 +1,321 @@
+package hotelmapping
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Service defines interface for hotel mapping business logic
+type Service interface {
+	// Rebuild re-matches all provider hotels into master hotels, keeping manual mappings
+	Rebuild(ctx context.Context) (*RebuildResult, error)
+	GetMaster(ctx context.Context, id string) (*MasterHotel, error)
+	ListMasters(ctx context.Context, filter ListFilter, limit, offset int) ([]*MasterHotel, int, error)
+	// AssignHotel maps a provider hotel to a master hotel and pins the master's
+	// hotels, so rebuilds don't split them up again
+	AssignHotel(ctx context.Context, hotelID, masterID, adminID string) (*MasterHotel, error)
+	// DetachHotel gives a provider hotel a master hotel of its own that rebuilds don't merge
+	DetachHotel(ctx context.Context, hotelID, adminID string) (*MasterHotel, error)
+	// Deduplicate collapses offers for the same master hotel into the cheapest one
+	Deduplicate(ctx context.Context, hotels []types.HotelAvailability) ([]types.HotelAvailability, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService creates a new hotel mapping service
+func NewService(repo Repository) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+// Rebuild clusters the provider hotels of each city into master hotels. Each
+// city is saved in its own transaction.
+func (s *service) Rebuild(ctx context.Context) (*RebuildResult, error) {
+	start := time.Now()
+
+	if removed, err := s.repo.DeleteStaleMappings(ctx); err != nil {
+		return nil, err
+	} else if removed > 0 {
+		logger.Infof("Removed %d mappings of deleted hotels", removed)
+	}
+
+	candidates, err := s.repo.ListCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	areas := make(map[string][]*Candidate)
+	var order []string
+	for _, c := range candidates {
+		key := areaKey(c)
+		if _, exists := areas[key]; !exists {
+			order = append(order, key)
+		}
+		areas[key] = append(areas[key], c)
+	}
+
+	result := &RebuildResult{Hotels: len(candidates)}
+	for _, key := range order {
+		clusters := clusterCandidates(areas[key])
+
+		masters := make([]*MasterHotel, 0, len(clusters))
+		var mappings []*Mapping
+		now := time.Now()
+		for _, cl := range clusters {
+			master := newMaster(cl.masterID, cl.members)
+			masters = append(masters, master)
+
+			if len(cl.members) > 1 {
+				result.Merged += len(cl.members)
+			}
+			for _, m := range cl.members {
+				// Manual mappings keep who made them
+				if m.Source == SourceManual && m.MasterHotelID == master.ID {
+					result.Manual++
+					continue
+				}
+				mappings = append(mappings, &Mapping{
+					HotelID:       m.HotelID,
+					MasterHotelID: master.ID,
+					Score:         cl.scores[m.HotelID],
+					Source:        SourceAuto,
+					UpdatedAt:     now,
+				})
+			}
+		}
+
+		if err := s.repo.SaveClusters(ctx, masters, mappings); err != nil {
+			logger.ErrorWithErr(err, fmt.Sprintf("Failed to save hotel mappings for %s", key))
+			return nil, err
+		}
+		result.Masters += len(masters)
+	}
+
+	result.Duration = time.Since(start)
+	logger.Infof("🏨 Hotel mapping rebuilt: %d hotels into %d master hotels (%d merged, %d manual) in %v",
+		result.Hotels, result.Masters, result.Merged, result.Manual, result.Duration)
+
+	return result, nil
+}
+
+// areaKey groups hotels that can be compared: the same hotel is never listed in two cities
+func areaKey(c *Candidate) string {
+	return strings.ToUpper(c.CountryCode) + "|" + strings.ToLower(strings.TrimSpace(c.City))
+}
+
+// GetMaster returns a master hotel with its provider hotels
+func (s *service) GetMaster(ctx context.Context, id string) (*MasterHotel, error) {
+	return s.repo.GetMaster(ctx, id)
+}
+
+// ListMasters lists master hotels
+func (s *service) ListMasters(ctx context.Context, filter ListFilter, limit, offset int) ([]*MasterHotel, int, error) {
+	return s.repo.ListMasters(ctx, filter, limit, offset)
+}
+
+// AssignHotel moves a provider hotel to another master hotel
+func (s *service) AssignHotel(ctx context.Context, hotelID, masterID, adminID string) (*MasterHotel, error) {
+	hotel, err := s.repo.GetCandidate(ctx, hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListCandidatesByMaster(ctx, masterID)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, ErrMasterNotFound
+	}
+
+	target := []*Candidate{hotel}
+	for _, m := range members {
+		if m.HotelID != hotel.HotelID {
+			target = append(target, m)
+		}
+	}
+
+	now := time.Now()
+	masters := []*MasterHotel{newMaster(masterID, target)}
+	mappings := make([]*Mapping, 0, len(target))
+	for _, m := range target {
+		mappings = append(mappings, &Mapping{
+			HotelID:       m.HotelID,
+			MasterHotelID: masterID,
+			Score:         1,
+			Source:        SourceManual,
+			UpdatedBy:     adminID,
+			UpdatedAt:     now,
+		})
+	}
+
+	previous, err := s.remainingMaster(ctx, hotel, masterID)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		masters = append(masters, previous)
+	}
+
+	if err := s.repo.SaveClusters(ctx, masters, mappings); err != nil {
+		logger.ErrorWithErr(err, "Failed to assign hotel mapping")
+		return nil, err
+	}
+
+	logger.Infof("🏨 Hotel %s mapped to master hotel %s by %s", hotelID, masterID, adminID)
+	return s.repo.GetMaster(ctx, masterID)
+}
+
+// DetachHotel splits a provider hotel off its master hotel
+func (s *service) DetachHotel(ctx context.Context, hotelID, adminID string) (*MasterHotel, error) {
+	hotel, err := s.repo.GetCandidate(ctx, hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	master := newMaster("", []*Candidate{hotel})
+	masters := []*MasterHotel{master}
+
+	previous, err := s.remainingMaster(ctx, hotel, master.ID)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		masters = append(masters, previous)
+	}
+
+	mapping := &Mapping{
+		HotelID:       hotel.HotelID,
+		MasterHotelID: master.ID,
+		Score:         1,
+		Source:        SourceManual,
+		UpdatedBy:     adminID,
+		UpdatedAt:     time.Now(),
+	}
+	if err := s.repo.SaveClusters(ctx, masters, []*Mapping{mapping}); err != nil {
+		logger.ErrorWithErr(err, "Failed to detach hotel mapping")
+		return nil, err
+	}
+
+	logger.Infof("🏨 Hotel %s detached into master hotel %s by %s", hotelID, master.ID, adminID)
+	return s.repo.GetMaster(ctx, master.ID)
+}
+
+// remainingMaster rebuilds the master hotel a hotel is leaving from the hotels
+// that stay, since its content may have come from the one leaving. It returns
+// nil when the hotel isn't leaving a master or no hotel stays.
+func (s *service) remainingMaster(ctx context.Context, hotel *Candidate, newMasterID string) (*MasterHotel, error) {
+	if hotel.MasterHotelID == "" || hotel.MasterHotelID == newMasterID {
+		return nil, nil
+	}
+
+	members, err := s.repo.ListCandidatesByMaster(ctx, hotel.MasterHotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining []*Candidate
+	for _, m := range members {
+		if m.HotelID != hotel.HotelID {
+			remaining = append(remaining, m)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil, nil
+	}
+
+	return newMaster(hotel.MasterHotelID, remaining), nil
+}
+
+// Deduplicate keeps one offer per master hotel and currency, the cheapest, in
+// the order the hotels first appear. Prices in different currencies can't be
+// compared, so such offers are all kept. Hotels that aren't mapped yet are
+// kept as they are.
+func (s *service) Deduplicate(ctx context.Context, hotels []types.HotelAvailability) ([]types.HotelAvailability, error) {
+	refs := make([]ProviderRef, 0, len(hotels))
+	for _, h := range hotels {
+		if h.Provider != "" && h.Hotel.ID != "" {
+			refs = append(refs, ProviderRef{ProviderCode: h.Provider, ProviderHotelID: h.Hotel.ID})
+		}
+	}
+
+	masterIDs, err := s.repo.FindMasterIDs(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	deduplicated := make([]types.HotelAvailability, 0, len(hotels))
+	index := make(map[offerKey]int, len(hotels))
+	for _, h := range hotels {
+		key := offerKey{hotel: h.Provider + ":" + h.Hotel.ID, currency: offerCurrency(h)}
+		if masterID, ok := masterIDs[ProviderRef{ProviderCode: h.Provider, ProviderHotelID: h.Hotel.ID}]; ok {
+			h.MasterHotelID = masterID
+			key.hotel = masterID
+		}
+
+		i, seen := index[key]
+		if !seen {
+			index[key] = len(deduplicated)
+			deduplicated = append(deduplicated, h)
+			continue
+		}
+		if cheaper(h, deduplicated[i]) {
+			deduplicated[i] = h
+		}
+	}
+
+	if removed := len(hotels) - len(deduplicated); removed > 0 {
+		logger.Infof("Collapsed %d duplicate hotel offers", removed)
+	}
+
+	return deduplicated, nil
+}
+
+// offerKey identifies the offers of a hotel that are compared by price
+type offerKey struct {
+	hotel    string
+	currency string
+}
+
+// offerCurrency returns the currency of a hotel's lowest rate, empty when it
+// has no rates
+func offerCurrency(h types.HotelAvailability) string {
+	rate, ok := lowestRate(h)
+	if !ok {
+		return ""
+	}
+	return rate.Currency
+}
+
+// cheaper reports whether offer a has a lower price than b, both priced in
+// the same currency. An offer with no rates is never cheaper.
+func cheaper(a, b types.HotelAvailability) bool {
+	rateA, okA := lowestRate(a)
+	rateB, okB := lowestRate(b)
+	if !okA {
+		return false
+	}
+	return !okB || rateA.NetPrice < rateB.NetPrice
+}
+
+// lowestRate returns the rate with the lowest net price among a hotel's rates
+func lowestRate(h types.HotelAvailability) (types.Rate, bool) {
+	var lowest types.Rate
+	found := false
+	for _, room := range h.Rooms {
+		for _, rate := range room.Rates {
+			if rate.NetPrice > 0 && (!found || rate.NetPrice < lowest.NetPrice) {
+				lowest, found = rate, true
+			}
+		}
+	}
+	return lowest, found
+}
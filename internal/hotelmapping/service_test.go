@@ -0,0 +1,232 @@
+package hotelmapping
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRepository is a mock implementation of hotelmapping.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) ListCandidates(ctx context.Context) ([]*Candidate, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Candidate), args.Error(1)
+}
+
+func (m *MockRepository) GetCandidate(ctx context.Context, hotelID string) (*Candidate, error) {
+	args := m.Called(ctx, hotelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Candidate), args.Error(1)
+}
+
+func (m *MockRepository) ListCandidatesByMaster(ctx context.Context, masterID string) ([]*Candidate, error) {
+	args := m.Called(ctx, masterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Candidate), args.Error(1)
+}
+
+func (m *MockRepository) SaveClusters(ctx context.Context, masters []*MasterHotel, mappings []*Mapping) error {
+	args := m.Called(ctx, masters, mappings)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteStaleMappings(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) GetMaster(ctx context.Context, id string) (*MasterHotel, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*MasterHotel), args.Error(1)
+}
+
+func (m *MockRepository) ListMasters(ctx context.Context, filter ListFilter, limit, offset int) ([]*MasterHotel, int, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*MasterHotel), args.Int(1), args.Error(2)
+}
+
+func (m *MockRepository) FindMasterIDs(ctx context.Context, refs []ProviderRef) (map[ProviderRef]string, error) {
+	args := m.Called(ctx, refs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[ProviderRef]string), args.Error(1)
+}
+
+func offer(provider, hotelID string, prices ...int) types.HotelAvailability {
+	rates := make([]types.Rate, 0, len(prices))
+	for _, p := range prices {
+		rates = append(rates, types.Rate{NetPrice: p, Currency: "IDR"})
+	}
+	return types.HotelAvailability{
+		Hotel:    types.Hotel{ID: hotelID},
+		Rooms:    []types.RoomRate{{Rates: rates}},
+		Provider: provider,
+	}
+}
+
+func TestService_Deduplicate(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+
+	hotels := []types.HotelAvailability{
+		offer("hotelbeds", "HB-1", 1500000, 1800000),
+		offer("hotelbeds", "HB-2", 900000),
+		offer("hotelplanner", "HP-9", 1400000),
+		offer("hotelplanner", "HP-7", 700000),
+	}
+
+	mockRepo.On("FindMasterIDs", ctx, mock.Anything).Return(map[ProviderRef]string{
+		{ProviderCode: "hotelbeds", ProviderHotelID: "HB-1"}:    "master-1",
+		{ProviderCode: "hotelplanner", ProviderHotelID: "HP-9"}: "master-1",
+		{ProviderCode: "hotelbeds", ProviderHotelID: "HB-2"}:    "master-2",
+	}, nil)
+
+	result, err := svc.Deduplicate(ctx, hotels)
+
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	assert.Equal(t, "HP-9", result[0].Hotel.ID, "cheapest offer for master-1 takes its place")
+	assert.Equal(t, "master-1", result[0].MasterHotelID)
+	assert.Equal(t, "HB-2", result[1].Hotel.ID)
+	assert.Equal(t, "HP-7", result[2].Hotel.ID, "unmapped hotels are kept")
+	assert.Empty(t, result[2].MasterHotelID)
+}
+
+// TestService_Deduplicate_KeepsOtherCurrencies tests that offers of a master
+// hotel priced in different currencies are not compared
+func TestService_Deduplicate_KeepsOtherCurrencies(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+
+	usd := offer("hotelplanner", "HP-9", 95)
+	usd.Rooms[0].Rates[0].Currency = "USD"
+	hotels := []types.HotelAvailability{
+		offer("hotelbeds", "HB-1", 1500000),
+		usd,
+		offer("hotelbeds", "HB-3", 1400000),
+	}
+
+	mockRepo.On("FindMasterIDs", ctx, mock.Anything).Return(map[ProviderRef]string{
+		{ProviderCode: "hotelbeds", ProviderHotelID: "HB-1"}:    "master-1",
+		{ProviderCode: "hotelplanner", ProviderHotelID: "HP-9"}: "master-1",
+		{ProviderCode: "hotelbeds", ProviderHotelID: "HB-3"}:    "master-1",
+	}, nil)
+
+	result, err := svc.Deduplicate(ctx, hotels)
+
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "HB-3", result[0].Hotel.ID, "cheapest IDR offer")
+	assert.Equal(t, "HP-9", result[1].Hotel.ID, "the USD offer is kept")
+}
+
+func TestCheaper(t *testing.T) {
+	assert.True(t, cheaper(offer("a", "1", 100), offer("b", "2", 200)))
+	assert.False(t, cheaper(offer("a", "1", 200), offer("b", "2", 100)))
+	assert.True(t, cheaper(offer("a", "1", 200), offer("b", "2")), "an offer without rates loses")
+	assert.False(t, cheaper(offer("a", "1"), offer("b", "2", 100)))
+}
+
+func TestService_Rebuild(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+
+	hb := candidate("a-hb", "hotelbeds", "Grand Hyatt Bali", -8.8003, 115.2323)
+	hp := candidate("b-hp", "hotelplanner", "Grand Hyatt Bali", -8.8004, 115.2324)
+	jakarta := candidate("c-hb", "hotelbeds", "Grand Hyatt Jakarta", -6.1951, 106.8230)
+	jakarta.City = "Jakarta"
+	jakarta.MasterHotelID, jakarta.Source = "master-jkt", SourceManual
+
+	mockRepo.On("DeleteStaleMappings", ctx).Return(0, nil)
+	mockRepo.On("ListCandidates", ctx).Return([]*Candidate{hb, hp, jakarta}, nil)
+	mockRepo.On("SaveClusters", ctx, mock.MatchedBy(func(masters []*MasterHotel) bool {
+		return len(masters) == 1 && masters[0].HotelCount == 2
+	}), mock.MatchedBy(func(mappings []*Mapping) bool {
+		return len(mappings) == 2 && mappings[0].MasterHotelID == mappings[1].MasterHotelID
+	})).Return(nil).Once()
+	mockRepo.On("SaveClusters", ctx, mock.MatchedBy(func(masters []*MasterHotel) bool {
+		return len(masters) == 1 && masters[0].ID == "master-jkt"
+	}), []*Mapping(nil)).Return(nil).Once()
+
+	result, err := svc.Rebuild(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Hotels)
+	assert.Equal(t, 2, result.Masters)
+	assert.Equal(t, 2, result.Merged)
+	assert.Equal(t, 1, result.Manual)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_AssignHotel(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+
+	moving := candidate("a-hb", "hotelbeds", "Paradiso Kuta", 0, 0)
+	moving.MasterHotelID, moving.Source = "master-old", SourceAuto
+	left := candidate("c-hp", "hotelplanner", "Paradiso Kuta", 0, 0)
+	left.MasterHotelID = "master-old"
+	target := candidate("b-hp", "expedia", "Kuta Paradiso Hotel", 0, 0)
+	target.MasterHotelID = "master-new"
+
+	mockRepo.On("GetCandidate", ctx, "a-hb").Return(moving, nil)
+	mockRepo.On("ListCandidatesByMaster", ctx, "master-new").Return([]*Candidate{target}, nil)
+	mockRepo.On("ListCandidatesByMaster", ctx, "master-old").Return([]*Candidate{moving, left}, nil)
+	mockRepo.On("SaveClusters", ctx, mock.MatchedBy(func(masters []*MasterHotel) bool {
+		return len(masters) == 2 && masters[0].ID == "master-new" && masters[0].HotelCount == 2 &&
+			masters[1].ID == "master-old" && masters[1].ContentHotelID == "c-hp"
+	}), mock.MatchedBy(func(mappings []*Mapping) bool {
+		for _, m := range mappings {
+			if m.Source != SourceManual || m.UpdatedBy != "admin-1" || m.MasterHotelID != "master-new" {
+				return false
+			}
+		}
+		return len(mappings) == 2
+	})).Return(nil)
+	mockRepo.On("GetMaster", ctx, "master-new").Return(&MasterHotel{ID: "master-new", HotelCount: 2}, nil)
+
+	master, err := svc.AssignHotel(ctx, "a-hb", "master-new", "admin-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, master.HotelCount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_AssignHotel_MasterNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+
+	mockRepo.On("GetCandidate", ctx, "a-hb").Return(candidate("a-hb", "hotelbeds", "Paradiso Kuta", 0, 0), nil)
+	mockRepo.On("ListCandidatesByMaster", ctx, "missing").Return([]*Candidate{}, nil)
+
+	_, err := svc.AssignHotel(ctx, "a-hb", "missing", "admin-1")
+
+	assert.ErrorIs(t, err, ErrMasterNotFound)
+	mockRepo.AssertNotCalled(t, "SaveClusters", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,227 @@
+package hotelplanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Booking statuses returned by HotelPlanner
+const (
+	StatusConfirmed = "confirmed"
+	StatusPending   = "pending"
+	StatusCancelled = "cancelled"
+	StatusFailed    = "failed"
+)
+
+// Payment methods accepted when booking
+const (
+	PaymentPrepaid = "prepaid"  // We pay HotelPlanner
+	PaymentAtHotel = "at_hotel" // The guest pays the property
+)
+
+// Date is a calendar date, sent as YYYY-MM-DD
+type Date time.Time
+
+// MarshalJSON implements json.Marshaler
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(d).Format("2006-01-02"))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return err
+	}
+	*d = Date(t)
+	return nil
+}
+
+// SearchRequest searches a destination for available hotels
+type SearchRequest struct {
+	City        string `json:"city"`
+	CountryCode string `json:"country_code,omitempty"`
+	CheckIn     Date   `json:"check_in"`
+	CheckOut    Date   `json:"check_out"`
+	Adults      int    `json:"adults"`
+	Rooms       int    `json:"rooms"`
+}
+
+// SearchResponse lists the hotels with availability
+type SearchResponse struct {
+	Hotels []Hotel `json:"hotels"`
+}
+
+// AvailabilityRequest checks one hotel's rooms for a stay
+type AvailabilityRequest struct {
+	HotelID  string `json:"-"`
+	RoomID   string `json:"room_id,omitempty"` // Empty for all rooms
+	CheckIn  Date   `json:"check_in"`
+	CheckOut Date   `json:"check_out"`
+	Adults   int    `json:"adults"`
+}
+
+// Hotel is a HotelPlanner property. Rooms are only set by search and
+// availability calls.
+type Hotel struct {
+	HotelID     string   `json:"hotel_id"`
+	Name        string   `json:"name"`
+	CountryCode string   `json:"country_code"`
+	City        string   `json:"city"`
+	Address     string   `json:"address,omitempty"`
+	StarRating  float64  `json:"star_rating"`
+	Latitude    float64  `json:"latitude"`
+	Longitude   float64  `json:"longitude"`
+	Description string   `json:"description,omitempty"`
+	Amenities   []string `json:"amenities,omitempty"`
+	Images      []Image  `json:"images,omitempty"`
+	Rooms       []Room   `json:"rooms,omitempty"`
+}
+
+// Image is a hotel photo
+type Image struct {
+	URL     string `json:"url"`
+	Caption string `json:"caption,omitempty"`
+}
+
+// Room is a room type with its bookable rates
+type Room struct {
+	RoomID       string `json:"room_id"`
+	Name         string `json:"name"`
+	MaxOccupancy int    `json:"max_occupancy"`
+	BedType      string `json:"bed_type,omitempty"`
+	Rates        []Rate `json:"rates"`
+}
+
+// Rate is one price for a room and stay
+type Rate struct {
+	RateID                string     `json:"rate_id"`
+	NetAmount             float64    `json:"net_amount"` // Total for the stay
+	Currency              string     `json:"currency"`
+	MealPlan              string     `json:"meal_plan,omitempty"` // RO, BB, HB, FB, AI
+	Refundable            bool       `json:"refundable"`
+	FreeCancellationUntil *time.Time `json:"free_cancellation_until,omitempty"`
+	RoomsLeft             int        `json:"rooms_left"`
+}
+
+// BookingRequest books a room. ClientReference is our booking reference;
+// HotelPlanner rejects a second booking with the same one, which makes a
+// repeated request safe.
+type BookingRequest struct {
+	HotelID         string `json:"hotel_id"`
+	RoomID          string `json:"room_id"`
+	RateID          string `json:"rate_id,omitempty"`
+	CheckIn         Date   `json:"check_in"`
+	CheckOut        Date   `json:"check_out"`
+	Adults          int    `json:"adults"`
+	Guest           Guest  `json:"guest"`
+	PaymentMethod   string `json:"payment_method"`
+	ClientReference string `json:"client_reference,omitempty"`
+}
+
+// Guest is the lead guest of a booking
+type Guest struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone,omitempty"`
+}
+
+// Booking is a HotelPlanner booking
+type Booking struct {
+	BookingID          string `json:"booking_id"`
+	ConfirmationNumber string `json:"confirmation_number"`
+	Status             string `json:"status"`
+	HotelID            string `json:"hotel_id"`
+	RoomID             string `json:"room_id"`
+	CheckIn            Date   `json:"check_in"`
+	CheckOut           Date   `json:"check_out"`
+	Total              Amount `json:"total"`
+}
+
+// Amount is a price in a currency
+type Amount struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// Search searches a destination for available hotels
+func (c *Client) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	logger.Infof("Searching HotelPlanner: city=%s, checkIn=%s, checkOut=%s, adults=%d",
+		req.City, time.Time(req.CheckIn).Format("2006-01-02"), time.Time(req.CheckOut).Format("2006-01-02"), req.Adults)
+
+	var resp SearchResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/hotels/search", req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to search hotels: %w", err)
+	}
+	return &resp, nil
+}
+
+// GetHotel fetches a hotel's content
+func (c *Client) GetHotel(ctx context.Context, hotelID string) (*Hotel, error) {
+	var hotel Hotel
+	if err := c.do(ctx, http.MethodGet, "/v1/hotels/"+url.PathEscape(hotelID), nil, &hotel); err != nil {
+		return nil, fmt.Errorf("failed to get hotel: %w", err)
+	}
+	return &hotel, nil
+}
+
+// CheckAvailability returns a hotel with its available rooms for a stay
+func (c *Client) CheckAvailability(ctx context.Context, req *AvailabilityRequest) (*Hotel, error) {
+	endpoint := fmt.Sprintf("/v1/hotels/%s/availability", url.PathEscape(req.HotelID))
+
+	var hotel Hotel
+	if err := c.do(ctx, http.MethodPost, endpoint, req, &hotel); err != nil {
+		return nil, fmt.Errorf("failed to check availability: %w", err)
+	}
+	return &hotel, nil
+}
+
+// CreateBooking books a room
+func (c *Client) CreateBooking(ctx context.Context, req *BookingRequest) (*Booking, error) {
+	logger.Infof("Creating HotelPlanner booking: hotel=%s, room=%s, ref=%s", req.HotelID, req.RoomID, req.ClientReference)
+
+	var booking Booking
+	if err := c.do(ctx, http.MethodPost, "/v1/bookings", req, &booking); err != nil {
+		return nil, fmt.Errorf("failed to create booking: %w", err)
+	}
+	return &booking, nil
+}
+
+// CancelBooking cancels a booking
+func (c *Client) CancelBooking(ctx context.Context, bookingID string) (*Booking, error) {
+	logger.Infof("Cancelling HotelPlanner booking: %s", bookingID)
+
+	var booking Booking
+	if err := c.do(ctx, http.MethodDelete, "/v1/bookings/"+url.PathEscape(bookingID), nil, &booking); err != nil {
+		return nil, fmt.Errorf("failed to cancel booking: %w", err)
+	}
+	return &booking, nil
+}
+
+// GetBooking fetches a booking
+func (c *Client) GetBooking(ctx context.Context, bookingID string) (*Booking, error) {
+	var booking Booking
+	if err := c.do(ctx, http.MethodGet, "/v1/bookings/"+url.PathEscape(bookingID), nil, &booking); err != nil {
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+	return &booking, nil
+}
+
+// Ping checks that the API is reachable and the credentials are valid
+func (c *Client) Ping(ctx context.Context) error {
+	if err := c.do(ctx, http.MethodGet, "/v1/ping", nil, nil); err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package hotelplanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// tokenRefreshMargin renews an access token this long before it expires, so
+// a token never runs out in the middle of a request
+const tokenRefreshMargin = time.Minute
+
+// Client handles HotelPlanner API communication. It authenticates with the
+// OAuth client-credentials flow and caches the access token until it expires.
+type Client struct {
+	apiKey     string
+	apiSecret  string
+	baseURL    string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+	now         func() time.Time
+}
+
+// NewClient creates a new HotelPlanner client
+func NewClient(apiKey, apiSecret, baseURL string) *Client {
+	return &Client{
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		baseURL:   baseURL,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		now: time.Now,
+	}
+}
+
+// WrapTransport wraps the HTTP transport, e.g. to capture traffic
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
+// tokenResponse is the body of a successful token request
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"` // Seconds
+}
+
+// accessToken returns a cached token, requesting a new one when the cached
+// token is missing or about to expire
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && c.now().Add(tokenRefreshMargin).Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	body := map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     c.apiKey,
+		"client_secret": c.apiSecret,
+	}
+	resp, err := c.send(ctx, http.MethodPost, "/v1/auth/token", body, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("failed to authenticate: empty access token")
+	}
+
+	c.token = token.AccessToken
+	c.tokenExpiry = c.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	logger.Infof("HotelPlanner access token refreshed, expires in %ds", token.ExpiresIn)
+
+	return c.token, nil
+}
+
+// invalidateToken drops a token the API rejected, unless another request
+// has already replaced it
+func (c *Client) invalidateToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+// do performs an authenticated request and decodes the JSON response into
+// out (when not nil). A request rejected with 401 is retried once with a new
+// token, since tokens can be revoked before they expire.
+func (c *Client) do(ctx context.Context, method, endpoint string, body, out interface{}) error {
+	for attempt := 0; ; attempt++ {
+		token, err := c.accessToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.send(ctx, method, endpoint, body, token)
+		if err != nil {
+			var apiErr *APIError
+			if attempt == 0 && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+				c.invalidateToken(token)
+				continue
+			}
+			return err
+		}
+		defer resp.Body.Close()
+
+		if out == nil {
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		return nil
+	}
+}
+
+// send executes one request. Non-2xx responses are returned as *APIError.
+func (c *Client) send(ctx context.Context, method, endpoint string, body interface{}, token string) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	logger.Infof("HotelPlanner API Request: %s %s", method, endpoint)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, newAPIError(resp, bodyBytes)
+	}
+
+	return resp, nil
+}
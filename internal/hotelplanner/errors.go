@@ -0,0 +1,89 @@
+package hotelplanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APIError is a non-2xx response from the HotelPlanner API
+type APIError struct {
+	StatusCode int
+	Code       string // HotelPlanner error code, e.g. ROOM_SOLD_OUT
+	Message    string
+	RetryAfter time.Duration // From the Retry-After header, when sent
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("HotelPlanner API error: status %d, %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("HotelPlanner API error: status %d, body: %s", e.StatusCode, e.Message)
+}
+
+// Retryable reports whether the same request may succeed later: rate limits,
+// timeouts and server-side failures. Anything else is a problem with the
+// request itself and fails the same way every time.
+func (e *APIError) Retryable() bool {
+	switch {
+	case e.StatusCode == http.StatusRequestTimeout,
+		e.StatusCode == http.StatusTooManyRequests,
+		e.StatusCode >= 500:
+		return true
+	default:
+		return false
+	}
+}
+
+// newAPIError builds an APIError from a failed response. HotelPlanner sends
+// {"error": {"code": ..., "message": ...}}; other bodies are kept verbatim.
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+
+	var envelope struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error.Code != "" {
+		apiErr.Code = envelope.Error.Code
+		apiErr.Message = envelope.Error.Message
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return apiErr
+}
+
+// IsRetryable classifies an error from the client. API errors are retryable
+// when their status says so, and network failures and timeouts always are.
+// Cancellation by the caller and malformed requests or responses are
+// permanent.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
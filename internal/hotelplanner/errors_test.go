@@ -0,0 +1,54 @@
+package hotelplanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", &APIError{StatusCode: http.StatusBadGateway}, true},
+		{"request timeout", &APIError{StatusCode: http.StatusRequestTimeout}, true},
+		{"wrapped server error", fmt.Errorf("failed to search hotels: %w", &APIError{StatusCode: 500}), true},
+		{"not found", &APIError{StatusCode: http.StatusNotFound}, false},
+		{"bad request", &APIError{StatusCode: http.StatusBadRequest, Code: "INVALID_DATES"}, false},
+		{"unauthorized", &APIError{StatusCode: http.StatusUnauthorized}, false},
+		{"network failure", fmt.Errorf("failed to execute request: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), true},
+		{"deadline", fmt.Errorf("failed to execute request: %w", context.DeadlineExceeded), true},
+		{"cancelled by caller", fmt.Errorf("failed to execute request: %w", context.Canceled), false},
+		{"malformed response", errors.New("failed to parse response: unexpected EOF"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestNewAPIError(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "7")
+
+	err := newAPIError(resp, []byte(`{"error": {"code": "RATE_LIMITED", "message": "Too many requests"}}`))
+	assert.Equal(t, "RATE_LIMITED", err.Code)
+	assert.Equal(t, "Too many requests", err.Message)
+	assert.Equal(t, 7*time.Second, err.RetryAfter)
+
+	err = newAPIError(&http.Response{StatusCode: http.StatusBadGateway}, []byte("<html>Bad Gateway</html>"))
+	assert.Empty(t, err.Code)
+	assert.Equal(t, "<html>Bad Gateway</html>", err.Message)
+}
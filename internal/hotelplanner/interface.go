@@ -0,0 +1,18 @@
+package hotelplanner
+
+import "context"
+
+// ClientInterface defines the interface for HotelPlanner API client
+// This allows mocking for tests
+type ClientInterface interface {
+	Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error)
+	GetHotel(ctx context.Context, hotelID string) (*Hotel, error)
+	CheckAvailability(ctx context.Context, req *AvailabilityRequest) (*Hotel, error)
+	CreateBooking(ctx context.Context, req *BookingRequest) (*Booking, error)
+	CancelBooking(ctx context.Context, bookingID string) (*Booking, error)
+	GetBooking(ctx context.Context, bookingID string) (*Booking, error)
+	Ping(ctx context.Context) error
+}
+
+// Ensure Client implements the interface
+var _ ClientInterface = (*Client)(nil)
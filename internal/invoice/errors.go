@@ -0,0 +1,11 @@
+package invoice
+
+import "errors"
+
+// Package-level errors for invoice operations
+var (
+	ErrInvoiceNotFound    = errors.New("invoice not found")
+	ErrInvoiceExists      = errors.New("invoice already issued for payment")
+	ErrInvalidAmount      = errors.New("invoice amount must be positive")
+	ErrCreditExceedsTotal = errors.New("credit notes cannot exceed the invoice total")
+)
@@ -0,0 +1,51 @@
+package invoice
+
+import (
+	"context"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// NewPaymentSuccessHandler issues an invoice for payment.success events
+func NewPaymentSuccessHandler(svc Service) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		bookingID, _ := event.Payload["booking_id"].(string)
+		paymentID, _ := event.Payload["payment_id"].(string)
+		amount, _ := event.Payload["amount"].(int)
+		currency, _ := event.Payload["currency"].(string)
+		method, _ := event.Payload["method"].(string)
+
+		if _, err := svc.IssueInvoice(ctx, &PaymentInfo{
+			BookingID: bookingID,
+			PaymentID: paymentID,
+			Amount:    amount,
+			Currency:  currency,
+			Method:    method,
+		}); err != nil {
+			// Don't block the payment flow - the invoice can be reissued from admin
+			logger.ErrorWithErr(err, "Failed to issue invoice")
+		}
+		return nil
+	}
+}
+
+// NewPaymentRefundedHandler issues a credit note for payment.refunded events
+func NewPaymentRefundedHandler(svc Service) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		bookingID, _ := event.Payload["booking_id"].(string)
+		paymentID, _ := event.Payload["payment_id"].(string)
+		amount, _ := event.Payload["amount"].(int)
+		currency, _ := event.Payload["currency"].(string)
+
+		if _, err := svc.IssueCreditNote(ctx, &RefundInfo{
+			BookingID: bookingID,
+			PaymentID: paymentID,
+			Amount:    amount,
+			Currency:  currency,
+		}); err != nil {
+			logger.ErrorWithErr(err, "Failed to issue credit note")
+		}
+		return nil
+	}
+}
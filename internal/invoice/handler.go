@@ -0,0 +1,154 @@
+package invoice
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/middleware"
+)
+
+// Handler handles HTTP requests for invoices
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new invoice handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetBookingInvoice handles GET /bookings/{id}/invoice
+// Returns a JSON receipt, or a PDF with ?format=pdf or Accept: application/pdf.
+func (h *Handler) GetBookingInvoice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User ID not found in request")
+		return
+	}
+
+	inv, err := h.service.GetBookingInvoice(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, ErrInvoiceNotFound) {
+			respondWithError(w, http.StatusNotFound, "Invoice not found")
+			return
+		}
+		logger.ErrorWithErr(err, "Failed to get booking invoice")
+		respondWithError(w, http.StatusInternalServerError, "Failed to get invoice")
+		return
+	}
+
+	respondWithInvoice(w, r, inv)
+}
+
+// GetInvoice handles GET /admin/invoices/{id}
+func (h *Handler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	inv, err := h.service.GetInvoice(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, ErrInvoiceNotFound) {
+			respondWithError(w, http.StatusNotFound, "Invoice not found")
+			return
+		}
+		logger.ErrorWithErr(err, "Failed to get invoice")
+		respondWithError(w, http.StatusInternalServerError, "Failed to get invoice")
+		return
+	}
+
+	respondWithInvoice(w, r, inv)
+}
+
+// SearchInvoices handles GET /admin/invoices
+func (h *Handler) SearchInvoices(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 || l > 100 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+		limit = l
+	}
+
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		o, err := strconv.Atoi(offsetStr)
+		if err != nil || o < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset parameter")
+			return
+		}
+		offset = o
+	}
+
+	filter := SearchFilter{
+		Number:           query.Get("number"),
+		BookingReference: query.Get("booking_reference"),
+		UserID:           query.Get("user_id"),
+		TaxID:            query.Get("tax_id"),
+		CompanyName:      query.Get("company"),
+		Type:             DocumentType(strings.ToUpper(query.Get("type"))),
+	}
+	if startDateStr := query.Get("start_date"); startDateStr != "" {
+		if t, err := time.ParseInLocation("2006-01-02", startDateStr, Location); err == nil {
+			filter.From = t
+		}
+	}
+	if endDateStr := query.Get("end_date"); endDateStr != "" {
+		if t, err := time.ParseInLocation("2006-01-02", endDateStr, Location); err == nil {
+			filter.To = t.AddDate(0, 0, 1)
+		}
+	}
+
+	invoices, total, err := h.service.SearchInvoices(r.Context(), filter, limit, offset)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to search invoices")
+		respondWithError(w, http.StatusInternalServerError, "Failed to search invoices")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"invoices": invoices,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// respondWithInvoice writes an invoice as JSON or PDF depending on the request
+func respondWithInvoice(w http.ResponseWriter, r *http.Request, inv *Invoice) {
+	if r.URL.Query().Get("format") != "pdf" && !strings.Contains(r.Header.Get("Accept"), "application/pdf") {
+		respondWithJSON(w, http.StatusOK, inv)
+		return
+	}
+
+	pdf, err := RenderPDF(inv)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to render invoice PDF")
+		respondWithError(w, http.StatusInternalServerError, "Failed to render invoice")
+		return
+	}
+
+	filename := strings.ReplaceAll(inv.Number, "/", "-")
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pdf", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdf)
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
+
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	respondWithJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,122 @@
+package invoice
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DocumentType distinguishes invoices from credit notes
+type DocumentType string
+
+const (
+	TypeInvoice    DocumentType = "INVOICE"
+	TypeCreditNote DocumentType = "CREDIT_NOTE"
+)
+
+// seriesPrefix is the number prefix of each document series. Each series has
+// its own gap-free sequence per year.
+var seriesPrefix = map[DocumentType]string{
+	TypeInvoice:    "INV",
+	TypeCreditNote: "CN",
+}
+
+// Location is the timezone invoice dates and numbering years use (WIB)
+var Location = time.FixedZone("WIB", 7*60*60)
+
+// FormatNumber formats a document number, e.g. INV/2026/000042
+func FormatNumber(docType DocumentType, year, sequence int) string {
+	return fmt.Sprintf("%s/%d/%06d", seriesPrefix[docType], year, sequence)
+}
+
+// Party is the seller or buyer printed on an invoice
+type Party struct {
+	Name    string `json:"name"`
+	TaxID   string `json:"tax_id,omitempty"`
+	Address string `json:"address,omitempty"`
+	Email   string `json:"email,omitempty"`
+}
+
+// Line is one line of the price breakdown
+type Line struct {
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitPrice   int    `json:"unit_price"`
+	Amount      int    `json:"amount"`
+}
+
+// Invoice is an issued invoice or credit note. Once issued it is never changed;
+// corrections are made with credit notes.
+type Invoice struct {
+	ID               string       `json:"id" db:"id"`
+	Number           string       `json:"number" db:"number"`
+	Type             DocumentType `json:"type" db:"document_type"`
+	Year             int          `json:"year" db:"year"`
+	Sequence         int          `json:"sequence" db:"sequence"`
+	BookingID        string       `json:"booking_id" db:"booking_id"`
+	BookingReference string       `json:"booking_reference" db:"booking_reference"`
+	UserID           string       `json:"user_id" db:"user_id"`
+	PaymentID        string       `json:"payment_id" db:"payment_id"`
+	PaymentMethod    string       `json:"payment_method,omitempty" db:"payment_method"`
+	OriginalID       string       `json:"original_invoice_id,omitempty" db:"original_invoice_id"`
+	OriginalNumber   string       `json:"original_invoice_number,omitempty" db:"original_invoice_number"`
+	Seller           Party        `json:"seller" db:"seller"`
+	Buyer            Party        `json:"buyer" db:"buyer"`
+	Lines            []Line       `json:"lines" db:"lines"`
+	Currency         string       `json:"currency" db:"currency"`
+	Subtotal         int          `json:"subtotal" db:"subtotal"`
+	TaxName          string       `json:"tax_name" db:"tax_name"`
+	TaxRate          float64      `json:"tax_rate" db:"tax_rate"`
+	TaxAmount        int          `json:"tax_amount" db:"tax_amount"`
+	Total            int          `json:"total" db:"total"`
+	IssuedAt         time.Time    `json:"issued_at" db:"issued_at"`
+}
+
+// Issuer is the company issuing invoices
+type Issuer struct {
+	Party
+	TaxName string  // e.g. PPN
+	TaxRate float64 // Percent; prices are tax-inclusive
+}
+
+// newDocument creates an unnumbered document; the repository assigns the
+// number when it is stored
+func newDocument(docType DocumentType, issuer Issuer, buyer Party, currency string, total int) *Invoice {
+	subtotal, tax := SplitTax(total, issuer.TaxRate)
+	return &Invoice{
+		ID:        uuid.New().String(),
+		Type:      docType,
+		Seller:    issuer.Party,
+		Buyer:     buyer,
+		Currency:  currency,
+		Subtotal:  subtotal,
+		TaxName:   issuer.TaxName,
+		TaxRate:   issuer.TaxRate,
+		TaxAmount: tax,
+		Total:     total,
+		IssuedAt:  time.Now().In(Location),
+	}
+}
+
+// SplitTax splits a tax-inclusive total into the amount before tax and the tax
+func SplitTax(total int, ratePercent float64) (subtotal, tax int) {
+	if ratePercent <= 0 {
+		return total, 0
+	}
+	subtotal = int(math.Round(float64(total) / (1 + ratePercent/100)))
+	return subtotal, total - subtotal
+}
+
+// SearchFilter filters invoices in admin search
+type SearchFilter struct {
+	Number           string // Prefix match
+	BookingReference string
+	UserID           string
+	TaxID            string
+	CompanyName      string // Case-insensitive substring
+	Type             DocumentType
+	From             time.Time
+	To               time.Time
+}
@@ -0,0 +1,208 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Page layout in PDF points (A4)
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	marginLeft   = 50
+	marginTop    = 790
+	lineHeight   = 14
+	amountColumn = 545 // Right edge of the amount column
+)
+
+// RenderPDF renders an invoice or credit note as a single-page PDF. It only
+// uses the built-in Helvetica fonts so no font files or libraries are needed.
+func RenderPDF(inv *Invoice) ([]byte, error) {
+	page := &pdfPage{y: marginTop}
+
+	title := "INVOICE"
+	if inv.Type == TypeCreditNote {
+		title = "CREDIT NOTE"
+	}
+	page.text(marginLeft, 18, true, title)
+	page.newline(10)
+	page.row("Number", inv.Number)
+	page.row("Date", inv.IssuedAt.In(Location).Format("02 January 2006"))
+	page.row("Booking", inv.BookingReference)
+	if inv.OriginalNumber != "" {
+		page.row("Credits invoice", inv.OriginalNumber)
+	}
+	if inv.PaymentMethod != "" {
+		page.row("Payment method", inv.PaymentMethod)
+	}
+	page.newline(10)
+
+	page.party("From", inv.Seller)
+	page.newline(6)
+	page.party("Bill to", inv.Buyer)
+	page.newline(10)
+
+	// Price breakdown
+	page.text(marginLeft, 10, true, "Description")
+	page.text(330, 10, true, "Qty")
+	page.textRight(450, 10, true, "Unit price")
+	page.textRight(amountColumn, 10, true, "Amount")
+	page.newline(4)
+	page.rule()
+	for _, line := range inv.Lines {
+		page.text(marginLeft, 10, false, truncate(line.Description, 52))
+		page.text(330, 10, false, strconv.Itoa(line.Quantity))
+		page.textRight(450, 10, false, formatMoney(inv.Currency, line.UnitPrice))
+		page.textRight(amountColumn, 10, false, formatMoney(inv.Currency, line.Amount))
+		page.newline(0)
+	}
+	page.rule()
+
+	page.total("Subtotal", formatMoney(inv.Currency, inv.Subtotal), false)
+	page.total(fmt.Sprintf("%s %s%%", inv.TaxName, strconv.FormatFloat(inv.TaxRate, 'f', -1, 64)), formatMoney(inv.Currency, inv.TaxAmount), false)
+	page.total("Total", formatMoney(inv.Currency, inv.Total), true)
+
+	page.newline(20)
+	if inv.Type == TypeCreditNote {
+		page.text(marginLeft, 9, false, "This credit note reduces the amount of the invoice referenced above.")
+	} else {
+		page.text(marginLeft, 9, false, "Paid in full. Prices include tax.")
+	}
+
+	return page.document(), nil
+}
+
+type pdfPage struct {
+	content bytes.Buffer
+	y       int
+}
+
+func (p *pdfPage) newline(extra int) {
+	p.y -= lineHeight + extra
+}
+
+func (p *pdfPage) text(x, size int, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, x, p.y, escapePDF(s))
+}
+
+// textRight draws text ending at x, estimating Helvetica's average glyph width
+func (p *pdfPage) textRight(x, size int, bold bool, s string) {
+	width := len(s) * size * 55 / 100
+	p.text(x-width, size, bold, s)
+}
+
+func (p *pdfPage) row(label, value string) {
+	p.text(marginLeft, 10, true, label)
+	p.text(160, 10, false, value)
+	p.newline(0)
+}
+
+func (p *pdfPage) party(label string, party Party) {
+	p.text(marginLeft, 10, true, label)
+	p.newline(0)
+	for _, s := range []string{party.Name, taxIDLine(party.TaxID), party.Address, party.Email} {
+		if s == "" {
+			continue
+		}
+		p.text(marginLeft, 10, false, truncate(s, 90))
+		p.newline(0)
+	}
+}
+
+func (p *pdfPage) total(label, amount string, bold bool) {
+	p.text(350, 10, bold, label)
+	p.textRight(amountColumn, 10, bold, amount)
+	p.newline(0)
+}
+
+func (p *pdfPage) rule() {
+	fmt.Fprintf(&p.content, "%d %d m %d %d l S\n", marginLeft, p.y+10, amountColumn, p.y+10)
+	p.newline(0)
+}
+
+// document wraps the page content in a minimal PDF file with a valid xref table
+func (p *pdfPage) document() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pageWidth, pageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+// escapePDF escapes a PDF string literal. Characters outside ASCII are
+// replaced because the built-in fonts only cover WinAnsi.
+func escapePDF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func taxIDLine(taxID string) string {
+	if taxID == "" {
+		return ""
+	}
+	return "NPWP: " + taxID
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max-3] + "..."
+}
+
+// formatMoney formats an amount with Indonesian thousand separators, e.g. IDR 1.250.000
+func formatMoney(currency string, amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.Itoa(amount)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+
+	return fmt.Sprintf("%s %s%s", currency, sign, b.String())
+}
@@ -0,0 +1,239 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Repository defines interface for invoice data operations
+type Repository interface {
+	// Create assigns the next number in the document's series and stores it.
+	// Numbering and insert share a transaction so numbers are gap-free.
+	Create(ctx context.Context, inv *Invoice) error
+	GetByID(ctx context.Context, id string) (*Invoice, error)
+	GetByPayment(ctx context.Context, docType DocumentType, paymentID string) (*Invoice, error)
+	GetLatestByBooking(ctx context.Context, bookingID string, docType DocumentType) (*Invoice, error)
+	GetCreditedTotal(ctx context.Context, originalID string) (int, error)
+	Search(ctx context.Context, filter SearchFilter, limit, offset int) ([]*Invoice, int, error)
+}
+
+type repository struct {
+	db *db.DB
+}
+
+// NewRepository creates a new invoice repository
+func NewRepository(database *db.DB) Repository {
+	return &repository{
+		db: database,
+	}
+}
+
+const invoiceColumns = `
+	id, number, document_type, year, sequence, booking_id, booking_reference, user_id, payment_id,
+	COALESCE(payment_method, ''), COALESCE(original_invoice_id::text, ''), COALESCE(original_invoice_number, ''),
+	seller, buyer, lines, currency, subtotal, tax_name, tax_rate, tax_amount, total, issued_at
+`
+
+func scanInvoice(row pgx.Row) (*Invoice, error) {
+	var inv Invoice
+	err := row.Scan(
+		&inv.ID, &inv.Number, &inv.Type, &inv.Year, &inv.Sequence, &inv.BookingID, &inv.BookingReference,
+		&inv.UserID, &inv.PaymentID, &inv.PaymentMethod, &inv.OriginalID, &inv.OriginalNumber,
+		&inv.Seller, &inv.Buyer, &inv.Lines, &inv.Currency, &inv.Subtotal, &inv.TaxName, &inv.TaxRate,
+		&inv.TaxAmount, &inv.Total, &inv.IssuedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// Create numbers and stores an invoice
+func (r *repository) Create(ctx context.Context, inv *Invoice) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	year := inv.IssuedAt.In(Location).Year()
+
+	// The row lock on the sequence serialises numbering per series and year;
+	// a failed insert rolls the counter back, leaving no gap.
+	var sequence int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO invoice_sequences (document_type, year, last_number)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (document_type, year)
+		DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number
+	`, inv.Type, year).Scan(&sequence)
+	if err != nil {
+		return fmt.Errorf("failed to allocate invoice number: %w", err)
+	}
+
+	inv.Year = year
+	inv.Sequence = sequence
+	inv.Number = FormatNumber(inv.Type, year, sequence)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO invoices (
+			id, number, document_type, year, sequence, booking_id, booking_reference, user_id, payment_id,
+			payment_method, original_invoice_id, original_invoice_number,
+			seller, buyer, lines, currency, subtotal, tax_name, tax_rate, tax_amount, total, issued_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), NULLIF($11, '')::uuid, NULLIF($12, ''),
+			$13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+	`,
+		inv.ID, inv.Number, inv.Type, inv.Year, inv.Sequence, inv.BookingID, inv.BookingReference, inv.UserID, inv.PaymentID,
+		inv.PaymentMethod, inv.OriginalID, inv.OriginalNumber,
+		inv.Seller, inv.Buyer, inv.Lines, inv.Currency, inv.Subtotal, inv.TaxName, inv.TaxRate, inv.TaxAmount, inv.Total, inv.IssuedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrInvoiceExists
+		}
+		return fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit invoice: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an invoice by ID
+func (r *repository) GetByID(ctx context.Context, id string) (*Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE id = $1`
+
+	inv, err := scanInvoice(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	return inv, nil
+}
+
+// GetByPayment retrieves the document issued for a payment
+func (r *repository) GetByPayment(ctx context.Context, docType DocumentType, paymentID string) (*Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE document_type = $1 AND payment_id = $2`
+
+	inv, err := scanInvoice(r.db.Pool.QueryRow(ctx, query, docType, paymentID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, fmt.Errorf("failed to get invoice by payment: %w", err)
+	}
+
+	return inv, nil
+}
+
+// GetLatestByBooking retrieves the most recent document of a type for a booking
+func (r *repository) GetLatestByBooking(ctx context.Context, bookingID string, docType DocumentType) (*Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices
+		WHERE booking_id = $1 AND document_type = $2
+		ORDER BY issued_at DESC
+		LIMIT 1`
+
+	inv, err := scanInvoice(r.db.Pool.QueryRow(ctx, query, bookingID, docType))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, fmt.Errorf("failed to get booking invoice: %w", err)
+	}
+
+	return inv, nil
+}
+
+// GetCreditedTotal returns the total of credit notes issued against an invoice
+func (r *repository) GetCreditedTotal(ctx context.Context, originalID string) (int, error) {
+	var total int
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(total), 0) FROM invoices
+		WHERE document_type = $1 AND original_invoice_id = $2
+	`, TypeCreditNote, originalID).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get credited total: %w", err)
+	}
+
+	return total, nil
+}
+
+// Search retrieves invoices matching the filter, newest first
+func (r *repository) Search(ctx context.Context, filter SearchFilter, limit, offset int) ([]*Invoice, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Number != "" {
+		add("number LIKE $%d", filter.Number+"%")
+	}
+	if filter.BookingReference != "" {
+		add("booking_reference = $%d", filter.BookingReference)
+	}
+	if filter.UserID != "" {
+		add("user_id = $%d", filter.UserID)
+	}
+	if filter.TaxID != "" {
+		add("buyer->>'tax_id' = $%d", filter.TaxID)
+	}
+	if filter.CompanyName != "" {
+		add("buyer->>'name' ILIKE $%d", "%"+filter.CompanyName+"%")
+	}
+	if filter.Type != "" {
+		add("document_type = $%d", filter.Type)
+	}
+	if !filter.From.IsZero() {
+		add("issued_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("issued_at < $%d", filter.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM invoices`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count invoices: %w", err)
+	}
+
+	args = append(args, limit, offset)
+	query := `SELECT ` + invoiceColumns + ` FROM invoices` + where +
+		fmt.Sprintf(" ORDER BY issued_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*Invoice
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, inv)
+	}
+
+	return invoices, total, rows.Err()
+}
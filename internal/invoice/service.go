@@ -0,0 +1,234 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/booking"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// BookingReader loads the booking an invoice is issued for
+type BookingReader interface {
+	GetBooking(ctx context.Context, bookingID string) (*booking.Booking, error)
+}
+
+// PaymentInfo describes a successful payment to invoice
+type PaymentInfo struct {
+	BookingID string
+	PaymentID string
+	Amount    int
+	Currency  string
+	Method    string
+}
+
+// RefundInfo describes a refund to issue a credit note for
+type RefundInfo struct {
+	BookingID string
+	PaymentID string
+	Amount    int
+	Currency  string
+}
+
+// Service defines interface for invoicing business logic
+type Service interface {
+	IssueInvoice(ctx context.Context, payment *PaymentInfo) (*Invoice, error)
+	IssueCreditNote(ctx context.Context, refund *RefundInfo) (*Invoice, error)
+	GetBookingInvoice(ctx context.Context, userID, bookingID string) (*Invoice, error)
+	GetInvoice(ctx context.Context, id string) (*Invoice, error)
+	SearchInvoices(ctx context.Context, filter SearchFilter, limit, offset int) ([]*Invoice, int, error)
+}
+
+type service struct {
+	repo     Repository
+	bookings BookingReader
+	issuer   Issuer
+}
+
+// NewService creates a new invoice service
+func NewService(repo Repository, bookings BookingReader, issuer Issuer) Service {
+	return &service{
+		repo:     repo,
+		bookings: bookings,
+		issuer:   issuer,
+	}
+}
+
+// IssueInvoice issues the invoice for a successful payment. Issuing twice for
+// the same payment returns the existing invoice.
+func (s *service) IssueInvoice(ctx context.Context, payment *PaymentInfo) (*Invoice, error) {
+	existing, err := s.repo.GetByPayment(ctx, TypeInvoice, payment.PaymentID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, ErrInvoiceNotFound) {
+		return nil, err
+	}
+
+	b, err := s.bookings.GetBooking(ctx, payment.BookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking for invoice: %w", err)
+	}
+
+	total := payment.Amount
+	if total <= 0 {
+		total = b.TotalAmount
+	}
+	if total <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	currency := payment.Currency
+	if currency == "" {
+		currency = b.Currency
+	}
+
+	inv := newDocument(TypeInvoice, s.issuer, buyerFor(b), currency, total)
+	inv.BookingID = b.ID
+	inv.BookingReference = b.BookingReference
+	inv.UserID = b.UserID
+	inv.PaymentID = payment.PaymentID
+	inv.PaymentMethod = payment.Method
+	inv.Lines = accommodationLines(b, inv.Subtotal)
+
+	if err := s.create(ctx, inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// IssueCreditNote issues a credit note against the invoice of the refunded payment
+func (s *service) IssueCreditNote(ctx context.Context, refund *RefundInfo) (*Invoice, error) {
+	if refund.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	existing, err := s.repo.GetByPayment(ctx, TypeCreditNote, refund.PaymentID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, ErrInvoiceNotFound) {
+		return nil, err
+	}
+
+	original, err := s.repo.GetByPayment(ctx, TypeInvoice, refund.PaymentID)
+	if errors.Is(err, ErrInvoiceNotFound) {
+		original, err = s.repo.GetLatestByBooking(ctx, refund.BookingID, TypeInvoice)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get original invoice: %w", err)
+	}
+
+	credited, err := s.repo.GetCreditedTotal(ctx, original.ID)
+	if err != nil {
+		return nil, err
+	}
+	if credited+refund.Amount > original.Total {
+		return nil, ErrCreditExceedsTotal
+	}
+
+	// Credit notes use the tax rate of the invoice they correct
+	issuer := s.issuer
+	issuer.Party = original.Seller
+	issuer.TaxName = original.TaxName
+	issuer.TaxRate = original.TaxRate
+
+	note := newDocument(TypeCreditNote, issuer, original.Buyer, original.Currency, refund.Amount)
+	note.BookingID = original.BookingID
+	note.BookingReference = original.BookingReference
+	note.UserID = original.UserID
+	note.PaymentID = refund.PaymentID
+	note.PaymentMethod = original.PaymentMethod
+	note.OriginalID = original.ID
+	note.OriginalNumber = original.Number
+	note.Lines = []Line{{
+		Description: fmt.Sprintf("Refund for invoice %s (booking %s)", original.Number, original.BookingReference),
+		Quantity:    1,
+		UnitPrice:   note.Subtotal,
+		Amount:      note.Subtotal,
+	}}
+
+	if err := s.create(ctx, note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// create stores a document; a concurrent duplicate returns the stored one
+func (s *service) create(ctx context.Context, inv *Invoice) error {
+	if err := s.repo.Create(ctx, inv); err != nil {
+		if errors.Is(err, ErrInvoiceExists) {
+			stored, getErr := s.repo.GetByPayment(ctx, inv.Type, inv.PaymentID)
+			if getErr != nil {
+				return getErr
+			}
+			*inv = *stored
+			return nil
+		}
+		logger.ErrorWithErr(err, "Failed to issue invoice")
+		return err
+	}
+
+	logger.Infof("🧾 %s %s issued for booking %s: %s %d", inv.Type, inv.Number, inv.BookingReference, inv.Currency, inv.Total)
+	return nil
+}
+
+// GetBookingInvoice returns the invoice of a booking owned by the user
+func (s *service) GetBookingInvoice(ctx context.Context, userID, bookingID string) (*Invoice, error) {
+	inv, err := s.repo.GetLatestByBooking(ctx, bookingID, TypeInvoice)
+	if err != nil {
+		return nil, err
+	}
+
+	// Don't reveal other users' invoices
+	if inv.UserID != userID {
+		return nil, ErrInvoiceNotFound
+	}
+
+	return inv, nil
+}
+
+// GetInvoice returns any invoice or credit note (admin)
+func (s *service) GetInvoice(ctx context.Context, id string) (*Invoice, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// SearchInvoices searches invoices and credit notes (admin)
+func (s *service) SearchInvoices(ctx context.Context, filter SearchFilter, limit, offset int) ([]*Invoice, int, error) {
+	return s.repo.Search(ctx, filter, limit, offset)
+}
+
+// buyerFor uses the company billing details entered at booking, falling back to the guest
+func buyerFor(b *booking.Booking) Party {
+	if b.Billing != nil {
+		return Party{
+			Name:    b.Billing.CompanyName,
+			TaxID:   b.Billing.TaxID,
+			Address: b.Billing.Address,
+			Email:   b.Billing.Email,
+		}
+	}
+
+	name := b.GuestName
+	if name == "" {
+		name = "Guest"
+	}
+	return Party{Name: name, Email: b.GuestEmail}
+}
+
+// accommodationLines builds the price breakdown of a booking before tax
+func accommodationLines(b *booking.Booking, subtotal int) []Line {
+	nights := int(b.CheckOut.Sub(b.CheckIn).Hours() / 24)
+	if nights < 1 {
+		nights = 1
+	}
+
+	return []Line{{
+		Description: fmt.Sprintf("Hotel accommodation, booking %s (%s - %s)",
+			b.BookingReference, b.CheckIn.Format("02 Jan 2006"), b.CheckOut.Format("02 Jan 2006")),
+		Quantity:  nights,
+		UnitPrice: subtotal / nights,
+		Amount:    subtotal,
+	}}
+}
@@ -0,0 +1,307 @@
+package invoice
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/booking"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRepository is a mock implementation of invoice.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, inv *Invoice) error {
+	args := m.Called(ctx, inv)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetByID(ctx context.Context, id string) (*Invoice, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Invoice), args.Error(1)
+}
+
+func (m *MockRepository) GetByPayment(ctx context.Context, docType DocumentType, paymentID string) (*Invoice, error) {
+	args := m.Called(ctx, docType, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Invoice), args.Error(1)
+}
+
+func (m *MockRepository) GetLatestByBooking(ctx context.Context, bookingID string, docType DocumentType) (*Invoice, error) {
+	args := m.Called(ctx, bookingID, docType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Invoice), args.Error(1)
+}
+
+func (m *MockRepository) GetCreditedTotal(ctx context.Context, originalID string) (int, error) {
+	args := m.Called(ctx, originalID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) Search(ctx context.Context, filter SearchFilter, limit, offset int) ([]*Invoice, int, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*Invoice), args.Int(1), args.Error(2)
+}
+
+// MockBookingReader is a mock implementation of invoice.BookingReader
+type MockBookingReader struct {
+	mock.Mock
+}
+
+func (m *MockBookingReader) GetBooking(ctx context.Context, bookingID string) (*booking.Booking, error) {
+	args := m.Called(ctx, bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*booking.Booking), args.Error(1)
+}
+
+func testIssuer() Issuer {
+	return Issuer{
+		Party:   Party{Name: "PT Bookingkuy Indonesia", TaxID: "01.000.000.0-000.000"},
+		TaxName: "PPN",
+		TaxRate: 11,
+	}
+}
+
+func testBooking() *booking.Booking {
+	checkIn := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	return &booking.Booking{
+		ID:               "booking-1",
+		UserID:           "user-1",
+		BookingReference: "BKG-12345678",
+		CheckIn:          checkIn,
+		CheckOut:         checkIn.AddDate(0, 0, 2),
+		TotalAmount:      1110000,
+		Currency:         "IDR",
+		Billing: &booking.BillingInfo{
+			CompanyName: "PT Maju Bersama",
+			TaxID:       "01.234.567.8-901.000",
+			Address:     "Jakarta",
+		},
+	}
+}
+
+// numberOnCreate simulates the repository assigning the next number
+func numberOnCreate(mockRepo *MockRepository, sequence int) {
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*invoice.Invoice")).
+		Run(func(args mock.Arguments) {
+			inv := args.Get(1).(*Invoice)
+			inv.Year = inv.IssuedAt.Year()
+			inv.Sequence = sequence
+			inv.Number = FormatNumber(inv.Type, inv.Year, sequence)
+		}).
+		Return(nil).Once()
+}
+
+func TestFormatNumber(t *testing.T) {
+	assert.Equal(t, "INV/2026/000042", FormatNumber(TypeInvoice, 2026, 42))
+	assert.Equal(t, "CN/2026/000001", FormatNumber(TypeCreditNote, 2026, 1))
+}
+
+func TestSplitTax(t *testing.T) {
+	tests := []struct {
+		total            int
+		rate             float64
+		expectedSubtotal int
+		expectedTax      int
+	}{
+		{total: 1110000, rate: 11, expectedSubtotal: 1000000, expectedTax: 110000},
+		{total: 1000000, rate: 11, expectedSubtotal: 900901, expectedTax: 99099},
+		{total: 500000, rate: 0, expectedSubtotal: 500000, expectedTax: 0},
+	}
+
+	for _, tt := range tests {
+		subtotal, tax := SplitTax(tt.total, tt.rate)
+		assert.Equal(t, tt.expectedSubtotal, subtotal)
+		assert.Equal(t, tt.expectedTax, tax)
+		assert.Equal(t, tt.total, subtotal+tax)
+	}
+}
+
+func TestService_IssueInvoice(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockBookings := new(MockBookingReader)
+	svc := NewService(mockRepo, mockBookings, testIssuer())
+
+	mockRepo.On("GetByPayment", mock.Anything, TypeInvoice, "pay-1").Return(nil, ErrInvoiceNotFound)
+	mockBookings.On("GetBooking", mock.Anything, "booking-1").Return(testBooking(), nil)
+	numberOnCreate(mockRepo, 7)
+
+	inv, err := svc.IssueInvoice(context.Background(), &PaymentInfo{
+		BookingID: "booking-1",
+		PaymentID: "pay-1",
+		Amount:    1110000,
+		Currency:  "IDR",
+		Method:    "credit_card",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, TypeInvoice, inv.Type)
+	assert.Contains(t, inv.Number, "/000007")
+	assert.Equal(t, "PT Maju Bersama", inv.Buyer.Name)
+	assert.Equal(t, "01.234.567.8-901.000", inv.Buyer.TaxID)
+	assert.Equal(t, "PT Bookingkuy Indonesia", inv.Seller.Name)
+	assert.Equal(t, 1000000, inv.Subtotal)
+	assert.Equal(t, 110000, inv.TaxAmount)
+	assert.Equal(t, 1110000, inv.Total)
+	require.Len(t, inv.Lines, 1)
+	assert.Equal(t, 2, inv.Lines[0].Quantity)
+	assert.Equal(t, 500000, inv.Lines[0].UnitPrice)
+	assert.Equal(t, "user-1", inv.UserID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_IssueInvoice_GuestBuyer(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockBookings := new(MockBookingReader)
+	svc := NewService(mockRepo, mockBookings, testIssuer())
+
+	b := testBooking()
+	b.Billing = nil
+	b.GuestName = "Budi Santoso"
+
+	mockRepo.On("GetByPayment", mock.Anything, TypeInvoice, "pay-1").Return(nil, ErrInvoiceNotFound)
+	mockBookings.On("GetBooking", mock.Anything, "booking-1").Return(b, nil)
+	numberOnCreate(mockRepo, 1)
+
+	inv, err := svc.IssueInvoice(context.Background(), &PaymentInfo{BookingID: "booking-1", PaymentID: "pay-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Budi Santoso", inv.Buyer.Name)
+	assert.Empty(t, inv.Buyer.TaxID)
+	assert.Equal(t, b.TotalAmount, inv.Total)
+}
+
+func TestService_IssueInvoice_AlreadyIssued(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockBookings := new(MockBookingReader)
+	svc := NewService(mockRepo, mockBookings, testIssuer())
+	existing := &Invoice{ID: "inv-1", Number: "INV/2026/000001"}
+
+	mockRepo.On("GetByPayment", mock.Anything, TypeInvoice, "pay-1").Return(existing, nil)
+
+	inv, err := svc.IssueInvoice(context.Background(), &PaymentInfo{BookingID: "booking-1", PaymentID: "pay-1", Amount: 1000})
+
+	require.NoError(t, err)
+	assert.Equal(t, existing, inv)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockBookings.AssertNotCalled(t, "GetBooking", mock.Anything, mock.Anything)
+}
+
+func TestService_IssueCreditNote(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo, new(MockBookingReader), testIssuer())
+	original := &Invoice{
+		ID:               "inv-1",
+		Number:           "INV/2026/000007",
+		Type:             TypeInvoice,
+		BookingID:        "booking-1",
+		BookingReference: "BKG-12345678",
+		UserID:           "user-1",
+		Seller:           testIssuer().Party,
+		Buyer:            Party{Name: "PT Maju Bersama", TaxID: "01.234.567.8-901.000"},
+		Currency:         "IDR",
+		TaxName:          "PPN",
+		TaxRate:          11,
+		Total:            1110000,
+	}
+
+	mockRepo.On("GetByPayment", mock.Anything, TypeCreditNote, "pay-1").Return(nil, ErrInvoiceNotFound)
+	mockRepo.On("GetByPayment", mock.Anything, TypeInvoice, "pay-1").Return(original, nil)
+	mockRepo.On("GetCreditedTotal", mock.Anything, "inv-1").Return(0, nil)
+	numberOnCreate(mockRepo, 1)
+
+	note, err := svc.IssueCreditNote(context.Background(), &RefundInfo{BookingID: "booking-1", PaymentID: "pay-1", Amount: 555000})
+
+	require.NoError(t, err)
+	assert.Equal(t, TypeCreditNote, note.Type)
+	assert.Contains(t, note.Number, "CN/")
+	assert.Equal(t, "inv-1", note.OriginalID)
+	assert.Equal(t, "INV/2026/000007", note.OriginalNumber)
+	assert.Equal(t, original.Buyer, note.Buyer)
+	assert.Equal(t, 500000, note.Subtotal)
+	assert.Equal(t, 55000, note.TaxAmount)
+	assert.Equal(t, 555000, note.Total)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_IssueCreditNote_ExceedsInvoice(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo, new(MockBookingReader), testIssuer())
+	original := &Invoice{ID: "inv-1", Type: TypeInvoice, Total: 1110000}
+
+	mockRepo.On("GetByPayment", mock.Anything, TypeCreditNote, "pay-1").Return(nil, ErrInvoiceNotFound)
+	mockRepo.On("GetByPayment", mock.Anything, TypeInvoice, "pay-1").Return(original, nil)
+	mockRepo.On("GetCreditedTotal", mock.Anything, "inv-1").Return(1000000, nil)
+
+	_, err := svc.IssueCreditNote(context.Background(), &RefundInfo{BookingID: "booking-1", PaymentID: "pay-1", Amount: 200000})
+
+	assert.ErrorIs(t, err, ErrCreditExceedsTotal)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_GetBookingInvoice_OtherUser(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo, new(MockBookingReader), testIssuer())
+
+	mockRepo.On("GetLatestByBooking", mock.Anything, "booking-1", TypeInvoice).Return(&Invoice{ID: "inv-1", UserID: "user-1"}, nil)
+
+	inv, err := svc.GetBookingInvoice(context.Background(), "user-1", "booking-1")
+	require.NoError(t, err)
+	assert.Equal(t, "inv-1", inv.ID)
+
+	_, err = svc.GetBookingInvoice(context.Background(), "user-2", "booking-1")
+	assert.ErrorIs(t, err, ErrInvoiceNotFound)
+}
+
+func TestRenderPDF(t *testing.T) {
+	inv := &Invoice{
+		Number:           "INV/2026/000007",
+		Type:             TypeInvoice,
+		BookingReference: "BKG-12345678",
+		Seller:           testIssuer().Party,
+		Buyer:            Party{Name: "PT Maju (Bersama)", TaxID: "01.234.567.8-901.000"},
+		Lines:            []Line{{Description: "Hotel accommodation", Quantity: 2, UnitPrice: 500000, Amount: 1000000}},
+		Currency:         "IDR",
+		Subtotal:         1000000,
+		TaxName:          "PPN",
+		TaxRate:          11,
+		TaxAmount:        110000,
+		Total:            1110000,
+		IssuedAt:         time.Date(2026, 5, 1, 10, 0, 0, 0, Location),
+	}
+
+	pdf, err := RenderPDF(inv)
+
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), "(INV/2026/000007)")
+	assert.Contains(t, string(pdf), `(PT Maju \(Bersama\))`)
+	assert.Contains(t, string(pdf), "(IDR 1.110.000)")
+	assert.Contains(t, string(pdf), "(PPN 11%)")
+}
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "IDR 0", formatMoney("IDR", 0))
+	assert.Equal(t, "IDR 999", formatMoney("IDR", 999))
+	assert.Equal(t, "IDR 1.250.000", formatMoney("IDR", 1250000))
+	assert.Equal(t, "IDR -1.000", formatMoney("IDR", -1000))
+}
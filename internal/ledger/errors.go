@@ -0,0 +1,14 @@
+package ledger
+
+import "errors"
+
+// Package-level errors for ledger operations
+var (
+	ErrTooFewLines      = errors.New("journal entry needs at least two lines")
+	ErrUnknownAccount   = errors.New("unknown ledger account")
+	ErrInvalidLine      = errors.New("journal line must have exactly one positive side")
+	ErrUnbalancedEntry  = errors.New("journal entry debits and credits do not balance")
+	ErrDuplicateEntry   = errors.New("journal entry already posted")
+	ErrInvalidAmount    = errors.New("amount must be positive")
+	ErrInvalidDimension = errors.New("invalid revenue dimension")
+)
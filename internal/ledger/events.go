@@ -0,0 +1,115 @@
+package ledger
+
+import (
+	"context"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/booking"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// NewPaymentSuccessHandler posts payment.success events to the ledger
+func NewPaymentSuccessHandler(svc Service) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		bookingID, _ := event.Payload["booking_id"].(string)
+		paymentID, _ := event.Payload["payment_id"].(string)
+		amount, _ := event.Payload["amount"].(int)
+		fee, _ := event.Payload["fee"].(int)
+		currency, _ := event.Payload["currency"].(string)
+		method, _ := event.Payload["method"].(string)
+
+		if err := svc.PostPaymentSuccess(ctx, &PaymentPosting{
+			BookingID: bookingID,
+			PaymentID: paymentID,
+			Amount:    int64(amount),
+			Fee:       int64(fee),
+			Currency:  currency,
+			Method:    method,
+		}); err != nil {
+			// Don't block the payment flow - finance can repost from the payments table
+			logger.ErrorWithErr(err, "Failed to post payment to ledger")
+		}
+		return nil
+	}
+}
+
+// NewPaymentRefundedHandler posts payment.refunded events to the ledger
+func NewPaymentRefundedHandler(svc Service) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		bookingID, _ := event.Payload["booking_id"].(string)
+		paymentID, _ := event.Payload["payment_id"].(string)
+		amount, _ := event.Payload["amount"].(int)
+		currency, _ := event.Payload["currency"].(string)
+
+		if err := svc.PostRefund(ctx, &RefundPosting{
+			BookingID: bookingID,
+			PaymentID: paymentID,
+			Amount:    int64(amount),
+			Currency:  currency,
+		}); err != nil {
+			logger.ErrorWithErr(err, "Failed to post refund to ledger")
+		}
+		return nil
+	}
+}
+
+// NewBookingConfirmedHandler posts booking.confirmed events (supplier confirmation) to the ledger.
+// Pay-at-hotel guests pay the hotel, so there is nothing to receive from them or owe the supplier.
+func NewBookingConfirmedHandler(svc Service) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		if paysAtHotel(event) {
+			return nil
+		}
+
+		bookingID, _ := event.Payload["booking_id"].(string)
+		sellAmount, _ := event.Payload["total_amount"].(int)
+		netAmount, _ := event.Payload["net_amount"].(int)
+		currency, _ := event.Payload["currency"].(string)
+		providerCode, _ := event.Payload["provider_code"].(string)
+
+		if err := svc.PostSupplierConfirmation(ctx, &SupplierPosting{
+			BookingID:    bookingID,
+			SellAmount:   int64(sellAmount),
+			NetCost:      int64(netAmount),
+			Currency:     currency,
+			ProviderCode: providerCode,
+		}); err != nil {
+			logger.ErrorWithErr(err, "Failed to post supplier confirmation to ledger")
+		}
+		return nil
+	}
+}
+
+// NewBookingCancelledHandler posts cancellations of supplier-confirmed bookings to the ledger
+func NewBookingCancelledHandler(svc Service) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		// Nothing was owed to the supplier if the booking never reached them
+		// or the guest pays the hotel
+		if supplierRef, _ := event.Payload["supplier_reference"].(string); supplierRef == "" || paysAtHotel(event) {
+			return nil
+		}
+
+		bookingID, _ := event.Payload["booking_id"].(string)
+		netAmount, _ := event.Payload["net_amount"].(int)
+		penalty, _ := event.Payload["cancellation_penalty"].(int)
+		currency, _ := event.Payload["currency"].(string)
+		providerCode, _ := event.Payload["provider_code"].(string)
+
+		if err := svc.PostCancellationPenalty(ctx, &CancellationPosting{
+			BookingID:    bookingID,
+			NetCost:      int64(netAmount),
+			Penalty:      int64(penalty),
+			Currency:     currency,
+			ProviderCode: providerCode,
+		}); err != nil {
+			logger.ErrorWithErr(err, "Failed to post cancellation to ledger")
+		}
+		return nil
+	}
+}
+
+// paysAtHotel reports whether a booking event is for a pay-at-hotel booking
+func paysAtHotel(event eventbus.Event) bool {
+	paymentType, _ := event.Payload["payment_type"].(string)
+	return paymentType == string(booking.PaymentTypePayAtHotel)
+}
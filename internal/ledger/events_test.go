@@ -0,0 +1,107 @@
+package ledger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/ledger"
+	"github.com/ekonugroho98/be-bookingkuy/internal/midtrans"
+	"github.com/ekonugroho98/be-bookingkuy/internal/payment"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubPaymentRepository holds a single payment for the payment service
+type stubPaymentRepository struct {
+	payment *payment.Payment
+}
+
+func (r *stubPaymentRepository) Create(ctx context.Context, p *payment.Payment) error { return nil }
+
+func (r *stubPaymentRepository) GetByID(ctx context.Context, id string) (*payment.Payment, error) {
+	return r.payment, nil
+}
+
+func (r *stubPaymentRepository) GetByBookingID(ctx context.Context, bookingID string) (*payment.Payment, error) {
+	return r.payment, nil
+}
+
+func (r *stubPaymentRepository) GetByProviderRef(ctx context.Context, providerRef string) (*payment.Payment, error) {
+	return r.payment, nil
+}
+
+func (r *stubPaymentRepository) UpdateStatus(ctx context.Context, id string, status payment.PaymentStatus, providerRef string) error {
+	r.payment.Status = status
+	return nil
+}
+
+// TestPaymentSuccessHandler_PostsGatewayFee tests that the fee of a payment
+// published by the payment service reaches the fees account
+func TestPaymentSuccessHandler_PostsGatewayFee(t *testing.T) {
+	mockRepo := new(ledger.MockRepository)
+	var posted *ledger.JournalEntry
+	mockRepo.On("CreateEntry", mock.Anything, mock.AnythingOfType("*ledger.JournalEntry")).
+		Run(func(args mock.Arguments) { posted = args.Get(1).(*ledger.JournalEntry) }).
+		Return(nil).Once()
+
+	eb := eventbus.New()
+	eb.Subscribe(context.Background(), eventbus.EventPaymentSuccess, ledger.NewPaymentSuccessHandler(ledger.NewService(mockRepo)))
+
+	paymentRepo := &stubPaymentRepository{payment: &payment.Payment{
+		ID:        "pay-1",
+		BookingID: "booking-1",
+		Provider:  payment.ProviderMidtrans,
+		Method:    string(midtrans.PaymentTypeCreditCard),
+		Amount:    1000000,
+		Currency:  "IDR",
+		Status:    payment.StatusPending,
+	}}
+	err := payment.NewService(paymentRepo, eb).HandleWebhook(context.Background(), &payment.WebhookPayload{
+		PaymentID: "pay-1",
+		Status:    "success",
+		Signature: "valid-signature",
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, posted)
+	fee := int64(midtrans.TransactionFee(midtrans.PaymentTypeCreditCard, 1000000))
+	require.Positive(t, fee)
+	var fees, clearing ledger.Totals
+	for _, line := range posted.Lines {
+		switch line.Account {
+		case ledger.AccountFees:
+			fees.Debit += line.Debit
+			fees.Credit += line.Credit
+		case ledger.AccountGatewayClearing:
+			clearing.Debit += line.Debit
+			clearing.Credit += line.Credit
+		}
+	}
+	assert.Equal(t, ledger.Totals{Debit: fee}, fees)
+	assert.Equal(t, ledger.Totals{Debit: 1000000, Credit: fee}, clearing)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestBookingEventHandlers_SkipPayAtHotel tests that pay-at-hotel bookings,
+// paid by the guest to the hotel, post nothing
+func TestBookingEventHandlers_SkipPayAtHotel(t *testing.T) {
+	mockRepo := new(ledger.MockRepository)
+	svc := ledger.NewService(mockRepo)
+	payload := map[string]interface{}{
+		"booking_id":           "booking-1",
+		"supplier_reference":   "102-123456",
+		"provider_code":        "hotelbeds",
+		"payment_type":         "PAY_AT_HOTEL",
+		"total_amount":         1180000,
+		"net_amount":           1000000,
+		"cancellation_penalty": 500000,
+		"currency":             "IDR",
+	}
+
+	require.NoError(t, ledger.NewBookingConfirmedHandler(svc)(context.Background(), eventbus.Event{Payload: payload}))
+	require.NoError(t, ledger.NewBookingCancelledHandler(svc)(context.Background(), eventbus.Event{Payload: payload}))
+
+	mockRepo.AssertNotCalled(t, "CreateEntry", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,257 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountCode identifies a ledger account
+type AccountCode string
+
+const (
+	// AccountCustomerReceivable tracks what customers owe us. A credit balance
+	// means the customer paid before the booking was confirmed (a deposit).
+	AccountCustomerReceivable AccountCode = "customer_receivable"
+	// AccountGatewayClearing holds money collected by the payment gateway
+	// that has not been settled to our bank account yet
+	AccountGatewayClearing AccountCode = "gateway_clearing"
+	// AccountSupplierPayable tracks what we owe suppliers for confirmed bookings
+	AccountSupplierPayable AccountCode = "supplier_payable"
+	// AccountRevenue is our margin (sell price minus supplier cost)
+	AccountRevenue AccountCode = "revenue"
+	// AccountRefunds is contra-revenue for money returned after confirmation
+	AccountRefunds AccountCode = "refunds"
+	// AccountFees holds payment gateway fees
+	AccountFees AccountCode = "fees"
+)
+
+// AccountType represents the accounting type of an account
+type AccountType string
+
+const (
+	AccountTypeAsset     AccountType = "asset"
+	AccountTypeLiability AccountType = "liability"
+	AccountTypeIncome    AccountType = "income"
+	AccountTypeExpense   AccountType = "expense"
+)
+
+// Account describes a ledger account
+type Account struct {
+	Code AccountCode `json:"code"`
+	Name string      `json:"name"`
+	Type AccountType `json:"type"`
+}
+
+// DebitNormal reports whether the account's balance normally sits on the debit side
+func (a Account) DebitNormal() bool {
+	return a.Type == AccountTypeAsset || a.Type == AccountTypeExpense
+}
+
+// Accounts is the chart of accounts
+var Accounts = map[AccountCode]Account{
+	AccountCustomerReceivable: {Code: AccountCustomerReceivable, Name: "Customer Receivable", Type: AccountTypeAsset},
+	AccountGatewayClearing:    {Code: AccountGatewayClearing, Name: "Gateway Clearing", Type: AccountTypeAsset},
+	AccountSupplierPayable:    {Code: AccountSupplierPayable, Name: "Supplier Payable", Type: AccountTypeLiability},
+	AccountRevenue:            {Code: AccountRevenue, Name: "Revenue", Type: AccountTypeIncome},
+	AccountRefunds:            {Code: AccountRefunds, Name: "Refunds", Type: AccountTypeExpense},
+	AccountFees:               {Code: AccountFees, Name: "Payment Fees", Type: AccountTypeExpense},
+}
+
+// EntryType represents the business event behind a journal entry
+type EntryType string
+
+const (
+	EntryPaymentSuccess       EntryType = "payment_success"
+	EntryRefund               EntryType = "refund"
+	EntrySupplierConfirmation EntryType = "supplier_confirmation"
+	EntryCancellationPenalty  EntryType = "cancellation_penalty"
+)
+
+// JournalEntry is a balanced set of ledger lines posted together
+type JournalEntry struct {
+	ID            string         `json:"id" db:"id"`
+	Type          EntryType      `json:"entry_type" db:"entry_type"`
+	BookingID     string         `json:"booking_id" db:"booking_id"`
+	ReferenceID   string         `json:"reference_id" db:"reference_id"`
+	Description   string         `json:"description" db:"description"`
+	Currency      string         `json:"currency" db:"currency"`
+	ProviderCode  string         `json:"provider_code,omitempty" db:"provider_code"`
+	PaymentMethod string         `json:"payment_method,omitempty" db:"payment_method"`
+	PostedAt      time.Time      `json:"posted_at" db:"posted_at"`
+	Lines         []*JournalLine `json:"lines"`
+}
+
+// JournalLine is a single debit or credit against an account
+type JournalLine struct {
+	ID      string      `json:"id" db:"id"`
+	EntryID string      `json:"entry_id" db:"entry_id"`
+	Account AccountCode `json:"account" db:"account"`
+	Debit   int64       `json:"debit" db:"debit"`
+	Credit  int64       `json:"credit" db:"credit"`
+}
+
+// NewJournalEntry creates an empty journal entry
+func NewJournalEntry(entryType EntryType, bookingID, referenceID, currency, description string) *JournalEntry {
+	return &JournalEntry{
+		ID:          uuid.New().String(),
+		Type:        entryType,
+		BookingID:   bookingID,
+		ReferenceID: referenceID,
+		Description: description,
+		Currency:    currency,
+		PostedAt:    time.Now(),
+	}
+}
+
+// Debit adds a debit line. Zero amounts are skipped and negative amounts
+// are posted on the credit side.
+func (e *JournalEntry) Debit(account AccountCode, amount int64) *JournalEntry {
+	switch {
+	case amount > 0:
+		e.Lines = append(e.Lines, &JournalLine{ID: uuid.New().String(), EntryID: e.ID, Account: account, Debit: amount})
+	case amount < 0:
+		e.Credit(account, -amount)
+	}
+	return e
+}
+
+// Credit adds a credit line. Zero amounts are skipped and negative amounts
+// are posted on the debit side.
+func (e *JournalEntry) Credit(account AccountCode, amount int64) *JournalEntry {
+	switch {
+	case amount > 0:
+		e.Lines = append(e.Lines, &JournalLine{ID: uuid.New().String(), EntryID: e.ID, Account: account, Credit: amount})
+	case amount < 0:
+		e.Debit(account, -amount)
+	}
+	return e
+}
+
+// Validate checks that the entry uses known accounts and that debits equal credits
+func (e *JournalEntry) Validate() error {
+	if len(e.Lines) < 2 {
+		return ErrTooFewLines
+	}
+
+	var debit, credit int64
+	for _, line := range e.Lines {
+		if _, ok := Accounts[line.Account]; !ok {
+			return ErrUnknownAccount
+		}
+		if line.Debit < 0 || line.Credit < 0 || (line.Debit > 0) == (line.Credit > 0) {
+			return ErrInvalidLine
+		}
+		debit += line.Debit
+		credit += line.Credit
+	}
+
+	if debit != credit {
+		return ErrUnbalancedEntry
+	}
+	return nil
+}
+
+// Totals holds the summed debit and credit sides of a set of lines
+type Totals struct {
+	Debit  int64
+	Credit int64
+}
+
+// Balance is the total of an account's lines in one currency
+type Balance struct {
+	Account  AccountCode `json:"account"`
+	Name     string      `json:"name"`
+	Type     AccountType `json:"type"`
+	Currency string      `json:"currency"`
+	Debit    int64       `json:"debit"`
+	Credit   int64       `json:"credit"`
+	// Balance is signed on the account's normal side (debit for assets and
+	// expenses, credit for liabilities and income)
+	Balance int64 `json:"balance"`
+}
+
+// NewBalance builds a balance from debit and credit totals
+func NewBalance(code AccountCode, currency string, debit, credit int64) *Balance {
+	account := Accounts[code]
+	balance := credit - debit
+	if account.DebitNormal() {
+		balance = debit - credit
+	}
+
+	return &Balance{
+		Account:  code,
+		Name:     account.Name,
+		Type:     account.Type,
+		Currency: currency,
+		Debit:    debit,
+		Credit:   credit,
+		Balance:  balance,
+	}
+}
+
+// TrialBalance lists every account with its totals, one section per currency.
+// Amounts in different currencies are never added together.
+type TrialBalance struct {
+	AsOf       time.Time               `json:"as_of"`
+	Currencies []*CurrencyTrialBalance `json:"currencies"`
+	Balanced   bool                    `json:"balanced"` // True when every currency is balanced
+}
+
+// CurrencyTrialBalance is the trial balance of the lines posted in one currency
+type CurrencyTrialBalance struct {
+	Currency    string     `json:"currency"`
+	Accounts    []*Balance `json:"accounts"`
+	TotalDebit  int64      `json:"total_debit"`
+	TotalCredit int64      `json:"total_credit"`
+	Balanced    bool       `json:"balanced"`
+}
+
+// RevenueSummary is the revenue view used by admin analytics, for one currency
+type RevenueSummary struct {
+	Currency        string           `json:"currency"`
+	Revenue         int64            `json:"revenue"`
+	Refunds         int64            `json:"refunds"`
+	Fees            int64            `json:"fees"`
+	NetRevenue      int64            `json:"net_revenue"`
+	ByDate          map[string]int64 `json:"by_date"` // Key: YYYY-MM-DD
+	ByProvider      map[string]int64 `json:"by_provider"`
+	ByPaymentMethod map[string]int64 `json:"by_payment_method"` // Cash collected per method
+}
+
+// PaymentPosting describes a successful customer payment
+type PaymentPosting struct {
+	BookingID string
+	PaymentID string
+	Amount    int64
+	Fee       int64
+	Currency  string
+	Method    string
+}
+
+// RefundPosting describes money returned to a customer
+type RefundPosting struct {
+	BookingID string
+	PaymentID string
+	Amount    int64
+	Currency  string
+}
+
+// SupplierPosting describes a booking confirmed with a supplier
+type SupplierPosting struct {
+	BookingID    string
+	SellAmount   int64
+	NetCost      int64
+	Currency     string
+	ProviderCode string
+}
+
+// CancellationPosting describes a confirmed booking cancelled with the supplier.
+// Penalty is what the supplier still charges; the rest of NetCost is released.
+type CancellationPosting struct {
+	BookingID    string
+	NetCost      int64
+	Penalty      int64
+	Currency     string
+	ProviderCode string
+}
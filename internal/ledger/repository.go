@@ -0,0 +1,207 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Dimension is a column revenue can be grouped by
+type Dimension string
+
+const (
+	DimensionDate          Dimension = "date"
+	DimensionProvider      Dimension = "provider"
+	DimensionPaymentMethod Dimension = "payment_method"
+)
+
+// dimensionColumns maps dimensions to SQL expressions (never build these from user input)
+var dimensionColumns = map[Dimension]string{
+	DimensionDate:          "TO_CHAR(e.posted_at, 'YYYY-MM-DD')",
+	DimensionProvider:      "COALESCE(e.provider_code, 'unknown')",
+	DimensionPaymentMethod: "COALESCE(e.payment_method, 'unknown')",
+}
+
+// Repository defines interface for ledger data operations
+type Repository interface {
+	CreateEntry(ctx context.Context, entry *JournalEntry) error
+	GetAccountTotals(ctx context.Context, account AccountCode, from, to time.Time) (map[string]Totals, error)
+	GetBookingAccountTotals(ctx context.Context, account AccountCode, bookingID string) (Totals, error)
+	GetTrialBalance(ctx context.Context, asOf time.Time) (map[string]map[AccountCode]Totals, error)
+	GetAccountTotalsBy(ctx context.Context, account AccountCode, dimension Dimension, from, to time.Time) (map[string]map[string]Totals, error)
+}
+
+type repository struct {
+	db *db.DB
+}
+
+// NewRepository creates a new ledger repository
+func NewRepository(database *db.DB) Repository {
+	return &repository{
+		db: database,
+	}
+}
+
+// CreateEntry stores a journal entry and its lines in one transaction
+func (r *repository) CreateEntry(ctx context.Context, entry *JournalEntry) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO ledger_entries (id, entry_type, booking_id, reference_id, description, currency, provider_code, payment_method, posted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9)
+	`,
+		entry.ID, entry.Type, entry.BookingID, entry.ReferenceID, entry.Description,
+		entry.Currency, entry.ProviderCode, entry.PaymentMethod, entry.PostedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicateEntry
+		}
+		return fmt.Errorf("failed to create journal entry: %w", err)
+	}
+
+	for _, line := range entry.Lines {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO ledger_lines (id, entry_id, account, debit, credit)
+			VALUES ($1, $2, $3, $4, $5)
+		`, line.ID, entry.ID, line.Account, line.Debit, line.Credit)
+		if err != nil {
+			return fmt.Errorf("failed to create journal line: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit journal entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetAccountTotals returns debit and credit totals of an account posted in [from, to],
+// keyed by currency
+func (r *repository) GetAccountTotals(ctx context.Context, account AccountCode, from, to time.Time) (map[string]Totals, error) {
+	query := `
+		SELECT e.currency, COALESCE(SUM(l.debit), 0), COALESCE(SUM(l.credit), 0)
+		FROM ledger_lines l
+		JOIN ledger_entries e ON e.id = l.entry_id
+		WHERE l.account = $1 AND e.posted_at >= $2 AND e.posted_at <= $3
+		GROUP BY e.currency
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, account, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]Totals)
+	for rows.Next() {
+		var currency string
+		var debit, credit int64
+		if err := rows.Scan(&currency, &debit, &credit); err != nil {
+			return nil, fmt.Errorf("failed to scan account totals: %w", err)
+		}
+		totals[currency] = Totals{Debit: debit, Credit: credit}
+	}
+
+	return totals, nil
+}
+
+// GetBookingAccountTotals returns debit and credit totals of an account for one booking
+func (r *repository) GetBookingAccountTotals(ctx context.Context, account AccountCode, bookingID string) (Totals, error) {
+	query := `
+		SELECT COALESCE(SUM(l.debit), 0), COALESCE(SUM(l.credit), 0)
+		FROM ledger_lines l
+		JOIN ledger_entries e ON e.id = l.entry_id
+		WHERE l.account = $1 AND e.booking_id = $2
+	`
+
+	var totals Totals
+	if err := r.db.Pool.QueryRow(ctx, query, account, bookingID).Scan(&totals.Debit, &totals.Credit); err != nil {
+		return Totals{}, fmt.Errorf("failed to get booking account totals: %w", err)
+	}
+
+	return totals, nil
+}
+
+// GetTrialBalance returns debit and credit totals of every account up to asOf,
+// keyed by currency and then account
+func (r *repository) GetTrialBalance(ctx context.Context, asOf time.Time) (map[string]map[AccountCode]Totals, error) {
+	query := `
+		SELECT e.currency, l.account, COALESCE(SUM(l.debit), 0), COALESCE(SUM(l.credit), 0)
+		FROM ledger_lines l
+		JOIN ledger_entries e ON e.id = l.entry_id
+		WHERE e.posted_at <= $1
+		GROUP BY e.currency, l.account
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trial balance: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]map[AccountCode]Totals)
+	for rows.Next() {
+		var currency string
+		var account AccountCode
+		var debit, credit int64
+		if err := rows.Scan(&currency, &account, &debit, &credit); err != nil {
+			return nil, fmt.Errorf("failed to scan trial balance: %w", err)
+		}
+		if totals[currency] == nil {
+			totals[currency] = make(map[AccountCode]Totals)
+		}
+		totals[currency][account] = Totals{Debit: debit, Credit: credit}
+	}
+
+	return totals, nil
+}
+
+// GetAccountTotalsBy returns debit and credit totals of an account grouped by a dimension,
+// keyed by currency and then dimension value
+func (r *repository) GetAccountTotalsBy(ctx context.Context, account AccountCode, dimension Dimension, from, to time.Time) (map[string]map[string]Totals, error) {
+	column, ok := dimensionColumns[dimension]
+	if !ok {
+		return nil, ErrInvalidDimension
+	}
+
+	query := fmt.Sprintf(`
+		SELECT e.currency, %s AS dim, COALESCE(SUM(l.debit), 0), COALESCE(SUM(l.credit), 0)
+		FROM ledger_lines l
+		JOIN ledger_entries e ON e.id = l.entry_id
+		WHERE l.account = $1 AND e.posted_at >= $2 AND e.posted_at <= $3
+		GROUP BY e.currency, dim
+		ORDER BY e.currency, dim
+	`, column)
+
+	rows, err := r.db.Pool.Query(ctx, query, account, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account totals by %s: %w", dimension, err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]map[string]Totals)
+	for rows.Next() {
+		var currency, key string
+		var debit, credit int64
+		if err := rows.Scan(&currency, &key, &debit, &credit); err != nil {
+			return nil, fmt.Errorf("failed to scan account totals: %w", err)
+		}
+		if totals[currency] == nil {
+			totals[currency] = make(map[string]Totals)
+		}
+		totals[currency][key] = Totals{Debit: debit, Credit: credit}
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,290 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Service defines interface for ledger business logic
+type Service interface {
+	PostPaymentSuccess(ctx context.Context, p *PaymentPosting) error
+	PostRefund(ctx context.Context, p *RefundPosting) error
+	PostSupplierConfirmation(ctx context.Context, p *SupplierPosting) error
+	PostCancellationPenalty(ctx context.Context, p *CancellationPosting) error
+	GetBalance(ctx context.Context, account AccountCode, from, to time.Time) ([]*Balance, error)
+	GetTrialBalance(ctx context.Context, asOf time.Time) (*TrialBalance, error)
+	GetRevenueSummary(ctx context.Context, from, to time.Time) (map[string]*RevenueSummary, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService creates a new ledger service
+func NewService(repo Repository) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+// PostPaymentSuccess records money collected by the gateway against the customer.
+//
+//	Dr gateway_clearing     amount
+//	Cr customer_receivable  amount
+//	Dr fees                 fee
+//	Cr gateway_clearing     fee
+func (s *service) PostPaymentSuccess(ctx context.Context, p *PaymentPosting) error {
+	if p.Amount <= 0 || p.Fee < 0 {
+		return ErrInvalidAmount
+	}
+
+	entry := NewJournalEntry(EntryPaymentSuccess, p.BookingID, p.PaymentID, p.Currency, "Customer payment received")
+	entry.PaymentMethod = p.Method
+	entry.Debit(AccountGatewayClearing, p.Amount).
+		Credit(AccountCustomerReceivable, p.Amount).
+		Debit(AccountFees, p.Fee).
+		Credit(AccountGatewayClearing, p.Fee)
+
+	return s.post(ctx, entry)
+}
+
+// PostRefund records money returned to the customer. Refunds first use up any
+// unapplied customer deposit (payment received before supplier confirmation);
+// the rest is contra-revenue.
+//
+//	Dr customer_receivable  deposit part
+//	Dr refunds              remainder
+//	Cr gateway_clearing     amount
+func (s *service) PostRefund(ctx context.Context, p *RefundPosting) error {
+	if p.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	totals, err := s.repo.GetBookingAccountTotals(ctx, AccountCustomerReceivable, p.BookingID)
+	if err != nil {
+		return err
+	}
+
+	deposit := totals.Credit - totals.Debit
+	if deposit < 0 {
+		deposit = 0
+	}
+	if deposit > p.Amount {
+		deposit = p.Amount
+	}
+
+	entry := NewJournalEntry(EntryRefund, p.BookingID, p.PaymentID, p.Currency, "Customer refund")
+	entry.Debit(AccountCustomerReceivable, deposit).
+		Debit(AccountRefunds, p.Amount-deposit).
+		Credit(AccountGatewayClearing, p.Amount)
+
+	return s.post(ctx, entry)
+}
+
+// PostSupplierConfirmation recognises the sale once the supplier confirms.
+//
+//	Dr customer_receivable  sell amount
+//	Cr supplier_payable     net cost
+//	Cr revenue              margin
+func (s *service) PostSupplierConfirmation(ctx context.Context, p *SupplierPosting) error {
+	if p.SellAmount <= 0 || p.NetCost < 0 {
+		return ErrInvalidAmount
+	}
+
+	entry := NewJournalEntry(EntrySupplierConfirmation, p.BookingID, p.BookingID, p.Currency, "Booking confirmed with supplier")
+	entry.ProviderCode = p.ProviderCode
+	entry.Debit(AccountCustomerReceivable, p.SellAmount).
+		Credit(AccountSupplierPayable, p.NetCost).
+		Credit(AccountRevenue, p.SellAmount-p.NetCost)
+
+	return s.post(ctx, entry)
+}
+
+// PostCancellationPenalty releases the part of the supplier payable the supplier
+// no longer charges after a cancellation; what is kept back is the penalty.
+//
+//	Dr supplier_payable  net cost - penalty
+//	Cr revenue           net cost - penalty
+func (s *service) PostCancellationPenalty(ctx context.Context, p *CancellationPosting) error {
+	if p.NetCost < 0 || p.Penalty < 0 || p.Penalty > p.NetCost {
+		return ErrInvalidAmount
+	}
+
+	released := p.NetCost - p.Penalty
+	if released == 0 {
+		// Supplier keeps the full cost - nothing to release
+		return nil
+	}
+
+	entry := NewJournalEntry(EntryCancellationPenalty, p.BookingID, p.BookingID, p.Currency,
+		fmt.Sprintf("Booking cancelled with supplier, penalty %d", p.Penalty))
+	entry.ProviderCode = p.ProviderCode
+	entry.Debit(AccountSupplierPayable, released).
+		Credit(AccountRevenue, released)
+
+	return s.post(ctx, entry)
+}
+
+// post validates and stores an entry. Re-posting the same business event is a no-op
+// so event handlers can be retried safely.
+func (s *service) post(ctx context.Context, entry *JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateEntry(ctx, entry); err != nil {
+		if errors.Is(err, ErrDuplicateEntry) {
+			logger.Warnf("Ledger entry %s for %s already posted, skipping", entry.Type, entry.ReferenceID)
+			return nil
+		}
+		logger.ErrorWithErr(err, "Failed to post ledger entry")
+		return err
+	}
+
+	logger.Infof("Ledger entry posted: %s for booking %s", entry.Type, entry.BookingID)
+	return nil
+}
+
+// GetBalance returns an account balance per currency for lines posted in [from, to]
+func (s *service) GetBalance(ctx context.Context, account AccountCode, from, to time.Time) ([]*Balance, error) {
+	if _, ok := Accounts[account]; !ok {
+		return nil, ErrUnknownAccount
+	}
+
+	totals, err := s.repo.GetAccountTotals(ctx, account, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]*Balance, 0, len(totals))
+	for _, currency := range sortedKeys(totals) {
+		t := totals[currency]
+		balances = append(balances, NewBalance(account, currency, t.Debit, t.Credit))
+	}
+
+	return balances, nil
+}
+
+// GetTrialBalance lists every account up to asOf and checks, per currency, that debits equal credits
+func (s *service) GetTrialBalance(ctx context.Context, asOf time.Time) (*TrialBalance, error) {
+	totals, err := s.repo.GetTrialBalance(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	tb := &TrialBalance{AsOf: asOf, Currencies: []*CurrencyTrialBalance{}, Balanced: true}
+	for _, currency := range sortedKeys(totals) {
+		section := &CurrencyTrialBalance{Currency: currency}
+		for code := range Accounts {
+			t := totals[currency][code]
+			section.Accounts = append(section.Accounts, NewBalance(code, currency, t.Debit, t.Credit))
+			section.TotalDebit += t.Debit
+			section.TotalCredit += t.Credit
+		}
+		sort.Slice(section.Accounts, func(i, j int) bool {
+			return section.Accounts[i].Account < section.Accounts[j].Account
+		})
+		section.Balanced = section.TotalDebit == section.TotalCredit
+
+		tb.Currencies = append(tb.Currencies, section)
+		tb.Balanced = tb.Balanced && section.Balanced
+	}
+
+	return tb, nil
+}
+
+// GetRevenueSummary returns revenue, refunds and fees posted in [from, to], keyed by currency
+func (s *service) GetRevenueSummary(ctx context.Context, from, to time.Time) (map[string]*RevenueSummary, error) {
+	revenue, err := s.repo.GetAccountTotals(ctx, AccountRevenue, from, to)
+	if err != nil {
+		return nil, err
+	}
+	refunds, err := s.repo.GetAccountTotals(ctx, AccountRefunds, from, to)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := s.repo.GetAccountTotals(ctx, AccountFees, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byDate, err := s.repo.GetAccountTotalsBy(ctx, AccountRevenue, DimensionDate, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byProvider, err := s.repo.GetAccountTotalsBy(ctx, AccountRevenue, DimensionProvider, from, to)
+	if err != nil {
+		return nil, err
+	}
+	// Cash collected per payment method is the debit side of gateway clearing
+	byMethod, err := s.repo.GetAccountTotalsBy(ctx, AccountGatewayClearing, DimensionPaymentMethod, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string]*RevenueSummary)
+	summaryFor := func(currency string) *RevenueSummary {
+		summary, ok := summaries[currency]
+		if !ok {
+			summary = &RevenueSummary{
+				Currency:        currency,
+				ByDate:          map[string]int64{},
+				ByProvider:      map[string]int64{},
+				ByPaymentMethod: map[string]int64{},
+			}
+			summaries[currency] = summary
+		}
+		return summary
+	}
+
+	for currency, t := range revenue {
+		summaryFor(currency).Revenue = t.Credit - t.Debit
+	}
+	for currency, t := range refunds {
+		summaryFor(currency).Refunds = t.Debit - t.Credit
+	}
+	for currency, t := range fees {
+		summaryFor(currency).Fees = t.Debit - t.Credit
+	}
+	for currency, totals := range byDate {
+		summaryFor(currency).ByDate = creditBalances(totals)
+	}
+	for currency, totals := range byProvider {
+		summaryFor(currency).ByProvider = creditBalances(totals)
+	}
+	for currency, totals := range byMethod {
+		methods := summaryFor(currency).ByPaymentMethod
+		for method, t := range totals {
+			methods[method] = t.Debit
+		}
+	}
+
+	for _, summary := range summaries {
+		summary.NetRevenue = summary.Revenue - summary.Refunds - summary.Fees
+	}
+
+	return summaries, nil
+}
+
+// creditBalances converts grouped totals to credit-normal balances
+func creditBalances(totals map[string]Totals) map[string]int64 {
+	balances := make(map[string]int64, len(totals))
+	for key, t := range totals {
+		balances[key] = t.Credit - t.Debit
+	}
+	return balances
+}
+
+// sortedKeys returns the keys of a currency-keyed map in order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
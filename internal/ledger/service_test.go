@@ -0,0 +1,439 @@
+package ledger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRepository is a mock implementation of ledger.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateEntry(ctx context.Context, entry *JournalEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetAccountTotals(ctx context.Context, account AccountCode, from, to time.Time) (map[string]Totals, error) {
+	args := m.Called(ctx, account, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]Totals), args.Error(1)
+}
+
+func (m *MockRepository) GetBookingAccountTotals(ctx context.Context, account AccountCode, bookingID string) (Totals, error) {
+	args := m.Called(ctx, account, bookingID)
+	return args.Get(0).(Totals), args.Error(1)
+}
+
+func (m *MockRepository) GetTrialBalance(ctx context.Context, asOf time.Time) (map[string]map[AccountCode]Totals, error) {
+	args := m.Called(ctx, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]map[AccountCode]Totals), args.Error(1)
+}
+
+func (m *MockRepository) GetAccountTotalsBy(ctx context.Context, account AccountCode, dimension Dimension, from, to time.Time) (map[string]map[string]Totals, error) {
+	args := m.Called(ctx, account, dimension, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]map[string]Totals), args.Error(1)
+}
+
+// captureEntry expects one CreateEntry call and returns a pointer to the posted entry
+func captureEntry(mockRepo *MockRepository, err error) **JournalEntry {
+	var posted *JournalEntry
+	mockRepo.On("CreateEntry", mock.Anything, mock.AnythingOfType("*ledger.JournalEntry")).
+		Run(func(args mock.Arguments) { posted = args.Get(1).(*JournalEntry) }).
+		Return(err).Once()
+	return &posted
+}
+
+// lineTotals sums an entry's lines per account
+func lineTotals(entry *JournalEntry) map[AccountCode]Totals {
+	totals := make(map[AccountCode]Totals)
+	for _, line := range entry.Lines {
+		t := totals[line.Account]
+		t.Debit += line.Debit
+		t.Credit += line.Credit
+		totals[line.Account] = t
+	}
+	return totals
+}
+
+func TestJournalEntry_Validate(t *testing.T) {
+	t.Run("balanced", func(t *testing.T) {
+		entry := NewJournalEntry(EntryPaymentSuccess, "booking-1", "pay-1", "IDR", "test")
+		entry.Debit(AccountGatewayClearing, 1000).Credit(AccountCustomerReceivable, 1000)
+		assert.NoError(t, entry.Validate())
+	})
+
+	t.Run("unbalanced", func(t *testing.T) {
+		entry := NewJournalEntry(EntryPaymentSuccess, "booking-1", "pay-1", "IDR", "test")
+		entry.Debit(AccountGatewayClearing, 1000).Credit(AccountCustomerReceivable, 900)
+		assert.ErrorIs(t, entry.Validate(), ErrUnbalancedEntry)
+	})
+
+	t.Run("too few lines", func(t *testing.T) {
+		entry := NewJournalEntry(EntryPaymentSuccess, "booking-1", "pay-1", "IDR", "test")
+		entry.Debit(AccountGatewayClearing, 1000).Credit(AccountCustomerReceivable, 0)
+		assert.ErrorIs(t, entry.Validate(), ErrTooFewLines)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		entry := NewJournalEntry(EntryPaymentSuccess, "booking-1", "pay-1", "IDR", "test")
+		entry.Debit(AccountCode("cash"), 1000).Credit(AccountCustomerReceivable, 1000)
+		assert.ErrorIs(t, entry.Validate(), ErrUnknownAccount)
+	})
+
+	t.Run("negative amount flips side", func(t *testing.T) {
+		entry := NewJournalEntry(EntryPaymentSuccess, "booking-1", "pay-1", "IDR", "test")
+		entry.Debit(AccountGatewayClearing, 1000).Debit(AccountCustomerReceivable, -1000)
+		require.NoError(t, entry.Validate())
+		assert.Equal(t, int64(1000), entry.Lines[1].Credit)
+	})
+}
+
+func TestService_PostPaymentSuccess(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+	posted := captureEntry(mockRepo, nil)
+
+	err := svc.PostPaymentSuccess(context.Background(), &PaymentPosting{
+		BookingID: "booking-1",
+		PaymentID: "pay-1",
+		Amount:    1000000,
+		Fee:       20000,
+		Currency:  "IDR",
+		Method:    "credit_card",
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, *posted)
+	assert.Equal(t, EntryPaymentSuccess, (*posted).Type)
+	assert.Equal(t, "pay-1", (*posted).ReferenceID)
+	assert.Equal(t, "credit_card", (*posted).PaymentMethod)
+
+	totals := lineTotals(*posted)
+	assert.Equal(t, Totals{Debit: 1000000, Credit: 20000}, totals[AccountGatewayClearing])
+	assert.Equal(t, Totals{Credit: 1000000}, totals[AccountCustomerReceivable])
+	assert.Equal(t, Totals{Debit: 20000}, totals[AccountFees])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_PostPaymentSuccess_InvalidAmount(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+
+	err := svc.PostPaymentSuccess(context.Background(), &PaymentPosting{BookingID: "booking-1", PaymentID: "pay-1"})
+
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	mockRepo.AssertNotCalled(t, "CreateEntry", mock.Anything, mock.Anything)
+}
+
+func TestService_PostPaymentSuccess_Duplicate(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+	captureEntry(mockRepo, ErrDuplicateEntry)
+
+	err := svc.PostPaymentSuccess(context.Background(), &PaymentPosting{
+		BookingID: "booking-1",
+		PaymentID: "pay-1",
+		Amount:    1000000,
+		Currency:  "IDR",
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_PostRefund(t *testing.T) {
+	tests := []struct {
+		name            string
+		receivable      Totals
+		amount          int64
+		expectedDeposit int64
+		expectedRefunds int64
+	}{
+		{
+			name:            "before confirmation refunds the deposit",
+			receivable:      Totals{Credit: 1000000},
+			amount:          1000000,
+			expectedDeposit: 1000000,
+		},
+		{
+			name:            "after confirmation is contra-revenue",
+			receivable:      Totals{Debit: 1000000, Credit: 1000000},
+			amount:          1000000,
+			expectedRefunds: 1000000,
+		},
+		{
+			name:            "partial deposit left",
+			receivable:      Totals{Credit: 300000},
+			amount:          1000000,
+			expectedDeposit: 300000,
+			expectedRefunds: 700000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			svc := NewService(mockRepo)
+			mockRepo.On("GetBookingAccountTotals", mock.Anything, AccountCustomerReceivable, "booking-1").Return(tt.receivable, nil)
+			posted := captureEntry(mockRepo, nil)
+
+			err := svc.PostRefund(context.Background(), &RefundPosting{
+				BookingID: "booking-1",
+				PaymentID: "pay-1",
+				Amount:    tt.amount,
+				Currency:  "IDR",
+			})
+
+			require.NoError(t, err)
+			totals := lineTotals(*posted)
+			assert.Equal(t, tt.expectedDeposit, totals[AccountCustomerReceivable].Debit)
+			assert.Equal(t, tt.expectedRefunds, totals[AccountRefunds].Debit)
+			assert.Equal(t, tt.amount, totals[AccountGatewayClearing].Credit)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_PostSupplierConfirmation(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+	posted := captureEntry(mockRepo, nil)
+
+	err := svc.PostSupplierConfirmation(context.Background(), &SupplierPosting{
+		BookingID:    "booking-1",
+		SellAmount:   1000000,
+		NetCost:      850000,
+		Currency:     "IDR",
+		ProviderCode: "hotelbeds",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "hotelbeds", (*posted).ProviderCode)
+	totals := lineTotals(*posted)
+	assert.Equal(t, Totals{Debit: 1000000}, totals[AccountCustomerReceivable])
+	assert.Equal(t, Totals{Credit: 850000}, totals[AccountSupplierPayable])
+	assert.Equal(t, Totals{Credit: 150000}, totals[AccountRevenue])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_PostCancellationPenalty(t *testing.T) {
+	t.Run("releases payable not kept as penalty", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := NewService(mockRepo)
+		posted := captureEntry(mockRepo, nil)
+
+		err := svc.PostCancellationPenalty(context.Background(), &CancellationPosting{
+			BookingID: "booking-1",
+			NetCost:   850000,
+			Penalty:   250000,
+			Currency:  "IDR",
+		})
+
+		require.NoError(t, err)
+		totals := lineTotals(*posted)
+		assert.Equal(t, Totals{Debit: 600000}, totals[AccountSupplierPayable])
+		assert.Equal(t, Totals{Credit: 600000}, totals[AccountRevenue])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("full penalty posts nothing", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := NewService(mockRepo)
+
+		err := svc.PostCancellationPenalty(context.Background(), &CancellationPosting{
+			BookingID: "booking-1",
+			NetCost:   850000,
+			Penalty:   850000,
+			Currency:  "IDR",
+		})
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "CreateEntry", mock.Anything, mock.Anything)
+	})
+
+	t.Run("penalty above cost", func(t *testing.T) {
+		svc := NewService(new(MockRepository))
+
+		err := svc.PostCancellationPenalty(context.Background(), &CancellationPosting{
+			BookingID: "booking-1",
+			NetCost:   100,
+			Penalty:   200,
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidAmount)
+	})
+}
+
+func TestService_GetBalance_UnknownAccount(t *testing.T) {
+	svc := NewService(new(MockRepository))
+
+	_, err := svc.GetBalance(context.Background(), AccountCode("cash"), time.Time{}, time.Now())
+
+	assert.ErrorIs(t, err, ErrUnknownAccount)
+}
+
+func TestService_GetBalance_PerCurrency(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetAccountTotals", mock.Anything, AccountRevenue, from, to).Return(map[string]Totals{
+		"USD": {Credit: 1500},
+		"IDR": {Debit: 10000, Credit: 160000},
+	}, nil)
+
+	balances, err := svc.GetBalance(context.Background(), AccountRevenue, from, to)
+
+	require.NoError(t, err)
+	require.Len(t, balances, 2)
+	assert.Equal(t, "IDR", balances[0].Currency)
+	assert.Equal(t, int64(150000), balances[0].Balance)
+	assert.Equal(t, "USD", balances[1].Currency)
+	assert.Equal(t, int64(1500), balances[1].Balance)
+}
+
+func TestService_GetTrialBalance(t *testing.T) {
+	asOf := time.Now()
+
+	t.Run("balanced", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := NewService(mockRepo)
+		mockRepo.On("GetTrialBalance", mock.Anything, asOf).Return(map[string]map[AccountCode]Totals{
+			"IDR": {
+				AccountGatewayClearing:    {Debit: 1000000, Credit: 20000},
+				AccountCustomerReceivable: {Debit: 1000000, Credit: 1000000},
+				AccountFees:               {Debit: 20000},
+				AccountSupplierPayable:    {Credit: 850000},
+				AccountRevenue:            {Credit: 150000},
+			},
+		}, nil)
+
+		tb, err := svc.GetTrialBalance(context.Background(), asOf)
+
+		require.NoError(t, err)
+		assert.True(t, tb.Balanced)
+		require.Len(t, tb.Currencies, 1)
+		idr := tb.Currencies[0]
+		assert.Equal(t, "IDR", idr.Currency)
+		assert.Len(t, idr.Accounts, len(Accounts))
+		assert.Equal(t, int64(2020000), idr.TotalDebit)
+		for _, b := range idr.Accounts {
+			if b.Account == AccountGatewayClearing {
+				assert.Equal(t, int64(980000), b.Balance)
+			}
+			if b.Account == AccountSupplierPayable {
+				assert.Equal(t, int64(850000), b.Balance)
+			}
+		}
+	})
+
+	t.Run("unbalanced", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := NewService(mockRepo)
+		mockRepo.On("GetTrialBalance", mock.Anything, asOf).Return(map[string]map[AccountCode]Totals{
+			"IDR": {AccountGatewayClearing: {Debit: 1000}},
+		}, nil)
+
+		tb, err := svc.GetTrialBalance(context.Background(), asOf)
+
+		require.NoError(t, err)
+		assert.False(t, tb.Balanced)
+	})
+
+	t.Run("currencies are not offset against each other", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := NewService(mockRepo)
+		// Each currency is off by the same amount in opposite directions, which
+		// would look balanced if the two were summed together
+		mockRepo.On("GetTrialBalance", mock.Anything, asOf).Return(map[string]map[AccountCode]Totals{
+			"IDR": {AccountGatewayClearing: {Debit: 1000}},
+			"USD": {AccountRevenue: {Credit: 1000}},
+		}, nil)
+
+		tb, err := svc.GetTrialBalance(context.Background(), asOf)
+
+		require.NoError(t, err)
+		assert.False(t, tb.Balanced)
+		require.Len(t, tb.Currencies, 2)
+		assert.Equal(t, "IDR", tb.Currencies[0].Currency)
+		assert.Equal(t, int64(1000), tb.Currencies[0].TotalDebit)
+		assert.Equal(t, int64(0), tb.Currencies[0].TotalCredit)
+		assert.Equal(t, "USD", tb.Currencies[1].Currency)
+		assert.Equal(t, int64(1000), tb.Currencies[1].TotalCredit)
+	})
+}
+
+func TestService_GetRevenueSummary(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetAccountTotals", mock.Anything, AccountRevenue, from, to).Return(map[string]Totals{
+		"IDR": {Debit: 10000, Credit: 160000},
+		"USD": {Credit: 1500},
+	}, nil)
+	mockRepo.On("GetAccountTotals", mock.Anything, AccountRefunds, from, to).Return(map[string]Totals{
+		"IDR": {Debit: 50000},
+	}, nil)
+	mockRepo.On("GetAccountTotals", mock.Anything, AccountFees, from, to).Return(map[string]Totals{
+		"IDR": {Debit: 20000},
+		"USD": {Debit: 30},
+	}, nil)
+	mockRepo.On("GetAccountTotalsBy", mock.Anything, AccountRevenue, DimensionDate, from, to).
+		Return(map[string]map[string]Totals{
+			"IDR": {"2026-01-05": {Credit: 160000, Debit: 10000}},
+			"USD": {"2026-01-05": {Credit: 1500}},
+		}, nil)
+	mockRepo.On("GetAccountTotalsBy", mock.Anything, AccountRevenue, DimensionProvider, from, to).
+		Return(map[string]map[string]Totals{
+			"IDR": {"hotelbeds": {Credit: 160000, Debit: 10000}},
+			"USD": {"hotelbeds": {Credit: 1500}},
+		}, nil)
+	mockRepo.On("GetAccountTotalsBy", mock.Anything, AccountGatewayClearing, DimensionPaymentMethod, from, to).
+		Return(map[string]map[string]Totals{
+			"IDR": {"credit_card": {Debit: 1000000, Credit: 20000}},
+			"USD": {"credit_card": {Debit: 10000, Credit: 30}},
+		}, nil)
+
+	summaries, err := svc.GetRevenueSummary(context.Background(), from, to)
+
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+
+	idr := summaries["IDR"]
+	require.NotNil(t, idr)
+	assert.Equal(t, "IDR", idr.Currency)
+	assert.Equal(t, int64(150000), idr.Revenue)
+	assert.Equal(t, int64(50000), idr.Refunds)
+	assert.Equal(t, int64(20000), idr.Fees)
+	assert.Equal(t, int64(80000), idr.NetRevenue)
+	assert.Equal(t, int64(150000), idr.ByDate["2026-01-05"])
+	assert.Equal(t, int64(150000), idr.ByProvider["hotelbeds"])
+	assert.Equal(t, int64(1000000), idr.ByPaymentMethod["credit_card"])
+
+	usd := summaries["USD"]
+	require.NotNil(t, usd)
+	assert.Equal(t, int64(1500), usd.Revenue)
+	assert.Equal(t, int64(0), usd.Refunds)
+	assert.Equal(t, int64(1470), usd.NetRevenue)
+	assert.Equal(t, int64(1500), usd.ByProvider["hotelbeds"])
+	assert.Equal(t, int64(10000), usd.ByPaymentMethod["credit_card"])
+	mockRepo.AssertExpectations(t)
+}
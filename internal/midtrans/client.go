@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
@@ -21,6 +22,10 @@ const (
 	// Production URLs
 	ProductionBaseURL = "https://api.midtrans.com/v2"
 	ProductionSnapURL = "https://app.midtrans.com/snap/v1"
+
+	// VerificationAmount is the amount (IDR) pre-authorized to verify a saved
+	// card. Midtrans does not accept zero-amount authorizations.
+	VerificationAmount = 10000
 )
 
 // Config represents Midtrans configuration
@@ -98,6 +103,40 @@ func (c *Client) Charge(req *ChargeRequest) (*ChargeResponse, error) {
 	return &chargeResp, nil
 }
 
+// VerifyCard checks that a saved card can be charged by pre-authorizing
+// VerificationAmount on it, then voids the authorization so the guest is
+// never charged
+func (c *Client) VerifyCard(orderID, savedTokenID string) error {
+	resp, err := c.Charge(&ChargeRequest{
+		PaymentType: PaymentTypeCreditCard,
+		TransactionDetails: TransactionDetails{
+			OrderID:     orderID,
+			GrossAmount: VerificationAmount,
+		},
+		CreditCard: &CreditCardDetails{
+			SavedTokenID: savedTokenID,
+			Type:         "authorize",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify card: %w", err)
+	}
+
+	authorized := resp.TransactionStatus == StatusAuthorize && resp.FraudStatus != FraudDeny
+	if resp.TransactionStatus == StatusAuthorize {
+		if _, err := c.Cancel(orderID); err != nil {
+			// The hold lapses on its own; the card itself was verified
+			logger.Errorf("Failed to void card verification %s: %v", orderID, err)
+		}
+	}
+	if !authorized {
+		return fmt.Errorf("card not authorized: status %s, fraud status %s", resp.TransactionStatus, resp.FraudStatus)
+	}
+
+	logger.Infof("Midtrans card verified: OrderID=%s", orderID)
+	return nil
+}
+
 // GetTransactionStatus retrieves transaction status
 func (c *Client) GetTransactionStatus(orderID string) (*GetTransactionStatusResponse, error) {
 	url := fmt.Sprintf("%s/%s/status", c.baseURL, orderID)
@@ -153,6 +192,70 @@ func (c *Client) Cancel(orderID string) (*CancelResponse, error) {
 	return &cancelResp, nil
 }
 
+// Approve accepts a transaction challenged by fraud detection so it is captured
+func (c *Client) Approve(orderID string) (*ReviewResponse, error) {
+	return c.review(orderID, "approve")
+}
+
+// Deny rejects a transaction challenged by fraud detection
+func (c *Client) Deny(orderID string) (*ReviewResponse, error) {
+	return c.review(orderID, "deny")
+}
+
+func (c *Client) review(orderID, action string) (*ReviewResponse, error) {
+	url := fmt.Sprintf("%s/%s/%s", c.baseURL, orderID, action)
+
+	resp, err := c.doRequest("POST", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s failed with status %d: %s", action, resp.StatusCode, string(respBody))
+	}
+
+	var reviewResp ReviewResponse
+	if err := json.Unmarshal(respBody, &reviewResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	logger.Infof("Midtrans transaction %s: OrderID=%s", action, orderID)
+	return &reviewResp, nil
+}
+
+// GetBIN looks up the issuing bank and country of a card number prefix
+func (c *Client) GetBIN(bin string) (*BINInfo, error) {
+	url := fmt.Sprintf("%s/v1/bins/%s", strings.TrimSuffix(c.baseURL, "/v2"), bin)
+
+	resp, err := c.doRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BIN: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("get BIN failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var binResp BINResponse
+	if err := json.Unmarshal(respBody, &binResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &binResp.Data, nil
+}
+
 // doRequest performs HTTP request with authentication
 func (c *Client) doRequest(method, url string, body []byte) (*http.Response, error) {
 	var reqBody io.Reader
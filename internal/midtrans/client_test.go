@@ -2,7 +2,10 @@ package midtrans
 
 import (
 	"crypto/sha512"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -317,3 +320,60 @@ func TestIsTransactionStatus_Consistency(t *testing.T) {
 			"Final status %s should map to terminal state, got: %s", status, mapped)
 	}
 }
+
+// TestVerifyCard tests that a saved card is verified with a voided pre-authorization
+func TestVerifyCard(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      TransactionStatus
+		fraudStatus string
+		wantErr     bool
+		wantCancel  bool
+	}{
+		{"authorized", StatusAuthorize, FraudAccept, false, true},
+		{"denied by fraud detection", StatusAuthorize, FraudDeny, true, true},
+		{"declined", StatusDeny, "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var charge ChargeRequest
+			cancelled := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/transactions":
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&charge))
+					json.NewEncoder(w).Encode(ChargeResponse{
+						TransactionID:     "txn-1",
+						OrderID:           charge.TransactionDetails.OrderID,
+						TransactionStatus: tt.status,
+						FraudStatus:       tt.fraudStatus,
+					})
+				case "/verify-booking-123/cancel":
+					cancelled = true
+					json.NewEncoder(w).Encode(CancelResponse{Status: "200"})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{ServerKey: "test-server-key"})
+			client.baseURL = server.URL
+			client.snapURL = server.URL
+
+			err := client.VerifyCard("verify-booking-123", "saved-token-123")
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCancel, cancelled)
+			require.NotNil(t, charge.CreditCard)
+			assert.Equal(t, "saved-token-123", charge.CreditCard.SavedTokenID)
+			assert.Equal(t, "authorize", charge.CreditCard.Type)
+			assert.Equal(t, int64(VerificationAmount), charge.TransactionDetails.GrossAmount)
+		})
+	}
+}
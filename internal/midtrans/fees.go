@@ -0,0 +1,29 @@
+package midtrans
+
+import "math"
+
+// Fee is what Midtrans keeps of a settled transaction: a percentage of the
+// amount plus a fixed charge (IDR)
+type Fee struct {
+	Percent float64
+	Fixed   int
+}
+
+// DefaultFees are Midtrans' standard rates per payment type
+var DefaultFees = map[PaymentType]Fee{
+	PaymentTypeCreditCard:   {Percent: 2.9, Fixed: 2000},
+	PaymentTypeBankTransfer: {Fixed: 4000},
+	PaymentTypeGopay:        {Percent: 2},
+	PaymentTypeQRIS:         {Percent: 0.7},
+	PaymentTypeShopeePay:    {Percent: 2},
+}
+
+// TransactionFee returns the fee Midtrans charges on amount paid with
+// paymentType, or 0 for payment types without a known rate
+func TransactionFee(paymentType PaymentType, amount int) int {
+	fee, ok := DefaultFees[paymentType]
+	if !ok {
+		return 0
+	}
+	return int(math.Round(float64(amount)*fee.Percent/100)) + fee.Fixed
+}
@@ -30,6 +30,13 @@ const (
 	StatusPartialRefund TransactionStatus = "partial_refund"
 )
 
+// Fraud detection (FDS) verdicts on card transactions
+const (
+	FraudAccept    = "accept"
+	FraudChallenge = "challenge"
+	FraudDeny      = "deny"
+)
+
 // TransactionDetails represents transaction details
 type TransactionDetails struct {
 	OrderID    string `json:"order_id"`
@@ -73,6 +80,7 @@ type CreditCardDetails struct {
 	Installment *Installment `json:"installment,omitempty"`
 	TokenID    string `json:"token_id,omitempty"`
 	SavedTokenID string `json:"saved_token_id,omitempty"`
+	Type       string `json:"type,omitempty"` // "authorize" only pre-authorizes the amount
 }
 
 // Installment represents installment options
@@ -127,6 +135,8 @@ type ChargeResponse struct {
 	GrossAmount   string           `json:"gross_amount,omitempty"`
 	Currency      string           `json:"currency,omitempty"`
 	PaymentType   string           `json:"payment_type,omitempty"`
+	TransactionStatus TransactionStatus `json:"transaction_status,omitempty"`
+	FraudStatus   string           `json:"fraud_status,omitempty"`
 	RedirectURL   string           `json:"redirect_url,omitempty"`
 	TokenID       string           `json:"token_id,omitempty"`
 	PaymentURL    string           `json:"payment_url,omitempty"`
@@ -175,6 +185,7 @@ type WebhookPayload struct {
 	PaymentType          string           `json:"payment_type,omitempty"`
 	TransactionStatus    TransactionStatus `json:"transaction_status,omitempty"`
 	FraudStatus          string           `json:"fraud_status,omitempty"`
+	MaskedCard           string           `json:"masked_card,omitempty"`
 	OrderID              string           `json:"order_id"`
 	GrossAmount          string           `json:"gross_amount,omitempty"`
 	Currency             string           `json:"currency,omitempty"`
@@ -197,3 +208,29 @@ type CancelResponse struct {
 	Message       string `json:"message"`
 	TransactionID string `json:"transaction_id,omitempty"`
 }
+
+// ReviewResponse represents response from the approve and deny APIs for
+// transactions challenged by fraud detection
+type ReviewResponse struct {
+	StatusCode    string `json:"status_code"`
+	StatusMessage string `json:"status_message"`
+	TransactionID string `json:"transaction_id,omitempty"`
+	OrderID       string `json:"order_id,omitempty"`
+	FraudStatus   string `json:"fraud_status,omitempty"`
+}
+
+// BINResponse represents response from the card BIN API
+type BINResponse struct {
+	Data BINInfo `json:"data"`
+}
+
+// BINInfo describes the issuer of a card number prefix
+type BINInfo struct {
+	BIN         string `json:"bin"`
+	Bank        string `json:"bank"`
+	BankCode    string `json:"bank_code"`
+	Brand       string `json:"brand"`
+	BINType     string `json:"bin_type"`
+	CountryCode string `json:"country_code"`
+	CountryName string `json:"country_name"`
+}
@@ -109,6 +109,32 @@ func (s *Service) SendBookingCancelled(ctx context.Context, email, name string,
 	return s.emailService.SendEmail(ctx, email, "Booking Cancelled", cancellationDetails)
 }
 
+// SendPaymentReminder sends a reminder to pay a PAY_LATER booking
+func (s *Service) SendPaymentReminder(ctx context.Context, email, name string, reminderDetails map[string]interface{}) error {
+	logger.Infof("Sending payment reminder to %s", email)
+
+	if s.queueClient != nil && s.queueClient.IsConnected() {
+		message := queue.Message{
+			Type:    "payment_reminder",
+			Payload: map[string]interface{}{
+				"email":            email,
+				"name":             name,
+				"reminder_details": reminderDetails,
+			},
+		}
+
+		if err := s.queueClient.Publish(ctx, queue.QueueEmail, message); err != nil {
+			logger.ErrorWithErr(err, "Failed to publish email to queue, sending synchronously")
+			return s.emailService.SendEmail(ctx, email, "Payment Reminder", reminderDetails)
+		}
+
+		logger.Infof("Payment reminder queued for %s", email)
+		return nil
+	}
+
+	return s.emailService.SendEmail(ctx, email, "Payment Reminder", reminderDetails)
+}
+
 // SendOTPSMS sends OTP via SMS
 func (s *Service) SendOTPSMS(ctx context.Context, phone, otp string) error {
 	logger.Infof("Sending OTP to %s", phone)
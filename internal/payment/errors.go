@@ -8,4 +8,7 @@ var (
 	ErrInvalidPayment     = errors.New("invalid payment")
 	ErrPaymentFailed      = errors.New("payment failed")
 	ErrInvalidAmount      = errors.New("invalid amount")
+	ErrPaymentDeclined    = errors.New("payment declined by fraud checks")
+	ErrPaymentUnderReview = errors.New("payment is held for risk review")
+	ErrCardNotVerified    = errors.New("saved card could not be verified")
 )
@@ -2,7 +2,10 @@ package payment
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/ekonugroho98/be-bookingkuy/internal/booking"
+	"github.com/ekonugroho98/be-bookingkuy/internal/risk"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
 )
@@ -118,3 +121,68 @@ func sendRefundConfirmationNotification(ctx context.Context, data map[string]int
 	// TODO: Send actual email notification with refund details
 	return nil
 }
+
+// BookingLookup loads the booking a payment is for
+type BookingLookup interface {
+	GetByID(ctx context.Context, id string) (*booking.Booking, error)
+}
+
+// NewBookingPaymentDueHandler returns a handler that charges the saved card of a
+// PAY_LATER booking once its charge is due. The card token is loaded from the
+// booking, never carried on the event. Bookings without a saved card are left
+// to the reminder email sent by the booking package.
+func NewBookingPaymentDueHandler(svc Service, bookings BookingLookup) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		bookingID, _ := event.Payload["booking_id"].(string)
+		autoCharge, _ := event.Payload["auto_charge"].(bool)
+		if !autoCharge {
+			return nil
+		}
+
+		b, err := bookings.GetByID(ctx, bookingID)
+		if err != nil {
+			return fmt.Errorf("failed to load booking %s to charge: %w", bookingID, err)
+		}
+		if b.PaymentToken == "" {
+			logger.Warnf("Booking %s has no saved card to charge", bookingID)
+			return nil
+		}
+
+		logger.Infof("💳 Auto-charging saved card for booking %s, amount: %d", bookingID, b.TotalAmount)
+		if _, err := svc.ChargeSavedCard(ctx, bookingID, b.TotalAmount, b.PaymentToken); err != nil {
+			// The booking stays AWAITING_PAYMENT, so the guest can still pay
+			// manually before the free-cancellation deadline
+			logger.ErrorWithErr(err, "Failed to auto-charge booking")
+		}
+		return nil
+	}
+}
+
+// NewRiskReviewApprovedHandler creates a handler that completes a payment a
+// reviewer approved after the gateway flagged it
+func NewRiskReviewApprovedHandler(svc Service) eventbus.Handler {
+	return newRiskReviewHandler(svc, true)
+}
+
+// NewRiskReviewRejectedHandler creates a handler that voids a payment a
+// reviewer rejected after the gateway flagged it
+func NewRiskReviewRejectedHandler(svc Service) eventbus.Handler {
+	return newRiskReviewHandler(svc, false)
+}
+
+func newRiskReviewHandler(svc Service, approved bool) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		stage, _ := event.Payload["stage"].(string)
+		paymentID, _ := event.Payload["payment_id"].(string)
+		// Reviews before the gateway is charged only concern the booking
+		if stage != string(risk.StageGateway) || paymentID == "" {
+			return nil
+		}
+
+		fraudStatus, _ := event.Payload["fds_status"].(string)
+		if err := svc.ResolveRiskReview(ctx, paymentID, approved, fraudStatus); err != nil {
+			logger.ErrorWithErr(err, fmt.Sprintf("Failed to resolve risk review of payment %s", paymentID))
+		}
+		return nil
+	}
+}
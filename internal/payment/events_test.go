@@ -0,0 +1,50 @@
+package payment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/booking"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubBookings returns the booking it holds
+type stubBookings struct {
+	booking *booking.Booking
+}
+
+func (s *stubBookings) GetByID(ctx context.Context, id string) (*booking.Booking, error) {
+	return s.booking, nil
+}
+
+// chargeRecorder records the saved card charges made
+type chargeRecorder struct {
+	Service
+	bookingID, token string
+	amount           int
+}
+
+func (c *chargeRecorder) ChargeSavedCard(ctx context.Context, bookingID string, amount int, savedTokenID string) (*Payment, error) {
+	c.bookingID, c.amount, c.token = bookingID, amount, savedTokenID
+	return &Payment{}, nil
+}
+
+// TestBookingPaymentDueHandler_ChargesTokenFromBooking tests that the saved
+// card charged is the one stored on the booking, not one from the event
+func TestBookingPaymentDueHandler_ChargesTokenFromBooking(t *testing.T) {
+	svc := &chargeRecorder{}
+	bookings := &stubBookings{booking: &booking.Booking{ID: "booking-1", TotalAmount: 1500000, PaymentToken: "saved-token-123"}}
+
+	err := NewBookingPaymentDueHandler(svc, bookings)(context.Background(), eventbus.Event{Payload: map[string]interface{}{
+		"booking_id":   "booking-1",
+		"total_amount": 1500000,
+		"auto_charge":  true,
+	}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "booking-1", svc.bookingID)
+	assert.Equal(t, 1500000, svc.amount)
+	assert.Equal(t, "saved-token-123", svc.token)
+}
@@ -36,6 +36,9 @@ func (h *Handler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	req.IPAddress = middleware.ClientIP(r)
+	req.IPCountry = middleware.ClientCountry(r)
+
 	// TODO: Get booking amount from booking service
 	// For now, use default amount
 	amount := 1000000 // 1 million IDR
@@ -43,8 +46,10 @@ func (h *Handler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 	payment, err := h.service.CreatePayment(r.Context(), &req, amount)
 	if err != nil {
 		logger.ErrorWithErr(err, "Failed to create payment")
-		if err == ErrInvalidPayment {
+		if err == ErrInvalidPayment || err == ErrPaymentUnderReview {
 			respondWithError(w, http.StatusConflict, err.Error())
+		} else if err == ErrPaymentDeclined {
+			respondWithError(w, http.StatusForbidden, err.Error())
 		} else {
 			respondWithError(w, http.StatusInternalServerError, "Failed to create payment")
 		}
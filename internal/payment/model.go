@@ -45,6 +45,9 @@ type CreatePaymentRequest struct {
 	BookingID string       `json:"booking_id" validate:"required"`
 	Provider  PaymentProvider `json:"provider" validate:"required,oneof=midtrans stripe xendit"`
 	Method    string       `json:"method" validate:"required"`
+	// Filled in by the handler for fraud screening
+	IPAddress string `json:"-"`
+	IPCountry string `json:"-"`
 }
 
 // NewPayment creates a new payment
@@ -77,4 +80,7 @@ type WebhookPayload struct {
 	SignatureKey    string `json:"signature_key,omitempty"`
 	PaymentType     string `json:"payment_type,omitempty"`
 	TransactionStatus string `json:"transaction_status,omitempty"`
+	FraudStatus     string `json:"fraud_status,omitempty"`
+	MaskedCard      string `json:"masked_card,omitempty"`
+	SavedTokenID    string `json:"saved_token_id,omitempty"` // Set for cards saved or charged by token
 }
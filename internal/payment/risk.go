@@ -0,0 +1,174 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/midtrans"
+	"github.com/ekonugroho98/be-bookingkuy/internal/risk"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// RiskChecker screens payment attempts and the gateway's fraud verdicts
+type RiskChecker interface {
+	AssessPayment(ctx context.Context, attempt *risk.PaymentAttempt) (*risk.Assessment, error)
+	RecordGatewayResult(ctx context.Context, result *risk.GatewayResult) (*risk.Assessment, error)
+}
+
+// screenPayment scores a payment attempt before anything is charged
+func (s *service) screenPayment(ctx context.Context, payment *Payment, req *CreatePaymentRequest) error {
+	if s.riskChecker == nil {
+		return nil
+	}
+
+	assessment, err := s.riskChecker.AssessPayment(ctx, &risk.PaymentAttempt{
+		BookingID: payment.BookingID,
+		PaymentID: payment.ID,
+		IPAddress: req.IPAddress,
+		IPCountry: req.IPCountry,
+	})
+	if err != nil {
+		// The gateway's own fraud detection still applies
+		logger.ErrorWithErr(err, "Failed to screen payment for fraud")
+		return nil
+	}
+
+	switch assessment.Decision {
+	case risk.DecisionBlock:
+		logger.Warnf("Payment for booking %s blocked by fraud checks (score %d)", payment.BookingID, assessment.Score)
+		return ErrPaymentDeclined
+	case risk.DecisionReview:
+		logger.Warnf("Payment for booking %s held for risk review (score %d)", payment.BookingID, assessment.Score)
+		return ErrPaymentUnderReview
+	}
+	return nil
+}
+
+// applyFraudVerdict feeds the gateway's fraud verdict on a card transaction
+// into risk scoring and returns the status the payment should get. A capture
+// that is challenged, or that our own checks hold, stays PENDING until a
+// reviewer decides; one our checks block is cancelled at the gateway.
+func (s *service) applyFraudVerdict(ctx context.Context, payment *Payment, payload *WebhookPayload, status PaymentStatus) PaymentStatus {
+	decision := risk.DecisionAllow
+	if payload.FraudStatus == midtrans.FraudChallenge {
+		decision = risk.DecisionReview
+	}
+
+	if s.riskChecker != nil {
+		assessment, err := s.riskChecker.RecordGatewayResult(ctx, &risk.GatewayResult{
+			BookingID:   payment.BookingID,
+			PaymentID:   payment.ID,
+			FraudStatus: payload.FraudStatus,
+			MaskedCard:  payload.MaskedCard,
+			CardToken:   payload.SavedTokenID,
+			CardCountry: s.cardCountry(payload.MaskedCard),
+		})
+		if err != nil {
+			logger.ErrorWithErr(err, "Failed to record gateway fraud verdict")
+		} else {
+			decision = assessment.Decision
+		}
+	}
+
+	if status != StatusSuccess {
+		return status
+	}
+
+	switch decision {
+	case risk.DecisionReview:
+		logger.Warnf("Payment %s held for risk review (fraud status: %s)", payment.ID, payload.FraudStatus)
+		return StatusPending
+	case risk.DecisionBlock:
+		if err := s.voidAtGateway(payment, payload.FraudStatus); err != nil {
+			logger.ErrorWithErr(err, fmt.Sprintf("Failed to void blocked payment %s", payment.ID))
+		}
+		logger.Warnf("Payment %s blocked by fraud checks", payment.ID)
+		return StatusFailed
+	}
+	return status
+}
+
+// ResolveRiskReview finishes a payment held for review at the gateway once a
+// reviewer decides. Challenged transactions are approved or denied at Midtrans,
+// whose notification then completes the payment; captures we held ourselves
+// are completed here.
+func (s *service) ResolveRiskReview(ctx context.Context, paymentID string, approved bool, fraudStatus string) error {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+
+	// The gateway may have settled or expired it in the meantime
+	if payment.Status != StatusPending {
+		return nil
+	}
+
+	if s.midtransClient == nil {
+		return fmt.Errorf("risk review of payment %s requires Midtrans client", paymentID)
+	}
+
+	if fraudStatus == midtrans.FraudChallenge {
+		if approved {
+			_, err = s.midtransClient.Approve(payment.ID)
+		} else {
+			_, err = s.midtransClient.Deny(payment.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resolve challenged payment: %w", err)
+		}
+		return nil
+	}
+
+	newStatus := StatusSuccess
+	if !approved {
+		if err := s.voidAtGateway(payment, fraudStatus); err != nil {
+			return err
+		}
+		newStatus = StatusFailed
+	}
+
+	if err := s.repo.UpdateStatus(ctx, payment.ID, newStatus, payment.ProviderRef); err != nil {
+		logger.ErrorWithErr(err, "Failed to update payment status")
+		return err
+	}
+
+	if err := s.publishPaymentEvent(ctx, payment, newStatus); err != nil {
+		logger.ErrorWithErr(err, "Failed to publish payment event")
+	}
+
+	logger.Infof("Payment %s updated to status: %s (risk review)", payment.ID, newStatus)
+	return nil
+}
+
+// voidAtGateway stops a captured card transaction before it settles
+func (s *service) voidAtGateway(payment *Payment, fraudStatus string) error {
+	if s.midtransClient == nil {
+		return nil
+	}
+
+	if fraudStatus == midtrans.FraudChallenge {
+		if _, err := s.midtransClient.Deny(payment.ID); err != nil {
+			return fmt.Errorf("failed to deny payment: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := s.midtransClient.Cancel(payment.ID); err != nil {
+		return fmt.Errorf("failed to cancel payment: %w", err)
+	}
+	return nil
+}
+
+// cardCountry looks up the issuing country of a masked card (e.g. 481111-1114)
+func (s *service) cardCountry(maskedCard string) string {
+	if s.midtransClient == nil || len(maskedCard) < 6 {
+		return ""
+	}
+
+	info, err := s.midtransClient.GetBIN(maskedCard[:6])
+	if err != nil {
+		logger.Warnf("Failed to look up card BIN: %v", err)
+		return ""
+	}
+	return info.CountryCode
+}
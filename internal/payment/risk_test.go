@@ -0,0 +1,73 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/risk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRiskChecker returns a fixed risk decision
+type stubRiskChecker struct {
+	decision risk.Decision
+	err      error
+}
+
+func (s *stubRiskChecker) AssessPayment(ctx context.Context, attempt *risk.PaymentAttempt) (*risk.Assessment, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &risk.Assessment{BookingID: attempt.BookingID, Decision: s.decision}, nil
+}
+
+func (s *stubRiskChecker) RecordGatewayResult(ctx context.Context, result *risk.GatewayResult) (*risk.Assessment, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &risk.Assessment{BookingID: result.BookingID, Decision: s.decision}, nil
+}
+
+func TestService_CreatePayment_RiskScreening(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker *stubRiskChecker
+		wantErr error
+	}{
+		{"allowed", &stubRiskChecker{decision: risk.DecisionAllow}, nil},
+		{"held for review", &stubRiskChecker{decision: risk.DecisionReview}, ErrPaymentUnderReview},
+		{"blocked", &stubRiskChecker{decision: risk.DecisionBlock}, ErrPaymentDeclined},
+		{"screening unavailable", &stubRiskChecker{err: errors.New("database down")}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockRepo := new(MockRepository)
+			service := NewServiceWithRisk(mockRepo, new(MockEventBus), nil, tt.checker)
+
+			req := &CreatePaymentRequest{
+				BookingID: "booking-123",
+				Provider:  ProviderMidtrans,
+				Method:    "credit_card",
+				IPAddress: "203.0.113.7",
+			}
+
+			mockRepo.On("GetByBookingID", ctx, req.BookingID).Return(nil, errors.New("not found"))
+			mockRepo.On("Create", ctx, mock.AnythingOfType("*payment.Payment")).Return(nil)
+
+			payment, err := service.CreatePayment(ctx, req, 1000000)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, StatusPending, payment.Status)
+		})
+	}
+}
@@ -3,10 +3,12 @@ package payment
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/ekonugroho98/be-bookingkuy/internal/midtrans"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/google/uuid"
 )
 
 // Service defines interface for payment business logic
@@ -14,6 +16,9 @@ type Service interface {
 	CreatePayment(ctx context.Context, req *CreatePaymentRequest, amount int) (*Payment, error)
 	HandleWebhook(ctx context.Context, payload *WebhookPayload) error
 	GetPayment(ctx context.Context, paymentID string) (*Payment, error)
+	ChargeSavedCard(ctx context.Context, bookingID string, amount int, savedTokenID string) (*Payment, error)
+	VerifySavedCard(ctx context.Context, bookingID, savedTokenID string) error
+	ResolveRiskReview(ctx context.Context, paymentID string, approved bool, fraudStatus string) error
 }
 
 type service struct {
@@ -21,6 +26,7 @@ type service struct {
 	eventBus       eventbus.EventBus
 	midtransClient *midtrans.Client
 	midtransMapper *midtrans.Mapper
+	riskChecker    RiskChecker
 }
 
 // NewService creates a new payment service
@@ -34,11 +40,18 @@ func NewService(repo Repository, eb eventbus.EventBus) Service {
 
 // NewServiceWithMidtrans creates a new payment service with Midtrans client
 func NewServiceWithMidtrans(repo Repository, eb eventbus.EventBus, midtransClient *midtrans.Client) Service {
+	return NewServiceWithRisk(repo, eb, midtransClient, nil)
+}
+
+// NewServiceWithRisk creates a new payment service with Midtrans client that
+// screens payments for fraud. A nil checker disables screening.
+func NewServiceWithRisk(repo Repository, eb eventbus.EventBus, midtransClient *midtrans.Client, rc RiskChecker) Service {
 	return &service{
 		repo:           repo,
 		eventBus:       eb,
 		midtransClient: midtransClient,
 		midtransMapper: midtrans.NewMapper(),
+		riskChecker:    rc,
 	}
 }
 
@@ -51,13 +64,20 @@ func (s *service) CreatePayment(ctx context.Context, req *CreatePaymentRequest,
 			logger.Infof("Pending payment already exists for booking %s", req.BookingID)
 			return existingPayment, nil
 		}
-		// If payment is completed, return error
-		return nil, errors.New("payment already completed for this booking")
+		// If payment is completed, return error. A failed one can be retried.
+		if existingPayment.Status != StatusFailed {
+			return nil, errors.New("payment already completed for this booking")
+		}
 	}
 
 	// Create new payment
 	payment := NewPayment(req.BookingID, req, amount)
 
+	// Screen the attempt before anything is charged
+	if err := s.screenPayment(ctx, payment, req); err != nil {
+		return nil, err
+	}
+
 	// Call payment provider based on provider type
 	if req.Provider == ProviderMidtrans && s.midtransClient != nil {
 		// Create Midtrans charge request
@@ -138,21 +158,31 @@ func (s *service) HandleWebhook(ctx context.Context, payload *WebhookPayload) er
 		}
 
 		// Map Midtrans status to internal status
-		newStatus := PaymentStatus(midtrans.MapTransactionStatus(
-			midtrans.TransactionStatus(payload.TransactionStatus),
-		))
+		newStatus := paymentStatus(midtrans.TransactionStatus(payload.TransactionStatus))
 
 		if newStatus == "" {
 			logger.Error("Invalid payment status in webhook")
 			return errors.New("invalid payment status")
 		}
 
+		// Card captures and denials carry the gateway's fraud verdict
+		transactionStatus := midtrans.TransactionStatus(payload.TransactionStatus)
+		if payload.FraudStatus != "" && (transactionStatus == midtrans.StatusCapture || transactionStatus == midtrans.StatusDeny) {
+			newStatus = s.applyFraudVerdict(ctx, payment, payload, newStatus)
+		}
+
 		// Update payment status
 		if err := s.repo.UpdateStatus(ctx, payment.ID, newStatus, payload.ProviderRef); err != nil {
 			logger.ErrorWithErr(err, "Failed to update payment status")
 			return err
 		}
 
+		// The guest may have picked another method on the payment page, and
+		// the gateway fee depends on it
+		if payload.PaymentType != "" {
+			payment.Method = payload.PaymentType
+		}
+
 		// Publish payment event
 		if err := s.publishPaymentEvent(ctx, payment, newStatus); err != nil {
 			logger.ErrorWithErr(err, "Failed to publish payment event")
@@ -205,6 +235,140 @@ func (s *service) GetPayment(ctx context.Context, paymentID string) (*Payment, e
 	return s.repo.GetByID(ctx, paymentID)
 }
 
+// ChargeSavedCard charges a card saved with the provider without the guest being present.
+// Used for PAY_LATER bookings once their charge is due. A booking's card is charged once:
+// a pending or successful payment of the booking is returned as it is, and the gateway
+// order ID is derived from the booking so the gateway refuses a repeated charge.
+func (s *service) ChargeSavedCard(ctx context.Context, bookingID string, amount int, savedTokenID string) (*Payment, error) {
+	existing, err := s.repo.GetByBookingID(ctx, bookingID)
+	if err == nil && existing != nil && (existing.Status == StatusPending || existing.Status == StatusSuccess) {
+		logger.Infof("Booking %s already has payment %s (%s), not charging again", bookingID, existing.ID, existing.Status)
+		return existing, nil
+	}
+
+	if s.midtransClient == nil {
+		return nil, errors.New("saved card charge requires Midtrans client")
+	}
+
+	req := &CreatePaymentRequest{
+		BookingID: bookingID,
+		Provider:  ProviderMidtrans,
+		Method:    string(midtrans.PaymentTypeCreditCard),
+	}
+	payment := NewPayment(bookingID, req, amount)
+
+	if err := s.screenPayment(ctx, payment, req); err != nil {
+		return nil, err
+	}
+
+	chargeReq := s.midtransMapper.ToChargeRequestWithPaymentType(
+		&midtrans.PaymentInput{
+			OrderID:   payLaterOrderID(bookingID),
+			BookingID: payment.BookingID,
+			Amount:    payment.Amount,
+		},
+		&midtrans.CustomerDetails{},
+		midtrans.PaymentTypeCreditCard,
+	)
+	chargeReq.CreditCard = &midtrans.CreditCardDetails{
+		SavedTokenID: savedTokenID,
+	}
+
+	chargeResp, err := s.midtransClient.Charge(chargeReq)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to charge saved card with Midtrans")
+		return nil, errors.New("failed to charge saved card")
+	}
+	payment.ProviderRef = chargeResp.TransactionID
+	payment.Status = s.savedCardChargeStatus(ctx, payment, chargeResp, savedTokenID)
+
+	if err := s.repo.Create(ctx, payment); err != nil {
+		logger.ErrorWithErr(err, "Failed to create payment")
+		return nil, errors.New("failed to create payment")
+	}
+
+	switch payment.Status {
+	case StatusFailed:
+		if err := s.publishPaymentEvent(ctx, payment, payment.Status); err != nil {
+			logger.ErrorWithErr(err, "Failed to publish payment event")
+		}
+		return nil, fmt.Errorf("%w: saved card charge %s (fraud status: %s)",
+			ErrPaymentFailed, chargeResp.TransactionStatus, chargeResp.FraudStatus)
+	case StatusSuccess:
+		if err := s.publishPaymentEvent(ctx, payment, payment.Status); err != nil {
+			logger.ErrorWithErr(err, "Failed to publish payment event")
+		}
+	case StatusPending:
+		if chargeResp.FraudStatus == midtrans.FraudChallenge {
+			return nil, ErrPaymentUnderReview
+		}
+	}
+
+	logger.Infof("Saved card charged: payment %s for booking %s (%s)", payment.ID, bookingID, payment.Status)
+	return payment, nil
+}
+
+// savedCardChargeStatus returns the status of a saved card charge from the
+// gateway's answer, applying its fraud verdict as a webhook would
+func (s *service) savedCardChargeStatus(ctx context.Context, payment *Payment, resp *midtrans.ChargeResponse, savedTokenID string) PaymentStatus {
+	status := paymentStatus(resp.TransactionStatus)
+	if status == "" {
+		// An answer we can't read is settled by the webhook
+		status = StatusPending
+	}
+	if resp.FraudStatus != "" && (resp.TransactionStatus == midtrans.StatusCapture || resp.TransactionStatus == midtrans.StatusDeny) {
+		status = s.applyFraudVerdict(ctx, payment, &WebhookPayload{
+			TransactionStatus: string(resp.TransactionStatus),
+			FraudStatus:       resp.FraudStatus,
+			SavedTokenID:      savedTokenID,
+		}, status)
+	}
+	if resp.FraudStatus == midtrans.FraudDeny {
+		return StatusFailed
+	}
+	return status
+}
+
+// paymentStatus maps a Midtrans transaction status to a payment status, or
+// returns "" for a status it does not know
+func paymentStatus(status midtrans.TransactionStatus) PaymentStatus {
+	switch midtrans.MapTransactionStatus(status) {
+	case "pending":
+		return StatusPending
+	case "success":
+		return StatusSuccess
+	case "failed", "cancelled":
+		return StatusFailed
+	case "refunded":
+		return StatusRefunded
+	default:
+		return ""
+	}
+}
+
+// payLaterOrderID is the gateway order ID of a booking's PAY_LATER charge
+func payLaterOrderID(bookingID string) string {
+	return "paylater-" + bookingID
+}
+
+// VerifySavedCard checks with the provider that a saved card can be charged,
+// without charging it. Used to accept the card as a PAY_AT_HOTEL guarantee.
+func (s *service) VerifySavedCard(ctx context.Context, bookingID, savedTokenID string) error {
+	if s.midtransClient == nil {
+		return errors.New("card verification requires Midtrans client")
+	}
+
+	// Each verification is its own gateway order
+	orderID := "verify-" + bookingID + "-" + uuid.New().String()[:8]
+	if err := s.midtransClient.VerifyCard(orderID, savedTokenID); err != nil {
+		logger.ErrorWithErr(err, "Failed to verify saved card with Midtrans")
+		return ErrCardNotVerified
+	}
+
+	logger.Infof("Saved card verified for booking %s", bookingID)
+	return nil
+}
+
 func (s *service) generatePaymentURL(payment *Payment) string {
 	// Mock implementation - in real scenario, this would call payment gateway API
 	return "https://payment-gateway.example.com/pay/" + payment.ID
@@ -228,12 +392,25 @@ func (s *service) publishPaymentEvent(ctx context.Context, payment *Payment, sta
 		return nil
 	}
 
-	return s.eventBus.Publish(ctx, eventType, map[string]interface{}{
+	payload := map[string]interface{}{
 		"payment_id": payment.ID,
 		"booking_id": payment.BookingID,
 		"amount":     payment.Amount,
 		"currency":   payment.Currency,
 		"status":     string(status),
 		"provider":   string(payment.Provider),
-	})
+		"method":     payment.Method,
+	}
+	if status == StatusSuccess {
+		payload["fee"] = gatewayFee(payment)
+	}
+	return s.eventBus.Publish(ctx, eventType, payload)
+}
+
+// gatewayFee returns what the payment provider keeps of a successful payment
+func gatewayFee(payment *Payment) int {
+	if payment.Provider != ProviderMidtrans {
+		return 0
+	}
+	return midtrans.TransactionFee(midtrans.PaymentType(payment.Method), payment.Amount)
 }
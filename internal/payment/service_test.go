@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/ekonugroho98/be-bookingkuy/internal/midtrans"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -548,3 +549,71 @@ func TestPaymentProvider_Constants(t *testing.T) {
 	assert.Equal(t, PaymentProvider("stripe"), ProviderStripe)
 	assert.Equal(t, PaymentProvider("xendit"), ProviderXendit)
 }
+
+// TestService_ChargeSavedCard_NoMidtrans tests that saved card charges need the Midtrans client
+func TestService_ChargeSavedCard_NoMidtrans(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockEB := new(MockEventBus)
+
+	service := NewService(mockRepo, mockEB)
+
+	mockRepo.On("GetByBookingID", mock.Anything, "booking-123").Return(nil, ErrPaymentNotFound)
+
+	payment, err := service.ChargeSavedCard(context.Background(), "booking-123", 1500000, "saved-token-123")
+
+	require.Error(t, err)
+	assert.Nil(t, payment)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestService_ChargeSavedCard_AlreadyCharged tests that a booking with a
+// pending or successful payment is not charged again
+func TestService_ChargeSavedCard_AlreadyCharged(t *testing.T) {
+	for _, status := range []PaymentStatus{StatusPending, StatusSuccess} {
+		t.Run(string(status), func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := NewService(mockRepo, new(MockEventBus))
+
+			existing := &Payment{ID: "payment-1", BookingID: "booking-123", Status: status}
+			mockRepo.On("GetByBookingID", mock.Anything, "booking-123").Return(existing, nil)
+
+			payment, err := service.ChargeSavedCard(context.Background(), "booking-123", 1500000, "saved-token-123")
+
+			require.NoError(t, err)
+			assert.Same(t, existing, payment)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestService_SavedCardChargeStatus tests reading the gateway's answer to a
+// saved card charge
+func TestService_SavedCardChargeStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      midtrans.TransactionStatus
+		fraudStatus string
+		expected    PaymentStatus
+	}{
+		{name: "captured", status: midtrans.StatusCapture, fraudStatus: midtrans.FraudAccept, expected: StatusSuccess},
+		{name: "challenged", status: midtrans.StatusCapture, fraudStatus: midtrans.FraudChallenge, expected: StatusPending},
+		{name: "fraud deny", status: midtrans.StatusCapture, fraudStatus: midtrans.FraudDeny, expected: StatusFailed},
+		{name: "denied", status: midtrans.StatusDeny, fraudStatus: midtrans.FraudDeny, expected: StatusFailed},
+		{name: "pending", status: midtrans.StatusPending, expected: StatusPending},
+		{name: "unknown", status: "", expected: StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &service{}
+			payment := &Payment{ID: "payment-1", BookingID: "booking-123"}
+
+			status := svc.savedCardChargeStatus(context.Background(), payment, &midtrans.ChargeResponse{
+				TransactionStatus: tt.status,
+				FraudStatus:       tt.fraudStatus,
+			}, "saved-token-123")
+
+			assert.Equal(t, tt.expected, status)
+		})
+	}
+}
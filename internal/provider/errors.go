@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelplanner"
+)
+
+// ErrRejected marks a provider turning a request down, e.g. a room that is
+// sold out. Providers wrap it so callers can tell a refusal from a failure.
+var ErrRejected = errors.New("rejected by provider")
+
+// IsRejection reports whether err is a definitive refusal of this request by
+// the provider: ErrRejected, a rate that changed since it was priced, or a
+// 400, 404, 409 or 422 response. The provider answered, so it is healthy and
+// did not act on the request. Transport errors, timeouts, throttling, 5xx
+// responses, cancellation and open breakers are not rejections; the request
+// may or may not have been processed. Neither are 401, 403 and other 4xx
+// responses, which point at our credentials or setup and fail every request.
+func IsRejection(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrRejected) || errors.Is(err, hotelbeds.ErrRateChanged) {
+		return true
+	}
+
+	var hotelbedsErr *hotelbeds.APIError
+	if errors.As(err, &hotelbedsErr) {
+		return isRejectionStatus(hotelbedsErr.StatusCode)
+	}
+	var hotelplannerErr *hotelplanner.APIError
+	if errors.As(err, &hotelplannerErr) {
+		return isRejectionStatus(hotelplannerErr.StatusCode)
+	}
+	return false
+}
+
+func isRejectionStatus(status int) bool {
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
+}
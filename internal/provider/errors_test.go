@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelplanner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRejection(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"rejected", fmt.Errorf("%w: sold out", ErrRejected), true},
+		{"rate changed", fmt.Errorf("failed to create booking: %w", hotelbeds.ErrRateChanged), true},
+		{"hotelbeds bad request", fmt.Errorf("failed to create booking: %w", &hotelbeds.APIError{StatusCode: http.StatusBadRequest}), true},
+		{"hotelplanner conflict", &hotelplanner.APIError{StatusCode: http.StatusConflict, Code: "ROOM_SOLD_OUT"}, true},
+		{"hotelbeds unknown hotel", &hotelbeds.APIError{StatusCode: http.StatusNotFound}, true},
+		{"hotelplanner invalid request", &hotelplanner.APIError{StatusCode: http.StatusUnprocessableEntity}, true},
+		{"hotelbeds unauthorized", &hotelbeds.APIError{StatusCode: http.StatusUnauthorized}, false},
+		{"hotelplanner forbidden", &hotelplanner.APIError{StatusCode: http.StatusForbidden}, false},
+		{"hotelbeds server error", &hotelbeds.APIError{StatusCode: http.StatusBadGateway}, false},
+		{"hotelbeds throttled", &hotelbeds.APIError{StatusCode: http.StatusTooManyRequests}, false},
+		{"hotelplanner request timeout", &hotelplanner.APIError{StatusCode: http.StatusRequestTimeout}, false},
+		{"deadline exceeded", fmt.Errorf("failed to execute request: %w", context.DeadlineExceeded), false},
+		{"cancelled", context.Canceled, false},
+		{"breaker open", fmt.Errorf("hotelbeds: %w", ErrBreakerOpen), false},
+		{"transport error", errors.New("connection reset by peer"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRejection(tt.err))
+		})
+	}
+}
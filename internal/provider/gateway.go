@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
+)
+
+// Gateway calls a provider by its code. Services that work on a known hotel
+// or an existing booking use it rather than a provider client, so they work
+// with every registered provider.
+type Gateway interface {
+	CheckAvailability(ctx context.Context, providerCode string, req *types.RoomRequest) (*types.HotelAvailability, error)
+	CheckHotelsAvailability(ctx context.Context, providerCode string, req *types.HotelsRequest) ([]types.HotelAvailability, error)
+	GetRoomRate(ctx context.Context, providerCode string, req *types.RoomRequest) (*types.Rate, error)
+	GetHotelDetails(ctx context.Context, providerCode, hotelID string) (*types.Hotel, error)
+	CreateBooking(ctx context.Context, providerCode string, req *types.BookingRequest) (*types.BookingConfirmation, error)
+	CancelBooking(ctx context.Context, providerCode, reference string) error
+	GetBookingStatus(ctx context.Context, providerCode, reference string) (string, error)
+}
+
+// Ensure Registry implements the gateway
+var _ Gateway = (*Registry)(nil)
+
+// call runs fn against a provider through its circuit breaker and records
+// the outcome. Providers disabled for routing can still be called, since
+// their existing bookings must stay manageable.
+func (r *Registry) call(ctx context.Context, providerCode string, fn func(p Provider) error) error {
+	provider, exists := r.providers[providerCode]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrProviderNotFound, providerCode)
+	}
+	if !r.breakers[providerCode].allow() {
+		return fmt.Errorf("%s: %w", providerCode, ErrBreakerOpen)
+	}
+
+	start := time.Now()
+	err := fn(provider)
+	r.recordCall(ctx, providerCode, time.Since(start), err)
+	return err
+}
+
+// CheckAvailability checks a hotel's rooms with the given provider
+func (r *Registry) CheckAvailability(ctx context.Context, providerCode string, req *types.RoomRequest) (*types.HotelAvailability, error) {
+	var availability *types.HotelAvailability
+	err := r.call(ctx, providerCode, func(p Provider) (err error) {
+		availability, err = p.CheckAvailability(ctx, req)
+		return err
+	})
+	return availability, err
+}
+
+// CheckHotelsAvailability checks several hotels' rooms with the given provider
+func (r *Registry) CheckHotelsAvailability(ctx context.Context, providerCode string, req *types.HotelsRequest) ([]types.HotelAvailability, error) {
+	var hotels []types.HotelAvailability
+	err := r.call(ctx, providerCode, func(p Provider) (err error) {
+		hotels, err = p.CheckHotelsAvailability(ctx, req)
+		return err
+	})
+	return hotels, err
+}
+
+// GetRoomRate prices a room with the given provider
+func (r *Registry) GetRoomRate(ctx context.Context, providerCode string, req *types.RoomRequest) (*types.Rate, error) {
+	var rate *types.Rate
+	err := r.call(ctx, providerCode, func(p Provider) (err error) {
+		rate, err = p.GetRoomRate(ctx, req)
+		return err
+	})
+	return rate, err
+}
+
+// GetHotelDetails retrieves a hotel's details from the given provider
+func (r *Registry) GetHotelDetails(ctx context.Context, providerCode, hotelID string) (*types.Hotel, error) {
+	var hotel *types.Hotel
+	err := r.call(ctx, providerCode, func(p Provider) (err error) {
+		hotel, err = p.GetHotelDetails(ctx, hotelID)
+		return err
+	})
+	return hotel, err
+}
+
+// CreateBooking books with the given provider, without falling back to others
+func (r *Registry) CreateBooking(ctx context.Context, providerCode string, req *types.BookingRequest) (*types.BookingConfirmation, error) {
+	var confirmation *types.BookingConfirmation
+	err := r.call(ctx, providerCode, func(p Provider) (err error) {
+		confirmation, err = p.CreateBooking(ctx, req)
+		return err
+	})
+	return confirmation, err
+}
+
+// CancelBooking cancels a booking with the provider it was made with
+func (r *Registry) CancelBooking(ctx context.Context, providerCode, reference string) error {
+	return r.call(ctx, providerCode, func(p Provider) error {
+		return p.CancelBooking(ctx, reference)
+	})
+}
+
+// GetBookingStatus retrieves a booking's status from the provider it was made with
+func (r *Registry) GetBookingStatus(ctx context.Context, providerCode, reference string) (string, error) {
+	var status string
+	err := r.call(ctx, providerCode, func(p Provider) (err error) {
+		status, err = p.GetBookingStatus(ctx, reference)
+		return err
+	})
+	return status, err
+}
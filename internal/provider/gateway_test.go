@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_GatewayCallsNamedProvider(t *testing.T) {
+	var attempts []string
+	registry := NewRegistry()
+	registry.RegisterWithConfig(&bookingProvider{fakeProvider: fakeProvider{name: "hotelbeds"}, attempts: &attempts},
+		Config{Priority: 1, Enabled: true})
+	registry.RegisterWithConfig(&bookingProvider{fakeProvider: fakeProvider{name: "hotelplanner"}, attempts: &attempts},
+		Config{Priority: 2, Enabled: false})
+
+	// Disabled providers stay reachable for bookings already made with them
+	confirmation, err := registry.CreateBooking(context.Background(), "hotelplanner", &types.BookingRequest{HotelID: "H1"})
+	require.NoError(t, err)
+	assert.Equal(t, "hotelplanner-ref", confirmation.ProviderReference)
+	assert.Equal(t, []string{"hotelplanner"}, attempts)
+
+	_, err = registry.CreateBooking(context.Background(), "unknown", &types.BookingRequest{HotelID: "H1"})
+	assert.ErrorIs(t, err, ErrProviderNotFound)
+}
+
+func TestRegistry_GatewayRespectsOpenBreaker(t *testing.T) {
+	var attempts []string
+	registry := NewRegistry()
+	registry.RegisterWithConfig(&bookingProvider{fakeProvider: fakeProvider{name: "hotelbeds"}, attempts: &attempts},
+		Config{Priority: 1, Enabled: true})
+	require.NoError(t, registry.OverrideBreaker("hotelbeds", OverrideOpen, time.Now().Add(time.Hour)))
+
+	_, err := registry.CreateBooking(context.Background(), "hotelbeds", &types.BookingRequest{HotelID: "H1"})
+	assert.ErrorIs(t, err, ErrBreakerOpen)
+	assert.Empty(t, attempts)
+}
+
+// rejectingProvider turns every booking down with err
+type rejectingProvider struct {
+	fakeProvider
+	err error
+}
+
+func (p *rejectingProvider) CreateBooking(ctx context.Context, req *types.BookingRequest) (*types.BookingConfirmation, error) {
+	return nil, p.err
+}
+
+func TestRegistry_GatewayRejectionsKeepBreakerClosed(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetBreakerConfig(BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	registry.Register(&rejectingProvider{fakeProvider: fakeProvider{name: "hotelbeds"}, err: fmt.Errorf("%w: room sold out", ErrRejected)})
+
+	for i := 0; i < 3; i++ {
+		_, err := registry.CreateBooking(context.Background(), "hotelbeds", &types.BookingRequest{HotelID: "H1"})
+		require.ErrorIs(t, err, ErrRejected)
+	}
+
+	status, err := registry.ProviderHealth("hotelbeds")
+	require.NoError(t, err)
+	assert.Equal(t, BreakerClosed, status.State)
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.Equal(t, int64(3), registry.Metrics()["hotelbeds"].SuccessRequests)
+}
+
+func TestRegistry_GatewayAuthErrorsOpenBreaker(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetBreakerConfig(BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	registry.Register(&rejectingProvider{
+		fakeProvider: fakeProvider{name: "hotelbeds"},
+		err:          &hotelbeds.APIError{StatusCode: http.StatusUnauthorized},
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := registry.CreateBooking(context.Background(), "hotelbeds", &types.BookingRequest{HotelID: "H1"})
+		require.Error(t, err)
+	}
+
+	status, err := registry.ProviderHealth("hotelbeds")
+	require.NoError(t, err)
+	assert.Equal(t, BreakerOpen, status.State)
+}
@@ -0,0 +1,313 @@
+package provider
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// ErrInvalidOverride is returned for an unknown circuit breaker override
+var ErrInvalidOverride = errors.New("invalid circuit breaker override")
+
+// BreakerState is the state of a provider's circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "CLOSED"    // Calls go through
+	BreakerOpen     BreakerState = "OPEN"      // Calls are skipped until the cool-down ends
+	BreakerHalfOpen BreakerState = "HALF_OPEN" // One trial call decides whether to close again
+)
+
+// BreakerOverride is an admin's manual setting of a breaker. It holds until
+// its expiry, after which the breaker follows call outcomes again.
+type BreakerOverride string
+
+const (
+	OverrideNone   BreakerOverride = ""       // The breaker follows call outcomes
+	OverrideOpen   BreakerOverride = "OPEN"   // The provider is never called
+	OverrideClosed BreakerOverride = "CLOSED" // The provider is always called
+)
+
+const (
+	DefaultOverrideDuration = time.Hour      // How long an override holds when no duration is given
+	MaxOverrideDuration     = 24 * time.Hour // Longest an override may be set for
+)
+
+// StoredOverride is a breaker override as saved in the database, so that every
+// instance applies it until it expires
+type StoredOverride struct {
+	ProviderCode string          `json:"provider_code" db:"provider_code"`
+	Override     BreakerOverride `json:"override" db:"override"`
+	ExpiresAt    time.Time       `json:"expires_at" db:"expires_at"`
+	UpdatedBy    string          `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt    time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// BreakerConfig holds the circuit breaker thresholds
+type BreakerConfig struct {
+	FailureThreshold int           // Consecutive failures that open the breaker
+	OpenTimeout      time.Duration // How long an open breaker skips calls before a trial call
+	ScoreWindow      int           // Recent calls the health score is computed over
+}
+
+// DefaultBreakerConfig returns the default circuit breaker configuration
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		ScoreWindow:      20,
+	}
+}
+
+// HealthStatus is a provider's breaker state and recent health
+type HealthStatus struct {
+	State               BreakerState    `json:"state"`
+	Override            BreakerOverride `json:"override,omitempty"`
+	OverrideExpiresAt   *time.Time      `json:"override_expires_at,omitempty"`
+	Score               int             `json:"score"` // Share of recent calls that succeeded, 0-100
+	ConsecutiveFailures int             `json:"consecutive_failures"`
+	LastError           string          `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time      `json:"last_failure_at,omitempty"`
+	LastSuccessAt       *time.Time      `json:"last_success_at,omitempty"`
+	OpenedAt            *time.Time      `json:"opened_at,omitempty"`
+	ProbeError          string          `json:"probe_error,omitempty"`
+	LastProbeAt         *time.Time      `json:"last_probe_at,omitempty"`
+}
+
+// circuitBreaker tracks one provider's call outcomes. Consecutive failures
+// open it; after the cool-down a single trial call (or a passing health
+// probe) closes it again, a failing one reopens it.
+type circuitBreaker struct {
+	mu     sync.Mutex
+	name   string
+	config BreakerConfig
+	now    func() time.Time
+
+	state         BreakerState
+	override      BreakerOverride
+	overrideUntil time.Time
+	failures      int
+	openedAt      time.Time
+	trialInFlight bool
+
+	outcomes []bool // Ring buffer of recent outcomes for the health score
+	next     int
+
+	lastError     string
+	lastFailureAt time.Time
+	lastSuccessAt time.Time
+	probeError    string
+	lastProbeAt   time.Time
+}
+
+func newCircuitBreaker(name string, config BreakerConfig) *circuitBreaker {
+	if config.ScoreWindow <= 0 {
+		config.ScoreWindow = DefaultBreakerConfig().ScoreWindow
+	}
+	return &circuitBreaker{
+		name:     name,
+		config:   config,
+		now:      time.Now,
+		state:    BreakerClosed,
+		outcomes: make([]bool, 0, config.ScoreWindow),
+	}
+}
+
+// allow reports whether a call may go through. An open breaker past its
+// cool-down lets exactly one trial call through, which must be followed by
+// record or release.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.activeOverride() {
+	case OverrideOpen:
+		return false
+	case OverrideClosed:
+		return true
+	}
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.config.OpenTimeout {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.trialInFlight = true
+		return true
+	case BreakerHalfOpen:
+		if b.trialInFlight {
+			return false
+		}
+		b.trialInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// available reports whether allow would let a call through, without claiming a trial
+func (b *circuitBreaker) available() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.activeOverride() {
+	case OverrideOpen:
+		return false
+	case OverrideClosed:
+		return true
+	}
+
+	switch b.state {
+	case BreakerOpen:
+		return b.now().Sub(b.openedAt) >= b.config.OpenTimeout
+	case BreakerHalfOpen:
+		return !b.trialInFlight
+	default:
+		return true
+	}
+}
+
+// record feeds the outcome of a call
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialInFlight = false
+	if len(b.outcomes) < b.config.ScoreWindow {
+		b.outcomes = append(b.outcomes, err == nil)
+	} else {
+		b.outcomes[b.next] = err == nil
+		b.next = (b.next + 1) % b.config.ScoreWindow
+	}
+
+	now := b.now()
+	if err == nil {
+		b.failures = 0
+		b.lastSuccessAt = now
+		if b.state != BreakerClosed {
+			b.close()
+		}
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+	b.lastFailureAt = now
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.config.FailureThreshold) {
+		b.trip(now)
+	}
+}
+
+// release gives back a trial call that ended without an outcome, e.g. because the caller went away
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialInFlight = false
+}
+
+// recordProbe feeds a background health check. A failing probe opens the
+// breaker right away; a passing one closes it once the cool-down is over.
+func (b *circuitBreaker) recordProbe(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.lastProbeAt = now
+	if err != nil {
+		b.probeError = err.Error()
+		b.lastError = "health check: " + err.Error()
+		b.lastFailureAt = now
+		if b.state != BreakerOpen {
+			b.trip(now)
+		}
+		return
+	}
+
+	b.probeError = ""
+	if b.state != BreakerClosed && !b.trialInFlight && now.Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.close()
+	}
+}
+
+func (b *circuitBreaker) trip(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+	logger.Warnf("🔌 Circuit breaker for %s opened: %s", b.name, b.lastError)
+}
+
+func (b *circuitBreaker) close() {
+	b.state = BreakerClosed
+	b.failures = 0
+	logger.Infof("🔌 Circuit breaker for %s closed", b.name)
+}
+
+// setOverride forces the breaker open or closed until the given time, or
+// with OverrideNone hands it back to call outcomes
+func (b *circuitBreaker) setOverride(override BreakerOverride, until time.Time) error {
+	switch override {
+	case OverrideNone:
+		until = time.Time{}
+	case OverrideOpen, OverrideClosed:
+	default:
+		return ErrInvalidOverride
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.override = override
+	b.overrideUntil = until
+	return nil
+}
+
+// activeOverride returns the override unless it has expired. Callers hold b.mu.
+func (b *circuitBreaker) activeOverride() BreakerOverride {
+	if b.override == OverrideNone || !b.now().Before(b.overrideUntil) {
+		return OverrideNone
+	}
+	return b.override
+}
+
+// status returns a snapshot of the breaker
+func (b *circuitBreaker) status() HealthStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := HealthStatus{
+		State:               b.state,
+		Override:            b.activeOverride(),
+		Score:               100,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+		LastFailureAt:       timePtr(b.lastFailureAt),
+		LastSuccessAt:       timePtr(b.lastSuccessAt),
+		ProbeError:          b.probeError,
+		LastProbeAt:         timePtr(b.lastProbeAt),
+	}
+	if status.Override != OverrideNone {
+		status.OverrideExpiresAt = timePtr(b.overrideUntil)
+	}
+	if b.state != BreakerClosed {
+		status.OpenedAt = timePtr(b.openedAt)
+	}
+
+	if len(b.outcomes) > 0 {
+		successes := 0
+		for _, ok := range b.outcomes {
+			if ok {
+				successes++
+			}
+		}
+		status.Score = successes * 100 / len(b.outcomes)
+	}
+	return status
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
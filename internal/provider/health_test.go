@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a settable time source
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestBreaker(clock *fakeClock) *circuitBreaker {
+	b := newCircuitBreaker("hotelbeds", BreakerConfig{FailureThreshold: 3, OpenTimeout: 30 * time.Second, ScoreWindow: 4})
+	b.now = clock.Now
+	return b
+}
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	b := newTestBreaker(clock)
+	failure := errors.New("503 service unavailable")
+
+	b.record(failure)
+	b.record(failure)
+	b.record(nil) // A success resets the count
+	b.record(failure)
+	b.record(failure)
+	assert.Equal(t, BreakerClosed, b.status().State)
+	assert.True(t, b.allow())
+
+	b.record(failure)
+	status := b.status()
+	assert.Equal(t, BreakerOpen, status.State)
+	assert.Equal(t, 3, status.ConsecutiveFailures)
+	assert.Equal(t, "503 service unavailable", status.LastError)
+	assert.Equal(t, 25, status.Score) // 1 of the last 4 calls succeeded
+	assert.False(t, b.allow())
+	assert.False(t, b.available())
+}
+
+func TestCircuitBreaker_HalfOpenTrial(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	b := newTestBreaker(clock)
+	for i := 0; i < 3; i++ {
+		b.record(errors.New("timeout"))
+	}
+
+	clock.now = clock.now.Add(31 * time.Second)
+	assert.True(t, b.available())
+	require.True(t, b.allow())
+	assert.Equal(t, BreakerHalfOpen, b.status().State)
+	assert.False(t, b.allow(), "only one trial call at a time")
+
+	// A failed trial reopens the breaker for another cool-down
+	b.record(errors.New("timeout"))
+	assert.Equal(t, BreakerOpen, b.status().State)
+	assert.False(t, b.allow())
+
+	clock.now = clock.now.Add(31 * time.Second)
+	require.True(t, b.allow())
+	b.record(nil)
+	assert.Equal(t, BreakerClosed, b.status().State)
+	assert.True(t, b.allow())
+}
+
+func TestCircuitBreaker_ReleaseFreesTrial(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	b := newTestBreaker(clock)
+	for i := 0; i < 3; i++ {
+		b.record(errors.New("timeout"))
+	}
+	clock.now = clock.now.Add(31 * time.Second)
+
+	require.True(t, b.allow())
+	b.release()
+	assert.True(t, b.allow())
+}
+
+func TestCircuitBreaker_Probe(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	b := newTestBreaker(clock)
+
+	b.recordProbe(errors.New("connection refused"))
+	status := b.status()
+	assert.Equal(t, BreakerOpen, status.State)
+	assert.Equal(t, "connection refused", status.ProbeError)
+	require.NotNil(t, status.LastProbeAt)
+
+	// A passing probe only closes the breaker once the cool-down is over
+	b.recordProbe(nil)
+	assert.Equal(t, BreakerOpen, b.status().State)
+
+	clock.now = clock.now.Add(31 * time.Second)
+	b.recordProbe(nil)
+	status = b.status()
+	assert.Equal(t, BreakerClosed, status.State)
+	assert.Empty(t, status.ProbeError)
+}
+
+func TestCircuitBreaker_Override(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	b := newTestBreaker(clock)
+	until := clock.now.Add(time.Hour)
+
+	require.NoError(t, b.setOverride(OverrideOpen, until))
+	assert.False(t, b.allow())
+	status := b.status()
+	assert.Equal(t, OverrideOpen, status.Override)
+	require.NotNil(t, status.OverrideExpiresAt)
+	assert.Equal(t, until, *status.OverrideExpiresAt)
+
+	for i := 0; i < 3; i++ {
+		b.record(errors.New("timeout"))
+	}
+	require.NoError(t, b.setOverride(OverrideClosed, until))
+	assert.True(t, b.allow())
+
+	require.NoError(t, b.setOverride(OverrideNone, until))
+	assert.False(t, b.allow())
+	assert.Nil(t, b.status().OverrideExpiresAt)
+
+	assert.ErrorIs(t, b.setOverride("HALF", until), ErrInvalidOverride)
+}
+
+func TestCircuitBreaker_OverrideExpires(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	b := newTestBreaker(clock)
+
+	require.NoError(t, b.setOverride(OverrideOpen, clock.now.Add(time.Hour)))
+	assert.False(t, b.available())
+
+	// Past its expiry the override no longer applies, even without a reload
+	clock.now = clock.now.Add(time.Hour)
+	assert.True(t, b.available())
+	assert.True(t, b.allow())
+	status := b.status()
+	assert.Equal(t, OverrideNone, status.Override)
+	assert.Nil(t, status.OverrideExpiresAt)
+}
+
+// unhealthyProvider fails its health check and counts searches
+type unhealthyProvider struct {
+	fakeProvider
+	searches int
+}
+
+func (u *unhealthyProvider) SearchAvailability(ctx context.Context, req *types.AvailabilityRequest) (*types.AvailabilityResponse, error) {
+	u.searches++
+	return u.fakeProvider.SearchAvailability(ctx, req)
+}
+
+func (u *unhealthyProvider) HealthCheck(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestRegistry_ProbeHealthSkipsOpenProviders(t *testing.T) {
+	r := NewRegistry()
+	down := &unhealthyProvider{fakeProvider: fakeProvider{name: "down", hotels: []string{"d1"}}}
+	r.Register(down)
+	r.Register(&fakeProvider{name: "up", hotels: []string{"u1"}})
+
+	require.NoError(t, r.ProbeHealth(context.Background()))
+	health := r.Health()
+	assert.Equal(t, BreakerOpen, health["down"].State)
+	assert.Equal(t, BreakerClosed, health["up"].State)
+	assert.Equal(t, []string{"up"}, names(r.GetHealthy(context.Background())))
+
+	resp, err := r.SearchAll(context.Background(), &types.AvailabilityRequest{City: "Bali"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, hotelIDs(resp))
+	assert.Equal(t, 0, down.searches)
+	require.Len(t, resp.Providers, 2)
+	assert.Equal(t, ErrBreakerOpen.Error(), resp.Providers[0].Error)
+
+	// Forcing the breaker closed lets the provider be called again
+	require.NoError(t, r.OverrideBreaker("down", OverrideClosed, time.Now().Add(time.Hour)))
+	resp, err = r.SearchAll(context.Background(), &types.AvailabilityRequest{City: "Bali"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"d1", "u1"}, hotelIDs(resp))
+	assert.Equal(t, 1, down.searches)
+
+	assert.ErrorIs(t, r.OverrideBreaker("unknown", OverrideOpen, time.Now().Add(time.Hour)), ErrProviderNotFound)
+}
+
+func TestRegistry_SearchAllFeedsBreaker(t *testing.T) {
+	r := NewRegistry()
+	r.SetBreakerConfig(BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	r.Register(&fakeProvider{name: "flaky", err: errors.New("502 bad gateway")})
+	r.Register(&fakeProvider{name: "stable", hotels: []string{"s1"}})
+
+	for i := 0; i < 2; i++ {
+		_, err := r.SearchAll(context.Background(), &types.AvailabilityRequest{City: "Bali"})
+		require.NoError(t, err)
+	}
+
+	status, err := r.ProviderHealth("flaky")
+	require.NoError(t, err)
+	assert.Equal(t, BreakerOpen, status.State)
+	assert.Equal(t, 0, status.Score)
+	assert.Equal(t, int64(2), r.Metrics()["flaky"].FailedRequests)
+}
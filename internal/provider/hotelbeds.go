@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"time"
 
 	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
 	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
@@ -14,13 +13,21 @@ import (
 // HotelbedsProvider implements Provider interface for Hotelbeds
 type HotelbedsProvider struct {
 	client *hotelbeds.Client
+	api    hotelbeds.ClientInterface
 	mapper *hotelbeds.Mapper
 }
 
 // NewHotelbedsProvider creates a new Hotelbeds provider
 func NewHotelbedsProvider(apiKey, sharedSecret, baseURL string) *HotelbedsProvider {
+	return NewHotelbedsProviderWithClient(hotelbeds.NewClient(apiKey, sharedSecret, baseURL))
+}
+
+// NewHotelbedsProviderWithClient creates a Hotelbeds provider sharing an
+// existing client, and with it the client's rate limit
+func NewHotelbedsProviderWithClient(client *hotelbeds.Client) *HotelbedsProvider {
 	return &HotelbedsProvider{
-		client: hotelbeds.NewClient(apiKey, sharedSecret, baseURL),
+		client: client,
+		api:    client,
 		mapper: hotelbeds.NewMapper(),
 	}
 }
@@ -103,53 +110,224 @@ func (h *HotelbedsProvider) SearchAvailability(ctx context.Context, req *types.A
 func (h *HotelbedsProvider) GetHotelDetails(ctx context.Context, hotelID string) (*types.Hotel, error) {
 	logger.Infof("Getting Hotelbeds hotel details: %s", hotelID)
 
-	// Remove HB- prefix if present
-	code := hotelID
-	if len(hotelID) > 3 && hotelID[:3] == "HB-" {
-		code = hotelID[3:]
+	details, err := h.api.GetHotelDetails(ctx, hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hotel details: %w", err)
 	}
 
-	endpoint := fmt.Sprintf("/hotel-api/1.0/hotels/%s", code)
-	resp, err := h.client.Get(ctx, endpoint)
+	hotel := &types.Hotel{
+		ID:          details.HotelCode,
+		Name:        details.HotelName,
+		CountryCode: details.CountryCode,
+		City:        details.CityCode,
+		Address:     details.Address,
+		Latitude:    details.Location.Latitude,
+		Longitude:   details.Location.Longitude,
+		Rating:      details.Rating,
+		Description: details.Description,
+	}
+	for _, image := range details.Images {
+		hotel.Images = append(hotel.Images, image.URL)
+	}
+	for _, amenity := range details.Amenities {
+		hotel.Amenities = append(hotel.Amenities, amenity.Description)
+	}
+
+	return hotel, nil
+}
+
+// CheckAvailability checks a Hotelbeds hotel's rooms for a stay
+func (h *HotelbedsProvider) CheckAvailability(ctx context.Context, req *types.RoomRequest) (*types.HotelAvailability, error) {
+	resp, err := h.api.GetHotelAvailability(ctx, &hotelbeds.AvailabilityRequest{
+		HotelCode: req.HotelID,
+		RoomCode:  req.RoomID,
+		CheckIn:   req.CheckIn,
+		CheckOut:  req.CheckOut,
+		Guests:    req.Guests,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get hotel details: %w", err)
+		return nil, fmt.Errorf("failed to check availability: %w", err)
+	}
+
+	return h.toAvailability(resp), nil
+}
+
+// CheckHotelsAvailability checks several Hotelbeds hotels' rooms for a stay
+// with one availability request
+func (h *HotelbedsProvider) CheckHotelsAvailability(ctx context.Context, req *types.HotelsRequest) ([]types.HotelAvailability, error) {
+	resp, err := h.api.GetHotelsAvailability(ctx, &hotelbeds.HotelsAvailabilityRequest{
+		HotelCodes: req.HotelIDs,
+		CheckIn:    req.CheckIn,
+		CheckOut:   req.CheckOut,
+		Guests:     req.Guests,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check availability: %w", err)
+	}
+
+	hotels := make([]types.HotelAvailability, 0, len(resp))
+	for i := range resp {
+		if availability := h.toAvailability(&resp[i]); len(availability.Rooms) > 0 {
+			hotels = append(hotels, *availability)
+		}
+	}
+	return hotels, nil
+}
+
+// toAvailability maps a Hotelbeds hotel's availability to its available rooms
+func (h *HotelbedsProvider) toAvailability(resp *hotelbeds.AvailabilityResponse) *types.HotelAvailability {
+	availability := &types.HotelAvailability{
+		Hotel:    types.Hotel{ID: resp.HotelCode, Name: resp.HotelName},
+		Rooms:    []types.RoomRate{},
+		Provider: h.Name(),
+	}
+	if !resp.IsAvailable {
+		return availability
+	}
+
+	for _, room := range resp.Rooms {
+		if !room.Available {
+			continue
+		}
+		roomRate := types.RoomRate{
+			Room: types.Room{
+				ID:       room.RoomCode,
+				HotelID:  resp.HotelCode,
+				Name:     room.RoomName,
+				Capacity: room.MaxGuests,
+				BedType:  room.Beds,
+			},
+		}
+		for _, rate := range room.Rates {
+			roomRate.Rates = append(roomRate.Rates, types.Rate{
+				RoomID:       room.RoomCode,
+				NetPrice:     rate.Price,
+				Currency:     rate.Currency,
+				MealPlan:     rate.BoardCode,
+				Cancellation: toCancellationPolicy(rate.CancellationPolicies),
+				RateKey:      rate.RateKey,
+				RateType:     rate.RateType,
+			})
+		}
+		// Responses without the room's rates carry its priced rate only
+		if len(roomRate.Rates) == 0 {
+			roomRate.Rates = []types.Rate{{
+				RoomID:   room.RoomCode,
+				NetPrice: room.Price,
+				Currency: room.Currency,
+				RateKey:  room.RateKey,
+				RateType: room.RateType,
+			}}
+		}
+		availability.Rooms = append(availability.Rooms, roomRate)
+	}
+
+	return availability
+}
+
+// GetRoomRate prices a Hotelbeds room for the whole stay
+func (h *HotelbedsProvider) GetRoomRate(ctx context.Context, req *types.RoomRequest) (*types.Rate, error) {
+	resp, err := h.api.GetRoomRates(ctx, &hotelbeds.RoomRateRequest{
+		HotelCode: req.HotelID,
+		RoomCode:  req.RoomID,
+		CheckIn:   req.CheckIn,
+		CheckOut:  req.CheckOut,
+		Guests:    req.Guests,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room rates: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// TODO: Parse actual response
-	return &types.Hotel{
-		ID:     hotelID,
-		Name:   "Sample Hotel from Hotelbeds",
-		City:   "Jakarta",
-		Rating: 4.5,
+	return &types.Rate{
+		RoomID:       resp.RoomCode,
+		NetPrice:     resp.TotalPrice,
+		Currency:     resp.Currency,
+		Cancellation: toCancellationPolicy(resp.CancellationPolicies),
+		RateKey:      resp.RateKey,
+		RateType:     resp.RateType,
 	}, nil
 }
 
+// toCancellationPolicy condenses HotelBeds cancellation charges: cancelling
+// is free until the earliest charge applies, and that charge is the penalty
+func toCancellationPolicy(policies []hotelbeds.CancellationPolicy) types.CancellationPolicy {
+	var result types.CancellationPolicy
+	for _, policy := range policies {
+		if result.FreeCancellationBefore.IsZero() || policy.From.Before(result.FreeCancellationBefore) {
+			result.FreeCancellationBefore = policy.From
+			result.PenaltyType = "FIXED"
+			result.PenaltyAmount = policy.Amount
+		}
+	}
+	return result
+}
+
+// fromCancellationPolicy turns a condensed policy back into its first
+// HotelBeds charge, or none when the policy has no deadline
+func fromCancellationPolicy(policy types.CancellationPolicy) []hotelbeds.CancellationPolicy {
+	if policy.FreeCancellationBefore.IsZero() {
+		return nil
+	}
+	return []hotelbeds.CancellationPolicy{{
+		Amount: policy.PenaltyAmount,
+		From:   policy.FreeCancellationBefore,
+	}}
+}
+
 // CreateBooking creates a booking on Hotelbeds
 func (h *HotelbedsProvider) CreateBooking(ctx context.Context, req *types.BookingRequest) (*types.BookingConfirmation, error) {
 	logger.Infof("Creating booking on Hotelbeds for hotel %s", req.HotelID)
 
-	// Convert to Hotelbeds request format
-	hbReq := h.mapper.ToHotelbedsBookingRequest(req)
+	// Pay-at-hotel bookings are settled by the guest at the property
+	paymentMethodType := "CREDITCARD"
+	if req.PayAtHotel {
+		paymentMethodType = "AT_HOTEL"
+	}
 
-	// Call Hotelbeds booking API
-	endpoint := "/hotel-api/1.0/bookings"
-	resp, err := h.client.Post(ctx, endpoint, hbReq)
+	resp, err := h.api.CreateBooking(ctx, &hotelbeds.BookingRequest{
+		HotelCode: req.HotelID,
+		RoomCode:  req.RoomID,
+		CheckIn:   req.CheckIn,
+		CheckOut:  req.CheckOut,
+		Guests:    req.Guests,
+		Holder: hotelbeds.HolderInfo{
+			Name:    req.GuestInfo.FirstName,
+			Surname: req.GuestInfo.LastName,
+			Email:   req.GuestInfo.Email,
+			Phone:   req.GuestInfo.Phone,
+		},
+		Payment: hotelbeds.PaymentInfo{
+			PaymentMethodType: paymentMethodType,
+		},
+		RateKey:              req.Rate.RateKey,
+		RateType:             req.Rate.RateType,
+		NetPrice:             req.Rate.NetPrice,
+		CancellationPolicies: fromCancellationPolicy(req.Rate.Cancellation),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create booking: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// TODO: Parse actual response
 	confirmation := &types.BookingConfirmation{
-		BookingID:         fmt.Sprintf("HB-%d", time.Now().Unix()),
-		ProviderReference: fmt.Sprintf("HBS-%d", time.Now().Unix()),
-		Status:            "CONFIRMED",
+		BookingID:         resp.BookingReference,
+		ProviderReference: resp.BookingReference,
+		Hotel:             types.Hotel{ID: resp.HotelCode},
+		Room:              types.Room{ID: resp.RoomCode, HotelID: resp.HotelCode},
+		CheckIn:           resp.CheckIn,
+		CheckOut:          resp.CheckOut,
+		Status:            resp.Status,
 		TotalPrice:        req.Rate.NetPrice,
 		Currency:          req.Rate.Currency,
 	}
+	// The net HotelBeds confirms is what we owe, whatever was priced
+	if resp.TotalNet > 0 {
+		confirmation.TotalPrice = resp.TotalNet
+		if resp.Currency != "" {
+			confirmation.Currency = resp.Currency
+		}
+	}
 
-	logger.Infof("Booking created on Hotelbeds: %s", confirmation.BookingID)
+	logger.Infof("Booking created on Hotelbeds: %s", confirmation.ProviderReference)
 	return confirmation, nil
 }
 
@@ -157,18 +335,9 @@ func (h *HotelbedsProvider) CreateBooking(ctx context.Context, req *types.Bookin
 func (h *HotelbedsProvider) CancelBooking(ctx context.Context, bookingID string) error {
 	logger.Infof("Cancelling Hotelbeds booking: %s", bookingID)
 
-	// Remove HB- prefix if present
-	ref := bookingID
-	if len(bookingID) > 3 && bookingID[:3] == "HB-" {
-		ref = bookingID[3:]
-	}
-
-	endpoint := fmt.Sprintf("/hotel-api/1.0/bookings/%s/cancellations", ref)
-	resp, err := h.client.Post(ctx, endpoint, nil)
-	if err != nil {
+	if err := h.api.CancelBooking(ctx, bookingID); err != nil {
 		return fmt.Errorf("failed to cancel booking: %w", err)
 	}
-	defer resp.Body.Close()
 
 	logger.Infof("Booking %s cancelled on Hotelbeds", bookingID)
 	return nil
@@ -2,31 +2,31 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
+	"math"
+	"strings"
+	"sync"
 
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelplanner"
 	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
 )
 
 // HotelPlannerProvider implements Provider interface for HotelPlanner
-// CONTOH: Betapa mudahnya menambah provider baru!
-// Cukup implement interface Provider, dan otomatis terintegrasi ke sistem.
 type HotelPlannerProvider struct {
-	apiKey    string
-	apiSecret string
-	baseURL   string
-	timeout   time.Duration
+	api hotelplanner.ClientInterface
 }
 
 // NewHotelPlannerProvider creates a new HotelPlanner provider
 func NewHotelPlannerProvider(apiKey, apiSecret, baseURL string) *HotelPlannerProvider {
-	return &HotelPlannerProvider{
-		apiKey:    apiKey,
-		apiSecret: apiSecret,
-		baseURL:   baseURL,
-		timeout:   30 * time.Second,
-	}
+	return NewHotelPlannerProviderWithClient(hotelplanner.NewClient(apiKey, apiSecret, baseURL))
+}
+
+// NewHotelPlannerProviderWithClient creates a HotelPlanner provider on an
+// existing client
+func NewHotelPlannerProviderWithClient(client hotelplanner.ClientInterface) *HotelPlannerProvider {
+	return &HotelPlannerProvider{api: client}
 }
 
 // Name returns the provider name
@@ -36,88 +36,266 @@ func (h *HotelPlannerProvider) Name() string {
 
 // SearchAvailability searches for available hotels on HotelPlanner
 func (h *HotelPlannerProvider) SearchAvailability(ctx context.Context, req *types.AvailabilityRequest) (*types.AvailabilityResponse, error) {
-	logger.Infof("Searching HotelPlanner availability for %s", req.City)
-
-	// TODO: Implement actual HotelPlanner API call
-	// Format response ke canonical models
-	mockResponse := &types.AvailabilityResponse{
-		Hotels: []types.HotelAvailability{
-			{
-				Hotel: types.Hotel{
-					ID:          "HP-98765",
-					Name:        "Planner Hotel (HotelPlanner)",
-					CountryCode: "ID",
-					City:        req.City,
-					Rating:      4.0,
-				},
-				Rooms: []types.RoomRate{
-					{
-						Room: types.Room{
-							ID:       "HP-ROOM-1",
-							HotelID:  "HP-98765",
-							Name:     "Standard Room",
-							Capacity: 2,
-						},
-						Rates: []types.Rate{
-							{
-								RoomID:    "HP-ROOM-1",
-								NetPrice:  1200000,
-								Currency:  "IDR",
-								Allotment: 5,
-							},
-						},
-					},
-				},
-			},
-		},
+	resp, err := h.api.Search(ctx, &hotelplanner.SearchRequest{
+		City:        req.City,
+		CountryCode: req.Country,
+		CheckIn:     hotelplanner.Date(req.CheckIn),
+		CheckOut:    hotelplanner.Date(req.CheckOut),
+		Adults:      req.Guests,
+		Rooms:       1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.AvailabilityResponse{}
+	for _, hotel := range resp.Hotels {
+		availability := h.toAvailability(&hotel)
+		if len(availability.Rooms) > 0 {
+			response.Hotels = append(response.Hotels, *availability)
+		}
 	}
 
-	return mockResponse, nil
+	logger.Infof("HotelPlanner search complete: %d hotels in %s", len(response.Hotels), req.City)
+	return response, nil
 }
 
 // GetHotelDetails retrieves hotel details from HotelPlanner
 func (h *HotelPlannerProvider) GetHotelDetails(ctx context.Context, hotelID string) (*types.Hotel, error) {
-	logger.Infof("Getting HotelPlanner hotel details: %s", hotelID)
-	// TODO: Implement actual HotelPlanner API call
-	return &types.Hotel{
-		ID:     hotelID,
-		Name:   "Sample Hotel from HotelPlanner",
-		City:   "Bali",
-		Rating: 4.0,
-	}, nil
+	hotel, err := h.api.GetHotel(ctx, hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := toPlannerHotel(hotel)
+	return &result, nil
+}
+
+// CheckAvailability checks a HotelPlanner hotel's rooms for a stay
+func (h *HotelPlannerProvider) CheckAvailability(ctx context.Context, req *types.RoomRequest) (*types.HotelAvailability, error) {
+	hotel, err := h.api.CheckAvailability(ctx, &hotelplanner.AvailabilityRequest{
+		HotelID:  req.HotelID,
+		RoomID:   req.RoomID,
+		CheckIn:  hotelplanner.Date(req.CheckIn),
+		CheckOut: hotelplanner.Date(req.CheckOut),
+		Adults:   req.Guests,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return h.toAvailability(hotel), nil
+}
+
+// maxPlannerChecks caps the HotelPlanner hotels checked at once
+const maxPlannerChecks = 10
+
+// CheckHotelsAvailability checks several HotelPlanner hotels' rooms for a
+// stay. HotelPlanner checks one hotel per request, so the hotels are checked
+// side by side; the batch fails if any check fails.
+func (h *HotelPlannerProvider) CheckHotelsAvailability(ctx context.Context, req *types.HotelsRequest) ([]types.HotelAvailability, error) {
+	results := make([]*types.HotelAvailability, len(req.HotelIDs))
+	errs := make([]error, len(req.HotelIDs))
+	sem := make(chan struct{}, maxPlannerChecks)
+	var wg sync.WaitGroup
+	for i, hotelID := range req.HotelIDs {
+		wg.Add(1)
+		go func(i int, hotelID string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = h.CheckAvailability(ctx, &types.RoomRequest{
+				HotelID:  hotelID,
+				CheckIn:  req.CheckIn,
+				CheckOut: req.CheckOut,
+				Guests:   req.Guests,
+			})
+		}(i, hotelID)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	hotels := make([]types.HotelAvailability, 0, len(results))
+	for _, availability := range results {
+		if len(availability.Rooms) > 0 {
+			hotels = append(hotels, *availability)
+		}
+	}
+	return hotels, nil
+}
+
+// GetRoomRate prices a HotelPlanner room for a stay, returning its cheapest
+// bookable rate
+func (h *HotelPlannerProvider) GetRoomRate(ctx context.Context, req *types.RoomRequest) (*types.Rate, error) {
+	availability, err := h.CheckAvailability(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var cheapest *types.Rate
+	for _, room := range availability.Rooms {
+		if room.Room.ID != req.RoomID {
+			continue
+		}
+		for i := range room.Rates {
+			if cheapest == nil || room.Rates[i].NetPrice < cheapest.NetPrice {
+				cheapest = &room.Rates[i]
+			}
+		}
+	}
+	if cheapest == nil {
+		return nil, fmt.Errorf("%w: room %s is not available at HotelPlanner hotel %s", ErrRejected, req.RoomID, req.HotelID)
+	}
+
+	return cheapest, nil
 }
 
 // CreateBooking creates a booking on HotelPlanner
 func (h *HotelPlannerProvider) CreateBooking(ctx context.Context, req *types.BookingRequest) (*types.BookingConfirmation, error) {
-	logger.Infof("Creating booking on HotelPlanner for hotel %s", req.HotelID)
-	// TODO: Implement actual HotelPlanner booking API call
-	confirmation := &types.BookingConfirmation{
-		BookingID:         fmt.Sprintf("HP-%d", time.Now().Unix()),
-		ProviderReference: fmt.Sprintf("HPL-%d", time.Now().Unix()),
-		Status:            "CONFIRMED",
-		TotalPrice:        req.Rate.NetPrice,
-		Currency:          req.Rate.Currency,
+	paymentMethod := hotelplanner.PaymentPrepaid
+	if req.PayAtHotel {
+		paymentMethod = hotelplanner.PaymentAtHotel
 	}
-	return confirmation, nil
+
+	booking, err := h.api.CreateBooking(ctx, &hotelplanner.BookingRequest{
+		HotelID:  req.HotelID,
+		RoomID:   req.RoomID,
+		RateID:   req.Rate.RateKey,
+		CheckIn:  hotelplanner.Date(req.CheckIn),
+		CheckOut: hotelplanner.Date(req.CheckOut),
+		Adults:   req.Guests,
+		Guest: hotelplanner.Guest{
+			FirstName: req.GuestInfo.FirstName,
+			LastName:  req.GuestInfo.LastName,
+			Email:     req.GuestInfo.Email,
+			Phone:     req.GuestInfo.Phone,
+		},
+		PaymentMethod:   paymentMethod,
+		ClientReference: req.Reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status == hotelplanner.StatusFailed {
+		return nil, fmt.Errorf("%w: HotelPlanner booking %s failed", ErrRejected, booking.BookingID)
+	}
+
+	logger.Infof("HotelPlanner booking created: %s (%s)", booking.BookingID, booking.Status)
+
+	return &types.BookingConfirmation{
+		BookingID:         booking.BookingID,
+		ProviderReference: booking.BookingID,
+		CheckIn:           req.CheckIn,
+		CheckOut:          req.CheckOut,
+		Status:            toPlannerStatus(booking.Status),
+		TotalPrice:        toAmount(booking.Total.Amount),
+		Currency:          booking.Total.Currency,
+	}, nil
 }
 
 // CancelBooking cancels a booking on HotelPlanner
 func (h *HotelPlannerProvider) CancelBooking(ctx context.Context, bookingID string) error {
-	logger.Infof("Cancelling HotelPlanner booking: %s", bookingID)
-	// TODO: Implement actual HotelPlanner cancellation API call
+	booking, err := h.api.CancelBooking(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+	if booking.Status != hotelplanner.StatusCancelled {
+		return fmt.Errorf("HotelPlanner booking %s is %s after cancellation", bookingID, booking.Status)
+	}
 	return nil
 }
 
 // GetBookingStatus retrieves booking status from HotelPlanner
 func (h *HotelPlannerProvider) GetBookingStatus(ctx context.Context, bookingID string) (string, error) {
-	logger.Infof("Getting HotelPlanner booking status: %s", bookingID)
-	// TODO: Implement actual HotelPlanner status API call
-	return "CONFIRMED", nil
+	booking, err := h.api.GetBooking(ctx, bookingID)
+	if err != nil {
+		return "", err
+	}
+	return toPlannerStatus(booking.Status), nil
 }
 
 // HealthCheck checks if HotelPlanner is healthy
 func (h *HotelPlannerProvider) HealthCheck(ctx context.Context) error {
-	// TODO: Implement actual health check (ping HotelPlanner API)
-	logger.Infof("Health check for HotelPlanner")
-	return nil
+	return h.api.Ping(ctx)
+}
+
+// toAvailability maps a HotelPlanner hotel and its rooms, keeping only rooms
+// with at least one rate
+func (h *HotelPlannerProvider) toAvailability(hotel *hotelplanner.Hotel) *types.HotelAvailability {
+	availability := &types.HotelAvailability{
+		Hotel:    toPlannerHotel(hotel),
+		Provider: h.Name(),
+	}
+
+	for _, room := range hotel.Rooms {
+		if len(room.Rates) == 0 {
+			continue
+		}
+		roomRate := types.RoomRate{
+			Room: types.Room{
+				ID:       room.RoomID,
+				HotelID:  hotel.HotelID,
+				Name:     room.Name,
+				Capacity: room.MaxOccupancy,
+				BedType:  room.BedType,
+			},
+		}
+		for _, rate := range room.Rates {
+			roomRate.Rates = append(roomRate.Rates, toPlannerRate(room.RoomID, rate))
+		}
+		availability.Rooms = append(availability.Rooms, roomRate)
+	}
+
+	return availability
+}
+
+// toPlannerHotel maps HotelPlanner hotel content to the canonical hotel
+func toPlannerHotel(hotel *hotelplanner.Hotel) types.Hotel {
+	result := types.Hotel{
+		ID:          hotel.HotelID,
+		Name:        hotel.Name,
+		CountryCode: hotel.CountryCode,
+		City:        hotel.City,
+		Address:     hotel.Address,
+		Latitude:    hotel.Latitude,
+		Longitude:   hotel.Longitude,
+		Rating:      hotel.StarRating,
+		Description: hotel.Description,
+		Amenities:   hotel.Amenities,
+	}
+	for _, image := range hotel.Images {
+		result.Images = append(result.Images, image.URL)
+	}
+	return result
+}
+
+// toPlannerRate maps a HotelPlanner rate to the canonical rate
+func toPlannerRate(roomID string, rate hotelplanner.Rate) types.Rate {
+	result := types.Rate{
+		RoomID:    roomID,
+		RateKey:   rate.RateID,
+		NetPrice:  toAmount(rate.NetAmount),
+		Currency:  rate.Currency,
+		Allotment: rate.RoomsLeft,
+		MealPlan:  rate.MealPlan,
+		Cancellation: types.CancellationPolicy{
+			NonRefundable: !rate.Refundable,
+		},
+	}
+	if rate.FreeCancellationUntil != nil {
+		result.Cancellation.FreeCancellationBefore = *rate.FreeCancellationUntil
+	}
+	return result
+}
+
+// toPlannerStatus maps HotelPlanner's lower-case statuses to ours
+func toPlannerStatus(status string) string {
+	return strings.ToUpper(status)
+}
+
+// toAmount rounds a HotelPlanner amount to whole currency units
+func toAmount(amount float64) int {
+	return int(math.Round(amount))
 }
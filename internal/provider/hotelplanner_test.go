@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelplanner"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
+	"github.com/ekonugroho98/be-bookingkuy/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHotelPlanner(t *testing.T) (*HotelPlannerProvider, *testutil.MockHotelPlannerServer) {
+	server := testutil.NewMockHotelPlannerServer(t)
+	return NewHotelPlannerProvider(testutil.HotelPlannerTestAPIKey, testutil.HotelPlannerTestSecret, server.URL()), server
+}
+
+func plannerStay() (time.Time, time.Time) {
+	checkIn := time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC)
+	return checkIn, checkIn.AddDate(0, 0, 2)
+}
+
+func TestHotelPlanner_SearchAvailability(t *testing.T) {
+	p, server := newTestHotelPlanner(t)
+	checkIn, checkOut := plannerStay()
+
+	resp, err := p.SearchAvailability(context.Background(), &types.AvailabilityRequest{
+		City: "Bali", Country: "ID", CheckIn: checkIn, CheckOut: checkOut, Guests: 2,
+	})
+	require.NoError(t, err)
+
+	// The hotel without rates is left out
+	require.Len(t, resp.Hotels, 1)
+	hotel := resp.Hotels[0]
+	assert.Equal(t, "hotelplanner", hotel.Provider)
+	assert.Equal(t, "HP-1001", hotel.Hotel.ID)
+	assert.Equal(t, "Grand Hyatt Bali", hotel.Hotel.Name)
+	assert.Equal(t, 5.0, hotel.Hotel.Rating)
+	require.Len(t, hotel.Rooms, 1)
+	assert.Equal(t, "HP-1001-DLX", hotel.Rooms[0].Room.ID)
+	assert.Equal(t, 2, hotel.Rooms[0].Room.Capacity)
+	require.Len(t, hotel.Rooms[0].Rates, 2)
+
+	refundable := hotel.Rooms[0].Rates[0]
+	assert.Equal(t, 2450000, refundable.NetPrice)
+	assert.Equal(t, "BB", refundable.MealPlan)
+	assert.Equal(t, 4, refundable.Allotment)
+	assert.Equal(t, "RT-88231", refundable.RateKey)
+	assert.False(t, refundable.Cancellation.NonRefundable)
+	assert.Equal(t, time.Date(2026, 11, 18, 12, 0, 0, 0, time.UTC), refundable.Cancellation.FreeCancellationBefore)
+	assert.True(t, hotel.Rooms[0].Rates[1].Cancellation.NonRefundable)
+
+	body := server.LastBody(http.MethodPost, "/v1/hotels/search")
+	assert.Equal(t, "Bali", body["city"])
+	assert.Equal(t, "2026-11-20", body["check_in"])
+	assert.Equal(t, "2026-11-22", body["check_out"])
+	assert.Equal(t, float64(2), body["adults"])
+}
+
+func TestHotelPlanner_GetHotelDetails(t *testing.T) {
+	p, _ := newTestHotelPlanner(t)
+
+	hotel, err := p.GetHotelDetails(context.Background(), "HP-1001")
+	require.NoError(t, err)
+	assert.Equal(t, "Grand Hyatt Bali", hotel.Name)
+	assert.Equal(t, -8.8004, hotel.Latitude)
+	assert.Contains(t, hotel.Amenities, "Spa")
+	assert.Equal(t, []string{
+		"https://images.hotelplanner.test/HP-1001/exterior.jpg",
+		"https://images.hotelplanner.test/HP-1001/pool.jpg",
+	}, hotel.Images)
+
+	_, err = p.GetHotelDetails(context.Background(), "HP-404")
+	var apiErr *hotelplanner.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "NOT_FOUND", apiErr.Code)
+	assert.False(t, hotelplanner.IsRetryable(err))
+}
+
+func TestHotelPlanner_GetRoomRateReturnsCheapestRate(t *testing.T) {
+	p, _ := newTestHotelPlanner(t)
+	checkIn, checkOut := plannerStay()
+	req := &types.RoomRequest{HotelID: "HP-1001", RoomID: "HP-1001-DLX", CheckIn: checkIn, CheckOut: checkOut, Guests: 2}
+
+	availability, err := p.CheckAvailability(context.Background(), req)
+	require.NoError(t, err)
+	assert.Len(t, availability.Rooms, 2)
+
+	rate, err := p.GetRoomRate(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 2150000, rate.NetPrice)
+	assert.Equal(t, "HP-1001-DLX", rate.RoomID)
+	assert.Equal(t, "RT-88232", rate.RateKey)
+
+	req.RoomID = "HP-1001-NONE"
+	_, err = p.GetRoomRate(context.Background(), req)
+	assert.Error(t, err)
+}
+
+func TestHotelPlanner_BookingLifecycle(t *testing.T) {
+	p, server := newTestHotelPlanner(t)
+	ctx := context.Background()
+	checkIn, checkOut := plannerStay()
+
+	confirmation, err := p.CreateBooking(ctx, &types.BookingRequest{
+		HotelID:    "HP-1001",
+		RoomID:     "HP-1001-DLX",
+		CheckIn:    checkIn,
+		CheckOut:   checkOut,
+		Guests:     2,
+		GuestInfo:  types.GuestInfo{FirstName: "Budi", LastName: "Santoso", Email: "budi@example.com"},
+		Rate:       types.Rate{RoomID: "HP-1001-DLX", RateKey: "RT-88231"},
+		PayAtHotel: true,
+		Reference:  "BKG-1a2b3c4d",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "HPB-5001", confirmation.ProviderReference)
+	assert.Equal(t, "CONFIRMED", confirmation.Status)
+	assert.Equal(t, 2450000, confirmation.TotalPrice)
+	assert.Equal(t, "IDR", confirmation.Currency)
+
+	body := server.LastBody(http.MethodPost, "/v1/bookings")
+	assert.Equal(t, "at_hotel", body["payment_method"])
+	assert.Equal(t, "RT-88231", body["rate_id"])
+	assert.Equal(t, "BKG-1a2b3c4d", body["client_reference"])
+	assert.Equal(t, "budi@example.com", body["guest"].(map[string]interface{})["email"])
+
+	status, err := p.GetBookingStatus(ctx, "HPB-5001")
+	require.NoError(t, err)
+	assert.Equal(t, "CONFIRMED", status)
+
+	require.NoError(t, p.CancelBooking(ctx, "HPB-5001"))
+	require.NoError(t, p.HealthCheck(ctx))
+
+	// One token serves every call
+	assert.Equal(t, 1, server.TokenRequests())
+}
+
+func TestHotelPlanner_RefreshesRevokedToken(t *testing.T) {
+	p, server := newTestHotelPlanner(t)
+	ctx := context.Background()
+
+	require.NoError(t, p.HealthCheck(ctx))
+	server.RevokeTokens()
+	require.NoError(t, p.HealthCheck(ctx))
+
+	assert.Equal(t, 2, server.TokenRequests())
+	assert.Equal(t, []string{
+		"POST /v1/auth/token", "GET /v1/ping",
+		"GET /v1/ping", "POST /v1/auth/token", "GET /v1/ping",
+	}, server.Requests())
+}
+
+func TestHotelPlanner_InvalidCredentials(t *testing.T) {
+	server := testutil.NewMockHotelPlannerServer(t)
+	p := NewHotelPlannerProvider("wrong-key", testutil.HotelPlannerTestSecret, server.URL())
+
+	err := p.HealthCheck(context.Background())
+	var apiErr *hotelplanner.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "INVALID_CLIENT", apiErr.Code)
+	assert.False(t, hotelplanner.IsRetryable(err))
+}
+
+func TestHotelPlanner_ClassifiesServerErrors(t *testing.T) {
+	p, server := newTestHotelPlanner(t)
+	ctx := context.Background()
+	req := &types.RoomRequest{HotelID: "HP-1001", Guests: 2}
+	req.CheckIn, req.CheckOut = plannerStay()
+
+	server.FailNext(http.MethodPost, "/v1/hotels/HP-1001/availability", http.StatusServiceUnavailable, "upstream unavailable")
+	_, err := p.CheckAvailability(ctx, req)
+	require.Error(t, err)
+	assert.True(t, hotelplanner.IsRetryable(err))
+
+	server.FailNext(http.MethodPost, "/v1/hotels/HP-1001/availability", http.StatusUnprocessableEntity,
+		`{"error": {"code": "INVALID_DATES", "message": "check_out must be after check_in"}}`)
+	_, err = p.CheckAvailability(ctx, req)
+	require.Error(t, err)
+	assert.False(t, hotelplanner.IsRetryable(err))
+	assert.Contains(t, err.Error(), "INVALID_DATES")
+
+	// The queued failures are used up
+	_, err = p.CheckAvailability(ctx, req)
+	assert.NoError(t, err)
+}
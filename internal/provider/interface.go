@@ -19,6 +19,16 @@ type Provider interface {
 	// GetHotelDetails retrieves detailed hotel information
 	GetHotelDetails(ctx context.Context, hotelID string) (*types.Hotel, error)
 
+	// CheckAvailability checks one hotel's rooms for a stay; no rooms means nothing is available
+	CheckAvailability(ctx context.Context, req *types.RoomRequest) (*types.HotelAvailability, error)
+
+	// CheckHotelsAvailability checks several hotels' rooms for a stay at once,
+	// returning the hotels with rooms available
+	CheckHotelsAvailability(ctx context.Context, req *types.HotelsRequest) ([]types.HotelAvailability, error)
+
+	// GetRoomRate prices a room for the whole stay
+	GetRoomRate(ctx context.Context, req *types.RoomRequest) (*types.Rate, error)
+
 	// CreateBooking creates a booking with the provider
 	CreateBooking(ctx context.Context, req *types.BookingRequest) (*types.BookingConfirmation, error)
 
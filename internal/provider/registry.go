@@ -2,30 +2,78 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/metrics"
 )
 
+// DefaultSearchTimeout bounds a provider's search when its config sets no timeout
+const DefaultSearchTimeout = 10 * time.Second
+
+// ErrBreakerOpen is returned for a provider skipped because its circuit breaker is open
+var ErrBreakerOpen = errors.New("circuit breaker open")
+
+// Deduplicator collapses offers for the same physical hotel from different providers
+type Deduplicator interface {
+	Deduplicate(ctx context.Context, hotels []types.HotelAvailability) ([]types.HotelAvailability, error)
+}
+
 // Registry manages provider instances
 type Registry struct {
-	providers map[string]Provider
+	providers    map[string]Provider
+	configs      map[string]Config
+	deduplicator Deduplicator
+
+	mu      sync.Mutex
+	metrics map[string]*Metrics
+
+	breakerConfig BreakerConfig
+	breakers      map[string]*circuitBreaker
+
+	rulesMu sync.RWMutex
+	rules   map[string][]*RoutingRule // By provider code
+	intn    func(n int) int
 }
 
 // NewRegistry creates a new provider registry
 func NewRegistry() *Registry {
 	return &Registry{
 		providers: make(map[string]Provider),
+		configs:   make(map[string]Config),
+		metrics:   make(map[string]*Metrics),
+		rules:     make(map[string][]*RoutingRule),
+		intn:      rand.Intn,
+
+		breakerConfig: DefaultBreakerConfig(),
+		breakers:      make(map[string]*circuitBreaker),
 	}
 }
 
-// Register adds a provider to the registry
+// SetBreakerConfig sets the circuit breaker thresholds of providers registered afterwards
+func (r *Registry) SetBreakerConfig(config BreakerConfig) {
+	r.breakerConfig = config
+}
+
+// Register adds an enabled provider with the default timeout to the registry
 // Ini yang membuat menambah provider baru jadi sangat mudah!
 func (r *Registry) Register(provider Provider) {
+	r.RegisterWithConfig(provider, Config{Enabled: true})
+}
+
+// RegisterWithConfig adds a provider with its configuration to the registry
+func (r *Registry) RegisterWithConfig(provider Provider, config Config) {
 	r.providers[provider.Name()] = provider
-	logger.Infof("Provider registered: %s", provider.Name())
+	r.configs[provider.Name()] = config
+	r.breakers[provider.Name()] = newCircuitBreaker(provider.Name(), r.breakerConfig)
+	logger.Infof("Provider registered: %s (enabled: %t)", provider.Name(), config.Enabled)
 }
 
 // Get retrieves a provider by name
@@ -37,6 +85,12 @@ func (r *Registry) Get(name string) (Provider, error) {
 	return provider, nil
 }
 
+// GetConfig returns a registered provider's configuration
+func (r *Registry) GetConfig(name string) (Config, bool) {
+	config, exists := r.configs[name]
+	return config, exists
+}
+
 // GetAll returns all registered providers
 func (r *Registry) GetAll() []Provider {
 	providers := make([]Provider, 0, len(r.providers))
@@ -46,56 +100,394 @@ func (r *Registry) GetAll() []Provider {
 	return providers
 }
 
-// GetHealthy returns all healthy providers
+// SetDeduplicator sets how SearchAll collapses the same hotel sold by several providers
+func (r *Registry) SetDeduplicator(d Deduplicator) {
+	r.deduplicator = d
+}
+
+// GetEnabled returns all enabled providers sorted by name
+func (r *Registry) GetEnabled() []Provider {
+	enabled := make([]Provider, 0, len(r.providers))
+	for name, provider := range r.providers {
+		if r.configs[name].Enabled {
+			enabled = append(enabled, provider)
+		}
+	}
+	sort.Slice(enabled, func(i, j int) bool {
+		return enabled[i].Name() < enabled[j].Name()
+	})
+	return enabled
+}
+
+// GetHealthy returns all providers whose circuit breaker lets calls through.
+// Health comes from recent call outcomes and background probes, so no
+// provider is called here.
 func (r *Registry) GetHealthy(ctx context.Context) []Provider {
-	healthy := make([]Provider, 0)
-	for _, provider := range r.providers {
-		if err := provider.HealthCheck(ctx); err == nil {
+	return r.healthy(r.GetAll())
+}
+
+// GetByPriority returns healthy providers in the order their default routing
+// rules try them
+// Ini untuk implementasi cheap-first strategy!
+func (r *Registry) GetByPriority(ctx context.Context) []Provider {
+	return r.healthy(r.Route(RouteTarget{}))
+}
+
+// healthy filters providers down to the ones whose breaker lets calls through, keeping their order
+func (r *Registry) healthy(providers []Provider) []Provider {
+	healthy := make([]Provider, 0, len(providers))
+	for _, provider := range providers {
+		if r.breakers[provider.Name()].available() {
 			healthy = append(healthy, provider)
-		} else {
-			logger.Warnf("Provider %s is unhealthy: %v", provider.Name(), err)
 		}
 	}
 	return healthy
 }
 
-// GetByPriority returns providers sorted by priority
-// Ini untuk implementasi cheap-first strategy!
-func (r *Registry) GetByPriority(ctx context.Context) []Provider {
-	healthy := r.GetHealthy(ctx)
+// ProbeHealth runs every provider's health check concurrently and feeds the
+// results to their circuit breakers. It is meant to run in the background.
+func (r *Registry) ProbeHealth(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, provider := range r.GetAll() {
+		wg.Add(1)
+		go func(provider Provider) {
+			defer wg.Done()
 
-	// Sort by priority (lower = higher priority)
-	sort.Slice(healthy, func(i, j int) bool {
-		// TODO: Add priority field to Provider interface
-		// For now, just return in insertion order
-		return healthy[i].Name() < healthy[j].Name()
-	})
+			timeout := r.configs[provider.Name()].Timeout
+			if timeout <= 0 {
+				timeout = DefaultSearchTimeout
+			}
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
 
-	return healthy
+			err := provider.HealthCheck(probeCtx)
+			if err != nil && ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				logger.Warnf("Provider %s is unhealthy: %v", provider.Name(), err)
+			}
+			r.breakers[provider.Name()].recordProbe(err)
+		}(provider)
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// Health returns each provider's circuit breaker state and health score
+func (r *Registry) Health() map[string]HealthStatus {
+	health := make(map[string]HealthStatus, len(r.breakers))
+	for name, b := range r.breakers {
+		health[name] = b.status()
+	}
+	return health
+}
+
+// ProviderHealth returns one provider's circuit breaker state and health score
+func (r *Registry) ProviderHealth(name string) (HealthStatus, error) {
+	b, exists := r.breakers[name]
+	if !exists {
+		return HealthStatus{}, ErrProviderNotFound
+	}
+	return b.status(), nil
+}
+
+// OverrideBreaker forces a provider's circuit breaker open or closed until the
+// given time, or with OverrideNone hands it back to call outcomes
+func (r *Registry) OverrideBreaker(name string, override BreakerOverride, until time.Time) error {
+	b, exists := r.breakers[name]
+	if !exists {
+		return ErrProviderNotFound
+	}
+	if err := b.setOverride(override, until); err != nil {
+		return err
+	}
+
+	logger.Infof("🔌 Circuit breaker override for %s set to %q until %s", name, override, until.Format(time.RFC3339))
+	return nil
+}
+
+// SetOverrides replaces the breaker overrides, e.g. after loading them from the
+// database. Providers without a stored override follow call outcomes.
+func (r *Registry) SetOverrides(overrides []*StoredOverride) {
+	byProvider := make(map[string]*StoredOverride, len(overrides))
+	for _, o := range overrides {
+		byProvider[o.ProviderCode] = o
+	}
+
+	for name, b := range r.breakers {
+		o, ok := byProvider[name]
+		if !ok {
+			_ = b.setOverride(OverrideNone, time.Time{})
+			continue
+		}
+		if err := b.setOverride(o.Override, o.ExpiresAt); err != nil {
+			logger.Warnf("Ignoring stored breaker override %q for %s: %v", o.Override, name, err)
+		}
+	}
+}
+
+// SetRules replaces the routing rules, e.g. after loading them from the database
+func (r *Registry) SetRules(rules []*RoutingRule) {
+	byProvider := make(map[string][]*RoutingRule)
+	for _, rule := range rules {
+		byProvider[rule.ProviderCode] = append(byProvider[rule.ProviderCode], rule)
+	}
+
+	r.rulesMu.Lock()
+	r.rules = byProvider
+	r.rulesMu.Unlock()
+}
+
+// Rules returns a provider's routing rules
+func (r *Registry) Rules(name string) []*RoutingRule {
+	r.rulesMu.RLock()
+	defer r.rulesMu.RUnlock()
+	return append([]*RoutingRule(nil), r.rules[name]...)
+}
+
+// routes returns the providers a target may use with the rule that applies
+// to each, sorted by priority. Providers disabled in their configuration or
+// by the applicable rule are left out; without a rule a provider keeps its
+// configured priority and the default weight.
+func (r *Registry) routes(target RouteTarget) []route {
+	r.rulesMu.RLock()
+	defer r.rulesMu.RUnlock()
+
+	routes := make([]route, 0, len(r.providers))
+	for name, provider := range r.providers {
+		config := r.configs[name]
+		if !config.Enabled {
+			continue
+		}
+
+		rt := route{provider: provider, priority: config.Priority, weight: DefaultWeight}
+		if rule := resolveRule(r.rules[name], target); rule != nil {
+			if !rule.Enabled {
+				continue
+			}
+			rt.priority, rt.weight = rule.Priority, rule.Weight
+		}
+		routes = append(routes, rt)
+	}
+
+	sortRoutes(routes)
+	return routes
 }
 
-// SearchAll searches all providers and aggregates results
+// Route returns the providers to try for a target, in order: by priority,
+// with equal priorities shuffled by traffic weight
+func (r *Registry) Route(target RouteTarget) []Provider {
+	return orderRoutes(r.routes(target), r.intn)
+}
+
+// SearchAll searches all providers the routing rules enable for the
+// destination concurrently and merges their results in priority order. Each
+// provider gets its own timeout, so a slow or failing provider only loses
+// its own hotels, and providers with an open circuit breaker are skipped; an
+// error is returned only when every provider fails. The response reports each
+// provider's latency and error. With a deduplicator set, a hotel sold by
+// several providers appears once.
 func (r *Registry) SearchAll(ctx context.Context, req *types.AvailabilityRequest) (*types.AvailabilityResponse, error) {
-	healthyProviders := r.GetHealthy(ctx)
-	if len(healthyProviders) == 0 {
-		return nil, fmt.Errorf("no healthy providers available")
+	routes := r.routes(RouteTarget{CountryCode: req.Country, Destination: req.City})
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("no enabled providers available")
+	}
+
+	providers := make([]Provider, len(routes))
+	for i, rt := range routes {
+		providers[i] = rt.provider
+	}
+
+	type searchResult struct {
+		resp  *types.AvailabilityResponse
+		stats types.ProviderStats
+	}
+
+	results := make([]searchResult, len(providers))
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func(i int, provider Provider) {
+			defer wg.Done()
+			resp, stats := r.search(ctx, provider, req)
+			results[i] = searchResult{resp: resp, stats: stats}
+		}(i, provider)
+	}
+	wg.Wait()
+
+	merged := &types.AvailabilityResponse{
+		Hotels:    []types.HotelAvailability{},
+		Providers: make([]types.ProviderStats, 0, len(results)),
+	}
+	var failures []string
+	for _, result := range results {
+		merged.Providers = append(merged.Providers, result.stats)
+		if result.stats.Error != "" {
+			failures = append(failures, result.stats.Provider+": "+result.stats.Error)
+			continue
+		}
+		for _, hotel := range result.resp.Hotels {
+			if hotel.Provider == "" {
+				hotel.Provider = result.stats.Provider
+			}
+			merged.Hotels = append(merged.Hotels, hotel)
+		}
+	}
+
+	if len(failures) == len(providers) {
+		return nil, fmt.Errorf("all providers failed: %s", strings.Join(failures, "; "))
+	}
+	if len(failures) > 0 {
+		logger.Warnf("Partial search results, %d of %d providers failed: %s",
+			len(failures), len(providers), strings.Join(failures, "; "))
 	}
 
-	// Search all providers in parallel
-	// TODO: Implement parallel search with goroutines
-	// For now, just use the first healthy provider
-	provider := healthyProviders[0]
-	return provider.SearchAvailability(ctx, req)
+	if r.deduplicator != nil {
+		hotels, err := r.deduplicator.Deduplicate(ctx, merged.Hotels)
+		if err != nil {
+			logger.Warnf("Failed to deduplicate search results, returning them as is: %v", err)
+		} else {
+			merged.Hotels = hotels
+		}
+	}
+
+	return merged, nil
 }
 
-// CreateBookingWithFallback creates booking with automatic failover
+// search runs one provider's search within its timeout and records its outcome
+func (r *Registry) search(ctx context.Context, provider Provider, req *types.AvailabilityRequest) (*types.AvailabilityResponse, types.ProviderStats) {
+	if !r.breakers[provider.Name()].allow() {
+		return nil, types.ProviderStats{Provider: provider.Name(), Error: ErrBreakerOpen.Error()}
+	}
+
+	timeout := r.configs[provider.Name()].Timeout
+	if timeout <= 0 {
+		timeout = DefaultSearchTimeout
+	}
+
+	searchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := searchWithContext(searchCtx, provider, req)
+	latency := time.Since(start)
+
+	if err == nil && resp == nil {
+		err = errors.New("empty response")
+	}
+
+	stats := types.ProviderStats{
+		Provider:  provider.Name(),
+		LatencyMs: latency.Milliseconds(),
+	}
+	if err != nil {
+		stats.Error = err.Error()
+		stats.TimedOut = errors.Is(err, context.DeadlineExceeded)
+	} else {
+		stats.HotelCount = len(resp.Hotels)
+	}
+
+	r.recordCall(ctx, provider.Name(), latency, err)
+	logger.Infof("Provider %s search: %d hotels in %dms", provider.Name(), stats.HotelCount, stats.LatencyMs)
+
+	return resp, stats
+}
+
+// searchWithContext returns when the search does or the context ends,
+// whichever comes first, for providers that don't honour cancellation
+func searchWithContext(ctx context.Context, provider Provider, req *types.AvailabilityRequest) (*types.AvailabilityResponse, error) {
+	type outcome struct {
+		resp *types.AvailabilityResponse
+		err  error
+	}
+
+	done := make(chan outcome, 1)
+	go func() {
+		resp, err := provider.SearchAvailability(ctx, req)
+		done <- outcome{resp: resp, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.resp, o.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("search timed out: %w", ctx.Err())
+	}
+}
+
+// recordCall feeds the outcome of a provider call to its circuit breaker and
+// metrics. Calls cut short because the caller went away say nothing about
+// the provider and are left out. A rejection means the provider answered, so
+// it counts as a successful call.
+func (r *Registry) recordCall(ctx context.Context, name string, latency time.Duration, err error) {
+	if err != nil && ctx.Err() != nil {
+		r.breakers[name].release()
+		return
+	}
+	if IsRejection(err) {
+		err = nil
+	}
+
+	r.breakers[name].record(err)
+	r.recordMetrics(name, latency, err == nil)
+	metrics.RecordProviderCall(name, err == nil)
+}
+
+// recordMetrics adds one call to a provider's running metrics
+func (r *Registry) recordMetrics(name string, latency time.Duration, success bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, exists := r.metrics[name]
+	if !exists {
+		m = &Metrics{}
+		r.metrics[name] = m
+	}
+
+	m.TotalRequests++
+	if success {
+		m.SuccessRequests++
+	} else {
+		m.FailedRequests++
+	}
+	m.AverageResponseTime += (latency - m.AverageResponseTime) / time.Duration(m.TotalRequests)
+}
+
+// Metrics returns a snapshot of each provider's call metrics
+func (r *Registry) Metrics() map[string]Metrics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make(map[string]Metrics, len(r.metrics))
+	for name, m := range r.metrics {
+		snapshot[name] = *m
+	}
+	return snapshot
+}
+
+// CreateBookingWithFallback creates booking with automatic failover, trying
+// providers in the order the routing rules give for the hotel and skipping
+// the ones whose circuit breaker is open
 func (r *Registry) CreateBookingWithFallback(ctx context.Context, req *types.BookingRequest) (*types.BookingConfirmation, error) {
-	providers := r.GetByPriority(ctx)
+	providers := r.Route(RouteTarget{
+		CountryCode: req.CountryCode,
+		Destination: req.Destination,
+		HotelID:     req.HotelID,
+	})
 
 	for _, provider := range providers {
+		if !r.breakers[provider.Name()].allow() {
+			logger.Warnf("Skipping provider %s for booking: %v", provider.Name(), ErrBreakerOpen)
+			continue
+		}
+
 		logger.Infof("Attempting to create booking with provider: %s", provider.Name())
 
+		start := time.Now()
 		confirmation, err := provider.CreateBooking(ctx, req)
+		r.recordCall(ctx, provider.Name(), time.Since(start), err)
 		if err != nil {
 			logger.Warnf("Failed to create booking with %s: %v, trying next provider", provider.Name(), err)
 			continue
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider returns fixed hotels after a delay
+type fakeProvider struct {
+	name   string
+	hotels []string
+	delay  time.Duration
+	err    error
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+
+func (f *fakeProvider) SearchAvailability(ctx context.Context, req *types.AvailabilityRequest) (*types.AvailabilityResponse, error) {
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	resp := &types.AvailabilityResponse{}
+	for _, id := range f.hotels {
+		resp.Hotels = append(resp.Hotels, types.HotelAvailability{Hotel: types.Hotel{ID: id, City: req.City}})
+	}
+	return resp, nil
+}
+
+func (f *fakeProvider) GetHotelDetails(ctx context.Context, hotelID string) (*types.Hotel, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeProvider) CheckAvailability(ctx context.Context, req *types.RoomRequest) (*types.HotelAvailability, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeProvider) CheckHotelsAvailability(ctx context.Context, req *types.HotelsRequest) ([]types.HotelAvailability, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeProvider) GetRoomRate(ctx context.Context, req *types.RoomRequest) (*types.Rate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeProvider) CreateBooking(ctx context.Context, req *types.BookingRequest) (*types.BookingConfirmation, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeProvider) CancelBooking(ctx context.Context, bookingID string) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeProvider) GetBookingStatus(ctx context.Context, bookingID string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (f *fakeProvider) HealthCheck(ctx context.Context) error { return nil }
+
+func hotelIDs(resp *types.AvailabilityResponse) []string {
+	ids := make([]string, 0, len(resp.Hotels))
+	for _, h := range resp.Hotels {
+		ids = append(ids, h.Hotel.ID)
+	}
+	return ids
+}
+
+func TestRegistry_SearchAll_MergesProviders(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&fakeProvider{name: "hotelbeds", hotels: []string{"HB-1", "HB-2"}, delay: 50 * time.Millisecond})
+	registry.Register(&fakeProvider{name: "hotelplanner", hotels: []string{"HP-1"}, delay: 50 * time.Millisecond})
+
+	start := time.Now()
+	resp, err := registry.SearchAll(context.Background(), &types.AvailabilityRequest{City: "Bali"})
+
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 90*time.Millisecond, "providers should be searched concurrently")
+	assert.Equal(t, []string{"HB-1", "HB-2", "HP-1"}, hotelIDs(resp))
+	require.Len(t, resp.Providers, 2)
+	assert.Equal(t, "hotelbeds", resp.Providers[0].Provider)
+	assert.Equal(t, 2, resp.Providers[0].HotelCount)
+	assert.Equal(t, 1, resp.Providers[1].HotelCount)
+}
+
+func TestRegistry_SearchAll_PartialResults(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&fakeProvider{name: "hotelbeds", hotels: []string{"HB-1"}})
+	registry.RegisterWithConfig(&fakeProvider{name: "hotelplanner", hotels: []string{"HP-1"}, delay: time.Second},
+		Config{Enabled: true, Timeout: 20 * time.Millisecond})
+	registry.Register(&fakeProvider{name: "expedia", err: errors.New("503 service unavailable")})
+
+	resp, err := registry.SearchAll(context.Background(), &types.AvailabilityRequest{City: "Bali"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HB-1"}, hotelIDs(resp))
+
+	stats := make(map[string]types.ProviderStats)
+	for _, s := range resp.Providers {
+		stats[s.Provider] = s
+	}
+	assert.True(t, stats["hotelplanner"].TimedOut)
+	assert.Less(t, stats["hotelplanner"].LatencyMs, int64(500))
+	assert.Equal(t, "503 service unavailable", stats["expedia"].Error)
+	assert.False(t, stats["expedia"].TimedOut)
+
+	metrics := registry.Metrics()
+	assert.Equal(t, int64(1), metrics["hotelbeds"].SuccessRequests)
+	assert.Equal(t, int64(1), metrics["hotelplanner"].FailedRequests)
+	assert.Equal(t, int64(1), metrics["expedia"].FailedRequests)
+}
+
+func TestRegistry_SearchAll_AllFail(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&fakeProvider{name: "hotelbeds", err: errors.New("401 unauthorized")})
+	registry.RegisterWithConfig(&fakeProvider{name: "hotelplanner", hotels: []string{"HP-1"}}, Config{Enabled: false})
+
+	_, err := registry.SearchAll(context.Background(), &types.AvailabilityRequest{City: "Bali"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hotelbeds: 401 unauthorized")
+}
+
+func TestRegistry_SearchAll_NoProviders(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterWithConfig(&fakeProvider{name: "hotelbeds"}, Config{Enabled: false})
+
+	_, err := registry.SearchAll(context.Background(), &types.AvailabilityRequest{City: "Bali"})
+
+	assert.Error(t, err)
+}
+
+// keepFirst is a deduplicator that keeps the first offer in each city
+type keepFirst struct{}
+
+func (keepFirst) Deduplicate(ctx context.Context, hotels []types.HotelAvailability) ([]types.HotelAvailability, error) {
+	seen := make(map[string]bool)
+	var out []types.HotelAvailability
+	for _, h := range hotels {
+		if !seen[h.Hotel.City] {
+			seen[h.Hotel.City] = true
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+func TestRegistry_SearchAll_Deduplicates(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&fakeProvider{name: "hotelbeds", hotels: []string{"HB-1"}})
+	registry.Register(&fakeProvider{name: "hotelplanner", hotels: []string{"HP-1"}})
+	registry.SetDeduplicator(keepFirst{})
+
+	resp, err := registry.SearchAll(context.Background(), &types.AvailabilityRequest{City: "Bali"})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Hotels, 1)
+	assert.Equal(t, "hotelbeds", resp.Hotels[0].Provider, "offers are tagged with their provider")
+}
@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrProviderNotFound is returned for a provider code that isn't registered
+var ErrProviderNotFound = errors.New("provider not found")
+
+// ErrInvalidRule is returned for a routing rule that can't be applied
+var ErrInvalidRule = errors.New("invalid routing rule")
+
+// RuleScope is what a routing rule applies to. A more specific scope wins:
+// hotel over destination over country over the provider default.
+type RuleScope string
+
+const (
+	ScopeDefault     RuleScope = "DEFAULT"
+	ScopeCountry     RuleScope = "COUNTRY"     // Value is an ISO 3166-1 alpha-2 code
+	ScopeDestination RuleScope = "DESTINATION" // Value is a city or destination name
+	ScopeHotel       RuleScope = "HOTEL"       // Value is the hotel ID
+)
+
+// scopeRank orders scopes from least to most specific
+var scopeRank = map[RuleScope]int{
+	ScopeDefault:     0,
+	ScopeCountry:     1,
+	ScopeDestination: 2,
+	ScopeHotel:       3,
+}
+
+// RoutingRule sets how a provider is used for part of the inventory
+type RoutingRule struct {
+	ID           string    `json:"id" db:"id"`
+	ProviderCode string    `json:"provider_code" db:"provider_code"`
+	Scope        RuleScope `json:"scope" db:"scope"`
+	ScopeValue   string    `json:"scope_value,omitempty" db:"scope_value"`
+	Priority     int       `json:"priority" db:"priority"` // Lower = tried first
+	Weight       int       `json:"weight" db:"weight"`     // Share of traffic among providers of equal priority
+	Enabled      bool      `json:"enabled" db:"enabled"`
+	UpdatedBy    string    `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NewRoutingRule creates a routing rule with its scope value normalized
+func NewRoutingRule(providerCode string, scope RuleScope, scopeValue string, priority, weight int, enabled bool) (*RoutingRule, error) {
+	scope = RuleScope(strings.ToUpper(string(scope)))
+	if _, ok := scopeRank[scope]; !ok {
+		return nil, ErrInvalidRule
+	}
+	if priority < 0 || weight < 0 {
+		return nil, ErrInvalidRule
+	}
+
+	scopeValue = normalizeScopeValue(scope, scopeValue)
+	if (scope == ScopeDefault) != (scopeValue == "") {
+		return nil, ErrInvalidRule
+	}
+
+	now := time.Now()
+	return &RoutingRule{
+		ID:           uuid.New().String(),
+		ProviderCode: providerCode,
+		Scope:        scope,
+		ScopeValue:   scopeValue,
+		Priority:     priority,
+		Weight:       weight,
+		Enabled:      enabled,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+func normalizeScopeValue(scope RuleScope, value string) string {
+	value = strings.TrimSpace(value)
+	switch scope {
+	case ScopeCountry:
+		return strings.ToUpper(value)
+	case ScopeDestination:
+		return strings.ToLower(value)
+	default:
+		return value
+	}
+}
+
+// RouteTarget is what a search or booking is for. Empty fields match no
+// scoped rule.
+type RouteTarget struct {
+	CountryCode string
+	Destination string
+	HotelID     string
+}
+
+// matches reports whether a rule applies to the target
+func (t RouteTarget) matches(rule *RoutingRule) bool {
+	switch rule.Scope {
+	case ScopeDefault:
+		return true
+	case ScopeCountry:
+		return t.CountryCode != "" && rule.ScopeValue == normalizeScopeValue(ScopeCountry, t.CountryCode)
+	case ScopeDestination:
+		return t.Destination != "" && rule.ScopeValue == normalizeScopeValue(ScopeDestination, t.Destination)
+	case ScopeHotel:
+		return t.HotelID != "" && rule.ScopeValue == t.HotelID
+	}
+	return false
+}
+
+// DefaultWeight is the traffic weight of a provider no rule sets a weight for
+const DefaultWeight = 100
+
+// route is a provider with the rule that applies to it for one target
+type route struct {
+	provider Provider
+	priority int
+	weight   int
+}
+
+// resolveRule returns the most specific rule of a provider for the target,
+// or nil when none applies
+func resolveRule(rules []*RoutingRule, target RouteTarget) *RoutingRule {
+	var best *RoutingRule
+	for _, rule := range rules {
+		if target.matches(rule) && (best == nil || scopeRank[rule.Scope] > scopeRank[best.Scope]) {
+			best = rule
+		}
+	}
+	return best
+}
+
+// sortRoutes sorts routes by priority, then by name
+func sortRoutes(routes []route) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].priority != routes[j].priority {
+			return routes[i].priority < routes[j].priority
+		}
+		return routes[i].provider.Name() < routes[j].provider.Name()
+	})
+}
+
+// orderRoutes orders sorted routes for trying one after another. Providers of
+// equal priority are shuffled by weight, so each is tried first in
+// proportion to its weight; providers with no weight only serve as
+// fallbacks. intn returns a random number in [0, n).
+func orderRoutes(routes []route, intn func(n int) int) []Provider {
+	ordered := make([]Provider, 0, len(routes))
+	for start := 0; start < len(routes); {
+		end := start
+		for end < len(routes) && routes[end].priority == routes[start].priority {
+			end++
+		}
+		ordered = append(ordered, weightedOrder(routes[start:end], intn)...)
+		start = end
+	}
+	return ordered
+}
+
+// weightedOrder draws providers one by one with probability proportional to weight
+func weightedOrder(group []route, intn func(n int) int) []Provider {
+	remaining := append([]route(nil), group...)
+	ordered := make([]Provider, 0, len(group))
+
+	for len(remaining) > 0 {
+		total := 0
+		for _, r := range remaining {
+			total += r.weight
+		}
+		if total == 0 {
+			for _, r := range remaining {
+				ordered = append(ordered, r.provider)
+			}
+			break
+		}
+
+		pick := intn(total)
+		for i, r := range remaining {
+			if pick < r.weight {
+				ordered = append(ordered, r.provider)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+			pick -= r.weight
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ProviderRouting is how a provider is routed: its defaults, the rules that
+// override them for a country, destination or hotel, and its circuit breaker
+type ProviderRouting struct {
+	Code           string         `json:"provider_code"`
+	Configured     bool           `json:"configured"` // Enabled in the service configuration; a provider that isn't is never used
+	Enabled        bool           `json:"enabled"`
+	Priority       int            `json:"priority"`
+	Weight         int            `json:"weight"`
+	BaseURL        string         `json:"base_url,omitempty"`
+	TimeoutSeconds int            `json:"timeout_seconds,omitempty"`
+	Rules          []*RoutingRule `json:"rules"`
+	Health         HealthStatus   `json:"health"`
+}
+
+// RuleInput is a scoped routing rule as edited by an admin
+type RuleInput struct {
+	Scope      RuleScope `json:"scope"`
+	ScopeValue string    `json:"scope_value"`
+	Priority   int       `json:"priority"`
+	Weight     int       `json:"weight"`
+	Enabled    bool      `json:"enabled"`
+}
+
+// RoutingUpdate changes a provider's defaults and, when Rules is set,
+// replaces its scoped rules
+type RoutingUpdate struct {
+	Enabled  *bool
+	Priority *int
+	Weight   *int
+	Rules    *[]RuleInput
+}
+
+// RoutingService defines interface for managing provider routing
+type RoutingService interface {
+	// LoadRules loads the routing rules and breaker overrides from the database
+	// into the registry. Every instance runs it periodically so changes saved
+	// elsewhere apply everywhere.
+	LoadRules(ctx context.Context) error
+	ListProviders(ctx context.Context) ([]*ProviderRouting, error)
+	GetProvider(ctx context.Context, code string) (*ProviderRouting, error)
+	UpdateProvider(ctx context.Context, code string, update *RoutingUpdate, updatedBy string) (*ProviderRouting, error)
+	// OverrideBreaker forces a provider's circuit breaker open or closed for a
+	// duration (DefaultOverrideDuration when zero) on every instance
+	OverrideBreaker(ctx context.Context, code string, override BreakerOverride, duration time.Duration, updatedBy string) (*ProviderRouting, error)
+}
+
+type routingService struct {
+	registry *Registry
+	repo     RulesRepository
+}
+
+// NewRoutingService creates a new provider routing service
+func NewRoutingService(registry *Registry, repo RulesRepository) RoutingService {
+	return &routingService{
+		registry: registry,
+		repo:     repo,
+	}
+}
+
+// LoadRules replaces the registry's rules and breaker overrides with the stored ones
+func (s *routingService) LoadRules(ctx context.Context) error {
+	rules, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	overrides, err := s.repo.ListOverrides(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.registry.SetRules(rules)
+	s.registry.SetOverrides(overrides)
+	return nil
+}
+
+// ListProviders returns the routing of every registered provider
+func (s *routingService) ListProviders(ctx context.Context) ([]*ProviderRouting, error) {
+	providers := s.registry.GetAll()
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name() < providers[j].Name()
+	})
+
+	routings := make([]*ProviderRouting, 0, len(providers))
+	for _, p := range providers {
+		routings = append(routings, s.routing(p.Name()))
+	}
+	return routings, nil
+}
+
+// GetProvider returns the routing of one provider
+func (s *routingService) GetProvider(ctx context.Context, code string) (*ProviderRouting, error) {
+	if _, exists := s.registry.GetConfig(code); !exists {
+		return nil, ErrProviderNotFound
+	}
+	return s.routing(code), nil
+}
+
+// UpdateProvider stores a provider's new routing and applies it right away on this
+// instance; other instances pick it up on their next periodic LoadRules
+func (s *routingService) UpdateProvider(ctx context.Context, code string, update *RoutingUpdate, updatedBy string) (*ProviderRouting, error) {
+	if _, exists := s.registry.GetConfig(code); !exists {
+		return nil, ErrProviderNotFound
+	}
+	current := s.routing(code)
+
+	enabled, priority, weight := current.Enabled, current.Priority, current.Weight
+	if update.Enabled != nil {
+		enabled = *update.Enabled
+	}
+	if update.Priority != nil {
+		priority = *update.Priority
+	}
+	if update.Weight != nil {
+		weight = *update.Weight
+	}
+
+	defaults, err := NewRoutingRule(code, ScopeDefault, "", priority, weight, enabled)
+	if err != nil {
+		return nil, err
+	}
+	rules := []*RoutingRule{defaults}
+
+	if update.Rules == nil {
+		rules = append(rules, current.Rules...)
+	} else {
+		seen := make(map[string]bool)
+		for _, input := range *update.Rules {
+			rule, err := NewRoutingRule(code, input.Scope, input.ScopeValue, input.Priority, input.Weight, input.Enabled)
+			if err != nil {
+				return nil, err
+			}
+			// The provider defaults are set through Enabled, Priority and Weight
+			if rule.Scope == ScopeDefault {
+				return nil, fmt.Errorf("%w: default rule is set through the provider fields", ErrInvalidRule)
+			}
+			key := string(rule.Scope) + ":" + rule.ScopeValue
+			if seen[key] {
+				return nil, fmt.Errorf("%w: duplicate %s rule for %q", ErrInvalidRule, rule.Scope, rule.ScopeValue)
+			}
+			seen[key] = true
+			rules = append(rules, rule)
+		}
+	}
+
+	for _, rule := range rules {
+		rule.UpdatedBy = updatedBy
+	}
+
+	if err := s.repo.ReplaceProviderRules(ctx, code, rules); err != nil {
+		return nil, err
+	}
+	if err := s.LoadRules(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.routing(code), nil
+}
+
+// OverrideBreaker stores a manual circuit breaker state, or clears it with
+// OverrideNone, and applies it right away on this instance; other instances
+// pick it up on their next periodic LoadRules
+func (s *routingService) OverrideBreaker(ctx context.Context, code string, override BreakerOverride, duration time.Duration, updatedBy string) (*ProviderRouting, error) {
+	if _, exists := s.registry.GetConfig(code); !exists {
+		return nil, ErrProviderNotFound
+	}
+
+	switch override {
+	case OverrideNone:
+		if err := s.repo.DeleteOverride(ctx, code); err != nil {
+			return nil, err
+		}
+	case OverrideOpen, OverrideClosed:
+		if duration == 0 {
+			duration = DefaultOverrideDuration
+		}
+		if duration < 0 || duration > MaxOverrideDuration {
+			return nil, fmt.Errorf("%w: duration must be at most %s", ErrInvalidOverride, MaxOverrideDuration)
+		}
+		now := time.Now()
+		if err := s.repo.SaveOverride(ctx, &StoredOverride{
+			ProviderCode: code,
+			Override:     override,
+			ExpiresAt:    now.Add(duration),
+			UpdatedBy:    updatedBy,
+			UpdatedAt:    now,
+		}); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidOverride
+	}
+
+	if err := s.LoadRules(ctx); err != nil {
+		return nil, err
+	}
+	return s.routing(code), nil
+}
+
+// routing builds a provider's routing from its configuration, rules and breaker
+func (s *routingService) routing(code string) *ProviderRouting {
+	config, _ := s.registry.GetConfig(code)
+	health, _ := s.registry.ProviderHealth(code)
+
+	routing := &ProviderRouting{
+		Code:           code,
+		Configured:     config.Enabled,
+		Enabled:        true,
+		Priority:       config.Priority,
+		Weight:         DefaultWeight,
+		BaseURL:        config.BaseURL,
+		TimeoutSeconds: int(config.Timeout.Seconds()),
+		Rules:          []*RoutingRule{},
+		Health:         health,
+	}
+
+	for _, rule := range s.registry.Rules(code) {
+		if rule.Scope == ScopeDefault {
+			routing.Enabled, routing.Priority, routing.Weight = rule.Enabled, rule.Priority, rule.Weight
+			continue
+		}
+		routing.Rules = append(routing.Rules, rule)
+	}
+
+	sort.Slice(routing.Rules, func(i, j int) bool {
+		if routing.Rules[i].Scope != routing.Rules[j].Scope {
+			return scopeRank[routing.Rules[i].Scope] < scopeRank[routing.Rules[j].Scope]
+		}
+		return routing.Rules[i].ScopeValue < routing.Rules[j].ScopeValue
+	})
+	return routing
+}
@@ -0,0 +1,280 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bookingProvider records booking attempts and fails when told to
+type bookingProvider struct {
+	fakeProvider
+	fail     bool
+	attempts *[]string
+}
+
+func (b *bookingProvider) CreateBooking(ctx context.Context, req *types.BookingRequest) (*types.BookingConfirmation, error) {
+	*b.attempts = append(*b.attempts, b.name)
+	if b.fail {
+		return nil, errors.New("sold out")
+	}
+	return &types.BookingConfirmation{ProviderReference: b.name + "-ref"}, nil
+}
+
+// memoryRulesRepository keeps rules and overrides in memory
+type memoryRulesRepository struct {
+	rules     []*RoutingRule
+	overrides map[string]*StoredOverride
+}
+
+func (m *memoryRulesRepository) List(ctx context.Context) ([]*RoutingRule, error) {
+	return m.rules, nil
+}
+
+func (m *memoryRulesRepository) ReplaceProviderRules(ctx context.Context, providerCode string, rules []*RoutingRule) error {
+	kept := rules
+	for _, rule := range m.rules {
+		if rule.ProviderCode != providerCode {
+			kept = append(kept, rule)
+		}
+	}
+	m.rules = kept
+	return nil
+}
+
+func (m *memoryRulesRepository) ListOverrides(ctx context.Context) ([]*StoredOverride, error) {
+	var overrides []*StoredOverride
+	for _, o := range m.overrides {
+		if o.ExpiresAt.After(time.Now()) {
+			overrides = append(overrides, o)
+		}
+	}
+	return overrides, nil
+}
+
+func (m *memoryRulesRepository) SaveOverride(ctx context.Context, override *StoredOverride) error {
+	if m.overrides == nil {
+		m.overrides = make(map[string]*StoredOverride)
+	}
+	m.overrides[override.ProviderCode] = override
+	return nil
+}
+
+func (m *memoryRulesRepository) DeleteOverride(ctx context.Context, providerCode string) error {
+	delete(m.overrides, providerCode)
+	return nil
+}
+
+func mustRule(t *testing.T, code string, scope RuleScope, value string, priority, weight int, enabled bool) *RoutingRule {
+	t.Helper()
+	rule, err := NewRoutingRule(code, scope, value, priority, weight, enabled)
+	require.NoError(t, err)
+	return rule
+}
+
+func names(providers []Provider) []string {
+	out := make([]string, len(providers))
+	for i, p := range providers {
+		out[i] = p.Name()
+	}
+	return out
+}
+
+func TestNewRoutingRule(t *testing.T) {
+	rule, err := NewRoutingRule("hotelbeds", "country", " id ", 1, 50, true)
+	require.NoError(t, err)
+	assert.Equal(t, ScopeCountry, rule.Scope)
+	assert.Equal(t, "ID", rule.ScopeValue)
+
+	rule, err = NewRoutingRule("hotelbeds", ScopeDestination, "Bali", 1, 50, true)
+	require.NoError(t, err)
+	assert.Equal(t, "bali", rule.ScopeValue)
+
+	for _, tc := range []struct {
+		name  string
+		scope RuleScope
+		value string
+		prio  int
+		wt    int
+	}{
+		{"unknown scope", "REGION", "asia", 0, 100},
+		{"default with value", ScopeDefault, "ID", 0, 100},
+		{"scoped without value", ScopeHotel, " ", 0, 100},
+		{"negative priority", ScopeDefault, "", -1, 100},
+		{"negative weight", ScopeDefault, "", 0, -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewRoutingRule("hotelbeds", tc.scope, tc.value, tc.prio, tc.wt, true)
+			assert.ErrorIs(t, err, ErrInvalidRule)
+		})
+	}
+}
+
+func TestResolveRule_MostSpecificWins(t *testing.T) {
+	defaults := mustRule(t, "hb", ScopeDefault, "", 5, 100, true)
+	country := mustRule(t, "hb", ScopeCountry, "ID", 3, 100, true)
+	destination := mustRule(t, "hb", ScopeDestination, "bali", 1, 100, true)
+	hotel := mustRule(t, "hb", ScopeHotel, "hotel-1", 0, 100, false)
+	rules := []*RoutingRule{hotel, destination, country, defaults}
+
+	assert.Equal(t, defaults, resolveRule(rules, RouteTarget{}))
+	assert.Equal(t, country, resolveRule(rules, RouteTarget{CountryCode: "id"}))
+	assert.Equal(t, destination, resolveRule(rules, RouteTarget{CountryCode: "ID", Destination: "Bali"}))
+	assert.Equal(t, hotel, resolveRule(rules, RouteTarget{CountryCode: "ID", Destination: "Bali", HotelID: "hotel-1"}))
+	assert.Nil(t, resolveRule([]*RoutingRule{country}, RouteTarget{CountryCode: "SG"}))
+}
+
+func TestRegistry_Route(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterWithConfig(&fakeProvider{name: "a"}, Config{Enabled: true, Priority: 2})
+	r.RegisterWithConfig(&fakeProvider{name: "b"}, Config{Enabled: true, Priority: 1})
+	r.RegisterWithConfig(&fakeProvider{name: "c"}, Config{Enabled: true, Priority: 3})
+	r.RegisterWithConfig(&fakeProvider{name: "off"}, Config{Enabled: false})
+
+	// Without rules providers follow their configured priority
+	assert.Equal(t, []string{"b", "a", "c"}, names(r.Route(RouteTarget{})))
+
+	r.SetRules([]*RoutingRule{
+		mustRule(t, "a", ScopeCountry, "ID", 0, 100, true),
+		mustRule(t, "c", ScopeDestination, "bali", 0, 100, false),
+		mustRule(t, "off", ScopeDefault, "", 0, 100, true),
+	})
+
+	assert.Equal(t, []string{"b", "a", "c"}, names(r.Route(RouteTarget{CountryCode: "SG"})))
+	assert.Equal(t, []string{"a", "b", "c"}, names(r.Route(RouteTarget{CountryCode: "ID"})))
+	// A disabled rule keeps the provider out, a rule can't enable an unconfigured provider
+	assert.Equal(t, []string{"a", "b"}, names(r.Route(RouteTarget{CountryCode: "ID", Destination: "Bali"})))
+}
+
+func TestRegistry_Route_WeightedWithinPriority(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterWithConfig(&fakeProvider{name: "a"}, Config{Enabled: true})
+	r.RegisterWithConfig(&fakeProvider{name: "b"}, Config{Enabled: true})
+	r.RegisterWithConfig(&fakeProvider{name: "fallback"}, Config{Enabled: true})
+	r.SetRules([]*RoutingRule{
+		mustRule(t, "a", ScopeDefault, "", 0, 20, true),
+		mustRule(t, "b", ScopeDefault, "", 0, 80, true),
+		mustRule(t, "fallback", ScopeDefault, "", 0, 0, true),
+	})
+
+	// Draws below a's weight of 20 pick a, the rest pick b
+	r.intn = func(n int) int { return 10 }
+	assert.Equal(t, []string{"a", "b", "fallback"}, names(r.Route(RouteTarget{})))
+
+	r.intn = func(n int) int { return n - 1 }
+	assert.Equal(t, []string{"b", "a", "fallback"}, names(r.Route(RouteTarget{})))
+}
+
+func TestRegistry_CreateBookingWithFallback_FollowsRules(t *testing.T) {
+	var attempts []string
+	r := NewRegistry()
+	r.RegisterWithConfig(&bookingProvider{fakeProvider: fakeProvider{name: "hotelbeds"}, fail: true, attempts: &attempts}, Config{Enabled: true, Priority: 1})
+	r.RegisterWithConfig(&bookingProvider{fakeProvider: fakeProvider{name: "hotelplanner"}, attempts: &attempts}, Config{Enabled: true, Priority: 2})
+	r.SetRules([]*RoutingRule{
+		mustRule(t, "hotelplanner", ScopeHotel, "hotel-9", 0, 100, false),
+	})
+
+	confirmation, err := r.CreateBookingWithFallback(context.Background(), &types.BookingRequest{HotelID: "hotel-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "hotelplanner-ref", confirmation.ProviderReference)
+	assert.Equal(t, []string{"hotelbeds", "hotelplanner"}, attempts)
+
+	// hotelplanner is switched off for this hotel, so nothing is left to fall back to
+	attempts = nil
+	_, err = r.CreateBookingWithFallback(context.Background(), &types.BookingRequest{HotelID: "hotel-9"})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"hotelbeds"}, attempts)
+}
+
+func TestRoutingService_UpdateProvider(t *testing.T) {
+	ctx := context.Background()
+	r := NewRegistry()
+	r.RegisterWithConfig(&fakeProvider{name: "hotelbeds"}, Config{Enabled: true, Priority: 1})
+	repo := &memoryRulesRepository{rules: []*RoutingRule{
+		mustRule(t, "hotelbeds", ScopeCountry, "SG", 0, 100, false),
+	}}
+	svc := NewRoutingService(r, repo)
+	require.NoError(t, svc.LoadRules(ctx))
+
+	routing, err := svc.GetProvider(ctx, "hotelbeds")
+	require.NoError(t, err)
+	assert.True(t, routing.Enabled)
+	assert.Equal(t, 1, routing.Priority)
+	assert.Equal(t, DefaultWeight, routing.Weight)
+	require.Len(t, routing.Rules, 1)
+
+	// Changing the defaults keeps the scoped rules
+	weight := 40
+	routing, err = svc.UpdateProvider(ctx, "hotelbeds", &RoutingUpdate{Weight: &weight}, "ops@bookingkuy.com")
+	require.NoError(t, err)
+	assert.Equal(t, 40, routing.Weight)
+	assert.Equal(t, 1, routing.Priority)
+	require.Len(t, routing.Rules, 1)
+	assert.Equal(t, "SG", routing.Rules[0].ScopeValue)
+	assert.Equal(t, "ops@bookingkuy.com", routing.Rules[0].UpdatedBy)
+
+	// Rules replace the scoped rules and apply to routing right away
+	rules := []RuleInput{{Scope: ScopeDestination, ScopeValue: "Bali", Priority: 0, Weight: 100, Enabled: false}}
+	routing, err = svc.UpdateProvider(ctx, "hotelbeds", &RoutingUpdate{Rules: &rules}, "ops@bookingkuy.com")
+	require.NoError(t, err)
+	require.Len(t, routing.Rules, 1)
+	assert.Equal(t, ScopeDestination, routing.Rules[0].Scope)
+	assert.Empty(t, r.Route(RouteTarget{Destination: "bali"}))
+	assert.Len(t, r.Route(RouteTarget{CountryCode: "SG"}), 1)
+
+	duplicate := []RuleInput{
+		{Scope: ScopeCountry, ScopeValue: "id", Weight: 100, Enabled: true},
+		{Scope: ScopeCountry, ScopeValue: "ID", Weight: 100, Enabled: true},
+	}
+	_, err = svc.UpdateProvider(ctx, "hotelbeds", &RoutingUpdate{Rules: &duplicate}, "ops@bookingkuy.com")
+	assert.ErrorIs(t, err, ErrInvalidRule)
+
+	_, err = svc.UpdateProvider(ctx, "unknown", &RoutingUpdate{Weight: &weight}, "ops@bookingkuy.com")
+	assert.ErrorIs(t, err, ErrProviderNotFound)
+}
+
+func TestRoutingService_OverrideBreakerAppliesToEveryInstance(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryRulesRepository{}
+	newInstance := func() (*Registry, RoutingService) {
+		r := NewRegistry()
+		r.RegisterWithConfig(&fakeProvider{name: "hotelbeds"}, Config{Enabled: true, Priority: 1})
+		svc := NewRoutingService(r, repo)
+		require.NoError(t, svc.LoadRules(ctx))
+		return r, svc
+	}
+	_, first := newInstance()
+	other, otherSvc := newInstance()
+
+	routing, err := first.OverrideBreaker(ctx, "hotelbeds", OverrideOpen, 0, "ops@bookingkuy.com")
+	require.NoError(t, err)
+	assert.Equal(t, OverrideOpen, routing.Health.Override)
+	require.NotNil(t, routing.Health.OverrideExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(DefaultOverrideDuration), *routing.Health.OverrideExpiresAt, time.Minute)
+	assert.Equal(t, "ops@bookingkuy.com", repo.overrides["hotelbeds"].UpdatedBy)
+
+	// Another instance applies the stored override on its next reload
+	assert.Equal(t, OverrideNone, other.Health()["hotelbeds"].Override)
+	require.NoError(t, otherSvc.LoadRules(ctx))
+	assert.Equal(t, OverrideOpen, other.Health()["hotelbeds"].Override)
+	assert.Empty(t, other.GetHealthy(ctx))
+
+	// Handing the breaker back clears it everywhere
+	_, err = first.OverrideBreaker(ctx, "hotelbeds", OverrideNone, 0, "ops@bookingkuy.com")
+	require.NoError(t, err)
+	require.NoError(t, otherSvc.LoadRules(ctx))
+	assert.Equal(t, OverrideNone, other.Health()["hotelbeds"].Override)
+	assert.Len(t, other.GetHealthy(ctx), 1)
+
+	_, err = first.OverrideBreaker(ctx, "hotelbeds", OverrideClosed, MaxOverrideDuration+time.Minute, "ops@bookingkuy.com")
+	assert.ErrorIs(t, err, ErrInvalidOverride)
+	_, err = first.OverrideBreaker(ctx, "hotelbeds", "HALF", time.Hour, "ops@bookingkuy.com")
+	assert.ErrorIs(t, err, ErrInvalidOverride)
+	_, err = first.OverrideBreaker(ctx, "unknown", OverrideOpen, time.Hour, "ops@bookingkuy.com")
+	assert.ErrorIs(t, err, ErrProviderNotFound)
+}
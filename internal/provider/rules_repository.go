@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+)
+
+// RulesRepository defines interface for routing rule and breaker override data operations
+type RulesRepository interface {
+	List(ctx context.Context) ([]*RoutingRule, error)
+	// ReplaceProviderRules swaps all rules of a provider for the given ones
+	ReplaceProviderRules(ctx context.Context, providerCode string, rules []*RoutingRule) error
+	// ListOverrides returns the breaker overrides that have not expired yet
+	ListOverrides(ctx context.Context) ([]*StoredOverride, error)
+	SaveOverride(ctx context.Context, override *StoredOverride) error
+	DeleteOverride(ctx context.Context, providerCode string) error
+}
+
+type rulesRepository struct {
+	db *db.DB
+}
+
+// NewRulesRepository creates a new routing rules repository
+func NewRulesRepository(database *db.DB) RulesRepository {
+	return &rulesRepository{
+		db: database,
+	}
+}
+
+// List retrieves all routing rules
+func (r *rulesRepository) List(ctx context.Context) ([]*RoutingRule, error) {
+	query := `
+		SELECT id, provider_code, scope, scope_value, priority, weight, enabled,
+			COALESCE(updated_by, ''), created_at, updated_at
+		FROM provider_routing_rules
+		ORDER BY provider_code, scope, scope_value
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routing rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*RoutingRule
+	for rows.Next() {
+		var rule RoutingRule
+		if err := rows.Scan(
+			&rule.ID, &rule.ProviderCode, &rule.Scope, &rule.ScopeValue, &rule.Priority, &rule.Weight,
+			&rule.Enabled, &rule.UpdatedBy, &rule.CreatedAt, &rule.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan routing rule: %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// ReplaceProviderRules replaces a provider's rules in one transaction
+func (r *rulesRepository) ReplaceProviderRules(ctx context.Context, providerCode string, rules []*RoutingRule) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM provider_routing_rules WHERE provider_code = $1`, providerCode); err != nil {
+		return fmt.Errorf("failed to delete routing rules: %w", err)
+	}
+
+	query := `
+		INSERT INTO provider_routing_rules (
+			id, provider_code, scope, scope_value, priority, weight, enabled, updated_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10)
+	`
+	for _, rule := range rules {
+		if _, err := tx.Exec(ctx, query,
+			rule.ID, rule.ProviderCode, rule.Scope, rule.ScopeValue, rule.Priority, rule.Weight,
+			rule.Enabled, rule.UpdatedBy, rule.CreatedAt, rule.UpdatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to create routing rule: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListOverrides retrieves the breaker overrides that are still in force
+func (r *rulesRepository) ListOverrides(ctx context.Context) ([]*StoredOverride, error) {
+	query := `
+		SELECT provider_code, override, expires_at, COALESCE(updated_by, ''), updated_at
+		FROM provider_breaker_overrides
+		WHERE expires_at > NOW()
+		ORDER BY provider_code
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list breaker overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*StoredOverride
+	for rows.Next() {
+		var o StoredOverride
+		if err := rows.Scan(&o.ProviderCode, &o.Override, &o.ExpiresAt, &o.UpdatedBy, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan breaker override: %w", err)
+		}
+		overrides = append(overrides, &o)
+	}
+
+	return overrides, rows.Err()
+}
+
+// SaveOverride creates or replaces a provider's breaker override
+func (r *rulesRepository) SaveOverride(ctx context.Context, override *StoredOverride) error {
+	query := `
+		INSERT INTO provider_breaker_overrides (provider_code, override, expires_at, updated_by, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		ON CONFLICT (provider_code) DO UPDATE SET
+			override = EXCLUDED.override,
+			expires_at = EXCLUDED.expires_at,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query,
+		override.ProviderCode, override.Override, override.ExpiresAt, override.UpdatedBy, override.UpdatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to save breaker override: %w", err)
+	}
+	return nil
+}
+
+// DeleteOverride removes a provider's breaker override, if any
+func (r *rulesRepository) DeleteOverride(ctx context.Context, providerCode string) error {
+	if _, err := r.db.Pool.Exec(ctx, `DELETE FROM provider_breaker_overrides WHERE provider_code = $1`, providerCode); err != nil {
+		return fmt.Errorf("failed to delete breaker override: %w", err)
+	}
+	return nil
+}
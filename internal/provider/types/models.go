@@ -37,6 +37,8 @@ type Rate struct {
 	Allotment    int       `json:"allotment"`    // Number of rooms available
 	MealPlan     string    `json:"meal_plan,omitempty"` // BB, HB, FB, AI
 	Cancellation CancellationPolicy `json:"cancellation"`
+	RateKey      string    `json:"rate_key,omitempty"`  // The provider's key for booking this rate
+	RateType     string    `json:"rate_type,omitempty"` // Provider rate type, e.g. HotelBeds BOOKABLE or RECHECK
 }
 
 // CancellationPolicy represents cancellation policy
@@ -58,13 +60,25 @@ type AvailabilityRequest struct {
 
 // AvailabilityResponse represents availability search response
 type AvailabilityResponse struct {
-	Hotels []HotelAvailability `json:"hotels"`
+	Hotels    []HotelAvailability `json:"hotels"`
+	Providers []ProviderStats     `json:"providers,omitempty"` // Set when several providers were searched
+}
+
+// ProviderStats reports how one provider did in a fan-out search
+type ProviderStats struct {
+	Provider   string `json:"provider"`
+	LatencyMs  int64  `json:"latency_ms"`
+	HotelCount int    `json:"hotel_count"`
+	Error      string `json:"error,omitempty"`
+	TimedOut   bool   `json:"timed_out,omitempty"`
 }
 
 // HotelAvailability represents hotel with available rooms
 type HotelAvailability struct {
-	Hotel Hotel        `json:"hotel"`
-	Rooms []RoomRate   `json:"rooms"`
+	Hotel         Hotel      `json:"hotel"`
+	Rooms         []RoomRate `json:"rooms"`
+	Provider      string     `json:"provider,omitempty"`        // Provider the offer came from
+	MasterHotelID string     `json:"master_hotel_id,omitempty"` // Set when the hotel is mapped across providers
 }
 
 // RoomRate represents room with rate
@@ -73,6 +87,24 @@ type RoomRate struct {
 	Rates []Rate `json:"rates"`
 }
 
+// RoomRequest identifies one hotel's rooms for a stay, for availability and rate checks
+type RoomRequest struct {
+	HotelID  string    `json:"hotel_id"`          // The provider's hotel code
+	RoomID   string    `json:"room_id,omitempty"` // Empty for all rooms
+	CheckIn  time.Time `json:"check_in"`
+	CheckOut time.Time `json:"check_out"`
+	Guests   int       `json:"guests"`
+}
+
+// HotelsRequest identifies several hotels of one provider for a stay, to
+// check their availability together
+type HotelsRequest struct {
+	HotelIDs []string  `json:"hotel_ids"` // The provider's hotel codes
+	CheckIn  time.Time `json:"check_in"`
+	CheckOut time.Time `json:"check_out"`
+	Guests   int       `json:"guests"`
+}
+
 // BookingRequest represents booking request
 type BookingRequest struct {
 	HotelID    string    `json:"hotel_id"`
@@ -82,6 +114,13 @@ type BookingRequest struct {
 	Guests     int       `json:"guests"`
 	GuestInfo  GuestInfo `json:"guest_info"`
 	Rate       Rate      `json:"rate"`
+
+	PayAtHotel bool      `json:"pay_at_hotel,omitempty"` // The guest pays the hotel instead of us paying the provider
+	Reference  string    `json:"reference,omitempty"`    // Our booking reference, sent so providers can spot repeated requests
+
+	// Where the hotel is, for routing rules by country and destination
+	CountryCode string `json:"country_code,omitempty"`
+	Destination string `json:"destination,omitempty"`
 }
 
 // GuestInfo represents guest information
@@ -101,6 +140,6 @@ type BookingConfirmation struct {
 	CheckIn           time.Time `json:"check_in"`
 	CheckOut          time.Time `json:"check_out"`
 	Status            string    `json:"status"`
-	TotalPrice        int       `json:"total_price"`
+	TotalPrice        int       `json:"total_price"` // Net the provider charges us, as confirmed
 	Currency          string    `json:"currency"`
 }
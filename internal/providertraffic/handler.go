@@ -0,0 +1,50 @@
+package providertraffic
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Handler handles admin HTTP requests for captured provider traffic
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new provider traffic handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// ListForBooking handles GET /admin/bookings/{id}/provider-traffic
+func (h *Handler) ListForBooking(w http.ResponseWriter, r *http.Request) {
+	bookingID := r.PathValue("id")
+
+	exchanges, err := h.service.ListForBooking(r.Context(), bookingID)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to list provider traffic")
+		respondWithError(w, http.StatusInternalServerError, "Failed to list provider traffic")
+		return
+	}
+	if exchanges == nil {
+		exchanges = []*Exchange{}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"booking_id": bookingID,
+		"exchanges":  exchanges,
+	})
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
+
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	respondWithJSON(w, status, map[string]string{"error": message})
+}
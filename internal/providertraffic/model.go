@@ -0,0 +1,57 @@
+package providertraffic
+
+import (
+	"context"
+	"time"
+)
+
+// Exchange is one captured provider HTTP request and its response, with
+// credentials and card data redacted
+type Exchange struct {
+	ID              string            `json:"id" db:"id"`
+	ProviderCode    string            `json:"provider_code" db:"provider_code"`
+	CorrelationID   string            `json:"correlation_id,omitempty" db:"correlation_id"`
+	BookingID       string            `json:"booking_id,omitempty" db:"booking_id"`
+	Method          string            `json:"method" db:"method"`
+	URL             string            `json:"url" db:"url"`
+	RequestHeaders  map[string]string `json:"request_headers" db:"request_headers"`
+	RequestBody     string            `json:"request_body,omitempty" db:"request_body"`
+	StatusCode      int               `json:"status_code,omitempty" db:"status_code"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty" db:"response_headers"`
+	ResponseBody    string            `json:"response_body,omitempty" db:"response_body"`
+	Error           string            `json:"error,omitempty" db:"error"`
+	DurationMs      int64             `json:"duration_ms" db:"duration_ms"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+}
+
+// Config controls traffic capture. Capture is opt-in.
+type Config struct {
+	Enabled       bool
+	RetentionDays int // Exchanges older than this are purged
+	MaxBodyBytes  int // Longer bodies are truncated
+	QueueSize     int // Exchanges waiting to be stored; more are dropped
+}
+
+// DefaultConfig returns the default capture configuration
+func DefaultConfig() Config {
+	return Config{
+		Enabled:       false,
+		RetentionDays: 14,
+		MaxBodyBytes:  64 << 10,
+		QueueSize:     1000,
+	}
+}
+
+type bookingIDKey struct{}
+
+// WithBookingID tags provider calls made with ctx with a booking, so their
+// traffic can be found from the booking
+func WithBookingID(ctx context.Context, bookingID string) context.Context {
+	return context.WithValue(ctx, bookingIDKey{}, bookingID)
+}
+
+// BookingIDFromContext returns the booking tagged by WithBookingID
+func BookingIDFromContext(ctx context.Context) string {
+	bookingID, _ := ctx.Value(bookingIDKey{}).(string)
+	return bookingID
+}
@@ -0,0 +1,148 @@
+package providertraffic
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces secret values in captured traffic
+const Redacted = "[REDACTED]"
+
+// sensitiveHeaders carry credentials or signatures
+var sensitiveHeaders = map[string]bool{
+	"Api-Key":             true,
+	"X-Signature":         true,
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// sensitiveFields are JSON and query keys holding credentials or card data,
+// lowercased without separators
+var sensitiveFields = map[string]bool{
+	"password":     true,
+	"secret":       true,
+	"clientsecret": true,
+	"apikey":       true,
+	"accesstoken":  true,
+	"refreshtoken": true,
+	"token":        true,
+	"cardnumber":   true,
+	"pan":          true,
+	"cardcvc":      true,
+	"cvc":          true,
+	"cvv":          true,
+	"cvv2":         true,
+	"cardexpiry":   true,
+	"expirydate":   true,
+}
+
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer("_", "", "-", "").Replace(name)
+	return sensitiveFields[name]
+}
+
+// cardNumberPattern matches 13-19 digits, optionally grouped by spaces or dashes
+var cardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// RedactHeaders copies headers, replacing credentials
+func RedactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for name, values := range header {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			redacted[name] = Redacted
+			continue
+		}
+		redacted[name] = strings.Join(values, ", ")
+	}
+	return redacted
+}
+
+// RedactURL replaces sensitive query parameters
+func RedactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	query := redacted.Query()
+	changed := false
+	for key := range query {
+		if isSensitiveField(key) {
+			query.Set(key, Redacted)
+			changed = true
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
+
+// RedactBody replaces sensitive fields of JSON bodies, then masks anything
+// that looks like a card number in what remains
+func RedactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if decoder.Decode(&decoded) == nil {
+		if data, err := json.Marshal(redactValue(decoded)); err == nil {
+			body = data
+		}
+	}
+
+	return maskCardNumbers(string(body))
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitiveField(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return value
+}
+
+// maskCardNumbers keeps the last four digits of Luhn-valid card numbers
+func maskCardNumbers(text string) string {
+	return cardNumberPattern.ReplaceAllStringFunc(text, func(match string) string {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(match)
+		if !luhnValid(digits) {
+			return match
+		}
+		return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+	})
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,103 @@
+package providertraffic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+)
+
+// Repository defines interface for captured traffic data operations
+type Repository interface {
+	Create(ctx context.Context, exchange *Exchange) error
+	ListByBooking(ctx context.Context, bookingID string) ([]*Exchange, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type repository struct {
+	db *db.DB
+}
+
+// NewRepository creates a new captured traffic repository
+func NewRepository(database *db.DB) Repository {
+	return &repository{
+		db: database,
+	}
+}
+
+// Create stores a captured exchange
+func (r *repository) Create(ctx context.Context, e *Exchange) error {
+	requestHeaders, err := json.Marshal(e.RequestHeaders)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request headers: %w", err)
+	}
+	responseHeaders, err := json.Marshal(e.ResponseHeaders)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response headers: %w", err)
+	}
+
+	query := `
+		INSERT INTO provider_exchanges (
+			id, provider_code, correlation_id, booking_id, method, url, request_headers, request_body,
+			status_code, response_headers, response_body, error, duration_ms, created_at
+		) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')::uuid, $5, $6, $7, NULLIF($8, ''),
+			NULLIF($9, 0), $10, NULLIF($11, ''), NULLIF($12, ''), $13, $14)
+	`
+
+	_, err = r.db.Pool.Exec(ctx, query,
+		e.ID, e.ProviderCode, e.CorrelationID, e.BookingID, e.Method, e.URL, requestHeaders, e.RequestBody,
+		e.StatusCode, responseHeaders, e.ResponseBody, e.Error, e.DurationMs, e.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create provider exchange: %w", err)
+	}
+
+	return nil
+}
+
+// ListByBooking retrieves the traffic captured for a booking, oldest first
+func (r *repository) ListByBooking(ctx context.Context, bookingID string) ([]*Exchange, error) {
+	query := `
+		SELECT id, provider_code, COALESCE(correlation_id, ''), COALESCE(booking_id::text, ''), method, url,
+			request_headers, COALESCE(request_body, ''), COALESCE(status_code, 0), response_headers,
+			COALESCE(response_body, ''), COALESCE(error, ''), duration_ms, created_at
+		FROM provider_exchanges
+		WHERE booking_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider exchanges: %w", err)
+	}
+	defer rows.Close()
+
+	var exchanges []*Exchange
+	for rows.Next() {
+		var e Exchange
+		var requestHeaders, responseHeaders []byte
+		if err := rows.Scan(
+			&e.ID, &e.ProviderCode, &e.CorrelationID, &e.BookingID, &e.Method, &e.URL,
+			&requestHeaders, &e.RequestBody, &e.StatusCode, &responseHeaders,
+			&e.ResponseBody, &e.Error, &e.DurationMs, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan provider exchange: %w", err)
+		}
+		_ = json.Unmarshal(requestHeaders, &e.RequestHeaders)
+		_ = json.Unmarshal(responseHeaders, &e.ResponseHeaders)
+		exchanges = append(exchanges, &e)
+	}
+
+	return exchanges, rows.Err()
+}
+
+// DeleteBefore purges exchanges captured before a moment
+func (r *repository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM provider_exchanges WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge provider exchanges: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
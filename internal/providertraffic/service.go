@@ -0,0 +1,137 @@
+package providertraffic
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/google/uuid"
+)
+
+// storeTimeout bounds how long storing one exchange may take
+const storeTimeout = 2 * time.Second
+
+// Service defines interface for captured provider traffic
+type Service interface {
+	Recorder
+	ListForBooking(ctx context.Context, bookingID string) ([]*Exchange, error)
+	Purge(ctx context.Context) error
+	Start(ctx context.Context)
+	Stop()
+}
+
+type service struct {
+	repo   Repository
+	config Config
+	now    func() time.Time
+	queue  chan *Exchange
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService creates a new captured traffic service. Exchanges are stored
+// by a background writer, run with Start.
+func NewService(repo Repository, config Config) Service {
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultConfig().QueueSize
+	}
+	return &service{
+		repo:   repo,
+		config: config,
+		now:    time.Now,
+		queue:  make(chan *Exchange, queueSize),
+	}
+}
+
+// Record queues an exchange for the background writer. It never waits: when
+// the queue is full the exchange is dropped, so capture cannot slow down or
+// fail a provider call.
+func (s *service) Record(ctx context.Context, exchange *Exchange) {
+	if exchange.ID == "" {
+		exchange.ID = uuid.New().String()
+	}
+
+	select {
+	case s.queue <- exchange:
+	default:
+		logger.Warnf("Provider traffic queue full, dropped %s exchange %s", exchange.ProviderCode, exchange.ID)
+	}
+}
+
+// Start runs the background writer storing queued exchanges
+func (s *service) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go s.write(ctx)
+}
+
+// Stop stops the background writer once the exchanges already queued are stored
+func (s *service) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	s.wg.Wait()
+}
+
+// write stores queued exchanges until ctx is done, then drains the queue
+func (s *service) write(ctx context.Context) {
+	defer s.wg.Done()
+	for {
+		select {
+		case exchange := <-s.queue:
+			s.store(exchange)
+		case <-ctx.Done():
+			for {
+				select {
+				case exchange := <-s.queue:
+					s.store(exchange)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// store saves an exchange. Failures are logged and never fail the provider call.
+func (s *service) store(exchange *Exchange) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := s.repo.Create(ctx, exchange); err != nil {
+		logger.ErrorWithErr(err, "Failed to record provider exchange")
+	}
+}
+
+// ListForBooking returns the traffic captured for a booking
+func (s *service) ListForBooking(ctx context.Context, bookingID string) ([]*Exchange, error) {
+	return s.repo.ListByBooking(ctx, bookingID)
+}
+
+// Purge deletes exchanges older than the retention period
+func (s *service) Purge(ctx context.Context) error {
+	before := s.now().AddDate(0, 0, -s.config.RetentionDays)
+	deleted, err := s.repo.DeleteBefore(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Purged %d provider exchanges captured before %s", deleted, before.Format("2006-01-02"))
+	return nil
+}
@@ -0,0 +1,65 @@
+package providertraffic
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingRepository stores exchanges once released
+type blockingRepository struct {
+	release chan struct{}
+
+	mu     sync.Mutex
+	stored []*Exchange
+}
+
+func (r *blockingRepository) Create(ctx context.Context, exchange *Exchange) error {
+	<-r.release
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stored = append(r.stored, exchange)
+	return nil
+}
+
+func (r *blockingRepository) ListByBooking(ctx context.Context, bookingID string) ([]*Exchange, error) {
+	return nil, nil
+}
+
+func (r *blockingRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// TestService_RecordDoesNotWait tests that recording never waits for the
+// database, dropping exchanges once the queue is full
+func TestService_RecordDoesNotWait(t *testing.T) {
+	repo := &blockingRepository{release: make(chan struct{})}
+	svc := NewService(repo, Config{QueueSize: 2})
+	svc.Start(context.Background())
+
+	recorded := make(chan struct{})
+	go func() {
+		// One is taken by the writer, two wait in the queue, the rest are dropped
+		for range 5 {
+			svc.Record(context.Background(), &Exchange{ProviderCode: "hotelbeds"})
+		}
+		close(recorded)
+	}()
+	select {
+	case <-recorded:
+	case <-time.After(time.Second):
+		t.Fatal("Record waited for the database")
+	}
+
+	close(repo.release)
+	svc.Stop()
+
+	assert.GreaterOrEqual(t, len(repo.stored), 2)
+	assert.LessOrEqual(t, len(repo.stored), 3)
+	for _, exchange := range repo.stored {
+		assert.NotEmpty(t, exchange.ID)
+	}
+}
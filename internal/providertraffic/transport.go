@@ -0,0 +1,99 @@
+package providertraffic
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Recorder stores captured exchanges
+type Recorder interface {
+	Record(ctx context.Context, exchange *Exchange)
+}
+
+// Transport is an http.RoundTripper that captures a provider's traffic
+type Transport struct {
+	providerCode string
+	base         http.RoundTripper
+	recorder     Recorder
+	maxBodyBytes int
+}
+
+// NewTransport wraps base so every request to the provider is captured.
+// A nil base uses http.DefaultTransport.
+func NewTransport(providerCode string, base http.RoundTripper, recorder Recorder, config Config) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		providerCode: providerCode,
+		base:         base,
+		recorder:     recorder,
+		maxBodyBytes: config.MaxBodyBytes,
+	}
+}
+
+// Wrap returns a function that wraps a client's transport for capture, for
+// provider clients that accept a transport wrapper
+func Wrap(providerCode string, recorder Recorder, config Config) func(http.RoundTripper) http.RoundTripper {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return NewTransport(providerCode, base, recorder, config)
+	}
+}
+
+// RoundTrip sends the request and records it with its response
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	exchange := &Exchange{
+		ProviderCode:   t.providerCode,
+		CorrelationID:  logger.GetRequestID(ctx),
+		BookingID:      BookingIDFromContext(ctx),
+		Method:         req.Method,
+		URL:            RedactURL(req.URL),
+		RequestHeaders: RedactHeaders(req.Header),
+		CreatedAt:      time.Now(),
+	}
+
+	// GetBody returns a copy, leaving the body sent untouched
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			exchange.RequestBody = t.truncate(RedactBody(data))
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	exchange.DurationMs = time.Since(exchange.CreatedAt).Milliseconds()
+
+	if err != nil {
+		exchange.Error = err.Error()
+	}
+	if resp != nil {
+		exchange.StatusCode = resp.StatusCode
+		exchange.ResponseHeaders = RedactHeaders(resp.Header)
+		if resp.Body != nil {
+			data, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(data))
+			exchange.ResponseBody = t.truncate(RedactBody(data))
+			if readErr != nil {
+				exchange.Error = readErr.Error()
+			}
+		}
+	}
+
+	t.recorder.Record(ctx, exchange)
+	return resp, err
+}
+
+func (t *Transport) truncate(body string) string {
+	if t.maxBodyBytes > 0 && len(body) > t.maxBodyBytes {
+		return body[:t.maxBodyBytes] + "...[truncated]"
+	}
+	return body
+}
@@ -0,0 +1,93 @@
+package providertraffic
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingRecorder struct {
+	exchanges []*Exchange
+}
+
+func (r *recordingRecorder) Record(ctx context.Context, exchange *Exchange) {
+	r.exchanges = append(r.exchanges, exchange)
+}
+
+func TestTransport_RoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), "4111111111111111", "provider must receive the unredacted body")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"reference":"HB-1","access_token":"tok"}`))
+	}))
+	defer server.Close()
+
+	recorder := &recordingRecorder{}
+	client := &http.Client{Transport: NewTransport("hotelbeds", nil, recorder, DefaultConfig())}
+
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+	ctx = WithBookingID(ctx, "booking-1")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/bookings",
+		bytes.NewReader([]byte(`{"cardNumber":"4111111111111111"}`)))
+	require.NoError(t, err)
+	req.Header.Set("Api-Key", "hb-key")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "HB-1", "caller must still read the response body")
+
+	require.Len(t, recorder.exchanges, 1)
+	exchange := recorder.exchanges[0]
+	assert.Equal(t, "hotelbeds", exchange.ProviderCode)
+	assert.Equal(t, "req-1", exchange.CorrelationID)
+	assert.Equal(t, "booking-1", exchange.BookingID)
+	assert.Equal(t, http.StatusCreated, exchange.StatusCode)
+	assert.Equal(t, Redacted, exchange.RequestHeaders["Api-Key"])
+	assert.NotContains(t, exchange.RequestBody, "4111111111111111")
+	assert.Contains(t, exchange.ResponseBody, "HB-1")
+	assert.NotContains(t, exchange.ResponseBody, "tok\"")
+}
+
+func TestTransport_TruncatesBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("a"), 100))
+	}))
+	defer server.Close()
+
+	recorder := &recordingRecorder{}
+	client := &http.Client{Transport: NewTransport("hotelbeds", nil, recorder, Config{MaxBodyBytes: 10})}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	assert.Len(t, body, 100)
+	require.Len(t, recorder.exchanges, 1)
+	assert.Equal(t, "aaaaaaaaaa...[truncated]", recorder.exchanges[0].ResponseBody)
+}
+
+func TestTransport_RecordsTransportErrors(t *testing.T) {
+	recorder := &recordingRecorder{}
+	client := &http.Client{Transport: NewTransport("hotelbeds", nil, recorder, DefaultConfig())}
+
+	_, err := client.Get("http://127.0.0.1:1/unreachable")
+	require.Error(t, err)
+
+	require.Len(t, recorder.exchanges, 1)
+	assert.NotEmpty(t, recorder.exchanges[0].Error)
+	assert.Zero(t, recorder.exchanges[0].StatusCode)
+}
@@ -0,0 +1,12 @@
+package reconciliation
+
+import "errors"
+
+// Package-level errors for reconciliation operations
+var (
+	ErrDiscrepancyNotFound     = errors.New("discrepancy not found")
+	ErrAlreadyResolved         = errors.New("discrepancy already resolved")
+	ErrLocalBookingNotFound    = errors.New("local booking not found")
+	ErrSupplierBookingNotFound = errors.New("supplier booking not found")
+	ErrUnknownSupplier         = errors.New("no reconciliation supplier for provider")
+)
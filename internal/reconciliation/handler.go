@@ -0,0 +1,160 @@
+package reconciliation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/admin"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Handler handles admin HTTP requests for booking reconciliation
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new reconciliation handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// ResolveRequest is the body of a resolve request
+type ResolveRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// ListDiscrepancies handles GET /admin/reconciliation/discrepancies
+// Defaults to open discrepancies; ?status=, ?type=, ?provider= and ?booking_id= filter it.
+func (h *Handler) ListDiscrepancies(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 || l > 100 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+		limit = l
+	}
+
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		o, err := strconv.Atoi(offsetStr)
+		if err != nil || o < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset parameter")
+			return
+		}
+		offset = o
+	}
+
+	filter := ListFilter{
+		ProviderCode: query.Get("provider"),
+		Type:         DiscrepancyType(strings.ToUpper(query.Get("type"))),
+		Status:       DiscrepancyStatus(strings.ToUpper(query.Get("status"))),
+		BookingID:    query.Get("booking_id"),
+	}
+	if filter.Status == "" && filter.BookingID == "" {
+		filter.Status = DiscrepancyStatusOpen
+	}
+
+	discrepancies, total, err := h.service.ListDiscrepancies(r.Context(), filter, limit, offset)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to list booking discrepancies")
+		respondWithError(w, http.StatusInternalServerError, "Failed to list booking discrepancies")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"discrepancies": discrepancies,
+		"total":         total,
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
+// GetDiscrepancy handles GET /admin/reconciliation/discrepancies/{id}
+func (h *Handler) GetDiscrepancy(w http.ResponseWriter, r *http.Request) {
+	discrepancy, err := h.service.GetDiscrepancy(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, ErrDiscrepancyNotFound) {
+			respondWithError(w, http.StatusNotFound, "Discrepancy not found")
+			return
+		}
+		logger.ErrorWithErr(err, "Failed to get booking discrepancy")
+		respondWithError(w, http.StatusInternalServerError, "Failed to get booking discrepancy")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, discrepancy)
+}
+
+// Resolve handles POST /admin/reconciliation/discrepancies/{id}/resolve
+func (h *Handler) Resolve(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := admin.AdminIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Admin ID not found in request")
+		return
+	}
+
+	var req ResolveRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	discrepancy, err := h.service.Resolve(r.Context(), r.PathValue("id"), adminID, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrDiscrepancyNotFound):
+			respondWithError(w, http.StatusNotFound, "Discrepancy not found")
+		case errors.Is(err, ErrAlreadyResolved):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			logger.ErrorWithErr(err, "Failed to resolve booking discrepancy")
+			respondWithError(w, http.StatusInternalServerError, "Failed to resolve booking discrepancy")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, discrepancy)
+}
+
+// Run handles POST /admin/reconciliation/run?provider=hotelbeds
+// It runs the nightly reconciliation for one supplier on demand.
+func (h *Handler) Run(w http.ResponseWriter, r *http.Request) {
+	provider := r.URL.Query().Get("provider")
+	if provider == "" {
+		provider = "hotelbeds"
+	}
+
+	result, err := h.service.Reconcile(r.Context(), provider)
+	if err != nil {
+		if errors.Is(err, ErrUnknownSupplier) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		logger.ErrorWithErr(err, "Failed to reconcile supplier bookings")
+		respondWithError(w, http.StatusBadGateway, "Failed to reconcile supplier bookings")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
+
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	respondWithJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,159 @@
+package reconciliation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/booking"
+	"github.com/google/uuid"
+)
+
+// DiscrepancyType describes how a supplier booking differs from ours
+type DiscrepancyType string
+
+const (
+	// DiscrepancyCancelledAtSupplier is cancelled at the supplier but live locally
+	DiscrepancyCancelledAtSupplier DiscrepancyType = "CANCELLED_AT_SUPPLIER"
+	// DiscrepancyCancelledLocally is cancelled locally but still confirmed at the supplier
+	DiscrepancyCancelledLocally DiscrepancyType = "CANCELLED_LOCALLY"
+	// DiscrepancyMissingAtSupplier is a live local booking the supplier doesn't know
+	DiscrepancyMissingAtSupplier DiscrepancyType = "MISSING_AT_SUPPLIER"
+	// DiscrepancyMissingLocally is a confirmed supplier booking we have no record of
+	DiscrepancyMissingLocally DiscrepancyType = "MISSING_LOCALLY"
+	// DiscrepancyDates means the stay dates differ
+	DiscrepancyDates DiscrepancyType = "DATES_MISMATCH"
+	// DiscrepancyAmount means the net amount or its currency differs
+	DiscrepancyAmount DiscrepancyType = "AMOUNT_MISMATCH"
+)
+
+// DiscrepancyStatus represents the review state of a discrepancy
+type DiscrepancyStatus string
+
+const (
+	DiscrepancyStatusOpen     DiscrepancyStatus = "OPEN"
+	DiscrepancyStatusResolved DiscrepancyStatus = "RESOLVED"
+)
+
+// Supplier booking statuses, as normalized by Supplier implementations
+const (
+	SupplierStatusConfirmed = "CONFIRMED"
+	SupplierStatusCancelled = "CANCELLED"
+)
+
+// Discrepancy is a difference between a supplier booking and the bookings table
+type Discrepancy struct {
+	ID                string            `json:"id" db:"id"`
+	ProviderCode      string            `json:"provider_code" db:"provider_code"`
+	SupplierReference string            `json:"supplier_reference" db:"supplier_reference"`
+	BookingID         string            `json:"booking_id,omitempty" db:"booking_id"`
+	BookingReference  string            `json:"booking_reference,omitempty" db:"booking_reference"`
+	Type              DiscrepancyType   `json:"type" db:"type"`
+	LocalValue        string            `json:"local_value,omitempty" db:"local_value"`
+	SupplierValue     string            `json:"supplier_value,omitempty" db:"supplier_value"`
+	Status            DiscrepancyStatus `json:"status" db:"status"`
+	ResolvedBy        string            `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolutionNote    string            `json:"resolution_note,omitempty" db:"resolution_note"`
+	DetectedAt        time.Time         `json:"detected_at" db:"detected_at"`
+	LastSeenAt        time.Time         `json:"last_seen_at" db:"last_seen_at"`
+	ResolvedAt        *time.Time        `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// NewDiscrepancy creates an open discrepancy for a supplier booking. local may
+// be nil when the booking is unknown locally.
+func NewDiscrepancy(providerCode, supplierReference string, local *LocalBooking, kind DiscrepancyType, localValue, supplierValue string) *Discrepancy {
+	now := time.Now()
+	d := &Discrepancy{
+		ID:                uuid.New().String(),
+		ProviderCode:      providerCode,
+		SupplierReference: supplierReference,
+		Type:              kind,
+		LocalValue:        localValue,
+		SupplierValue:     supplierValue,
+		Status:            DiscrepancyStatusOpen,
+		DetectedAt:        now,
+		LastSeenAt:        now,
+	}
+	if local != nil {
+		d.BookingID = local.ID
+		d.BookingReference = local.BookingReference
+	}
+	return d
+}
+
+// LocalBooking is what reconciliation compares from the bookings table
+type LocalBooking struct {
+	ID                string
+	BookingReference  string
+	SupplierReference string
+	ProviderCode      string
+	Status            booking.BookingStatus
+	CheckIn           time.Time
+	CheckOut          time.Time
+	NetAmount         int
+	Currency          string
+}
+
+// Cancelled reports whether the booking is cancelled locally
+func (b *LocalBooking) Cancelled() bool {
+	return b.Status == booking.StatusCancelled
+}
+
+// SupplierBooking is a booking as the supplier reports it
+type SupplierBooking struct {
+	Reference       string
+	ClientReference string
+	Status          string // SupplierStatusConfirmed or SupplierStatusCancelled
+	CheckIn         time.Time
+	CheckOut        time.Time
+	NetAmount       int
+	Currency        string
+}
+
+// Cancelled reports whether the booking is cancelled at the supplier
+func (b *SupplierBooking) Cancelled() bool {
+	return b.Status == SupplierStatusCancelled
+}
+
+// Config controls which bookings a run compares
+type Config struct {
+	LookBack  time.Duration // Check-ins this far in the past are still compared
+	LookAhead time.Duration // Check-ins this far in the future are compared
+}
+
+// DefaultConfig returns the default reconciliation window
+func DefaultConfig() Config {
+	return Config{
+		LookBack:  7 * 24 * time.Hour,
+		LookAhead: 365 * 24 * time.Hour,
+	}
+}
+
+// RunResult summarizes one reconciliation run against a supplier
+type RunResult struct {
+	ProviderCode     string    `json:"provider_code"`
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end"`
+	SupplierBookings int       `json:"supplier_bookings"`
+	LocalBookings    int       `json:"local_bookings"`
+	Discrepancies    int       `json:"discrepancies"`     // Found in this run
+	NewDiscrepancies int       `json:"new_discrepancies"` // Not already open
+	Errors           int       `json:"errors"`            // Bookings that could not be compared
+}
+
+// ListFilter filters discrepancies in admin listings
+type ListFilter struct {
+	ProviderCode string
+	Type         DiscrepancyType
+	Status       DiscrepancyStatus
+	BookingID    string
+}
+
+// stayValue formats stay dates for discrepancy values
+func stayValue(checkIn, checkOut time.Time) string {
+	return checkIn.Format("2006-01-02") + "/" + checkOut.Format("2006-01-02")
+}
+
+// amountValue formats an amount for discrepancy values
+func amountValue(amount int, currency string) string {
+	return fmt.Sprintf("%d %s", amount, currency)
+}
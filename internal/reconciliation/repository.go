@@ -0,0 +1,232 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// Repository defines interface for reconciliation data operations
+type Repository interface {
+	ListLocalBookings(ctx context.Context, providerCode string, start, end time.Time) ([]*LocalBooking, error)
+	GetLocalBooking(ctx context.Context, providerCode, supplierReference string) (*LocalBooking, error)
+	RecordDiscrepancy(ctx context.Context, discrepancy *Discrepancy) (bool, error)
+	GetDiscrepancy(ctx context.Context, id string) (*Discrepancy, error)
+	ListDiscrepancies(ctx context.Context, filter ListFilter, limit, offset int) ([]*Discrepancy, int, error)
+	UpdateDiscrepancy(ctx context.Context, discrepancy *Discrepancy) error
+}
+
+type repository struct {
+	db *db.DB
+}
+
+// NewRepository creates a new reconciliation repository
+func NewRepository(database *db.DB) Repository {
+	return &repository{
+		db: database,
+	}
+}
+
+const localBookingColumns = `
+	id, booking_reference, supplier_reference, provider_code, status,
+	check_in, check_out, COALESCE(net_amount, total_amount), currency
+`
+
+func scanLocalBooking(row pgx.Row) (*LocalBooking, error) {
+	var b LocalBooking
+	err := row.Scan(
+		&b.ID, &b.BookingReference, &b.SupplierReference, &b.ProviderCode, &b.Status,
+		&b.CheckIn, &b.CheckOut, &b.NetAmount, &b.Currency,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ListLocalBookings retrieves bookings confirmed with a supplier that check in
+// between start and end
+func (r *repository) ListLocalBookings(ctx context.Context, providerCode string, start, end time.Time) ([]*LocalBooking, error) {
+	query := `
+		SELECT ` + localBookingColumns + `
+		FROM bookings
+		WHERE provider_code = $1 AND COALESCE(supplier_reference, '') <> ''
+			AND check_in >= $2 AND check_in <= $3
+		ORDER BY check_in, booking_reference
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, providerCode, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []*LocalBooking
+	for rows.Next() {
+		b, err := scanLocalBooking(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan local booking: %w", err)
+		}
+		bookings = append(bookings, b)
+	}
+
+	return bookings, rows.Err()
+}
+
+// GetLocalBooking retrieves a booking by the supplier's booking reference
+func (r *repository) GetLocalBooking(ctx context.Context, providerCode, supplierReference string) (*LocalBooking, error) {
+	query := `SELECT ` + localBookingColumns + ` FROM bookings WHERE provider_code = $1 AND supplier_reference = $2`
+
+	b, err := scanLocalBooking(r.db.Pool.QueryRow(ctx, query, providerCode, supplierReference))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrLocalBookingNotFound
+		}
+		return nil, fmt.Errorf("failed to get local booking: %w", err)
+	}
+
+	return b, nil
+}
+
+const discrepancyColumns = `
+	id, provider_code, supplier_reference, COALESCE(booking_id::text, ''), COALESCE(booking_reference, ''),
+	type, COALESCE(local_value, ''), COALESCE(supplier_value, ''), status,
+	COALESCE(resolved_by::text, ''), COALESCE(resolution_note, ''), detected_at, last_seen_at, resolved_at
+`
+
+func scanDiscrepancy(row pgx.Row) (*Discrepancy, error) {
+	var d Discrepancy
+	err := row.Scan(
+		&d.ID, &d.ProviderCode, &d.SupplierReference, &d.BookingID, &d.BookingReference,
+		&d.Type, &d.LocalValue, &d.SupplierValue, &d.Status,
+		&d.ResolvedBy, &d.ResolutionNote, &d.DetectedAt, &d.LastSeenAt, &d.ResolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// RecordDiscrepancy stores an open discrepancy. When the same one is already
+// open its values and last_seen_at are refreshed instead. Reports whether the
+// discrepancy is new.
+func (r *repository) RecordDiscrepancy(ctx context.Context, d *Discrepancy) (bool, error) {
+	query := `
+		INSERT INTO booking_discrepancies (
+			id, provider_code, supplier_reference, booking_id, booking_reference,
+			type, local_value, supplier_value, status, detected_at, last_seen_at
+		) VALUES ($1, $2, $3, NULLIF($4, '')::uuid, NULLIF($5, ''), $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10, $11)
+		ON CONFLICT (provider_code, supplier_reference, type) WHERE status = 'OPEN'
+		DO UPDATE SET local_value = EXCLUDED.local_value, supplier_value = EXCLUDED.supplier_value,
+			last_seen_at = EXCLUDED.last_seen_at
+		RETURNING id, (xmax = 0)
+	`
+
+	var inserted bool
+	err := r.db.Pool.QueryRow(ctx, query,
+		d.ID, d.ProviderCode, d.SupplierReference, d.BookingID, d.BookingReference,
+		d.Type, d.LocalValue, d.SupplierValue, d.Status, d.DetectedAt, d.LastSeenAt,
+	).Scan(&d.ID, &inserted)
+	if err != nil {
+		return false, fmt.Errorf("failed to record discrepancy: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// GetDiscrepancy retrieves a discrepancy by ID
+func (r *repository) GetDiscrepancy(ctx context.Context, id string) (*Discrepancy, error) {
+	query := `SELECT ` + discrepancyColumns + ` FROM booking_discrepancies WHERE id = $1`
+
+	d, err := scanDiscrepancy(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDiscrepancyNotFound
+		}
+		return nil, fmt.Errorf("failed to get discrepancy: %w", err)
+	}
+
+	return d, nil
+}
+
+// ListDiscrepancies retrieves discrepancies matching the filter, newest first,
+// with the total number of matches
+func (r *repository) ListDiscrepancies(ctx context.Context, filter ListFilter, limit, offset int) ([]*Discrepancy, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.ProviderCode != "" {
+		args = append(args, filter.ProviderCode)
+		conditions = append(conditions, fmt.Sprintf("provider_code = $%d", len(args)))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, fmt.Sprintf("type = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.BookingID != "" {
+		args = append(args, filter.BookingID)
+		conditions = append(conditions, fmt.Sprintf("booking_id = $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM booking_discrepancies`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count discrepancies: %w", err)
+	}
+
+	query := `SELECT ` + discrepancyColumns + ` FROM booking_discrepancies` + where + ` ORDER BY detected_at DESC`
+	if limit > 0 {
+		args = append(args, limit, offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list discrepancies: %w", err)
+	}
+	defer rows.Close()
+
+	var discrepancies []*Discrepancy
+	for rows.Next() {
+		d, err := scanDiscrepancy(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan discrepancy: %w", err)
+		}
+		discrepancies = append(discrepancies, d)
+	}
+
+	return discrepancies, total, rows.Err()
+}
+
+// UpdateDiscrepancy saves the review fields of a discrepancy
+func (r *repository) UpdateDiscrepancy(ctx context.Context, d *Discrepancy) error {
+	query := `
+		UPDATE booking_discrepancies
+		SET status = $2, resolved_by = NULLIF($3, '')::uuid, resolution_note = NULLIF($4, ''), resolved_at = $5
+		WHERE id = $1
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, d.ID, d.Status, d.ResolvedBy, d.ResolutionNote, d.ResolvedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update discrepancy: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrDiscrepancyNotFound
+	}
+
+	return nil
+}
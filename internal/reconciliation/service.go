@@ -0,0 +1,228 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Service defines interface for supplier booking reconciliation
+type Service interface {
+	Reconcile(ctx context.Context, providerCode string) (*RunResult, error)
+	ReconcileAll(ctx context.Context) error
+	ListDiscrepancies(ctx context.Context, filter ListFilter, limit, offset int) ([]*Discrepancy, int, error)
+	GetDiscrepancy(ctx context.Context, id string) (*Discrepancy, error)
+	Resolve(ctx context.Context, id, adminID, note string) (*Discrepancy, error)
+}
+
+type service struct {
+	repo      Repository
+	suppliers map[string]Supplier
+	order     []string
+	config    Config
+	now       func() time.Time
+}
+
+// NewService creates a new reconciliation service comparing bookings with
+// each of the given suppliers
+func NewService(repo Repository, config Config, suppliers ...Supplier) Service {
+	s := &service{
+		repo:      repo,
+		suppliers: make(map[string]Supplier, len(suppliers)),
+		config:    config,
+		now:       time.Now,
+	}
+	for _, supplier := range suppliers {
+		s.suppliers[supplier.Code()] = supplier
+		s.order = append(s.order, supplier.Code())
+	}
+	return s
+}
+
+// ReconcileAll reconciles every supplier. It is run nightly.
+func (s *service) ReconcileAll(ctx context.Context) error {
+	var errs []error
+	for _, code := range s.order {
+		if _, err := s.Reconcile(ctx, code); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", code, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Reconcile compares a supplier's bookings with ours and records a
+// discrepancy for every difference found
+func (s *service) Reconcile(ctx context.Context, providerCode string) (*RunResult, error) {
+	supplier, ok := s.suppliers[providerCode]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSupplier, providerCode)
+	}
+
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	result := &RunResult{
+		ProviderCode: providerCode,
+		Start:        today.Add(-s.config.LookBack),
+		End:          today.Add(s.config.LookAhead),
+	}
+
+	logger.Infof("Reconciling %s bookings checking in %s to %s",
+		providerCode, result.Start.Format("2006-01-02"), result.End.Format("2006-01-02"))
+
+	supplierBookings, err := supplier.ListBookings(ctx, result.Start, result.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list supplier bookings: %w", err)
+	}
+	localBookings, err := s.repo.ListLocalBookings(ctx, providerCode, result.Start, result.End)
+	if err != nil {
+		return nil, err
+	}
+	result.SupplierBookings = len(supplierBookings)
+	result.LocalBookings = len(localBookings)
+
+	bySupplierRef := make(map[string]*SupplierBooking, len(supplierBookings))
+	for i := range supplierBookings {
+		bySupplierRef[supplierBookings[i].Reference] = &supplierBookings[i]
+	}
+
+	matched := make(map[string]bool, len(localBookings))
+	for _, local := range localBookings {
+		matched[local.SupplierReference] = true
+
+		remote, ok := bySupplierRef[local.SupplierReference]
+		if !ok {
+			// Not in the listed window - the stay may have moved, so look it up
+			remote, err = supplier.GetBooking(ctx, local.SupplierReference)
+			if errors.Is(err, ErrSupplierBookingNotFound) {
+				if !local.Cancelled() {
+					s.record(ctx, result, NewDiscrepancy(providerCode, local.SupplierReference, local,
+						DiscrepancyMissingAtSupplier, string(local.Status), ""))
+				}
+				continue
+			}
+			if err != nil {
+				logger.ErrorWithErr(err, "Failed to get supplier booking "+local.SupplierReference)
+				result.Errors++
+				continue
+			}
+		}
+
+		for _, d := range compare(providerCode, local, remote) {
+			s.record(ctx, result, d)
+		}
+	}
+
+	for i := range supplierBookings {
+		remote := &supplierBookings[i]
+		if matched[remote.Reference] {
+			continue
+		}
+
+		// Our check-in may lie outside the window if the dates differ
+		local, err := s.repo.GetLocalBooking(ctx, providerCode, remote.Reference)
+		if errors.Is(err, ErrLocalBookingNotFound) {
+			if !remote.Cancelled() {
+				s.record(ctx, result, NewDiscrepancy(providerCode, remote.Reference, nil,
+					DiscrepancyMissingLocally, "", remote.Status))
+			}
+			continue
+		}
+		if err != nil {
+			logger.ErrorWithErr(err, "Failed to get local booking for "+remote.Reference)
+			result.Errors++
+			continue
+		}
+
+		for _, d := range compare(providerCode, local, remote) {
+			s.record(ctx, result, d)
+		}
+	}
+
+	logger.Infof("Reconciled %s: %d supplier and %d local bookings, %d discrepancies (%d new), %d errors",
+		providerCode, result.SupplierBookings, result.LocalBookings, result.Discrepancies, result.NewDiscrepancies, result.Errors)
+
+	return result, nil
+}
+
+// compare returns the differences between a local booking and the supplier's
+func compare(providerCode string, local *LocalBooking, remote *SupplierBooking) []*Discrepancy {
+	switch {
+	case remote.Cancelled() && local.Cancelled():
+		return nil
+	case remote.Cancelled():
+		return []*Discrepancy{NewDiscrepancy(providerCode, remote.Reference, local,
+			DiscrepancyCancelledAtSupplier, string(local.Status), remote.Status)}
+	case local.Cancelled():
+		return []*Discrepancy{NewDiscrepancy(providerCode, remote.Reference, local,
+			DiscrepancyCancelledLocally, string(local.Status), remote.Status)}
+	}
+
+	var discrepancies []*Discrepancy
+	if !sameDay(local.CheckIn, remote.CheckIn) || !sameDay(local.CheckOut, remote.CheckOut) {
+		discrepancies = append(discrepancies, NewDiscrepancy(providerCode, remote.Reference, local, DiscrepancyDates,
+			stayValue(local.CheckIn, local.CheckOut), stayValue(remote.CheckIn, remote.CheckOut)))
+	}
+	if remote.NetAmount > 0 && (remote.NetAmount != local.NetAmount || remote.Currency != local.Currency) {
+		discrepancies = append(discrepancies, NewDiscrepancy(providerCode, remote.Reference, local, DiscrepancyAmount,
+			amountValue(local.NetAmount, local.Currency), amountValue(remote.NetAmount, remote.Currency)))
+	}
+	return discrepancies
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// record stores a discrepancy. Failures are counted rather than stopping the run.
+func (s *service) record(ctx context.Context, result *RunResult, d *Discrepancy) {
+	result.Discrepancies++
+
+	inserted, err := s.repo.RecordDiscrepancy(ctx, d)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to record booking discrepancy")
+		result.Errors++
+		return
+	}
+	if inserted {
+		result.NewDiscrepancies++
+		logger.Warnf("Booking discrepancy %s: %s %s (local %q, supplier %q)",
+			d.Type, d.ProviderCode, d.SupplierReference, d.LocalValue, d.SupplierValue)
+	}
+}
+
+// ListDiscrepancies lists discrepancies for admins
+func (s *service) ListDiscrepancies(ctx context.Context, filter ListFilter, limit, offset int) ([]*Discrepancy, int, error) {
+	return s.repo.ListDiscrepancies(ctx, filter, limit, offset)
+}
+
+// GetDiscrepancy retrieves a discrepancy
+func (s *service) GetDiscrepancy(ctx context.Context, id string) (*Discrepancy, error) {
+	return s.repo.GetDiscrepancy(ctx, id)
+}
+
+// Resolve closes a discrepancy once an admin has dealt with it. A later run
+// that still finds the difference opens a new one.
+func (s *service) Resolve(ctx context.Context, id, adminID, note string) (*Discrepancy, error) {
+	d, err := s.repo.GetDiscrepancy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status != DiscrepancyStatusOpen {
+		return nil, ErrAlreadyResolved
+	}
+
+	now := s.now()
+	d.Status = DiscrepancyStatusResolved
+	d.ResolvedBy = adminID
+	d.ResolutionNote = note
+	d.ResolvedAt = &now
+
+	if err := s.repo.UpdateDiscrepancy(ctx, d); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Booking discrepancy %s resolved by %s", d.ID, adminID)
+	return d, nil
+}
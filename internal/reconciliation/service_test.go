@@ -0,0 +1,249 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/booking"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRepository is a mock implementation of reconciliation.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) ListLocalBookings(ctx context.Context, providerCode string, start, end time.Time) ([]*LocalBooking, error) {
+	args := m.Called(ctx, providerCode, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*LocalBooking), args.Error(1)
+}
+
+func (m *MockRepository) GetLocalBooking(ctx context.Context, providerCode, supplierReference string) (*LocalBooking, error) {
+	args := m.Called(ctx, providerCode, supplierReference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*LocalBooking), args.Error(1)
+}
+
+func (m *MockRepository) RecordDiscrepancy(ctx context.Context, discrepancy *Discrepancy) (bool, error) {
+	args := m.Called(ctx, discrepancy)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetDiscrepancy(ctx context.Context, id string) (*Discrepancy, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Discrepancy), args.Error(1)
+}
+
+func (m *MockRepository) ListDiscrepancies(ctx context.Context, filter ListFilter, limit, offset int) ([]*Discrepancy, int, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*Discrepancy), args.Int(1), args.Error(2)
+}
+
+func (m *MockRepository) UpdateDiscrepancy(ctx context.Context, discrepancy *Discrepancy) error {
+	args := m.Called(ctx, discrepancy)
+	return args.Error(0)
+}
+
+// fakeSupplier serves a fixed booking list; GetBooking also finds extra bookings
+type fakeSupplier struct {
+	listed []SupplierBooking
+	extra  map[string]SupplierBooking
+	err    error
+}
+
+func (f *fakeSupplier) Code() string { return "hotelbeds" }
+
+func (f *fakeSupplier) ListBookings(ctx context.Context, start, end time.Time) ([]SupplierBooking, error) {
+	return f.listed, f.err
+}
+
+func (f *fakeSupplier) GetBooking(ctx context.Context, reference string) (*SupplierBooking, error) {
+	if b, ok := f.extra[reference]; ok {
+		return &b, nil
+	}
+	return nil, ErrSupplierBookingNotFound
+}
+
+var testToday = time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+
+func newTestService(repo Repository, supplier Supplier) *service {
+	s := NewService(repo, DefaultConfig(), supplier).(*service)
+	s.now = func() time.Time { return testToday }
+	return s
+}
+
+func stay() (time.Time, time.Time) {
+	checkIn := time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC)
+	return checkIn, checkIn.AddDate(0, 0, 2)
+}
+
+func localBooking(ref string, status booking.BookingStatus) *LocalBooking {
+	checkIn, checkOut := stay()
+	return &LocalBooking{
+		ID:                "booking-" + ref,
+		BookingReference:  "BKG-" + ref,
+		SupplierReference: ref,
+		ProviderCode:      "hotelbeds",
+		Status:            status,
+		CheckIn:           checkIn,
+		CheckOut:          checkOut,
+		NetAmount:         2100000,
+		Currency:          "IDR",
+	}
+}
+
+func supplierBooking(ref, status string) SupplierBooking {
+	checkIn, checkOut := stay()
+	return SupplierBooking{Reference: ref, Status: status, CheckIn: checkIn, CheckOut: checkOut, NetAmount: 2100000, Currency: "IDR"}
+}
+
+func TestService_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+
+	moved := supplierBooking("HB-MOVED", SupplierStatusConfirmed)
+	moved.CheckIn = moved.CheckIn.AddDate(1, 0, 0)
+	moved.CheckOut = moved.CheckOut.AddDate(1, 0, 0)
+	repriced := supplierBooking("HB-PRICE", SupplierStatusConfirmed)
+	repriced.NetAmount = 2300000
+
+	supplier := &fakeSupplier{
+		listed: []SupplierBooking{
+			supplierBooking("HB-OK", SupplierStatusConfirmed),
+			supplierBooking("HB-CANCELLED", SupplierStatusCancelled),
+			repriced,
+			supplierBooking("HB-BOTH-CANCELLED", SupplierStatusCancelled),
+			supplierBooking("HB-LOCAL-CANCEL", SupplierStatusConfirmed),
+			supplierBooking("HB-UNKNOWN", SupplierStatusConfirmed),
+			supplierBooking("HB-UNKNOWN-CANCELLED", SupplierStatusCancelled),
+			supplierBooking("HB-LATE", SupplierStatusConfirmed),
+		},
+		extra: map[string]SupplierBooking{"HB-MOVED": moved},
+	}
+
+	start := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2027, 10, 18, 0, 0, 0, 0, time.UTC)
+	repo.On("ListLocalBookings", ctx, "hotelbeds", start, end).Return([]*LocalBooking{
+		localBooking("HB-OK", booking.StatusConfirmed),
+		localBooking("HB-CANCELLED", booking.StatusConfirmed),
+		localBooking("HB-PRICE", booking.StatusConfirmed),
+		localBooking("HB-BOTH-CANCELLED", booking.StatusCancelled),
+		localBooking("HB-LOCAL-CANCEL", booking.StatusCancelled),
+		localBooking("HB-MOVED", booking.StatusConfirmed),
+		localBooking("HB-GONE", booking.StatusGuaranteed),
+		localBooking("HB-GONE-CANCELLED", booking.StatusCancelled),
+	}, nil)
+	repo.On("GetLocalBooking", ctx, "hotelbeds", "HB-UNKNOWN").Return(nil, ErrLocalBookingNotFound)
+	repo.On("GetLocalBooking", ctx, "hotelbeds", "HB-UNKNOWN-CANCELLED").Return(nil, ErrLocalBookingNotFound)
+	late := localBooking("HB-LATE", booking.StatusConfirmed)
+	late.CheckIn = late.CheckIn.AddDate(0, 0, -1)
+	repo.On("GetLocalBooking", ctx, "hotelbeds", "HB-LATE").Return(late, nil)
+
+	var recorded []*Discrepancy
+	repo.On("RecordDiscrepancy", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = append(recorded, args.Get(1).(*Discrepancy))
+	}).Return(true, nil)
+
+	result, err := newTestService(repo, supplier).Reconcile(ctx, "hotelbeds")
+	require.NoError(t, err)
+
+	found := make(map[string]*Discrepancy)
+	for _, d := range recorded {
+		found[d.SupplierReference+" "+string(d.Type)] = d
+	}
+	assert.Len(t, found, 7)
+
+	cancelled := found["HB-CANCELLED CANCELLED_AT_SUPPLIER"]
+	require.NotNil(t, cancelled)
+	assert.Equal(t, "booking-HB-CANCELLED", cancelled.BookingID)
+	assert.Equal(t, "CONFIRMED", cancelled.LocalValue)
+	assert.Equal(t, "CANCELLED", cancelled.SupplierValue)
+
+	require.Contains(t, found, "HB-PRICE AMOUNT_MISMATCH")
+	assert.Equal(t, "2100000 IDR", found["HB-PRICE AMOUNT_MISMATCH"].LocalValue)
+	assert.Equal(t, "2300000 IDR", found["HB-PRICE AMOUNT_MISMATCH"].SupplierValue)
+
+	assert.Contains(t, found, "HB-LOCAL-CANCEL CANCELLED_LOCALLY")
+	assert.Contains(t, found, "HB-GONE MISSING_AT_SUPPLIER")
+
+	require.Contains(t, found, "HB-MOVED DATES_MISMATCH")
+	assert.Equal(t, "2026-11-20/2026-11-22", found["HB-MOVED DATES_MISMATCH"].LocalValue)
+	assert.Equal(t, "2027-11-20/2027-11-22", found["HB-MOVED DATES_MISMATCH"].SupplierValue)
+
+	// Found through the supplier list, though our check-in is a day earlier
+	assert.Contains(t, found, "HB-LATE DATES_MISMATCH")
+
+	unknown := found["HB-UNKNOWN MISSING_LOCALLY"]
+	require.NotNil(t, unknown)
+	assert.Empty(t, unknown.BookingID)
+
+	assert.Equal(t, 8, result.SupplierBookings)
+	assert.Equal(t, 8, result.LocalBookings)
+	assert.Equal(t, 0, result.Errors)
+	repo.AssertExpectations(t)
+}
+
+func TestService_Reconcile_CountsExistingDiscrepancies(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+	supplier := &fakeSupplier{listed: []SupplierBooking{supplierBooking("HB-1", SupplierStatusCancelled)}}
+
+	repo.On("ListLocalBookings", ctx, "hotelbeds", mock.Anything, mock.Anything).
+		Return([]*LocalBooking{localBooking("HB-1", booking.StatusConfirmed)}, nil)
+	repo.On("RecordDiscrepancy", ctx, mock.Anything).Return(false, nil)
+
+	result, err := newTestService(repo, supplier).Reconcile(ctx, "hotelbeds")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Discrepancies)
+	assert.Equal(t, 0, result.NewDiscrepancies)
+}
+
+func TestService_Reconcile_Errors(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+
+	_, err := newTestService(repo, &fakeSupplier{}).Reconcile(ctx, "hotelplanner")
+	assert.ErrorIs(t, err, ErrUnknownSupplier)
+
+	_, err = newTestService(repo, &fakeSupplier{err: errors.New("status 503")}).Reconcile(ctx, "hotelbeds")
+	assert.ErrorContains(t, err, "status 503")
+	repo.AssertNotCalled(t, "ListLocalBookings", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Resolve(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepository)
+	s := newTestService(repo, &fakeSupplier{})
+
+	open := NewDiscrepancy("hotelbeds", "HB-1", localBooking("HB-1", booking.StatusConfirmed),
+		DiscrepancyCancelledAtSupplier, "CONFIRMED", "CANCELLED")
+	repo.On("GetDiscrepancy", ctx, open.ID).Return(open, nil)
+	repo.On("UpdateDiscrepancy", ctx, open).Return(nil)
+
+	resolved, err := s.Resolve(ctx, open.ID, "admin-1", "Refunded the guest")
+	require.NoError(t, err)
+	assert.Equal(t, DiscrepancyStatusResolved, resolved.Status)
+	assert.Equal(t, "admin-1", resolved.ResolvedBy)
+	assert.Equal(t, "Refunded the guest", resolved.ResolutionNote)
+	require.NotNil(t, resolved.ResolvedAt)
+	assert.Equal(t, testToday, *resolved.ResolvedAt)
+
+	_, err = s.Resolve(ctx, open.ID, "admin-2", "")
+	assert.ErrorIs(t, err, ErrAlreadyResolved)
+	repo.AssertNumberOfCalls(t, "UpdateDiscrepancy", 1)
+}
@@ -0,0 +1,90 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
+)
+
+// Supplier lists a supplier's bookings for reconciliation
+type Supplier interface {
+	// Code returns the provider code bookings are stored under
+	Code() string
+	// ListBookings returns every booking checking in between start and end
+	ListBookings(ctx context.Context, start, end time.Time) ([]SupplierBooking, error)
+	// GetBooking returns one booking, or ErrSupplierBookingNotFound
+	GetBooking(ctx context.Context, reference string) (*SupplierBooking, error)
+}
+
+// hotelbedsSupplier reconciles against the HotelBeds Booking List and
+// Booking Detail endpoints
+type hotelbedsSupplier struct {
+	client hotelbeds.ClientInterface
+}
+
+// NewHotelbedsSupplier creates a reconciliation supplier for HotelBeds
+func NewHotelbedsSupplier(client hotelbeds.ClientInterface) Supplier {
+	return &hotelbedsSupplier{client: client}
+}
+
+func (s *hotelbedsSupplier) Code() string {
+	return "hotelbeds"
+}
+
+// ListBookings pages through the Booking List endpoint
+func (s *hotelbedsSupplier) ListBookings(ctx context.Context, start, end time.Time) ([]SupplierBooking, error) {
+	var bookings []SupplierBooking
+	from := 1
+	for {
+		page, err := s.client.ListBookings(ctx, &hotelbeds.BookingListRequest{
+			Start:      start,
+			End:        end,
+			FilterType: hotelbeds.FilterByCheckIn,
+			From:       from,
+			To:         from + hotelbeds.MaxBookingListPage - 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range page.Bookings {
+			bookings = append(bookings, toSupplierBooking(&page.Bookings[i]))
+		}
+
+		if !page.HasMore() {
+			return bookings, nil
+		}
+		from = page.To + 1
+	}
+}
+
+func (s *hotelbedsSupplier) GetBooking(ctx context.Context, reference string) (*SupplierBooking, error) {
+	detail, err := s.client.GetBookingDetail(ctx, reference)
+	if err != nil {
+		if errors.Is(err, hotelbeds.ErrBookingNotFound) {
+			return nil, ErrSupplierBookingNotFound
+		}
+		return nil, err
+	}
+
+	b := toSupplierBooking(detail)
+	return &b, nil
+}
+
+func toSupplierBooking(detail *hotelbeds.BookingDetail) SupplierBooking {
+	status := SupplierStatusConfirmed
+	if detail.Status == hotelbeds.BookingStatusCancelled {
+		status = SupplierStatusCancelled
+	}
+	return SupplierBooking{
+		Reference:       detail.Reference,
+		ClientReference: detail.ClientReference,
+		Status:          status,
+		CheckIn:         detail.CheckIn,
+		CheckOut:        detail.CheckOut,
+		NetAmount:       detail.TotalNet,
+		Currency:        detail.Currency,
+	}
+}
@@ -0,0 +1,9 @@
+package risk
+
+import "errors"
+
+// Package-level errors for risk operations
+var (
+	ErrAssessmentNotFound = errors.New("risk assessment not found")
+	ErrReviewClosed       = errors.New("risk assessment is not awaiting review")
+)
@@ -0,0 +1,147 @@
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/admin"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Handler handles admin HTTP requests for risk reviews
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new risk handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// ReviewRequest is the body of an approve or reject request
+type ReviewRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// ListAssessments handles GET /admin/risk/assessments
+// Defaults to the pending review queue; ?review_status= and ?stage= filter it.
+func (h *Handler) ListAssessments(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 || l > 100 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+		limit = l
+	}
+
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		o, err := strconv.Atoi(offsetStr)
+		if err != nil || o < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset parameter")
+			return
+		}
+		offset = o
+	}
+
+	filter := ListFilter{
+		ReviewStatus: ReviewStatus(strings.ToUpper(query.Get("review_status"))),
+		Stage:        Stage(strings.ToUpper(query.Get("stage"))),
+		BookingID:    query.Get("booking_id"),
+	}
+	if filter.ReviewStatus == "" && filter.BookingID == "" {
+		filter.ReviewStatus = ReviewStatusPending
+	}
+
+	assessments, total, err := h.service.ListAssessments(r.Context(), filter, limit, offset)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to list risk assessments")
+		respondWithError(w, http.StatusInternalServerError, "Failed to list risk assessments")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"assessments": assessments,
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// GetAssessment handles GET /admin/risk/assessments/{id}
+func (h *Handler) GetAssessment(w http.ResponseWriter, r *http.Request) {
+	assessment, err := h.service.GetAssessment(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, ErrAssessmentNotFound) {
+			respondWithError(w, http.StatusNotFound, "Risk assessment not found")
+			return
+		}
+		logger.ErrorWithErr(err, "Failed to get risk assessment")
+		respondWithError(w, http.StatusInternalServerError, "Failed to get risk assessment")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, assessment)
+}
+
+// Approve handles POST /admin/risk/assessments/{id}/approve
+func (h *Handler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.Approve)
+}
+
+// Reject handles POST /admin/risk/assessments/{id}/reject
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.Reject)
+}
+
+func (h *Handler) review(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, id, reviewerID, note string) (*Assessment, error)) {
+	reviewerID, ok := admin.AdminIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Admin ID not found in request")
+		return
+	}
+
+	var req ReviewRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	assessment, err := decide(r.Context(), r.PathValue("id"), reviewerID, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAssessmentNotFound):
+			respondWithError(w, http.StatusNotFound, "Risk assessment not found")
+		case errors.Is(err, ErrReviewClosed):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			logger.ErrorWithErr(err, "Failed to review risk assessment")
+			respondWithError(w, http.StatusInternalServerError, "Failed to review risk assessment")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, assessment)
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
+
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	respondWithJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,317 @@
+package risk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Decision is the outcome of a risk assessment
+type Decision string
+
+const (
+	DecisionAllow  Decision = "ALLOW"
+	DecisionReview Decision = "REVIEW"
+	DecisionBlock  Decision = "BLOCK"
+)
+
+// Stage is the point in the booking flow an assessment was made at
+type Stage string
+
+const (
+	// StageBooking scores a new booking before inventory is held
+	StageBooking Stage = "BOOKING"
+	// StagePayment scores a payment attempt before it is sent to the gateway
+	StagePayment Stage = "PAYMENT"
+	// StageGateway scores the gateway result, including the Midtrans FDS verdict
+	StageGateway Stage = "GATEWAY"
+)
+
+// ReviewStatus tracks an assessment through the manual review queue
+type ReviewStatus string
+
+const (
+	ReviewStatusNotRequired ReviewStatus = "NOT_REQUIRED"
+	ReviewStatusPending     ReviewStatus = "PENDING"
+	ReviewStatusApproved    ReviewStatus = "APPROVED"
+	ReviewStatusRejected    ReviewStatus = "REJECTED"
+)
+
+// Midtrans fraud detection (FDS) verdicts on card transactions
+const (
+	FDSAccept    = "accept"
+	FDSChallenge = "challenge"
+	FDSDeny      = "deny"
+)
+
+// Signal codes
+const (
+	SignalNewAccount        = "NEW_ACCOUNT"
+	SignalEmailUnverified   = "EMAIL_UNVERIFIED"
+	SignalUserVelocity      = "USER_VELOCITY"
+	SignalIPVelocity        = "IP_VELOCITY"
+	SignalCardVelocity      = "CARD_VELOCITY"
+	SignalGuestNameMismatch = "GUEST_NAME_MISMATCH"
+	SignalHighValue         = "HIGH_VALUE"
+	SignalLastMinute        = "LAST_MINUTE"
+	SignalCountryMismatch   = "COUNTRY_MISMATCH"
+	SignalFDSChallenge      = "FDS_CHALLENGE"
+	SignalFDSDeny           = "FDS_DENY"
+)
+
+// signalPoints is how much each signal adds to the score (0-100)
+var signalPoints = map[string]int{
+	SignalNewAccount:        15,
+	SignalEmailUnverified:   15,
+	SignalUserVelocity:      25,
+	SignalIPVelocity:        25,
+	SignalCardVelocity:      35,
+	SignalGuestNameMismatch: 10,
+	SignalHighValue:         20,
+	SignalLastMinute:        10,
+	SignalCountryMismatch:   20,
+	SignalFDSChallenge:      40,
+	SignalFDSDeny:           100,
+}
+
+// MaxScore is the highest possible risk score
+const MaxScore = 100
+
+// Config holds the risk thresholds and velocity limits
+type Config struct {
+	ReviewScore        int           // Scores at or above this are held for review
+	BlockScore         int           // Scores at or above this are blocked
+	HighValueAmount    int           // Totals (IDR) at or above this are high value
+	LastMinuteWindow   time.Duration // Check-ins sooner than this are last-minute
+	NewAccountAge      time.Duration // Accounts younger than this are new
+	VelocityWindow     time.Duration // Window attempts are counted over
+	MaxAttemptsPerUser int
+	MaxAttemptsPerIP   int
+	MaxAccountsPerCard int // Accounts a card may be used by within the window
+}
+
+// DefaultConfig returns the default risk configuration
+func DefaultConfig() Config {
+	return Config{
+		ReviewScore:        40,
+		BlockScore:         80,
+		HighValueAmount:    10000000,
+		LastMinuteWindow:   24 * time.Hour,
+		NewAccountAge:      24 * time.Hour,
+		VelocityWindow:     time.Hour,
+		MaxAttemptsPerUser: 5,
+		MaxAttemptsPerIP:   10,
+		MaxAccountsPerCard: 1,
+	}
+}
+
+// Decide maps a score to a decision using the configured thresholds
+func (c Config) Decide(score int) Decision {
+	switch {
+	case score >= c.BlockScore:
+		return DecisionBlock
+	case score >= c.ReviewScore:
+		return DecisionReview
+	default:
+		return DecisionAllow
+	}
+}
+
+// CardFingerprint identifies a card by a hash of its gateway token, so card
+// velocity is counted without storing the token. An empty token gives an
+// empty fingerprint.
+func CardFingerprint(cardToken string) string {
+	if cardToken == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(cardToken))
+	return hex.EncodeToString(hash[:])
+}
+
+// Attempt is a booking or payment attempt to score
+type Attempt struct {
+	Stage           Stage
+	BookingID       string
+	PaymentID       string
+	UserID          string
+	IPAddress       string
+	IPCountry       string // ISO 3166-1 alpha-2
+	CardFingerprint string // See CardFingerprint; empty when the card is unknown
+	CardCountry     string // ISO 3166-1 alpha-2 of the issuing bank
+	GuestName       string
+	Amount          int
+	Currency        string
+	CheckIn         time.Time
+	FDSStatus       string
+}
+
+// Profile is what we know about the account making the attempt
+type Profile struct {
+	Name          string
+	EmailVerified bool
+	CreatedAt     time.Time
+}
+
+// Velocity counts recent attempts sharing the user, IP or card
+type Velocity struct {
+	UserAttempts int
+	IPAttempts   int
+	CardAccounts int // Other accounts that used the card
+}
+
+// Signal is one risk indicator that contributed to a score
+type Signal struct {
+	Code   string `json:"code"`
+	Points int    `json:"points"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Assessment is a scored attempt and, when held, its review
+type Assessment struct {
+	ID              string       `json:"id" db:"id"`
+	Stage           Stage        `json:"stage" db:"stage"`
+	BookingID       string       `json:"booking_id,omitempty" db:"booking_id"`
+	PaymentID       string       `json:"payment_id,omitempty" db:"payment_id"`
+	UserID          string       `json:"user_id,omitempty" db:"user_id"`
+	IPAddress       string       `json:"ip_address,omitempty" db:"ip_address"`
+	IPCountry       string       `json:"ip_country,omitempty" db:"ip_country"`
+	CardFingerprint string       `json:"card_fingerprint,omitempty" db:"card_fingerprint"`
+	CardCountry     string       `json:"card_country,omitempty" db:"card_country"`
+	Amount          int          `json:"amount" db:"amount"`
+	Currency        string       `json:"currency,omitempty" db:"currency"`
+	Score           int          `json:"score" db:"score"`
+	Decision        Decision     `json:"decision" db:"decision"`
+	Signals         []Signal     `json:"signals" db:"signals"`
+	FDSStatus       string       `json:"fds_status,omitempty" db:"fds_status"`
+	ReviewStatus    ReviewStatus `json:"review_status" db:"review_status"`
+	ReviewedBy      string       `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewNote      string       `json:"review_note,omitempty" db:"review_note"`
+	ReviewedAt      *time.Time   `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt       time.Time    `json:"created_at" db:"created_at"`
+}
+
+// NewAssessment creates an assessment for an attempt from its signals
+func NewAssessment(attempt *Attempt, signals []Signal, cfg Config) *Assessment {
+	score := 0
+	for _, s := range signals {
+		score += s.Points
+	}
+	if score > MaxScore {
+		score = MaxScore
+	}
+
+	decision := cfg.Decide(score)
+
+	// The gateway's own verdict is a floor, whatever our score says
+	switch attempt.FDSStatus {
+	case FDSDeny:
+		decision = DecisionBlock
+	case FDSChallenge:
+		if decision == DecisionAllow {
+			decision = DecisionReview
+		}
+	}
+
+	reviewStatus := ReviewStatusNotRequired
+	if decision == DecisionReview {
+		reviewStatus = ReviewStatusPending
+	}
+
+	if signals == nil {
+		signals = []Signal{}
+	}
+
+	return &Assessment{
+		ID:              uuid.New().String(),
+		Stage:           attempt.Stage,
+		BookingID:       attempt.BookingID,
+		PaymentID:       attempt.PaymentID,
+		UserID:          attempt.UserID,
+		IPAddress:       attempt.IPAddress,
+		IPCountry:       attempt.IPCountry,
+		CardFingerprint: attempt.CardFingerprint,
+		CardCountry:     attempt.CardCountry,
+		Amount:          attempt.Amount,
+		Currency:        attempt.Currency,
+		Score:           score,
+		Decision:        decision,
+		Signals:         signals,
+		FDSStatus:       attempt.FDSStatus,
+		ReviewStatus:    reviewStatus,
+		CreatedAt:       time.Now(),
+	}
+}
+
+// Evaluate returns the signals raised by an attempt. A nil profile means the
+// account is unknown and account signals are skipped.
+func Evaluate(cfg Config, attempt *Attempt, profile *Profile, velocity Velocity, now time.Time) []Signal {
+	var signals []Signal
+	add := func(code, detail string) {
+		signals = append(signals, Signal{Code: code, Points: signalPoints[code], Detail: detail})
+	}
+
+	if profile != nil {
+		if !profile.CreatedAt.IsZero() && now.Sub(profile.CreatedAt) < cfg.NewAccountAge {
+			add(SignalNewAccount, "account created "+profile.CreatedAt.Format(time.RFC3339))
+		}
+		if !profile.EmailVerified {
+			add(SignalEmailUnverified, "")
+		}
+		if attempt.GuestName != "" && profile.Name != "" && !namesMatch(attempt.GuestName, profile.Name) {
+			add(SignalGuestNameMismatch, attempt.GuestName+" / "+profile.Name)
+		}
+	}
+
+	if cfg.MaxAttemptsPerUser > 0 && velocity.UserAttempts >= cfg.MaxAttemptsPerUser {
+		add(SignalUserVelocity, "")
+	}
+	if cfg.MaxAttemptsPerIP > 0 && velocity.IPAttempts >= cfg.MaxAttemptsPerIP {
+		add(SignalIPVelocity, attempt.IPAddress)
+	}
+	if attempt.CardFingerprint != "" && cfg.MaxAccountsPerCard > 0 && velocity.CardAccounts >= cfg.MaxAccountsPerCard {
+		add(SignalCardVelocity, attempt.CardFingerprint)
+	}
+
+	if cfg.HighValueAmount > 0 && attempt.Currency == "IDR" && attempt.Amount >= cfg.HighValueAmount {
+		add(SignalHighValue, "")
+	}
+	if !attempt.CheckIn.IsZero() && attempt.CheckIn.Sub(now) < cfg.LastMinuteWindow {
+		add(SignalLastMinute, "check-in "+attempt.CheckIn.Format("2006-01-02"))
+	}
+	if attempt.IPCountry != "" && attempt.CardCountry != "" && !strings.EqualFold(attempt.IPCountry, attempt.CardCountry) {
+		add(SignalCountryMismatch, "IP "+attempt.IPCountry+", card "+attempt.CardCountry)
+	}
+
+	switch attempt.FDSStatus {
+	case FDSChallenge:
+		add(SignalFDSChallenge, "")
+	case FDSDeny:
+		add(SignalFDSDeny, "")
+	}
+
+	return signals
+}
+
+// namesMatch reports whether two names share at least one word, ignoring case
+func namesMatch(a, b string) bool {
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(strings.ToLower(a)) {
+		words[w] = true
+	}
+	for _, w := range strings.Fields(strings.ToLower(b)) {
+		if words[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// ListFilter filters assessments in the admin review queue
+type ListFilter struct {
+	ReviewStatus ReviewStatus
+	Stage        Stage
+	BookingID    string
+}
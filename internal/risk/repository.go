@@ -0,0 +1,232 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// Repository defines interface for risk assessment data operations
+type Repository interface {
+	Create(ctx context.Context, assessment *Assessment) error
+	GetByID(ctx context.Context, id string) (*Assessment, error)
+	// GetLatestByBooking returns the most recent assessment of a booking at any of the stages
+	GetLatestByBooking(ctx context.Context, bookingID string, stages []Stage) (*Assessment, error)
+	// HasApprovedReview reports whether a reviewer approved the booking at any of the stages
+	HasApprovedReview(ctx context.Context, bookingID string, stages []Stage) (bool, error)
+	// Velocity counts booking and payment attempts sharing the attempt's user, IP or card since a time
+	Velocity(ctx context.Context, attempt *Attempt, since time.Time) (Velocity, error)
+	List(ctx context.Context, filter ListFilter, limit, offset int) ([]*Assessment, int, error)
+	UpdateReview(ctx context.Context, assessment *Assessment) error
+}
+
+type repository struct {
+	db *db.DB
+}
+
+// NewRepository creates a new risk repository
+func NewRepository(database *db.DB) Repository {
+	return &repository{
+		db: database,
+	}
+}
+
+const assessmentColumns = `
+	id, stage, COALESCE(booking_id::text, ''), COALESCE(payment_id::text, ''), COALESCE(user_id::text, ''),
+	COALESCE(ip_address, ''), COALESCE(ip_country, ''), COALESCE(card_fingerprint, ''), COALESCE(card_country, ''),
+	amount, currency, score, decision, signals, COALESCE(fds_status, ''), review_status,
+	COALESCE(reviewed_by, ''), COALESCE(review_note, ''), reviewed_at, created_at
+`
+
+func scanAssessment(row pgx.Row) (*Assessment, error) {
+	var a Assessment
+	err := row.Scan(
+		&a.ID, &a.Stage, &a.BookingID, &a.PaymentID, &a.UserID,
+		&a.IPAddress, &a.IPCountry, &a.CardFingerprint, &a.CardCountry,
+		&a.Amount, &a.Currency, &a.Score, &a.Decision, &a.Signals, &a.FDSStatus, &a.ReviewStatus,
+		&a.ReviewedBy, &a.ReviewNote, &a.ReviewedAt, &a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Create stores an assessment
+func (r *repository) Create(ctx context.Context, a *Assessment) error {
+	query := `
+		INSERT INTO risk_assessments (
+			id, stage, booking_id, payment_id, user_id, ip_address, ip_country, card_fingerprint, card_country,
+			amount, currency, score, decision, signals, fds_status, review_status, created_at
+		) VALUES ($1, $2, NULLIF($3, '')::uuid, NULLIF($4, '')::uuid, NULLIF($5, '')::uuid, NULLIF($6, ''),
+			NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), $10, $11, $12, $13, $14, NULLIF($15, ''), $16, $17)
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		a.ID, a.Stage, a.BookingID, a.PaymentID, a.UserID, a.IPAddress, a.IPCountry, a.CardFingerprint, a.CardCountry,
+		a.Amount, a.Currency, a.Score, a.Decision, a.Signals, a.FDSStatus, a.ReviewStatus, a.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create risk assessment: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an assessment by ID
+func (r *repository) GetByID(ctx context.Context, id string) (*Assessment, error) {
+	query := `SELECT ` + assessmentColumns + ` FROM risk_assessments WHERE id = $1`
+
+	a, err := scanAssessment(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAssessmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get risk assessment: %w", err)
+	}
+
+	return a, nil
+}
+
+// GetLatestByBooking retrieves the most recent assessment of a booking
+func (r *repository) GetLatestByBooking(ctx context.Context, bookingID string, stages []Stage) (*Assessment, error) {
+	query := `SELECT ` + assessmentColumns + ` FROM risk_assessments
+		WHERE booking_id = $1 AND stage = ANY($2)
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	a, err := scanAssessment(r.db.Pool.QueryRow(ctx, query, bookingID, stageStrings(stages)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAssessmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get booking risk assessment: %w", err)
+	}
+
+	return a, nil
+}
+
+// HasApprovedReview checks for an approved review of a booking
+func (r *repository) HasApprovedReview(ctx context.Context, bookingID string, stages []Stage) (bool, error) {
+	var approved bool
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM risk_assessments
+			WHERE booking_id = $1 AND stage = ANY($2) AND review_status = $3
+		)
+	`, bookingID, stageStrings(stages), ReviewStatusApproved).Scan(&approved)
+	if err != nil {
+		return false, fmt.Errorf("failed to check risk review: %w", err)
+	}
+
+	return approved, nil
+}
+
+// Velocity counts recent attempts. Gateway results are not attempts of their
+// own, but they are the only place card details are known, so card reuse is
+// counted across all stages.
+func (r *repository) Velocity(ctx context.Context, attempt *Attempt, since time.Time) (Velocity, error) {
+	var v Velocity
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE $2 <> '' AND user_id = NULLIF($2, '')::uuid AND stage <> $5),
+			COUNT(*) FILTER (WHERE $3 <> '' AND ip_address = $3 AND stage <> $5),
+			COUNT(DISTINCT user_id) FILTER (WHERE $4 <> '' AND card_fingerprint = $4
+				AND user_id IS DISTINCT FROM NULLIF($2, '')::uuid)
+		FROM risk_assessments
+		WHERE created_at >= $1
+	`, since, attempt.UserID, attempt.IPAddress, attempt.CardFingerprint, StageGateway).Scan(
+		&v.UserAttempts, &v.IPAttempts, &v.CardAccounts,
+	)
+	if err != nil {
+		return Velocity{}, fmt.Errorf("failed to count risk velocity: %w", err)
+	}
+
+	return v, nil
+}
+
+// List retrieves assessments matching the filter, oldest first so the review queue is worked in order
+func (r *repository) List(ctx context.Context, filter ListFilter, limit, offset int) ([]*Assessment, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ReviewStatus != "" {
+		add("review_status = $%d", filter.ReviewStatus)
+	}
+	if filter.Stage != "" {
+		add("stage = $%d", filter.Stage)
+	}
+	if filter.BookingID != "" {
+		add("booking_id = $%d", filter.BookingID)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM risk_assessments`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count risk assessments: %w", err)
+	}
+
+	args = append(args, limit, offset)
+	query := `SELECT ` + assessmentColumns + ` FROM risk_assessments` + where +
+		fmt.Sprintf(" ORDER BY created_at ASC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list risk assessments: %w", err)
+	}
+	defer rows.Close()
+
+	var assessments []*Assessment
+	for rows.Next() {
+		a, err := scanAssessment(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan risk assessment: %w", err)
+		}
+		assessments = append(assessments, a)
+	}
+
+	return assessments, total, rows.Err()
+}
+
+// UpdateReview stores the outcome of a manual review. Only a pending review
+// can be closed, so of two concurrent decisions the second gets ErrReviewClosed.
+func (r *repository) UpdateReview(ctx context.Context, a *Assessment) error {
+	query := `
+		UPDATE risk_assessments
+		SET review_status = $2, reviewed_by = NULLIF($3, ''), review_note = NULLIF($4, ''), reviewed_at = $5
+		WHERE id = $1 AND review_status = $6
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, a.ID, a.ReviewStatus, a.ReviewedBy, a.ReviewNote, a.ReviewedAt, ReviewStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to update risk review: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrReviewClosed
+	}
+
+	return nil
+}
+
+func stageStrings(stages []Stage) []string {
+	out := make([]string, len(stages))
+	for i, s := range stages {
+		out[i] = string(s)
+	}
+	return out
+}
@@ -0,0 +1,32 @@
+package risk
+
+import (
+	"context"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/booking"
+)
+
+type bookingScreener struct {
+	service Service
+}
+
+// NewBookingScreener adapts the risk service to screen new bookings
+func NewBookingScreener(service Service) booking.RiskScreener {
+	return &bookingScreener{service: service}
+}
+
+func (s *bookingScreener) ScreenBooking(ctx context.Context, b *booking.Booking, client booking.ClientInfo) (booking.RiskDecision, error) {
+	assessment, err := s.service.AssessBooking(ctx, b, client)
+	if err != nil {
+		return booking.RiskAllow, err
+	}
+
+	switch assessment.Decision {
+	case DecisionBlock:
+		return booking.RiskBlock, nil
+	case DecisionReview:
+		return booking.RiskReview, nil
+	default:
+		return booking.RiskAllow, nil
+	}
+}
@@ -0,0 +1,263 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/booking"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/ekonugroho98/be-bookingkuy/internal/user"
+)
+
+// UserReader loads the account behind an attempt
+type UserReader interface {
+	GetByID(ctx context.Context, id string) (*user.User, error)
+}
+
+// BookingReader loads the booking a payment is made for
+type BookingReader interface {
+	GetByID(ctx context.Context, id string) (*booking.Booking, error)
+}
+
+// PaymentAttempt is a payment about to be sent to the gateway
+type PaymentAttempt struct {
+	BookingID string
+	PaymentID string
+	IPAddress string
+	IPCountry string
+}
+
+// GatewayResult is the gateway's verdict on a card transaction
+type GatewayResult struct {
+	BookingID   string
+	PaymentID   string
+	FraudStatus string // Midtrans fraud_status: accept, challenge or deny
+	MaskedCard  string
+	CardToken   string // Gateway's saved-card token, the same for every use of a card
+	CardCountry string
+}
+
+// Service defines interface for risk scoring and review business logic
+type Service interface {
+	AssessBooking(ctx context.Context, b *booking.Booking, client booking.ClientInfo) (*Assessment, error)
+	AssessPayment(ctx context.Context, attempt *PaymentAttempt) (*Assessment, error)
+	RecordGatewayResult(ctx context.Context, result *GatewayResult) (*Assessment, error)
+	GetAssessment(ctx context.Context, id string) (*Assessment, error)
+	ListAssessments(ctx context.Context, filter ListFilter, limit, offset int) ([]*Assessment, int, error)
+	Approve(ctx context.Context, id, reviewerID, note string) (*Assessment, error)
+	Reject(ctx context.Context, id, reviewerID, note string) (*Assessment, error)
+}
+
+type service struct {
+	repo     Repository
+	users    UserReader
+	bookings BookingReader
+	eventBus eventbus.EventBus
+	config   Config
+}
+
+// NewService creates a new risk service
+func NewService(repo Repository, users UserReader, bookings BookingReader, eb eventbus.EventBus, config Config) Service {
+	return &service{
+		repo:     repo,
+		users:    users,
+		bookings: bookings,
+		eventBus: eb,
+		config:   config,
+	}
+}
+
+// AssessBooking scores a new booking before it is saved
+func (s *service) AssessBooking(ctx context.Context, b *booking.Booking, client booking.ClientInfo) (*Assessment, error) {
+	return s.assess(ctx, &Attempt{
+		Stage:     StageBooking,
+		BookingID: b.ID,
+		UserID:    b.UserID,
+		IPAddress: client.IPAddress,
+		IPCountry: client.Country,
+		GuestName: b.GuestName,
+		Amount:    b.TotalAmount,
+		Currency:  b.Currency,
+		CheckIn:   b.CheckIn,
+	}, nil)
+}
+
+// AssessPayment scores a payment attempt before the gateway is charged. A
+// booking a reviewer already approved is not held again.
+func (s *service) AssessPayment(ctx context.Context, attempt *PaymentAttempt) (*Assessment, error) {
+	b, err := s.bookings.GetByID(ctx, attempt.BookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking for risk assessment: %w", err)
+	}
+
+	return s.assess(ctx, &Attempt{
+		Stage:     StagePayment,
+		BookingID: b.ID,
+		PaymentID: attempt.PaymentID,
+		UserID:    b.UserID,
+		IPAddress: attempt.IPAddress,
+		IPCountry: attempt.IPCountry,
+		GuestName: b.GuestName,
+		Amount:    b.TotalAmount,
+		Currency:  b.Currency,
+		CheckIn:   b.CheckIn,
+	}, []Stage{StageBooking, StagePayment})
+}
+
+// RecordGatewayResult scores the gateway's verdict together with the card
+// details only the gateway knows
+func (s *service) RecordGatewayResult(ctx context.Context, result *GatewayResult) (*Assessment, error) {
+	b, err := s.bookings.GetByID(ctx, result.BookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking for risk assessment: %w", err)
+	}
+
+	// Masked numbers are shared by many cards, so a card is told apart by its
+	// token: the gateway's, or the one the booking was guaranteed with
+	cardToken := result.CardToken
+	if cardToken == "" {
+		cardToken = b.PaymentToken
+	}
+
+	attempt := &Attempt{
+		Stage:           StageGateway,
+		BookingID:       b.ID,
+		PaymentID:       result.PaymentID,
+		UserID:          b.UserID,
+		CardFingerprint: CardFingerprint(cardToken),
+		CardCountry:     result.CardCountry,
+		GuestName:       b.GuestName,
+		Amount:          b.TotalAmount,
+		Currency:        b.Currency,
+		CheckIn:         b.CheckIn,
+		FDSStatus:       result.FraudStatus,
+	}
+
+	// The gateway doesn't tell us where the guest is, the earlier attempts do
+	previous, err := s.repo.GetLatestByBooking(ctx, b.ID, []Stage{StagePayment, StageBooking})
+	if err == nil {
+		attempt.IPAddress = previous.IPAddress
+		attempt.IPCountry = previous.IPCountry
+	} else if !errors.Is(err, ErrAssessmentNotFound) {
+		return nil, err
+	}
+
+	return s.assess(ctx, attempt, []Stage{StageGateway})
+}
+
+// assess scores and stores an attempt. A review is skipped when a reviewer
+// already approved the booking at one of the approvedStages; blocks always stand.
+func (s *service) assess(ctx context.Context, attempt *Attempt, approvedStages []Stage) (*Assessment, error) {
+	now := time.Now()
+
+	var profile *Profile
+	if attempt.UserID != "" {
+		u, err := s.users.GetByID(ctx, attempt.UserID)
+		if err != nil {
+			logger.Warnf("Risk assessment without user profile for %s: %v", attempt.UserID, err)
+		} else {
+			profile = &Profile{Name: u.Name, EmailVerified: u.EmailVerified, CreatedAt: u.CreatedAt}
+		}
+	}
+
+	velocity, err := s.repo.Velocity(ctx, attempt, now.Add(-s.config.VelocityWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	assessment := NewAssessment(attempt, Evaluate(s.config, attempt, profile, velocity, now), s.config)
+
+	if assessment.Decision == DecisionReview && attempt.BookingID != "" && len(approvedStages) > 0 {
+		approved, err := s.repo.HasApprovedReview(ctx, attempt.BookingID, approvedStages)
+		if err != nil {
+			return nil, err
+		}
+		if approved {
+			assessment.Decision = DecisionAllow
+			assessment.ReviewStatus = ReviewStatusNotRequired
+		}
+	}
+
+	if err := s.repo.Create(ctx, assessment); err != nil {
+		logger.ErrorWithErr(err, "Failed to save risk assessment")
+		return nil, err
+	}
+
+	logger.Infof("🛡️ Risk %s assessment for booking %s (user %s): score %d, %s",
+		assessment.Stage, assessment.BookingID, assessment.UserID, assessment.Score, assessment.Decision)
+
+	if assessment.ReviewStatus == ReviewStatusPending {
+		s.publish(ctx, eventbus.EventRiskReviewRequired, assessment)
+	}
+
+	return assessment, nil
+}
+
+// GetAssessment returns an assessment with its signals
+func (s *service) GetAssessment(ctx context.Context, id string) (*Assessment, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListAssessments lists assessments, e.g. the pending review queue
+func (s *service) ListAssessments(ctx context.Context, filter ListFilter, limit, offset int) ([]*Assessment, int, error) {
+	return s.repo.List(ctx, filter, limit, offset)
+}
+
+// Approve releases a held booking or payment
+func (s *service) Approve(ctx context.Context, id, reviewerID, note string) (*Assessment, error) {
+	return s.review(ctx, id, reviewerID, note, ReviewStatusApproved)
+}
+
+// Reject cancels a held booking or payment
+func (s *service) Reject(ctx context.Context, id, reviewerID, note string) (*Assessment, error) {
+	return s.review(ctx, id, reviewerID, note, ReviewStatusRejected)
+}
+
+func (s *service) review(ctx context.Context, id, reviewerID, note string, status ReviewStatus) (*Assessment, error) {
+	assessment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if assessment.ReviewStatus != ReviewStatusPending {
+		return nil, ErrReviewClosed
+	}
+
+	now := time.Now()
+	assessment.ReviewStatus = status
+	assessment.ReviewedBy = reviewerID
+	assessment.ReviewNote = note
+	assessment.ReviewedAt = &now
+
+	if err := s.repo.UpdateReview(ctx, assessment); err != nil {
+		logger.ErrorWithErr(err, "Failed to update risk review")
+		return nil, err
+	}
+
+	eventType := eventbus.EventRiskReviewApproved
+	if status == ReviewStatusRejected {
+		eventType = eventbus.EventRiskReviewRejected
+	}
+	s.publish(ctx, eventType, assessment)
+
+	logger.Infof("🛡️ Risk review %s %s by %s (booking %s)", assessment.ID, status, reviewerID, assessment.BookingID)
+	return assessment, nil
+}
+
+func (s *service) publish(ctx context.Context, eventType string, a *Assessment) {
+	if err := s.eventBus.Publish(ctx, eventType, map[string]interface{}{
+		"assessment_id": a.ID,
+		"booking_id":    a.BookingID,
+		"payment_id":    a.PaymentID,
+		"user_id":       a.UserID,
+		"stage":         string(a.Stage),
+		"score":         a.Score,
+		"fds_status":    a.FDSStatus,
+		"review_status": string(a.ReviewStatus),
+	}); err != nil {
+		logger.ErrorWithErr(err, fmt.Sprintf("Failed to publish %s event", eventType))
+	}
+}
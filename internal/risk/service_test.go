@@ -0,0 +1,423 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/booking"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
+	"github.com/ekonugroho98/be-bookingkuy/internal/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRepository is a mock implementation of risk.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, assessment *Assessment) error {
+	args := m.Called(ctx, assessment)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetByID(ctx context.Context, id string) (*Assessment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Assessment), args.Error(1)
+}
+
+func (m *MockRepository) GetLatestByBooking(ctx context.Context, bookingID string, stages []Stage) (*Assessment, error) {
+	args := m.Called(ctx, bookingID, stages)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Assessment), args.Error(1)
+}
+
+func (m *MockRepository) HasApprovedReview(ctx context.Context, bookingID string, stages []Stage) (bool, error) {
+	args := m.Called(ctx, bookingID, stages)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) Velocity(ctx context.Context, attempt *Attempt, since time.Time) (Velocity, error) {
+	args := m.Called(ctx, attempt, since)
+	return args.Get(0).(Velocity), args.Error(1)
+}
+
+func (m *MockRepository) List(ctx context.Context, filter ListFilter, limit, offset int) ([]*Assessment, int, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*Assessment), args.Int(1), args.Error(2)
+}
+
+func (m *MockRepository) UpdateReview(ctx context.Context, assessment *Assessment) error {
+	args := m.Called(ctx, assessment)
+	return args.Error(0)
+}
+
+// MockUserReader is a mock implementation of risk.UserReader
+type MockUserReader struct {
+	mock.Mock
+}
+
+func (m *MockUserReader) GetByID(ctx context.Context, id string) (*user.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+// MockBookingReader is a mock implementation of risk.BookingReader
+type MockBookingReader struct {
+	mock.Mock
+}
+
+func (m *MockBookingReader) GetByID(ctx context.Context, id string) (*booking.Booking, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*booking.Booking), args.Error(1)
+}
+
+// MockEventBus is a mock implementation of eventbus.EventBus
+type MockEventBus struct {
+	mock.Mock
+}
+
+func (m *MockEventBus) Publish(ctx context.Context, eventType string, data map[string]interface{}) error {
+	args := m.Called(ctx, eventType, data)
+	return args.Error(0)
+}
+
+func (m *MockEventBus) Subscribe(ctx context.Context, eventType string, handler eventbus.Handler) error {
+	args := m.Called(ctx, eventType, handler)
+	return args.Error(0)
+}
+
+func (m *MockEventBus) SubscribeAsync(ctx context.Context, eventType string, handler eventbus.Handler) error {
+	args := m.Called(ctx, eventType, handler)
+	return args.Error(0)
+}
+
+func testBooking() *booking.Booking {
+	return &booking.Booking{
+		ID:          "booking-1",
+		UserID:      "user-1",
+		GuestName:   "Budi Santoso",
+		TotalAmount: 1500000,
+		Currency:    "IDR",
+		CheckIn:     time.Now().AddDate(0, 0, 14),
+	}
+}
+
+func trustedUser() *user.User {
+	return &user.User{
+		ID:            "user-1",
+		Name:          "Budi Santoso",
+		EmailVerified: true,
+		CreatedAt:     time.Now().AddDate(-1, 0, 0),
+	}
+}
+
+func signalCodes(signals []Signal) []string {
+	codes := make([]string, 0, len(signals))
+	for _, s := range signals {
+		codes = append(codes, s.Code)
+	}
+	return codes
+}
+
+func TestEvaluate(t *testing.T) {
+	cfg := DefaultConfig()
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		attempt  Attempt
+		profile  *Profile
+		velocity Velocity
+		want     []string
+	}{
+		{
+			name:    "clean attempt",
+			attempt: Attempt{GuestName: "Budi Santoso", Amount: 1500000, Currency: "IDR", CheckIn: now.AddDate(0, 0, 7)},
+			profile: &Profile{Name: "Budi", EmailVerified: true, CreatedAt: now.AddDate(0, -6, 0)},
+			want:    []string{},
+		},
+		{
+			name:    "new unverified account booking for someone else",
+			attempt: Attempt{GuestName: "John Doe", Currency: "IDR", CheckIn: now.AddDate(0, 0, 7)},
+			profile: &Profile{Name: "Budi Santoso", CreatedAt: now.Add(-time.Hour)},
+			want:    []string{SignalNewAccount, SignalEmailUnverified, SignalGuestNameMismatch},
+		},
+		{
+			name:     "velocity",
+			attempt:  Attempt{IPAddress: "203.0.113.7", CardFingerprint: "481111-1114", Currency: "IDR", CheckIn: now.AddDate(0, 0, 7)},
+			velocity: Velocity{UserAttempts: 5, IPAttempts: 10, CardAccounts: 1},
+			want:     []string{SignalUserVelocity, SignalIPVelocity, SignalCardVelocity},
+		},
+		{
+			name:    "high value last minute from abroad",
+			attempt: Attempt{Amount: 12000000, Currency: "IDR", CheckIn: now.Add(6 * time.Hour), IPCountry: "RU", CardCountry: "ID"},
+			want:    []string{SignalHighValue, SignalLastMinute, SignalCountryMismatch},
+		},
+		{
+			name:    "gateway challenge",
+			attempt: Attempt{Currency: "IDR", CheckIn: now.AddDate(0, 0, 7), FDSStatus: FDSChallenge},
+			want:    []string{SignalFDSChallenge},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signals := Evaluate(cfg, &tt.attempt, tt.profile, tt.velocity, now)
+			assert.Equal(t, tt.want, signalCodes(signals))
+		})
+	}
+}
+
+func TestNewAssessment(t *testing.T) {
+	cfg := DefaultConfig()
+
+	tests := []struct {
+		name     string
+		fds      string
+		signals  []Signal
+		score    int
+		decision Decision
+		review   ReviewStatus
+	}{
+		{"no signals", "", nil, 0, DecisionAllow, ReviewStatusNotRequired},
+		{"review threshold", "", []Signal{{Points: 25}, {Points: 15}}, 40, DecisionReview, ReviewStatusPending},
+		{"block threshold", "", []Signal{{Points: 35}, {Points: 25}, {Points: 20}}, 80, DecisionBlock, ReviewStatusNotRequired},
+		{"score is capped", FDSDeny, []Signal{{Points: 100}, {Points: 20}}, MaxScore, DecisionBlock, ReviewStatusNotRequired},
+		{"challenge is at least a review", FDSChallenge, []Signal{{Points: 10}}, 10, DecisionReview, ReviewStatusPending},
+		{"deny always blocks", FDSDeny, nil, 0, DecisionBlock, ReviewStatusNotRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAssessment(&Attempt{Stage: StageGateway, FDSStatus: tt.fds}, tt.signals, cfg)
+			assert.Equal(t, tt.score, a.Score)
+			assert.Equal(t, tt.decision, a.Decision)
+			assert.Equal(t, tt.review, a.ReviewStatus)
+			assert.NotNil(t, a.Signals)
+		})
+	}
+}
+
+func TestService_AssessBooking_Review(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	mockUsers := new(MockUserReader)
+	mockEventBus := new(MockEventBus)
+	svc := NewService(mockRepo, mockUsers, new(MockBookingReader), mockEventBus, DefaultConfig())
+
+	newUser := trustedUser()
+	newUser.EmailVerified = false
+	newUser.CreatedAt = time.Now().Add(-time.Hour)
+
+	b := testBooking()
+	b.CheckIn = time.Now().Add(6 * time.Hour)
+
+	mockUsers.On("GetByID", ctx, "user-1").Return(newUser, nil)
+	mockRepo.On("Velocity", ctx, mock.Anything, mock.Anything).Return(Velocity{}, nil)
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*risk.Assessment")).Return(nil)
+	mockEventBus.On("Publish", ctx, eventbus.EventRiskReviewRequired, mock.MatchedBy(func(p map[string]interface{}) bool {
+		return p["booking_id"] == "booking-1" && p["stage"] == string(StageBooking)
+	})).Return(nil)
+
+	a, err := svc.AssessBooking(ctx, b, booking.ClientInfo{IPAddress: "203.0.113.7", Country: "ID"})
+
+	require.NoError(t, err)
+	assert.Equal(t, DecisionReview, a.Decision)
+	assert.Equal(t, ReviewStatusPending, a.ReviewStatus)
+	assert.Equal(t, 40, a.Score)
+	mockEventBus.AssertExpectations(t)
+}
+
+func TestService_AssessPayment_AlreadyApproved(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	mockUsers := new(MockUserReader)
+	mockBookings := new(MockBookingReader)
+	mockEventBus := new(MockEventBus)
+	svc := NewService(mockRepo, mockUsers, mockBookings, mockEventBus, DefaultConfig())
+
+	b := testBooking()
+	b.TotalAmount = 15000000
+
+	mockBookings.On("GetByID", ctx, "booking-1").Return(b, nil)
+	mockUsers.On("GetByID", ctx, "user-1").Return(trustedUser(), nil)
+	mockRepo.On("Velocity", ctx, mock.Anything, mock.Anything).Return(Velocity{UserAttempts: 5}, nil)
+	mockRepo.On("HasApprovedReview", ctx, "booking-1", []Stage{StageBooking, StagePayment}).Return(true, nil)
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*risk.Assessment")).Return(nil)
+
+	a, err := svc.AssessPayment(ctx, &PaymentAttempt{BookingID: "booking-1", PaymentID: "payment-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 45, a.Score)
+	assert.Equal(t, DecisionAllow, a.Decision)
+	assert.Equal(t, ReviewStatusNotRequired, a.ReviewStatus)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_RecordGatewayResult_Deny(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	mockUsers := new(MockUserReader)
+	mockBookings := new(MockBookingReader)
+	svc := NewService(mockRepo, mockUsers, mockBookings, new(MockEventBus), DefaultConfig())
+
+	mockBookings.On("GetByID", ctx, "booking-1").Return(testBooking(), nil)
+	mockRepo.On("GetLatestByBooking", ctx, "booking-1", []Stage{StagePayment, StageBooking}).
+		Return(&Assessment{IPAddress: "203.0.113.7", IPCountry: "ID"}, nil)
+	mockUsers.On("GetByID", ctx, "user-1").Return(trustedUser(), nil)
+	mockRepo.On("Velocity", ctx, mock.Anything, mock.Anything).Return(Velocity{}, nil)
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*risk.Assessment")).Return(nil)
+
+	a, err := svc.RecordGatewayResult(ctx, &GatewayResult{
+		BookingID:   "booking-1",
+		PaymentID:   "payment-1",
+		FraudStatus: FDSDeny,
+		MaskedCard:  "481111-1114",
+		CardToken:   "481111-saved-token-a",
+		CardCountry: "ID",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, DecisionBlock, a.Decision)
+	assert.Equal(t, "203.0.113.7", a.IPAddress)
+	assert.Equal(t, CardFingerprint("481111-saved-token-a"), a.CardFingerprint)
+}
+
+// TestService_RecordGatewayResult_CardFingerprint tests that cards are told
+// apart by their token, not by their masked number
+func TestService_RecordGatewayResult_CardFingerprint(t *testing.T) {
+	tests := []struct {
+		name         string
+		cardToken    string
+		bookingToken string
+		want         string
+	}{
+		{"gateway token", "saved-token-a", "", CardFingerprint("saved-token-a")},
+		{"booking guarantee token", "", "saved-token-b", CardFingerprint("saved-token-b")},
+		{"no token", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockRepo := new(MockRepository)
+			mockUsers := new(MockUserReader)
+			mockBookings := new(MockBookingReader)
+			svc := NewService(mockRepo, mockUsers, mockBookings, new(MockEventBus), DefaultConfig())
+
+			b := testBooking()
+			b.PaymentToken = tt.bookingToken
+			mockBookings.On("GetByID", ctx, "booking-1").Return(b, nil)
+			mockRepo.On("GetLatestByBooking", ctx, "booking-1", mock.Anything).Return(nil, ErrAssessmentNotFound)
+			mockUsers.On("GetByID", ctx, "user-1").Return(trustedUser(), nil)
+			mockRepo.On("Velocity", ctx, mock.Anything, mock.Anything).Return(Velocity{}, nil)
+			mockRepo.On("Create", ctx, mock.AnythingOfType("*risk.Assessment")).Return(nil)
+
+			a, err := svc.RecordGatewayResult(ctx, &GatewayResult{
+				BookingID:   "booking-1",
+				PaymentID:   "payment-1",
+				FraudStatus: FDSAccept,
+				MaskedCard:  "481111-1114",
+				CardToken:   tt.cardToken,
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, a.CardFingerprint)
+		})
+	}
+
+	assert.NotEqual(t, CardFingerprint("saved-token-a"), CardFingerprint("saved-token-b"),
+		"two cards with the same masked number have different fingerprints")
+}
+
+func TestService_Review(t *testing.T) {
+	tests := []struct {
+		name      string
+		approve   bool
+		wantEvent string
+		want      ReviewStatus
+	}{
+		{"approve", true, eventbus.EventRiskReviewApproved, ReviewStatusApproved},
+		{"reject", false, eventbus.EventRiskReviewRejected, ReviewStatusRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockRepo := new(MockRepository)
+			mockEventBus := new(MockEventBus)
+			svc := NewService(mockRepo, new(MockUserReader), new(MockBookingReader), mockEventBus, DefaultConfig())
+
+			mockRepo.On("GetByID", ctx, "assessment-1").Return(&Assessment{
+				ID: "assessment-1", BookingID: "booking-1", Stage: StageBooking, ReviewStatus: ReviewStatusPending,
+			}, nil)
+			mockRepo.On("UpdateReview", ctx, mock.AnythingOfType("*risk.Assessment")).Return(nil)
+			mockEventBus.On("Publish", ctx, tt.wantEvent, mock.Anything).Return(nil)
+
+			var a *Assessment
+			var err error
+			if tt.approve {
+				a, err = svc.Approve(ctx, "assessment-1", "admin-1", "guest verified by phone")
+			} else {
+				a, err = svc.Reject(ctx, "assessment-1", "admin-1", "stolen card")
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, a.ReviewStatus)
+			assert.Equal(t, "admin-1", a.ReviewedBy)
+			assert.NotNil(t, a.ReviewedAt)
+			mockEventBus.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_Review_AlreadyClosed(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo, new(MockUserReader), new(MockBookingReader), new(MockEventBus), DefaultConfig())
+
+	mockRepo.On("GetByID", ctx, "assessment-1").Return(&Assessment{
+		ID: "assessment-1", ReviewStatus: ReviewStatusApproved,
+	}, nil)
+
+	_, err := svc.Reject(ctx, "assessment-1", "admin-1", "")
+
+	assert.ErrorIs(t, err, ErrReviewClosed)
+	mockRepo.AssertNotCalled(t, "UpdateReview", mock.Anything, mock.Anything)
+}
+
+// TestService_Review_ClosedConcurrently tests that a decision losing the race
+// to another one publishes nothing
+func TestService_Review_ClosedConcurrently(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	mockEventBus := new(MockEventBus)
+	svc := NewService(mockRepo, new(MockUserReader), new(MockBookingReader), mockEventBus, DefaultConfig())
+
+	mockRepo.On("GetByID", ctx, "assessment-1").Return(&Assessment{
+		ID: "assessment-1", ReviewStatus: ReviewStatusPending,
+	}, nil)
+	mockRepo.On("UpdateReview", ctx, mock.Anything).Return(ErrReviewClosed)
+
+	_, err := svc.Approve(ctx, "assessment-1", "admin-1", "")
+
+	assert.ErrorIs(t, err, ErrReviewClosed)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,63 @@
+package search
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Autocomplete ranking: a match at the start of a name beats a slightly
+// closer one mid-name, and popularity lifts well-known places and hotels
+// over obscure ones matching about as well
+const (
+	prefixBoost          = 0.1
+	maxPopularityBoost   = 0.2
+	popularitySaturation = 1000 // Popularity earning the full boost
+)
+
+// rankSuggestions blends destination and hotel suggestions best first and
+// keeps the top limit
+func rankSuggestions(suggestions []Suggestion, limit int) []AutocompleteResult {
+	slices.SortStableFunc(suggestions, func(a, b Suggestion) int {
+		return cmp.Or(
+			cmp.Compare(suggestionScore(b), suggestionScore(a)),
+			cmp.Compare(typeRank(a.Type), typeRank(b.Type)),
+			strings.Compare(a.Name, b.Name),
+		)
+	})
+
+	results := []AutocompleteResult{}
+	for _, s := range suggestions {
+		if len(results) == limit {
+			break
+		}
+		results = append(results, s.AutocompleteResult)
+	}
+	return results
+}
+
+// suggestionScore is a suggestion's similarity with its ranking boosts
+func suggestionScore(s Suggestion) float64 {
+	score := s.Similarity
+	if s.Prefix {
+		score += prefixBoost
+	}
+	if s.Popularity > 0 {
+		// Logarithmic, so the first hotels or reviews count the most
+		score += maxPopularityBoost * min(1, math.Log1p(float64(s.Popularity))/math.Log1p(popularitySaturation))
+	}
+	return score
+}
+
+// typeRank orders equally scored suggestions broadest first
+func typeRank(t AutocompleteResultType) int {
+	switch t {
+	case AutocompleteTypeRegion:
+		return 0
+	case AutocompleteTypeCity:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,195 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func suggestion(resultType AutocompleteResultType, name string, similarity float64, prefix bool, popularity int) Suggestion {
+	return Suggestion{
+		AutocompleteResult: AutocompleteResult{Type: resultType, ID: name, Name: name},
+		Similarity:         similarity,
+		Prefix:             prefix,
+		Popularity:         popularity,
+	}
+}
+
+func resultNames(results []AutocompleteResult) []string {
+	names := []string{}
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// TestRankSuggestions tests blending destinations and hotels by similarity,
+// prefix and popularity
+func TestRankSuggestions(t *testing.T) {
+	suggestions := []Suggestion{
+		suggestion(AutocompleteTypeHotel, "Bandung Hostel", 0.7, true, 0),
+		suggestion(AutocompleteTypeCity, "Bandung", 0.7, true, 250),
+		suggestion(AutocompleteTypeHotel, "Hotel Bandung Permai", 0.75, false, 0),
+		suggestion(AutocompleteTypeRegion, "Bandung Barat", 0.6, true, 0),
+		suggestion(AutocompleteTypeCity, "Bandar Lampung", 0.4, true, 40),
+	}
+
+	results := rankSuggestions(suggestions, 4)
+
+	assert.Equal(t, []string{"Bandung", "Bandung Hostel", "Hotel Bandung Permai", "Bandung Barat"}, resultNames(results))
+}
+
+// TestRankSuggestions_Ties tests that equally good suggestions list the
+// broadest first
+func TestRankSuggestions_Ties(t *testing.T) {
+	suggestions := []Suggestion{
+		suggestion(AutocompleteTypeHotel, "Bali", 1, true, 0),
+		suggestion(AutocompleteTypeCity, "Bali", 1, true, 0),
+		suggestion(AutocompleteTypeRegion, "Bali", 1, true, 0),
+	}
+
+	results := rankSuggestions(suggestions, 10)
+
+	require.Len(t, results, 3)
+	assert.Equal(t, AutocompleteTypeRegion, results[0].Type)
+	assert.Equal(t, AutocompleteTypeCity, results[1].Type)
+	assert.Equal(t, AutocompleteTypeHotel, results[2].Type)
+}
+
+// TestSuggestionScore_PopularitySaturates tests that popularity lifts a
+// suggestion by at most the full boost
+func TestSuggestionScore_PopularitySaturates(t *testing.T) {
+	base := suggestionScore(suggestion(AutocompleteTypeCity, "Bali", 0.5, false, 0))
+
+	assert.Greater(t, suggestionScore(suggestion(AutocompleteTypeCity, "Bali", 0.5, false, 10)), base)
+	assert.InDelta(t, base+maxPopularityBoost, suggestionScore(suggestion(AutocompleteTypeCity, "Bali", 0.5, false, popularitySaturation)), 1e-9)
+	assert.InDelta(t, base+maxPopularityBoost, suggestionScore(suggestion(AutocompleteTypeCity, "Bali", 0.5, false, 50000)), 1e-9)
+}
+
+// TestService_Autocomplete tests blending destination and hotel suggestions
+func TestService_Autocomplete(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, testGateway())
+
+	jogja := suggestion(AutocompleteTypeCity, "Yogyakarta", 0.8, true, 120)
+	jogja.MatchedAlias = "Jogja"
+	mockRepo.On("SuggestDestinations", mock.Anything, "Jogja", []AutocompleteResultType(nil), 10).Return([]Suggestion{jogja}, nil)
+	mockRepo.On("SuggestHotels", mock.Anything, "Jogja", 10).Return([]Suggestion{
+		suggestion(AutocompleteTypeHotel, "Jogja Plaza Hotel", 0.8, true, 15),
+	}, nil)
+
+	result, err := service.Autocomplete(context.Background(), &AutocompleteRequest{Query: " Jogja ", Limit: 5})
+
+	require.NoError(t, err)
+	assert.False(t, result.Partial)
+	assert.Equal(t, []string{"Yogyakarta", "Jogja Plaza Hotel"}, resultNames(result.Results))
+	assert.Equal(t, "Jogja", result.Results[0].MatchedAlias)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestService_Autocomplete_ShortQuery tests that one character finds nothing
+// without querying
+func TestService_Autocomplete_ShortQuery(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, testGateway())
+
+	result, err := service.Autocomplete(context.Background(), &AutocompleteRequest{Query: "é ", Limit: 10})
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Results)
+	mockRepo.AssertNotCalled(t, "SuggestDestinations", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestService_Autocomplete_LatencyBudget tests that a source missing the
+// budget is left out and the response marked partial
+func TestService_Autocomplete_LatencyBudget(t *testing.T) {
+	mockRepo := new(MockRepository)
+	config := DefaultConfig()
+	config.AutocompleteBudget = 20 * time.Millisecond
+	service := NewService(mockRepo, testGateway(), nil, config)
+
+	mockRepo.On("SuggestDestinations", mock.Anything, "Bandung", []AutocompleteResultType(nil), 20).Return([]Suggestion{
+		suggestion(AutocompleteTypeCity, "Bandung", 1, true, 0),
+	}, nil)
+	mockRepo.On("SuggestHotels", mock.Anything, "Bandung", 20).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil, context.DeadlineExceeded)
+
+	result, err := service.Autocomplete(context.Background(), &AutocompleteRequest{Query: "Bandung", Limit: 10})
+
+	require.NoError(t, err)
+	assert.True(t, result.Partial)
+	assert.Equal(t, []string{"Bandung"}, resultNames(result.Results))
+}
+
+// TestService_Autocomplete_AllSourcesFail tests that autocomplete fails when
+// no source answers
+func TestService_Autocomplete_AllSourcesFail(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, testGateway())
+
+	mockRepo.On("SuggestDestinations", mock.Anything, "Bandung", []AutocompleteResultType(nil), 20).Return(nil, errors.New("connection refused"))
+	mockRepo.On("SuggestHotels", mock.Anything, "Bandung", 20).Return(nil, errors.New("connection refused"))
+
+	result, err := service.Autocomplete(context.Background(), &AutocompleteRequest{Query: "Bandung", Limit: 10})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+}
+
+// TestService_Autocomplete_Types tests that only the sources of the
+// requested types are searched
+func TestService_Autocomplete_Types(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, testGateway())
+
+	kuta := suggestion(AutocompleteTypeLandmark, "Kuta Beach", 0.9, true, 0)
+	kuta.Code, kuta.Region, kuta.RegionCode = "KUTA-BEACH", "Bali", "DPS"
+	mockRepo.On("SuggestDestinations", mock.Anything, "Kuta", []AutocompleteResultType{AutocompleteTypeCity, AutocompleteTypeLandmark}, 20).
+		Return([]Suggestion{kuta}, nil)
+
+	result, err := service.Autocomplete(context.Background(), &AutocompleteRequest{
+		Query: "Kuta",
+		Types: []AutocompleteResultType{AutocompleteTypeCity, AutocompleteTypeLandmark},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, AutocompleteTypeLandmark, result.Results[0].Type)
+	assert.Equal(t, "DPS", result.Results[0].RegionCode)
+	mockRepo.AssertNotCalled(t, "SuggestHotels", mock.Anything, mock.Anything, mock.Anything)
+
+	// Hotels only leaves destinations out
+	mockRepo.On("SuggestHotels", mock.Anything, "Kuta", 20).Return([]Suggestion{
+		suggestion(AutocompleteTypeHotel, "Kuta Resort", 0.8, true, 0),
+	}, nil)
+
+	result, err = service.Autocomplete(context.Background(), &AutocompleteRequest{
+		Query: "Kuta",
+		Types: []AutocompleteResultType{AutocompleteTypeHotel},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Kuta Resort"}, resultNames(result.Results))
+	mockRepo.AssertNumberOfCalls(t, "SuggestDestinations", 1)
+}
+
+// TestParseAutocompleteTypes tests parsing the types query parameter
+func TestParseAutocompleteTypes(t *testing.T) {
+	types, err := ParseAutocompleteTypes("City, landmark,,hotel")
+	require.NoError(t, err)
+	assert.Equal(t, []AutocompleteResultType{AutocompleteTypeCity, AutocompleteTypeLandmark, AutocompleteTypeHotel}, types)
+
+	types, err = ParseAutocompleteTypes("")
+	require.NoError(t, err)
+	assert.Empty(t, types)
+
+	_, err = ParseAutocompleteTypes("city,airport")
+	assert.ErrorIs(t, err, ErrInvalidResultType)
+}
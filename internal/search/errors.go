@@ -4,8 +4,14 @@ import "errors"
 
 // Package-level errors for search operations
 var (
-	ErrInvalidDates    = errors.New("invalid date range")
-	ErrInvalidGuests   = errors.New("invalid number of guests")
-	ErrNoResults       = errors.New("no search results found")
-	ErrMissingLocation = errors.New("location is required")
+	ErrInvalidDates     = errors.New("invalid date range")
+	ErrInvalidGuests    = errors.New("invalid number of guests")
+	ErrNoResults        = errors.New("no search results found")
+	ErrMissingLocation  = errors.New("location is required")
+	ErrInvalidLocation  = errors.New("invalid location")
+	ErrLandmarkNotFound = errors.New("landmark not found")
+
+	ErrInvalidResultType = errors.New("invalid autocomplete result type")
+
+	ErrProvidersUnavailable = errors.New("hotel providers unavailable")
 )
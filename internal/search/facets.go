@@ -0,0 +1,194 @@
+package search
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// facet identifies a search filter, so a facet's counts can leave out its
+// own filter
+type facet int
+
+const (
+	facetNone facet = iota
+	facetStarRating
+	facetAmenity
+	facetPropertyType
+	facetMealPlan
+	facetFreeCancellation
+	facetReviewScore
+)
+
+// maxAmenityFacets caps the amenity values returned, most common first
+const maxAmenityFacets = 30
+
+// reviewScoreThresholds are the minimum review scores offered as facet values
+var reviewScoreThresholds = []float64{4.5, 4, 3.5, 3}
+
+// filterHotels returns the hotels matching every filter, each priced from its
+// cheapest offer that matches
+func filterHotels(hotels []*pricedHotel, req *SearchRequest) []Hotel {
+	filtered := []Hotel{}
+	for _, h := range hotels {
+		o, ok := match(h, req, facetNone)
+		if !ok {
+			continue
+		}
+
+		hotel := h.Hotel
+		hotel.FromPrice = o.price
+		hotel.Currency = o.currency
+		hotel.MealPlan = o.mealPlan
+		hotel.FreeCancellation = o.freeCancellation
+		filtered = append(filtered, hotel)
+	}
+	return filtered
+}
+
+// match returns the cheapest offer of h matching the filters of req, leaving
+// out the filter of skip, and whether the hotel matches at all
+func match(h *pricedHotel, req *SearchRequest, skip facet) (offer, bool) {
+	if skip != facetStarRating && len(req.StarRatings) > 0 {
+		stars, ok := starRating(&h.Hotel)
+		if !ok || !slices.Contains(req.StarRatings, stars) {
+			return offer{}, false
+		}
+	}
+	if skip != facetAmenity {
+		for _, amenity := range req.Amenities {
+			if !containsFold(h.Amenities, amenity) {
+				return offer{}, false
+			}
+		}
+	}
+	if skip != facetPropertyType && len(req.PropertyTypes) > 0 && !containsFold(req.PropertyTypes, h.PropertyType) {
+		return offer{}, false
+	}
+	if skip != facetReviewScore && req.MinReviewScore != nil {
+		if h.Rating == nil || *h.Rating < *req.MinReviewScore {
+			return offer{}, false
+		}
+	}
+
+	// Offers are cheapest first
+	for _, o := range h.offers {
+		if skip != facetMealPlan && len(req.MealPlans) > 0 && !containsFold(req.MealPlans, o.mealPlan) {
+			continue
+		}
+		if skip != facetFreeCancellation && req.FreeCancellation && !o.freeCancellation {
+			continue
+		}
+		if req.MinPrice != nil && o.price < *req.MinPrice {
+			continue
+		}
+		if req.MaxPrice != nil && o.price > *req.MaxPrice {
+			continue
+		}
+		return o, true
+	}
+	return offer{}, false
+}
+
+// countFacets counts the hotels matching each filter value, applying the
+// search's other filters
+func countFacets(hotels []*pricedHotel, req *SearchRequest) *Facets {
+	stars := make(map[string]int)
+	amenities := make(map[string]int)
+	propertyTypes := make(map[string]int)
+	mealPlans := make(map[string]int)
+	reviewScores := make(map[string]int)
+	facets := &Facets{}
+
+	for _, h := range hotels {
+		if _, ok := match(h, req, facetStarRating); ok {
+			if s, ok := starRating(&h.Hotel); ok {
+				stars[strconv.Itoa(s)]++
+			}
+		}
+		if _, ok := match(h, req, facetAmenity); ok {
+			for _, amenity := range h.Amenities {
+				amenities[amenity]++
+			}
+		}
+		if _, ok := match(h, req, facetPropertyType); ok && h.PropertyType != "" {
+			propertyTypes[h.PropertyType]++
+		}
+		if _, ok := match(h, req, facetReviewScore); ok && h.Rating != nil {
+			for _, threshold := range reviewScoreThresholds {
+				if *h.Rating >= threshold {
+					reviewScores[strconv.FormatFloat(threshold, 'f', -1, 64)]++
+				}
+			}
+		}
+
+		// Board and cancellation facets depend on which offers match, so
+		// each value is matched on its own
+		counted := make(map[string]bool)
+		for _, o := range h.offers {
+			if o.mealPlan == "" || counted[o.mealPlan] {
+				continue
+			}
+			narrowed := *req
+			narrowed.MealPlans = []string{o.mealPlan}
+			if _, ok := match(h, &narrowed, facetNone); ok {
+				mealPlans[o.mealPlan]++
+				counted[o.mealPlan] = true
+			}
+		}
+		narrowed := *req
+		narrowed.FreeCancellation = true
+		if _, ok := match(h, &narrowed, facetNone); ok {
+			facets.FreeCancellation++
+		}
+	}
+
+	// Star ratings and review scores highest first, the others most common first
+	facets.StarRatings = facetValues(stars, func(a, b FacetValue) int {
+		return strings.Compare(b.Value, a.Value)
+	})
+	facets.ReviewScores = facetValues(reviewScores, func(a, b FacetValue) int {
+		x, _ := strconv.ParseFloat(a.Value, 64)
+		y, _ := strconv.ParseFloat(b.Value, 64)
+		return cmp.Compare(y, x)
+	})
+	facets.Amenities = facetValues(amenities, byCount)
+	if len(facets.Amenities) > maxAmenityFacets {
+		facets.Amenities = facets.Amenities[:maxAmenityFacets]
+	}
+	facets.PropertyTypes = facetValues(propertyTypes, byCount)
+	facets.MealPlans = facetValues(mealPlans, byCount)
+
+	return facets
+}
+
+// facetValues lists counts in the order given by compare
+func facetValues(counts map[string]int, compare func(a, b FacetValue) int) []FacetValue {
+	values := make([]FacetValue, 0, len(counts))
+	for value, count := range counts {
+		values = append(values, FacetValue{Value: value, Count: count})
+	}
+	slices.SortFunc(values, compare)
+	return values
+}
+
+// byCount orders facet values most common first, then by value
+func byCount(a, b FacetValue) int {
+	return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Value, b.Value))
+}
+
+// starRating returns a hotel's star rating rounded to whole stars
+func starRating(h *Hotel) (int, bool) {
+	if h.StarRating == nil || *h.StarRating <= 0 {
+		return 0, false
+	}
+	return int(math.Round(*h.StarRating)), true
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+}
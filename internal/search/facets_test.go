@@ -0,0 +1,202 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// facetHotels are three available hotels with different content and offers
+func facetHotels() []*pricedHotel {
+	return []*pricedHotel{
+		{
+			Hotel: Hotel{
+				ID: "villa", Name: "Ubud Villa", StarRating: pointerToFloat64(5), Rating: pointerToFloat64(4.8),
+				PropertyType: "VILLA", Amenities: []string{"Pool", "Wi-fi"},
+			},
+			offers: []offer{
+				{price: 900000, currency: "IDR", mealPlan: "RO"},
+				{price: 1100000, currency: "IDR", mealPlan: "BB", freeCancellation: true},
+			},
+		},
+		{
+			Hotel: Hotel{
+				ID: "resort", Name: "Kuta Resort", StarRating: pointerToFloat64(4), Rating: pointerToFloat64(4.1),
+				PropertyType: "HOTEL", Amenities: []string{"Pool", "Gym"},
+			},
+			offers: []offer{
+				{price: 700000, currency: "IDR", mealPlan: "BB"},
+			},
+		},
+		{
+			Hotel: Hotel{
+				ID: "hostel", Name: "Canggu Hostel", StarRating: pointerToFloat64(2),
+				PropertyType: "HOSTEL", Amenities: []string{"Wi-fi"},
+			},
+			offers: []offer{
+				{price: 150000, currency: "IDR", mealPlan: "RO", freeCancellation: true},
+			},
+		},
+	}
+}
+
+func hotelIDs(hotels []Hotel) []string {
+	ids := []string{}
+	for _, h := range hotels {
+		ids = append(ids, h.ID)
+	}
+	return ids
+}
+
+// TestFilterHotels tests each filter on its own
+func TestFilterHotels(t *testing.T) {
+	testCases := []struct {
+		name     string
+		req      SearchRequest
+		expected []string
+	}{
+		{name: "No filters", expected: []string{"villa", "resort", "hostel"}},
+		{name: "Star ratings", req: SearchRequest{StarRatings: []int{4, 5}}, expected: []string{"villa", "resort"}},
+		{name: "All amenities", req: SearchRequest{Amenities: []string{"pool", "Wi-fi"}}, expected: []string{"villa"}},
+		{name: "Property types", req: SearchRequest{PropertyTypes: []string{"hostel", "villa"}}, expected: []string{"villa", "hostel"}},
+		{name: "Meal plan", req: SearchRequest{MealPlans: []string{"BB"}}, expected: []string{"villa", "resort"}},
+		{name: "Free cancellation", req: SearchRequest{FreeCancellation: true}, expected: []string{"villa", "hostel"}},
+		{name: "Review score", req: SearchRequest{MinReviewScore: pointerToFloat64(4.5)}, expected: []string{"villa"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, hotelIDs(filterHotels(facetHotels(), &tc.req)))
+		})
+	}
+}
+
+// TestFilterHotels_PricesFromMatchingOffer tests that a hotel is priced from
+// its cheapest offer matching the rate filters
+func TestFilterHotels_PricesFromMatchingOffer(t *testing.T) {
+	req := &SearchRequest{FreeCancellation: true, StarRatings: []int{5}}
+
+	hotels := filterHotels(facetHotels(), req)
+
+	require.Len(t, hotels, 1)
+	assert.Equal(t, 1100000, hotels[0].FromPrice)
+	assert.Equal(t, "BB", hotels[0].MealPlan)
+	assert.True(t, hotels[0].FreeCancellation)
+
+	// Price filters apply to the matching offer too
+	req.MaxPrice = intPtr(1000000)
+	assert.Empty(t, filterHotels(facetHotels(), req))
+}
+
+// TestCountFacets tests that each facet counts the hotels matching the
+// other filters but not its own
+func TestCountFacets(t *testing.T) {
+	req := &SearchRequest{
+		StarRatings: []int{4, 5},
+		MealPlans:   []string{"BB"},
+	}
+
+	facets := countFacets(facetHotels(), req)
+
+	// The hostel only has room-only rates, so it drops out of every facet
+	// but the board one, where it is left out by its star rating
+	assert.Equal(t, []FacetValue{{"5", 1}, {"4", 1}}, facets.StarRatings)
+	assert.Equal(t, []FacetValue{{"Pool", 2}, {"Gym", 1}, {"Wi-fi", 1}}, facets.Amenities)
+	assert.Equal(t, []FacetValue{{"HOTEL", 1}, {"VILLA", 1}}, facets.PropertyTypes)
+	assert.Equal(t, []FacetValue{{"BB", 2}, {"RO", 1}}, facets.MealPlans)
+	assert.Equal(t, 1, facets.FreeCancellation)
+	assert.Equal(t, []FacetValue{{"4.5", 1}, {"4", 2}, {"3.5", 2}, {"3", 2}}, facets.ReviewScores)
+}
+
+// TestCountFacets_NoHotels tests that facets are empty lists, not null
+func TestCountFacets_NoHotels(t *testing.T) {
+	facets := countFacets(nil, &SearchRequest{})
+
+	assert.NotNil(t, facets.StarRatings)
+	assert.NotNil(t, facets.Amenities)
+	assert.NotNil(t, facets.PropertyTypes)
+	assert.NotNil(t, facets.MealPlans)
+	assert.NotNil(t, facets.ReviewScores)
+	assert.Zero(t, facets.FreeCancellation)
+}
+
+// TestService_SearchHotels_HotelbedsFacets tests that the boards and
+// cancellation policies of HotelBeds rates reach the facets
+func TestService_SearchHotels_HotelbedsFacets(t *testing.T) {
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	future := time.Now().Add(72 * time.Hour).Format(time.RFC3339)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/hotel-api/1.0/hotels", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"hotels": map[string]interface{}{
+				"hotels": []map[string]interface{}{
+					{
+						"code": "P-hotel-1", "currency": "IDR",
+						"rooms": []map[string]interface{}{{
+							"code": "DBL",
+							"rates": []map[string]interface{}{
+								{"rateKey": "k1", "net": 1000000, "boardCode": "RO",
+									"cancellationPolicies": []map[string]interface{}{{"amount": 1000000, "from": past}}},
+								{"rateKey": "k2", "net": 1200000, "boardCode": "BB",
+									"cancellationPolicies": []map[string]interface{}{{"amount": 600000, "from": future}}},
+							},
+						}},
+					},
+					{
+						"code": "P-hotel-2", "currency": "IDR",
+						"rooms": []map[string]interface{}{{
+							"code": "TWN",
+							"rates": []map[string]interface{}{
+								{"rateKey": "k3", "net": 800000, "boardCode": "HB",
+									"cancellationPolicies": []map[string]interface{}{{"amount": 800000, "from": past}}},
+							},
+						}},
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	registry := provider.NewRegistry()
+	registry.Register(provider.NewHotelbedsProviderWithClient(hotelbeds.NewClient("key", "secret", server.URL)))
+
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, registry)
+
+	ctx := context.Background()
+	req := testRequest()
+	mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return([]Candidate{
+		candidate("hotel-1", "hotel-1", "Grand Hotel Bali", "hotelbeds", 4.5),
+		candidate("hotel-2", "hotel-2", "Bali Beach Resort", "hotelbeds", 4.0),
+	}, nil)
+
+	result, err := service.SearchHotels(ctx, req, &SearchOptions{Page: 1, PerPage: 10, SortBy: SortByRating})
+
+	require.NoError(t, err)
+	require.Len(t, result.Hotels, 2)
+	assert.Equal(t, "RO", result.Hotels[0].MealPlan)
+	assert.False(t, result.Hotels[0].FreeCancellation)
+	assert.Equal(t, []FacetValue{{"BB", 1}, {"HB", 1}, {"RO", 1}}, result.Facets.MealPlans)
+	assert.Equal(t, 1, result.Facets.FreeCancellation)
+
+	// Filtering on free cancellation prices the hotel from its BB rate
+	req.FreeCancellation = true
+	result, err = service.SearchHotels(ctx, req, &SearchOptions{Page: 1, PerPage: 10})
+
+	require.NoError(t, err)
+	require.Len(t, result.Hotels, 1)
+	assert.Equal(t, "hotel-1", result.Hotels[0].ID)
+	assert.Equal(t, "BB", result.Hotels[0].MealPlan)
+	assert.Equal(t, 1416000, result.Hotels[0].FromPrice)
+}
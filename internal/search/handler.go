@@ -2,6 +2,7 @@ package search
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
@@ -52,9 +53,13 @@ func (h *Handler) SearchHotels(w http.ResponseWriter, r *http.Request) {
 	result, err := h.service.SearchHotels(r.Context(), &req, opts)
 	if err != nil {
 		logger.ErrorWithErr(err, "Failed to search hotels")
-		if err == ErrInvalidDates {
+		switch {
+		case errors.Is(err, ErrInvalidDates), errors.Is(err, ErrInvalidGuests),
+			errors.Is(err, ErrMissingLocation), errors.Is(err, ErrInvalidLocation), errors.Is(err, ErrLandmarkNotFound):
 			respondWithError(w, http.StatusBadRequest, err.Error())
-		} else {
+		case errors.Is(err, ErrProvidersUnavailable):
+			respondWithError(w, http.StatusServiceUnavailable, "Hotel availability is temporarily unavailable")
+		default:
 			respondWithError(w, http.StatusInternalServerError, "Internal server error")
 		}
 		return
@@ -79,7 +84,14 @@ func (h *Handler) Autocomplete(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	result, err := h.service.Autocomplete(r.Context(), query, limit)
+	// Optional comma separated result types, e.g. types=city,landmark
+	types, err := ParseAutocompleteTypes(r.URL.Query().Get("types"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.Autocomplete(r.Context(), &AutocompleteRequest{Query: query, Limit: limit, Types: types})
 	if err != nil {
 		logger.ErrorWithErr(err, "Failed to get autocomplete results")
 		respondWithError(w, http.StatusInternalServerError, "Internal server error")
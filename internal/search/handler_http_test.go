@@ -23,7 +23,7 @@ type mockContextType struct {
 func TestSearchHandler_SearchHotels_Success(t *testing.T) {
 	// Setup
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, testGateway())
 	handler := NewHandler(service)
 
 	// Create request body
@@ -39,21 +39,7 @@ func TestSearchHandler_SearchHotels_Success(t *testing.T) {
 	jsonBody, _ := json.Marshal(reqBody)
 
 	// Mock: Successful search
-	expectedResult := &SearchResult{
-		Hotels: []Hotel{
-			{
-				ID:      "hotel-1",
-				Name:    "Grand Hotel Bali",
-				Country: "Indonesia",
-				City:    "Bali",
-			},
-		},
-		Total:      1,
-		Page:       1,
-		PerPage:    10,
-		TotalPages: 1,
-	}
-	mockRepo.On("SearchHotels", mock.Anything, mock.AnythingOfType("*search.SearchRequest"), mock.AnythingOfType("*search.SearchOptions")).Return(expectedResult, nil)
+	mockRepo.On("SearchCandidates", mock.Anything, mock.AnythingOfType("*search.SearchRequest"), mock.Anything).Return(testCandidates(), nil)
 
 	// Create HTTP request
 	req := httptest.NewRequest("POST", "/search", bytes.NewReader(jsonBody))
@@ -72,8 +58,9 @@ func TestSearchHandler_SearchHotels_Success(t *testing.T) {
 	err := json.NewDecoder(rr.Body).Decode(&respBody)
 	require.NoError(t, err)
 
-	assert.Len(t, respBody.Hotels, 1)
-	assert.Equal(t, "Grand Hotel Bali", respBody.Hotels[0].Name)
+	require.Len(t, respBody.Hotels, 2)
+	assert.Equal(t, "Bali Beach Resort", respBody.Hotels[0].Name)
+	assert.Equal(t, 590000, respBody.Hotels[0].FromPrice)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -82,7 +69,7 @@ func TestSearchHandler_SearchHotels_Success(t *testing.T) {
 func TestSearchHandler_SearchHotels_InvalidDates_CheckOutBeforeCheckIn(t *testing.T) {
 	// Setup
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, testGateway())
 	handler := NewHandler(service)
 
 	// Create request body with invalid dates
@@ -121,7 +108,7 @@ func TestSearchHandler_SearchHotels_InvalidDates_CheckOutBeforeCheckIn(t *testin
 func TestSearchHandler_SearchHotels_InvalidGuests_TooFew(t *testing.T) {
 	// Setup
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, testGateway())
 	handler := NewHandler(service)
 
 	// Create request body with invalid guests
@@ -160,7 +147,7 @@ func TestSearchHandler_SearchHotels_InvalidGuests_TooFew(t *testing.T) {
 func TestSearchHandler_SearchHotels_InvalidGuests_TooMany(t *testing.T) {
 	// Setup
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, testGateway())
 	handler := NewHandler(service)
 
 	// Create request body with invalid guests
@@ -199,7 +186,7 @@ func TestSearchHandler_SearchHotels_InvalidGuests_TooMany(t *testing.T) {
 func TestSearchHandler_SearchHotels_WithPagination(t *testing.T) {
 	// Setup
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, testGateway())
 	handler := NewHandler(service)
 
 	// Create request body with pagination
@@ -215,19 +202,10 @@ func TestSearchHandler_SearchHotels_WithPagination(t *testing.T) {
 	jsonBody, _ := json.Marshal(reqBody)
 
 	// Mock: Successful search with pagination
-	expectedResult := &SearchResult{
-		Hotels:     []Hotel{},
-		Total:      50,
-		Page:       2,
-		PerPage:    20,
-		TotalPages: 3,
-	}
-	mockRepo.On("SearchHotels", mock.Anything, mock.AnythingOfType("*search.SearchRequest"), mock.MatchedBy(func(opts *SearchOptions) bool {
-		return opts.Page == 2 && opts.PerPage == 20
-	})).Return(expectedResult, nil)
+	mockRepo.On("SearchCandidates", mock.Anything, mock.AnythingOfType("*search.SearchRequest"), mock.Anything).Return(testCandidates(), nil)
 
 	// Create HTTP request with query params
-	req := httptest.NewRequest("POST", "/search?page=2&per_page=20", bytes.NewReader(jsonBody))
+	req := httptest.NewRequest("POST", "/search?page=2&per_page=1&sort_by=price", bytes.NewReader(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 
 	// Create response recorder
@@ -244,9 +222,11 @@ func TestSearchHandler_SearchHotels_WithPagination(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, 2, respBody.Page)
-	assert.Equal(t, 20, respBody.PerPage)
-	assert.Equal(t, 50, respBody.Total)
-	assert.Equal(t, 3, respBody.TotalPages)
+	assert.Equal(t, 1, respBody.PerPage)
+	assert.Equal(t, 2, respBody.Total)
+	assert.Equal(t, 2, respBody.TotalPages)
+	require.Len(t, respBody.Hotels, 1)
+	assert.Equal(t, "Grand Hotel Bali", respBody.Hotels[0].Name)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -255,13 +235,13 @@ func TestSearchHandler_SearchHotels_WithPagination(t *testing.T) {
 func TestSearchHandler_SearchHotels_WithPriceFilters(t *testing.T) {
 	// Setup
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, testGateway())
 	handler := NewHandler(service)
 
 	// Create request body with price filters
 	checkIn := time.Now().Add(24 * time.Hour)
 	checkOut := time.Now().Add(48 * time.Hour)
-	minPrice := 500000
+	minPrice := 600000
 	maxPrice := 2000000
 
 	reqBody := SearchRequest{
@@ -275,24 +255,10 @@ func TestSearchHandler_SearchHotels_WithPriceFilters(t *testing.T) {
 	jsonBody, _ := json.Marshal(reqBody)
 
 	// Mock: Successful search
-	expectedResult := &SearchResult{
-		Hotels: []Hotel{
-			{
-				ID:      "hotel-1",
-				Name:    "Bali Resort",
-				Country: "Indonesia",
-				City:    "Bali",
-			},
-		},
-		Total:      1,
-		Page:       1,
-		PerPage:    20,
-		TotalPages: 1,
-	}
-	mockRepo.On("SearchHotels", mock.Anything, mock.MatchedBy(func(req *SearchRequest) bool {
-		return req.MinPrice != nil && *req.MinPrice == 500000 &&
+	mockRepo.On("SearchCandidates", mock.Anything, mock.MatchedBy(func(req *SearchRequest) bool {
+		return req.MinPrice != nil && *req.MinPrice == 600000 &&
 			req.MaxPrice != nil && *req.MaxPrice == 2000000
-	}), mock.AnythingOfType("*search.SearchOptions")).Return(expectedResult, nil)
+	}), mock.Anything).Return(testCandidates(), nil)
 
 	// Create HTTP request
 	req := httptest.NewRequest("POST", "/search", bytes.NewReader(jsonBody))
@@ -311,8 +277,8 @@ func TestSearchHandler_SearchHotels_WithPriceFilters(t *testing.T) {
 	err := json.NewDecoder(rr.Body).Decode(&respBody)
 	require.NoError(t, err)
 
-	assert.Len(t, respBody.Hotels, 1)
-	assert.Equal(t, "Bali Resort", respBody.Hotels[0].Name)
+	require.Len(t, respBody.Hotels, 1)
+	assert.Equal(t, "Grand Hotel Bali", respBody.Hotels[0].Name)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -321,7 +287,7 @@ func TestSearchHandler_SearchHotels_WithPriceFilters(t *testing.T) {
 func TestSearchHandler_SearchHotels_EmptyResults(t *testing.T) {
 	// Setup
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, testGateway())
 	handler := NewHandler(service)
 
 	// Create request body
@@ -337,14 +303,7 @@ func TestSearchHandler_SearchHotels_EmptyResults(t *testing.T) {
 	jsonBody, _ := json.Marshal(reqBody)
 
 	// Mock: No results
-	expectedResult := &SearchResult{
-		Hotels:     []Hotel{},
-		Total:      0,
-		Page:       1,
-		PerPage:    20,
-		TotalPages: 1,
-	}
-	mockRepo.On("SearchHotels", mock.Anything, mock.AnythingOfType("*search.SearchRequest"), mock.AnythingOfType("*search.SearchOptions")).Return(expectedResult, nil)
+	mockRepo.On("SearchCandidates", mock.Anything, mock.AnythingOfType("*search.SearchRequest"), mock.Anything).Return([]Candidate{}, nil)
 
 	// Create HTTP request
 	req := httptest.NewRequest("POST", "/search", bytes.NewReader(jsonBody))
@@ -373,7 +332,7 @@ func TestSearchHandler_SearchHotels_EmptyResults(t *testing.T) {
 func TestSearchHandler_SearchHotels_InvalidJSON(t *testing.T) {
 	// Setup
 	mockRepo := new(MockRepository)
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, testGateway())
 	handler := NewHandler(service)
 
 	// Create HTTP request with invalid JSON
@@ -1,43 +1,146 @@
 package search
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // SearchRequest represents a hotel search request
 type SearchRequest struct {
 	CheckIn  time.Time `json:"check_in" validate:"required"`
 	CheckOut time.Time `json:"check_out" validate:"required,gtfield=CheckIn"`
-	City     string    `json:"city" validate:"required"`
+	City     string    `json:"city"` // Optional with a geo search
 	Guests   int       `json:"guests" validate:"required,min=1,max=10"`
 	MinPrice *int      `json:"min_price,omitempty" validate:"omitempty,min=0"`
 	MaxPrice *int      `json:"max_price,omitempty" validate:"omitempty,min=0"`
+	Currency string    `json:"currency,omitempty"` // Of prices and price filters, DefaultCurrency when empty
+
+	// Filters; each facet in the response counts hotels for its values
+	StarRatings      []int    `json:"star_ratings,omitempty"`      // Any of these star ratings
+	Amenities        []string `json:"amenities,omitempty"`         // All of these amenities
+	PropertyTypes    []string `json:"property_types,omitempty"`    // Any of these property types, e.g. HOTEL
+	MealPlans        []string `json:"meal_plans,omitempty"`        // A rate on any of these boards, e.g. BB
+	FreeCancellation bool     `json:"free_cancellation,omitempty"` // A rate cancellable for free
+	MinReviewScore   *float64 `json:"min_review_score,omitempty"`  // Guest review score of at least this
+
+	// Geo search, instead of or within City: around a point or a landmark,
+	// or within a map viewport. With a viewport, a point only sets where
+	// distances are measured from unless RadiusKm is set too.
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Landmark  string   `json:"landmark,omitempty"`  // Code of the destination to search around
+	RadiusKm  float64  `json:"radius_km,omitempty"` // DefaultRadiusKm when zero
+	Bounds    *Bounds  `json:"bounds,omitempty"`
+}
+
+// DefaultCurrency is what searches are priced in unless they ask for another.
+// Rates in other currencies can't be compared and are left out.
+const DefaultCurrency = "IDR"
+
+// PriceCurrency returns the currency the search is priced in
+func (r *SearchRequest) PriceCurrency() string {
+	if r.Currency == "" {
+		return DefaultCurrency
+	}
+	return strings.ToUpper(r.Currency)
+}
+
+// Geo search radius limits
+const (
+	DefaultRadiusKm = 5.0
+	MaxRadiusKm     = 100.0
+)
+
+// Bounds is a map viewport. West is greater than East for a viewport
+// across the antimeridian.
+type Bounds struct {
+	North float64 `json:"north"`
+	South float64 `json:"south"`
+	East  float64 `json:"east"`
+	West  float64 `json:"west"`
+}
+
+// Center returns the middle of the viewport
+func (b *Bounds) Center() (latitude, longitude float64) {
+	width := b.East - b.West
+	if width < 0 {
+		width += 360
+	}
+	longitude = b.West + width/2
+	if longitude > 180 {
+		longitude -= 360
+	}
+	return (b.North + b.South) / 2, longitude
+}
+
+// center returns where distances are measured from: the search point, or
+// the middle of the viewport
+func (r *SearchRequest) center() (latitude, longitude float64, ok bool) {
+	if r.Latitude != nil && r.Longitude != nil {
+		return *r.Latitude, *r.Longitude, true
+	}
+	if r.Bounds != nil {
+		latitude, longitude = r.Bounds.Center()
+		return latitude, longitude, true
+	}
+	return 0, 0, false
+}
+
+// Landmark is a destination hotels can be searched around
+type Landmark struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
 }
 
 // SortBy represents sort options
 type SortBy string
 
 const (
-	SortByPrice  SortBy = "price"
-	SortByRating SortBy = "rating"
-	SortByName   SortBy = "name"
+	SortByPrice    SortBy = "price"
+	SortByRating   SortBy = "rating"
+	SortByName     SortBy = "name"
+	SortByDistance SortBy = "distance" // From the search point or viewport center
 )
 
 // SearchOptions represents search options
 type SearchOptions struct {
 	Page    int    `json:"page" validate:"min=1"`
 	PerPage int    `json:"per_page" validate:"min=1,max=100"`
-	SortBy  SortBy `json:"sort_by" validate:"omitempty,oneof=price rating name"`
+	SortBy  SortBy `json:"sort_by" validate:"omitempty,oneof=price rating name distance"`
 }
 
 // Hotel represents a hotel in search results
 type Hotel struct {
-	ID          string   `json:"id" db:"id"`
-	Name        string   `json:"name" db:"name"`
-	CountryCode string   `json:"country_code" db:"country_code"`
-	City        string   `json:"city" db:"city"`
-	Rating      *float64 `json:"rating" db:"overall_rating"`
-	MinPrice    *int     `json:"min_price,omitempty"`
-	MaxPrice    *int     `json:"max_price,omitempty"`
-	Description string   `json:"description,omitempty"`
+	ID               string   `json:"id" db:"id"`
+	Name             string   `json:"name" db:"name"`
+	CountryCode      string   `json:"country_code" db:"country_code"`
+	City             string   `json:"city" db:"city"`
+	Rating           *float64 `json:"rating" db:"overall_rating"`
+	StarRating       *float64 `json:"star_rating,omitempty" db:"star_rating"`
+	PropertyType     string   `json:"property_type,omitempty" db:"property_type"`
+	Amenities        []string `json:"amenities,omitempty" db:"amenities"`
+	FromPrice        int      `json:"from_price"` // Lowest sell price for the stay matching the filters
+	Currency         string   `json:"currency"`
+	MealPlan         string   `json:"meal_plan,omitempty"` // Board of the from-price rate
+	FreeCancellation bool     `json:"free_cancellation"`   // Whether the from-price rate cancels for free
+	Latitude         *float64 `json:"latitude,omitempty" db:"latitude"`
+	Longitude        *float64 `json:"longitude,omitempty" db:"longitude"`
+	DistanceKm       *float64 `json:"distance_km,omitempty"` // From the search point or viewport center
+	Description      string   `json:"description,omitempty"`
+}
+
+// Candidate is a provider hotel matching a search, before its availability
+// is checked. A hotel sold by several providers has a candidate per
+// provider, sharing a GroupID; the first of the group carries its content.
+type Candidate struct {
+	Hotel
+	GroupID         string
+	ProviderCode    string
+	ProviderHotelID string
 }
 
 // SearchResult represents search results with pagination
@@ -47,42 +150,97 @@ type SearchResult struct {
 	Page       int     `json:"page"`
 	PerPage    int     `json:"per_page"`
 	TotalPages int     `json:"total_pages"`
+	Facets     *Facets `json:"facets"`
+	Partial    bool    `json:"partial,omitempty"`   // Some hotels could not be checked or priced; they are not in Hotels
+	Unchecked  int     `json:"unchecked,omitempty"` // Hotels whose availability check failed or timed out
+	Unpriced   int     `json:"unpriced,omitempty"`  // Hotels with rooms, but only at rates in another currency than the search's
 }
 
+// FacetValue is a filter value and the number of hotels it would match
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facets counts the available hotels for each filter value. A facet's
+// counts apply every other filter of the search but not its own, so they
+// show what choosing a value would return.
+type Facets struct {
+	StarRatings      []FacetValue `json:"star_ratings"`
+	Amenities        []FacetValue `json:"amenities"`
+	PropertyTypes    []FacetValue `json:"property_types"`
+	MealPlans        []FacetValue `json:"meal_plans"`
+	FreeCancellation int          `json:"free_cancellation"`
+	ReviewScores     []FacetValue `json:"review_scores"` // Hotels scoring at least the value
+}
+
+// MaxAutocompleteLimit is the most suggestions an autocomplete returns
+const MaxAutocompleteLimit = 50
+
 // AutocompleteRequest represents autocomplete search request
 type AutocompleteRequest struct {
-	Query string `json:"query" validate:"required,min=2"`
-	Limit int    `json:"limit" validate:"omitempty,min=1,max=20"`
+	Query string                   `json:"query" validate:"required,min=2"`
+	Limit int                      `json:"limit" validate:"omitempty,min=1,max=50"`
+	Types []AutocompleteResultType `json:"types,omitempty"` // Result types to suggest, all when empty
 }
 
 // AutocompleteResultType represents the type of autocomplete result
 type AutocompleteResultType string
 
 const (
-	AutocompleteTypeRegion AutocompleteResultType = "region" // Country/State level
-	AutocompleteTypeCity   AutocompleteResultType = "city"   // City level
-	AutocompleteTypeHotel  AutocompleteResultType = "hotel"  // Specific hotel
+	AutocompleteTypeRegion   AutocompleteResultType = "region"   // Country/State level
+	AutocompleteTypeCity     AutocompleteResultType = "city"     // City level
+	AutocompleteTypeLandmark AutocompleteResultType = "landmark" // Point of interest to search around
+	AutocompleteTypeHotel    AutocompleteResultType = "hotel"    // Specific hotel
 )
 
-// AutocompleteResult represents autocomplete result
+// ParseAutocompleteTypes parses a comma separated list of result types
+func ParseAutocompleteTypes(s string) ([]AutocompleteResultType, error) {
+	var types []AutocompleteResultType
+	for _, part := range strings.Split(s, ",") {
+		t := AutocompleteResultType(strings.ToLower(strings.TrimSpace(part)))
+		switch t {
+		case "":
+			continue
+		case AutocompleteTypeRegion, AutocompleteTypeCity, AutocompleteTypeLandmark, AutocompleteTypeHotel:
+			types = append(types, t)
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrInvalidResultType, part)
+		}
+	}
+	return types, nil
+}
+
+// AutocompleteResult represents autocomplete result. Destinations and hotels
+// carry their place in the hierarchy: the parent region and the country.
 type AutocompleteResult struct {
-	Type        AutocompleteResultType `json:"type"`         // region, city, hotel
-	ID          string                 `json:"id"`           // For region/city: code, for hotel: hotel_id
-	Name        string                 `json:"name"`         // Display name
-	FullName    string                 `json:"full_name"`    // Full name with location
-	City        string                 `json:"city,omitempty"`      // City name (for hotels)
-	CountryCode string                 `json:"country_code,omitempty"` // Country code (e.g., "ID")
-	CountryName string                 `json:"country_name,omitempty"` // Country name (e.g., "Indonesia")
-	Region      string                 `json:"region,omitempty"`      // State/Province (optional)
+	Type            AutocompleteResultType `json:"type"`                       // region, city, landmark, hotel
+	ID              string                 `json:"id"`                         // For destinations: name, usable as the search city; for hotel: hotel_id
+	Code            string                 `json:"code,omitempty"`             // Destination code (for destinations)
+	DestinationType string                 `json:"destination_type,omitempty"` // Supplier type, e.g. CITY or COUNTRY (for destinations)
+	Name            string                 `json:"name"`                       // Display name
+	FullName        string                 `json:"full_name"`                  // Full name with location
+	City            string                 `json:"city,omitempty"`             // City name (for hotels)
+	Region          string                 `json:"region,omitempty"`           // Parent region: a destination's parent, a hotel's destination
+	RegionCode      string                 `json:"region_code,omitempty"`      // Parent region destination code
+	CountryCode     string                 `json:"country_code,omitempty"`     // Country code (e.g., "ID")
+	CountryName     string                 `json:"country_name,omitempty"`     // Country name (e.g., "Indonesia")
+	Latitude        *float64               `json:"latitude,omitempty"`
+	Longitude       *float64               `json:"longitude,omitempty"`
+	MatchedAlias    string                 `json:"matched_alias,omitempty"` // Alias the query matched, e.g. "Jogja" for Yogyakarta
 }
 
 // AutocompleteResponse represents autocomplete response
 type AutocompleteResponse struct {
-	Query   string                `json:"query"`
-	Results []AutocompleteResult  `json:"results"`
+	Query   string               `json:"query"`
+	Results []AutocompleteResult `json:"results"`
+	Partial bool                 `json:"partial,omitempty"` // A source missed the latency budget
 }
 
-// AutocompleteOptions represents autocomplete options
-type AutocompleteOptions struct {
-	Limit int
+// Suggestion is an autocomplete match before ranking
+type Suggestion struct {
+	AutocompleteResult
+	Similarity float64 // Trigram similarity of the query to the name or alias, 0 to 1
+	Prefix     bool    // The name or alias starts with the query
+	Popularity int     // Active hotels of a destination, reviews of a hotel
 }
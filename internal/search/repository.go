@@ -2,15 +2,20 @@ package search
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+	"github.com/jackc/pgx/v5"
 )
 
 // Repository defines interface for search data operations
 type Repository interface {
-	SearchHotels(ctx context.Context, req *SearchRequest, opts *SearchOptions) (*SearchResult, error)
-	Autocomplete(ctx context.Context, query string, opts *AutocompleteOptions) (*AutocompleteResponse, error)
+	SearchCandidates(ctx context.Context, req *SearchRequest, limit int) ([]Candidate, error)
+	GetLandmark(ctx context.Context, code string) (*Landmark, error)
+	SuggestDestinations(ctx context.Context, query string, types []AutocompleteResultType, limit int) ([]Suggestion, error)
+	SuggestHotels(ctx context.Context, query string, limit int) ([]Suggestion, error)
 	GetPopularDestinations(ctx context.Context, limit int) ([]AutocompleteResult, error)
 }
 
@@ -25,201 +30,331 @@ func NewRepository(database *db.DB) Repository {
 	}
 }
 
-func (r *repository) SearchHotels(ctx context.Context, req *SearchRequest, opts *SearchOptions) (*SearchResult, error) {
-	// Build base query
-	baseQuery := `
-		SELECT id, name, country_code, city, overall_rating
-		FROM hotels
-		WHERE city ILIKE $1
-			AND deleted_at IS NULL
-	`
-
-	countQuery := `
-		SELECT COUNT(*)
-		FROM hotels
-		WHERE city ILIKE $1
-			AND deleted_at IS NULL
-	`
-
-	args := []interface{}{req.City}
-	argPos := 2
-
-	// Add price filters if provided
-	if req.MinPrice != nil {
-		baseQuery += fmt.Sprintf(" AND $%d >= $%d", argPos, argPos+1)
-		countQuery += fmt.Sprintf(" AND $%d >= $%d", argPos, argPos+1)
-		args = append(args, *req.MinPrice)
-		argPos += 2
+// hotelEarth is a hotel's location for the earthdistance functions, as
+// indexed by idx_hotels_earth
+const hotelEarth = "ll_to_earth(h.latitude::float8, h.longitude::float8)"
+
+// hotelPoint is a hotel's location as a (longitude, latitude) point, as
+// indexed by idx_hotels_point
+const hotelPoint = "point(h.longitude::float8, h.latitude::float8)"
+
+// SearchCandidates returns the active provider hotels in the requested city
+// and area, grouped by master hotel with the content hotel of each group
+// first. Amenities are the names in the synced amenities JSONB. At most
+// limit hotels are returned: the nearest to the search point or viewport
+// center, else the most reviewed.
+func (r *repository) SearchCandidates(ctx context.Context, req *SearchRequest, limit int) ([]Candidate, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
 	}
-	if req.MaxPrice != nil {
-		baseQuery += fmt.Sprintf(" AND $%d <= $%d", argPos, argPos+1)
-		countQuery += fmt.Sprintf(" AND $%d <= $%d", argPos, argPos+1)
-		args = append(args, *req.MaxPrice)
-		argPos += 2
+
+	conditions := []string{"h.deleted_at IS NULL"}
+	if req.City != "" {
+		conditions = append(conditions, "h.city ILIKE "+arg(req.City))
 	}
 
-	// Add sorting
-	sortClause := " ORDER BY name ASC"
-	if opts.SortBy != "" {
-		switch opts.SortBy {
-		case SortByPrice:
-			sortClause = " ORDER BY name ASC" // TODO: Add price sorting when pricing is implemented
-		case SortByRating:
-			sortClause = " ORDER BY overall_rating DESC NULLS LAST, name ASC"
-		case SortByName:
-			sortClause = " ORDER BY name ASC"
+	// Distances are in km from the search point or viewport center
+	distance := "NULL::numeric"
+	if lat, lng, ok := req.center(); ok {
+		center := fmt.Sprintf("ll_to_earth(%s::float8, %s::float8)", arg(lat), arg(lng))
+		distance = fmt.Sprintf("ROUND((earth_distance(%s, %s) / 1000)::numeric, 2)", center, hotelEarth)
+		conditions = append(conditions, "h.latitude IS NOT NULL", "h.longitude IS NOT NULL")
+
+		if req.Latitude != nil && req.Longitude != nil && req.RadiusKm > 0 {
+			// earth_box finds hotels by index; it is a little larger than the circle
+			radius := arg(req.RadiusKm * 1000)
+			conditions = append(conditions,
+				fmt.Sprintf("earth_box(%s, %s::float8) @> %s", center, radius, hotelEarth),
+				fmt.Sprintf("earth_distance(%s, %s) <= %s::float8", center, hotelEarth, radius),
+			)
 		}
 	}
-	baseQuery += sortClause
-
-	// Add pagination
-	limit := opts.PerPage
-	offset := (opts.Page - 1) * opts.PerPage
-	baseQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argPos, argPos+1)
-	args = append(args, limit, offset)
 
-	// Get total count
-	var total int
-	err := r.db.Pool.QueryRow(ctx, countQuery, args[0]).Scan(&total)
-	if err != nil {
-		return nil, fmt.Errorf("failed to count hotels: %w", err)
+	if b := req.Bounds; b != nil {
+		south, north := arg(b.South), arg(b.North)
+		west, east := arg(b.West), arg(b.East)
+		inBox := func(west, east string) string {
+			return fmt.Sprintf("%s <@ box(point(%s::float8, %s::float8), point(%s::float8, %s::float8))",
+				hotelPoint, west, south, east, north)
+		}
+		if b.West <= b.East {
+			conditions = append(conditions, inBox(west, east))
+		} else {
+			// Across the antimeridian, the viewport is a box on either side
+			conditions = append(conditions, fmt.Sprintf("(%s OR %s)", inBox(west, "180"), inBox("-180", east)))
+		}
 	}
 
-	// Execute search query
-	rows, err := r.db.Pool.Query(ctx, baseQuery, args...)
+	query := `
+		WITH candidates AS (
+			SELECT COALESCE(hm.master_hotel_id, h.id) AS group_id, h.id, h.name, h.country_code, h.city,
+				h.overall_rating, h.star_rating, COALESCE(h.property_type, '') AS property_type,
+				COALESCE((
+					SELECT jsonb_agg(DISTINCT name)
+					FROM jsonb_array_elements(CASE WHEN jsonb_typeof(h.amenities) = 'array' THEN h.amenities ELSE '[]' END) a,
+						LATERAL (SELECT CASE WHEN jsonb_typeof(a) = 'string' THEN a #>> '{}' ELSE a->>'description' END AS name) n
+					WHERE name <> ''
+				), '[]') AS amenities,
+				h.latitude, h.longitude, ` + distance + ` AS distance_km,
+				h.provider_code, h.provider_hotel_id,
+				(h.id = mh.content_hotel_id) AS is_content, COALESCE(h.review_count, 0) AS popularity
+			FROM hotels h
+			LEFT JOIN hotel_mappings hm ON hm.hotel_id = h.id
+			LEFT JOIN master_hotels mh ON mh.id = hm.master_hotel_id
+			WHERE ` + strings.Join(conditions, "\n\t\t\t\tAND ") + `
+		),
+		top_groups AS (
+			SELECT group_id
+			FROM candidates
+			GROUP BY group_id
+			ORDER BY MIN(distance_km) NULLS LAST, MAX(popularity) DESC, group_id
+			LIMIT ` + arg(limit) + `
+		)
+		SELECT c.group_id, c.id, c.name, c.country_code, c.city,
+			c.overall_rating, c.star_rating, c.property_type, c.amenities,
+			c.latitude, c.longitude, c.distance_km,
+			c.provider_code, c.provider_hotel_id
+		FROM candidates c
+		JOIN top_groups USING (group_id)
+		ORDER BY c.group_id, c.is_content DESC NULLS LAST, c.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search hotels: %w", err)
 	}
 	defer rows.Close()
 
-	var hotels []Hotel
+	var candidates []Candidate
 	for rows.Next() {
-		var hotel Hotel
+		var c Candidate
 		err := rows.Scan(
-			&hotel.ID,
-			&hotel.Name,
-			&hotel.CountryCode,
-			&hotel.City,
-			&hotel.Rating,
+			&c.GroupID,
+			&c.ID,
+			&c.Name,
+			&c.CountryCode,
+			&c.City,
+			&c.Rating,
+			&c.StarRating,
+			&c.PropertyType,
+			&c.Amenities,
+			&c.Latitude,
+			&c.Longitude,
+			&c.DistanceKm,
+			&c.ProviderCode,
+			&c.ProviderHotelID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan hotel: %w", err)
 		}
-		hotels = append(hotels, hotel)
+		candidates = append(candidates, c)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating hotels: %w", err)
 	}
 
-	// Calculate total pages
-	totalPages := (total + opts.PerPage - 1) / opts.PerPage
-	if totalPages == 0 {
-		totalPages = 1
-	}
-
-	return &SearchResult{
-		Hotels:     hotels,
-		Total:      total,
-		Page:       opts.Page,
-		PerPage:    opts.PerPage,
-		TotalPages: totalPages,
-	}, nil
+	return candidates, nil
 }
 
-// Autocomplete searches for regions, cities, and hotels by query
-func (r *repository) Autocomplete(ctx context.Context, query string, opts *AutocompleteOptions) (*AutocompleteResponse, error) {
-	if opts == nil {
-		opts = &AutocompleteOptions{Limit: 10}
-	}
-
-	limit := opts.Limit
-	if limit > 20 {
-		limit = 20
+// GetLandmark returns a destination with a location, to search around
+func (r *repository) GetLandmark(ctx context.Context, code string) (*Landmark, error) {
+	var l Landmark
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT code, name, type, latitude, longitude
+		FROM destinations
+		WHERE code = $1
+			AND latitude IS NOT NULL
+			AND longitude IS NOT NULL
+	`, code).Scan(&l.Code, &l.Name, &l.Type, &l.Latitude, &l.Longitude)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrLandmarkNotFound
 	}
-	if limit < 1 {
-		limit = 10
+	if err != nil {
+		return nil, fmt.Errorf("failed to get landmark: %w", err)
 	}
+	return &l, nil
+}
 
-	results := []AutocompleteResult{}
+// trigramMatch matches a column to the query $1 by trigram similarity, for
+// typos, or by word similarity, for queries typed so far. Both compare
+// search_normalize'd text, as indexed, so accents and case don't matter.
+func trigramMatch(column string) string {
+	return fmt.Sprintf("(search_normalize(%[1]s) %% search_normalize($1) OR search_normalize($1) <%% search_normalize(%[1]s))", column)
+}
 
-	// 1. Search for cities from destinations table - priority 1
-	cityQuery := `
-		SELECT name, country_code
-		FROM destinations
-		WHERE name ILIKE $1
-		ORDER BY
-			CASE WHEN name ILIKE $2 THEN 0 ELSE 1 END,
-			name
-		LIMIT $3
+// trigramScore is the better of the similarities trigramMatch matches on
+func trigramScore(column string) string {
+	return fmt.Sprintf("GREATEST(similarity(search_normalize(%[1]s), search_normalize($1)), word_similarity(search_normalize($1), search_normalize(%[1]s)))", column)
+}
+
+// trigramPrefix tells whether a column starts with the query $1
+func trigramPrefix(column string) string {
+	return fmt.Sprintf("starts_with(search_normalize(%s), search_normalize($1))", column)
+}
+
+// destinationResultType maps a destination's supplier type to its
+// autocomplete type in SQL
+const destinationResultType = `CASE UPPER(d.type)
+				WHEN 'COUNTRY' THEN 'region' WHEN 'REGION' THEN 'region'
+				WHEN 'LANDMARK' THEN 'landmark'
+				ELSE 'city'
+			END`
+
+// SuggestDestinations returns the destinations of the given result types, or
+// of any type, whose name or an alias of it matches query, best match first
+func (r *repository) SuggestDestinations(ctx context.Context, query string, types []AutocompleteResultType, limit int) ([]Suggestion, error) {
+	sqlQuery := `
+		WITH matches AS (
+			SELECT d.code, ` + trigramScore("d.name") + ` AS score,
+				` + trigramPrefix("d.name") + ` AS prefix, '' AS alias
+			FROM destinations d
+			WHERE ` + trigramMatch("d.name") + `
+			UNION ALL
+			SELECT d.code, ` + trigramScore("a.alias") + `,
+				` + trigramPrefix("a.alias") + `, a.alias
+			FROM search_aliases a
+			JOIN destinations d ON search_normalize(d.name) = search_normalize(a.canonical_name)
+			WHERE ` + trigramMatch("a.alias") + `
+		),
+		best AS (
+			SELECT DISTINCT ON (code) code, score, prefix, alias
+			FROM matches
+			ORDER BY code, score DESC, prefix DESC
+		)
+		SELECT ` + destinationResultType + `,
+			d.code, d.type, d.name, d.country_code, d.country_name,
+			COALESCE(p.code, ''), COALESCE(p.name, ''), d.latitude, d.longitude,
+			b.score, b.prefix, b.alias,
+			(SELECT COUNT(*) FROM hotels h WHERE h.destination_code = d.code AND h.deleted_at IS NULL)
+		FROM best b
+		JOIN destinations d ON d.code = b.code
+		LEFT JOIN destinations p ON p.code = d.parent_code
+		WHERE cardinality($3::text[]) = 0 OR ` + destinationResultType + ` = ANY($3::text[])
+		ORDER BY b.score DESC, d.name
+		LIMIT $2
 	`
 
-	cityRows, err := r.db.Pool.Query(ctx, cityQuery, "%"+query+"%", query+"%", limit)
+	typeNames := make([]string, 0, len(types))
+	for _, t := range types {
+		typeNames = append(typeNames, string(t))
+	}
+
+	rows, err := r.db.Pool.Query(ctx, sqlQuery, query, limit, typeNames)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search cities: %w", err)
+		return nil, fmt.Errorf("failed to search destinations: %w", err)
 	}
-	defer cityRows.Close()
+	defer rows.Close()
 
-	for cityRows.Next() {
-		var cityName, countryCode string
-		if err := cityRows.Scan(&cityName, &countryCode); err != nil {
-			continue
+	var suggestions []Suggestion
+	for rows.Next() {
+		var s Suggestion
+		err := rows.Scan(
+			&s.Type,
+			&s.Code,
+			&s.DestinationType,
+			&s.Name,
+			&s.CountryCode,
+			&s.CountryName,
+			&s.RegionCode,
+			&s.Region,
+			&s.Latitude,
+			&s.Longitude,
+			&s.Similarity,
+			&s.Prefix,
+			&s.MatchedAlias,
+			&s.Popularity,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan destination: %w", err)
 		}
 
-		results = append(results, AutocompleteResult{
-			Type:        AutocompleteTypeCity,
-			ID:          cityName,
-			Name:        cityName,
-			FullName:    fmt.Sprintf("%s, %s", cityName, countryCode),
-			CountryCode: countryCode,
-			CountryName: countryCode, // Will be looked up if needed
-		})
+		// Destinations are searched by name, so the name is the ID
+		s.ID = s.Name
+		s.FullName = joinNonEmpty(s.Name, s.Region, s.CountryName)
+		suggestions = append(suggestions, s)
 	}
 
-	// 2. Search for hotels by name - priority 2
-	hotelQuery := `
-		SELECT id, name, city, country_code
-		FROM hotels
-		WHERE name ILIKE $1
-			AND deleted_at IS NULL
-		ORDER BY overall_rating DESC NULLS LAST, name ASC
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating destinations: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+// SuggestHotels returns the active hotels whose name matches query, best
+// match first, one per master hotel
+func (r *repository) SuggestHotels(ctx context.Context, query string, limit int) ([]Suggestion, error) {
+	sqlQuery := `
+		SELECT id, name, city, region_code, region, country_code, country_name,
+			latitude, longitude, score, prefix, popularity
+		FROM (
+			SELECT DISTINCT ON (COALESCE(hm.master_hotel_id, h.id))
+				h.id, h.name, h.city, COALESCE(d.code, '') AS region_code, COALESCE(d.name, '') AS region,
+				h.country_code, COALESCE(d.country_name, '') AS country_name, h.latitude, h.longitude,
+				` + trigramScore("h.name") + ` AS score,
+				` + trigramPrefix("h.name") + ` AS prefix,
+				COALESCE(h.review_count, 0) AS popularity
+			FROM hotels h
+			LEFT JOIN hotel_mappings hm ON hm.hotel_id = h.id
+			LEFT JOIN destinations d ON d.code = h.destination_code
+			WHERE h.deleted_at IS NULL
+				AND ` + trigramMatch("h.name") + `
+			ORDER BY COALESCE(hm.master_hotel_id, h.id), score DESC, popularity DESC
+		) matches
+		ORDER BY score DESC, popularity DESC, name
 		LIMIT $2
 	`
 
-	hotelRows, err := r.db.Pool.Query(ctx, hotelQuery, "%"+query+"%", limit/2)
+	rows, err := r.db.Pool.Query(ctx, sqlQuery, query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search hotels: %w", err)
 	}
-	defer hotelRows.Close()
+	defer rows.Close()
 
-	for hotelRows.Next() {
-		var id, name, city, countryCode string
-		if err := hotelRows.Scan(&id, &name, &city, &countryCode); err != nil {
-			continue
+	var suggestions []Suggestion
+	for rows.Next() {
+		s := Suggestion{AutocompleteResult: AutocompleteResult{Type: AutocompleteTypeHotel}}
+		err := rows.Scan(
+			&s.ID,
+			&s.Name,
+			&s.City,
+			&s.RegionCode,
+			&s.Region,
+			&s.CountryCode,
+			&s.CountryName,
+			&s.Latitude,
+			&s.Longitude,
+			&s.Similarity,
+			&s.Prefix,
+			&s.Popularity,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan hotel: %w", err)
 		}
 
-		results = append(results, AutocompleteResult{
-			Type:        AutocompleteTypeHotel,
-			ID:          id,
-			Name:        name,
-			FullName:    fmt.Sprintf("%s, %s", name, city),
-			City:        city,
-			CountryCode: countryCode,
-			CountryName: "Indonesia",
-		})
+		s.FullName = joinNonEmpty(s.Name, s.City)
+		suggestions = append(suggestions, s)
 	}
 
-	if len(results) == 0 {
-		return &AutocompleteResponse{
-			Query:   query,
-			Results: []AutocompleteResult{},
-		}, nil
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating hotels: %w", err)
 	}
 
-	return &AutocompleteResponse{
-		Query:   query,
-		Results: results,
-	}, nil
+	return suggestions, nil
+}
+
+// joinNonEmpty joins the non-empty parts of a display name
+func joinNonEmpty(parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
 }
 
 // GetPopularDestinations retrieves popular destinations (most searched cities)
@@ -0,0 +1,87 @@
+package search
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepository connects to the migrated database at
+// BOOKINGKUY_TEST_DATABASE_URL, skipping the test when it is not set
+func newTestRepository(t *testing.T) (Repository, *pgxpool.Pool) {
+	url := os.Getenv("BOOKINGKUY_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("BOOKINGKUY_TEST_DATABASE_URL not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return NewRepository(&db.DB{Pool: pool}), pool
+}
+
+// TestRepository_SuggestDestinations_CountsHotels tests that a destination's
+// popularity counts the hotels synced under its HotelBeds code
+func TestRepository_SuggestDestinations_CountsHotels(t *testing.T) {
+	repo, pool := newTestRepository(t)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `
+		INSERT INTO destinations (code, name, country_code, country_name, type)
+		VALUES ('ZZQ', 'Zzyzx Test Bay', 'ID', 'Indonesia', 'CITY')
+	`)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `
+		INSERT INTO hotels (provider_code, provider_hotel_id, name, country_code, city, destination_code)
+		VALUES ('hotelbeds', 'zzq-test-1', 'Zzyzx Bay Hotel', 'ID', 'Zzyzx', 'ZZQ'),
+			('hotelbeds', 'zzq-test-2', 'Zzyzx Bay Inn', 'ID', 'Zzyzx', 'ZZQ')
+	`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), `DELETE FROM hotels WHERE provider_hotel_id LIKE 'zzq-test-%'`)
+		pool.Exec(context.Background(), `DELETE FROM destinations WHERE code = 'ZZQ'`)
+	})
+
+	suggestions, err := repo.SuggestDestinations(ctx, "Zzyzx Test", nil, 5)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, suggestions)
+	assert.Equal(t, "ZZQ", suggestions[0].Code)
+	assert.Equal(t, 2, suggestions[0].Popularity)
+}
+
+// TestRepository_SearchCandidates_Limit tests that a search returns only the
+// hotels nearest to its point when there are more than the limit
+func TestRepository_SearchCandidates_Limit(t *testing.T) {
+	repo, pool := newTestRepository(t)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `
+		INSERT INTO hotels (provider_code, provider_hotel_id, name, country_code, city, latitude, longitude)
+		VALUES ('hotelbeds', 'zzq-cap-1', 'Near Hotel', 'ID', 'Zzyzx Cap', -8.7000, 115.1700),
+			('hotelbeds', 'zzq-cap-2', 'Middle Hotel', 'ID', 'Zzyzx Cap', -8.7200, 115.1700),
+			('hotelbeds', 'zzq-cap-3', 'Far Hotel', 'ID', 'Zzyzx Cap', -8.8000, 115.1700)
+	`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), `DELETE FROM hotels WHERE provider_hotel_id LIKE 'zzq-cap-%'`)
+	})
+
+	candidates, err := repo.SearchCandidates(ctx, &SearchRequest{
+		City:      "Zzyzx Cap",
+		Latitude:  pointerToFloat64(-8.7000),
+		Longitude: pointerToFloat64(115.1700),
+	}, 2)
+	require.NoError(t, err)
+
+	var names []string
+	for _, c := range candidates {
+		names = append(names, c.Name)
+	}
+	assert.ElementsMatch(t, []string{"Near Hotel", "Middle Hotel"}, names)
+}
@@ -1,82 +1,558 @@
 package search
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
+	"github.com/ekonugroho98/be-bookingkuy/internal/pricing"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
 )
 
 // Service defines interface for search business logic
 type Service interface {
 	SearchHotels(ctx context.Context, req *SearchRequest, opts *SearchOptions) (*SearchResult, error)
-	Autocomplete(ctx context.Context, query string, limit int) (*AutocompleteResponse, error)
+	Autocomplete(ctx context.Context, req *AutocompleteRequest) (*AutocompleteResponse, error)
 	GetPopularDestinations(ctx context.Context, limit int) ([]AutocompleteResult, error)
 }
 
+// Config tunes how searches check live availability and how long
+// autocomplete may take
+type Config struct {
+	MaxCandidates       int           // Hotels a search checks at most, nearest or most reviewed first
+	MaxConcurrentChecks int           // Provider availability checks in flight per search
+	HotelsPerCheck      int           // Hotels of a provider checked with one availability request
+	CheckTimeout        time.Duration // Time a search's availability checks may take in all
+	AutocompleteBudget  time.Duration // Time an autocomplete may take; slower sources are left out
+}
+
+// DefaultConfig returns the default search configuration
+func DefaultConfig() Config {
+	return Config{
+		MaxCandidates:       500,
+		MaxConcurrentChecks: 10,
+		HotelsPerCheck:      50,
+		CheckTimeout:        10 * time.Second,
+		AutocompleteBudget:  300 * time.Millisecond,
+	}
+}
+
 type service struct {
-	repo Repository
+	repo      Repository
+	providers provider.Gateway
+	pricing   pricing.Service
+	config    Config
 }
 
 // NewService creates a new search service
-func NewService(repo Repository) Service {
+func NewService(repo Repository, providers provider.Gateway, ps pricing.Service, config Config) Service {
 	return &service{
-		repo: repo,
+		repo:      repo,
+		providers: providers,
+		pricing:   ps,
+		config:    config,
 	}
 }
 
+// SearchHotels finds the hotels in the local content index with rooms
+// available for the stay. Each hotel's providers are checked live and the
+// hotel is priced from its cheapest room; hotels with no availability are
+// dropped. Hotels whose check failed or timed out are counted as unchecked,
+// hotels available only at rates in another currency as unpriced, and either
+// marks the result partial. Price filters and sorting apply to the sell
+// prices, in the search's currency.
 func (s *service) SearchHotels(ctx context.Context, req *SearchRequest, opts *SearchOptions) (*SearchResult, error) {
 	// Validate dates
 	if req.CheckOut.Before(req.CheckIn) || req.CheckOut.Equal(req.CheckIn) {
-		return nil, fmt.Errorf("check-out date must be after check-in date")
+		return nil, fmt.Errorf("%w: check-out date must be after check-in date", ErrInvalidDates)
 	}
 
 	// Validate guests
 	if req.Guests < 1 || req.Guests > 10 {
-		return nil, fmt.Errorf("number of guests must be between 1 and 10")
+		return nil, fmt.Errorf("%w: number of guests must be between 1 and 10", ErrInvalidGuests)
 	}
 
 	// Set default pagination options
-	if opts.Page == 0 {
+	if opts.Page <= 0 {
 		opts.Page = 1
 	}
-	if opts.PerPage == 0 {
+	if opts.PerPage <= 0 {
 		opts.PerPage = 20
 	}
 
-	logger.Infof("Searching hotels in %s for %d guests", req.City, req.Guests)
+	// Validate and resolve the location
+	req, err := s.resolveLocation(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Searching hotels in %s for %d guests", describeLocation(req), req.Guests)
 
-	// Execute search
-	result, err := s.repo.SearchHotels(ctx, req, opts)
+	// 1. Find the candidate hotels in the content index
+	candidates, err := s.repo.SearchCandidates(ctx, req, max(s.config.MaxCandidates, 1))
 	if err != nil {
 		logger.ErrorWithErr(err, "Failed to search hotels")
 		return nil, fmt.Errorf("failed to search hotels: %w", err)
 	}
 
-	logger.Infof("Found %d hotels (page %d of %d)", result.Total, result.Page, result.TotalPages)
+	// 2. Price them with live availability
+	priced, unchecked, unpriced, err := s.priceHotels(ctx, req, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Filter, sort and paginate on sell prices, counting facets
+	hotels := filterHotels(priced, req)
+	sortHotels(hotels, opts.SortBy)
+	result := paginate(hotels, opts)
+	result.Facets = countFacets(priced, req)
+	result.Unchecked = unchecked
+	result.Unpriced = unpriced
+	result.Partial = unchecked > 0 || unpriced > 0
+
+	logger.Infof("Found %d available hotels of %d candidates (page %d of %d)",
+		result.Total, len(candidates), result.Page, result.TotalPages)
 	return result, nil
 }
 
-// Autocomplete provides search suggestions for cities and hotels
-func (s *service) Autocomplete(ctx context.Context, query string, limit int) (*AutocompleteResponse, error) {
+// resolveLocation validates where a search looks and returns the request
+// with a landmark resolved to its point and the default radius applied
+func (s *service) resolveLocation(ctx context.Context, req *SearchRequest) (*SearchRequest, error) {
+	hasPoint := req.Latitude != nil || req.Longitude != nil
+	if req.City == "" && !hasPoint && req.Landmark == "" && req.Bounds == nil {
+		return nil, ErrMissingLocation
+	}
+
+	if hasPoint {
+		if req.Latitude == nil || req.Longitude == nil {
+			return nil, fmt.Errorf("%w: latitude and longitude must be given together", ErrInvalidLocation)
+		}
+		if req.Landmark != "" {
+			return nil, fmt.Errorf("%w: search around a point or a landmark, not both", ErrInvalidLocation)
+		}
+		if !validCoordinates(*req.Latitude, *req.Longitude) {
+			return nil, fmt.Errorf("%w: coordinates out of range", ErrInvalidLocation)
+		}
+	}
+	if req.RadiusKm < 0 || req.RadiusKm > MaxRadiusKm {
+		return nil, fmt.Errorf("%w: radius must be between 0 and %g km", ErrInvalidLocation, MaxRadiusKm)
+	}
+	if b := req.Bounds; b != nil {
+		if !validCoordinates(b.North, b.East) || !validCoordinates(b.South, b.West) || b.South > b.North {
+			return nil, fmt.Errorf("%w: invalid map bounds", ErrInvalidLocation)
+		}
+	}
+
+	resolved := *req
+	if req.Landmark != "" {
+		landmark, err := s.repo.GetLandmark(ctx, req.Landmark)
+		if err != nil {
+			if errors.Is(err, ErrLandmarkNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrLandmarkNotFound, req.Landmark)
+			}
+			logger.ErrorWithErr(err, "Failed to get landmark")
+			return nil, fmt.Errorf("failed to search hotels: %w", err)
+		}
+		resolved.Latitude, resolved.Longitude = &landmark.Latitude, &landmark.Longitude
+	}
+
+	// A point searches around it, unless it only orders a viewport by distance
+	if resolved.Latitude != nil && resolved.RadiusKm == 0 && resolved.Bounds == nil {
+		resolved.RadiusKm = DefaultRadiusKm
+	}
+	return &resolved, nil
+}
+
+func validCoordinates(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
+
+// describeLocation names where a search looks, for logs
+func describeLocation(req *SearchRequest) string {
+	var parts []string
+	if req.City != "" {
+		parts = append(parts, req.City)
+	}
+	if req.Landmark != "" {
+		parts = append(parts, fmt.Sprintf("%gkm around landmark %s", req.RadiusKm, req.Landmark))
+	} else if req.Latitude != nil && req.RadiusKm > 0 {
+		parts = append(parts, fmt.Sprintf("%gkm around %.5f,%.5f", req.RadiusKm, *req.Latitude, *req.Longitude))
+	}
+	if b := req.Bounds; b != nil {
+		parts = append(parts, fmt.Sprintf("bounds %.5f,%.5f to %.5f,%.5f", b.South, b.West, b.North, b.East))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// offer is the cheapest sell price of a hotel's rates on one board and
+// cancellation terms
+type offer struct {
+	price            int
+	currency         string
+	mealPlan         string
+	freeCancellation bool
+}
+
+// pricedHotel is an available hotel with its offers, cheapest first
+type pricedHotel struct {
+	Hotel
+	offers []offer
+}
+
+// priceHotels checks the candidates' availability and returns each hotel
+// with rooms available, with the offers of all its providers, the number of
+// hotels that could not be checked and the number available only in another
+// currency than the search's. A provider's candidates are checked in batches
+// of HotelsPerCheck, one availability request each.
+func (s *service) priceHotels(ctx context.Context, req *SearchRequest, candidates []Candidate) ([]*pricedHotel, int, int, error) {
+	if len(candidates) == 0 {
+		return []*pricedHotel{}, 0, 0, nil
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, s.config.CheckTimeout)
+	defer cancel()
+
+	batches := batchCandidates(candidates, max(s.config.HotelsPerCheck, 1))
+	availability := make([]map[string]*types.HotelAvailability, len(batches))
+	errs := make([]error, len(batches))
+	sem := make(chan struct{}, max(s.config.MaxConcurrentChecks, 1))
+	var wg sync.WaitGroup
+	for i := range batches {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-checkCtx.Done():
+				errs[i] = checkCtx.Err()
+				return
+			}
+			availability[i], errs[i] = s.checkBatch(checkCtx, req, batches[i])
+		}(i)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, 0, 0, ctx.Err()
+	}
+
+	// Offers of each candidate; a candidate in a failed batch is unchecked,
+	// one with rooms but no rate in the search's currency is unpriced
+	currency := req.PriceCurrency()
+	offers := make([][]offer, len(candidates))
+	checked := make([]bool, len(candidates))
+	unpricedCandidates := make([]bool, len(candidates))
+	var failed int
+	for i, b := range batches {
+		if errs[i] != nil {
+			failed++
+			logger.Warnf("Availability check of %d hotels with %s failed: %v", len(b.indexes), b.providerCode, errs[i])
+			continue
+		}
+		for _, j := range b.indexes {
+			c := &candidates[j]
+			checked[j] = true
+			if a := availability[i][c.ProviderHotelID]; a != nil {
+				o, skipped, err := s.offersFor(c, a, currency)
+				if err != nil {
+					return nil, 0, 0, err
+				}
+				offers[j] = o
+				unpricedCandidates[j] = len(o) == 0 && skipped > 0
+			}
+		}
+	}
+	if failed == len(batches) {
+		return nil, 0, 0, fmt.Errorf("%w: all %d availability checks failed", ErrProvidersUnavailable, failed)
+	}
+
+	// A group's hotel takes the offers of all its providers. It is unchecked
+	// when it has none and a provider's check failed, and otherwise unpriced
+	// when a provider only had rates in another currency.
+	var groupIDs []string
+	group := make(map[string]*pricedHotel)
+	uncheckedGroups := make(map[string]bool)
+	unpricedGroups := make(map[string]bool)
+	for i := range candidates {
+		c := &candidates[i]
+		h, seen := group[c.GroupID]
+		if !seen {
+			h = &pricedHotel{Hotel: c.Hotel}
+			group[c.GroupID] = h
+			groupIDs = append(groupIDs, c.GroupID)
+		}
+		h.offers = append(h.offers, offers[i]...)
+		if !checked[i] {
+			uncheckedGroups[c.GroupID] = true
+		}
+		if unpricedCandidates[i] {
+			unpricedGroups[c.GroupID] = true
+		}
+	}
+
+	available := []*pricedHotel{}
+	var unchecked, unpriced int
+	for _, id := range groupIDs {
+		h := group[id]
+		if len(h.offers) == 0 {
+			switch {
+			case uncheckedGroups[id]:
+				unchecked++
+			case unpricedGroups[id]:
+				unpriced++
+			}
+			continue
+		}
+		slices.SortStableFunc(h.offers, func(a, b offer) int {
+			return cmp.Compare(a.price, b.price)
+		})
+		available = append(available, h)
+	}
+	if unpriced > 0 {
+		logger.Warnf("%d available hotels have no rates in %s and were left out", unpriced, currency)
+	}
+	return available, unchecked, unpriced, nil
+}
+
+// batch is candidates of one provider checked with one availability request
+type batch struct {
+	providerCode string
+	indexes      []int    // Of the candidates
+	hotelIDs     []string // The candidates' provider hotel IDs
+}
+
+// batchCandidates splits the candidates by provider into batches of at most
+// size hotels
+func batchCandidates(candidates []Candidate, size int) []*batch {
+	var batches []*batch
+	open := make(map[string]*batch)
+	for i := range candidates {
+		c := &candidates[i]
+		b, ok := open[c.ProviderCode]
+		if !ok || len(b.indexes) == size {
+			b = &batch{providerCode: c.ProviderCode}
+			open[c.ProviderCode] = b
+			batches = append(batches, b)
+		}
+		b.indexes = append(b.indexes, i)
+		b.hotelIDs = append(b.hotelIDs, c.ProviderHotelID)
+	}
+	return batches
+}
+
+// checkBatch checks a batch's hotels with its provider and returns the
+// availability of those with rooms by provider hotel ID
+func (s *service) checkBatch(ctx context.Context, req *SearchRequest, b *batch) (map[string]*types.HotelAvailability, error) {
+	hotels, err := s.providers.CheckHotelsAvailability(ctx, b.providerCode, &types.HotelsRequest{
+		HotelIDs: b.hotelIDs,
+		CheckIn:  req.CheckIn,
+		CheckOut: req.CheckOut,
+		Guests:   req.Guests,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	available := make(map[string]*types.HotelAvailability, len(hotels))
+	for i := range hotels {
+		available[hotels[i].Hotel.ID] = &hotels[i]
+	}
+	return available, nil
+}
+
+// offersFor returns a candidate's offers in currency from its availability
+// and the number of rates skipped for being in another currency
+func (s *service) offersFor(c *Candidate, availability *types.HotelAvailability, currency string) ([]offer, int, error) {
+	// Cheapest net rate per board and cancellation terms
+	type terms struct {
+		mealPlan         string
+		freeCancellation bool
+	}
+	now := time.Now()
+	cheapest := make(map[terms]*types.Rate)
+	var skipped int
+	for _, room := range availability.Rooms {
+		for i := range room.Rates {
+			rate := &room.Rates[i]
+			if !strings.EqualFold(rate.Currency, currency) {
+				skipped++
+				continue
+			}
+			t := terms{mealPlan: rate.MealPlan, freeCancellation: isFreeCancellation(rate.Cancellation, now)}
+			if best, ok := cheapest[t]; !ok || rate.NetPrice < best.NetPrice {
+				cheapest[t] = rate
+			}
+		}
+	}
+
+	// Markups only grow with the net price, so the cheapest net rate sells cheapest
+	var category pricing.HotelCategory
+	if c.StarRating != nil {
+		category = pricing.HotelCategory(math.Round(*c.StarRating))
+	}
+	offers := make([]offer, 0, len(cheapest))
+	for t, rate := range cheapest {
+		calc, err := s.pricing.CalculateSellPrice(rate.NetPrice, category)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to price rate: %w", err)
+		}
+		offers = append(offers, offer{
+			price:            calc.SellPrice,
+			currency:         rate.Currency,
+			mealPlan:         t.mealPlan,
+			freeCancellation: t.freeCancellation,
+		})
+	}
+	return offers, skipped, nil
+}
+
+// isFreeCancellation reports whether a rate can still be cancelled for free
+func isFreeCancellation(policy types.CancellationPolicy, now time.Time) bool {
+	return !policy.NonRefundable && policy.FreeCancellationBefore.After(now)
+}
+
+// sortHotels orders hotels by sortBy, by name by default
+func sortHotels(hotels []Hotel, sortBy SortBy) {
+	byName := func(a, b Hotel) int {
+		return strings.Compare(a.Name, b.Name)
+	}
+
+	switch sortBy {
+	case SortByPrice:
+		slices.SortStableFunc(hotels, func(a, b Hotel) int {
+			return cmp.Or(cmp.Compare(a.FromPrice, b.FromPrice), byName(a, b))
+		})
+	case SortByRating:
+		// Unrated hotels last
+		slices.SortStableFunc(hotels, func(a, b Hotel) int {
+			switch {
+			case a.Rating == nil && b.Rating == nil:
+				return byName(a, b)
+			case a.Rating == nil:
+				return 1
+			case b.Rating == nil:
+				return -1
+			}
+			return cmp.Or(cmp.Compare(*b.Rating, *a.Rating), byName(a, b))
+		})
+	case SortByDistance:
+		// Hotels without a distance last
+		slices.SortStableFunc(hotels, func(a, b Hotel) int {
+			switch {
+			case a.DistanceKm == nil && b.DistanceKm == nil:
+				return byName(a, b)
+			case a.DistanceKm == nil:
+				return 1
+			case b.DistanceKm == nil:
+				return -1
+			}
+			return cmp.Or(cmp.Compare(*a.DistanceKm, *b.DistanceKm), byName(a, b))
+		})
+	default:
+		slices.SortStableFunc(hotels, byName)
+	}
+}
+
+// paginate returns the requested page of hotels
+func paginate(hotels []Hotel, opts *SearchOptions) *SearchResult {
+	total := len(hotels)
+	totalPages := (total + opts.PerPage - 1) / opts.PerPage
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	start := min((opts.Page-1)*opts.PerPage, total)
+	end := min(start+opts.PerPage, total)
+
+	return &SearchResult{
+		Hotels:     hotels[start:end],
+		Total:      total,
+		Page:       opts.Page,
+		PerPage:    opts.PerPage,
+		TotalPages: totalPages,
+	}
+}
+
+// Autocomplete suggests destinations and hotels for a query typed so far,
+// tolerating typos and accents. Destinations and hotels are matched at once
+// within the latency budget; a source that misses it or fails is left out
+// and the response marked partial.
+func (s *service) Autocomplete(ctx context.Context, req *AutocompleteRequest) (*AutocompleteResponse, error) {
+	response := &AutocompleteResponse{
+		Query:   req.Query,
+		Results: []AutocompleteResult{},
+	}
+
 	// Validate query length
-	if len(query) < 2 {
-		return &AutocompleteResponse{
-			Query:   query,
-			Results: []AutocompleteResult{},
-		}, nil
+	query := strings.TrimSpace(req.Query)
+	if utf8.RuneCountInString(query) < 2 {
+		return response, nil
 	}
+	limit := req.Limit
+	if limit < 1 || limit > MaxAutocompleteLimit {
+		limit = 10
+	}
+
+	// Hotels are one source, every other type is a destination
+	destinationTypes := slices.DeleteFunc(slices.Clone(req.Types), func(t AutocompleteResultType) bool {
+		return t == AutocompleteTypeHotel
+	})
+	wantDestinations := len(req.Types) == 0 || len(destinationTypes) > 0
+	wantHotels := len(req.Types) == 0 || slices.Contains(req.Types, AutocompleteTypeHotel)
 
 	logger.Infof("Autocomplete search for: %s", query)
 
-	opts := &AutocompleteOptions{Limit: limit}
-	result, err := s.repo.Autocomplete(ctx, query, opts)
-	if err != nil {
+	budgetCtx, cancel := context.WithTimeout(ctx, s.config.AutocompleteBudget)
+	defer cancel()
+
+	// Each source fetches extra matches, as popularity can reorder them
+	fetch := limit * 2
+
+	var destinations, hotels []Suggestion
+	var destinationsErr, hotelsErr error
+	var wg sync.WaitGroup
+	if wantDestinations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			destinations, destinationsErr = s.repo.SuggestDestinations(budgetCtx, query, destinationTypes, fetch)
+		}()
+	}
+	if wantHotels {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hotels, hotelsErr = s.repo.SuggestHotels(budgetCtx, query, fetch)
+		}()
+	}
+	wg.Wait()
+
+	if (!wantDestinations || destinationsErr != nil) && (!wantHotels || hotelsErr != nil) {
+		err := errors.Join(destinationsErr, hotelsErr)
 		logger.ErrorWithErr(err, "Failed to get autocomplete results")
 		return nil, fmt.Errorf("failed to get autocomplete: %w", err)
 	}
+	if destinationsErr != nil {
+		logger.Warnf("Autocomplete for %q without destinations: %v", query, destinationsErr)
+		response.Partial = true
+	}
+	if hotelsErr != nil {
+		logger.Warnf("Autocomplete for %q without hotels: %v", query, hotelsErr)
+		response.Partial = true
+	}
 
-	return result, nil
+	response.Results = rankSuggestions(append(destinations, hotels...), limit)
+	return response, nil
 }
 
 // GetPopularDestinations returns popular destination cities
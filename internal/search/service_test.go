@@ -3,9 +3,14 @@ package search
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/ekonugroho98/be-bookingkuy/internal/pricing"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider"
+	"github.com/ekonugroho98/be-bookingkuy/internal/provider/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -16,339 +21,406 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) SearchHotels(ctx context.Context, req *SearchRequest, opts *SearchOptions) (*SearchResult, error) {
-	args := m.Called(ctx, req, opts)
+func (m *MockRepository) SearchCandidates(ctx context.Context, req *SearchRequest, limit int) ([]Candidate, error) {
+	args := m.Called(ctx, req, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*SearchResult), args.Error(1)
+	return args.Get(0).([]Candidate), args.Error(1)
 }
 
-// TestNewService tests creating a new search service
-func TestNewService(t *testing.T) {
-	mockRepo := new(MockRepository)
+func (m *MockRepository) GetLandmark(ctx context.Context, code string) (*Landmark, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Landmark), args.Error(1)
+}
 
-	service := NewService(mockRepo)
+func (m *MockRepository) SuggestDestinations(ctx context.Context, query string, types []AutocompleteResultType, limit int) ([]Suggestion, error) {
+	args := m.Called(ctx, query, types, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Suggestion), args.Error(1)
+}
 
-	require.NotNil(t, service)
+func (m *MockRepository) SuggestHotels(ctx context.Context, query string, limit int) ([]Suggestion, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Suggestion), args.Error(1)
 }
 
-// TestService_SearchHotels_Success tests successful hotel search
-func TestService_SearchHotels_Success(t *testing.T) {
-	mockRepo := new(MockRepository)
+func (m *MockRepository) GetPopularDestinations(ctx context.Context, limit int) ([]AutocompleteResult, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]AutocompleteResult), args.Error(1)
+}
 
-	service := NewService(mockRepo)
+// stubGateway returns rates by provider and provider hotel ID, a room per
+// rate; hotels it doesn't know have no rooms. It fails every check when err
+// is set, and the checks of the providers in failing. Other gateway methods
+// are unused.
+type stubGateway struct {
+	provider.Gateway
+	rates   map[string][]types.Rate
+	err     error
+	failing map[string]error
+
+	mu     sync.Mutex
+	checks []*types.HotelsRequest
+}
 
-	ctx := context.Background()
-	checkIn := time.Now().Add(24 * time.Hour)
-	checkOut := time.Now().Add(48 * time.Hour)
+func (g *stubGateway) CheckHotelsAvailability(ctx context.Context, providerCode string, req *types.HotelsRequest) ([]types.HotelAvailability, error) {
+	g.mu.Lock()
+	g.checks = append(g.checks, req)
+	g.mu.Unlock()
 
-	req := &SearchRequest{
-		CheckIn:  checkIn,
-		CheckOut: checkOut,
+	if g.err != nil {
+		return nil, g.err
+	}
+	if err := g.failing[providerCode]; err != nil {
+		return nil, err
+	}
+
+	var hotels []types.HotelAvailability
+	for _, hotelID := range req.HotelIDs {
+		availability := types.HotelAvailability{Hotel: types.Hotel{ID: hotelID}, Provider: providerCode}
+		for i, rate := range g.rates[providerCode+"/"+hotelID] {
+			availability.Rooms = append(availability.Rooms, types.RoomRate{
+				Room:  types.Room{ID: fmt.Sprintf("ROOM-%d", i)},
+				Rates: []types.Rate{rate},
+			})
+		}
+		if len(availability.Rooms) > 0 {
+			hotels = append(hotels, availability)
+		}
+	}
+	return hotels, nil
+}
+
+// netRates builds room-only, non-refundable rates
+func netRates(prices ...int) []types.Rate {
+	rates := make([]types.Rate, len(prices))
+	for i, price := range prices {
+		rates[i] = types.Rate{NetPrice: price, Currency: "IDR", MealPlan: "RO", Cancellation: types.CancellationPolicy{NonRefundable: true}}
+	}
+	return rates
+}
+
+func newTestService(repo Repository, gateway provider.Gateway) Service {
+	return NewService(repo, gateway, pricing.NewService(), DefaultConfig())
+}
+
+func testRequest() *SearchRequest {
+	return &SearchRequest{
+		CheckIn:  time.Now().Add(24 * time.Hour),
+		CheckOut: time.Now().Add(48 * time.Hour),
 		City:     "Bali",
 		Guests:   2,
 	}
+}
 
-	opts := &SearchOptions{
-		Page:    1,
-		PerPage: 10,
-		SortBy:  SortByRating,
+// candidate builds a four-star candidate, which sells at an 18% markup
+func candidate(groupID, id, name, providerCode string, rating float64) Candidate {
+	return Candidate{
+		Hotel: Hotel{
+			ID:          id,
+			Name:        name,
+			CountryCode: "ID",
+			City:        "Bali",
+			Rating:      pointerToFloat64(rating),
+			StarRating:  pointerToFloat64(4),
+		},
+		GroupID:         groupID,
+		ProviderCode:    providerCode,
+		ProviderHotelID: "P-" + id,
 	}
+}
 
-	expectedResult := &SearchResult{
-		Hotels: []Hotel{
-			{
-				ID:      "hotel-1",
-				Name:    "Grand Hotel Bali",
-				Country: "Indonesia",
-				City:    "Bali",
-				Rating:  pointerToFloat64(4.5),
-			},
-			{
-				ID:      "hotel-2",
-				Name:    "Bali Beach Resort",
-				Country: "Indonesia",
-				City:    "Bali",
-				Rating:  pointerToFloat64(4.0),
-			},
-		},
-		Total:      25,
-		Page:       1,
-		PerPage:    10,
-		TotalPages: 3,
+// testCandidates are three hotels, one sold by two providers and one with
+// no availability
+func testCandidates() []Candidate {
+	return []Candidate{
+		candidate("master-1", "hotel-1", "Grand Hotel Bali", "hotelbeds", 4.5),
+		candidate("master-1", "hotel-1b", "Grand Hotel Bali (HP)", "hotelplanner", 4.5),
+		candidate("hotel-2", "hotel-2", "Bali Beach Resort", "hotelbeds", 4.0),
+		candidate("hotel-3", "hotel-3", "Sold Out Inn", "hotelbeds", 3.0),
 	}
+}
 
-	// Setup expectations
-	mockRepo.On("SearchHotels", ctx, mock.MatchedBy(func(r *SearchRequest) bool {
-		return r.City == "Bali" && r.Guests == 2
-	}), mock.MatchedBy(func(o *SearchOptions) bool {
-		return o.Page == 1 && o.PerPage == 10
-	})).Return(expectedResult, nil)
+func testGateway() *stubGateway {
+	return &stubGateway{rates: map[string][]types.Rate{
+		"hotelbeds/P-hotel-1":     netRates(2000000, 1500000),
+		"hotelplanner/P-hotel-1b": netRates(1000000),
+		"hotelbeds/P-hotel-2":     netRates(500000),
+	}}
+}
 
-	// Execute
-	result, err := service.SearchHotels(ctx, req, opts)
+// TestNewService tests creating a new search service
+func TestNewService(t *testing.T) {
+	mockRepo := new(MockRepository)
 
-	// Assertions
-	require.NoError(t, err)
-	require.NotNil(t, result)
-	assert.Len(t, result.Hotels, 2)
-	assert.Equal(t, 25, result.Total)
-	assert.Equal(t, 1, result.Page)
-	assert.Equal(t, 10, result.PerPage)
-	assert.Equal(t, 3, result.TotalPages)
-	assert.Equal(t, "Grand Hotel Bali", result.Hotels[0].Name)
+	service := newTestService(mockRepo, testGateway())
 
-	mockRepo.AssertExpectations(t)
+	require.NotNil(t, service)
 }
 
-// TestService_SearchHotels_InvalidDates_CheckOutBeforeCheckIn tests error when check-out is before check-in
-func TestService_SearchHotels_InvalidDates_CheckOutBeforeCheckIn(t *testing.T) {
+// TestService_SearchHotels_PricesInOneCurrency tests that rates in another
+// currency than the search's are not compared with its prices
+func TestService_SearchHotels_PricesInOneCurrency(t *testing.T) {
 	mockRepo := new(MockRepository)
-
-	service := NewService(mockRepo)
+	gateway := testGateway()
+	usd := netRates(60)
+	usd[0].Currency = "USD"
+	gateway.rates["hotelplanner/P-hotel-1b"] = usd
+	service := newTestService(mockRepo, gateway)
 
 	ctx := context.Background()
-	checkIn := time.Now().Add(48 * time.Hour)
-	checkOut := time.Now().Add(24 * time.Hour) // Before check-in
-
-	req := &SearchRequest{
-		CheckIn:  checkIn,
-		CheckOut: checkOut,
-		City:     "Bali",
-		Guests:   2,
-	}
+	req := testRequest()
+	req.MaxPrice = intPtr(1000000)
+	opts := &SearchOptions{Page: 1, PerPage: 10, SortBy: SortByPrice}
 
-	opts := &SearchOptions{}
+	mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return(testCandidates(), nil)
 
-	// Execute
 	result, err := service.SearchHotels(ctx, req, opts)
 
-	// Assertions
-	require.Error(t, err)
-	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "check-out date must be after check-in date")
+	require.NoError(t, err)
+	require.Len(t, result.Hotels, 1, "the USD rate is not under the IDR price limit")
+	assert.Equal(t, "hotel-2", result.Hotels[0].ID)
+	assert.Equal(t, "IDR", result.Hotels[0].Currency)
+	assert.Zero(t, result.Unpriced, "hotel-1 still has an IDR rate")
+	assert.False(t, result.Partial)
+
+	req.Currency = "usd"
+	req.MaxPrice = nil
+	result, err = service.SearchHotels(ctx, req, opts)
+
+	require.NoError(t, err)
+	require.Len(t, result.Hotels, 1)
+	assert.Equal(t, "hotel-1", result.Hotels[0].ID)
+	assert.Equal(t, "USD", result.Hotels[0].Currency)
+	// hotel-2 has rooms but only IDR rates, so it is reported as unpriced
+	// rather than looking sold out
+	assert.Equal(t, 1, result.Unpriced)
+	assert.Zero(t, result.Unchecked)
+	assert.True(t, result.Partial)
 }
 
-// TestService_SearchHotels_InvalidDates_EqualDates tests error when check-out equals check-in
-func TestService_SearchHotels_InvalidDates_EqualDates(t *testing.T) {
+// TestService_SearchHotels_Success tests that hotels are priced from their
+// cheapest provider and hotels without availability are dropped
+func TestService_SearchHotels_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
-
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, testGateway())
 
 	ctx := context.Background()
-	checkIn := time.Now().Add(24 * time.Hour)
-	checkOut := checkIn // Same as check-in
+	req := testRequest()
+	opts := &SearchOptions{Page: 1, PerPage: 10, SortBy: SortByRating}
 
-	req := &SearchRequest{
-		CheckIn:  checkIn,
-		CheckOut: checkOut,
-		City:     "Bali",
-		Guests:   2,
-	}
-
-	opts := &SearchOptions{}
+	mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return(testCandidates(), nil)
 
-	// Execute
 	result, err := service.SearchHotels(ctx, req, opts)
 
-	// Assertions
-	require.Error(t, err)
-	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "check-out date must be after check-in date")
+	require.NoError(t, err)
+	require.Len(t, result.Hotels, 2)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, 1, result.TotalPages)
+
+	// The master hotel shows its content hotel at the cheaper provider's price
+	assert.Equal(t, "hotel-1", result.Hotels[0].ID)
+	assert.Equal(t, "Grand Hotel Bali", result.Hotels[0].Name)
+	assert.Equal(t, 1180000, result.Hotels[0].FromPrice)
+	assert.Equal(t, "IDR", result.Hotels[0].Currency)
+
+	assert.Equal(t, "hotel-2", result.Hotels[1].ID)
+	assert.Equal(t, 590000, result.Hotels[1].FromPrice)
+
+	mockRepo.AssertExpectations(t)
 }
 
-// TestService_SearchHotels_InvalidGuests_TooFew tests error when guests < 1
-func TestService_SearchHotels_InvalidGuests_TooFew(t *testing.T) {
+// TestService_SearchHotels_SortByPrice tests sorting by sell price
+func TestService_SearchHotels_SortByPrice(t *testing.T) {
 	mockRepo := new(MockRepository)
-
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, testGateway())
 
 	ctx := context.Background()
-	checkIn := time.Now().Add(24 * time.Hour)
-	checkOut := time.Now().Add(48 * time.Hour)
-
-	req := &SearchRequest{
-		CheckIn:  checkIn,
-		CheckOut: checkOut,
-		City:     "Bali",
-		Guests:   0, // Invalid
-	}
+	req := testRequest()
+	opts := &SearchOptions{Page: 1, PerPage: 10, SortBy: SortByPrice}
 
-	opts := &SearchOptions{}
+	mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return(testCandidates(), nil)
 
-	// Execute
 	result, err := service.SearchHotels(ctx, req, opts)
 
-	// Assertions
-	require.Error(t, err)
-	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "number of guests must be between 1 and 10")
+	require.NoError(t, err)
+	require.Len(t, result.Hotels, 2)
+	assert.Equal(t, "hotel-2", result.Hotels[0].ID)
+	assert.Equal(t, "hotel-1", result.Hotels[1].ID)
 }
 
-// TestService_SearchHotels_InvalidGuests_TooMany tests error when guests > 10
-func TestService_SearchHotels_InvalidGuests_TooMany(t *testing.T) {
-	mockRepo := new(MockRepository)
+// TestService_SearchHotels_WithPriceFilters tests that price filters apply
+// to sell prices
+func TestService_SearchHotels_WithPriceFilters(t *testing.T) {
+	testCases := []struct {
+		name     string
+		minPrice *int
+		maxPrice *int
+		expected []string
+	}{
+		{name: "Min price", minPrice: intPtr(600000), expected: []string{"hotel-1"}},
+		{name: "Max price on sell price", maxPrice: intPtr(1000000), expected: []string{"hotel-2"}},
+		{name: "Range", minPrice: intPtr(500000), maxPrice: intPtr(2000000), expected: []string{"hotel-1", "hotel-2"}},
+		{name: "Nothing in range", minPrice: intPtr(3000000), expected: []string{}},
+	}
 
-	service := NewService(mockRepo)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := newTestService(mockRepo, testGateway())
 
-	ctx := context.Background()
-	checkIn := time.Now().Add(24 * time.Hour)
-	checkOut := time.Now().Add(48 * time.Hour)
+			ctx := context.Background()
+			req := testRequest()
+			req.MinPrice = tc.minPrice
+			req.MaxPrice = tc.maxPrice
+			opts := &SearchOptions{Page: 1, PerPage: 10, SortBy: SortByPrice}
 
-	req := &SearchRequest{
-		CheckIn:  checkIn,
-		CheckOut: checkOut,
-		City:     "Bali",
-		Guests:   11, // Invalid
+			mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return(testCandidates(), nil)
+
+			result, err := service.SearchHotels(ctx, req, opts)
+
+			require.NoError(t, err)
+			ids := []string{}
+			for _, h := range result.Hotels {
+				ids = append(ids, h.ID)
+			}
+			assert.ElementsMatch(t, tc.expected, ids)
+			assert.Equal(t, len(tc.expected), result.Total)
+		})
 	}
+}
 
-	opts := &SearchOptions{}
+// TestService_SearchHotels_InvalidDates_CheckOutBeforeCheckIn tests error when check-out is before check-in
+func TestService_SearchHotels_InvalidDates_CheckOutBeforeCheckIn(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, testGateway())
 
-	// Execute
-	result, err := service.SearchHotels(ctx, req, opts)
+	req := testRequest()
+	req.CheckIn, req.CheckOut = req.CheckOut, req.CheckIn
 
-	// Assertions
-	require.Error(t, err)
+	result, err := service.SearchHotels(context.Background(), req, &SearchOptions{})
+
+	require.ErrorIs(t, err, ErrInvalidDates)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "number of guests must be between 1 and 10")
+	assert.Contains(t, err.Error(), "check-out date must be after check-in date")
 }
 
-// TestService_SearchHotels_DefaultPagination tests default pagination values
-func TestService_SearchHotels_DefaultPagination(t *testing.T) {
+// TestService_SearchHotels_InvalidDates_EqualDates tests error when check-out equals check-in
+func TestService_SearchHotels_InvalidDates_EqualDates(t *testing.T) {
 	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, testGateway())
 
-	service := NewService(mockRepo)
+	req := testRequest()
+	req.CheckOut = req.CheckIn
 
-	ctx := context.Background()
-	checkIn := time.Now().Add(24 * time.Hour)
-	checkOut := time.Now().Add(48 * time.Hour)
+	result, err := service.SearchHotels(context.Background(), req, &SearchOptions{})
 
-	req := &SearchRequest{
-		CheckIn:  checkIn,
-		CheckOut: checkOut,
-		City:     "Bali",
-		Guests:   2,
-	}
+	require.ErrorIs(t, err, ErrInvalidDates)
+	assert.Nil(t, result)
+}
 
-	opts := &SearchOptions{
-		Page:    0, // Should default to 1
-		PerPage: 0, // Should default to 20
-	}
+// TestService_SearchHotels_InvalidGuests tests error when guests are out of range
+func TestService_SearchHotels_InvalidGuests(t *testing.T) {
+	for _, guests := range []int{0, 11} {
+		t.Run(fmt.Sprintf("Guests %d", guests), func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := newTestService(mockRepo, testGateway())
 
-	expectedResult := &SearchResult{
-		Hotels:     []Hotel{},
-		Total:      0,
-		Page:       1,
-		PerPage:    20,
-		TotalPages: 1,
-	}
+			req := testRequest()
+			req.Guests = guests
 
-	// Setup expectations - should receive defaults
-	mockRepo.On("SearchHotels", ctx, mock.AnythingOfType("*search.SearchRequest"), mock.MatchedBy(func(o *SearchOptions) bool {
-		return o.Page == 1 && o.PerPage == 20
-	})).Return(expectedResult, nil)
+			result, err := service.SearchHotels(context.Background(), req, &SearchOptions{})
 
-	// Execute
-	result, err := service.SearchHotels(ctx, req, opts)
+			require.ErrorIs(t, err, ErrInvalidGuests)
+			assert.Nil(t, result)
+			assert.Contains(t, err.Error(), "number of guests must be between 1 and 10")
+		})
+	}
+}
 
-	// Assertions
-	require.NoError(t, err)
-	require.NotNil(t, result)
-	assert.Equal(t, 1, result.Page)
-	assert.Equal(t, 20, result.PerPage)
+// TestService_SearchHotels_Pagination tests paging through available hotels
+func TestService_SearchHotels_Pagination(t *testing.T) {
+	var candidates []Candidate
+	rates := make(map[string][]types.Rate)
+	for i := 1; i <= 25; i++ {
+		c := candidate(fmt.Sprintf("hotel-%02d", i), fmt.Sprintf("hotel-%02d", i), fmt.Sprintf("Hotel %02d", i), "hotelbeds", 4)
+		candidates = append(candidates, c)
+		rates["hotelbeds/"+c.ProviderHotelID] = netRates(100000 * i)
+	}
 
-	mockRepo.AssertExpectations(t)
-}
+	testCases := []struct {
+		page     int
+		expected int
+	}{
+		{page: 1, expected: 10},
+		{page: 3, expected: 5},
+		{page: 4, expected: 0},
+	}
 
-// TestService_SearchHotels_WithPriceFilters tests search with price filters
-func TestService_SearchHotels_WithPriceFilters(t *testing.T) {
-	mockRepo := new(MockRepository)
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Page %d", tc.page), func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := newTestService(mockRepo, &stubGateway{rates: rates})
 
-	service := NewService(mockRepo)
+			ctx := context.Background()
+			req := testRequest()
+			opts := &SearchOptions{Page: tc.page, PerPage: 10}
 
-	ctx := context.Background()
-	checkIn := time.Now().Add(24 * time.Hour)
-	checkOut := time.Now().Add(48 * time.Hour)
+			mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return(candidates, nil)
 
-	minPrice := 500000
-	maxPrice := 2000000
+			result, err := service.SearchHotels(ctx, req, opts)
 
-	req := &SearchRequest{
-		CheckIn:  checkIn,
-		CheckOut: checkOut,
-		City:     "Bali",
-		Guests:   2,
-		MinPrice: &minPrice,
-		MaxPrice: &maxPrice,
+			require.NoError(t, err)
+			assert.Len(t, result.Hotels, tc.expected)
+			assert.Equal(t, 25, result.Total)
+			assert.Equal(t, 3, result.TotalPages)
+			assert.Equal(t, tc.page, result.Page)
+		})
 	}
+}
 
-	opts := &SearchOptions{
-		Page:    1,
-		PerPage: 10,
-	}
+// TestService_SearchHotels_DefaultPagination tests default pagination values
+func TestService_SearchHotels_DefaultPagination(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, testGateway())
 
-	expectedResult := &SearchResult{
-		Hotels: []Hotel{
-			{
-				ID:      "hotel-1",
-				Name:    "Bali Resort",
-				Country: "Indonesia",
-				City:    "Bali",
-			},
-		},
-		Total:      1,
-		Page:       1,
-		PerPage:    10,
-		TotalPages: 1,
-	}
+	ctx := context.Background()
+	req := testRequest()
+	opts := &SearchOptions{}
 
-	// Setup expectations
-	mockRepo.On("SearchHotels", ctx, mock.MatchedBy(func(r *SearchRequest) bool {
-		return r.MinPrice != nil && *r.MinPrice == 500000 &&
-			   r.MaxPrice != nil && *r.MaxPrice == 2000000
-	}), mock.AnythingOfType("*search.SearchOptions")).Return(expectedResult, nil)
+	mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return(testCandidates(), nil)
 
-	// Execute
 	result, err := service.SearchHotels(ctx, req, opts)
 
-	// Assertions
 	require.NoError(t, err)
-	require.NotNil(t, result)
-	assert.Len(t, result.Hotels, 1)
-
-	mockRepo.AssertExpectations(t)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, 20, result.PerPage)
 }
 
 // TestService_SearchHotels_RepositoryError tests error when repository fails
 func TestService_SearchHotels_RepositoryError(t *testing.T) {
 	mockRepo := new(MockRepository)
-
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, testGateway())
 
 	ctx := context.Background()
-	checkIn := time.Now().Add(24 * time.Hour)
-	checkOut := time.Now().Add(48 * time.Hour)
+	req := testRequest()
 
-	req := &SearchRequest{
-		CheckIn:  checkIn,
-		CheckOut: checkOut,
-		City:     "Bali",
-		Guests:   2,
-	}
+	mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return(nil, errors.New("database connection error"))
 
-	opts := &SearchOptions{
-		Page:    1,
-		PerPage: 10,
-	}
+	result, err := service.SearchHotels(ctx, req, &SearchOptions{Page: 1, PerPage: 10})
 
-	// Setup expectations
-	mockRepo.On("SearchHotels", ctx, mock.AnythingOfType("*search.SearchRequest"), mock.AnythingOfType("*search.SearchOptions")).Return(nil, errors.New("database connection error"))
-
-	// Execute
-	result, err := service.SearchHotels(ctx, req, opts)
-
-	// Assertions
 	require.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "failed to search hotels")
@@ -356,211 +428,246 @@ func TestService_SearchHotels_RepositoryError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-// TestService_SearchHotels_EmptyResults tests search with no results
+// TestService_SearchHotels_EmptyResults tests search with no candidates
 func TestService_SearchHotels_EmptyResults(t *testing.T) {
 	mockRepo := new(MockRepository)
-
-	service := NewService(mockRepo)
+	service := newTestService(mockRepo, &stubGateway{err: errors.New("must not be called")})
 
 	ctx := context.Background()
-	checkIn := time.Now().Add(24 * time.Hour)
-	checkOut := time.Now().Add(48 * time.Hour)
+	req := testRequest()
+	req.City = "UnknownCity"
 
-	req := &SearchRequest{
-		CheckIn:  checkIn,
-		CheckOut: checkOut,
-		City:     "UnknownCity",
-		Guests:   2,
-	}
+	mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return([]Candidate{}, nil)
 
-	opts := &SearchOptions{
-		Page:    1,
-		PerPage: 10,
-	}
-
-	expectedResult := &SearchResult{
-		Hotels:     []Hotel{},
-		Total:      0,
-		Page:       1,
-		PerPage:    10,
-		TotalPages: 1,
-	}
-
-	// Setup expectations
-	mockRepo.On("SearchHotels", ctx, mock.AnythingOfType("*search.SearchRequest"), mock.AnythingOfType("*search.SearchOptions")).Return(expectedResult, nil)
-
-	// Execute
-	result, err := service.SearchHotels(ctx, req, opts)
+	result, err := service.SearchHotels(ctx, req, &SearchOptions{Page: 1, PerPage: 10})
 
-	// Assertions
 	require.NoError(t, err)
-	require.NotNil(t, result)
 	assert.Empty(t, result.Hotels)
 	assert.Equal(t, 0, result.Total)
 	assert.Equal(t, 1, result.TotalPages)
-
-	mockRepo.AssertExpectations(t)
 }
 
-// TestService_SearchHotels_DifferentSortOptions tests different sort options
-func TestService_SearchHotels_DifferentSortOptions(t *testing.T) {
+// TestService_SearchHotels_ProvidersUnavailable tests that a search fails
+// rather than returning nothing when no availability check succeeds
+func TestService_SearchHotels_ProvidersUnavailable(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, &stubGateway{err: provider.ErrBreakerOpen})
+
 	ctx := context.Background()
-	checkIn := time.Now().Add(24 * time.Hour)
-	checkOut := time.Now().Add(48 * time.Hour)
+	req := testRequest()
 
-	testCases := []struct {
-		name      string
-		sortBy    SortBy
-	}{
-		{name: "Sort by price", sortBy: SortByPrice},
-		{name: "Sort by rating", sortBy: SortByRating},
-		{name: "Sort by name", sortBy: SortByName},
-		{name: "No sort specified", sortBy: ""},
-	}
+	mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return(testCandidates(), nil)
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			mockRepo := new(MockRepository)
-			service := NewService(mockRepo)
+	result, err := service.SearchHotels(ctx, req, &SearchOptions{Page: 1, PerPage: 10})
 
-			req := &SearchRequest{
-				CheckIn:  checkIn,
-				CheckOut: checkOut,
-				City:     "Bali",
-				Guests:   2,
-			}
+	require.ErrorIs(t, err, ErrProvidersUnavailable)
+	assert.Nil(t, result)
+}
 
-			opts := &SearchOptions{
-				Page:    1,
-				PerPage: 10,
-				SortBy:  tc.sortBy,
-			}
+// TestService_SearchHotels_BatchesChecks tests that a provider's hotels are
+// checked together, in batches of HotelsPerCheck
+func TestService_SearchHotels_BatchesChecks(t *testing.T) {
+	mockRepo := new(MockRepository)
+	gateway := testGateway()
+	config := DefaultConfig()
+	config.HotelsPerCheck = 2
+	service := NewService(mockRepo, gateway, pricing.NewService(), config)
 
-			expectedResult := &SearchResult{
-				Hotels:     []Hotel{},
-				Total:      0,
-				Page:       1,
-				PerPage:    10,
-				TotalPages: 1,
-			}
+	ctx := context.Background()
+	req := testRequest()
 
-			// Setup expectations
-			mockRepo.On("SearchHotels", ctx, mock.AnythingOfType("*search.SearchRequest"), mock.MatchedBy(func(o *SearchOptions) bool {
-				return o.SortBy == tc.sortBy
-			})).Return(expectedResult, nil)
+	mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return(testCandidates(), nil)
 
-			// Execute
-			result, err := service.SearchHotels(ctx, req, opts)
+	result, err := service.SearchHotels(ctx, req, &SearchOptions{Page: 1, PerPage: 10})
 
-			// Assertions
-			require.NoError(t, err)
-			require.NotNil(t, result)
+	require.NoError(t, err)
+	assert.Len(t, result.Hotels, 2)
+	assert.False(t, result.Partial)
 
-			mockRepo.AssertExpectations(t)
-		})
+	var checked [][]string
+	for _, check := range gateway.checks {
+		checked = append(checked, check.HotelIDs)
 	}
+	assert.ElementsMatch(t, [][]string{
+		{"P-hotel-1", "P-hotel-2"},
+		{"P-hotel-3"},
+		{"P-hotel-1b"},
+	}, checked)
 }
 
-// TestService_SearchHotels_MultiplePages tests pagination across multiple pages
-func TestService_SearchHotels_MultiplePages(t *testing.T) {
+// TestService_SearchHotels_CapsCandidates tests that a search asks for at
+// most MaxCandidates hotels, so a large city costs a bounded number of checks
+func TestService_SearchHotels_CapsCandidates(t *testing.T) {
+	mockRepo := new(MockRepository)
+	config := DefaultConfig()
+	config.MaxCandidates = 2
+	service := NewService(mockRepo, testGateway(), pricing.NewService(), config)
+
 	ctx := context.Background()
-	checkIn := time.Now().Add(24 * time.Hour)
-	checkOut := time.Now().Add(48 * time.Hour)
+	req := testRequest()
 
-	pages := []int{1, 2, 3}
+	mockRepo.On("SearchCandidates", ctx, req, 2).Return(testCandidates()[:3], nil)
 
-	for _, page := range pages {
-		t.Run("Page "+string(rune(page+'0')), func(t *testing.T) {
-			mockRepo := new(MockRepository)
-			service := NewService(mockRepo)
+	result, err := service.SearchHotels(ctx, req, &SearchOptions{Page: 1, PerPage: 10})
 
-			req := &SearchRequest{
-				CheckIn:  checkIn,
-				CheckOut: checkOut,
-				City:     "Bali",
-				Guests:   2,
-			}
+	require.NoError(t, err)
+	assert.Len(t, result.Hotels, 2)
+	mockRepo.AssertExpectations(t)
+}
 
-			opts := &SearchOptions{
-				Page:    page,
-				PerPage: 10,
-			}
+// TestService_SearchHotels_PartialResults tests that hotels of a provider
+// whose check fails are counted as unchecked rather than left out silently,
+// while a hotel another provider sells is still priced
+func TestService_SearchHotels_PartialResults(t *testing.T) {
+	mockRepo := new(MockRepository)
+	gateway := testGateway()
+	gateway.failing = map[string]error{"hotelbeds": context.DeadlineExceeded}
+	service := newTestService(mockRepo, gateway)
 
-			expectedResult := &SearchResult{
-				Hotels:     []Hotel{},
-				Total:      25,
-				Page:       page,
-				PerPage:    10,
-				TotalPages: 3,
-			}
+	ctx := context.Background()
+	req := testRequest()
 
-			// Setup expectations
-			mockRepo.On("SearchHotels", ctx, mock.AnythingOfType("*search.SearchRequest"), mock.MatchedBy(func(o *SearchOptions) bool {
-				return o.Page == page && o.PerPage == 10
-			})).Return(expectedResult, nil)
+	mockRepo.On("SearchCandidates", ctx, req, mock.Anything).Return(testCandidates(), nil)
 
-			// Execute
-			result, err := service.SearchHotels(ctx, req, opts)
+	result, err := service.SearchHotels(ctx, req, &SearchOptions{Page: 1, PerPage: 10})
 
-			// Assertions
-			require.NoError(t, err)
-			require.NotNil(t, result)
-			assert.Equal(t, page, result.Page)
-			assert.Equal(t, 3, result.TotalPages)
+	require.NoError(t, err)
+	require.Len(t, result.Hotels, 1)
+	assert.Equal(t, "hotel-1", result.Hotels[0].ID)
+	assert.Equal(t, 1180000, result.Hotels[0].FromPrice)
+	assert.True(t, result.Partial)
+	assert.Equal(t, 2, result.Unchecked)
+}
 
-			mockRepo.AssertExpectations(t)
-		})
-	}
+// TestService_SearchHotels_Landmark tests searching around a landmark with
+// the default radius
+func TestService_SearchHotels_Landmark(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, testGateway())
+
+	ctx := context.Background()
+	req := testRequest()
+	req.City = ""
+	req.Landmark = "KUTA-BEACH"
+
+	mockRepo.On("GetLandmark", ctx, "KUTA-BEACH").Return(&Landmark{
+		Code: "KUTA-BEACH", Name: "Kuta Beach", Type: "LANDMARK", Latitude: -8.7184, Longitude: 115.1686,
+	}, nil)
+	mockRepo.On("SearchCandidates", ctx, mock.MatchedBy(func(r *SearchRequest) bool {
+		return r.Latitude != nil && *r.Latitude == -8.7184 &&
+			r.Longitude != nil && *r.Longitude == 115.1686 &&
+			r.RadiusKm == DefaultRadiusKm
+	}), mock.Anything).Return(testCandidates(), nil)
+
+	result, err := service.SearchHotels(ctx, req, &SearchOptions{Page: 1, PerPage: 10})
+
+	require.NoError(t, err)
+	assert.Len(t, result.Hotels, 2)
+	assert.Nil(t, req.Latitude, "the caller's request is left as is")
+
+	mockRepo.AssertExpectations(t)
 }
 
-// TestService_SearchHotels_ValidGuestRange tests all valid guest counts
-func TestService_SearchHotels_ValidGuestRange(t *testing.T) {
+// TestService_SearchHotels_LandmarkNotFound tests searching around an unknown landmark
+func TestService_SearchHotels_LandmarkNotFound(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, testGateway())
+
 	ctx := context.Background()
-	checkIn := time.Now().Add(24 * time.Hour)
-	checkOut := time.Now().Add(48 * time.Hour)
+	req := testRequest()
+	req.Landmark = "NOWHERE"
 
-	for guests := 1; guests <= 10; guests++ {
-		t.Run("Guests "+string(rune(guests+'0')), func(t *testing.T) {
-			mockRepo := new(MockRepository)
-			service := NewService(mockRepo)
+	mockRepo.On("GetLandmark", ctx, "NOWHERE").Return(nil, ErrLandmarkNotFound)
 
-			req := &SearchRequest{
-				CheckIn:  checkIn,
-				CheckOut: checkOut,
-				City:     "Bali",
-				Guests:   guests,
-			}
+	result, err := service.SearchHotels(ctx, req, &SearchOptions{Page: 1, PerPage: 10})
 
-			opts := &SearchOptions{
-				Page:    1,
-				PerPage: 10,
-			}
+	require.ErrorIs(t, err, ErrLandmarkNotFound)
+	assert.Nil(t, result)
+}
 
-			expectedResult := &SearchResult{
-				Hotels:     []Hotel{},
-				Total:      0,
-				Page:       1,
-				PerPage:    10,
-				TotalPages: 1,
-			}
+// TestService_SearchHotels_InvalidLocation tests location validation
+func TestService_SearchHotels_InvalidLocation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		modify   func(r *SearchRequest)
+		expected error
+	}{
+		{name: "No location", modify: func(r *SearchRequest) { r.City = "" }, expected: ErrMissingLocation},
+		{name: "Latitude only", modify: func(r *SearchRequest) { r.Latitude = pointerToFloat64(-8.7) }, expected: ErrInvalidLocation},
+		{name: "Point out of range", modify: func(r *SearchRequest) {
+			r.Latitude, r.Longitude = pointerToFloat64(-91), pointerToFloat64(115)
+		}, expected: ErrInvalidLocation},
+		{name: "Point and landmark", modify: func(r *SearchRequest) {
+			r.Latitude, r.Longitude = pointerToFloat64(-8.7), pointerToFloat64(115.2)
+			r.Landmark = "KUTA-BEACH"
+		}, expected: ErrInvalidLocation},
+		{name: "Radius too large", modify: func(r *SearchRequest) { r.RadiusKm = MaxRadiusKm + 1 }, expected: ErrInvalidLocation},
+		{name: "Bounds upside down", modify: func(r *SearchRequest) {
+			r.Bounds = &Bounds{North: -9, South: -8, East: 116, West: 115}
+		}, expected: ErrInvalidLocation},
+	}
 
-			// Setup expectations
-			mockRepo.On("SearchHotels", ctx, mock.MatchedBy(func(r *SearchRequest) bool {
-				return r.Guests == guests
-			}), mock.AnythingOfType("*search.SearchOptions")).Return(expectedResult, nil)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := newTestService(mockRepo, testGateway())
 
-			// Execute
-			result, err := service.SearchHotels(ctx, req, opts)
+			req := testRequest()
+			tc.modify(req)
 
-			// Assertions
-			require.NoError(t, err)
-			require.NotNil(t, result)
+			result, err := service.SearchHotels(context.Background(), req, &SearchOptions{})
 
-			mockRepo.AssertExpectations(t)
+			require.ErrorIs(t, err, tc.expected)
+			assert.Nil(t, result)
+			mockRepo.AssertNotCalled(t, "SearchCandidates", mock.Anything, mock.Anything, mock.Anything)
 		})
 	}
 }
 
+// TestService_SearchHotels_Bounds tests that a viewport with a point only
+// measures distances from the point, and sorting by distance
+func TestService_SearchHotels_Bounds(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo, testGateway())
+
+	ctx := context.Background()
+	req := testRequest()
+	req.City = ""
+	req.Bounds = &Bounds{North: -8.5, South: -8.9, East: 115.4, West: 115.0}
+	req.Latitude, req.Longitude = pointerToFloat64(-8.7), pointerToFloat64(115.2)
+
+	candidates := testCandidates()
+	candidates[0].DistanceKm = pointerToFloat64(3.2)
+	candidates[1].DistanceKm = pointerToFloat64(3.4)
+	candidates[2].DistanceKm = pointerToFloat64(1.5)
+	mockRepo.On("SearchCandidates", ctx, mock.MatchedBy(func(r *SearchRequest) bool {
+		return r.Bounds != nil && r.RadiusKm == 0
+	}), mock.Anything).Return(candidates, nil)
+
+	result, err := service.SearchHotels(ctx, req, &SearchOptions{Page: 1, PerPage: 10, SortBy: SortByDistance})
+
+	require.NoError(t, err)
+	require.Len(t, result.Hotels, 2)
+	assert.Equal(t, "hotel-2", result.Hotels[0].ID)
+	assert.Equal(t, 1.5, *result.Hotels[0].DistanceKm)
+	assert.Equal(t, "hotel-1", result.Hotels[1].ID)
+	assert.Equal(t, 3.2, *result.Hotels[1].DistanceKm)
+}
+
+// TestBounds_Center tests viewport centers, also across the antimeridian
+func TestBounds_Center(t *testing.T) {
+	lat, lng := (&Bounds{North: -8, South: -9, East: 116, West: 115}).Center()
+	assert.InDelta(t, -8.5, lat, 1e-9)
+	assert.InDelta(t, 115.5, lng, 1e-9)
+
+	lat, lng = (&Bounds{North: 10, South: -10, East: -170, West: 170}).Center()
+	assert.InDelta(t, 0, lat, 1e-9)
+	assert.InDelta(t, 180, lng, 1e-9)
+
+	_, lng = (&Bounds{North: 10, South: -10, East: -160, West: 170}).Center()
+	assert.InDelta(t, -175, lng, 1e-9)
+}
+
 // TestSortBy_Constants tests sort by constants
 func TestSortBy_Constants(t *testing.T) {
 	assert.Equal(t, SortBy("price"), SortByPrice)
@@ -605,54 +712,28 @@ func TestSearchOptions_Structure(t *testing.T) {
 	assert.Equal(t, SortByRating, opts.SortBy)
 }
 
-// TestSearchResult_Structure tests search result structure
-func TestSearchResult_Structure(t *testing.T) {
-	result := &SearchResult{
-		Hotels: []Hotel{
-			{
-				ID:      "hotel-1",
-				Name:    "Test Hotel",
-				Country: "Indonesia",
-				City:    "Bali",
-			},
-		},
-		Total:      1,
-		Page:       1,
-		PerPage:    10,
-		TotalPages: 1,
-	}
-
-	assert.Len(t, result.Hotels, 1)
-	assert.Equal(t, 1, result.Total)
-	assert.Equal(t, 1, result.Page)
-	assert.Equal(t, 10, result.PerPage)
-	assert.Equal(t, 1, result.TotalPages)
-}
-
 // TestHotel_Structure tests hotel structure
 func TestHotel_Structure(t *testing.T) {
 	rating := 4.5
-	minPrice := 500000
-	maxPrice := 2000000
 
 	hotel := &Hotel{
 		ID:          "hotel-1",
 		Name:        "Grand Hotel",
-		Country:     "Indonesia",
+		CountryCode: "ID",
 		City:        "Bali",
 		Rating:      &rating,
-		MinPrice:    &minPrice,
-		MaxPrice:    &maxPrice,
+		FromPrice:   500000,
+		Currency:    "IDR",
 		Description: "Luxury hotel in Bali",
 	}
 
 	assert.Equal(t, "hotel-1", hotel.ID)
 	assert.Equal(t, "Grand Hotel", hotel.Name)
-	assert.Equal(t, "Indonesia", hotel.Country)
+	assert.Equal(t, "ID", hotel.CountryCode)
 	assert.Equal(t, "Bali", hotel.City)
 	assert.Equal(t, 4.5, *hotel.Rating)
-	assert.Equal(t, 500000, *hotel.MinPrice)
-	assert.Equal(t, 2000000, *hotel.MaxPrice)
+	assert.Equal(t, 500000, hotel.FromPrice)
+	assert.Equal(t, "IDR", hotel.Currency)
 	assert.Equal(t, "Luxury hotel in Bali", hotel.Description)
 }
 
@@ -660,3 +741,7 @@ func TestHotel_Structure(t *testing.T) {
 func pointerToFloat64(f float64) *float64 {
 	return &f
 }
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,202 @@
+package settlement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// invoiceColumns lists accepted header names per field. Supplier exports are not
+// consistent between accounts, so a few common spellings are accepted.
+var invoiceColumns = map[string][]string{
+	"invoice_number":     {"invoice_number", "invoice", "invoice_no"},
+	"supplier_reference": {"supplier_reference", "booking_reference", "reference", "reference_number"},
+	"amount":             {"amount", "net_amount", "total", "total_net"},
+	"currency":           {"currency"},
+}
+
+// ParseInvoiceCSV reads a supplier invoice CSV. The header row must contain a
+// reference and an amount column; invoice number and currency are optional
+// (currency defaults to IDR).
+func ParseInvoiceCSV(r io.Reader) ([]*InvoiceLine, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrEmptyInvoiceFile
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInvoiceFile, err)
+	}
+
+	index := mapInvoiceColumns(header)
+	if _, ok := index["supplier_reference"]; !ok {
+		return nil, fmt.Errorf("%w: missing reference column", ErrInvalidInvoiceFile)
+	}
+	if _, ok := index["amount"]; !ok {
+		return nil, fmt.Errorf("%w: missing amount column", ErrInvalidInvoiceFile)
+	}
+
+	var lines []*InvoiceLine
+	row := 1
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		row++
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidInvoiceFile, row, err)
+		}
+
+		reference := field(record, index, "supplier_reference")
+		if reference == "" {
+			// Blank and subtotal rows
+			continue
+		}
+
+		amount, err := parseAmount(field(record, index, "amount"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: invalid amount", ErrInvalidInvoiceFile, row)
+		}
+
+		currency := strings.ToUpper(field(record, index, "currency"))
+		if currency == "" {
+			currency = "IDR"
+		}
+
+		lines = append(lines, &InvoiceLine{
+			InvoiceNumber:     field(record, index, "invoice_number"),
+			SupplierReference: reference,
+			Amount:            amount,
+			Currency:          currency,
+		})
+	}
+
+	if len(lines) == 0 {
+		return nil, ErrEmptyInvoiceFile
+	}
+
+	return lines, nil
+}
+
+// mapInvoiceColumns maps field names to column positions in the header
+func mapInvoiceColumns(header []string) map[string]int {
+	index := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		name = strings.ReplaceAll(name, " ", "_")
+		for fieldName, aliases := range invoiceColumns {
+			if _, seen := index[fieldName]; seen {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					index[fieldName] = i
+				}
+			}
+		}
+	}
+	return index
+}
+
+func field(record []string, index map[string]int, name string) string {
+	i, ok := index[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parseAmount parses amounts like "1250000", "1,250,000", "1.250.000",
+// "1250000.00" or "1.250.000,00". Of two separators the last is the decimal
+// one; a lone separator is a thousands separator when three digits follow it.
+func parseAmount(s string) (int, error) {
+	if s == "" {
+		return 0, errors.New("empty amount")
+	}
+
+	decimal := byte(0)
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimal = s[max(lastDot, lastComma)]
+	case lastDot >= 0 && strings.Count(s, ".") == 1 && len(s)-lastDot-1 != 3:
+		decimal = '.'
+	case lastComma >= 0 && strings.Count(s, ",") == 1 && len(s)-lastComma-1 != 3:
+		decimal = ','
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == decimal:
+			b.WriteByte('.')
+		case c == '.' || c == ',':
+			// Thousands separator
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	value, err := strconv.ParseFloat(b.String(), 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(value)), nil
+}
+
+// WriteReportCSV writes a settlement report as CSV, one row per payable
+func WriteReportCSV(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{
+		"booking_reference", "supplier_reference", "provider_code", "check_in", "check_out",
+		"due_date", "net_amount", "cancellation_penalty", "currency", "status", "invoice_number", "invoiced_amount", "settled_at",
+	}); err != nil {
+		return fmt.Errorf("failed to write report header: %w", err)
+	}
+
+	for _, p := range report.Payables {
+		invoiced := ""
+		if p.InvoicedAmount != nil {
+			invoiced = strconv.Itoa(*p.InvoicedAmount)
+		}
+		penalty := ""
+		if p.Penalty != nil {
+			penalty = strconv.Itoa(*p.Penalty)
+		}
+		settledAt := ""
+		if p.SettledAt != nil {
+			settledAt = p.SettledAt.Format(time.RFC3339)
+		}
+
+		if err := writer.Write([]string{
+			p.BookingReference,
+			p.SupplierReference,
+			p.ProviderCode,
+			p.CheckIn.Format("2006-01-02"),
+			p.CheckOut.Format("2006-01-02"),
+			p.DueDate.Format("2006-01-02"),
+			strconv.Itoa(p.NetAmount),
+			penalty,
+			p.Currency,
+			string(p.Status),
+			p.InvoiceNumber,
+			invoiced,
+			settledAt,
+		}); err != nil {
+			return fmt.Errorf("failed to write report row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,14 @@
+package settlement
+
+import "errors"
+
+// Package-level errors for settlement operations
+var (
+	ErrPayableNotFound    = errors.New("payable not found")
+	ErrPayableExists      = errors.New("payable already exists for booking")
+	ErrAlreadySettled     = errors.New("payable already settled")
+	ErrInvalidPayable     = errors.New("invalid payable")
+	ErrInvalidPeriod      = errors.New("invalid settlement period")
+	ErrInvalidInvoiceFile = errors.New("invalid supplier invoice file")
+	ErrEmptyInvoiceFile   = errors.New("supplier invoice file has no lines")
+)
@@ -0,0 +1,65 @@
+package settlement
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/booking"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// NewBookingConfirmedHandler records a supplier payable for booking.confirmed
+// events. The payable is the net_amount the supplier confirmed, not the sell
+// price in total_amount. Pay-at-hotel guests pay the hotel, so those
+// bookings owe the supplier nothing.
+func NewBookingConfirmedHandler(svc Service) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		if paymentType, _ := event.Payload["payment_type"].(string); paymentType == string(booking.PaymentTypePayAtHotel) {
+			return nil
+		}
+
+		bookingID, _ := event.Payload["booking_id"].(string)
+		bookingRef, _ := event.Payload["booking_reference"].(string)
+		supplierRef, _ := event.Payload["supplier_reference"].(string)
+		providerCode, _ := event.Payload["provider_code"].(string)
+		netAmount, _ := event.Payload["net_amount"].(int)
+		currency, _ := event.Payload["currency"].(string)
+		checkIn, _ := event.Payload["check_in"].(time.Time)
+		checkOut, _ := event.Payload["check_out"].(time.Time)
+
+		if _, err := svc.RecordPayable(ctx, &ConfirmedBooking{
+			BookingID:         bookingID,
+			BookingReference:  bookingRef,
+			SupplierReference: supplierRef,
+			ProviderCode:      providerCode,
+			NetAmount:         netAmount,
+			Currency:          currency,
+			CheckIn:           checkIn,
+			CheckOut:          checkOut,
+		}); err != nil {
+			// Don't fail the confirmation - reconciliation will flag the missing payable
+			logger.ErrorWithErr(err, "Failed to record supplier payable")
+		}
+		return nil
+	}
+}
+
+// NewBookingCancelledHandler reduces the supplier payable of cancelled bookings
+func NewBookingCancelledHandler(svc Service) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		// Bookings never confirmed with the supplier have no payable
+		if supplierRef, _ := event.Payload["supplier_reference"].(string); supplierRef == "" {
+			return nil
+		}
+
+		bookingID, _ := event.Payload["booking_id"].(string)
+		penalty, _ := event.Payload["cancellation_penalty"].(int)
+
+		if err := svc.CancelPayable(ctx, bookingID, penalty); err != nil && !errors.Is(err, ErrPayableNotFound) {
+			logger.ErrorWithErr(err, "Failed to cancel supplier payable")
+		}
+		return nil
+	}
+}
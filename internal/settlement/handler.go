@@ -0,0 +1,211 @@
+package settlement
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// maxInvoiceSize limits uploaded supplier invoice files
+const maxInvoiceSize = 10 << 20
+
+// Handler handles admin HTTP requests for supplier settlement
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new settlement handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// ListPayables handles GET /admin/settlements/payables
+func (h *Handler) ListPayables(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 || l > 100 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+		limit = l
+	}
+
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		o, err := strconv.Atoi(offsetStr)
+		if err != nil || o < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset parameter")
+			return
+		}
+		offset = o
+	}
+
+	filter := ListFilter{
+		ProviderCode: query.Get("provider"),
+		Status:       PayableStatus(strings.ToUpper(query.Get("status"))),
+	}
+	if startDateStr := query.Get("start_date"); startDateStr != "" {
+		if t, err := time.Parse("2006-01-02", startDateStr); err == nil {
+			filter.From = t
+		}
+	}
+	if endDateStr := query.Get("end_date"); endDateStr != "" {
+		if t, err := time.Parse("2006-01-02", endDateStr); err == nil {
+			filter.To = t.AddDate(0, 0, 1)
+		}
+	}
+
+	payables, err := h.service.ListPayables(r.Context(), filter, limit, offset)
+	if err != nil {
+		logger.ErrorWithErr(err, "Failed to list payables")
+		respondWithError(w, http.StatusInternalServerError, "Failed to list payables")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"payables": payables,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// SettlePayable handles POST /admin/settlements/payables/{id}/settle
+func (h *Handler) SettlePayable(w http.ResponseWriter, r *http.Request) {
+	payable, err := h.service.MarkSettled(r.Context(), r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPayableNotFound):
+			respondWithError(w, http.StatusNotFound, "Payable not found")
+		case errors.Is(err, ErrAlreadySettled), errors.Is(err, ErrInvalidPayable):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			logger.ErrorWithErr(err, "Failed to settle payable")
+			respondWithError(w, http.StatusInternalServerError, "Failed to settle payable")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, payable)
+}
+
+// GetReport handles GET /admin/settlements/reports/{year}/{month}?provider=hotelbeds&format=csv
+func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request) {
+	year, month, ok := parsePeriod(w, r.PathValue("year"), r.PathValue("month"))
+	if !ok {
+		return
+	}
+	provider := providerParam(r)
+
+	if r.URL.Query().Get("format") == "csv" {
+		report, err := h.service.GetMonthlyReport(r.Context(), provider, year, month)
+		if err != nil {
+			h.handleReportError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition",
+			fmt.Sprintf("attachment; filename=settlement-%s-%04d-%02d.csv", provider, year, month))
+		if err := WriteReportCSV(w, report); err != nil {
+			logger.ErrorWithErr(err, "Failed to write settlement report CSV")
+		}
+		return
+	}
+
+	report, err := h.service.GetMonthlyReport(r.Context(), provider, year, month)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+// Reconcile handles POST /admin/settlements/reconcile?provider=hotelbeds&year=2026&month=1
+// The supplier invoice CSV is sent as the request body or as a multipart "file" field.
+func (h *Handler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	year, month, ok := parsePeriod(w, r.URL.Query().Get("year"), r.URL.Query().Get("month"))
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxInvoiceSize)
+
+	var invoice io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invoice file is required")
+			return
+		}
+		defer file.Close()
+		invoice = file
+	}
+
+	result, err := h.service.Reconcile(r.Context(), providerParam(r), year, month, invoice)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidInvoiceFile), errors.Is(err, ErrEmptyInvoiceFile), errors.Is(err, ErrInvalidPeriod):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			logger.ErrorWithErr(err, "Failed to reconcile supplier invoice")
+			respondWithError(w, http.StatusInternalServerError, "Failed to reconcile supplier invoice")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) handleReportError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrInvalidPeriod) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	logger.ErrorWithErr(err, "Failed to get settlement report")
+	respondWithError(w, http.StatusInternalServerError, "Failed to get settlement report")
+}
+
+// providerParam returns the provider query parameter, defaulting to HotelBeds
+func providerParam(r *http.Request) string {
+	if provider := r.URL.Query().Get("provider"); provider != "" {
+		return provider
+	}
+	return "hotelbeds"
+}
+
+func parsePeriod(w http.ResponseWriter, yearStr, monthStr string) (int, int, bool) {
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid year")
+		return 0, 0, false
+	}
+	month, err := strconv.Atoi(monthStr)
+	if err != nil || month < 1 || month > 12 {
+		respondWithError(w, http.StatusBadRequest, "Invalid month")
+		return 0, 0, false
+	}
+	return year, month, true
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
+
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	respondWithJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,146 @@
+package settlement
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PayableStatus represents the state of an amount owed to a supplier
+type PayableStatus string
+
+const (
+	// PayableStatusPending means the booking is confirmed and the supplier is not paid yet
+	PayableStatusPending PayableStatus = "PENDING"
+	// PayableStatusDisputed means the supplier invoice does not match our records
+	PayableStatusDisputed PayableStatus = "DISPUTED"
+	// PayableStatusSettled means the supplier has been paid
+	PayableStatusSettled PayableStatus = "SETTLED"
+	// PayableStatusCancelled means the booking was cancelled and nothing is owed
+	PayableStatusCancelled PayableStatus = "CANCELLED"
+)
+
+// Payable is the net amount owed to a supplier for one confirmed booking
+type Payable struct {
+	ID                string        `json:"id" db:"id"`
+	BookingID         string        `json:"booking_id" db:"booking_id"`
+	BookingReference  string        `json:"booking_reference" db:"booking_reference"`
+	SupplierReference string        `json:"supplier_reference" db:"supplier_reference"`
+	ProviderCode      string        `json:"provider_code" db:"provider_code"`
+	NetAmount         int           `json:"net_amount" db:"net_amount"`
+	Penalty           *int          `json:"cancellation_penalty,omitempty" db:"cancellation_penalty"` // Set once the booking is cancelled
+	Currency          string        `json:"currency" db:"currency"`
+	CheckIn           time.Time     `json:"check_in" db:"check_in"`
+	CheckOut          time.Time     `json:"check_out" db:"check_out"`
+	DueDate           time.Time     `json:"due_date" db:"due_date"`
+	Status            PayableStatus `json:"status" db:"status"`
+	InvoiceNumber     string        `json:"invoice_number,omitempty" db:"invoice_number"`
+	InvoicedAmount    *int          `json:"invoiced_amount,omitempty" db:"invoiced_amount"`
+	SettledAt         *time.Time    `json:"settled_at,omitempty" db:"settled_at"`
+	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// NewPayable creates a pending payable due according to the supplier terms
+func NewPayable(bookingID, bookingReference, supplierReference, providerCode string, netAmount int, currency string, checkIn, checkOut time.Time, terms SupplierTerms) *Payable {
+	now := time.Now()
+	return &Payable{
+		ID:                uuid.New().String(),
+		BookingID:         bookingID,
+		BookingReference:  bookingReference,
+		SupplierReference: supplierReference,
+		ProviderCode:      providerCode,
+		NetAmount:         netAmount,
+		Currency:          currency,
+		CheckIn:           checkIn,
+		CheckOut:          checkOut,
+		DueDate:           terms.DueDate(checkOut),
+		Status:            PayableStatusPending,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+}
+
+// AmountDue returns what the supplier is owed: the cancellation penalty once
+// the booking is cancelled, the net amount otherwise
+func (p *Payable) AmountDue() int {
+	if p.Penalty != nil {
+		return *p.Penalty
+	}
+	return p.NetAmount
+}
+
+// SupplierTerms are the payment terms agreed with a supplier
+type SupplierTerms struct {
+	ProviderCode    string `json:"provider_code"`
+	PaymentTermDays int    `json:"payment_term_days"` // Days after check-out
+}
+
+// DueDate returns when a booking checking out at checkOut must be paid
+func (t SupplierTerms) DueDate(checkOut time.Time) time.Time {
+	return checkOut.AddDate(0, 0, t.PaymentTermDays)
+}
+
+// DefaultPaymentTermDays is used for suppliers without configured terms
+const DefaultPaymentTermDays = 30
+
+// ListFilter filters payables in admin listings
+type ListFilter struct {
+	ProviderCode string
+	Status       PayableStatus
+	From         time.Time // Check-out from (inclusive)
+	To           time.Time // Check-out to (exclusive)
+}
+
+// Report is the monthly settlement report for a supplier. Bookings belong to the
+// month they check out in, which is how suppliers invoice.
+type Report struct {
+	ProviderCode string         `json:"provider_code"`
+	Year         int            `json:"year"`
+	Month        int            `json:"month"`
+	Payables     []*Payable     `json:"payables"`
+	Count        int            `json:"count"`
+	Totals       map[string]int `json:"totals"`        // Per currency, excluding cancelled
+	Outstanding  map[string]int `json:"outstanding"`   // Per currency, not settled yet
+	StatusCounts map[string]int `json:"status_counts"` // Per payable status
+}
+
+// InvoiceLine is one booking line of a supplier invoice
+type InvoiceLine struct {
+	InvoiceNumber     string `json:"invoice_number"`
+	SupplierReference string `json:"supplier_reference"`
+	Amount            int    `json:"amount"`
+	Currency          string `json:"currency"`
+}
+
+// DiscrepancyType categorises a reconciliation finding
+type DiscrepancyType string
+
+const (
+	// DiscrepancyAmountMismatch means both sides have the booking with different amounts
+	DiscrepancyAmountMismatch DiscrepancyType = "AMOUNT_MISMATCH"
+	// DiscrepancyMissingInInvoice means we owe for a booking the supplier did not invoice
+	DiscrepancyMissingInInvoice DiscrepancyType = "MISSING_IN_INVOICE"
+	// DiscrepancyMissingInSystem means the supplier invoiced a booking we have no payable for
+	DiscrepancyMissingInSystem DiscrepancyType = "MISSING_IN_SYSTEM"
+)
+
+// Discrepancy is a difference between our payables and a supplier invoice
+type Discrepancy struct {
+	Type              DiscrepancyType `json:"type"`
+	SupplierReference string          `json:"supplier_reference"`
+	BookingReference  string          `json:"booking_reference,omitempty"`
+	ExpectedAmount    int             `json:"expected_amount"`
+	InvoicedAmount    int             `json:"invoiced_amount"`
+	Currency          string          `json:"currency"`
+}
+
+// ReconciliationResult summarises an imported supplier invoice
+type ReconciliationResult struct {
+	ProviderCode  string         `json:"provider_code"`
+	Year          int            `json:"year"`
+	Month         int            `json:"month"`
+	InvoiceLines  int            `json:"invoice_lines"`
+	Matched       int            `json:"matched"`
+	Discrepancies []*Discrepancy `json:"discrepancies"`
+}
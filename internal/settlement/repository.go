@@ -0,0 +1,198 @@
+package settlement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Repository defines interface for supplier payable data operations
+type Repository interface {
+	Create(ctx context.Context, payable *Payable) error
+	GetByID(ctx context.Context, id string) (*Payable, error)
+	GetByBookingID(ctx context.Context, bookingID string) (*Payable, error)
+	GetBySupplierReference(ctx context.Context, providerCode, supplierReference string) (*Payable, error)
+	List(ctx context.Context, filter ListFilter, limit, offset int) ([]*Payable, error)
+	Update(ctx context.Context, payable *Payable) error
+}
+
+type repository struct {
+	db *db.DB
+}
+
+// NewRepository creates a new settlement repository
+func NewRepository(database *db.DB) Repository {
+	return &repository{
+		db: database,
+	}
+}
+
+const payableColumns = `
+	id, booking_id, booking_reference, supplier_reference, provider_code, net_amount, currency,
+	check_in, check_out, due_date, status, COALESCE(invoice_number, ''), invoiced_amount,
+	settled_at, created_at, updated_at, cancellation_penalty
+`
+
+func scanPayable(row pgx.Row) (*Payable, error) {
+	var p Payable
+	err := row.Scan(
+		&p.ID, &p.BookingID, &p.BookingReference, &p.SupplierReference, &p.ProviderCode, &p.NetAmount, &p.Currency,
+		&p.CheckIn, &p.CheckOut, &p.DueDate, &p.Status, &p.InvoiceNumber, &p.InvoicedAmount,
+		&p.SettledAt, &p.CreatedAt, &p.UpdatedAt, &p.Penalty,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Create stores a new payable. There is at most one payable per booking.
+func (r *repository) Create(ctx context.Context, payable *Payable) error {
+	query := `
+		INSERT INTO supplier_payables (
+			id, booking_id, booking_reference, supplier_reference, provider_code, net_amount, currency,
+			check_in, check_out, due_date, status, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		payable.ID, payable.BookingID, payable.BookingReference, payable.SupplierReference,
+		payable.ProviderCode, payable.NetAmount, payable.Currency,
+		payable.CheckIn, payable.CheckOut, payable.DueDate, payable.Status,
+		payable.CreatedAt, payable.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrPayableExists
+		}
+		return fmt.Errorf("failed to create payable: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a payable by ID
+func (r *repository) GetByID(ctx context.Context, id string) (*Payable, error) {
+	query := `SELECT ` + payableColumns + ` FROM supplier_payables WHERE id = $1`
+
+	payable, err := scanPayable(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPayableNotFound
+		}
+		return nil, fmt.Errorf("failed to get payable: %w", err)
+	}
+
+	return payable, nil
+}
+
+// GetByBookingID retrieves the payable of a booking
+func (r *repository) GetByBookingID(ctx context.Context, bookingID string) (*Payable, error) {
+	query := `SELECT ` + payableColumns + ` FROM supplier_payables WHERE booking_id = $1`
+
+	payable, err := scanPayable(r.db.Pool.QueryRow(ctx, query, bookingID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPayableNotFound
+		}
+		return nil, fmt.Errorf("failed to get payable by booking: %w", err)
+	}
+
+	return payable, nil
+}
+
+// GetBySupplierReference retrieves a payable by the supplier's booking reference
+func (r *repository) GetBySupplierReference(ctx context.Context, providerCode, supplierReference string) (*Payable, error) {
+	query := `SELECT ` + payableColumns + ` FROM supplier_payables WHERE provider_code = $1 AND supplier_reference = $2`
+
+	payable, err := scanPayable(r.db.Pool.QueryRow(ctx, query, providerCode, supplierReference))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPayableNotFound
+		}
+		return nil, fmt.Errorf("failed to get payable by supplier reference: %w", err)
+	}
+
+	return payable, nil
+}
+
+// List retrieves payables matching the filter, oldest check-out first
+func (r *repository) List(ctx context.Context, filter ListFilter, limit, offset int) ([]*Payable, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.ProviderCode != "" {
+		args = append(args, filter.ProviderCode)
+		conditions = append(conditions, fmt.Sprintf("provider_code = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, fmt.Sprintf("check_out >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, fmt.Sprintf("check_out < $%d", len(args)))
+	}
+
+	query := `SELECT ` + payableColumns + ` FROM supplier_payables`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY check_out, booking_reference"
+
+	if limit > 0 {
+		args = append(args, limit, offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payables: %w", err)
+	}
+	defer rows.Close()
+
+	var payables []*Payable
+	for rows.Next() {
+		payable, err := scanPayable(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payable: %w", err)
+		}
+		payables = append(payables, payable)
+	}
+
+	return payables, rows.Err()
+}
+
+// Update saves the mutable fields of a payable
+func (r *repository) Update(ctx context.Context, payable *Payable) error {
+	query := `
+		UPDATE supplier_payables
+		SET net_amount = $2, status = $3, invoice_number = NULLIF($4, ''), invoiced_amount = $5,
+			settled_at = $6, updated_at = $7, cancellation_penalty = $8
+		WHERE id = $1
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query,
+		payable.ID, payable.NetAmount, payable.Status, payable.InvoiceNumber, payable.InvoicedAmount,
+		payable.SettledAt, payable.UpdatedAt, payable.Penalty,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update payable: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrPayableNotFound
+	}
+
+	return nil
+}
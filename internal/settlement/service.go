@@ -0,0 +1,309 @@
+package settlement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// ConfirmedBooking carries what a payable needs from a supplier-confirmed booking
+type ConfirmedBooking struct {
+	BookingID         string
+	BookingReference  string
+	SupplierReference string
+	ProviderCode      string
+	NetAmount         int
+	Currency          string
+	CheckIn           time.Time
+	CheckOut          time.Time
+}
+
+// Service defines interface for supplier settlement business logic
+type Service interface {
+	RecordPayable(ctx context.Context, booking *ConfirmedBooking) (*Payable, error)
+	CancelPayable(ctx context.Context, bookingID string, penalty int) error
+	MarkSettled(ctx context.Context, id string) (*Payable, error)
+	ListPayables(ctx context.Context, filter ListFilter, limit, offset int) ([]*Payable, error)
+	GetMonthlyReport(ctx context.Context, providerCode string, year, month int) (*Report, error)
+	Reconcile(ctx context.Context, providerCode string, year, month int, invoice io.Reader) (*ReconciliationResult, error)
+}
+
+type service struct {
+	repo  Repository
+	terms map[string]SupplierTerms
+}
+
+// NewService creates a new settlement service. Suppliers without terms are
+// due DefaultPaymentTermDays after check-out.
+func NewService(repo Repository, terms ...SupplierTerms) Service {
+	s := &service{
+		repo:  repo,
+		terms: make(map[string]SupplierTerms, len(terms)),
+	}
+	for _, t := range terms {
+		s.terms[t.ProviderCode] = t
+	}
+	return s
+}
+
+func (s *service) termsFor(providerCode string) SupplierTerms {
+	if t, ok := s.terms[providerCode]; ok {
+		return t
+	}
+	return SupplierTerms{ProviderCode: providerCode, PaymentTermDays: DefaultPaymentTermDays}
+}
+
+// RecordPayable creates the payable for a supplier-confirmed booking. Recording
+// the same booking twice returns the existing payable.
+func (s *service) RecordPayable(ctx context.Context, booking *ConfirmedBooking) (*Payable, error) {
+	if booking.BookingID == "" || booking.ProviderCode == "" || booking.NetAmount <= 0 || booking.CheckOut.IsZero() {
+		return nil, ErrInvalidPayable
+	}
+
+	payable := NewPayable(
+		booking.BookingID,
+		booking.BookingReference,
+		booking.SupplierReference,
+		booking.ProviderCode,
+		booking.NetAmount,
+		booking.Currency,
+		booking.CheckIn,
+		booking.CheckOut,
+		s.termsFor(booking.ProviderCode),
+	)
+
+	if err := s.repo.Create(ctx, payable); err != nil {
+		if errors.Is(err, ErrPayableExists) {
+			logger.Warnf("Payable for booking %s already recorded, skipping", booking.BookingID)
+			return s.repo.GetByBookingID(ctx, booking.BookingID)
+		}
+		return nil, err
+	}
+
+	logger.Infof("Supplier payable recorded: booking %s, %s %d %s due %s",
+		booking.BookingReference, payable.ProviderCode, payable.NetAmount, payable.Currency, payable.DueDate.Format("2006-01-02"))
+	return payable, nil
+}
+
+// CancelPayable records the supplier's cancellation penalty on a payable,
+// keeping its net amount. Without a penalty nothing is owed and the payable
+// is cancelled.
+func (s *service) CancelPayable(ctx context.Context, bookingID string, penalty int) error {
+	payable, err := s.repo.GetByBookingID(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+
+	if payable.Status == PayableStatusSettled {
+		// Money already left - the supplier owes us a credit, handled manually
+		logger.Warnf("Booking %s cancelled after supplier settlement", bookingID)
+		return nil
+	}
+
+	if penalty < 0 || penalty > payable.NetAmount {
+		return ErrInvalidPayable
+	}
+
+	payable.Penalty = &penalty
+	if penalty == 0 {
+		payable.Status = PayableStatusCancelled
+	}
+	payable.UpdatedAt = time.Now()
+
+	return s.repo.Update(ctx, payable)
+}
+
+// MarkSettled records that the supplier has been paid
+func (s *service) MarkSettled(ctx context.Context, id string) (*Payable, error) {
+	payable, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch payable.Status {
+	case PayableStatusSettled:
+		return nil, ErrAlreadySettled
+	case PayableStatusCancelled:
+		return nil, ErrInvalidPayable
+	}
+
+	now := time.Now()
+	payable.Status = PayableStatusSettled
+	payable.SettledAt = &now
+	payable.UpdatedAt = now
+
+	if err := s.repo.Update(ctx, payable); err != nil {
+		return nil, err
+	}
+
+	return payable, nil
+}
+
+// ListPayables lists payables for admin
+func (s *service) ListPayables(ctx context.Context, filter ListFilter, limit, offset int) ([]*Payable, error) {
+	return s.repo.List(ctx, filter, limit, offset)
+}
+
+// monthRange returns [first day of month, first day of next month) in UTC
+func monthRange(year, month int) (time.Time, time.Time, error) {
+	if year < 2000 || month < 1 || month > 12 {
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 1, 0), nil
+}
+
+// GetMonthlyReport returns a supplier's payables for bookings checking out in the month
+func (s *service) GetMonthlyReport(ctx context.Context, providerCode string, year, month int) (*Report, error) {
+	from, to, err := monthRange(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	payables, err := s.repo.List(ctx, ListFilter{ProviderCode: providerCode, From: from, To: to}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		ProviderCode: providerCode,
+		Year:         year,
+		Month:        month,
+		Payables:     payables,
+		Count:        len(payables),
+		Totals:       make(map[string]int),
+		Outstanding:  make(map[string]int),
+		StatusCounts: make(map[string]int),
+	}
+	for _, p := range payables {
+		report.StatusCounts[string(p.Status)]++
+		if p.Status == PayableStatusCancelled {
+			continue
+		}
+		report.Totals[p.Currency] += p.AmountDue()
+		if p.Status != PayableStatusSettled {
+			report.Outstanding[p.Currency] += p.AmountDue()
+		}
+	}
+
+	return report, nil
+}
+
+// Reconcile compares a supplier invoice CSV with our payables for the month.
+// Matching payables get the invoice number and amount; mismatches are marked
+// DISPUTED. Bookings missing on either side are reported.
+func (s *service) Reconcile(ctx context.Context, providerCode string, year, month int, invoice io.Reader) (*ReconciliationResult, error) {
+	from, to, err := monthRange(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := ParseInvoiceCSV(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	payables, err := s.repo.List(ctx, ListFilter{ProviderCode: providerCode, From: from, To: to}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	byReference := make(map[string]*Payable, len(payables))
+	for _, p := range payables {
+		byReference[p.SupplierReference] = p
+	}
+
+	result := &ReconciliationResult{
+		ProviderCode:  providerCode,
+		Year:          year,
+		Month:         month,
+		InvoiceLines:  len(lines),
+		Discrepancies: []*Discrepancy{},
+	}
+	invoiced := make(map[string]bool, len(lines))
+
+	for _, line := range lines {
+		invoiced[line.SupplierReference] = true
+
+		payable, ok := byReference[line.SupplierReference]
+		if !ok {
+			// Supplier may invoice in a different month than the check-out
+			payable, err = s.repo.GetBySupplierReference(ctx, providerCode, line.SupplierReference)
+			if err != nil && !errors.Is(err, ErrPayableNotFound) {
+				return nil, err
+			}
+		}
+
+		if payable == nil {
+			result.Discrepancies = append(result.Discrepancies, &Discrepancy{
+				Type:              DiscrepancyMissingInSystem,
+				SupplierReference: line.SupplierReference,
+				InvoicedAmount:    line.Amount,
+				Currency:          line.Currency,
+			})
+			continue
+		}
+
+		matched := line.Amount == payable.AmountDue() && line.Currency == payable.Currency
+		if matched {
+			result.Matched++
+		} else {
+			result.Discrepancies = append(result.Discrepancies, &Discrepancy{
+				Type:              DiscrepancyAmountMismatch,
+				SupplierReference: line.SupplierReference,
+				BookingReference:  payable.BookingReference,
+				ExpectedAmount:    payable.AmountDue(),
+				InvoicedAmount:    line.Amount,
+				Currency:          line.Currency,
+			})
+		}
+
+		if err := s.applyInvoiceLine(ctx, payable, line, matched); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, p := range payables {
+		if invoiced[p.SupplierReference] || p.Status == PayableStatusCancelled {
+			continue
+		}
+		result.Discrepancies = append(result.Discrepancies, &Discrepancy{
+			Type:              DiscrepancyMissingInInvoice,
+			SupplierReference: p.SupplierReference,
+			BookingReference:  p.BookingReference,
+			ExpectedAmount:    p.AmountDue(),
+			Currency:          p.Currency,
+		})
+	}
+
+	logger.Infof("Supplier invoice reconciled: %s %04d-%02d, %d lines, %d matched, %d discrepancies",
+		providerCode, year, month, len(lines), result.Matched, len(result.Discrepancies))
+	return result, nil
+}
+
+// applyInvoiceLine stores the invoiced amount on a payable and flags mismatches
+func (s *service) applyInvoiceLine(ctx context.Context, payable *Payable, line *InvoiceLine, matched bool) error {
+	amount := line.Amount
+	payable.InvoiceNumber = line.InvoiceNumber
+	payable.InvoicedAmount = &amount
+	payable.UpdatedAt = time.Now()
+
+	switch {
+	case payable.Status == PayableStatusSettled:
+		// Keep settled payables settled; the discrepancy is still reported
+	case !matched:
+		payable.Status = PayableStatusDisputed
+	case payable.Status == PayableStatusDisputed:
+		// A corrected invoice resolves an earlier dispute
+		payable.Status = PayableStatusPending
+	}
+
+	if err := s.repo.Update(ctx, payable); err != nil {
+		return fmt.Errorf("failed to apply invoice line %s: %w", line.SupplierReference, err)
+	}
+	return nil
+}
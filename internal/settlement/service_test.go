@@ -0,0 +1,393 @@
+package settlement
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRepository is a mock implementation of settlement.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, payable *Payable) error {
+	args := m.Called(ctx, payable)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetByID(ctx context.Context, id string) (*Payable, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Payable), args.Error(1)
+}
+
+func (m *MockRepository) GetByBookingID(ctx context.Context, bookingID string) (*Payable, error) {
+	args := m.Called(ctx, bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Payable), args.Error(1)
+}
+
+func (m *MockRepository) GetBySupplierReference(ctx context.Context, providerCode, supplierReference string) (*Payable, error) {
+	args := m.Called(ctx, providerCode, supplierReference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Payable), args.Error(1)
+}
+
+func (m *MockRepository) List(ctx context.Context, filter ListFilter, limit, offset int) ([]*Payable, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Payable), args.Error(1)
+}
+
+func (m *MockRepository) Update(ctx context.Context, payable *Payable) error {
+	args := m.Called(ctx, payable)
+	return args.Error(0)
+}
+
+func testPayable(ref string, amount int, status PayableStatus) *Payable {
+	checkOut := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
+	return &Payable{
+		ID:                "payable-" + ref,
+		BookingID:         "booking-" + ref,
+		BookingReference:  "BK-" + ref,
+		SupplierReference: ref,
+		ProviderCode:      "hotelbeds",
+		NetAmount:         amount,
+		Currency:          "IDR",
+		CheckIn:           checkOut.AddDate(0, 0, -2),
+		CheckOut:          checkOut,
+		DueDate:           checkOut.AddDate(0, 0, 30),
+		Status:            status,
+	}
+}
+
+func marchFilter() ListFilter {
+	return ListFilter{
+		ProviderCode: "hotelbeds",
+		From:         time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestService_RecordPayable(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo, SupplierTerms{ProviderCode: "hotelbeds", PaymentTermDays: 15})
+	checkOut := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*settlement.Payable")).Return(nil)
+
+	payable, err := svc.RecordPayable(context.Background(), &ConfirmedBooking{
+		BookingID:         "booking-1",
+		BookingReference:  "BK-1",
+		SupplierReference: "102-123456",
+		ProviderCode:      "hotelbeds",
+		NetAmount:         850000,
+		Currency:          "IDR",
+		CheckIn:           checkOut.AddDate(0, 0, -2),
+		CheckOut:          checkOut,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, PayableStatusPending, payable.Status)
+	assert.Equal(t, 850000, payable.NetAmount)
+	assert.Equal(t, checkOut.AddDate(0, 0, 15), payable.DueDate)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_RecordPayable_DefaultTerms(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+	checkOut := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*settlement.Payable")).Return(nil)
+
+	payable, err := svc.RecordPayable(context.Background(), &ConfirmedBooking{
+		BookingID:    "booking-1",
+		ProviderCode: "hotelplanner",
+		NetAmount:    100,
+		Currency:     "IDR",
+		CheckOut:     checkOut,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, checkOut.AddDate(0, 0, DefaultPaymentTermDays), payable.DueDate)
+}
+
+func TestService_RecordPayable_Duplicate(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+	existing := testPayable("102-1", 850000, PayableStatusPending)
+
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(ErrPayableExists)
+	mockRepo.On("GetByBookingID", mock.Anything, "booking-102-1").Return(existing, nil)
+
+	payable, err := svc.RecordPayable(context.Background(), &ConfirmedBooking{
+		BookingID:    "booking-102-1",
+		ProviderCode: "hotelbeds",
+		NetAmount:    850000,
+		Currency:     "IDR",
+		CheckOut:     existing.CheckOut,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, existing, payable)
+}
+
+func TestService_RecordPayable_Invalid(t *testing.T) {
+	svc := NewService(new(MockRepository))
+
+	_, err := svc.RecordPayable(context.Background(), &ConfirmedBooking{BookingID: "booking-1", ProviderCode: "hotelbeds"})
+
+	assert.ErrorIs(t, err, ErrInvalidPayable)
+}
+
+func TestService_CancelPayable(t *testing.T) {
+	tests := []struct {
+		name              string
+		penalty           int
+		expectedAmountDue int
+		expectedStatus    PayableStatus
+	}{
+		{name: "free cancellation", penalty: 0, expectedAmountDue: 0, expectedStatus: PayableStatusCancelled},
+		{name: "with penalty", penalty: 200000, expectedAmountDue: 200000, expectedStatus: PayableStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			svc := NewService(mockRepo)
+			payable := testPayable("102-1", 850000, PayableStatusPending)
+
+			mockRepo.On("GetByBookingID", mock.Anything, "booking-102-1").Return(payable, nil)
+			mockRepo.On("Update", mock.Anything, payable).Return(nil)
+
+			err := svc.CancelPayable(context.Background(), "booking-102-1", tt.penalty)
+
+			require.NoError(t, err)
+			assert.Equal(t, 850000, payable.NetAmount)
+			require.NotNil(t, payable.Penalty)
+			assert.Equal(t, tt.penalty, *payable.Penalty)
+			assert.Equal(t, tt.expectedAmountDue, payable.AmountDue())
+			assert.Equal(t, tt.expectedStatus, payable.Status)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_MarkSettled(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+	payable := testPayable("102-1", 850000, PayableStatusPending)
+
+	mockRepo.On("GetByID", mock.Anything, payable.ID).Return(payable, nil)
+	mockRepo.On("Update", mock.Anything, payable).Return(nil)
+
+	settled, err := svc.MarkSettled(context.Background(), payable.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, PayableStatusSettled, settled.Status)
+	assert.NotNil(t, settled.SettledAt)
+
+	_, err = svc.MarkSettled(context.Background(), payable.ID)
+	assert.ErrorIs(t, err, ErrAlreadySettled)
+}
+
+func TestService_GetMonthlyReport(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+
+	penalized := testPayable("102-4", 600000, PayableStatusPending)
+	penalty := 200000
+	penalized.Penalty = &penalty
+
+	mockRepo.On("List", mock.Anything, marchFilter(), 0, 0).Return([]*Payable{
+		testPayable("102-1", 850000, PayableStatusPending),
+		testPayable("102-2", 500000, PayableStatusSettled),
+		testPayable("102-3", 0, PayableStatusCancelled),
+		penalized,
+	}, nil)
+
+	report, err := svc.GetMonthlyReport(context.Background(), "hotelbeds", 2026, 3)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Count)
+	assert.Equal(t, 1550000, report.Totals["IDR"])
+	assert.Equal(t, 1050000, report.Outstanding["IDR"])
+	assert.Equal(t, 1, report.StatusCounts["CANCELLED"])
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReportCSV(&buf, report))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, rows, 5)
+	assert.Equal(t, "booking_reference", rows[0][0])
+	assert.Equal(t, "BK-102-1", rows[1][0])
+	assert.Equal(t, "850000", rows[1][6])
+	assert.Equal(t, "", rows[1][7])
+	assert.Equal(t, "600000", rows[4][6])
+	assert.Equal(t, "200000", rows[4][7])
+}
+
+func TestService_GetMonthlyReport_InvalidPeriod(t *testing.T) {
+	svc := NewService(new(MockRepository))
+
+	_, err := svc.GetMonthlyReport(context.Background(), "hotelbeds", 2026, 13)
+
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
+
+func TestService_Reconcile(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := NewService(mockRepo)
+
+	matched := testPayable("102-1", 850000, PayableStatusPending)
+	mismatched := testPayable("102-2", 500000, PayableStatusPending)
+	notInvoiced := testPayable("102-3", 300000, PayableStatusPending)
+	otherMonth := testPayable("102-9", 100000, PayableStatusPending)
+
+	mockRepo.On("List", mock.Anything, marchFilter(), 0, 0).Return([]*Payable{matched, mismatched, notInvoiced}, nil)
+	mockRepo.On("GetBySupplierReference", mock.Anything, "hotelbeds", "102-9").Return(otherMonth, nil)
+	mockRepo.On("GetBySupplierReference", mock.Anything, "hotelbeds", "102-404").Return(nil, ErrPayableNotFound)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*settlement.Payable")).Return(nil)
+
+	invoice := strings.NewReader(strings.Join([]string{
+		"Invoice No,Reference,Net Amount,Currency",
+		"INV-1,102-1,\"850,000.00\",IDR",
+		"INV-1,102-2,550000,IDR",
+		"INV-1,102-9,100000,IDR",
+		"INV-1,102-404,75000,IDR",
+		",,,",
+	}, "\n"))
+
+	result, err := svc.Reconcile(context.Background(), "hotelbeds", 2026, 3, invoice)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.InvoiceLines)
+	assert.Equal(t, 2, result.Matched)
+
+	byType := make(map[DiscrepancyType][]*Discrepancy)
+	for _, d := range result.Discrepancies {
+		byType[d.Type] = append(byType[d.Type], d)
+	}
+	require.Len(t, byType[DiscrepancyAmountMismatch], 1)
+	assert.Equal(t, "102-2", byType[DiscrepancyAmountMismatch][0].SupplierReference)
+	assert.Equal(t, 500000, byType[DiscrepancyAmountMismatch][0].ExpectedAmount)
+	assert.Equal(t, 550000, byType[DiscrepancyAmountMismatch][0].InvoicedAmount)
+	require.Len(t, byType[DiscrepancyMissingInSystem], 1)
+	assert.Equal(t, "102-404", byType[DiscrepancyMissingInSystem][0].SupplierReference)
+	require.Len(t, byType[DiscrepancyMissingInInvoice], 1)
+	assert.Equal(t, "102-3", byType[DiscrepancyMissingInInvoice][0].SupplierReference)
+
+	assert.Equal(t, PayableStatusPending, matched.Status)
+	assert.Equal(t, "INV-1", matched.InvoiceNumber)
+	assert.Equal(t, PayableStatusDisputed, mismatched.Status)
+	require.NotNil(t, mismatched.InvoicedAmount)
+	assert.Equal(t, 550000, *mismatched.InvoicedAmount)
+	mockRepo.AssertNumberOfCalls(t, "Update", 3)
+}
+
+func TestParseInvoiceCSV_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{name: "empty file", input: "", wantErr: ErrEmptyInvoiceFile},
+		{name: "header only", input: "reference,amount\n", wantErr: ErrEmptyInvoiceFile},
+		{name: "missing amount column", input: "reference,currency\n102-1,IDR\n", wantErr: ErrInvalidInvoiceFile},
+		{name: "invalid amount", input: "reference,amount\n102-1,abc\n", wantErr: ErrInvalidInvoiceFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseInvoiceCSV(strings.NewReader(tt.input))
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"1250000", 1250000},
+		{"1,250,000", 1250000},
+		{"1.250.000", 1250000},
+		{"1250000.00", 1250000},
+		{"1,250,000.50", 1250001},
+		{"1.250.000,00", 1250000},
+		{"1250000,4", 1250000},
+		{"1.250", 1250},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseAmount(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, input := range []string{"", "abc", "1.2.3,4,5"} {
+		_, err := parseAmount(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestBookingConfirmedHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		paymentType string
+		recorded    bool
+	}{
+		{name: "pay now", paymentType: "PAY_NOW", recorded: true},
+		{name: "pay later", paymentType: "PAY_LATER", recorded: true},
+		{name: "pay at hotel", paymentType: "PAY_AT_HOTEL", recorded: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := NewBookingConfirmedHandler(NewService(mockRepo))
+			if tt.recorded {
+				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*settlement.Payable")).Return(nil)
+			}
+
+			err := handler(context.Background(), eventbus.Event{Payload: map[string]interface{}{
+				"booking_id":         "booking-1",
+				"booking_reference":  "BK-1",
+				"supplier_reference": "102-123456",
+				"provider_code":      "hotelbeds",
+				"payment_type":       tt.paymentType,
+				"net_amount":         850000,
+				"currency":           "IDR",
+				"check_in":           time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+				"check_out":          time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC),
+			}})
+
+			require.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+			if !tt.recorded {
+				mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -10,15 +10,21 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Environment string
-	Database    DatabaseConfig
-	Redis       RedisConfig
-	Server      ServerConfig
-	JWT         JWTConfig
-	Hotelbeds   HotelbedsConfig
-	Midtrans    MidtransConfig
-	SendGrid    SendGridConfig
-	RabbitMQ    RabbitMQConfig
+	Environment     string
+	Database        DatabaseConfig
+	Redis           RedisConfig
+	Server          ServerConfig
+	JWT             JWTConfig
+	Hotelbeds       HotelbedsConfig
+	HotelPlanner    HotelPlannerConfig
+	ProviderTraffic ProviderTrafficConfig
+	Sync            SyncConfig
+	Midtrans        MidtransConfig
+	SendGrid        SendGridConfig
+	RabbitMQ        RabbitMQConfig
+	Invoice         InvoiceConfig
+	Risk            RiskConfig
+	Booking         BookingConfig
 }
 
 type DatabaseConfig struct {
@@ -38,8 +44,9 @@ type RedisConfig struct {
 }
 
 type ServerConfig struct {
-	Host string
-	Port string
+	Host           string
+	Port           string
+	TrustedProxies []string // IPs or CIDRs of load balancers whose X-Forwarded-For and CF-IPCountry are honored
 }
 
 type JWTConfig struct {
@@ -48,11 +55,45 @@ type JWTConfig struct {
 }
 
 type HotelbedsConfig struct {
+	APIKey            string
+	Secret            string
+	BaseURL           string
+	PaymentTermDays   int  // Days after check-out before a supplier invoice is due
+	AvailabilityQuota int  // Availability requests per minute
+	BookingQuota      int  // Booking and cancellation requests per minute
+	SharedRateLimit   bool // Share quotas between instances through Redis
+}
+
+// HotelPlannerConfig holds the HotelPlanner API credentials. The provider is
+// only registered when an API key is set.
+type HotelPlannerConfig struct {
 	APIKey  string
 	Secret  string
 	BaseURL string
 }
 
+// ProviderTrafficConfig controls capture of provider HTTP traffic for
+// debugging and certification. Capture is off unless enabled.
+type ProviderTrafficConfig struct {
+	Enabled       bool
+	RetentionDays int
+	MaxBodyBytes  int
+	QueueSize     int
+}
+
+// SyncConfig schedules the content sync daemon (cmd/sync --daemon).
+// Schedules are cron expressions; hotels and rooms are synced for each of
+// Destinations, HotelBeds destination codes.
+type SyncConfig struct {
+	DestinationsSchedule  string
+	HotelsSchedule        string
+	RoomsSchedule         string
+	Destinations          []string
+	CountryCode           string
+	StatusAddr            string  // Listen address of the status endpoint
+	DeactivationThreshold float64 // Share of a destination's hotels a run may deactivate
+}
+
 type MidtransConfig struct {
 	MerchantID   string
 	ClientKey    string
@@ -65,6 +106,36 @@ type SendGridConfig struct {
 	FromEmail string
 }
 
+// InvoiceConfig holds the issuer details printed on customer invoices
+type InvoiceConfig struct {
+	CompanyName string
+	TaxID       string
+	Address     string
+	TaxName     string
+	TaxRate     float64 // Percent, prices are tax-inclusive
+}
+
+// RiskConfig holds the fraud scoring thresholds (scores run 0-100)
+type RiskConfig struct {
+	Enabled            bool
+	ReviewScore        int
+	BlockScore         int
+	HighValueAmount    int // IDR
+	LastMinuteWindow   time.Duration
+	NewAccountAge      time.Duration
+	VelocityWindow     time.Duration
+	MaxAttemptsPerUser int
+	MaxAttemptsPerIP   int
+	MaxAccountsPerCard int
+}
+
+// BookingConfig holds the payment-type rules of new bookings
+type BookingConfig struct {
+	// MaxUnguaranteedAmount is the highest IDR total of a PAY_AT_HOTEL booking
+	// accepted without a card guarantee; 0 requires a card for every booking
+	MaxUnguaranteedAmount int
+}
+
 type RabbitMQConfig struct {
 	Host           string
 	Port           string
@@ -96,6 +167,7 @@ func Load() (*Config, error) {
 	viper.BindEnv("database.sslmode", "BOOKINGKUY_DATABASE_SSLMODE")
 	viper.BindEnv("server.host", "BOOKINGKUY_SERVER_HOST")
 	viper.BindEnv("server.port", "BOOKINGKUY_SERVER_PORT")
+	viper.BindEnv("server.trustedproxies", "BOOKINGKUY_SERVER_TRUSTEDPROXIES")
 	viper.BindEnv("redis.host", "BOOKINGKUY_REDIS_HOST")
 	viper.BindEnv("redis.port", "BOOKINGKUY_REDIS_PORT")
 	viper.BindEnv("redis.password", "BOOKINGKUY_REDIS_PASSWORD")
@@ -139,6 +211,30 @@ func setDefaults() {
 
 	// Hotelbeds
 	viper.SetDefault("hotelbeds.baseurl", "https://api.hotelbeds.com")
+	viper.SetDefault("hotelbeds.paymenttermdays", 30)
+	viper.SetDefault("hotelbeds.availabilityquota", 300)
+	viper.SetDefault("hotelbeds.bookingquota", 30)
+	viper.SetDefault("hotelbeds.sharedratelimit", false)
+
+	// HotelPlanner
+	viper.SetDefault("hotelplanner.apikey", "")
+	viper.SetDefault("hotelplanner.secret", "")
+	viper.SetDefault("hotelplanner.baseurl", "https://api.hotelplanner.com")
+
+	// Provider traffic capture
+	viper.SetDefault("providertraffic.enabled", false)
+	viper.SetDefault("providertraffic.retentiondays", 14)
+	viper.SetDefault("providertraffic.maxbodybytes", 65536)
+	viper.SetDefault("providertraffic.queuesize", 1000)
+
+	// Content sync daemon
+	viper.SetDefault("sync.destinationsschedule", "0 2 * * 0")
+	viper.SetDefault("sync.hotelsschedule", "0 * * * *")
+	viper.SetDefault("sync.roomsschedule", "30 3 * * *")
+	viper.SetDefault("sync.destinations", []string{})
+	viper.SetDefault("sync.countrycode", "ID")
+	viper.SetDefault("sync.statusaddr", ":8081")
+	viper.SetDefault("sync.deactivationthreshold", 0.1)
 
 	// Midtrans
 	viper.SetDefault("midtrans.isproduction", false)
@@ -146,6 +242,26 @@ func setDefaults() {
 	// SendGrid
 	viper.SetDefault("sendgrid.fromemail", "noreply@bookingkuy.com")
 
+	// Invoice
+	viper.SetDefault("invoice.companyname", "PT Bookingkuy Indonesia")
+	viper.SetDefault("invoice.taxname", "PPN")
+	viper.SetDefault("invoice.taxrate", 11.0)
+
+	// Risk
+	viper.SetDefault("risk.enabled", true)
+	viper.SetDefault("risk.reviewscore", 40)
+	viper.SetDefault("risk.blockscore", 80)
+	viper.SetDefault("risk.highvalueamount", 10000000)
+	viper.SetDefault("risk.lastminutewindow", "24h")
+	viper.SetDefault("risk.newaccountage", "24h")
+	viper.SetDefault("risk.velocitywindow", "1h")
+	viper.SetDefault("risk.maxattemptsperuser", 5)
+	viper.SetDefault("risk.maxattemptsperip", 10)
+	viper.SetDefault("risk.maxaccountspercard", 1)
+
+	// Booking
+	viper.SetDefault("booking.maxunguaranteedamount", 0)
+
 	// RabbitMQ
 	viper.SetDefault("rabbitmq.host", "localhost")
 	viper.SetDefault("rabbitmq.port", "5672")
@@ -171,6 +287,9 @@ func validate(cfg *Config) error {
 	if cfg.JWT.Secret == "" {
 		return fmt.Errorf("JWT secret is required")
 	}
+	if cfg.Risk.Enabled && cfg.Risk.ReviewScore > cfg.Risk.BlockScore {
+		return fmt.Errorf("risk review score must not exceed block score")
+	}
 
 	// For production, check for sensitive configurations
 	if cfg.Environment == "production" {
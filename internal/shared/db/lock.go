@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Lock is a session-level Postgres advisory lock. It holds a pool connection
+// for as long as it is held, and is released if that connection drops.
+type Lock struct {
+	conn *pgxpool.Conn
+	key  int64
+}
+
+// TryLock takes the advisory lock key without waiting. It returns nil when
+// another session holds it.
+func (db *DB) TryLock(ctx context.Context, key int64) (*Lock, error) {
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection for lock: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("failed to take advisory lock: %w", err)
+	}
+	if !locked {
+		conn.Release()
+		return nil, nil
+	}
+
+	return &Lock{conn: conn, key: key}, nil
+}
+
+// Hold blocks until ctx is done or the lock's connection fails a check,
+// checking every interval. It returns nil when ctx is done.
+func (l *Lock) Hold(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			err := l.conn.Ping(checkCtx)
+			cancel()
+			if err != nil && ctx.Err() == nil {
+				return fmt.Errorf("lost advisory lock connection: %w", err)
+			}
+		}
+	}
+}
+
+// Release unlocks and returns the connection to the pool. A connection that
+// cannot unlock is closed, which releases the lock too.
+func (l *Lock) Release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, l.key); err != nil {
+		l.conn.Conn().Close(ctx)
+	}
+	l.conn.Release()
+}
@@ -130,12 +130,18 @@ const (
 	EventBookingPaid     = "booking.paid"
 	EventBookingConfirmed = "booking.confirmed"
 	EventBookingCancelled = "booking.cancelled"
+	EventBookingPaymentDue = "booking.payment_due"
 
 	// Payment events
 	EventPaymentSuccess  = "payment.success"
 	EventPaymentFailed   = "payment.failed"
 	EventPaymentRefunded = "payment.refunded"
 
+	// Risk events
+	EventRiskReviewRequired = "risk.review_required"
+	EventRiskReviewApproved = "risk.review_approved"
+	EventRiskReviewRejected = "risk.review_rejected"
+
 	// Notification events
 	EventNotificationSent = "notification.sent"
 	EventNotificationFailed = "notification.failed"
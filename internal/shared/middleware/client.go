@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+const (
+	clientIPKey      = "client_ip"
+	clientCountryKey = "client_country"
+)
+
+// TrustedProxies creates a middleware that resolves the client IP for
+// ClientIP and the client country for ClientCountry. X-Forwarded-For,
+// X-Real-IP and CF-IPCountry are only honored when the request comes from
+// one of the given proxies (IPs or CIDRs); anyone else could set them to
+// any value they like
+func TrustedProxies(proxies []string) func(http.Handler) http.Handler {
+	var trusted []netip.Prefix
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			trusted = append(trusted, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			logger.Warnf("Ignoring invalid trusted proxy %q", proxy)
+			continue
+		}
+		addr = addr.Unmap()
+		trusted = append(trusted, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	isTrusted := func(ip string) bool {
+		addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+		if err != nil {
+			return false
+		}
+		addr = addr.Unmap()
+		for _, prefix := range trusted {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := remoteIP(r)
+			country := ""
+			if isTrusted(ip) {
+				ip = forwardedIP(r, ip, isTrusted)
+				country = cdnCountry(r)
+			}
+
+			ctx := context.WithValue(r.Context(), clientIPKey, ip)
+			ctx = context.WithValue(ctx, clientCountryKey, country)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// forwardedIP returns the client address reported by a trusted proxy. The
+// X-Forwarded-For chain is walked from the right, skipping our own proxies,
+// as only the entries they appended can be relied on
+func forwardedIP(r *http.Request, proxyIP string, isTrusted func(string) bool) string {
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			if !isTrusted(hop) {
+				return hop
+			}
+			proxyIP = hop
+		}
+		return proxyIP
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	return proxyIP
+}
+
+// ClientIP returns the IP address of the client as resolved by the
+// TrustedProxies middleware, or the connection's remote address without it
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey).(string); ok && ip != "" {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// remoteIP returns the IP address of the connection's peer
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ClientCountry returns the ISO country code of the client as resolved by
+// the TrustedProxies middleware, or "" when unknown or when the request did
+// not come through a trusted proxy
+func ClientCountry(r *http.Request) string {
+	country, _ := r.Context().Value(clientCountryKey).(string)
+	return country
+}
+
+// cdnCountry returns the country geolocated by the CDN (Cloudflare's
+// CF-IPCountry header), or "" when unknown
+func cdnCountry(r *http.Request) string {
+	country := strings.ToUpper(strings.TrimSpace(r.Header.Get("CF-IPCountry")))
+	// Cloudflare uses XX for unknown; T1 (Tor) is kept as it never matches a card country
+	if country == "XX" || len(country) != 2 {
+		return ""
+	}
+	return country
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustedProxiesClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"direct client", "203.0.113.7:51234", "", "", "203.0.113.7"},
+		{"spoofed header from untrusted peer", "203.0.113.7:51234", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"forwarded by trusted proxy", "10.0.0.5:443", "198.51.100.1", "", "198.51.100.1"},
+		{"client spoof prepended before proxy entry", "10.0.0.5:443", "1.2.3.4, 198.51.100.1", "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.5:443", "198.51.100.1, 10.0.0.9", "", "198.51.100.1"},
+		{"real ip from trusted proxy", "192.0.2.10:443", "", "198.51.100.3", "198.51.100.3"},
+		{"trusted proxy without headers", "10.0.0.5:443", "", "", "10.0.0.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := TrustedProxies([]string{"10.0.0.0/8", "192.0.2.10", "not-an-ip"})(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					got = ClientIP(r)
+				}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClientIPWithoutMiddleware(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	assert.Equal(t, "203.0.113.7", ClientIP(req), "headers are ignored unless a trusted proxy is configured")
+}
+
+func TestTrustedProxiesClientCountry(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		country    string
+		want       string
+	}{
+		{"country from trusted proxy", "10.0.0.5:443", "id", "ID"},
+		{"spoofed country from untrusted peer", "203.0.113.7:51234", "ID", ""},
+		{"unknown country from trusted proxy", "10.0.0.5:443", "XX", ""},
+		{"trusted proxy without header", "10.0.0.5:443", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := TrustedProxies([]string{"10.0.0.0/8"})(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					got = ClientCountry(r)
+				}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.country != "" {
+				req.Header.Set("CF-IPCountry", tt.country)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClientCountryWithoutMiddleware(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("CF-IPCountry", "ID")
+
+	assert.Equal(t, "", ClientCountry(req), "the CDN header is ignored unless a trusted proxy is configured")
+}
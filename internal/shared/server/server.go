@@ -8,6 +8,7 @@ import (
 
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/config"
 	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/middleware"
 )
 
 // Server wraps http.Server with additional functionality
@@ -15,13 +16,15 @@ type Server struct {
 	server      *http.Server
 	router      http.Handler
 	port        string
+	proxies     []string
 }
 
 // New creates a new HTTP server
 func New(cfg *config.Config, router http.Handler) *Server {
 	s := &Server{
-		router: router,
-		port:   cfg.Server.Port,
+		router:  router,
+		port:    cfg.Server.Port,
+		proxies: cfg.Server.TrustedProxies,
 	}
 
 	s.server = &http.Server{
@@ -68,6 +71,7 @@ func (s *Server) withMiddlewares(handler http.Handler) http.Handler {
 	// Chain middlewares
 	var h http.Handler = handler
 
+	h = middleware.TrustedProxies(s.proxies)(h)
 	h = s.requestIDMiddleware(h)
 	h = s.loggingMiddleware(h)
 	h = s.recoveryMiddleware(h)
@@ -15,6 +15,7 @@ type Job struct {
 	Name     string
 	Handler  func(ctx context.Context) error
 	Interval time.Duration
+	Schedule Schedule // Runs on schedule instead of every Interval when set
 }
 
 // Worker manages background jobs
@@ -81,6 +82,11 @@ func (w *Worker) Stop() {
 func (w *Worker) runJob(ctx context.Context, job *Job) {
 	defer w.wg.Done()
 
+	if job.Schedule != nil {
+		w.runScheduledJob(ctx, job)
+		return
+	}
+
 	ticker := time.NewTicker(job.Interval)
 	defer ticker.Stop()
 
@@ -104,6 +110,31 @@ func (w *Worker) runJob(ctx context.Context, job *Job) {
 	}
 }
 
+// runScheduledJob runs a job at each time its schedule returns
+func (w *Worker) runScheduledJob(ctx context.Context, job *Job) {
+	for {
+		next := job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			logger.Warnf("Job %s has no upcoming run", job.Name)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Infof("Job %s stopped", job.Name)
+			return
+		case <-timer.C:
+			if err := job.Handler(ctx); err != nil {
+				logger.Errorf("Job %s failed: %v", job.Name, err)
+			} else {
+				logger.Infof("Job %s completed successfully", job.Name)
+			}
+		}
+	}
+}
+
 // ExecuteOnce executes a job once immediately
 func (w *Worker) ExecuteOnce(ctx context.Context, jobID string) error {
 	w.mu.RLock()
@@ -0,0 +1,381 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/db"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/worker"
+)
+
+// leaderLockKey is the Postgres advisory lock held by the syncing daemon
+const leaderLockKey int64 = 0x73796e63 // "sync"
+
+// Daemon job IDs
+const (
+	JobDestinations = "destinations"
+	JobHotels       = "hotels"
+	JobRooms        = "rooms"
+)
+
+// Job run triggers and statuses
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+
+	JobStatusRunning   = "RUNNING"
+	JobStatusCompleted = "COMPLETED"
+	JobStatusFailed    = "FAILED"
+)
+
+// Syncer runs content syncs; *Service implements it
+type Syncer interface {
+	SyncDestinations(ctx context.Context, opts SyncOptions) (*SyncResult, error)
+	SyncHotels(ctx context.Context, destinationCode string, opts SyncOptions) (*SyncResult, error)
+	SyncRooms(ctx context.Context, destinationCode string, opts SyncOptions) (*SyncResult, error)
+}
+
+// DaemonConfig schedules the sync daemon's jobs
+type DaemonConfig struct {
+	DestinationsSchedule  string   // Cron expression, e.g. "0 2 * * 0"
+	HotelsSchedule        string   // Incremental hotel content syncs
+	RoomsSchedule         string   // Complete room catalog syncs, which also deactivate removed hotels
+	Destinations          []string // HotelBeds destinations whose hotels and rooms are synced
+	CountryCode           string   // Country of the destinations synced
+	Languages             []string // Hotel content languages (empty = DefaultContentLanguages)
+	DeactivationThreshold float64
+	LeaderRetry           time.Duration // How often a standby instance tries to become leader
+	LeaderCheck           time.Duration // How often the leader checks it still holds the lock
+}
+
+// DefaultDaemonConfig returns the default daemon schedules
+func DefaultDaemonConfig() DaemonConfig {
+	return DaemonConfig{
+		DestinationsSchedule:  "0 2 * * 0",
+		HotelsSchedule:        "0 * * * *",
+		RoomsSchedule:         "30 3 * * *",
+		CountryCode:           "ID",
+		DeactivationThreshold: DefaultDeactivationThreshold,
+		LeaderRetry:           30 * time.Second,
+		LeaderCheck:           15 * time.Second,
+	}
+}
+
+// JobRun is the outcome of one run of a daemon job
+type JobRun struct {
+	Trigger     string                 `json:"trigger"`
+	TriggeredBy string                 `json:"triggered_by,omitempty"`
+	Status      string                 `json:"status"`
+	Error       string                 `json:"error,omitempty"`
+	Results     map[string]*SyncResult `json:"results"` // By destination code; "all" for destination syncs
+	StartedAt   time.Time              `json:"started_at"`
+	FinishedAt  *time.Time             `json:"finished_at,omitempty"`
+}
+
+// JobStatus describes a daemon job and its last run
+type JobStatus struct {
+	ID       string     `json:"id"`
+	Schedule string     `json:"schedule"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	Running  bool       `json:"running"`
+	LastRun  *JobRun    `json:"last_run,omitempty"`
+}
+
+// DaemonStatus describes the daemon instance and its jobs
+type DaemonStatus struct {
+	Instance    string       `json:"instance"`
+	Leader      bool         `json:"leader"`
+	LeaderSince *time.Time   `json:"leader_since,omitempty"`
+	Jobs        []*JobStatus `json:"jobs"`
+}
+
+type daemonJob struct {
+	id       string
+	schedule worker.Schedule
+	expr     string
+	run      func(ctx context.Context, results map[string]*SyncResult) error
+	running  atomic.Bool
+	lastRun  *JobRun
+}
+
+// Daemon runs syncs on schedules. Only the instance holding the leader lock
+// syncs; the others stand by to take over.
+type Daemon struct {
+	syncer   Syncer
+	db       *db.DB
+	config   DaemonConfig
+	worker   *worker.Worker
+	jobs     []*daemonJob
+	instance string
+	manual   sync.WaitGroup // Manual runs of the current leadership
+
+	mu          sync.Mutex
+	leaderCtx   context.Context // Cancelled when leadership ends
+	leaderSince time.Time
+}
+
+// NewDaemon creates a sync daemon
+func NewDaemon(syncer Syncer, database *db.DB, config DaemonConfig) (*Daemon, error) {
+	instance, _ := os.Hostname()
+	d := &Daemon{
+		syncer:   syncer,
+		db:       database,
+		config:   config,
+		worker:   worker.New(),
+		instance: fmt.Sprintf("%s-%d", instance, os.Getpid()),
+	}
+
+	jobs := []struct {
+		id   string
+		expr string
+		run  func(ctx context.Context, results map[string]*SyncResult) error
+	}{
+		{JobDestinations, config.DestinationsSchedule, d.syncDestinations},
+		{JobHotels, config.HotelsSchedule, d.syncHotels},
+		{JobRooms, config.RoomsSchedule, d.syncRooms},
+	}
+	for _, j := range jobs {
+		schedule, err := worker.ParseCron(j.expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s job: %w", j.id, err)
+		}
+		job := &daemonJob{id: j.id, schedule: schedule, expr: j.expr, run: j.run}
+		d.jobs = append(d.jobs, job)
+
+		d.worker.Register(&worker.Job{
+			ID:       job.id,
+			Name:     job.id + "-sync",
+			Schedule: schedule,
+			Handler: func(ctx context.Context) error {
+				err := d.execute(ctx, job, TriggerSchedule, "")
+				if errors.Is(err, ErrJobRunning) {
+					logger.Warnf("Skipping scheduled %s sync: %v", job.id, err)
+					return nil
+				}
+				return err
+			},
+		})
+	}
+
+	return d, nil
+}
+
+// Run syncs on schedule while this instance is leader, until ctx is done.
+// Standby instances retry the leader lock every LeaderRetry.
+func (d *Daemon) Run(ctx context.Context) error {
+	logger.Infof("Sync daemon %s started", d.instance)
+
+	for {
+		lock, err := d.db.TryLock(ctx, leaderLockKey)
+		if err != nil {
+			logger.Errorf("Failed to take sync leader lock: %v", err)
+		}
+		if lock != nil {
+			d.lead(ctx, lock)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Infof("Sync daemon %s stopped", d.instance)
+			return nil
+		case <-time.After(d.config.LeaderRetry):
+		}
+	}
+}
+
+// lead runs the scheduled jobs until ctx is done or the lock is lost
+func (d *Daemon) lead(ctx context.Context, lock *db.Lock) {
+	defer lock.Release()
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	d.setLeader(leaderCtx)
+	logger.Infof("Sync daemon %s is now leader", d.instance)
+
+	d.worker.Start(leaderCtx)
+	if err := lock.Hold(leaderCtx, d.config.LeaderCheck); err != nil {
+		logger.Errorf("Sync daemon %s lost leadership: %v", d.instance, err)
+	}
+
+	d.setLeader(nil)
+	cancel()
+	d.worker.Stop()
+	d.manual.Wait()
+}
+
+func (d *Daemon) setLeader(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.leaderCtx = ctx
+	d.leaderSince = time.Now()
+}
+
+// Trigger starts a manual run of a job in the background
+func (d *Daemon) Trigger(jobID, adminID string) error {
+	job := d.job(jobID)
+	if job == nil {
+		return ErrUnknownJob
+	}
+
+	// Counted under mu, so a leadership ending waits for the run
+	d.mu.Lock()
+	ctx := d.leaderCtx
+	if ctx == nil {
+		d.mu.Unlock()
+		return ErrNotLeader
+	}
+	if job.running.Load() {
+		d.mu.Unlock()
+		return ErrJobRunning
+	}
+	d.manual.Add(1)
+	d.mu.Unlock()
+
+	go func() {
+		defer d.manual.Done()
+		if err := d.execute(ctx, job, TriggerManual, adminID); err != nil && !errors.Is(err, ErrJobRunning) {
+			logger.Errorf("Manual %s sync failed: %v", job.id, err)
+		}
+	}()
+
+	logger.Infof("Manual %s sync triggered by %s", job.id, adminID)
+	return nil
+}
+
+// execute runs a job and records its outcome. A job runs once at a time.
+func (d *Daemon) execute(ctx context.Context, job *daemonJob, trigger, triggeredBy string) error {
+	if !job.running.CompareAndSwap(false, true) {
+		return ErrJobRunning
+	}
+	defer job.running.Store(false)
+
+	run := &JobRun{
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Status:      JobStatusRunning,
+		Results:     make(map[string]*SyncResult),
+		StartedAt:   time.Now(),
+	}
+	d.mu.Lock()
+	job.lastRun = run
+	d.mu.Unlock()
+
+	results := make(map[string]*SyncResult)
+	err := job.run(ctx, results)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Results = results
+	run.Status = JobStatusCompleted
+	if err != nil {
+		run.Status = JobStatusFailed
+		run.Error = err.Error()
+	}
+	return err
+}
+
+// Status returns the leader state and the last run of each job
+func (d *Daemon) Status() *DaemonStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	status := &DaemonStatus{
+		Instance: d.instance,
+		Leader:   d.leaderCtx != nil,
+		Jobs:     make([]*JobStatus, 0, len(d.jobs)),
+	}
+	if status.Leader {
+		since := d.leaderSince
+		status.LeaderSince = &since
+	}
+
+	now := time.Now()
+	for _, job := range d.jobs {
+		js := &JobStatus{
+			ID:       job.id,
+			Schedule: job.expr,
+			Running:  job.running.Load(),
+		}
+		if status.Leader {
+			next := job.schedule.Next(now)
+			js.NextRun = &next
+		}
+		if job.lastRun != nil {
+			lastRun := *job.lastRun
+			js.LastRun = &lastRun
+		}
+		status.Jobs = append(status.Jobs, js)
+	}
+	return status
+}
+
+func (d *Daemon) job(id string) *daemonJob {
+	for _, job := range d.jobs {
+		if job.id == id {
+			return job
+		}
+	}
+	return nil
+}
+
+func (d *Daemon) options() SyncOptions {
+	return SyncOptions{
+		CountryCode:           d.config.CountryCode,
+		Languages:             d.config.Languages,
+		DeactivationThreshold: d.config.DeactivationThreshold,
+		// Runs cut short by a lost leadership or restart continue where they stopped
+		Resume: true,
+	}
+}
+
+func (d *Daemon) syncDestinations(ctx context.Context, results map[string]*SyncResult) error {
+	result, err := d.syncer.SyncDestinations(ctx, d.options())
+	if result != nil {
+		results["all"] = result
+	}
+	return err
+}
+
+// syncHotels syncs the hotel content changed since the last completed run
+func (d *Daemon) syncHotels(ctx context.Context, results map[string]*SyncResult) error {
+	opts := d.options()
+	opts.Incremental = true
+	return d.eachDestination(ctx, results, func(code string) (*SyncResult, error) {
+		return d.syncer.SyncHotels(ctx, code, opts)
+	})
+}
+
+// syncRooms syncs complete room catalogs, deactivating hotels no longer returned
+func (d *Daemon) syncRooms(ctx context.Context, results map[string]*SyncResult) error {
+	opts := d.options()
+	return d.eachDestination(ctx, results, func(code string) (*SyncResult, error) {
+		return d.syncer.SyncRooms(ctx, code, opts)
+	})
+}
+
+// eachDestination syncs every configured destination, carrying on past failures
+func (d *Daemon) eachDestination(ctx context.Context, results map[string]*SyncResult, syncDestination func(code string) (*SyncResult, error)) error {
+	var errs []error
+	for _, code := range d.config.Destinations {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		result, err := syncDestination(code)
+		if result != nil {
+			results[code] = result
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("destination %s: %w", code, err))
+		}
+	}
+	return errors.Join(errs...)
+}
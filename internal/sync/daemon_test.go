@@ -0,0 +1,123 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSyncer records the options of each sync and fails destination JOG
+type stubSyncer struct {
+	calls   chan string
+	options []SyncOptions
+}
+
+func (s *stubSyncer) SyncDestinations(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
+	s.options = append(s.options, opts)
+	s.calls <- JobDestinations
+	return &SyncResult{Total: 10}, nil
+}
+
+func (s *stubSyncer) SyncHotels(ctx context.Context, destinationCode string, opts SyncOptions) (*SyncResult, error) {
+	s.options = append(s.options, opts)
+	s.calls <- JobHotels
+	if destinationCode == "JOG" {
+		return nil, errors.New("HotelBeds unavailable")
+	}
+	return &SyncResult{Total: destinationHotels[destinationCode]}, nil
+}
+
+func (s *stubSyncer) SyncRooms(ctx context.Context, destinationCode string, opts SyncOptions) (*SyncResult, error) {
+	s.options = append(s.options, opts)
+	s.calls <- JobRooms
+	return &SyncResult{Total: destinationHotels[destinationCode]}, nil
+}
+
+// destinationHotels is how many hotels stubSyncer syncs per destination
+var destinationHotels = map[string]int{"DPS": 120, "JOG": 80, "LOP": 45}
+
+func newTestDaemon(t *testing.T) (*Daemon, *stubSyncer) {
+	syncer := &stubSyncer{calls: make(chan string, 10)}
+	config := DefaultDaemonConfig()
+	config.Destinations = []string{"DPS", "JOG", "LOP"}
+
+	daemon, err := NewDaemon(syncer, nil, config)
+	require.NoError(t, err)
+	return daemon, syncer
+}
+
+func TestNewDaemon_InvalidSchedule(t *testing.T) {
+	config := DefaultDaemonConfig()
+	config.RoomsSchedule = "every day"
+
+	_, err := NewDaemon(&stubSyncer{}, nil, config)
+	assert.ErrorContains(t, err, "rooms job")
+}
+
+func TestDaemon_ExecuteRecordsResultsPerDestination(t *testing.T) {
+	daemon, syncer := newTestDaemon(t)
+
+	err := daemon.execute(context.Background(), daemon.job(JobHotels), TriggerSchedule, "")
+	assert.ErrorContains(t, err, "destination JOG: HotelBeds unavailable")
+	assert.Len(t, syncer.calls, 3, "a failing destination does not stop the others")
+	assert.True(t, syncer.options[0].Incremental)
+	assert.True(t, syncer.options[0].Resume)
+
+	job := daemon.Status().Jobs[1]
+	assert.Equal(t, JobHotels, job.ID)
+	require.NotNil(t, job.LastRun)
+	assert.Equal(t, JobStatusFailed, job.LastRun.Status)
+	assert.Equal(t, TriggerSchedule, job.LastRun.Trigger)
+	assert.Equal(t, 120, job.LastRun.Results["DPS"].Total)
+	assert.Equal(t, 45, job.LastRun.Results["LOP"].Total)
+	assert.NotContains(t, job.LastRun.Results, "JOG")
+	assert.NotNil(t, job.LastRun.FinishedAt)
+}
+
+func TestDaemon_Trigger(t *testing.T) {
+	daemon, syncer := newTestDaemon(t)
+
+	assert.ErrorIs(t, daemon.Trigger("reviews", "admin-1"), ErrUnknownJob)
+	assert.ErrorIs(t, daemon.Trigger(JobRooms, "admin-1"), ErrNotLeader)
+	assert.False(t, daemon.Status().Leader)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	daemon.setLeader(ctx)
+
+	require.NoError(t, daemon.Trigger(JobRooms, "admin-1"))
+	for range 3 {
+		select {
+		case call := <-syncer.calls:
+			assert.Equal(t, JobRooms, call)
+		case <-time.After(time.Second):
+			t.Fatal("manual run did not sync every destination")
+		}
+	}
+	daemon.manual.Wait()
+
+	status := daemon.Status()
+	assert.True(t, status.Leader)
+	job := status.Jobs[2]
+	require.NotNil(t, job.LastRun)
+	assert.Equal(t, JobStatusCompleted, job.LastRun.Status)
+	assert.Equal(t, TriggerManual, job.LastRun.Trigger)
+	assert.Equal(t, "admin-1", job.LastRun.TriggeredBy)
+	assert.NotNil(t, job.NextRun)
+	assert.False(t, syncer.options[0].Incremental, "room syncs are complete runs")
+}
+
+func TestDaemon_ExecuteOnceAtATime(t *testing.T) {
+	daemon, _ := newTestDaemon(t)
+	job := daemon.job(JobDestinations)
+	job.running.Store(true)
+
+	assert.ErrorIs(t, daemon.execute(context.Background(), job, TriggerSchedule, ""), ErrJobRunning)
+
+	daemon.setLeader(context.Background())
+	assert.ErrorIs(t, daemon.Trigger(JobDestinations, "admin-1"), ErrJobRunning)
+}
@@ -0,0 +1,67 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// deactivateMissingHotels soft-deletes the active hotels of a destination
+// that a complete run did not return. Hotels last seen before the run
+// started are missing. When more than the threshold share of the
+// destination would go, nothing is deactivated and an alert is raised.
+func (s *Service) deactivateMissingHotels(ctx context.Context, run *SyncRun, opts SyncOptions, result *SyncResult) error {
+	threshold := opts.DeactivationThreshold
+	if threshold <= 0 {
+		threshold = DefaultDeactivationThreshold
+	}
+
+	var active, missing int
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE last_seen_at IS NULL OR last_seen_at < $2)
+		FROM hotels
+		WHERE provider_code = 'hotelbeds' AND destination_code = $1 AND deleted_at IS NULL
+	`, run.DestinationCode, run.StartedAt).Scan(&active, &missing)
+	if err != nil {
+		return fmt.Errorf("failed to count missing hotels: %w", err)
+	}
+	if missing == 0 {
+		return nil
+	}
+
+	if !opts.ForceDeactivation && float64(missing) > threshold*float64(active) {
+		alert := fmt.Sprintf("%d of %d active hotels in destination %s are missing from the supplier, above the %.0f%% deactivation threshold; none were deactivated",
+			missing, active, run.DestinationCode, threshold*100)
+		result.Alerts = append(result.Alerts, alert)
+		logger.Errorf("🚨 ALERT: %s", alert)
+		return nil
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		UPDATE hotels
+		SET deleted_at = NOW(), deactivation_reason = $3, updated_at = NOW()
+		WHERE provider_code = 'hotelbeds' AND destination_code = $1 AND deleted_at IS NULL
+			AND (last_seen_at IS NULL OR last_seen_at < $2)
+		RETURNING provider_hotel_id, name
+	`, run.DestinationCode, run.StartedAt, DeactivationReasonSupplierRemoved)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate missing hotels: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code, name string
+		if err := rows.Scan(&code, &name); err != nil {
+			return fmt.Errorf("failed to scan deactivated hotel: %w", err)
+		}
+		result.Deactivated++
+		logger.Warnf("Hotel %s (%s) deactivated: no longer returned by HotelBeds", code, name)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to deactivate missing hotels: %w", err)
+	}
+
+	logger.Infof("Deactivated %d of %d hotels missing from destination %s", result.Deactivated, active, run.DestinationCode)
+	return nil
+}
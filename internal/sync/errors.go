@@ -0,0 +1,14 @@
+package sync
+
+import "errors"
+
+var (
+	// ErrUnknownJob is returned when triggering a job the daemon does not run
+	ErrUnknownJob = errors.New("unknown sync job")
+
+	// ErrNotLeader is returned when triggering a job on a standby daemon
+	ErrNotLeader = errors.New("sync daemon is not the leader")
+
+	// ErrJobRunning is returned when a job is already running
+	ErrJobRunning = errors.New("sync job is already running")
+)
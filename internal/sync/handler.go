@@ -0,0 +1,67 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/admin"
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+)
+
+// Handler serves the sync daemon's status and manual runs
+type Handler struct {
+	daemon *Daemon
+}
+
+// NewHandler creates a new sync daemon handler
+func NewHandler(daemon *Daemon) *Handler {
+	return &Handler{
+		daemon: daemon,
+	}
+}
+
+// Status handles GET /status
+func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.daemon.Status())
+}
+
+// TriggerRun handles POST /runs/{job}, starting a manual run of a job
+func (h *Handler) TriggerRun(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := admin.AdminIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Admin ID not found in request")
+		return
+	}
+
+	jobID := r.PathValue("job")
+	if err := h.daemon.Trigger(jobID, adminID); err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownJob):
+			respondWithError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrNotLeader):
+			respondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, ErrJobRunning):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			logger.ErrorWithErr(err, "Failed to trigger sync")
+			respondWithError(w, http.StatusInternalServerError, "Failed to trigger sync")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]string{
+		"job":    jobID,
+		"status": "triggered",
+	})
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
+
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	respondWithJSON(w, status, map[string]string{"error": message})
+}
@@ -20,10 +20,20 @@ type Destination struct {
 
 // SyncOptions represents options for sync operation
 type SyncOptions struct {
-	CountryCode string // Filter by country code (empty = all countries)
-	Limit       int    // Max records to sync (0 = unlimited)
-	BatchSize   int    // Records per batch
-	DryRun      bool   // If true, don't save to database
+	CountryCode string    // Filter by country code (empty = all countries)
+	Limit       int       // Max records to sync (0 = unlimited)
+	BatchSize   int       // Records per batch, and hotels per page
+	DryRun      bool      // If true, don't save to database
+	Languages   []string  // Hotel content languages in sync order (empty = DefaultContentLanguages)
+	Resume      bool      // Continue the last unfinished run from its checkpoint
+	Since       time.Time // Only sync hotels updated since (zero = all)
+	Incremental bool      // Only sync hotels updated since the last completed run started
+
+	// Largest share of a destination's active hotels a complete run may
+	// deactivate (0 = DefaultDeactivationThreshold). Larger drops are
+	// alerted instead, unless ForceDeactivation is set.
+	DeactivationThreshold float64
+	ForceDeactivation     bool
 }
 
 // SyncResult represents the result of a sync operation
@@ -33,8 +43,10 @@ type SyncResult struct {
 	Updated   int           `json:"updated"`
 	Failed    int           `json:"failed"`
 	Skipped   int           `json:"skipped"`
+	Deactivated int         `json:"deactivated"`
 	Duration  time.Duration `json:"duration"`
 	Errors    []SyncError   `json:"errors,omitempty"`
+	Alerts    []string      `json:"alerts,omitempty"`
 }
 
 // SyncError represents an error during sync
@@ -53,17 +65,126 @@ type Hotel struct {
 	Name             string    `db:"name" json:"name"`
 	Description      string    `db:"description" json:"description"`
 	StarRating       float64   `db:"star_rating" json:"star_rating"`
+	PropertyType     string    `db:"property_type" json:"property_type,omitempty"` // HotelBeds accommodation type
 	CountryCode      string    `db:"country_code" json:"country_code"`
 	City             string    `db:"city" json:"city"`
 	Address          string    `db:"address" json:"address"`
 	PostalCode       string    `db:"postal_code" json:"postal_code,omitempty"`
+	Phone            string    `db:"phone" json:"phone,omitempty"`
 	Latitude         *float64  `db:"latitude" json:"latitude,omitempty"`
 	Longitude        *float64  `db:"longitude" json:"longitude,omitempty"`
 	Images           []byte    `db:"images" json:"-"`          // JSONB as byte array
 	Amenities        []byte    `db:"amenities" json:"-"`       // JSONB as byte array
-	DestinationCode  int       `db:"-" json:"-"`              // HotelBeds destination code (not in DB)
+	DestinationCode  string    `db:"destination_code" json:"-"` // HotelBeds destination code, e.g. "DPS"
 	HotelbedsData    []byte    `db:"-" json:"-"`              // Raw HotelBeds JSON
 	SyncedAt         time.Time `db:"-" json:"-"`              // Not in DB table
 	CreatedAt        time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt        time.Time `db:"updated_at" json:"updated_at"`
 }
+
+// Content languages, as ISO 639-1 codes
+const (
+	LanguageIndonesian = "id"
+	LanguageEnglish    = "en"
+
+	// BaseLanguage is the language of the hotels row and room names. It is
+	// fetched in every run, also when it is not one of the content languages.
+	BaseLanguage = LanguageEnglish
+)
+
+// DefaultContentLanguages are synced in order, Indonesian first
+var DefaultContentLanguages = []string{LanguageIndonesian, LanguageEnglish}
+
+// hotelbedsLanguages maps content languages to HotelBeds language codes
+var hotelbedsLanguages = map[string]string{
+	LanguageIndonesian: "IND",
+	LanguageEnglish:    "ENG",
+}
+
+// HotelContent is a hotel's description, amenities, images and policies in
+// one language
+type HotelContent struct {
+	HotelID     string `db:"hotel_id"`
+	Language    string `db:"language"` // ISO 639-1
+	Name        string `db:"name"`
+	Description string `db:"description"`
+	Amenities   []byte `db:"amenities"` // JSONB array of names
+	Images      []byte `db:"images"`    // JSONB array of images
+	Policies    []byte `db:"policies"`  // JSONB object
+}
+
+// Room is a room type in a hotel's synced room catalog
+type Room struct {
+	HotelID          string `db:"hotel_id"`
+	ProviderRoomCode string `db:"provider_room_code"` // HotelBeds room code
+	Name             string `db:"name"`
+	RoomType         string `db:"room_type"`
+	MaxOccupancy     int    `db:"max_occupancy"`
+	MaxAdults        int    `db:"max_adults"`
+	MaxChildren      int    `db:"max_children"`
+	Beds             string `db:"beds"` // e.g. "1 Double bed"
+	NumberOfBeds     int    `db:"number_of_beds"`
+	Amenities        []byte `db:"amenities"` // JSONB array of names
+	Images           []byte `db:"images"`    // JSONB array of images
+}
+
+// Sync run kinds
+const (
+	RunKindDestinations = "DESTINATIONS"
+	RunKindHotels       = "HOTELS"
+	RunKindRooms        = "ROOMS"
+)
+
+// Sync run statuses
+const (
+	RunStatusRunning   = "RUNNING"
+	RunStatusCompleted = "COMPLETED"
+	RunStatusFailed    = "FAILED"
+)
+
+// DefaultDeactivationThreshold is the share of a destination's hotels a run
+// may deactivate before it alerts instead
+const DefaultDeactivationThreshold = 0.1
+
+// DeactivationReasonSupplierRemoved marks hotels the supplier no longer
+// returns. They are reactivated when it returns them again.
+const DeactivationReasonSupplierRemoved = "SUPPLIER_REMOVED"
+
+// SyncRun is a persisted sync run. Unfinished runs resume from NextOffset.
+type SyncRun struct {
+	ID              string     `db:"id" json:"id"`
+	Kind            string     `db:"kind" json:"kind"`
+	DestinationCode string     `db:"destination_code" json:"destination_code,omitempty"`
+	Status          string     `db:"status" json:"status"`
+	Since           *time.Time `db:"since" json:"since,omitempty"`
+	NextOffset      int        `db:"next_offset" json:"next_offset"`
+	Total           int        `db:"total" json:"total"`
+	Inserted        int        `db:"inserted" json:"inserted"`
+	Updated         int        `db:"updated" json:"updated"`
+	Failed          int        `db:"failed" json:"failed"`
+	Deactivated     int        `db:"deactivated" json:"deactivated"`
+	Error           string     `db:"error" json:"error,omitempty"`
+	StartedAt       time.Time  `db:"started_at" json:"started_at"`
+	FinishedAt      *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+}
+
+// record counts a synced record as inserted or updated
+func (r *SyncResult) record(inserted bool) {
+	if inserted {
+		r.Inserted++
+	} else {
+		r.Updated++
+	}
+	r.Total++
+}
+
+// fail counts a record that could not be synced
+func (r *SyncResult) fail(code, record string, err error) {
+	r.Failed++
+	r.Errors = append(r.Errors, SyncError{
+		Code:        code,
+		Message:     err.Error(),
+		Record:      record,
+		Recoverable: true,
+	})
+}
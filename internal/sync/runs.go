@@ -0,0 +1,162 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/jackc/pgx/v5"
+)
+
+// beginRun starts a sync run, or resumes the last unfinished one when
+// opts.Resume is set. A resumed run carries its counters into result.
+// Dry runs are not persisted.
+func (s *Service) beginRun(ctx context.Context, kind, destinationCode string, opts SyncOptions, result *SyncResult) (*SyncRun, error) {
+	if opts.DryRun {
+		run := &SyncRun{Kind: kind, DestinationCode: destinationCode, Status: RunStatusRunning}
+		if !opts.Since.IsZero() {
+			run.Since = &opts.Since
+		}
+		return run, nil
+	}
+
+	if opts.Resume {
+		run, err := s.unfinishedRun(ctx, kind, destinationCode)
+		if err != nil {
+			return nil, err
+		}
+		if run != nil {
+			if _, err := s.db.Pool.Exec(ctx,
+				`UPDATE sync_runs SET status = $2, error = NULL, updated_at = NOW() WHERE id = $1`,
+				run.ID, RunStatusRunning,
+			); err != nil {
+				return nil, fmt.Errorf("failed to resume sync run: %w", err)
+			}
+			run.Status = RunStatusRunning
+			result.Total, result.Inserted, result.Updated, result.Failed = run.Total, run.Inserted, run.Updated, run.Failed
+
+			logger.Infof("Resuming %s sync run %s at offset %d", kind, run.ID, run.NextOffset)
+			return run, nil
+		}
+		logger.Infof("No unfinished %s sync run to resume, starting a new one", kind)
+	}
+
+	run := &SyncRun{Kind: kind, DestinationCode: destinationCode, Status: RunStatusRunning}
+	if !opts.Since.IsZero() {
+		run.Since = &opts.Since
+	} else if opts.Incremental {
+		watermark, err := s.watermark(ctx, kind, destinationCode)
+		if err != nil {
+			return nil, err
+		}
+		run.Since = watermark
+	}
+
+	err := s.db.Pool.QueryRow(ctx, `
+		INSERT INTO sync_runs (kind, destination_code, status, since)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, started_at
+	`, run.Kind, run.DestinationCode, run.Status, run.Since).Scan(&run.ID, &run.StartedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync run: %w", err)
+	}
+
+	return run, nil
+}
+
+// unfinishedRun returns the latest running or failed run, or nil
+func (s *Service) unfinishedRun(ctx context.Context, kind, destinationCode string) (*SyncRun, error) {
+	query := `
+		SELECT id, kind, destination_code, status, since, next_offset,
+			total, inserted, updated, failed, started_at
+		FROM sync_runs
+		WHERE kind = $1 AND destination_code = $2 AND status IN ($3, $4)
+		ORDER BY started_at DESC
+		LIMIT 1
+	`
+
+	var run SyncRun
+	err := s.db.Pool.QueryRow(ctx, query, kind, destinationCode, RunStatusRunning, RunStatusFailed).Scan(
+		&run.ID, &run.Kind, &run.DestinationCode, &run.Status, &run.Since, &run.NextOffset,
+		&run.Total, &run.Inserted, &run.Updated, &run.Failed, &run.StartedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get unfinished sync run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// watermark returns when the last completed run started, or nil when there
+// is none. Changes made during that run are fetched again, never missed.
+func (s *Service) watermark(ctx context.Context, kind, destinationCode string) (*time.Time, error) {
+	query := `
+		SELECT started_at
+		FROM sync_runs
+		WHERE kind = $1 AND destination_code = $2 AND status = $3
+		ORDER BY started_at DESC
+		LIMIT 1
+	`
+
+	var startedAt time.Time
+	err := s.db.Pool.QueryRow(ctx, query, kind, destinationCode, RunStatusCompleted).Scan(&startedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get sync watermark: %w", err)
+	}
+
+	return &startedAt, nil
+}
+
+// checkpoint records the progress of a run, so it can resume at offset
+func (s *Service) checkpoint(ctx context.Context, run *SyncRun, offset int, result *SyncResult) error {
+	run.NextOffset = offset
+	if run.ID == "" {
+		return nil // Dry run
+	}
+
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE sync_runs
+		SET next_offset = $2, total = $3, inserted = $4, updated = $5, failed = $6, updated_at = NOW()
+		WHERE id = $1
+	`, run.ID, offset, result.Total, result.Inserted, result.Updated, result.Failed)
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint sync run: %w", err)
+	}
+
+	return nil
+}
+
+// finishRun marks a run completed, or failed with runErr. It is recorded
+// even when ctx was cancelled, so interrupted runs can be resumed.
+func (s *Service) finishRun(ctx context.Context, run *SyncRun, result *SyncResult, runErr error) {
+	run.Status = RunStatusCompleted
+	run.Deactivated = result.Deactivated
+	if runErr != nil {
+		run.Status = RunStatusFailed
+		run.Error = runErr.Error()
+	}
+	if run.ID == "" {
+		return // Dry run
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE sync_runs
+		SET status = $2, error = NULLIF($3, ''), total = $4, inserted = $5, updated = $6, failed = $7,
+			deactivated = $8, finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, run.ID, run.Status, run.Error, result.Total, result.Inserted, result.Updated, result.Failed, result.Deactivated)
+	if err != nil {
+		logger.Errorf("Failed to finish sync run %s: %v", run.ID, err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
@@ -25,7 +27,8 @@ func NewService(database *db.DB, hbClient *hotelbeds.Client) *Service {
 	}
 }
 
-// SyncDestinations syncs destinations from HotelBeds to local database
+// SyncDestinations syncs destinations from HotelBeds to local database. The
+// run is checkpointed after each page, so an interrupted run can resume.
 func (s *Service) SyncDestinations(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
 	startTime := time.Now()
 	result := &SyncResult{
@@ -37,85 +40,70 @@ func (s *Service) SyncDestinations(ctx context.Context, opts SyncOptions) (*Sync
 		opts.BatchSize = 100
 	}
 
-	logger.Infof("Starting destination sync: country=%s, limit=%d, dry_run=%v",
-		opts.CountryCode, opts.Limit, opts.DryRun)
-
-	// Fetch destinations from HotelBeds
-	var destinations []hotelbeds.Destination
-	var err error
-
-	if opts.Limit > 0 {
-		// Fetch with limit
-		destinations, err = s.hotelbeds.GetDestinations(ctx, "", 0, opts.Limit)
-	} else {
-		// Fetch all destinations
-		destinations, err = s.hotelbeds.GetAllDestinations(ctx, "")
-	}
+	logger.Infof("Starting destination sync: country=%s, limit=%d, resume=%v, dry_run=%v",
+		opts.CountryCode, opts.Limit, opts.Resume, opts.DryRun)
 
+	run, err := s.beginRun(ctx, RunKindDestinations, "", opts, result)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch destinations from HotelBeds: %w", err)
-	}
-
-	// Filter by country code if specified (API doesn't support country filter)
-	if opts.CountryCode != "" {
-		filtered := make([]hotelbeds.Destination, 0)
-		for _, dest := range destinations {
-			if dest.CountryCode == opts.CountryCode {
-				filtered = append(filtered, dest)
-			}
-		}
-		destinations = filtered
+		return nil, err
 	}
 
-	logger.Infof("Fetched %d destinations from HotelBeds (filtered to country=%s)", len(destinations), opts.CountryCode)
-
-	// Process each destination
-	for _, dest := range destinations {
+	offset := run.NextOffset
+	for {
 		select {
 		case <-ctx.Done():
-			logger.Warnf("Sync cancelled by context")
+			logger.Warnf("Sync cancelled by context at offset %d", offset)
+			s.finishRun(ctx, run, result, ctx.Err())
 			return result, ctx.Err()
 		default:
 		}
 
-		// Convert to our model
-		destination := s.convertToDestination(dest)
-
-		// Dry run - skip database operations
-		if opts.DryRun {
-			result.Skipped++
-			result.Total++
-			continue
+		pageSize := hotelbeds.MaxContentPage
+		if opts.Limit > 0 {
+			pageSize = min(pageSize, opts.Limit-offset)
+			if pageSize <= 0 {
+				break
+			}
 		}
 
-		// Upsert to database
-		inserted, err := s.upsertDestination(ctx, &destination)
+		// Fetch a page of destinations from HotelBeds
+		page, err := s.hotelbeds.GetDestinations(ctx, "", offset, pageSize)
 		if err != nil {
-			result.Failed++
-			result.Errors = append(result.Errors, SyncError{
-				Code:        dest.Code,
-				Message:     err.Error(),
-				Record:      dest.Name.Content,
-				Recoverable: true,
-			})
-			logger.Errorf("Failed to sync destination %s (%s): %v", dest.Code, dest.Name.Content, err)
-			continue
+			err = fmt.Errorf("failed to fetch destinations from HotelBeds: %w", err)
+			s.finishRun(ctx, run, result, err)
+			return nil, err
 		}
 
-		if inserted {
-			result.Inserted++
+		// Filter by country code if specified (API doesn't support country filter)
+		destinations := make([]Destination, 0, len(page))
+		for _, dest := range page {
+			if opts.CountryCode == "" || dest.CountryCode == opts.CountryCode {
+				destinations = append(destinations, s.convertToDestination(dest))
+			}
+		}
+
+		if opts.DryRun {
+			result.Skipped += len(destinations)
+			result.Total += len(destinations)
 		} else {
-			result.Updated++
+			for batch := range slices.Chunk(destinations, opts.BatchSize) {
+				s.upsertDestinations(ctx, batch, result)
+			}
+		}
+
+		offset += len(page)
+		if err := s.checkpoint(ctx, run, offset, result); err != nil {
+			logger.Errorf("%v", err)
 		}
 
-		result.Total++
+		logger.Infof("Sync progress: %d destinations fetched, %d synced", offset, result.Total)
 
-		// Log progress every 100 records
-		if result.Total%100 == 0 {
-			logger.Infof("Sync progress: %d/%d destinations processed", result.Total, len(destinations))
+		if len(page) < pageSize {
+			break
 		}
 	}
 
+	s.finishRun(ctx, run, result, nil)
 	result.Duration = time.Since(startTime)
 
 	logger.Infof("Destination sync completed: total=%d, inserted=%d, updated=%d, failed=%d, duration=%v",
@@ -124,44 +112,6 @@ func (s *Service) SyncDestinations(ctx context.Context, opts SyncOptions) (*Sync
 	return result, nil
 }
 
-// upsertDestination inserts or updates a destination in the database
-func (s *Service) upsertDestination(ctx context.Context, dest *Destination) (bool, error) {
-	query := `
-		INSERT INTO destinations (code, name, country_code, country_name, type, parent_code, latitude, longitude, hotelbeds_data)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		ON CONFLICT (code) DO UPDATE SET
-			name = EXCLUDED.name,
-			country_code = EXCLUDED.country_code,
-			country_name = EXCLUDED.country_name,
-			type = EXCLUDED.type,
-			parent_code = EXCLUDED.parent_code,
-			latitude = EXCLUDED.latitude,
-			longitude = EXCLUDED.longitude,
-			hotelbeds_data = EXCLUDED.hotelbeds_data,
-			updated_at = NOW()
-		RETURNING (xmax = 0) AS inserted
-	`
-
-	var inserted bool
-	err := s.db.Pool.QueryRow(ctx, query,
-		dest.Code,
-		dest.Name,
-		dest.CountryCode,
-		dest.CountryName,
-		dest.Type,
-		dest.ParentCode,
-		dest.Latitude,
-		dest.Longitude,
-		dest.HotelbedsData,
-	).Scan(&inserted)
-
-	if err != nil {
-		return false, fmt.Errorf("failed to upsert destination: %w", err)
-	}
-
-	return inserted, nil
-}
-
 // convertToDestination converts HotelBeds destination to our model
 func (s *Service) convertToDestination(hbDest hotelbeds.Destination) Destination {
 	// Extract name content from DestinationName object
@@ -197,8 +147,22 @@ func (s *Service) convertToDestination(hbDest hotelbeds.Destination) Destination
 	}
 }
 
-// SyncHotels syncs hotels from HotelBeds to local database
-func (s *Service) SyncHotels(ctx context.Context, destinationCode int, opts SyncOptions) (*SyncResult, error) {
+// SyncHotels syncs hotels from HotelBeds to local database, with their
+// content in each of opts.Languages. Hotels are fetched a page at a time in
+// every language, and the run is checkpointed after each page, so an
+// interrupted run can resume.
+func (s *Service) SyncHotels(ctx context.Context, destinationCode string, opts SyncOptions) (*SyncResult, error) {
+	return s.syncHotels(ctx, RunKindHotels, destinationCode, opts)
+}
+
+// SyncRooms refreshes the room catalogs of a destination's hotels. Hotels
+// are fetched in BaseLanguage only, and their content is left alone.
+func (s *Service) SyncRooms(ctx context.Context, destinationCode string, opts SyncOptions) (*SyncResult, error) {
+	return s.syncHotels(ctx, RunKindRooms, destinationCode, opts)
+}
+
+// syncHotels runs a hotel or room sync of a destination
+func (s *Service) syncHotels(ctx context.Context, kind, destinationCode string, opts SyncOptions) (*SyncResult, error) {
 	startTime := time.Now()
 	result := &SyncResult{
 		Errors: []SyncError{},
@@ -208,134 +172,133 @@ func (s *Service) SyncHotels(ctx context.Context, destinationCode int, opts Sync
 	if opts.BatchSize == 0 {
 		opts.BatchSize = 100
 	}
+	languages := opts.Languages
+	if len(languages) == 0 {
+		languages = DefaultContentLanguages
+	}
+	for _, language := range languages {
+		if _, ok := hotelbedsLanguages[language]; !ok {
+			return nil, fmt.Errorf("unsupported content language: %s", language)
+		}
+	}
 
-	logger.Infof("Starting hotel sync: destination=%d, limit=%d, dry_run=%v",
-		destinationCode, opts.Limit, opts.DryRun)
-
-	// Fetch hotels from HotelBeds
-	var hotels []hotelbeds.ContentHotelContent
-	var err error
-
-	destCodeStr := fmt.Sprintf("%d", destinationCode)
-
-	if opts.Limit > 0 {
-		// Fetch with limit
-		hotels, err = s.hotelbeds.GetHotels(ctx, destCodeStr, 0, opts.Limit)
-	} else {
-		// Fetch all hotels
-		hotels, err = s.hotelbeds.GetAllHotels(ctx, destCodeStr)
+	// The hotels row and room names are stored once, in BaseLanguage
+	fetched := languages
+	if !slices.Contains(fetched, BaseLanguage) {
+		fetched = append([]string{BaseLanguage}, languages...)
+	}
+	if kind == RunKindRooms {
+		languages = nil
+		fetched = []string{BaseLanguage}
 	}
 
+	run, err := s.beginRun(ctx, kind, destinationCode, opts, result)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch hotels from HotelBeds: %w", err)
+		return nil, err
+	}
+	var since time.Time
+	if run.Since != nil {
+		since = *run.Since
 	}
 
-	logger.Infof("Fetched %d hotels from HotelBeds (destination=%s)", len(hotels), destCodeStr)
+	logger.Infof("Starting sync: kind=%s, destination=%s, languages=%v, since=%s, limit=%d, dry_run=%v",
+		kind, destinationCode, fetched, since.Format("2006-01-02"), opts.Limit, opts.DryRun)
 
-	// Process each hotel
-	for _, hotel := range hotels {
+	offset := run.NextOffset
+	for {
 		select {
 		case <-ctx.Done():
-			logger.Warnf("Sync cancelled by context")
+			logger.Warnf("Sync cancelled by context at offset %d", offset)
+			s.finishRun(ctx, run, result, ctx.Err())
 			return result, ctx.Err()
 		default:
 		}
 
-		// Convert to our model
-		h := s.convertToHotel(hotel)
-
-		// Dry run - skip database operations
-		if opts.DryRun {
-			result.Skipped++
-			result.Total++
-			continue
+		pageSize := opts.BatchSize
+		if opts.Limit > 0 {
+			pageSize = min(pageSize, opts.Limit-offset)
+			if pageSize <= 0 {
+				break
+			}
 		}
 
-		// Upsert to database
-		inserted, err := s.upsertHotel(ctx, &h)
-		if err != nil {
-			result.Failed++
-			result.Errors = append(result.Errors, SyncError{
-				Code:        hotel.Code,
-				Message:     err.Error(),
-				Record:      hotel.Name,
-				Recoverable: true,
+		// Fetch the page in every language; the base language is the hotel list
+		pages := make(map[string][]hotelbeds.ContentHotelContent, len(fetched))
+		for _, language := range fetched {
+			hotels, err := s.hotelbeds.GetHotels(ctx, &hotelbeds.ContentHotelsRequest{
+				DestinationCode: destinationCode,
+				Language:        hotelbedsLanguages[language],
+				LastUpdateTime:  since,
+				Offset:          offset,
+				Limit:           pageSize,
 			})
-			logger.Errorf("Failed to sync hotel %s (%s): %v", hotel.Code, hotel.Name, err)
-			continue
+			if err != nil {
+				err = fmt.Errorf("failed to fetch %s hotels from HotelBeds: %w", language, err)
+				s.finishRun(ctx, run, result, err)
+				return nil, err
+			}
+			pages[language] = hotels
 		}
+		base := pages[BaseLanguage]
 
-		if inserted {
-			result.Inserted++
+		if opts.DryRun {
+			result.Skipped += len(base)
+			result.Total += len(base)
 		} else {
-			result.Updated++
+			hotels := make([]Hotel, 0, len(base))
+			for _, hotel := range base {
+				h := s.convertToHotel(hotel)
+				h.DestinationCode = destinationCode
+				hotels = append(hotels, h)
+			}
+			hotelIDs := s.upsertHotels(ctx, hotels, result)
+
+			var contents []HotelContent
+			var rooms []Room
+			for _, language := range languages {
+				for _, hotel := range pages[language] {
+					if hotelID, ok := hotelIDs[hotel.Code]; ok {
+						contents = append(contents, s.convertToHotelContent(hotelID, language, hotel))
+					}
+				}
+			}
+			for _, hotel := range base {
+				if hotelID, ok := hotelIDs[hotel.Code]; ok {
+					rooms = append(rooms, s.convertToRooms(hotelID, hotel)...)
+				}
+			}
+			s.upsertHotelDetails(ctx, contents, rooms, result)
+		}
+
+		offset += len(base)
+		if err := s.checkpoint(ctx, run, offset, result); err != nil {
+			logger.Errorf("%v", err)
 		}
 
-		result.Total++
+		logger.Infof("Sync progress: %d hotels processed", offset)
+
+		if len(base) < pageSize {
+			break
+		}
+	}
 
-		// Log progress every 50 records (hotels sync is slower)
-		if result.Total%50 == 0 {
-			logger.Infof("Sync progress: %d/%d hotels processed", result.Total, len(hotels))
+	// Only a complete run shows which hotels the supplier removed
+	complete := opts.Limit == 0 && run.Since == nil && result.Failed == 0
+	if complete && !opts.DryRun {
+		if err := s.deactivateMissingHotels(ctx, run, opts, result); err != nil {
+			logger.Errorf("%v", err)
 		}
 	}
 
+	s.finishRun(ctx, run, result, nil)
 	result.Duration = time.Since(startTime)
 
-	logger.Infof("Hotel sync completed: total=%d, inserted=%d, updated=%d, failed=%d, duration=%v",
-		result.Total, result.Inserted, result.Updated, result.Failed, result.Duration)
+	logger.Infof("Sync completed: kind=%s, total=%d, inserted=%d, updated=%d, failed=%d, deactivated=%d, duration=%v",
+		kind, result.Total, result.Inserted, result.Updated, result.Failed, result.Deactivated, result.Duration)
 
 	return result, nil
 }
 
-// upsertHotel inserts or updates a hotel in the database
-func (s *Service) upsertHotel(ctx context.Context, hotel *Hotel) (bool, error) {
-	query := `
-		INSERT INTO hotels (
-			provider_code, provider_hotel_id, name, description, star_rating,
-			country_code, city, address, postal_code, latitude, longitude,
-			images, amenities
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
-		ON CONFLICT (provider_code, provider_hotel_id) DO UPDATE SET
-			name = EXCLUDED.name,
-			description = EXCLUDED.description,
-			star_rating = EXCLUDED.star_rating,
-			country_code = EXCLUDED.country_code,
-			city = EXCLUDED.city,
-			address = EXCLUDED.address,
-			postal_code = EXCLUDED.postal_code,
-			latitude = EXCLUDED.latitude,
-			longitude = EXCLUDED.longitude,
-			images = EXCLUDED.images,
-			amenities = EXCLUDED.amenities,
-			updated_at = NOW()
-		RETURNING (xmax = 0) AS inserted
-	`
-
-	var inserted bool
-	err := s.db.Pool.QueryRow(ctx, query,
-		hotel.ProviderCode,
-		hotel.ProviderHotelID,
-		hotel.Name,
-		hotel.Description,
-		hotel.StarRating,
-		hotel.CountryCode,
-		hotel.City,
-		hotel.Address,
-		hotel.PostalCode,
-		hotel.Latitude,
-		hotel.Longitude,
-		hotel.Images,
-		hotel.Amenities,
-	).Scan(&inserted)
-
-	if err != nil {
-		return false, fmt.Errorf("failed to upsert hotel: %w", err)
-	}
-
-	return inserted, nil
-}
-
 // convertToHotel converts HotelBeds hotel to our model
 func (s *Service) convertToHotel(hbHotel hotelbeds.ContentHotelContent) Hotel {
 	// Extract star rating from category code
@@ -364,7 +327,7 @@ func (s *Service) convertToHotel(hbHotel hotelbeds.ContentHotelContent) Hotel {
 			"type":          img.ImageType,
 			"path":          img.Path,
 			"visual_order":  img.VisualOrder,
-			"url":           fmt.Sprintf("https://photos.hotelbeds.com/giata/%s", img.Path),
+			"url":           hotelbedsImageURL(img.Path),
 		})
 	}
 	imagesJSON, _ := json.Marshal(images)
@@ -394,18 +357,25 @@ func (s *Service) convertToHotel(hbHotel hotelbeds.ContentHotelContent) Hotel {
 		city = hbHotel.Destination.Name
 	}
 
+	var latitude, longitude *float64
+	if c := hbHotel.Coordinates; c != nil {
+		latitude, longitude = &c.Latitude, &c.Longitude
+	}
+
 	return Hotel{
 		ProviderCode:    "hotelbeds",
 		ProviderHotelID: hbHotel.Code,
 		Name:            hbHotel.Name,
 		Description:     description,
 		StarRating:      starRating,
+		PropertyType:    hbHotel.AccommodationTypeCode,
 		CountryCode:     "", // Will need to be looked up or passed in
 		City:            city,
 		Address:         hbHotel.Address,
 		PostalCode:      hbHotel.PostalCode,
-		Latitude:        nil, // Not provided in Content API list endpoint
-		Longitude:       nil, // Not provided in Content API list endpoint
+		Phone:           hbHotel.PhoneNumber,
+		Latitude:        latitude,
+		Longitude:       longitude,
 		Images:          imagesJSON,
 		Amenities:       amenitiesJSON,
 		DestinationCode: hbHotel.Destination.Code,
@@ -415,3 +385,125 @@ func (s *Service) convertToHotel(hbHotel hotelbeds.ContentHotelContent) Hotel {
 		UpdatedAt:       time.Now(),
 	}
 }
+
+// convertToHotelContent converts a HotelBeds hotel fetched in language to
+// its content. Images and amenities use the shapes the hotel API serves.
+func (s *Service) convertToHotelContent(hotelID, language string, hbHotel hotelbeds.ContentHotelContent) HotelContent {
+	amenities := make([]string, 0)
+	for _, fg := range hbHotel.Facilities {
+		for _, f := range fg.Facilities {
+			if f.Content.ContentText != "" {
+				amenities = append(amenities, f.Content.ContentText)
+			}
+		}
+	}
+	amenitiesJSON, _ := json.Marshal(amenities)
+
+	images := make([]map[string]interface{}, 0, len(hbHotel.Images))
+	for _, img := range hbHotel.Images {
+		images = append(images, map[string]interface{}{
+			"url":        hotelbedsImageURL(img.Path),
+			"type":       img.ImageType,
+			"sort_order": img.VisualOrder,
+		})
+	}
+	imagesJSON, _ := json.Marshal(images)
+
+	policiesJSON, _ := json.Marshal(map[string]string{
+		"check_in_time":  hbHotel.Terms.InDate,
+		"check_out_time": hbHotel.Terms.OutDate,
+	})
+
+	return HotelContent{
+		HotelID:     hotelID,
+		Language:    language,
+		Name:        hbHotel.Name,
+		Description: hbHotel.Description.ContentText,
+		Amenities:   amenitiesJSON,
+		Images:      imagesJSON,
+		Policies:    policiesJSON,
+	}
+}
+
+// convertToRooms converts the room types of a HotelBeds hotel to its catalog
+func (s *Service) convertToRooms(hotelID string, hbHotel hotelbeds.ContentHotelContent) []Room {
+	// Room images are hotel images tagged with the room code
+	images := make(map[string][]map[string]interface{})
+	for _, img := range hbHotel.Images {
+		if img.RoomCode == "" {
+			continue
+		}
+		images[img.RoomCode] = append(images[img.RoomCode], map[string]interface{}{
+			"url":        hotelbedsImageURL(img.Path),
+			"type":       img.ImageType,
+			"sort_order": img.VisualOrder,
+		})
+	}
+
+	rooms := make([]Room, 0, len(hbHotel.RoomDetails))
+	for _, r := range hbHotel.RoomDetails {
+		amenities := make([]string, 0, len(r.RoomFacilities))
+		for _, f := range r.RoomFacilities {
+			if f.Description.ContentText != "" {
+				amenities = append(amenities, f.Description.ContentText)
+			}
+		}
+		amenitiesJSON, _ := json.Marshal(amenities)
+
+		roomImages := images[r.RoomCode]
+		if roomImages == nil {
+			roomImages = make([]map[string]interface{}, 0)
+		}
+		imagesJSON, _ := json.Marshal(roomImages)
+
+		beds, numberOfBeds := roomBeds(r.RoomStays)
+
+		name := r.Description
+		if name == "" {
+			name = r.RoomCode
+		}
+
+		rooms = append(rooms, Room{
+			HotelID:          hotelID,
+			ProviderRoomCode: r.RoomCode,
+			Name:             name,
+			RoomType:         r.RoomType,
+			MaxOccupancy:     r.MaxPax,
+			MaxAdults:        r.MaxAdults,
+			MaxChildren:      r.MaxChildren,
+			Beds:             beds,
+			NumberOfBeds:     numberOfBeds,
+			Amenities:        amenitiesJSON,
+			Images:           imagesJSON,
+		})
+	}
+
+	return rooms
+}
+
+// roomBeds describes the beds of a room's BED stays, e.g. "1 Double bed, 2 Single bed"
+func roomBeds(stays []hotelbeds.ContentRoomStay) (string, int) {
+	var beds []string
+	total := 0
+	for _, stay := range stays {
+		if stay.StayType != "BED" {
+			continue
+		}
+		for _, f := range stay.RoomStayFacilities {
+			number := f.Number
+			if number == 0 {
+				number = 1
+			}
+			total += number
+			if f.Description.ContentText != "" {
+				beds = append(beds, fmt.Sprintf("%d %s", number, f.Description.ContentText))
+			}
+		}
+	}
+	return strings.Join(beds, ", "), total
+}
+
+// hotelbedsImageURL returns the URL of a HotelBeds image path
+func hotelbedsImageURL(path string) string {
+	return fmt.Sprintf("https://photos.hotelbeds.com/giata/%s", path)
+}
@@ -0,0 +1,81 @@
+package sync
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/hotelbeds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertToRooms(t *testing.T) {
+	s := &Service{}
+	hbHotel := hotelbeds.ContentHotelContent{
+		Code: "12345",
+		Images: []hotelbeds.ContentHotelImage{
+			{ImageType: "GEN", Path: "12/123456a.jpg", VisualOrder: 1},
+			{ImageType: "HAB", Path: "12/123456b.jpg", VisualOrder: 2, RoomCode: "DBL.ST"},
+		},
+		RoomDetails: []hotelbeds.ContentRoomDetail{
+			{
+				RoomCode:    "DBL.ST",
+				RoomType:    "DBL",
+				Description: "DOUBLE STANDARD",
+				MaxPax:      3,
+				MaxAdults:   2,
+				MaxChildren: 1,
+				RoomFacilities: []hotelbeds.ContentRoomFacility{
+					{FacilityCode: 220, Description: hotelbeds.ContentContent{ContentText: "Air conditioning"}},
+				},
+				RoomStays: []hotelbeds.ContentRoomStay{
+					{StayType: "BED", RoomStayFacilities: []hotelbeds.ContentRoomFacility{
+						{Number: 1, Description: hotelbeds.ContentContent{ContentText: "Double bed"}},
+						{Number: 2, Description: hotelbeds.ContentContent{ContentText: "Single bed"}},
+					}},
+					{StayType: "LIVING", RoomStayFacilities: []hotelbeds.ContentRoomFacility{
+						{Number: 1, Description: hotelbeds.ContentContent{ContentText: "Sofa"}},
+					}},
+				},
+			},
+			{RoomCode: "SGL.ST", MaxPax: 1},
+		},
+	}
+
+	rooms := s.convertToRooms("hotel-1", hbHotel)
+
+	require.Len(t, rooms, 2)
+	double := rooms[0]
+	assert.Equal(t, "hotel-1", double.HotelID)
+	assert.Equal(t, "DBL.ST", double.ProviderRoomCode)
+	assert.Equal(t, "DOUBLE STANDARD", double.Name)
+	assert.Equal(t, 3, double.MaxOccupancy)
+	assert.Equal(t, "1 Double bed, 2 Single bed", double.Beds)
+	assert.Equal(t, 3, double.NumberOfBeds)
+	assert.JSONEq(t, `["Air conditioning"]`, string(double.Amenities))
+
+	var images []map[string]interface{}
+	require.NoError(t, json.Unmarshal(double.Images, &images))
+	require.Len(t, images, 1, "only images tagged with the room code")
+	assert.Equal(t, "https://photos.hotelbeds.com/giata/12/123456b.jpg", images[0]["url"])
+
+	single := rooms[1]
+	assert.Equal(t, "SGL.ST", single.Name, "falls back to the room code")
+	assert.JSONEq(t, `[]`, string(single.Images))
+}
+
+func TestConvertToHotel_KeepsDestinationCode(t *testing.T) {
+	s := &Service{}
+	h := s.convertToHotel(hotelbeds.ContentHotelContent{
+		Code:        "12345",
+		Destination: hotelbeds.ContentDestinationCode{Code: "DPS", Name: "Bali"},
+	})
+
+	// HotelBeds destination codes are letters, stored as they are
+	assert.Equal(t, "DPS", h.DestinationCode)
+	assert.Contains(t, h.upsertArgs(), "DPS")
+
+	var content hotelbeds.ContentHotelContent
+	require.NoError(t, json.Unmarshal([]byte(`{"code": "12345", "destination": {"code": "JOG"}}`), &content))
+	assert.Equal(t, "JOG", content.Destination.Code)
+}
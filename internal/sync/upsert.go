@@ -0,0 +1,295 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ekonugroho98/be-bookingkuy/internal/shared/logger"
+	"github.com/jackc/pgx/v5"
+)
+
+// Upserts are sent in batches of one round trip. A batch runs in a single
+// implicit transaction, so when one row fails the batch is retried row by
+// row to sync the others and report the failing one.
+
+const upsertDestinationQuery = `
+	INSERT INTO destinations (code, name, country_code, country_name, type, parent_code, latitude, longitude, hotelbeds_data)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	ON CONFLICT (code) DO UPDATE SET
+		name = EXCLUDED.name,
+		country_code = EXCLUDED.country_code,
+		country_name = EXCLUDED.country_name,
+		type = EXCLUDED.type,
+		parent_code = EXCLUDED.parent_code,
+		latitude = EXCLUDED.latitude,
+		longitude = EXCLUDED.longitude,
+		hotelbeds_data = EXCLUDED.hotelbeds_data,
+		updated_at = NOW()
+	RETURNING (xmax = 0) AS inserted
+`
+
+const upsertHotelQuery = `
+	INSERT INTO hotels (
+		provider_code, provider_hotel_id, name, description, star_rating,
+		country_code, city, address, postal_code, latitude, longitude,
+		images, amenities, phone, destination_code, property_type, last_seen_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''), NULLIF($15, ''), NULLIF($16, ''), NOW())
+	ON CONFLICT (provider_code, provider_hotel_id) DO UPDATE SET
+		name = EXCLUDED.name,
+		description = EXCLUDED.description,
+		star_rating = EXCLUDED.star_rating,
+		country_code = EXCLUDED.country_code,
+		city = EXCLUDED.city,
+		address = EXCLUDED.address,
+		postal_code = EXCLUDED.postal_code,
+		latitude = EXCLUDED.latitude,
+		longitude = EXCLUDED.longitude,
+		images = EXCLUDED.images,
+		amenities = EXCLUDED.amenities,
+		phone = EXCLUDED.phone,
+		destination_code = EXCLUDED.destination_code,
+		property_type = EXCLUDED.property_type,
+		last_seen_at = NOW(),
+		deleted_at = CASE WHEN hotels.deactivation_reason = 'SUPPLIER_REMOVED' THEN NULL ELSE hotels.deleted_at END,
+		deactivation_reason = CASE WHEN hotels.deactivation_reason = 'SUPPLIER_REMOVED' THEN NULL ELSE hotels.deactivation_reason END,
+		updated_at = NOW()
+	RETURNING id, (xmax = 0) AS inserted
+`
+
+const upsertHotelContentQuery = `
+	INSERT INTO hotel_contents (hotel_id, language, name, description, amenities, images, policies)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (hotel_id, language) DO UPDATE SET
+		name = EXCLUDED.name,
+		description = EXCLUDED.description,
+		amenities = EXCLUDED.amenities,
+		images = EXCLUDED.images,
+		policies = EXCLUDED.policies,
+		synced_at = NOW(),
+		updated_at = NOW()
+`
+
+const upsertRoomQuery = `
+	INSERT INTO rooms (
+		hotel_id, provider_room_code, name, room_type, max_occupancy,
+		max_adults, max_children, beds, number_of_beds, amenities, images
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11)
+	ON CONFLICT (hotel_id, provider_room_code) DO UPDATE SET
+		name = EXCLUDED.name,
+		room_type = EXCLUDED.room_type,
+		max_occupancy = EXCLUDED.max_occupancy,
+		max_adults = EXCLUDED.max_adults,
+		max_children = EXCLUDED.max_children,
+		beds = EXCLUDED.beds,
+		number_of_beds = EXCLUDED.number_of_beds,
+		amenities = EXCLUDED.amenities,
+		images = EXCLUDED.images,
+		deleted_at = NULL,
+		updated_at = NOW()
+`
+
+func (d *Destination) upsertArgs() []interface{} {
+	return []interface{}{
+		d.Code, d.Name, d.CountryCode, d.CountryName, d.Type,
+		d.ParentCode, d.Latitude, d.Longitude, d.HotelbedsData,
+	}
+}
+
+func (h *Hotel) upsertArgs() []interface{} {
+	return []interface{}{
+		h.ProviderCode, h.ProviderHotelID, h.Name, h.Description, h.StarRating,
+		h.CountryCode, h.City, h.Address, h.PostalCode, h.Latitude, h.Longitude,
+		h.Images, h.Amenities, h.Phone, h.DestinationCode, h.PropertyType,
+	}
+}
+
+func (c *HotelContent) upsertArgs() []interface{} {
+	return []interface{}{
+		c.HotelID, c.Language, c.Name, c.Description, c.Amenities, c.Images, c.Policies,
+	}
+}
+
+func (r *Room) upsertArgs() []interface{} {
+	return []interface{}{
+		r.HotelID, r.ProviderRoomCode, r.Name, r.RoomType, r.MaxOccupancy,
+		r.MaxAdults, r.MaxChildren, r.Beds, r.NumberOfBeds, r.Amenities, r.Images,
+	}
+}
+
+// upsertDestination inserts or updates a destination in the database
+func (s *Service) upsertDestination(ctx context.Context, dest *Destination) (bool, error) {
+	var inserted bool
+	if err := s.db.Pool.QueryRow(ctx, upsertDestinationQuery, dest.upsertArgs()...).Scan(&inserted); err != nil {
+		return false, fmt.Errorf("failed to upsert destination: %w", err)
+	}
+	return inserted, nil
+}
+
+// upsertDestinations upserts a batch of destinations, recording each in result
+func (s *Service) upsertDestinations(ctx context.Context, destinations []Destination, result *SyncResult) {
+	if len(destinations) == 0 {
+		return
+	}
+
+	batch := &pgx.Batch{}
+	for i := range destinations {
+		batch.Queue(upsertDestinationQuery, destinations[i].upsertArgs()...)
+	}
+
+	inserted := make([]bool, len(destinations))
+	err := s.sendBatch(ctx, batch, func(br pgx.BatchResults) error {
+		for i := range destinations {
+			if err := br.QueryRow().Scan(&inserted[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		for i := range destinations {
+			result.record(inserted[i])
+		}
+		return
+	}
+
+	logger.Warnf("Batch upsert of %d destinations failed, retrying one by one: %v", len(destinations), err)
+	for i := range destinations {
+		dest := &destinations[i]
+		ins, err := s.upsertDestination(ctx, dest)
+		if err != nil {
+			result.fail(dest.Code, dest.Name, err)
+			logger.Errorf("Failed to sync destination %s (%s): %v", dest.Code, dest.Name, err)
+			continue
+		}
+		result.record(ins)
+	}
+}
+
+// upsertHotel inserts or updates a hotel in the database, returning its ID
+func (s *Service) upsertHotel(ctx context.Context, hotel *Hotel) (string, bool, error) {
+	var id string
+	var inserted bool
+	if err := s.db.Pool.QueryRow(ctx, upsertHotelQuery, hotel.upsertArgs()...).Scan(&id, &inserted); err != nil {
+		return "", false, fmt.Errorf("failed to upsert hotel: %w", err)
+	}
+	return id, inserted, nil
+}
+
+// upsertHotels upserts a batch of hotels, recording each in result. It
+// returns the IDs of the hotels saved by their provider hotel ID.
+func (s *Service) upsertHotels(ctx context.Context, hotels []Hotel, result *SyncResult) map[string]string {
+	ids := make(map[string]string, len(hotels))
+	if len(hotels) == 0 {
+		return ids
+	}
+
+	batch := &pgx.Batch{}
+	for i := range hotels {
+		batch.Queue(upsertHotelQuery, hotels[i].upsertArgs()...)
+	}
+
+	hotelIDs := make([]string, len(hotels))
+	inserted := make([]bool, len(hotels))
+	err := s.sendBatch(ctx, batch, func(br pgx.BatchResults) error {
+		for i := range hotels {
+			if err := br.QueryRow().Scan(&hotelIDs[i], &inserted[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		for i := range hotels {
+			ids[hotels[i].ProviderHotelID] = hotelIDs[i]
+			result.record(inserted[i])
+		}
+		return ids
+	}
+
+	logger.Warnf("Batch upsert of %d hotels failed, retrying one by one: %v", len(hotels), err)
+	for i := range hotels {
+		hotel := &hotels[i]
+		id, ins, err := s.upsertHotel(ctx, hotel)
+		if err != nil {
+			result.fail(hotel.ProviderHotelID, hotel.Name, err)
+			logger.Errorf("Failed to sync hotel %s (%s): %v", hotel.ProviderHotelID, hotel.Name, err)
+			continue
+		}
+		ids[hotel.ProviderHotelID] = id
+		result.record(ins)
+	}
+	return ids
+}
+
+// upsertHotelContent inserts or updates a hotel's content in one language
+func (s *Service) upsertHotelContent(ctx context.Context, content *HotelContent) error {
+	if _, err := s.db.Pool.Exec(ctx, upsertHotelContentQuery, content.upsertArgs()...); err != nil {
+		return fmt.Errorf("failed to upsert hotel content: %w", err)
+	}
+	return nil
+}
+
+// upsertRoom inserts or updates a room type in a hotel's catalog
+func (s *Service) upsertRoom(ctx context.Context, room *Room) error {
+	if _, err := s.db.Pool.Exec(ctx, upsertRoomQuery, room.upsertArgs()...); err != nil {
+		return fmt.Errorf("failed to upsert room: %w", err)
+	}
+	return nil
+}
+
+// upsertHotelDetails upserts a batch of hotel contents and rooms, recording
+// failures in result
+func (s *Service) upsertHotelDetails(ctx context.Context, contents []HotelContent, rooms []Room, result *SyncResult) {
+	if len(contents)+len(rooms) == 0 {
+		return
+	}
+
+	batch := &pgx.Batch{}
+	for i := range contents {
+		batch.Queue(upsertHotelContentQuery, contents[i].upsertArgs()...)
+	}
+	for i := range rooms {
+		batch.Queue(upsertRoomQuery, rooms[i].upsertArgs()...)
+	}
+
+	err := s.sendBatch(ctx, batch, func(br pgx.BatchResults) error {
+		for i := 0; i < batch.Len(); i++ {
+			if _, err := br.Exec(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		return
+	}
+
+	logger.Warnf("Batch upsert of %d hotel contents and %d rooms failed, retrying one by one: %v",
+		len(contents), len(rooms), err)
+	for i := range contents {
+		content := &contents[i]
+		if err := s.upsertHotelContent(ctx, content); err != nil {
+			result.fail(content.HotelID, fmt.Sprintf("%s (%s)", content.Name, content.Language), err)
+			logger.Errorf("Failed to sync %s content of hotel %s: %v", content.Language, content.HotelID, err)
+		}
+	}
+	for i := range rooms {
+		room := &rooms[i]
+		if err := s.upsertRoom(ctx, room); err != nil {
+			result.fail(room.HotelID, fmt.Sprintf("room %s", room.ProviderRoomCode), err)
+			logger.Errorf("Failed to sync room %s of hotel %s: %v", room.ProviderRoomCode, room.HotelID, err)
+		}
+	}
+}
+
+// sendBatch sends batch and reads its results with read
+func (s *Service) sendBatch(ctx context.Context, batch *pgx.Batch, read func(br pgx.BatchResults) error) error {
+	br := s.db.Pool.SendBatch(ctx, batch)
+	if err := read(br); err != nil {
+		br.Close()
+		return err
+	}
+	return br.Close()
+}
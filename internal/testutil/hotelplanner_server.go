@@ -0,0 +1,188 @@
+package testutil
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// hotelPlannerFixtures holds responses recorded from the HotelPlanner sandbox
+//
+//go:embed testdata/hotelplanner/*.json
+var hotelPlannerFixtures embed.FS
+
+// Credentials the mock HotelPlanner server accepts
+const (
+	HotelPlannerTestAPIKey = "test-api-key"
+	HotelPlannerTestSecret = "test-secret"
+)
+
+// hotelPlannerRoutes maps requests to their recorded responses
+var hotelPlannerRoutes = map[string]string{
+	"POST /v1/hotels/search":               "search.json",
+	"GET /v1/hotels/HP-1001":               "hotel.json",
+	"POST /v1/hotels/HP-1001/availability": "availability.json",
+	"POST /v1/bookings":                    "booking_created.json",
+	"GET /v1/bookings/HPB-5001":            "booking.json",
+	"DELETE /v1/bookings/HPB-5001":         "booking_cancelled.json",
+	"GET /v1/ping":                         "ping.json",
+}
+
+// hotelPlannerFailure is a queued error response
+type hotelPlannerFailure struct {
+	status int
+	body   string
+}
+
+// MockHotelPlannerServer replays recorded HotelPlanner API responses. It
+// issues access tokens like the real API and rejects requests without one.
+type MockHotelPlannerServer struct {
+	Server *httptest.Server
+	T      *testing.T
+
+	mu            sync.Mutex
+	tokens        map[string]bool
+	tokenRequests int
+	requests      []string
+	bodies        map[string][]byte
+	failures      map[string][]hotelPlannerFailure
+}
+
+// NewMockHotelPlannerServer creates a new mock HotelPlanner server
+func NewMockHotelPlannerServer(t *testing.T) *MockHotelPlannerServer {
+	t.Helper()
+
+	m := &MockHotelPlannerServer{
+		T:        t,
+		tokens:   make(map[string]bool),
+		bodies:   make(map[string][]byte),
+		failures: make(map[string][]hotelPlannerFailure),
+	}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Close)
+
+	return m
+}
+
+func (m *MockHotelPlannerServer) handle(w http.ResponseWriter, r *http.Request) {
+	route := r.Method + " " + r.URL.Path
+
+	m.mu.Lock()
+	m.requests = append(m.requests, route)
+	var body map[string]interface{}
+	if r.Body != nil && json.NewDecoder(r.Body).Decode(&body) == nil {
+		m.bodies[route], _ = json.Marshal(body)
+	}
+	m.mu.Unlock()
+
+	if route == "POST /v1/auth/token" {
+		m.issueToken(w, body)
+		return
+	}
+
+	m.mu.Lock()
+	authorized := m.tokens[r.Header.Get("Authorization")]
+	var failure *hotelPlannerFailure
+	if queued := m.failures[route]; len(queued) > 0 {
+		failure = &queued[0]
+		m.failures[route] = queued[1:]
+	}
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case !authorized:
+		m.writeFixture(w, http.StatusUnauthorized, "invalid_token.json")
+	case failure != nil:
+		w.WriteHeader(failure.status)
+		_, _ = w.Write([]byte(failure.body))
+	case hotelPlannerRoutes[route] != "":
+		m.writeFixture(w, http.StatusOK, hotelPlannerRoutes[route])
+	default:
+		m.writeFixture(w, http.StatusNotFound, "not_found.json")
+	}
+}
+
+// issueToken grants a new access token for the test credentials
+func (m *MockHotelPlannerServer) issueToken(w http.ResponseWriter, body map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if body["client_id"] != HotelPlannerTestAPIKey || body["client_secret"] != HotelPlannerTestSecret {
+		m.writeFixture(w, http.StatusUnauthorized, "invalid_client.json")
+		return
+	}
+
+	m.mu.Lock()
+	m.tokenRequests++
+	token := fmt.Sprintf("hp-test-token-%d", m.tokenRequests)
+	m.tokens["Bearer "+token] = true
+	m.mu.Unlock()
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (m *MockHotelPlannerServer) writeFixture(w http.ResponseWriter, status int, name string) {
+	data, err := hotelPlannerFixtures.ReadFile(path.Join("testdata/hotelplanner", name))
+	require.NoError(m.T, err)
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+// FailNext makes the next request to method and path fail with the given
+// status and body
+func (m *MockHotelPlannerServer) FailNext(method, urlPath string, status int, body string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	route := method + " " + urlPath
+	m.failures[route] = append(m.failures[route], hotelPlannerFailure{status: status, body: body})
+}
+
+// RevokeTokens invalidates every access token issued so far
+func (m *MockHotelPlannerServer) RevokeTokens() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens = make(map[string]bool)
+}
+
+// TokenRequests returns how many access tokens were issued
+func (m *MockHotelPlannerServer) TokenRequests() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tokenRequests
+}
+
+// Requests returns the requests received, as "METHOD /path"
+func (m *MockHotelPlannerServer) Requests() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.requests...)
+}
+
+// LastBody returns the JSON body last sent to method and path
+func (m *MockHotelPlannerServer) LastBody(method, urlPath string) map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var body map[string]interface{}
+	_ = json.Unmarshal(m.bodies[method+" "+urlPath], &body)
+	return body
+}
+
+// Close closes the mock server
+func (m *MockHotelPlannerServer) Close() {
+	m.Server.Close()
+}
+
+// URL returns the mock server URL
+func (m *MockHotelPlannerServer) URL() string {
+	return m.Server.URL
+}
@@ -0,0 +1,10 @@
+-- Rollback pay-at-hotel and pay-later booking flows
+-- Migration: 000012
+
+DROP INDEX IF EXISTS idx_bookings_pay_later_due;
+
+ALTER TABLE bookings
+DROP COLUMN IF EXISTS payment_due_at,
+DROP COLUMN IF EXISTS free_cancellation_until,
+DROP COLUMN IF EXISTS payment_token,
+DROP COLUMN IF EXISTS payment_type;
@@ -0,0 +1,16 @@
+-- Pay-at-hotel and pay-later booking flows
+-- Migration: 000012
+
+ALTER TABLE bookings
+ADD COLUMN IF NOT EXISTS payment_type VARCHAR(20) NOT NULL DEFAULT 'PAY_NOW',
+ADD COLUMN IF NOT EXISTS payment_token VARCHAR(255),
+ADD COLUMN IF NOT EXISTS free_cancellation_until TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS payment_due_at TIMESTAMP WITH TIME ZONE;
+
+-- Used by the pay-later job to find bookings whose charge is due
+CREATE INDEX IF NOT EXISTS idx_bookings_pay_later_due ON bookings(status, payment_due_at)
+    WHERE payment_type = 'PAY_LATER';
+
+COMMENT ON COLUMN bookings.payment_token IS 'Saved-card token from the payment gateway (pay-at-hotel guarantee / pay-later auto charge)';
+COMMENT ON COLUMN bookings.free_cancellation_until IS 'Deadline for free cancellation';
+COMMENT ON COLUMN bookings.payment_due_at IS 'When a PAY_LATER booking is charged or the guest is reminded';
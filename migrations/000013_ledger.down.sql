@@ -0,0 +1,5 @@
+-- Rollback double-entry ledger
+-- Migration: 000013
+
+DROP TABLE IF EXISTS ledger_lines;
+DROP TABLE IF EXISTS ledger_entries;
@@ -0,0 +1,38 @@
+-- Double-entry ledger
+-- Migration: 000013
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entry_type VARCHAR(50) NOT NULL,
+    booking_id UUID REFERENCES bookings(id),
+    reference_id VARCHAR(255) NOT NULL,
+    description TEXT,
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    provider_code VARCHAR(50),
+    payment_method VARCHAR(50),
+    posted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    -- One entry per business event so event handlers can be retried
+    CONSTRAINT uq_ledger_entries_type_reference UNIQUE (entry_type, reference_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_booking_id ON ledger_entries(booking_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_posted_at ON ledger_entries(posted_at);
+
+CREATE TABLE IF NOT EXISTS ledger_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entry_id UUID NOT NULL REFERENCES ledger_entries(id) ON DELETE CASCADE,
+    account VARCHAR(50) NOT NULL,
+    debit BIGINT NOT NULL DEFAULT 0 CHECK (debit >= 0),
+    credit BIGINT NOT NULL DEFAULT 0 CHECK (credit >= 0),
+
+    CONSTRAINT chk_ledger_lines_one_side CHECK ((debit = 0) <> (credit = 0))
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_lines_entry_id ON ledger_lines(entry_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_lines_account ON ledger_lines(account);
+
+COMMENT ON TABLE ledger_entries IS 'Journal entries; lines of each entry must balance';
+COMMENT ON COLUMN ledger_entries.reference_id IS 'Payment or booking ID the entry was posted for';
+COMMENT ON TABLE ledger_lines IS 'Debit/credit lines per account, amounts in minor currency units';
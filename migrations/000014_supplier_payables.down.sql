@@ -0,0 +1,4 @@
+-- Rollback supplier payables and settlement
+-- Migration: 000014
+
+DROP TABLE IF EXISTS supplier_payables;
@@ -0,0 +1,32 @@
+-- Supplier payables and settlement
+-- Migration: 000014
+
+CREATE TABLE IF NOT EXISTS supplier_payables (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    booking_id UUID NOT NULL REFERENCES bookings(id),
+    booking_reference VARCHAR(50) NOT NULL,
+    supplier_reference VARCHAR(100) NOT NULL,
+    provider_code VARCHAR(50) NOT NULL,
+    net_amount INTEGER NOT NULL CHECK (net_amount >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    check_in DATE NOT NULL,
+    check_out DATE NOT NULL,
+    due_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    invoice_number VARCHAR(100),
+    invoiced_amount INTEGER,
+    settled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_supplier_payables_booking UNIQUE (booking_id),
+    CONSTRAINT chk_supplier_payables_status CHECK (status IN ('PENDING', 'DISPUTED', 'SETTLED', 'CANCELLED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_supplier_payables_provider_check_out ON supplier_payables(provider_code, check_out);
+CREATE INDEX IF NOT EXISTS idx_supplier_payables_supplier_reference ON supplier_payables(provider_code, supplier_reference);
+CREATE INDEX IF NOT EXISTS idx_supplier_payables_status_due ON supplier_payables(status, due_date);
+
+COMMENT ON TABLE supplier_payables IS 'Net amounts owed to suppliers for confirmed bookings';
+COMMENT ON COLUMN supplier_payables.due_date IS 'Check-out date plus the supplier payment terms';
+COMMENT ON COLUMN supplier_payables.invoiced_amount IS 'Amount on the last reconciled supplier invoice';
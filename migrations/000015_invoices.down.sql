@@ -0,0 +1,8 @@
+-- Rollback invoices, credit notes and billing details
+-- Migration: 000015
+
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS invoice_sequences;
+
+ALTER TABLE bookings
+DROP COLUMN IF EXISTS billing_details;
@@ -0,0 +1,55 @@
+-- Invoices, credit notes and billing details
+-- Migration: 000015
+
+ALTER TABLE bookings
+ADD COLUMN IF NOT EXISTS billing_details JSONB;
+
+COMMENT ON COLUMN bookings.billing_details IS 'Company billing details (name, NPWP, address) for corporate invoices';
+
+-- One counter per document series and year; incremented in the same
+-- transaction as the invoice insert so numbers are gap-free
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    document_type VARCHAR(20) NOT NULL,
+    year INTEGER NOT NULL,
+    last_number INTEGER NOT NULL,
+    PRIMARY KEY (document_type, year)
+);
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    number VARCHAR(30) NOT NULL UNIQUE,
+    document_type VARCHAR(20) NOT NULL CHECK (document_type IN ('INVOICE', 'CREDIT_NOTE')),
+    year INTEGER NOT NULL,
+    sequence INTEGER NOT NULL,
+    booking_id UUID NOT NULL REFERENCES bookings(id),
+    booking_reference VARCHAR(50) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id),
+    payment_id VARCHAR(255) NOT NULL,
+    payment_method VARCHAR(50),
+    original_invoice_id UUID REFERENCES invoices(id),
+    original_invoice_number VARCHAR(30),
+    seller JSONB NOT NULL,
+    buyer JSONB NOT NULL,
+    lines JSONB NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    subtotal INTEGER NOT NULL,
+    tax_name VARCHAR(20) NOT NULL,
+    tax_rate NUMERIC(5, 2) NOT NULL,
+    tax_amount INTEGER NOT NULL,
+    total INTEGER NOT NULL CHECK (total > 0),
+    issued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_invoices_series_sequence UNIQUE (document_type, year, sequence),
+    CONSTRAINT uq_invoices_type_payment UNIQUE (document_type, payment_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_booking_id ON invoices(booking_id);
+CREATE INDEX IF NOT EXISTS idx_invoices_booking_reference ON invoices(booking_reference);
+CREATE INDEX IF NOT EXISTS idx_invoices_user_id ON invoices(user_id);
+CREATE INDEX IF NOT EXISTS idx_invoices_issued_at ON invoices(issued_at DESC);
+CREATE INDEX IF NOT EXISTS idx_invoices_buyer_tax_id ON invoices((buyer->>'tax_id'));
+CREATE INDEX IF NOT EXISTS idx_invoices_original ON invoices(original_invoice_id) WHERE original_invoice_id IS NOT NULL;
+
+COMMENT ON TABLE invoices IS 'Issued invoices and credit notes; never updated after issue';
+COMMENT ON COLUMN invoices.number IS 'INV/<year>/<sequence> or CN/<year>/<sequence>, gap-free per series and year';
+COMMENT ON COLUMN invoices.tax_rate IS 'Tax rate in percent; totals are tax-inclusive';
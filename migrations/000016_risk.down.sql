@@ -0,0 +1,4 @@
+-- Rollback fraud and risk scoring
+-- Migration: 000016
+
+DROP TABLE IF EXISTS risk_assessments;
@@ -0,0 +1,46 @@
+-- Fraud and risk scoring of bookings and payments
+-- Migration: 000016
+
+-- One row per scored attempt. Bookings blocked at creation are never saved,
+-- so booking_id and payment_id carry no foreign keys.
+CREATE TABLE IF NOT EXISTS risk_assessments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    stage VARCHAR(20) NOT NULL CHECK (stage IN ('BOOKING', 'PAYMENT', 'GATEWAY')),
+    booking_id UUID,
+    payment_id UUID,
+    user_id UUID,
+    ip_address VARCHAR(45),
+    ip_country VARCHAR(2),
+    card_fingerprint VARCHAR(30),
+    card_country VARCHAR(2),
+    amount INTEGER NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    score INTEGER NOT NULL CHECK (score BETWEEN 0 AND 100),
+    decision VARCHAR(10) NOT NULL CHECK (decision IN ('ALLOW', 'REVIEW', 'BLOCK')),
+    signals JSONB NOT NULL DEFAULT '[]',
+    fds_status VARCHAR(20),
+    review_status VARCHAR(20) NOT NULL DEFAULT 'NOT_REQUIRED'
+        CHECK (review_status IN ('NOT_REQUIRED', 'PENDING', 'APPROVED', 'REJECTED')),
+    reviewed_by VARCHAR(255),
+    review_note TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Velocity lookups
+CREATE INDEX IF NOT EXISTS idx_risk_assessments_user ON risk_assessments(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_risk_assessments_ip ON risk_assessments(ip_address, created_at);
+CREATE INDEX IF NOT EXISTS idx_risk_assessments_card ON risk_assessments(card_fingerprint, created_at)
+    WHERE card_fingerprint IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_risk_assessments_booking ON risk_assessments(booking_id, created_at);
+
+-- Review queue
+CREATE INDEX IF NOT EXISTS idx_risk_assessments_pending ON risk_assessments(created_at)
+    WHERE review_status = 'PENDING';
+
+COMMENT ON TABLE risk_assessments IS 'Fraud risk scores of booking and payment attempts and their manual reviews';
+COMMENT ON COLUMN risk_assessments.stage IS 'BOOKING at creation, PAYMENT before charging, GATEWAY for the Midtrans FDS verdict';
+COMMENT ON COLUMN risk_assessments.signals IS 'Risk signals that contributed to the score, with their points';
+COMMENT ON COLUMN risk_assessments.card_fingerprint IS 'Masked card number from the gateway, e.g. 481111-1114';
+COMMENT ON COLUMN risk_assessments.fds_status IS 'Midtrans fraud_status: accept, challenge or deny';
@@ -0,0 +1,7 @@
+-- Rollback cross-provider hotel mapping
+-- Migration: 000017
+
+DROP TABLE IF EXISTS hotel_mappings;
+DROP TABLE IF EXISTS master_hotels;
+
+ALTER TABLE hotels DROP COLUMN IF EXISTS phone;
@@ -0,0 +1,40 @@
+-- Cross-provider hotel mapping
+-- Migration: 000017
+
+-- Phone numbers are one of the signals used to match provider hotels
+ALTER TABLE hotels ADD COLUMN IF NOT EXISTS phone VARCHAR(50);
+
+-- One row per physical hotel, whichever providers sell it
+CREATE TABLE IF NOT EXISTS master_hotels (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    city VARCHAR(100),
+    country_code VARCHAR(2),
+    address TEXT,
+    phone VARCHAR(50),
+    latitude DECIMAL(10, 8),
+    longitude DECIMAL(11, 8),
+    star_rating DECIMAL(3,1),
+    content_hotel_id UUID REFERENCES hotels(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Which master hotel each provider hotel is
+CREATE TABLE IF NOT EXISTS hotel_mappings (
+    hotel_id UUID PRIMARY KEY REFERENCES hotels(id) ON DELETE CASCADE,
+    master_hotel_id UUID NOT NULL REFERENCES master_hotels(id) ON DELETE CASCADE,
+    match_score DECIMAL(4,3) NOT NULL DEFAULT 1,
+    source VARCHAR(10) NOT NULL DEFAULT 'AUTO' CHECK (source IN ('AUTO', 'MANUAL')),
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_hotel_mappings_master ON hotel_mappings(master_hotel_id);
+CREATE INDEX IF NOT EXISTS idx_master_hotels_city ON master_hotels(country_code, city);
+
+COMMENT ON TABLE master_hotels IS 'Physical hotels that one or more provider hotels are mapped to';
+COMMENT ON COLUMN master_hotels.content_hotel_id IS 'Provider hotel whose description and images are shown for the master hotel';
+COMMENT ON TABLE hotel_mappings IS 'Maps provider hotels (hotels.provider_code + provider_hotel_id) to master hotels';
+COMMENT ON COLUMN hotel_mappings.source IS 'AUTO: set by the matcher and redone on rebuild, MANUAL: set by an admin and kept';
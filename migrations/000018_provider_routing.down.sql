@@ -0,0 +1,4 @@
+-- Rollback provider routing rules
+-- Migration: 000018
+
+DROP TABLE IF EXISTS provider_routing_rules;
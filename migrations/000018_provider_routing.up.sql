@@ -0,0 +1,22 @@
+-- Provider routing rules
+-- Migration: 000018
+
+-- Priority, traffic weight and on/off switch of a provider, either as its
+-- default (scope DEFAULT) or for a country, destination or single hotel
+CREATE TABLE IF NOT EXISTS provider_routing_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider_code VARCHAR(50) NOT NULL,
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('DEFAULT', 'COUNTRY', 'DESTINATION', 'HOTEL')),
+    scope_value VARCHAR(255) NOT NULL DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0 CHECK (priority >= 0),
+    weight INTEGER NOT NULL DEFAULT 100 CHECK (weight >= 0),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_provider_routing_rules UNIQUE (provider_code, scope, scope_value)
+);
+
+COMMENT ON TABLE provider_routing_rules IS 'Provider priority, weight and enablement by country, destination or hotel';
+COMMENT ON COLUMN provider_routing_rules.priority IS 'Lower is tried first';
+COMMENT ON COLUMN provider_routing_rules.weight IS 'Share of traffic among providers of equal priority';
@@ -0,0 +1,5 @@
+-- Rollback booking provider
+-- Migration: 000019
+
+DROP INDEX IF EXISTS idx_bookings_provider_code;
+ALTER TABLE bookings DROP COLUMN IF EXISTS provider_code;
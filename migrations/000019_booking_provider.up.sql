@@ -0,0 +1,10 @@
+-- Booking provider
+-- Migration: 000019
+
+-- Supplier a booking was made with, so confirmation, cancellation and
+-- content lookups go back to the same provider
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS provider_code VARCHAR(50) NOT NULL DEFAULT 'hotelbeds';
+
+CREATE INDEX IF NOT EXISTS idx_bookings_provider_code ON bookings(provider_code);
+
+COMMENT ON COLUMN bookings.provider_code IS 'Provider the booking was made with (hotelbeds, hotelplanner, ...)';
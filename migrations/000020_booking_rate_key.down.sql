@@ -0,0 +1,5 @@
+-- Rollback booking rate key
+-- Migration: 000020
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS provider_rate_type;
+ALTER TABLE bookings DROP COLUMN IF EXISTS provider_rate_key;
@@ -0,0 +1,10 @@
+-- Booking rate key
+-- Migration: 000020
+
+-- The provider rate a booking was priced with, booked at confirmation.
+-- HotelBeds RECHECK rates are rechecked before booking.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS provider_rate_key TEXT;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS provider_rate_type VARCHAR(20);
+
+COMMENT ON COLUMN bookings.provider_rate_key IS 'Provider key of the priced rate, used to book it';
+COMMENT ON COLUMN bookings.provider_rate_type IS 'Provider rate type (HotelBeds: BOOKABLE or RECHECK)';
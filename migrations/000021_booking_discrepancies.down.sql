@@ -0,0 +1,5 @@
+-- Rollback supplier booking reconciliation discrepancies
+-- Migration: 000021
+
+DROP INDEX IF EXISTS idx_bookings_provider_supplier_reference;
+DROP TABLE IF EXISTS booking_discrepancies;
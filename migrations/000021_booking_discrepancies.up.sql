@@ -0,0 +1,36 @@
+-- Supplier booking reconciliation discrepancies
+-- Migration: 000021
+
+CREATE TABLE IF NOT EXISTS booking_discrepancies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider_code VARCHAR(50) NOT NULL,
+    supplier_reference VARCHAR(100) NOT NULL,
+    booking_id UUID REFERENCES bookings(id),
+    booking_reference VARCHAR(50),
+    type VARCHAR(30) NOT NULL,
+    local_value VARCHAR(255),
+    supplier_value VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+    resolved_by UUID,
+    resolution_note TEXT,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT chk_booking_discrepancies_type CHECK (type IN (
+        'CANCELLED_AT_SUPPLIER', 'CANCELLED_LOCALLY', 'MISSING_AT_SUPPLIER',
+        'MISSING_LOCALLY', 'DATES_MISMATCH', 'AMOUNT_MISMATCH'
+    )),
+    CONSTRAINT chk_booking_discrepancies_status CHECK (status IN ('OPEN', 'RESOLVED'))
+);
+
+-- One open discrepancy per booking and kind; nightly runs refresh it
+CREATE UNIQUE INDEX IF NOT EXISTS uq_booking_discrepancies_open
+    ON booking_discrepancies(provider_code, supplier_reference, type) WHERE status = 'OPEN';
+CREATE INDEX IF NOT EXISTS idx_booking_discrepancies_status_detected ON booking_discrepancies(status, detected_at);
+CREATE INDEX IF NOT EXISTS idx_booking_discrepancies_booking ON booking_discrepancies(booking_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_provider_supplier_reference ON bookings(provider_code, supplier_reference);
+
+COMMENT ON TABLE booking_discrepancies IS 'Differences between supplier booking states and the bookings table, found by nightly reconciliation';
+COMMENT ON COLUMN booking_discrepancies.booking_id IS 'Local booking, NULL when the supplier booking is unknown locally';
+COMMENT ON COLUMN booking_discrepancies.last_seen_at IS 'Last reconciliation run that still found the discrepancy';
@@ -0,0 +1,4 @@
+-- Rollback captured provider HTTP traffic
+-- Migration: 000022
+
+DROP TABLE IF EXISTS provider_exchanges;
@@ -0,0 +1,27 @@
+-- Captured provider HTTP traffic
+-- Migration: 000022
+
+CREATE TABLE IF NOT EXISTS provider_exchanges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider_code VARCHAR(50) NOT NULL,
+    correlation_id VARCHAR(100),
+    booking_id UUID,
+    method VARCHAR(10) NOT NULL,
+    url TEXT NOT NULL,
+    request_headers JSONB NOT NULL DEFAULT '{}',
+    request_body TEXT,
+    status_code INTEGER,
+    response_headers JSONB NOT NULL DEFAULT '{}',
+    response_body TEXT,
+    error TEXT,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_provider_exchanges_booking ON provider_exchanges(booking_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_provider_exchanges_correlation ON provider_exchanges(correlation_id);
+CREATE INDEX IF NOT EXISTS idx_provider_exchanges_created_at ON provider_exchanges(created_at);
+
+COMMENT ON TABLE provider_exchanges IS 'Redacted provider requests and responses, captured when enabled and purged after the retention period';
+COMMENT ON COLUMN provider_exchanges.booking_id IS 'Booking the call was made for; no foreign key as calls precede the booking row';
+COMMENT ON COLUMN provider_exchanges.correlation_id IS 'Request ID of the API request that triggered the call';
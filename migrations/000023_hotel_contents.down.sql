@@ -0,0 +1,4 @@
+-- Rollback per-language hotel content
+-- Migration: 000023
+
+DROP TABLE IF EXISTS hotel_contents;
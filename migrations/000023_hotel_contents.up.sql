@@ -0,0 +1,21 @@
+-- Per-language hotel content
+-- Migration: 000023
+
+CREATE TABLE IF NOT EXISTS hotel_contents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    hotel_id UUID NOT NULL REFERENCES hotels(id) ON DELETE CASCADE,
+    language VARCHAR(5) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    amenities JSONB NOT NULL DEFAULT '[]',
+    images JSONB NOT NULL DEFAULT '[]',
+    policies JSONB NOT NULL DEFAULT '{}',
+    synced_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_hotel_contents_hotel_language UNIQUE (hotel_id, language)
+);
+
+COMMENT ON TABLE hotel_contents IS 'Hotel descriptions, amenities, images and policies synced from providers, one row per language';
+COMMENT ON COLUMN hotel_contents.language IS 'ISO 639-1 code, e.g. id or en';
@@ -0,0 +1,9 @@
+-- Rollback room catalog synced from providers
+-- Migration: 000024
+
+DROP INDEX IF EXISTS uq_rooms_hotel_provider_room;
+
+ALTER TABLE rooms
+DROP COLUMN IF EXISTS max_children,
+DROP COLUMN IF EXISTS max_adults,
+DROP COLUMN IF EXISTS beds;
@@ -0,0 +1,13 @@
+-- Room catalog synced from providers
+-- Migration: 000024
+
+ALTER TABLE rooms
+ADD COLUMN IF NOT EXISTS beds VARCHAR(255),
+ADD COLUMN IF NOT EXISTS max_adults INTEGER,
+ADD COLUMN IF NOT EXISTS max_children INTEGER;
+
+-- One row per provider room type of a hotel; sync upserts on it
+CREATE UNIQUE INDEX IF NOT EXISTS uq_rooms_hotel_provider_room ON rooms(hotel_id, provider_room_code);
+
+COMMENT ON COLUMN rooms.beds IS 'Bed configuration, e.g. "1 Double bed, 1 Sofa bed"';
+COMMENT ON COLUMN rooms.provider_room_code IS 'Room type code at the provider, as returned by availability';
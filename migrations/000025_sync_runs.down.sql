@@ -0,0 +1,4 @@
+-- Rollback content sync runs and checkpoints
+-- Migration: 000025
+
+DROP TABLE IF EXISTS sync_runs;
@@ -0,0 +1,30 @@
+-- Content sync runs and checkpoints
+-- Migration: 000025
+
+CREATE TABLE IF NOT EXISTS sync_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(20) NOT NULL,
+    destination_code VARCHAR(20) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'RUNNING',
+    since TIMESTAMP WITH TIME ZONE,
+    next_offset INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    inserted INTEGER NOT NULL DEFAULT 0,
+    updated INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_sync_runs_kind CHECK (kind IN ('DESTINATIONS', 'HOTELS')),
+    CONSTRAINT chk_sync_runs_status CHECK (status IN ('RUNNING', 'COMPLETED', 'FAILED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_runs_kind_destination ON sync_runs(kind, destination_code, started_at DESC);
+
+COMMENT ON TABLE sync_runs IS 'Content sync runs; unfinished runs resume from next_offset';
+COMMENT ON COLUMN sync_runs.destination_code IS 'HotelBeds destination of a hotel sync, empty for destination syncs';
+COMMENT ON COLUMN sync_runs.since IS 'lastUpdateTime filter of an incremental run, NULL for full runs';
+COMMENT ON COLUMN sync_runs.next_offset IS 'Checkpoint: position of the first record not yet synced';
+COMMENT ON COLUMN sync_runs.started_at IS 'Watermark: incremental runs fetch changes since the last completed run started';
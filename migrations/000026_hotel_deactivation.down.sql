@@ -0,0 +1,14 @@
+-- Rollback hotel deactivation on supplier removal
+-- Migration: 000026
+
+ALTER TABLE sync_runs
+DROP COLUMN IF EXISTS deactivated;
+
+DROP INDEX IF EXISTS idx_hotels_provider_destination;
+
+ALTER TABLE hotels
+DROP COLUMN IF EXISTS reactivated_at,
+DROP COLUMN IF EXISTS reactivated_by,
+DROP COLUMN IF EXISTS deactivation_reason,
+DROP COLUMN IF EXISTS last_seen_at,
+DROP COLUMN IF EXISTS destination_code;
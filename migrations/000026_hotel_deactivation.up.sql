@@ -0,0 +1,21 @@
+-- Hotel deactivation on supplier removal
+-- Migration: 000026
+
+ALTER TABLE hotels
+ADD COLUMN IF NOT EXISTS destination_code VARCHAR(20),
+ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS deactivation_reason VARCHAR(50),
+ADD COLUMN IF NOT EXISTS reactivated_by VARCHAR(255),
+ADD COLUMN IF NOT EXISTS reactivated_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_hotels_provider_destination ON hotels(provider_code, destination_code)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE sync_runs
+ADD COLUMN IF NOT EXISTS deactivated INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN hotels.destination_code IS 'HotelBeds destination the hotel was last synced under';
+COMMENT ON COLUMN hotels.last_seen_at IS 'When the supplier content API last returned the hotel';
+COMMENT ON COLUMN hotels.deactivation_reason IS 'Why deleted_at is set, e.g. SUPPLIER_REMOVED; such hotels are reactivated when the supplier returns them';
+COMMENT ON COLUMN hotels.reactivated_by IS 'Admin who last reactivated the hotel manually';
+COMMENT ON COLUMN sync_runs.deactivated IS 'Hotels deactivated because a complete run no longer returned them';
@@ -0,0 +1,8 @@
+-- Rollback room catalog sync runs
+-- Migration: 000027
+
+DELETE FROM sync_runs WHERE kind = 'ROOMS';
+
+ALTER TABLE sync_runs DROP CONSTRAINT IF EXISTS chk_sync_runs_kind;
+ALTER TABLE sync_runs
+ADD CONSTRAINT chk_sync_runs_kind CHECK (kind IN ('DESTINATIONS', 'HOTELS'));
@@ -0,0 +1,6 @@
+-- Room catalog sync runs
+-- Migration: 000027
+
+ALTER TABLE sync_runs DROP CONSTRAINT IF EXISTS chk_sync_runs_kind;
+ALTER TABLE sync_runs
+ADD CONSTRAINT chk_sync_runs_kind CHECK (kind IN ('DESTINATIONS', 'HOTELS', 'ROOMS'));
@@ -0,0 +1,5 @@
+-- Rollback hotel property types for search filters
+-- Migration: 000028
+
+ALTER TABLE hotels
+DROP COLUMN IF EXISTS property_type;
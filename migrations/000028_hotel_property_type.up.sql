@@ -0,0 +1,7 @@
+-- Hotel property types for search filters
+-- Migration: 000028
+
+ALTER TABLE hotels
+ADD COLUMN IF NOT EXISTS property_type VARCHAR(50);
+
+COMMENT ON COLUMN hotels.property_type IS 'Supplier accommodation type, e.g. HOTEL, APARTMENT or RESORT (HotelBeds accommodationTypeCode)';
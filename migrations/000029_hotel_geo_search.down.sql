@@ -0,0 +1,10 @@
+-- Rollback geo search of hotels by distance and map viewport
+-- Migration: 000029
+
+COMMENT ON COLUMN destinations.type IS 'Destination type: CITY, REGION, or COUNTRY';
+
+DROP INDEX IF EXISTS idx_hotels_point;
+DROP INDEX IF EXISTS idx_hotels_earth;
+
+DROP EXTENSION IF EXISTS earthdistance;
+DROP EXTENSION IF EXISTS cube;
@@ -0,0 +1,17 @@
+-- Geo search of hotels by distance and map viewport
+-- Migration: 000029
+
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+-- Radius searches: earth_box(center, radius) @> ll_to_earth(latitude, longitude)
+CREATE INDEX IF NOT EXISTS idx_hotels_earth ON hotels
+    USING GIST (ll_to_earth(latitude::float8, longitude::float8))
+    WHERE deleted_at IS NULL AND latitude IS NOT NULL AND longitude IS NOT NULL;
+
+-- Viewport searches: point(longitude, latitude) <@ box(south west, north east)
+CREATE INDEX IF NOT EXISTS idx_hotels_point ON hotels
+    USING GIST (point(longitude::float8, latitude::float8))
+    WHERE deleted_at IS NULL AND latitude IS NOT NULL AND longitude IS NOT NULL;
+
+COMMENT ON COLUMN destinations.type IS 'Destination type: CITY, REGION, COUNTRY, or LANDMARK for points of interest hotels can be searched around';
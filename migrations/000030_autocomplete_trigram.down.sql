@@ -0,0 +1,13 @@
+-- Rollback typo-tolerant, accent-insensitive autocomplete
+-- Migration: 000030
+
+DROP TABLE IF EXISTS search_aliases;
+
+DROP INDEX IF EXISTS idx_hotels_destination_code;
+DROP INDEX IF EXISTS idx_hotels_name_trgm;
+DROP INDEX IF EXISTS idx_destinations_name_trgm;
+
+DROP FUNCTION IF EXISTS search_normalize(TEXT);
+
+DROP EXTENSION IF EXISTS unaccent;
+DROP EXTENSION IF EXISTS pg_trgm;
@@ -0,0 +1,49 @@
+-- Typo-tolerant, accent-insensitive autocomplete
+-- Migration: 000030
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent() is only STABLE, so it cannot be used in index expressions as is
+CREATE OR REPLACE FUNCTION search_normalize(value TEXT)
+RETURNS TEXT AS $$
+    SELECT lower(public.unaccent('public.unaccent'::regdictionary, value))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+-- Trigram indexes for similarity (%) and word similarity (<%) matching
+CREATE INDEX IF NOT EXISTS idx_destinations_name_trgm ON destinations
+    USING GIN (search_normalize(name) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_hotels_name_trgm ON hotels
+    USING GIN (search_normalize(name) gin_trgm_ops)
+    WHERE deleted_at IS NULL;
+
+-- Destination popularity counts the active hotels in a destination
+CREATE INDEX IF NOT EXISTS idx_hotels_destination_code ON hotels(destination_code)
+    WHERE deleted_at IS NULL;
+
+-- Other names a destination is searched by, e.g. Jogja for Yogyakarta
+CREATE TABLE IF NOT EXISTS search_aliases (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    alias VARCHAR(255) NOT NULL,
+    canonical_name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_aliases_alias_canonical
+    ON search_aliases(search_normalize(alias), search_normalize(canonical_name));
+CREATE INDEX IF NOT EXISTS idx_search_aliases_alias_trgm ON search_aliases
+    USING GIN (search_normalize(alias) gin_trgm_ops);
+
+COMMENT ON TABLE search_aliases IS 'Alternative names and spellings of destinations for autocomplete';
+COMMENT ON COLUMN search_aliases.canonical_name IS 'Destination name the alias stands for, matched accent and case insensitively';
+
+-- Seed from the city aliases of the NLP entities
+INSERT INTO search_aliases (alias, canonical_name)
+SELECT a.alias, e.entity_name
+FROM nlp_entities e
+CROSS JOIN LATERAL unnest(e.aliases) AS a(alias)
+WHERE e.entity_type = 'city'
+    AND e.is_active
+    AND a.alias <> ''
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,5 @@
+-- Rollback supplier net rate of bookings
+-- Migration: 000031
+
+ALTER TABLE bookings
+DROP COLUMN IF EXISTS net_amount;
@@ -0,0 +1,11 @@
+-- Supplier net rate of bookings, apart from the sell price charged
+-- Migration: 000031
+
+ALTER TABLE bookings
+ADD COLUMN IF NOT EXISTS net_amount INTEGER;
+
+-- Bookings so far were charged the supplier's net rate
+UPDATE bookings SET net_amount = total_amount WHERE net_amount IS NULL;
+
+COMMENT ON COLUMN bookings.total_amount IS 'Sell price charged to the guest: the net rate plus markup';
+COMMENT ON COLUMN bookings.net_amount IS 'Supplier net rate, owed to the supplier once confirmed';
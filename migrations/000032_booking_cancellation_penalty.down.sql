@@ -0,0 +1,5 @@
+-- Rollback supplier cancellation penalty of bookings
+-- Migration: 000032
+
+ALTER TABLE bookings
+DROP COLUMN IF EXISTS cancellation_penalty;
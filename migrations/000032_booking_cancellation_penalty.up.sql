@@ -0,0 +1,10 @@
+-- Supplier cancellation penalty of bookings
+-- Migration: 000032
+
+ALTER TABLE bookings
+ADD COLUMN IF NOT EXISTS cancellation_penalty INTEGER;
+
+-- Without a known penalty, the supplier keeps the whole net rate
+UPDATE bookings SET cancellation_penalty = COALESCE(net_amount, total_amount) WHERE cancellation_penalty IS NULL;
+
+COMMENT ON COLUMN bookings.cancellation_penalty IS 'Supplier charge for cancelling after free_cancellation_until';
@@ -0,0 +1,5 @@
+-- Rollback lead guest name of bookings
+-- Migration: 000033
+
+ALTER TABLE bookings
+DROP COLUMN IF EXISTS guest_name;
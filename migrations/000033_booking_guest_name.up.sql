@@ -0,0 +1,7 @@
+-- Lead guest name of bookings, screened against the account holder
+-- Migration: 000033
+
+ALTER TABLE bookings
+ADD COLUMN IF NOT EXISTS guest_name VARCHAR(255);
+
+COMMENT ON COLUMN bookings.guest_name IS 'Lead guest name given at booking, when it differs from the account holder';
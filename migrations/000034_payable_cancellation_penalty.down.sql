@@ -0,0 +1,5 @@
+-- Rollback supplier cancellation penalty of payables
+-- Migration: 000034
+
+ALTER TABLE supplier_payables
+DROP COLUMN IF EXISTS cancellation_penalty;
@@ -0,0 +1,7 @@
+-- Supplier cancellation penalty of payables, kept apart from the net amount
+-- Migration: 000034
+
+ALTER TABLE supplier_payables
+ADD COLUMN IF NOT EXISTS cancellation_penalty INTEGER CHECK (cancellation_penalty >= 0);
+
+COMMENT ON COLUMN supplier_payables.cancellation_penalty IS 'Penalty owed to the supplier when the booking was cancelled, NULL while it is not';
@@ -0,0 +1,4 @@
+-- Rollback provider circuit breaker overrides
+-- Migration: 000035
+
+DROP TABLE IF EXISTS provider_breaker_overrides;
@@ -0,0 +1,14 @@
+-- Provider circuit breaker overrides
+-- Migration: 000035
+
+-- An admin's manual breaker setting, read by every instance until it expires
+CREATE TABLE IF NOT EXISTS provider_breaker_overrides (
+    provider_code VARCHAR(50) PRIMARY KEY,
+    override VARCHAR(10) NOT NULL CHECK (override IN ('OPEN', 'CLOSED')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE provider_breaker_overrides IS 'Circuit breakers forced open or closed by an admin';
+COMMENT ON COLUMN provider_breaker_overrides.expires_at IS 'After this the breaker follows call outcomes again';